@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
 	"reflect"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -50,6 +51,13 @@ func (c *PacketCluster) ValidateCreate() (admission.Warnings, error) {
 	clusterlog.Info("validate create", "name", c.Name)
 	allErrs := field.ErrorList{}
 
+	// The project is either given or created for the cluster
+	if c.Spec.ProjectID == "" && c.Spec.Project == nil {
+		allErrs = append(allErrs,
+			field.Required(field.NewPath("spec", "projectID"), "projectID is required unless project is set"),
+		)
+	}
+
 	// Must have either one of Metro or Facility
 	if c.Spec.Facility == "" && c.Spec.Metro == "" {
 		allErrs = append(allErrs,
@@ -58,6 +66,9 @@ func (c *PacketCluster) ValidateCreate() (admission.Warnings, error) {
 		)
 	}
 
+	allErrs = append(allErrs, validateControlPlaneEndpoint(c.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateClusterSpec(c.Spec, field.NewPath("spec"))...)
+
 	if len(allErrs) > 0 {
 		return nil, apierrors.NewInvalid(GroupVersion.WithKind("PacketCluster").GroupKind(), c.Name, allErrs)
 	}
@@ -71,13 +82,22 @@ func (c *PacketCluster) ValidateUpdate(oldRaw runtime.Object) (admission.Warning
 	var allErrs field.ErrorList
 	old, _ := oldRaw.(*PacketCluster)
 
-	if !reflect.DeepEqual(c.Spec.ProjectID, old.Spec.ProjectID) {
+	// The ID of a managed project is set once the project is created
+	projectCreated := old.Spec.ProjectID == "" && c.Spec.Project != nil
+	if !reflect.DeepEqual(c.Spec.ProjectID, old.Spec.ProjectID) && !projectCreated {
 		allErrs = append(allErrs,
 			field.Invalid(field.NewPath("spec", "projectID"),
 				c.Spec.ProjectID, "field is immutable"),
 		)
 	}
 
+	if !reflect.DeepEqual(c.Spec.Project, old.Spec.Project) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "project"),
+				c.Spec.Project, "field is immutable"),
+		)
+	}
+
 	if !reflect.DeepEqual(c.Spec.VIPManager, old.Spec.VIPManager) {
 		allErrs = append(allErrs,
 			field.Invalid(field.NewPath("spec", "VIPManager"),
@@ -85,6 +105,27 @@ func (c *PacketCluster) ValidateUpdate(oldRaw runtime.Object) (admission.Warning
 		)
 	}
 
+	if c.Spec.VIPReservationID != old.Spec.VIPReservationID {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "vipReservationID"),
+				c.Spec.VIPReservationID, "field is immutable"),
+		)
+	}
+
+	if !reflect.DeepEqual(c.Spec.LoadBalancer, old.Spec.LoadBalancer) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "loadBalancer"),
+				c.Spec.LoadBalancer, "field is immutable"),
+		)
+	}
+
+	if !reflect.DeepEqual(c.Spec.EMLB, old.Spec.EMLB) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "emlb"),
+				c.Spec.EMLB, "field is immutable"),
+		)
+	}
+
 	// Must have at least Metro or Facility specified
 	if c.Spec.Facility == "" && c.Spec.Metro == "" {
 		allErrs = append(allErrs,
@@ -101,6 +142,21 @@ func (c *PacketCluster) ValidateUpdate(oldRaw runtime.Object) (admission.Warning
 		)
 	}
 
+	allErrs = append(allErrs, validateControlPlaneEndpoint(c.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateClusterSpec(c.Spec, field.NewPath("spec"))...)
+
+	// Metal Gateways cannot be updated, changing their IP reservation requires removing and adding them again
+	for i, gateway := range c.Spec.MetalGateways {
+		for _, oldGateway := range old.Spec.MetalGateways {
+			if gateway.VLAN == oldGateway.VLAN && gateway != oldGateway {
+				allErrs = append(allErrs,
+					field.Forbidden(field.NewPath("spec", "metalGateways").Index(i),
+						"the IP reservation of a Metal Gateway is immutable, remove the gateway before adding it again"),
+				)
+			}
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil, nil
 	}
@@ -108,6 +164,153 @@ func (c *PacketCluster) ValidateUpdate(oldRaw runtime.Object) (admission.Warning
 	return nil, apierrors.NewInvalid(GroupVersion.WithKind("PacketCluster").GroupKind(), c.Name, allErrs)
 }
 
+// validateControlPlaneEndpoint checks that a user-managed endpoint is provided when the provider does not manage one.
+// Templates leave the endpoint to the clusters created from them, so only PacketClusters are checked.
+func validateControlPlaneEndpoint(spec PacketClusterSpec, path *field.Path) field.ErrorList {
+	if spec.VIPManager != NONEVIPID {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if spec.ControlPlaneEndpoint.Host == "" {
+		allErrs = append(allErrs,
+			field.Required(path.Child("controlPlaneEndpoint", "host"),
+				"controlPlaneEndpoint is required when vipManager is NONE"),
+		)
+	}
+	if spec.ControlPlaneEndpoint.Port <= 0 {
+		allErrs = append(allErrs,
+			field.Required(path.Child("controlPlaneEndpoint", "port"),
+				"controlPlaneEndpoint is required when vipManager is NONE"),
+		)
+	}
+	return allErrs
+}
+
+// validateClusterSpec checks the settings of a PacketClusterSpec, at path, that do not depend on a previous version
+// of the PacketCluster.
+func validateClusterSpec(spec PacketClusterSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, validateReservationPools(spec.ReservationPools, path)...)
+	allErrs = append(allErrs, validateLoadBalancerPools(spec, path)...)
+	allErrs = append(allErrs, validateLoadBalancer(spec, path)...)
+	allErrs = append(allErrs, validateEMLB(spec, path)...)
+	allErrs = append(allErrs, validateVIPReservation(spec, path)...)
+	allErrs = append(allErrs, validateMetalGateways(spec, path)...)
+
+	return allErrs
+}
+
+func validateReservationPools(pools []ReservationPool, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, pool := range pools {
+		path := specPath.Child("reservationPools").Index(i)
+		switch {
+		case len(pool.ReservationIDs) == 0 && pool.Plan == "":
+			allErrs = append(allErrs, field.Required(path, "one of reservationIDs or plan is required"))
+		case len(pool.ReservationIDs) > 0 && pool.Plan != "":
+			allErrs = append(allErrs, field.Invalid(path.Child("plan"), pool.Plan, "reservationIDs and plan are mutually exclusive"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateLoadBalancerPools(spec PacketClusterSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(spec.LoadBalancerPools) > 0 && spec.VIPManager != EMLBVIPID {
+		allErrs = append(allErrs,
+			field.Forbidden(specPath.Child("loadBalancerPools"),
+				fmt.Sprintf("loadBalancerPools require vipManager %s", EMLBVIPID)),
+		)
+	}
+
+	ports := map[int32]bool{}
+	for i, pool := range spec.LoadBalancerPools {
+		path := specPath.Child("loadBalancerPools").Index(i)
+		switch {
+		case pool.Port == spec.EMLB.ListenerPort():
+			allErrs = append(allErrs, field.Invalid(path.Child("port"), pool.Port, "port is used by the API server listener"))
+		case ports[pool.Port]:
+			allErrs = append(allErrs, field.Duplicate(path.Child("port"), pool.Port))
+		}
+		ports[pool.Port] = true
+	}
+
+	return allErrs
+}
+
+func validateLoadBalancer(spec PacketClusterSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.LoadBalancer == nil {
+		return nil
+	}
+	path := specPath.Child("loadBalancer")
+	if spec.VIPManager != EMLBVIPID {
+		allErrs = append(allErrs, field.Forbidden(path, fmt.Sprintf("loadBalancer requires vipManager %s", EMLBVIPID)))
+	}
+	if spec.LoadBalancer.ExistingID != "" && spec.LoadBalancer.ExistingName != "" {
+		allErrs = append(allErrs, field.Invalid(path.Child("existingName"), spec.LoadBalancer.ExistingName, "existingID and existingName are mutually exclusive"))
+	}
+
+	return allErrs
+}
+
+func validateEMLB(spec PacketClusterSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.EMLB == nil {
+		return nil
+	}
+	path := specPath.Child("emlb")
+	if spec.VIPManager != EMLBVIPID {
+		allErrs = append(allErrs, field.Forbidden(path, fmt.Sprintf("emlb requires vipManager %s", EMLBVIPID)))
+	}
+	if hc := spec.EMLB.HealthCheck; hc != nil && hc.IntervalSeconds != 0 && hc.TimeoutSeconds >= hc.IntervalSeconds {
+		allErrs = append(allErrs, field.Invalid(path.Child("healthCheck", "timeoutSeconds"), hc.TimeoutSeconds, "timeoutSeconds must be less than intervalSeconds"))
+	}
+
+	return allErrs
+}
+
+func validateVIPReservation(spec PacketClusterSpec, specPath *field.Path) field.ErrorList {
+	if spec.VIPReservationID == "" || spec.VIPManager == CPEMID || spec.VIPManager == KUBEVIPID {
+		return nil
+	}
+	return field.ErrorList{
+		field.Forbidden(specPath.Child("vipReservationID"),
+			fmt.Sprintf("vipReservationID requires vipManager %s or %s", CPEMID, KUBEVIPID)),
+	}
+}
+
+func validateMetalGateways(spec PacketClusterSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	vlans := map[string]bool{}
+	for _, vlan := range spec.VLANs {
+		vlans[vlan.Name] = true
+	}
+	for i, gateway := range spec.MetalGateways {
+		path := specPath.Child("metalGateways").Index(i)
+		if !vlans[gateway.VLAN] {
+			allErrs = append(allErrs, field.NotFound(path.Child("vlan"), gateway.VLAN))
+		}
+		switch {
+		case gateway.IPReservationID == "" && gateway.PrivateIPv4SubnetSize == 0:
+			allErrs = append(allErrs, field.Required(path, "one of ipReservationID or privateIPv4SubnetSize is required"))
+		case gateway.IPReservationID != "" && gateway.PrivateIPv4SubnetSize != 0:
+			allErrs = append(allErrs, field.Invalid(path.Child("privateIPv4SubnetSize"), gateway.PrivateIPv4SubnetSize,
+				"ipReservationID and privateIPv4SubnetSize are mutually exclusive"))
+		}
+	}
+
+	return allErrs
+}
+
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
 func (c *PacketCluster) ValidateDelete() (admission.Warnings, error) {
 	clusterlog.Info("PacketCluster.ValidateDelete called (not implemented)", "name", c.Name)
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var clusterTemplateLog = logf.Log.WithName("packetclustertemplate-resource")
+
+// SetupWebhookWithManager sets up and registers the webhook with the manager.
+func (c *PacketClusterTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(c).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-packetclustertemplate,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=packetclustertemplates,versions=v1beta1,name=validation.packetclustertemplate.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1beta1-packetclustertemplate,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=packetclustertemplates,versions=v1beta1,name=default.packetclustertemplate.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (c *PacketClusterTemplate) ValidateCreate() (admission.Warnings, error) {
+	clusterTemplateLog.Info("validate create", "name", c.Name)
+
+	return nil, c.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (c *PacketClusterTemplate) ValidateUpdate(_ runtime.Object) (admission.Warnings, error) {
+	clusterTemplateLog.Info("validate update", "name", c.Name)
+
+	return nil, c.validate()
+}
+
+// validate checks the template spec like the one of a PacketCluster, except for the settings ClusterClass variables
+// commonly patch in, such as the metro, which the template may leave unset.
+func (c *PacketClusterTemplate) validate() error {
+	path := field.NewPath("spec", "template", "spec")
+	allErrs := validateClusterSpec(c.Spec.Template.Spec, path)
+
+	if c.Spec.Template.Spec.Facility != "" && c.Spec.Template.Spec.Metro != "" {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("facility"),
+				c.Spec.Template.Spec.Facility, "metro and facility are mutually exclusive, metro is recommended"),
+		)
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("PacketClusterTemplate").GroupKind(), c.Name, allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (c *PacketClusterTemplate) ValidateDelete() (admission.Warnings, error) {
+	clusterTemplateLog.Info("PacketClusterTemplate.ValidateDelete called (not implemented)", "name", c.Name)
+
+	return nil, nil
+}
+
+// Default implements webhookutil.defaulter so a webhook will be registered for the type.
+func (c *PacketClusterTemplate) Default() {
+	clusterTemplateLog.Info("default", "name", c.Name)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// DeviceClaimFinalizer allows the PacketDeviceClaim controller to release or delete the device of a claim before
+	// removing it from the apiserver.
+	DeviceClaimFinalizer = "packetdeviceclaim.infrastructure.cluster.x-k8s.io"
+
+	// DeviceBoundCondition reports on the binding of a PacketDeviceClaim to a device.
+	DeviceBoundCondition clusterv1.ConditionType = "DeviceBound"
+	// WaitingForDeviceReason used while no device of the pool of the claim is available.
+	WaitingForDeviceReason = "WaitingForDevice"
+	// DeviceNotFoundReason used when the device of the claim does not exist, or no longer exists.
+	DeviceNotFoundReason = "DeviceNotFound"
+	// DeviceInUseReason used when the device of the claim is already claimed, or managed by a machine.
+	DeviceInUseReason = "DeviceInUse"
+
+	// WaitingForDeviceClaimReason used when a PacketMachine waits for its PacketDeviceClaim to be bound.
+	WaitingForDeviceClaimReason = "WaitingForDeviceClaim"
+)
+
+// DeviceReclaimPolicy describes what happens to the device of a PacketDeviceClaim when the claim is deleted.
+type DeviceReclaimPolicy string
+
+const (
+	// DeviceReclaimRetain keeps the device, without the tags it was given for the claim and its machine. A device
+	// claimed from a pool returns to the pool.
+	DeviceReclaimRetain = DeviceReclaimPolicy("Retain")
+	// DeviceReclaimDelete deletes the device.
+	DeviceReclaimDelete = DeviceReclaimPolicy("Delete")
+)
+
+// PacketDeviceClaimSpec defines the desired state of PacketDeviceClaim.
+type PacketDeviceClaimSpec struct {
+	// DeviceID claims a given device of the project of the cluster, e.g. to adopt a device created outside of
+	// Cluster API. Mutually exclusive with PoolTags.
+	// +optional
+	DeviceID string `json:"deviceID,omitempty"`
+
+	// PoolTags claims the oldest unclaimed active device carrying all of the tags, e.g. of a warm pool of devices
+	// provisioned ahead of time. Mutually exclusive with DeviceID.
+	// +optional
+	PoolTags []string `json:"poolTags,omitempty"`
+
+	// ReclaimPolicy is what happens to the device when the claim is deleted. Defaults to Retain.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +optional
+	ReclaimPolicy DeviceReclaimPolicy `json:"reclaimPolicy,omitempty"`
+}
+
+// PacketDeviceClaimStatus defines the observed state of PacketDeviceClaim.
+type PacketDeviceClaimStatus struct {
+	// Bound is true when the claim holds its device.
+	// +optional
+	Bound bool `json:"bound"`
+
+	// DeviceID is the ID of the device bound to the claim.
+	// +optional
+	DeviceID string `json:"deviceID,omitempty"`
+
+	// Machine is the name of the Machine using the device, if any. A claim is not released while its device is used.
+	// +optional
+	Machine string `json:"machine,omitempty"`
+
+	// Conditions defines current service state of the PacketDeviceClaim.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:subresource:status
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=packetdeviceclaims,shortName=pdc,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this PacketDeviceClaim belongs"
+// +kubebuilder:printcolumn:name="Bound",type="string",JSONPath=".status.bound",description="PacketDeviceClaim bound status"
+// +kubebuilder:printcolumn:name="Device",type="string",JSONPath=".status.deviceID",description="Device bound to the claim"
+// +kubebuilder:printcolumn:name="Machine",type="string",JSONPath=".status.machine",description="Machine using the device"
+
+// PacketDeviceClaim is the Schema for the packetdeviceclaims API. It binds an existing Equinix Metal device to the
+// cluster of its cluster.x-k8s.io/cluster-name label, for a PacketMachine to use it instead of creating a device.
+type PacketDeviceClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PacketDeviceClaimSpec   `json:"spec,omitempty"`
+	Status PacketDeviceClaimStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PacketDeviceClaimList contains a list of PacketDeviceClaim.
+type PacketDeviceClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PacketDeviceClaim `json:"items"`
+}
+
+// GetConditions returns the list of conditions for a PacketDeviceClaim API object.
+func (c *PacketDeviceClaim) GetConditions() clusterv1.Conditions {
+	return c.Status.Conditions
+}
+
+// SetConditions will set the given conditions on a PacketDeviceClaim object.
+func (c *PacketDeviceClaim) SetConditions(conditions clusterv1.Conditions) {
+	c.Status.Conditions = conditions
+}
+
+// ReclaimPolicy returns the reclaim policy of the claim, Retain by default.
+func (c *PacketDeviceClaim) ReclaimPolicy() DeviceReclaimPolicy {
+	if c.Spec.ReclaimPolicy == "" {
+		return DeviceReclaimRetain
+	}
+	return c.Spec.ReclaimPolicy
+}
+
+func init() {
+	objectTypes = append(objectTypes, &PacketDeviceClaim{}, &PacketDeviceClaimList{})
+}
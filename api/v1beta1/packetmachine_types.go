@@ -28,6 +28,24 @@ const (
 	// MachineFinalizer allows ReconcilePacketMachine to clean up Packet resources before
 	// removing it from the apiserver.
 	MachineFinalizer = "packetmachine.infrastructure.cluster.x-k8s.io"
+
+	// HibernatedAnnotation is set on PacketMachines whose device was powered off because their cluster is hibernated.
+	// Its value records whether the provider added the skip-remediation annotation to the Machine.
+	HibernatedAnnotation = "packetmachine.infrastructure.cluster.x-k8s.io/hibernated"
+
+	// ReleaseDeviceAnnotation keeps the device of a PacketMachine when it is deleted, releasing it to be adopted by
+	// another machine of the cluster instead. Its value, when set, is the name of the MachineDeployment whose new
+	// machines adopt the device.
+	ReleaseDeviceAnnotation = "packetmachine.infrastructure.cluster.x-k8s.io/release-device"
+
+	// AdoptDeviceAnnotation is set to the ID of a released device for a PacketMachine to adopt it rather than to
+	// create a new device. When empty, e.g. set on a PacketMachineTemplate, machines of a MachineDeployment adopt the
+	// devices released for it, if any.
+	AdoptDeviceAnnotation = "packetmachine.infrastructure.cluster.x-k8s.io/adopt-device"
+
+	// TolerateMaintenanceAnnotation set to "true" keeps the Node of a PacketMachine schedulable through the
+	// maintenances of its device, when the manager otherwise cordons Nodes ahead of them.
+	TolerateMaintenanceAnnotation = "packetmachine.infrastructure.cluster.x-k8s.io/tolerate-maintenance"
 )
 
 const (
@@ -46,18 +64,128 @@ const (
 	InstanceProvisionStartedReason = "InstanceProvisionStarted"
 	// InstanceProvisionFailedReason used for failures during instance provisioning.
 	InstanceProvisionFailedReason = "InstanceProvisionFailed"
+	// BootstrapDataTooLargeReason used when the bootstrap data exceeds the userdata size Equinix Metal accepts, even
+	// compressed.
+	BootstrapDataTooLargeReason = "BootstrapDataTooLarge"
 	// WaitingForClusterInfrastructureReason used when machine is waiting for cluster infrastructure to be ready before proceeding.
 	WaitingForClusterInfrastructureReason = "WaitingForClusterInfrastructure"
 	// WaitingForBootstrapDataReason used when machine is waiting for bootstrap data to be ready before proceeding.
 	WaitingForBootstrapDataReason = "WaitingForBootstrapData"
+	// InstanceLocationMismatchReason used when the facility or metro of the instance does not match the PacketMachine spec.
+	InstanceLocationMismatchReason = "InstanceLocationMismatch"
+	// InstanceDeprovisioningReason used when the instance was deleted and is waited for to finish deprovisioning.
+	InstanceDeprovisioningReason = "InstanceDeprovisioning"
+	// WaitingForDrainReason used when the deletion of the instance waits for Cluster API to drain the Node of the machine.
+	WaitingForDrainReason = "WaitingForDrain"
+	// InstanceRecreatingReason used when a failed instance was deleted to be created again.
+	InstanceRecreatingReason = "InstanceRecreating"
+	// InstanceSpotReclaimedReason used when Equinix Metal is reclaiming the spot market instance of the machine.
+	InstanceSpotReclaimedReason = "InstanceSpotReclaimed"
+	// WaitingForHardwareReservationReason used when the hardware reservations of the machine are all busy, e.g. still
+	// deprovisioning, and the device creation is retried later.
+	WaitingForHardwareReservationReason = "WaitingForHardwareReservation"
+	// InstanceHibernatedReason used when the instance is powered off, or being powered on or off, because the cluster is hibernated.
+	InstanceHibernatedReason = "InstanceHibernated"
+	// DeviceQuotaExceededReason used when the cluster has reached its maxDevices and the device creation is retried
+	// once devices of the cluster were deleted.
+	DeviceQuotaExceededReason = "DeviceQuotaExceeded"
+
+	// ProviderIDMigratedCondition reports on whether a legacy packet:// providerID has been migrated to the equinixmetal:// format.
+	ProviderIDMigratedCondition clusterv1.ConditionType = "ProviderIDMigrated"
+
+	// LegacyProviderIDReason used when the providerID uses the legacy packet:// format and cannot be migrated automatically.
+	LegacyProviderIDReason = "LegacyProviderID"
+
+	// BootstrapDataUpToDateCondition reports on whether the device was created with the current bootstrap data.
+	BootstrapDataUpToDateCondition clusterv1.ConditionType = "BootstrapDataUpToDate"
+
+	// BootstrapDataChangedReason used when the bootstrap data changed after the device was created with it.
+	BootstrapDataChangedReason = "BootstrapDataChanged"
+
+	// DuplicateDevicesCondition is set while more than one device carries the tags of the PacketMachine, e.g. after a
+	// crash during a device creation that was then retried. It is removed once the duplicates are gone.
+	DuplicateDevicesCondition clusterv1.ConditionType = "DuplicateDevices"
+
+	// DuplicateDevicesFoundReason used when devices other than the one used by the PacketMachine carry its tags.
+	DuplicateDevicesFoundReason = "DuplicateDevicesFound"
+
+	// DeviceIdentityMismatchCondition is set while the providerID of the PacketMachine refers to a device that has
+	// neither its tags nor its hostname, e.g. after restoring a backup, so that the device is left alone. It is
+	// removed once the providerID refers to the device of the machine again.
+	DeviceIdentityMismatchCondition clusterv1.ConditionType = "DeviceIdentityMismatch"
+
+	// DeviceNotOwnedReason used when the device of the providerID of the PacketMachine belongs to something else.
+	DeviceNotOwnedReason = "DeviceNotOwned"
+
+	// BGPSessionsReadyCondition reports on whether the BGP sessions of the device, used by kube-vip to announce the
+	// control plane endpoint, are established.
+	BGPSessionsReadyCondition clusterv1.ConditionType = "BGPSessionsReady"
+
+	// BGPSessionsDownReason used when a BGP session of the device is not established. The condition severity becomes
+	// Warning once the session stayed down for longer than the BGP session timeout.
+	BGPSessionsDownReason = "BGPSessionsDown"
+
+	// NetworkBondReadyCondition reports on whether the network ports of the device that belong to a bond are bonded.
+	NetworkBondReadyCondition clusterv1.ConditionType = "NetworkBondReady"
+
+	// RebondingPortsReason used while disbonded ports of the device are being bonded again.
+	RebondingPortsReason = "RebondingPorts"
+	// PortsDisbondedReason used when ports of the device stayed disbonded after they were bonded again, e.g. because
+	// the LACP partner is misconfigured, and need manual intervention.
+	PortsDisbondedReason = "PortsDisbonded"
+
+	// VLANsAttachedCondition reports on whether the device is attached to the VLANs of the PacketMachine.
+	VLANsAttachedCondition clusterv1.ConditionType = "VLANsAttached"
+
+	// WaitingForVLANReason used while a VLAN of the PacketMachine is not yet created by its PacketCluster.
+	WaitingForVLANReason = "WaitingForVLAN"
+	// VLANAttachFailedReason used when the device could not be attached to, or detached from, a VLAN.
+	VLANAttachFailedReason = "VLANAttachFailed"
+	// WaitingForPortConversionReason used while the VLANs of the device wait for the port conversions of other machines
+	// of the cluster to complete, see PacketClusterSpec.MaxConcurrentPortConversions.
+	WaitingForPortConversionReason = "WaitingForPortConversion"
+	// PortConversionInProgressReason used after the VLANs of the device were changed, until the Node of the machine is
+	// healthy again.
+	PortConversionInProgressReason = "PortConversionInProgress"
+
+	// IPAddressesClaimedCondition reports on whether the addresses of the AddressesFromPools of the PacketMachine are
+	// allocated.
+	IPAddressesClaimedCondition clusterv1.ConditionType = "IPAddressesClaimed"
+
+	// WaitingForIPAddressesReason used while an address claimed from an IP address pool is not allocated yet.
+	WaitingForIPAddressesReason = "WaitingForIPAddresses"
+
+	// MaintenanceScheduledCondition is set while Equinix Metal has a maintenance of the device scheduled or in
+	// progress. It is removed once the maintenance completed or was cancelled.
+	MaintenanceScheduledCondition clusterv1.ConditionType = "MaintenanceScheduled"
+
+	// MaintenancePendingReason used when a maintenance of the device is scheduled or in progress.
+	MaintenancePendingReason = "MaintenancePending"
+	// NodeCordonedForMaintenanceReason used when the Node of the machine was cordoned ahead of the maintenance of its
+	// device. The Node is uncordoned once the maintenance is over.
+	NodeCordonedForMaintenanceReason = "NodeCordonedForMaintenance"
 )
 
 // PacketMachineSpec defines the desired state of PacketMachine.
+//
+// MachineType, Facility, Metro, IPXEUrl and Tags may be Go templates, e.g. "{{ .variables.workerMetro }}", so a
+// single PacketMachineTemplate can serve several worker pools. They are resolved once, when the device is created,
+// with .cluster.name, .cluster.namespace, .machine.name, .machine.role, .machineSet.name, .machineDeployment.name
+// and .variables, the Cluster topology variables including the overrides of the Machine's MachineDeployment.
+// IPXEUrl may also use .metro, the resolved metro of the machine.
 type PacketMachineSpec struct {
 	OS           string                              `json:"os"`
 	BillingCycle metal.DeviceCreateInputBillingCycle `json:"billingCycle,omitempty"`
 	MachineType  string                              `json:"machineType"`
-	SSHKeys      []string                            `json:"sshKeys,omitempty"`
+
+	// SSHKeys are public keys, in authorized_keys format, to authorize on the device.
+	// +optional
+	SSHKeys []string `json:"sshKeys,omitempty"`
+
+	// ProjectSSHKeyIDs are the IDs of SSH keys of the project to authorize on the device, in addition to SSHKeys.
+	// Equinix Metal authorizes the keys of the project and of its members when neither is set.
+	// +optional
+	ProjectSSHKeyIDs []string `json:"projectSSHKeyIDs,omitempty"`
 
 	// Facility represents the Packet facility for this machine.
 	// Override from the PacketCluster spec.
@@ -74,11 +202,99 @@ type PacketMachineSpec struct {
 	// +optional
 	IPXEUrl string `json:"ipxeURL,omitempty"`
 
+	// IPXEScriptSecretRef references a Secret holding the iPXE script to boot the device with, for scripts that
+	// must not be publicly reachable. The script is passed to the device as its userdata, which Equinix Metal
+	// runs as the iPXE script when no URL is set, so it replaces the bootstrap data for this machine.
+	// Mutually exclusive with IPXEUrl; OS must be set to "custom_ipxe".
+	// +optional
+	IPXEScriptSecretRef *SecretKeyReference `json:"ipxeScriptSecretRef,omitempty"`
+
+	// AlwaysPXE makes the device boot from the network, with its iPXE script, on every boot rather than only on
+	// the first one, for operating systems managed by network boot such as Talos. OS must be set to "custom_ipxe",
+	// with IPXEUrl or IPXEScriptSecretRef.
+	// +optional
+	AlwaysPXE bool `json:"alwaysPXE,omitempty"`
+
+	// CustomImage installs a custom image on the device with the Equinix Metal custom images feature, on top of the
+	// operating system OS. Mutually exclusive with IPXEUrl, IPXEScriptSecretRef and AlwaysPXE, and with OS "custom_ipxe".
+	// +optional
+	CustomImage *CustomImage `json:"customImage,omitempty"`
+
+	// OSHardening applies a baseline hardening to the operating system of the device, with cloud-config merged into
+	// its bootstrap data. Requires cloud-config bootstrap data.
+	// +optional
+	OSHardening *OSHardening `json:"osHardening,omitempty"`
+
 	// HardwareReservationID is the unique device hardware reservation ID, a comma separated list of
 	// hardware reservation IDs, or `next-available` to automatically let the Packet api determine one.
 	// +optional
 	HardwareReservationID string `json:"hardwareReservationID,omitempty"`
 
+	// ReservationPool is the name of a reservation pool of the PacketCluster to allocate the device from.
+	// Mutually exclusive with HardwareReservationID.
+	// +optional
+	ReservationPool string `json:"reservationPool,omitempty"`
+
+	// ReservationSelector picks the hardware reservation to create the device on among the provisionable
+	// reservations of the project that match it, rather than from a list of IDs.
+	// Mutually exclusive with HardwareReservationID and ReservationPool.
+	// +optional
+	ReservationSelector *HardwareReservationSelector `json:"reservationSelector,omitempty"`
+
+	// DeviceClaimName is the name of a PacketDeviceClaim, in the namespace of the PacketMachine, whose device the
+	// machine uses instead of creating one. The claim decides what happens to the device when the machine is deleted.
+	// +optional
+	DeviceClaimName string `json:"deviceClaimName,omitempty"`
+
+	// LoadBalancerPools are the names of load balancer pools of the PacketCluster to register the device in.
+	// The device is added as an origin of each pool at its public IPv4 address.
+	// +optional
+	LoadBalancerPools []string `json:"loadBalancerPools,omitempty"`
+
+	// VLANs are the names of VLANs of the PacketCluster to attach the bond0 port of the device to, in hybrid bonded
+	// mode. The device stays reachable on its public and private addresses.
+	// +optional
+	VLANs []string `json:"vlans,omitempty"`
+
+	// AddressesFromPools are IP address pools to claim an address from for the device, one per pool, following the
+	// Cluster API IPAM contract, e.g. for its interfaces on the VLANs. The device is created once all the addresses
+	// are allocated, and they are passed to its bootstrap data as the ipAddresses template variable. The addresses
+	// are released before the device is deleted, once it is detached from its VLANs.
+	// +optional
+	AddressesFromPools []corev1.TypedLocalObjectReference `json:"addressesFromPools,omitempty"`
+
+	// PrivateIPv4SubnetSize is the prefix length of the private IPv4 block of the device, from 28 to 31, instead of
+	// the Equinix Metal default of 31. Use IPAddresses to also change the public blocks of the device.
+	// +kubebuilder:validation:Minimum=28
+	// +kubebuilder:validation:Maximum=31
+	// +optional
+	PrivateIPv4SubnetSize *int32 `json:"privateIPv4SubnetSize,omitempty"`
+
+	// PublicIPv4SubnetSize is the prefix length of the public IPv4 block of the device, from 28 to 31, instead of
+	// the Equinix Metal default of 31, for workloads needing a routable block on the host. The block is released
+	// with the device. Use IPAddresses to also change the other blocks of the device.
+	// +kubebuilder:validation:Minimum=28
+	// +kubebuilder:validation:Maximum=31
+	// +optional
+	PublicIPv4SubnetSize *int32 `json:"publicIPv4SubnetSize,omitempty"`
+
+	// IPAddresses are the address blocks the device is created with, instead of the Equinix Metal default of a public
+	// IPv4, a private IPv4 and a public IPv6 block. A private IPv4 block is required; leaving out the public blocks
+	// creates a device only reachable on its private network and its VLANs.
+	// +optional
+	IPAddresses []DeviceIPAddress `json:"ipAddresses,omitempty"`
+
+	// SpotPriceMax, when set, creates the device as a spot market instance bidding at most this price, in US dollars
+	// per hour, e.g. "0.50". Equinix Metal reclaims spot devices when they are outbid.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	SpotPriceMax string `json:"spotPriceMax,omitempty"`
+
+	// TerminationTime, when set, is when Equinix Metal deletes the device. When Equinix Metal gives a spot device
+	// another termination time because it was outbid, the machine is marked as failed for it to be remediated.
+	// +optional
+	TerminationTime *metav1.Time `json:"terminationTime,omitempty"`
+
 	// ProviderID is the unique identifier as specified by the cloud provider.
 	// +optional
 	ProviderID *string `json:"providerID,omitempty"`
@@ -86,6 +302,220 @@ type PacketMachineSpec struct {
 	// Tags is an optional set of tags to add to Packet resources managed by the Packet provider.
 	// +optional
 	Tags Tags `json:"tags,omitempty"`
+
+	// DeletePolicy controls how the device is deleted, overriding the one of the PacketCluster.
+	// +kubebuilder:validation:Enum=Graceful;Force;ForceAfterTimeout
+	// +optional
+	DeletePolicy DeletePolicy `json:"deletePolicy,omitempty"`
+
+	// DeletionTimeoutSeconds is how long the device is deleted gracefully with the ForceAfterTimeout delete policy,
+	// and how long the deletion waits for the Node of the machine to be drained, overriding the one of the
+	// PacketCluster. Defaults to 600.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DeletionTimeoutSeconds *int32 `json:"deletionTimeoutSeconds,omitempty"`
+
+	// FailedDeviceRetries is how many times a device that fails to provision is deleted and created again before the
+	// machine is marked as failed. Disabled when 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	FailedDeviceRetries int32 `json:"failedDeviceRetries,omitempty"`
+}
+
+// HardwareReservationSelector selects hardware reservations by criteria. Matching reservations are tried in the
+// order of their IDs.
+type HardwareReservationSelector struct {
+	// Plan is the plan of the reservations. Defaults to the machine type of the PacketMachine.
+	// +optional
+	Plan string `json:"plan,omitempty"`
+
+	// Metro is the metro of the reservations. Defaults to the metro the device is created in, if any.
+	// +optional
+	Metro string `json:"metro,omitempty"`
+
+	// Facility is the facility of the reservations. Defaults to the facility the device is created in, if any.
+	// Mutually exclusive with Metro.
+	// +optional
+	Facility string `json:"facility,omitempty"`
+
+	// Tags are tags the reservations must all have.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// NextAvailable lets Equinix Metal pick any provisionable hardware reservation of the plan of the machine when
+	// none matches the selector.
+	// +optional
+	NextAvailable bool `json:"nextAvailable,omitempty"`
+}
+
+// DeviceIPAddress is an address block a device is created with.
+type DeviceIPAddress struct {
+	// AddressFamily is the IP version of the block.
+	// +kubebuilder:validation:Enum=4;6
+	AddressFamily int32 `json:"addressFamily"`
+
+	// Public selects a public block rather than a private one. Private blocks are only available for IPv4.
+	// +optional
+	Public bool `json:"public,omitempty"`
+
+	// CIDR is the prefix length of the block, from 28 to 31 for IPv4 and from 120 to 127 for IPv6. Equinix Metal
+	// picks the default size of the block when unset.
+	// +optional
+	CIDR *int32 `json:"cidr,omitempty"`
+
+	// IPReservations are the IDs of IP reservations to assign the block from.
+	// +optional
+	IPReservations []string `json:"ipReservations,omitempty"`
+}
+
+// CustomImage is a custom operating system image, published in a Git repository in the layout of the Equinix Metal
+// images.
+type CustomImage struct {
+	// URL is the URL of the Git repository of the image.
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// Tag is the commit or tag of the image in the repository.
+	// +kubebuilder:validation:MinLength=1
+	Tag string `json:"tag"`
+}
+
+// OSHardening is a baseline hardening of the operating system of a device, applied by cloud-init on its first boot.
+type OSHardening struct {
+	// DisableSSHPasswordAuth disables password authentication to the SSH server, so that only keys are accepted.
+	// +optional
+	DisableSSHPasswordAuth bool `json:"disableSSHPasswordAuth,omitempty"`
+
+	// KernelHardening restricts access to kernel pointers and logs, and ignores ICMP redirects, with sysctls that
+	// do not affect Kubernetes networking.
+	// +optional
+	KernelHardening bool `json:"kernelHardening,omitempty"`
+}
+
+// SecretKeyReference references a key of a Secret in the same namespace as the referencing object.
+type SecretKeyReference struct {
+	// Name of the Secret.
+	Name string `json:"name"`
+
+	// Key within the Secret.
+	Key string `json:"key"`
+}
+
+// HardwareStatus describes the hardware of a device.
+type HardwareStatus struct {
+	// CPUs of the device.
+	// +optional
+	CPUs []HardwareCPU `json:"cpus,omitempty"`
+
+	// Memory is the total memory of the device, e.g. "64GB".
+	// +optional
+	Memory string `json:"memory,omitempty"`
+
+	// Drives of the device.
+	// +optional
+	Drives []HardwareDrive `json:"drives,omitempty"`
+
+	// NICs of the device.
+	// +optional
+	NICs []HardwareNIC `json:"nics,omitempty"`
+}
+
+// HardwareCPU describes a group of identical CPUs.
+type HardwareCPU struct {
+	// Count of CPUs.
+	Count int32 `json:"count"`
+
+	// Type of the CPUs, e.g. "Intel Xeon E-2278G 8-Core Processor @ 3.40GHz".
+	// +optional
+	Type string `json:"type,omitempty"`
+}
+
+// HardwareDrive describes a group of identical drives.
+type HardwareDrive struct {
+	// Count of drives.
+	Count int32 `json:"count"`
+
+	// Type of the drives, e.g. "SSD" or "NVME".
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Size of each drive, e.g. "480GB".
+	// +optional
+	Size string `json:"size,omitempty"`
+
+	// Category of the drives, e.g. "boot" or "storage".
+	// +optional
+	Category string `json:"category,omitempty"`
+}
+
+// HardwareNIC describes a group of identical network interfaces.
+type HardwareNIC struct {
+	// Count of network interfaces.
+	Count int32 `json:"count"`
+
+	// Type of the network interfaces, usually their speed, e.g. "10Gbps".
+	// +optional
+	Type string `json:"type,omitempty"`
+}
+
+// LoadBalancerOrigin describes the origin of a device in its pool of the API server on the load balancer of the
+// cluster.
+type LoadBalancerOrigin struct {
+	// PoolID is the ID of the pool created for the device.
+	// +optional
+	PoolID string `json:"poolID,omitempty"`
+
+	// OriginID is the ID of the origin of the device in the pool.
+	// +optional
+	OriginID string `json:"originID,omitempty"`
+}
+
+// LoadBalancerPoolOrigin describes the origin of a device in a load balancer pool of the cluster.
+type LoadBalancerPoolOrigin struct {
+	// Pool is the name of the pool in the loadBalancerPools of the cluster.
+	Pool string `json:"pool"`
+
+	// OriginID is the ID of the origin of the device in the pool.
+	OriginID string `json:"originID"`
+}
+
+// BGPSessionState is the state of a BGP session.
+// +kubebuilder:validation:Enum=up;down;unknown
+type BGPSessionState string
+
+const (
+	// BGPSessionUp is the state of a session established with every switch the device is connected to.
+	BGPSessionUp BGPSessionState = "up"
+	// BGPSessionDown is the state of a session that is down with at least one switch.
+	BGPSessionDown BGPSessionState = "down"
+	// BGPSessionUnknown is the state of a session whose status could not be retrieved from every switch yet.
+	BGPSessionUnknown BGPSessionState = "unknown"
+)
+
+// BGPSession describes a BGP session of the device.
+type BGPSession struct {
+	// AddressFamily of the session, ipv4 or ipv6.
+	AddressFamily string `json:"addressFamily"`
+
+	// State of the session.
+	State BGPSessionState `json:"state"`
+
+	// LastTransitionTime is the last time the session was established or lost.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// DeviceEvent is an event of the device reported by Equinix Metal.
+type DeviceEvent struct {
+	// Type of the event, e.g. provisioning.104.
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Message of the event.
+	Message string `json:"message"`
+
+	// Time of the event.
+	Time metav1.Time `json:"time"`
 }
 
 // PacketMachineStatus defines the observed state of PacketMachine.
@@ -97,10 +527,54 @@ type PacketMachineStatus struct {
 	// Addresses contains the Packet device associated addresses.
 	Addresses []corev1.NodeAddress `json:"addresses,omitempty"`
 
+	// PublicIPv4Block is the public IPv4 block of the device, in CIDR notation.
+	// +optional
+	PublicIPv4Block string `json:"publicIPv4Block,omitempty"`
+
 	// InstanceStatus is the status of the Packet device instance for this machine.
 	// +optional
 	InstanceStatus *PacketResourceStatus `json:"instanceStatus,omitempty"`
 
+	// ProvisioningPercentage is how far the provisioning of the device is, from 0 to 100, while it is provisioned.
+	// +optional
+	ProvisioningPercentage *int32 `json:"provisioningPercentage,omitempty"`
+
+	// LastEvent is the latest provisioning event of the device, e.g. to find out why a provision is slow or stuck.
+	// +optional
+	LastEvent *DeviceEvent `json:"lastEvent,omitempty"`
+
+	// BootstrapDataHash is the SHA-256 hash of the bootstrap data the device was created with.
+	// +optional
+	BootstrapDataHash string `json:"bootstrapDataHash,omitempty"`
+
+	// Hardware describes the hardware of the device, as advertised by its plan.
+	// +optional
+	Hardware *HardwareStatus `json:"hardware,omitempty"`
+
+	// BGPSessions are the BGP sessions of the device, for clusters using kube-vip.
+	// +optional
+	BGPSessions []BGPSession `json:"bgpSessions,omitempty"`
+
+	// DeviceRetries is how many devices of the machine failed to provision and were created again, see
+	// spec.failedDeviceRetries.
+	// +optional
+	DeviceRetries int32 `json:"deviceRetries,omitempty"`
+
+	// HardwareReservationID is the ID of the hardware reservation the device was created on, if any.
+	// +optional
+	HardwareReservationID string `json:"hardwareReservationID,omitempty"`
+
+	// LoadBalancerOrigin is the origin of the device in its pool of the API server on the load balancer of the
+	// cluster, for control plane machines of clusters with the EMLB VIP manager.
+	// +optional
+	LoadBalancerOrigin *LoadBalancerOrigin `json:"loadBalancerOrigin,omitempty"`
+
+	// LoadBalancerPoolOrigins are the origins of the device in the load balancer pools of the cluster it belongs to.
+	// +listType=map
+	// +listMapKey=pool
+	// +optional
+	LoadBalancerPoolOrigins []LoadBalancerPoolOrigin `json:"loadBalancerPoolOrigins,omitempty"`
+
 	// Any transient errors that occur during the reconciliation of Machines
 	// can be added as events to the Machine object and/or logged in the
 	// controller's output.
@@ -136,7 +610,8 @@ type PacketMachineStatus struct {
 // +kubebuilder:resource:path=packetmachines,shortName=pma,scope=Namespaced,categories=cluster-api
 // +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this PacketMachine belongs"
-// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.instanceState",description="Packet instance state"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.instanceStatus",description="Packet instance state"
+// +kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=".status.provisioningPercentage",description="Provisioning progress of the device, in percent",priority=1
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="Machine ready status"
 // +kubebuilder:printcolumn:name="InstanceID",type="string",JSONPath=".spec.providerID",description="Packet instance ID"
 // +kubebuilder:printcolumn:name="Machine",type="string",JSONPath=".metadata.ownerReferences[?(@.kind==\"Machine\")].name",description="Machine object which owns with this PacketMachine"
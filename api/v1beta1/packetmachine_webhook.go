@@ -17,7 +17,11 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
 
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -42,9 +46,44 @@ func (m *PacketMachine) SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
 func (m *PacketMachine) ValidateCreate() (admission.Warnings, error) {
-	machineLog.Info("PacketMachine.ValidateCreate called (not implemented)", "name", m.Name)
+	machineLog.Info("validate create", "name", m.Name)
+	var allErrs field.ErrorList
 
-	return nil, nil
+	allErrs = append(allErrs, validateSpecTemplates(m.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateAlwaysPXE(m.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateCustomImage(m.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateOSHardening(m.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateIPAddresses(m.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateSpotMarket(m.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateReservationSelector(m.Spec, field.NewPath("spec"))...)
+
+	if m.Spec.ReservationPool != "" && m.Spec.HardwareReservationID != "" {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "reservationPool"),
+				m.Spec.ReservationPool, "reservationPool and hardwareReservationID are mutually exclusive"),
+		)
+	}
+
+	if m.Spec.IPXEScriptSecretRef != nil {
+		if m.Spec.IPXEUrl != "" {
+			allErrs = append(allErrs,
+				field.Forbidden(field.NewPath("spec", "ipxeScriptSecretRef"),
+					"ipxeURL and ipxeScriptSecretRef are mutually exclusive"),
+			)
+		}
+		if m.Spec.OS != "custom_ipxe" {
+			allErrs = append(allErrs,
+				field.Invalid(field.NewPath("spec", "os"),
+					m.Spec.OS, "os must be custom_ipxe when ipxeScriptSecretRef is set"),
+			)
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil, nil
+	}
+
+	return nil, apierrors.NewInvalid(GroupVersion.WithKind("PacketMachine").GroupKind(), m.Name, allErrs)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
@@ -89,10 +128,28 @@ func (m *PacketMachine) ValidateUpdate(old runtime.Object) (admission.Warnings,
 	delete(oldPacketMachineSpec, "facility")
 	delete(newPacketMachineSpec, "facility")
 
+	// allow changes to deletePolicy
+	delete(oldPacketMachineSpec, "deletePolicy")
+	delete(newPacketMachineSpec, "deletePolicy")
+
+	// allow changes to failedDeviceRetries
+	delete(oldPacketMachineSpec, "failedDeviceRetries")
+	delete(newPacketMachineSpec, "failedDeviceRetries")
+
 	// allow changes to metro
 	delete(oldPacketMachineSpec, "metro")
 	delete(newPacketMachineSpec, "metro")
 
+	// allow templated fields to be resolved
+	if oldPacketMachine, ok := old.(*PacketMachine); ok && IsTemplated(oldPacketMachine.Spec.MachineType) {
+		delete(oldPacketMachineSpec, "machineType")
+		delete(newPacketMachineSpec, "machineType")
+	}
+	if oldPacketMachine, ok := old.(*PacketMachine); ok && IsTemplated(oldPacketMachine.Spec.IPXEUrl) {
+		delete(oldPacketMachineSpec, "ipxeURL")
+		delete(newPacketMachineSpec, "ipxeURL")
+	}
+
 	if !reflect.DeepEqual(oldPacketMachineSpec, newPacketMachineSpec) {
 		allErrs = append(allErrs,
 			field.Invalid(field.NewPath("spec"),
@@ -107,6 +164,231 @@ func (m *PacketMachine) ValidateUpdate(old runtime.Object) (admission.Warnings,
 	return nil, apierrors.NewInvalid(GroupVersion.WithKind("PacketMachine").GroupKind(), m.Name, allErrs)
 }
 
+// IsTemplated reports whether a PacketMachineSpec field value is a Go template to be resolved at device creation.
+func IsTemplated(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// validateSpecTemplates checks that the templated fields of a PacketMachineSpec parse.
+func validateSpecTemplates(spec PacketMachineSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	validate := func(fldPath *field.Path, value string) {
+		if !IsTemplated(value) {
+			return
+		}
+		if _, err := template.New(fldPath.String()).Parse(value); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, value, err.Error()))
+		}
+	}
+
+	validate(path.Child("machineType"), spec.MachineType)
+	validate(path.Child("facility"), spec.Facility)
+	validate(path.Child("metro"), spec.Metro)
+	validate(path.Child("ipxeURL"), spec.IPXEUrl)
+	for i, tag := range spec.Tags {
+		validate(path.Child("tags").Index(i), tag)
+	}
+
+	return allErrs
+}
+
+// validateAlwaysPXE checks that a PacketMachineSpec asking to always boot from the network has an iPXE script to
+// boot from.
+func validateAlwaysPXE(spec PacketMachineSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !spec.AlwaysPXE {
+		return nil
+	}
+	if spec.OS != "custom_ipxe" {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("os"), spec.OS, "os must be custom_ipxe when alwaysPXE is set"),
+		)
+	}
+	if spec.IPXEUrl == "" && spec.IPXEScriptSecretRef == nil {
+		allErrs = append(allErrs,
+			field.Required(path.Child("ipxeURL"), "ipxeURL or ipxeScriptSecretRef must be set when alwaysPXE is set"),
+		)
+	}
+
+	return allErrs
+}
+
+// validateCustomImage checks that a PacketMachineSpec installing a custom image on its device does not also boot it
+// from the network.
+func validateCustomImage(spec PacketMachineSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.CustomImage == nil {
+		return nil
+	}
+	if spec.OS == "custom_ipxe" {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("os"), spec.OS, "os must be the operating system of the image when customImage is set"),
+		)
+	}
+	for _, ipxe := range []struct {
+		name string
+		set  bool
+	}{
+		{"ipxeURL", spec.IPXEUrl != ""},
+		{"ipxeScriptSecretRef", spec.IPXEScriptSecretRef != nil},
+		{"alwaysPXE", spec.AlwaysPXE},
+	} {
+		if ipxe.set {
+			allErrs = append(allErrs,
+				field.Forbidden(path.Child(ipxe.name), "customImage and "+ipxe.name+" are mutually exclusive"),
+			)
+		}
+	}
+
+	return allErrs
+}
+
+// validateOSHardening checks that the OS hardening of a PacketMachineSpec can be merged into the userdata of its
+// device, which is replaced by the iPXE script of ipxeScriptSecretRef.
+func validateOSHardening(spec PacketMachineSpec, path *field.Path) field.ErrorList {
+	if spec.OSHardening == nil || spec.IPXEScriptSecretRef == nil {
+		return nil
+	}
+	return field.ErrorList{
+		field.Forbidden(path.Child("osHardening"), "osHardening and ipxeScriptSecretRef are mutually exclusive"),
+	}
+}
+
+// validateIPAddresses checks that the address blocks a PacketMachineSpec creates its device with are ones Equinix Metal
+// accepts: at most one block per address type, of a valid size, including a private IPv4 block.
+func validateIPAddresses(spec PacketMachineSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(spec.IPAddresses) == 0 {
+		return nil
+	}
+	if spec.PrivateIPv4SubnetSize != nil {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("privateIPv4SubnetSize"),
+				"privateIPv4SubnetSize and ipAddresses are mutually exclusive, set the cidr of the private IPv4 block instead"),
+		)
+	}
+	if spec.PublicIPv4SubnetSize != nil {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("publicIPv4SubnetSize"),
+				"publicIPv4SubnetSize and ipAddresses are mutually exclusive, set the cidr of the public IPv4 block instead"),
+		)
+	}
+
+	type addressType struct {
+		family int32
+		public bool
+	}
+	seen := map[addressType]bool{}
+	for i, address := range spec.IPAddresses {
+		fldPath := path.Child("ipAddresses").Index(i)
+
+		var minCIDR, maxCIDR int32
+		switch address.AddressFamily {
+		case 4:
+			minCIDR, maxCIDR = 28, 31
+		case 6:
+			minCIDR, maxCIDR = 120, 127
+			if !address.Public {
+				allErrs = append(allErrs,
+					field.Invalid(fldPath.Child("public"), address.Public, "private blocks are only available for IPv4"),
+				)
+			}
+		default:
+			allErrs = append(allErrs,
+				field.NotSupported(fldPath.Child("addressFamily"), address.AddressFamily, []string{"4", "6"}),
+			)
+			continue
+		}
+		if address.CIDR != nil && (*address.CIDR < minCIDR || *address.CIDR > maxCIDR) {
+			allErrs = append(allErrs,
+				field.Invalid(fldPath.Child("cidr"), *address.CIDR,
+					fmt.Sprintf("must be between %d and %d for IPv%d", minCIDR, maxCIDR, address.AddressFamily)),
+			)
+		}
+
+		key := addressType{family: address.AddressFamily, public: address.Public}
+		if seen[key] {
+			allErrs = append(allErrs, field.Duplicate(fldPath, address))
+		}
+		seen[key] = true
+	}
+	if !seen[addressType{family: 4}] {
+		allErrs = append(allErrs,
+			field.Required(path.Child("ipAddresses"), "a private IPv4 block is required"),
+		)
+	}
+
+	return allErrs
+}
+
+// validateSpotMarket checks that a PacketMachineSpec creating a spot market instance bids a positive price and does not
+// use hardware reservations, which are billed whether they are used or not.
+func validateSpotMarket(spec PacketMachineSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.SpotPriceMax == "" {
+		return nil
+	}
+	if price, err := strconv.ParseFloat(spec.SpotPriceMax, 32); err != nil || price <= 0 {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("spotPriceMax"), spec.SpotPriceMax, "must be a positive price in US dollars per hour"),
+		)
+	}
+	for _, reservation := range []struct {
+		name  string
+		value string
+	}{
+		{"hardwareReservationID", spec.HardwareReservationID},
+		{"reservationPool", spec.ReservationPool},
+		{"deviceClaimName", spec.DeviceClaimName},
+	} {
+		if reservation.value != "" {
+			allErrs = append(allErrs,
+				field.Forbidden(path.Child(reservation.name), "spot market instances cannot use hardware reservations"),
+			)
+		}
+	}
+	if spec.ReservationSelector != nil {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("reservationSelector"), "spot market instances cannot use hardware reservations"),
+		)
+	}
+
+	return allErrs
+}
+
+// validateReservationSelector checks that a PacketMachineSpec selecting its hardware reservation by criteria does not
+// also list reservations or a reservation pool.
+func validateReservationSelector(spec PacketMachineSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	selector := spec.ReservationSelector
+	if selector == nil {
+		return nil
+	}
+	if spec.HardwareReservationID != "" {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("reservationSelector"), "reservationSelector and hardwareReservationID are mutually exclusive"),
+		)
+	}
+	if spec.ReservationPool != "" {
+		allErrs = append(allErrs,
+			field.Forbidden(path.Child("reservationSelector"), "reservationSelector and reservationPool are mutually exclusive"),
+		)
+	}
+	if selector.Metro != "" && selector.Facility != "" {
+		allErrs = append(allErrs,
+			field.Invalid(path.Child("reservationSelector", "facility"), selector.Facility, "metro and facility are mutually exclusive"),
+		)
+	}
+
+	return allErrs
+}
+
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
 func (m *PacketMachine) ValidateDelete() (admission.Warnings, error) {
 	machineLog.Info("PacketMachine.ValidateDelete called (not implemented)", "name", m.Name)
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+)
+
+const (
+	// MachinePoolFinalizer allows the PacketMachinePool controller to delete the devices of a PacketMachinePool
+	// before removing it from the apiserver.
+	MachinePoolFinalizer = "packetmachinepool.infrastructure.cluster.x-k8s.io"
+
+	// DevicesReadyCondition reports on whether the pool has as many running devices as its MachinePool has replicas.
+	DevicesReadyCondition clusterv1.ConditionType = "DevicesReady"
+
+	// ScalingUpReason used while devices are being created for the pool.
+	ScalingUpReason = "ScalingUp"
+	// ScalingDownReason used while devices of the pool are being deleted.
+	ScalingDownReason = "ScalingDown"
+)
+
+// PacketMachinePoolDeviceSpec is the spec the devices of a PacketMachinePool are created with.
+type PacketMachinePoolDeviceSpec struct {
+	OS           string                              `json:"os"`
+	BillingCycle metal.DeviceCreateInputBillingCycle `json:"billingCycle,omitempty"`
+	MachineType  string                              `json:"machineType"`
+
+	// Facility represents the Equinix Metal facility of the devices.
+	// Override from the PacketCluster spec.
+	// +optional
+	Facility string `json:"facility,omitempty"`
+
+	// Metro represents the Equinix Metal metro of the devices.
+	// Override from the PacketCluster spec.
+	// +optional
+	Metro string `json:"metro,omitempty"`
+
+	// IPXEUrl can be used to set the pxe boot url when using custom OSes with this provider.
+	// Note that OS should also be set to "custom_ipxe" if using this value.
+	// +optional
+	IPXEUrl string `json:"ipxeURL,omitempty"`
+
+	// AlwaysPXE makes the devices boot from the network on every boot rather than only on the first one. OS must be
+	// set to "custom_ipxe", with IPXEUrl.
+	// +optional
+	AlwaysPXE bool `json:"alwaysPXE,omitempty"`
+
+	// Tags is an optional set of tags to add to the devices.
+	// +optional
+	Tags Tags `json:"tags,omitempty"`
+}
+
+// PacketMachinePoolSpec defines the desired state of PacketMachinePool.
+type PacketMachinePoolSpec struct {
+	// Template is the spec of the devices of the pool. Changing it only affects the devices created afterwards.
+	Template PacketMachinePoolDeviceSpec `json:"template"`
+
+	// ProviderIDList are the providerIDs of the devices of the pool, as the MachinePool expects them.
+	// +optional
+	ProviderIDList []string `json:"providerIDList,omitempty"`
+}
+
+// PacketMachinePoolInstance describes a device of a PacketMachinePool.
+type PacketMachinePoolInstance struct {
+	// ProviderID of the device.
+	ProviderID string `json:"providerID"`
+
+	// Hostname of the device.
+	Hostname string `json:"hostname"`
+
+	// InstanceStatus is the status of the device.
+	// +optional
+	InstanceStatus PacketResourceStatus `json:"instanceStatus,omitempty"`
+
+	// Addresses of the device.
+	// +optional
+	Addresses []corev1.NodeAddress `json:"addresses,omitempty"`
+}
+
+// PacketMachinePoolStatus defines the observed state of PacketMachinePool.
+type PacketMachinePoolStatus struct {
+	// Ready is true when the pool has as many running devices as its MachinePool has replicas.
+	// +optional
+	Ready bool `json:"ready"`
+
+	// Replicas is the number of running devices of the pool.
+	// +optional
+	Replicas int32 `json:"replicas"`
+
+	// Instances are the devices of the pool.
+	// +optional
+	Instances []PacketMachinePoolInstance `json:"instances,omitempty"`
+
+	// FailureReason will be set in the event that there is a terminal problem reconciling the pool.
+	// +optional
+	FailureReason *capierrors.MachineStatusError `json:"failureReason,omitempty"`
+
+	// FailureMessage will be set in the event that there is a terminal problem reconciling the pool and will
+	// contain a more verbose string suitable for logging and human consumption.
+	// +optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// Conditions defines current service state of the PacketMachinePool.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:subresource:status
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=packetmachinepools,shortName=pmp,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this PacketMachinePool belongs"
+// +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.replicas",description="Running devices of the pool"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="Machine pool ready status"
+// +kubebuilder:printcolumn:name="MachinePool",type="string",JSONPath=".metadata.ownerReferences[?(@.kind==\"MachinePool\")].name",description="MachinePool object which owns with this PacketMachinePool"
+
+// PacketMachinePool is the Schema for the packetmachinepools API. It is experimental.
+type PacketMachinePool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PacketMachinePoolSpec   `json:"spec,omitempty"`
+	Status PacketMachinePoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PacketMachinePoolList contains a list of PacketMachinePool.
+type PacketMachinePoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PacketMachinePool `json:"items"`
+}
+
+// GetConditions returns the list of conditions for an PacketMachinePool API object.
+func (m *PacketMachinePool) GetConditions() clusterv1.Conditions {
+	return m.Status.Conditions
+}
+
+// SetConditions will set the given conditions on an PacketMachinePool object.
+func (m *PacketMachinePool) SetConditions(conditions clusterv1.Conditions) {
+	m.Status.Conditions = conditions
+}
+
+func init() {
+	objectTypes = append(objectTypes, &PacketMachinePool{}, &PacketMachinePoolList{})
+}
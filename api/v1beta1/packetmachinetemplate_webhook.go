@@ -17,7 +17,9 @@ limitations under the License.
 package v1beta1
 
 import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -37,16 +39,31 @@ func (m *PacketMachineTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
 func (m *PacketMachineTemplate) ValidateCreate() (admission.Warnings, error) {
-	machineTemplateLog.Info("PacketMachineTemplate.ValidateCreate called (not implemented)", "name", m.Name)
+	machineTemplateLog.Info("validate create", "name", m.Name)
 
-	return nil, nil
+	return nil, m.validate()
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
 func (m *PacketMachineTemplate) ValidateUpdate(_ runtime.Object) (admission.Warnings, error) {
-	machineTemplateLog.Info("PacketMachineTemplate.ValidateUpdate called (not implemented)", "name", m.Name)
+	machineTemplateLog.Info("validate update", "name", m.Name)
 
-	return nil, nil
+	return nil, m.validate()
+}
+
+func (m *PacketMachineTemplate) validate() error {
+	allErrs := validateSpecTemplates(m.Spec.Template.Spec, field.NewPath("spec", "template", "spec"))
+	allErrs = append(allErrs, validateAlwaysPXE(m.Spec.Template.Spec, field.NewPath("spec", "template", "spec"))...)
+	allErrs = append(allErrs, validateCustomImage(m.Spec.Template.Spec, field.NewPath("spec", "template", "spec"))...)
+	allErrs = append(allErrs, validateOSHardening(m.Spec.Template.Spec, field.NewPath("spec", "template", "spec"))...)
+	allErrs = append(allErrs, validateIPAddresses(m.Spec.Template.Spec, field.NewPath("spec", "template", "spec"))...)
+	allErrs = append(allErrs, validateSpotMarket(m.Spec.Template.Spec, field.NewPath("spec", "template", "spec"))...)
+	allErrs = append(allErrs, validateReservationSelector(m.Spec.Template.Spec, field.NewPath("spec", "template", "spec"))...)
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("PacketMachineTemplate").GroupKind(), m.Name, allErrs)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
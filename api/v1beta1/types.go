@@ -16,6 +16,10 @@ limitations under the License.
 
 package v1beta1
 
+import (
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
 // PacketResourceStatus describes the status of a Packet resource.
 type PacketResourceStatus string
 
@@ -35,8 +39,30 @@ var (
 	PacketResourceStatusRunning = PacketResourceStatus("active")
 	// PacketResourceStatusErrored represents a Packet resource in a errored state.
 	PacketResourceStatusErrored = PacketResourceStatus("errored")
+	// PacketResourceStatusFailed represents a device that failed to provision.
+	PacketResourceStatusFailed = PacketResourceStatus("failed")
 	// PacketResourceStatusOff represents a Packet resource in off state.
 	PacketResourceStatusOff = PacketResourceStatus("off")
+	// PacketResourceStatusInactive represents a device that is powered off.
+	PacketResourceStatusInactive = PacketResourceStatus("inactive")
+	// PacketResourceStatusPoweringOff represents a device that is being powered off.
+	PacketResourceStatusPoweringOff = PacketResourceStatus("powering_off")
+	// PacketResourceStatusPoweringOn represents a device that is being powered on.
+	PacketResourceStatusPoweringOn = PacketResourceStatus("powering_on")
+)
+
+// DeletePolicy describes how devices are deleted.
+type DeletePolicy string
+
+const (
+	// DeletePolicyGraceful deletes devices without forcing, letting Equinix Metal finish any in-flight deprovisioning
+	// steps such as secure erase. Deletion is retried until it is accepted.
+	DeletePolicyGraceful = DeletePolicy("Graceful")
+	// DeletePolicyForce force deletes devices, whatever their state.
+	DeletePolicyForce = DeletePolicy("Force")
+	// DeletePolicyForceAfterTimeout deletes devices gracefully and falls back to force deletion when the device could
+	// not be deleted within 10 minutes of the deletion request.
+	DeletePolicyForceAfterTimeout = DeletePolicy("ForceAfterTimeout")
 )
 
 // Tags defines a slice of tags.
@@ -44,6 +70,11 @@ type Tags []string
 
 // PacketMachineTemplateResource describes the data needed to create am PacketMachine from a template.
 type PacketMachineTemplateResource struct {
+	// Standard object's metadata, applied to the PacketMachines created from the template.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	ObjectMeta clusterv1.ObjectMeta `json:"metadata,omitempty"`
+
 	// Spec is the specification of the desired behavior of the machine.
 	Spec PacketMachineSpec `json:"spec"`
 }
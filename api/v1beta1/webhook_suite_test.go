@@ -0,0 +1,520 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// k8sClient talks to the API server of the test environment, which sends the PacketCluster, PacketMachine and
+// template objects it admits through the webhooks of this package. It is nil when the test environment binaries are
+// not installed.
+var k8sClient client.Client
+
+func TestMain(m *testing.M) {
+	os.Exit(runWithTestEnv(m))
+}
+
+// runWithTestEnv runs the tests of the package against an API server started with the CRDs and webhook
+// configurations of config/, when the binaries of the test environment are installed.
+func runWithTestEnv(m *testing.M) int {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" && os.Getenv("TEST_ASSET_KUBE_APISERVER") == "" {
+		return m.Run()
+	}
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.Join("..", "..", "config", "webhook")},
+		},
+	}
+	cfg, err := testEnv.Start()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start test environment: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if err := testEnv.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to stop test environment: %v\n", err)
+		}
+	}()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = AddToScheme(scheme)
+
+	webhookOptions := &testEnv.WebhookInstallOptions
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    webhookOptions.LocalServingHost,
+			Port:    webhookOptions.LocalServingPort,
+			CertDir: webhookOptions.LocalServingCertDir,
+		}),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create manager: %v\n", err)
+		return 1
+	}
+	for _, setup := range []func(ctrl.Manager) error{
+		(&PacketCluster{}).SetupWebhookWithManager,
+		(&PacketClusterTemplate{}).SetupWebhookWithManager,
+		(&PacketMachine{}).SetupWebhookWithManager,
+		(&PacketMachineTemplate{}).SetupWebhookWithManager,
+	} {
+		if err := setup(mgr); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set up webhook: %v\n", err)
+			return 1
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to start manager: %v\n", err)
+		}
+	}()
+
+	// The API server rejects every admission request until the webhook server serves.
+	address := net.JoinHostPort(webhookOptions.LocalServingHost, fmt.Sprint(webhookOptions.LocalServingPort))
+	dialer := &net.Dialer{Timeout: time.Second}
+	if err := waitFor(10*time.Second, func() bool {
+		conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{InsecureSkipVerify: true}) //nolint:gosec
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "webhook server did not start: %v\n", err)
+		return 1
+	}
+
+	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create client: %v\n", err)
+		return 1
+	}
+
+	return m.Run()
+}
+
+func waitFor(timeout time.Duration, condition func() bool) error {
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("condition not met after %s", timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
+
+// testNamespace skips tests that need the test environment when it did not start, and otherwise creates a
+// namespace for the objects of the test.
+func testNamespace(t *testing.T) string {
+	t.Helper()
+	if k8sClient == nil {
+		t.Skip("the test environment binaries are not installed, set KUBEBUILDER_ASSETS to run the webhook tests")
+	}
+
+	g := NewWithT(t)
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "webhook-test-"}}
+	g.Expect(k8sClient.Create(context.Background(), ns)).To(Succeed())
+	t.Cleanup(func() {
+		_ = k8sClient.Delete(context.Background(), ns)
+	})
+
+	return ns.Name
+}
+
+func TestPacketClusterWebhook(t *testing.T) {
+	ns := testNamespace(t)
+	ctx := context.Background()
+
+	t.Run("defaults the VIP manager", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &unstructured.Unstructured{}
+		cluster.SetGroupVersionKind(GroupVersion.WithKind("PacketCluster"))
+		cluster.SetNamespace(ns)
+		cluster.SetName("defaulted")
+		g.Expect(unstructured.SetNestedField(cluster.Object, "project", "spec", "projectID")).To(Succeed())
+		g.Expect(unstructured.SetNestedField(cluster.Object, "da", "spec", "metro")).To(Succeed())
+		g.Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+
+		created := &PacketCluster{}
+		g.Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: "defaulted"}, created)).To(Succeed())
+		g.Expect(created.Spec.VIPManager).To(Equal(VIPManagerType(CPEMID)))
+	})
+
+	t.Run("rejects a cluster without a project", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &PacketCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "no-project"},
+			Spec:       PacketClusterSpec{Metro: "da", VIPManager: CPEMID},
+		}
+		err := k8sClient.Create(ctx, cluster)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+	})
+
+	t.Run("rejects a cluster without a location", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &PacketCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "no-location"},
+			Spec:       PacketClusterSpec{ProjectID: "project", VIPManager: CPEMID},
+		}
+		err := k8sClient.Create(ctx, cluster)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+	})
+
+	t.Run("requires the host and port of a user-managed control plane endpoint", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &PacketCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "none-without-port"},
+			Spec: PacketClusterSpec{
+				ProjectID:            "project",
+				Metro:                "da",
+				VIPManager:           NONEVIPID,
+				ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "api.example.com"},
+			},
+		}
+		err := k8sClient.Create(ctx, cluster)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		cluster.Spec.ControlPlaneEndpoint.Port = 6443
+		g.Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+
+		// The endpoint cannot be cleared later on either.
+		changed := cluster.DeepCopy()
+		changed.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{}
+		err = k8sClient.Update(ctx, changed)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		changed = cluster.DeepCopy()
+		changed.Spec.ControlPlaneEndpoint.Port = 0
+		err = k8sClient.Update(ctx, changed)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+	})
+
+	t.Run("rejects a VIP reservation without an Elastic IP VIP manager", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &PacketCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "vip-reservation-emlb"},
+			Spec:       PacketClusterSpec{ProjectID: "project", Metro: "da", VIPManager: EMLBVIPID, VIPReservationID: "reservation"},
+		}
+		err := k8sClient.Create(ctx, cluster)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+	})
+
+	t.Run("validates the load balancer configuration", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &PacketCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "emlb-cpem"},
+			Spec:       PacketClusterSpec{ProjectID: "project", Metro: "da", VIPManager: CPEMID, EMLB: &EMLBConfig{Port: 443}},
+		}
+		err := k8sClient.Create(ctx, cluster)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		cluster = &PacketCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "emlb-health-check"},
+			Spec: PacketClusterSpec{
+				ProjectID:  "project",
+				Metro:      "da",
+				VIPManager: EMLBVIPID,
+				EMLB:       &EMLBConfig{HealthCheck: &EMLBHealthCheck{IntervalSeconds: 5, TimeoutSeconds: 5}},
+			},
+		}
+		err = k8sClient.Create(ctx, cluster)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		// The API server listener port is taken, the default one is free for a pool.
+		cluster = &PacketCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "emlb-port"},
+			Spec: PacketClusterSpec{
+				ProjectID:         "project",
+				Metro:             "da",
+				VIPManager:        EMLBVIPID,
+				EMLB:              &EMLBConfig{Port: 443},
+				LoadBalancerPools: []LoadBalancerPool{{Name: "ingress", Port: 443}},
+			},
+		}
+		err = k8sClient.Create(ctx, cluster)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		cluster.Spec.LoadBalancerPools[0].Port = 6443
+		g.Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+
+		changed := cluster.DeepCopy()
+		changed.Spec.EMLB.Port = 8443
+		err = k8sClient.Update(ctx, changed)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+	})
+
+	t.Run("rejects changes to immutable fields", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &PacketCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "immutable"},
+			Spec:       PacketClusterSpec{ProjectID: "project", Metro: "da", VIPManager: CPEMID},
+		}
+		g.Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+
+		changed := cluster.DeepCopy()
+		changed.Spec.ProjectID = "other-project"
+		err := k8sClient.Update(ctx, changed)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		changed = cluster.DeepCopy()
+		changed.Spec.VIPManager = KUBEVIPID
+		err = k8sClient.Update(ctx, changed)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		changed = cluster.DeepCopy()
+		changed.Spec.VIPReservationID = "reservation"
+		err = k8sClient.Update(ctx, changed)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		changed = cluster.DeepCopy()
+		changed.Spec.Metro = "sv"
+		g.Expect(k8sClient.Update(ctx, changed)).To(Succeed())
+	})
+
+	t.Run("sets the ID of a managed project once", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := &PacketCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "managed-project"},
+			Spec: PacketClusterSpec{
+				Project:    &ManagedProject{OrganizationID: "org"},
+				Metro:      "da",
+				VIPManager: CPEMID,
+			},
+		}
+		g.Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+
+		cluster.Spec.ProjectID = "created-project"
+		g.Expect(k8sClient.Update(ctx, cluster)).To(Succeed())
+
+		changed := cluster.DeepCopy()
+		changed.Spec.ProjectID = "other-project"
+		err := k8sClient.Update(ctx, changed)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		changed = cluster.DeepCopy()
+		changed.Spec.Project.OrganizationID = "other-org"
+		err = k8sClient.Update(ctx, changed)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+	})
+}
+
+func TestPacketClusterTemplateWebhook(t *testing.T) {
+	ns := testNamespace(t)
+	ctx := context.Background()
+	g := NewWithT(t)
+
+	// The metro is commonly patched in by ClusterClass variables, so a template may leave it unset.
+	template := &PacketClusterTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "template"},
+		Spec: PacketClusterTemplateSpec{Template: PacketClusterTemplateResource{
+			Spec: PacketClusterSpec{ProjectID: "project", VIPManager: CPEMID},
+		}},
+	}
+	g.Expect(k8sClient.Create(ctx, template)).To(Succeed())
+
+	invalid := &PacketClusterTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "invalid"},
+		Spec: PacketClusterTemplateSpec{Template: PacketClusterTemplateResource{
+			Spec: PacketClusterSpec{
+				ProjectID:        "project",
+				VIPManager:       CPEMID,
+				ReservationPools: []ReservationPool{{Name: "pool"}},
+			},
+		}},
+	}
+	err := k8sClient.Create(ctx, invalid)
+	g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+}
+
+func TestPacketMachineWebhook(t *testing.T) {
+	ns := testNamespace(t)
+	ctx := context.Background()
+
+	newMachine := func(name string) *PacketMachine {
+		return &PacketMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec: PacketMachineSpec{
+				OS:           "ubuntu_22_04",
+				MachineType:  "c3.small.x86",
+				BillingCycle: "hourly",
+			},
+		}
+	}
+
+	t.Run("rejects invalid machines", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			mutate func(*PacketMachine)
+		}{
+			{
+				name: "spot market with a hardware reservation",
+				mutate: func(m *PacketMachine) {
+					m.Spec.SpotPriceMax = "0.5"
+					m.Spec.HardwareReservationID = "next-available"
+				},
+			},
+			{
+				name: "reservation pool with a hardware reservation",
+				mutate: func(m *PacketMachine) {
+					m.Spec.ReservationPool = "pool"
+					m.Spec.HardwareReservationID = "next-available"
+				},
+			},
+			{
+				name: "reservation selector with a reservation pool",
+				mutate: func(m *PacketMachine) {
+					m.Spec.ReservationSelector = &HardwareReservationSelector{Plan: "c3.small.x86"}
+					m.Spec.ReservationPool = "pool"
+				},
+			},
+			{
+				name: "reservation selector with a metro and a facility",
+				mutate: func(m *PacketMachine) {
+					m.Spec.ReservationSelector = &HardwareReservationSelector{Metro: "da", Facility: "da11"}
+				},
+			},
+			{
+				name: "unparsable templated machine type",
+				mutate: func(m *PacketMachine) {
+					m.Spec.MachineType = "{{ .Machine"
+				},
+			},
+			{
+				name: "always PXE without a script",
+				mutate: func(m *PacketMachine) {
+					m.Spec.OS = "custom_ipxe"
+					m.Spec.AlwaysPXE = true
+				},
+			},
+			{
+				name: "custom image with an iPXE URL",
+				mutate: func(m *PacketMachine) {
+					m.Spec.CustomImage = &CustomImage{URL: "https://github.com/example/images.git", Tag: "0123abc"}
+					m.Spec.IPXEUrl = "https://example.com/boot.ipxe"
+				},
+			},
+			{
+				name: "os hardening with an iPXE script",
+				mutate: func(m *PacketMachine) {
+					m.Spec.OS = "custom_ipxe"
+					m.Spec.IPXEScriptSecretRef = &SecretKeyReference{Name: "ipxe", Key: "script"}
+					m.Spec.OSHardening = &OSHardening{DisableSSHPasswordAuth: true}
+				},
+			},
+			{
+				name: "ip addresses with a public IPv4 subnet size",
+				mutate: func(m *PacketMachine) {
+					m.Spec.IPAddresses = []DeviceIPAddress{{AddressFamily: 4}}
+					m.Spec.PublicIPv4SubnetSize = ptr.To[int32](29)
+				},
+			},
+		}
+
+		for i, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				g := NewWithT(t)
+
+				machine := newMachine(fmt.Sprintf("invalid-%d", i))
+				tt.mutate(machine)
+				err := k8sClient.Create(ctx, machine)
+				g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			})
+		}
+	})
+
+	t.Run("rejects changes to the device spec", func(t *testing.T) {
+		g := NewWithT(t)
+
+		machine := newMachine("immutable")
+		g.Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+		changed := machine.DeepCopy()
+		changed.Spec.OS = "ubuntu_24_04"
+		err := k8sClient.Update(ctx, changed)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		changed = machine.DeepCopy()
+		changed.Spec.MachineType = "m3.small.x86"
+		err = k8sClient.Update(ctx, changed)
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		changed = machine.DeepCopy()
+		changed.Spec.ProviderID = ptr.To("equinixmetal://device")
+		changed.Spec.Tags = Tags{"tag"}
+		g.Expect(k8sClient.Update(ctx, changed)).To(Succeed())
+	})
+}
+
+func TestPacketMachineTemplateWebhook(t *testing.T) {
+	ns := testNamespace(t)
+	ctx := context.Background()
+	g := NewWithT(t)
+
+	template := &PacketMachineTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "template"},
+		Spec: PacketMachineTemplateSpec{Template: PacketMachineTemplateResource{
+			Spec: PacketMachineSpec{OS: "ubuntu_22_04", MachineType: "c3.small.x86", BillingCycle: "hourly"},
+		}},
+	}
+	g.Expect(k8sClient.Create(ctx, template)).To(Succeed())
+
+	changed := template.DeepCopy()
+	changed.Spec.Template.Spec.SpotPriceMax = "0.5"
+	changed.Spec.Template.Spec.HardwareReservationID = "next-available"
+	err := k8sClient.Update(ctx, changed)
+	g.Expect(apierrors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+}
@@ -27,27 +27,777 @@ import (
 	"sigs.k8s.io/cluster-api/errors"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BGPSession) DeepCopyInto(out *BGPSession) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BGPSession.
+func (in *BGPSession) DeepCopy() *BGPSession {
+	if in == nil {
+		return nil
+	}
+	out := new(BGPSession)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomImage) DeepCopyInto(out *CustomImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomImage.
+func (in *CustomImage) DeepCopy() *CustomImage {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceEvent) DeepCopyInto(out *DeviceEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceEvent.
+func (in *DeviceEvent) DeepCopy() *DeviceEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceIPAddress) DeepCopyInto(out *DeviceIPAddress) {
+	*out = *in
+	if in.CIDR != nil {
+		in, out := &in.CIDR, &out.CIDR
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IPReservations != nil {
+		in, out := &in.IPReservations, &out.IPReservations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceIPAddress.
+func (in *DeviceIPAddress) DeepCopy() *DeviceIPAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceIPAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EMLBConfig) DeepCopyInto(out *EMLBConfig) {
+	*out = *in
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(EMLBHealthCheck)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EMLBConfig.
+func (in *EMLBConfig) DeepCopy() *EMLBConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EMLBConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EMLBHealthCheck) DeepCopyInto(out *EMLBHealthCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EMLBHealthCheck.
+func (in *EMLBHealthCheck) DeepCopy() *EMLBHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(EMLBHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIPStatus) DeepCopyInto(out *ElasticIPStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIPStatus.
+func (in *ElasticIPStatus) DeepCopy() *ElasticIPStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIPStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareCPU) DeepCopyInto(out *HardwareCPU) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareCPU.
+func (in *HardwareCPU) DeepCopy() *HardwareCPU {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareCPU)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareDrive) DeepCopyInto(out *HardwareDrive) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareDrive.
+func (in *HardwareDrive) DeepCopy() *HardwareDrive {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareDrive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareNIC) DeepCopyInto(out *HardwareNIC) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareNIC.
+func (in *HardwareNIC) DeepCopy() *HardwareNIC {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareNIC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareReservationSelector) DeepCopyInto(out *HardwareReservationSelector) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareReservationSelector.
+func (in *HardwareReservationSelector) DeepCopy() *HardwareReservationSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareReservationSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareStatus) DeepCopyInto(out *HardwareStatus) {
+	*out = *in
+	if in.CPUs != nil {
+		in, out := &in.CPUs, &out.CPUs
+		*out = make([]HardwareCPU, len(*in))
+		copy(*out, *in)
+	}
+	if in.Drives != nil {
+		in, out := &in.Drives, &out.Drives
+		*out = make([]HardwareDrive, len(*in))
+		copy(*out, *in)
+	}
+	if in.NICs != nil {
+		in, out := &in.NICs, &out.NICs
+		*out = make([]HardwareNIC, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareStatus.
+func (in *HardwareStatus) DeepCopy() *HardwareStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancer) DeepCopyInto(out *LoadBalancer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancer.
+func (in *LoadBalancer) DeepCopy() *LoadBalancer {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerOrigin) DeepCopyInto(out *LoadBalancerOrigin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerOrigin.
+func (in *LoadBalancerOrigin) DeepCopy() *LoadBalancerOrigin {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerOrigin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPool) DeepCopyInto(out *LoadBalancerPool) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPool.
+func (in *LoadBalancerPool) DeepCopy() *LoadBalancerPool {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPoolOrigin) DeepCopyInto(out *LoadBalancerPoolOrigin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPoolOrigin.
+func (in *LoadBalancerPoolOrigin) DeepCopy() *LoadBalancerPoolOrigin {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPoolOrigin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerPoolStatus) DeepCopyInto(out *LoadBalancerPoolStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerPoolStatus.
+func (in *LoadBalancerPoolStatus) DeepCopy() *LoadBalancerPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerStatus) DeepCopyInto(out *LoadBalancerStatus) {
+	*out = *in
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]LoadBalancerPoolStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerStatus.
+func (in *LoadBalancerStatus) DeepCopy() *LoadBalancerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedProject) DeepCopyInto(out *ManagedProject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedProject.
+func (in *ManagedProject) DeepCopy() *ManagedProject {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedProject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetalGateway) DeepCopyInto(out *MetalGateway) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetalGateway.
+func (in *MetalGateway) DeepCopy() *MetalGateway {
+	if in == nil {
+		return nil
+	}
+	out := new(MetalGateway)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetalGatewayStatus) DeepCopyInto(out *MetalGatewayStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetalGatewayStatus.
+func (in *MetalGatewayStatus) DeepCopy() *MetalGatewayStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetalGatewayStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSHardening) DeepCopyInto(out *OSHardening) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSHardening.
+func (in *OSHardening) DeepCopy() *OSHardening {
+	if in == nil {
+		return nil
+	}
+	out := new(OSHardening)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PacketCluster) DeepCopyInto(out *PacketCluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketCluster.
+func (in *PacketCluster) DeepCopy() *PacketCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketClusterList) DeepCopyInto(out *PacketClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PacketCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketClusterList.
+func (in *PacketClusterList) DeepCopy() *PacketClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketClusterSpec) DeepCopyInto(out *PacketClusterSpec) {
+	*out = *in
+	if in.CredentialsRef != nil {
+		in, out := &in.CredentialsRef, &out.CredentialsRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.Project != nil {
+		in, out := &in.Project, &out.Project
+		*out = new(ManagedProject)
+		**out = **in
+	}
+	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.ServiceIPPool != nil {
+		in, out := &in.ServiceIPPool, &out.ServiceIPPool
+		*out = new(ServiceIPPool)
+		**out = **in
+	}
+	if in.DeletionTimeoutSeconds != nil {
+		in, out := &in.DeletionTimeoutSeconds, &out.DeletionTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxDevices != nil {
+		in, out := &in.MaxDevices, &out.MaxDevices
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReservationPools != nil {
+		in, out := &in.ReservationPools, &out.ReservationPools
+		*out = make([]ReservationPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LoadBalancer != nil {
+		in, out := &in.LoadBalancer, &out.LoadBalancer
+		*out = new(LoadBalancer)
+		**out = **in
+	}
+	if in.LoadBalancerPools != nil {
+		in, out := &in.LoadBalancerPools, &out.LoadBalancerPools
+		*out = make([]LoadBalancerPool, len(*in))
+		copy(*out, *in)
+	}
+	if in.EMLB != nil {
+		in, out := &in.EMLB, &out.EMLB
+		*out = new(EMLBConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VLANs != nil {
+		in, out := &in.VLANs, &out.VLANs
+		*out = make([]VLAN, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MetalGateways != nil {
+		in, out := &in.MetalGateways, &out.MetalGateways
+		*out = make([]MetalGateway, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxConcurrentPortConversions != nil {
+		in, out := &in.MaxConcurrentPortConversions, &out.MaxConcurrentPortConversions
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketClusterSpec.
+func (in *PacketClusterSpec) DeepCopy() *PacketClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketClusterStatus) DeepCopyInto(out *PacketClusterStatus) {
+	*out = *in
+	if in.ElasticIP != nil {
+		in, out := &in.ElasticIP, &out.ElasticIP
+		*out = new(ElasticIPStatus)
+		**out = **in
+	}
+	if in.ServiceIPPool != nil {
+		in, out := &in.ServiceIPPool, &out.ServiceIPPool
+		*out = new(ServiceIPPoolStatus)
+		**out = **in
+	}
+	if in.VLANs != nil {
+		in, out := &in.VLANs, &out.VLANs
+		*out = make([]VLANStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.MetalGateways != nil {
+		in, out := &in.MetalGateways, &out.MetalGateways
+		*out = make([]MetalGatewayStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LoadBalancer != nil {
+		in, out := &in.LoadBalancer, &out.LoadBalancer
+		*out = new(LoadBalancerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(apiv1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketClusterStatus.
+func (in *PacketClusterStatus) DeepCopy() *PacketClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketClusterTemplate) DeepCopyInto(out *PacketClusterTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketClusterTemplate.
+func (in *PacketClusterTemplate) DeepCopy() *PacketClusterTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketClusterTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketClusterTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketClusterTemplateList) DeepCopyInto(out *PacketClusterTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PacketClusterTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketClusterTemplateList.
+func (in *PacketClusterTemplateList) DeepCopy() *PacketClusterTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketClusterTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketClusterTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketClusterTemplateResource) DeepCopyInto(out *PacketClusterTemplateResource) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketClusterTemplateResource.
+func (in *PacketClusterTemplateResource) DeepCopy() *PacketClusterTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketClusterTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketClusterTemplateSpec) DeepCopyInto(out *PacketClusterTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketClusterTemplateSpec.
+func (in *PacketClusterTemplateSpec) DeepCopy() *PacketClusterTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketClusterTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketDeviceClaim) DeepCopyInto(out *PacketDeviceClaim) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketDeviceClaim.
+func (in *PacketDeviceClaim) DeepCopy() *PacketDeviceClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketDeviceClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketDeviceClaim) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketDeviceClaimList) DeepCopyInto(out *PacketDeviceClaimList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PacketDeviceClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketDeviceClaimList.
+func (in *PacketDeviceClaimList) DeepCopy() *PacketDeviceClaimList {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketDeviceClaimList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketDeviceClaimList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketDeviceClaimSpec) DeepCopyInto(out *PacketDeviceClaimSpec) {
+	*out = *in
+	if in.PoolTags != nil {
+		in, out := &in.PoolTags, &out.PoolTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketDeviceClaimSpec.
+func (in *PacketDeviceClaimSpec) DeepCopy() *PacketDeviceClaimSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketDeviceClaimSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketDeviceClaimStatus) DeepCopyInto(out *PacketDeviceClaimStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(apiv1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketDeviceClaimStatus.
+func (in *PacketDeviceClaimStatus) DeepCopy() *PacketDeviceClaimStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketDeviceClaimStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketMachine) DeepCopyInto(out *PacketMachine) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketCluster.
-func (in *PacketCluster) DeepCopy() *PacketCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachine.
+func (in *PacketMachine) DeepCopy() *PacketMachine {
 	if in == nil {
 		return nil
 	}
-	out := new(PacketCluster)
+	out := new(PacketMachine)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PacketCluster) DeepCopyObject() runtime.Object {
+func (in *PacketMachine) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -55,31 +805,31 @@ func (in *PacketCluster) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PacketClusterList) DeepCopyInto(out *PacketClusterList) {
+func (in *PacketMachineList) DeepCopyInto(out *PacketMachineList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]PacketCluster, len(*in))
+		*out = make([]PacketMachine, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketClusterList.
-func (in *PacketClusterList) DeepCopy() *PacketClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachineList.
+func (in *PacketMachineList) DeepCopy() *PacketMachineList {
 	if in == nil {
 		return nil
 	}
-	out := new(PacketClusterList)
+	out := new(PacketMachineList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PacketClusterList) DeepCopyObject() runtime.Object {
+func (in *PacketMachineList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -87,102 +837,164 @@ func (in *PacketClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PacketClusterSpec) DeepCopyInto(out *PacketClusterSpec) {
+func (in *PacketMachinePool) DeepCopyInto(out *PacketMachinePool) {
 	*out = *in
-	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketClusterSpec.
-func (in *PacketClusterSpec) DeepCopy() *PacketClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachinePool.
+func (in *PacketMachinePool) DeepCopy() *PacketMachinePool {
 	if in == nil {
 		return nil
 	}
-	out := new(PacketClusterSpec)
+	out := new(PacketMachinePool)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PacketMachinePool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PacketClusterStatus) DeepCopyInto(out *PacketClusterStatus) {
+func (in *PacketMachinePoolDeviceSpec) DeepCopyInto(out *PacketMachinePoolDeviceSpec) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make(apiv1beta1.Conditions, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(Tags, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketClusterStatus.
-func (in *PacketClusterStatus) DeepCopy() *PacketClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachinePoolDeviceSpec.
+func (in *PacketMachinePoolDeviceSpec) DeepCopy() *PacketMachinePoolDeviceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PacketClusterStatus)
+	out := new(PacketMachinePoolDeviceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PacketMachine) DeepCopyInto(out *PacketMachine) {
+func (in *PacketMachinePoolInstance) DeepCopyInto(out *PacketMachinePoolInstance) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]v1.NodeAddress, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachine.
-func (in *PacketMachine) DeepCopy() *PacketMachine {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachinePoolInstance.
+func (in *PacketMachinePoolInstance) DeepCopy() *PacketMachinePoolInstance {
 	if in == nil {
 		return nil
 	}
-	out := new(PacketMachine)
+	out := new(PacketMachinePoolInstance)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PacketMachine) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PacketMachineList) DeepCopyInto(out *PacketMachineList) {
+func (in *PacketMachinePoolList) DeepCopyInto(out *PacketMachinePoolList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]PacketMachine, len(*in))
+		*out = make([]PacketMachinePool, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachineList.
-func (in *PacketMachineList) DeepCopy() *PacketMachineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachinePoolList.
+func (in *PacketMachinePoolList) DeepCopy() *PacketMachinePoolList {
 	if in == nil {
 		return nil
 	}
-	out := new(PacketMachineList)
+	out := new(PacketMachinePoolList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PacketMachineList) DeepCopyObject() runtime.Object {
+func (in *PacketMachinePoolList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketMachinePoolSpec) DeepCopyInto(out *PacketMachinePoolSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.ProviderIDList != nil {
+		in, out := &in.ProviderIDList, &out.ProviderIDList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachinePoolSpec.
+func (in *PacketMachinePoolSpec) DeepCopy() *PacketMachinePoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketMachinePoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketMachinePoolStatus) DeepCopyInto(out *PacketMachinePoolStatus) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]PacketMachinePoolInstance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FailureReason != nil {
+		in, out := &in.FailureReason, &out.FailureReason
+		*out = new(errors.MachineStatusError)
+		**out = **in
+	}
+	if in.FailureMessage != nil {
+		in, out := &in.FailureMessage, &out.FailureMessage
+		*out = new(string)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(apiv1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachinePoolStatus.
+func (in *PacketMachinePoolStatus) DeepCopy() *PacketMachinePoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketMachinePoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PacketMachineSpec) DeepCopyInto(out *PacketMachineSpec) {
 	*out = *in
@@ -191,6 +1003,69 @@ func (in *PacketMachineSpec) DeepCopyInto(out *PacketMachineSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ProjectSSHKeyIDs != nil {
+		in, out := &in.ProjectSSHKeyIDs, &out.ProjectSSHKeyIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPXEScriptSecretRef != nil {
+		in, out := &in.IPXEScriptSecretRef, &out.IPXEScriptSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.CustomImage != nil {
+		in, out := &in.CustomImage, &out.CustomImage
+		*out = new(CustomImage)
+		**out = **in
+	}
+	if in.OSHardening != nil {
+		in, out := &in.OSHardening, &out.OSHardening
+		*out = new(OSHardening)
+		**out = **in
+	}
+	if in.ReservationSelector != nil {
+		in, out := &in.ReservationSelector, &out.ReservationSelector
+		*out = new(HardwareReservationSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LoadBalancerPools != nil {
+		in, out := &in.LoadBalancerPools, &out.LoadBalancerPools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VLANs != nil {
+		in, out := &in.VLANs, &out.VLANs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AddressesFromPools != nil {
+		in, out := &in.AddressesFromPools, &out.AddressesFromPools
+		*out = make([]v1.TypedLocalObjectReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PrivateIPv4SubnetSize != nil {
+		in, out := &in.PrivateIPv4SubnetSize, &out.PrivateIPv4SubnetSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PublicIPv4SubnetSize != nil {
+		in, out := &in.PublicIPv4SubnetSize, &out.PublicIPv4SubnetSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IPAddresses != nil {
+		in, out := &in.IPAddresses, &out.IPAddresses
+		*out = make([]DeviceIPAddress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TerminationTime != nil {
+		in, out := &in.TerminationTime, &out.TerminationTime
+		*out = (*in).DeepCopy()
+	}
 	if in.ProviderID != nil {
 		in, out := &in.ProviderID, &out.ProviderID
 		*out = new(string)
@@ -201,6 +1076,11 @@ func (in *PacketMachineSpec) DeepCopyInto(out *PacketMachineSpec) {
 		*out = make(Tags, len(*in))
 		copy(*out, *in)
 	}
+	if in.DeletionTimeoutSeconds != nil {
+		in, out := &in.DeletionTimeoutSeconds, &out.DeletionTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketMachineSpec.
@@ -226,6 +1106,38 @@ func (in *PacketMachineStatus) DeepCopyInto(out *PacketMachineStatus) {
 		*out = new(PacketResourceStatus)
 		**out = **in
 	}
+	if in.ProvisioningPercentage != nil {
+		in, out := &in.ProvisioningPercentage, &out.ProvisioningPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastEvent != nil {
+		in, out := &in.LastEvent, &out.LastEvent
+		*out = new(DeviceEvent)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hardware != nil {
+		in, out := &in.Hardware, &out.Hardware
+		*out = new(HardwareStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BGPSessions != nil {
+		in, out := &in.BGPSessions, &out.BGPSessions
+		*out = make([]BGPSession, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LoadBalancerOrigin != nil {
+		in, out := &in.LoadBalancerOrigin, &out.LoadBalancerOrigin
+		*out = new(LoadBalancerOrigin)
+		**out = **in
+	}
+	if in.LoadBalancerPoolOrigins != nil {
+		in, out := &in.LoadBalancerPoolOrigins, &out.LoadBalancerPoolOrigins
+		*out = make([]LoadBalancerPoolOrigin, len(*in))
+		copy(*out, *in)
+	}
 	if in.FailureReason != nil {
 		in, out := &in.FailureReason, &out.FailureReason
 		*out = new(errors.MachineStatusError)
@@ -316,6 +1228,7 @@ func (in *PacketMachineTemplateList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PacketMachineTemplateResource) DeepCopyInto(out *PacketMachineTemplateResource) {
 	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 }
 
@@ -345,6 +1258,71 @@ func (in *PacketMachineTemplateSpec) DeepCopy() *PacketMachineTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservationPool) DeepCopyInto(out *ReservationPool) {
+	*out = *in
+	if in.ReservationIDs != nil {
+		in, out := &in.ReservationIDs, &out.ReservationIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationPool.
+func (in *ReservationPool) DeepCopy() *ReservationPool {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservationPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceIPPool) DeepCopyInto(out *ServiceIPPool) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceIPPool.
+func (in *ServiceIPPool) DeepCopy() *ServiceIPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceIPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceIPPoolStatus) DeepCopyInto(out *ServiceIPPoolStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceIPPoolStatus.
+func (in *ServiceIPPoolStatus) DeepCopy() *ServiceIPPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceIPPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Tags) DeepCopyInto(out *Tags) {
 	{
@@ -363,3 +1341,38 @@ func (in Tags) DeepCopy() Tags {
 	in.DeepCopyInto(out)
 	return *out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VLAN) DeepCopyInto(out *VLAN) {
+	*out = *in
+	if in.VXLAN != nil {
+		in, out := &in.VXLAN, &out.VXLAN
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLAN.
+func (in *VLAN) DeepCopy() *VLAN {
+	if in == nil {
+		return nil
+	}
+	out := new(VLAN)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VLANStatus) DeepCopyInto(out *VLANStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLANStatus.
+func (in *VLANStatus) DeepCopy() *VLANStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VLANStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,480 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// nextAvailableReservation is the hardware reservation ID v1beta1 uses to let Equinix Metal pick a reservation.
+const nextAvailableReservation = "next-available"
+
+func convertPacketClusterSpecToHub(in *PacketClusterSpec, out *infrav1.PacketClusterSpec) {
+	out.ProjectID = in.ProjectID
+	if in.CredentialsRef != nil {
+		out.CredentialsRef = &infrav1.SecretKeyReference{Name: in.CredentialsRef.Name, Key: in.CredentialsRef.Key}
+	}
+	if in.Project != nil {
+		out.Project = &infrav1.ManagedProject{OrganizationID: in.Project.OrganizationID, Name: in.Project.Name}
+	}
+	out.Facility = in.Placement.Facility
+	out.Metro = in.Placement.Metro
+	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	out.VIPManager = infrav1.VIPManagerType(in.VIPManager)
+	out.ElasticIPReclaimPolicy = infrav1.ElasticIPReclaimPolicy(in.ElasticIPReclaimPolicy)
+	out.VIPReservationID = in.VIPReservationID
+	if in.ServiceIPPool != nil {
+		out.ServiceIPPool = &infrav1.ServiceIPPool{Size: in.ServiceIPPool.Size}
+	}
+	out.BGPPeerAnnotations = in.BGPPeerAnnotations
+	out.Hibernate = in.Hibernate
+	out.DeletePolicy = infrav1.DeletePolicy(in.DeletePolicy)
+	out.DeletionTimeoutSeconds = copyInt32(in.DeletionTimeoutSeconds)
+	out.MaxDevices = copyInt32(in.MaxDevices)
+	if in.ReservationPools != nil {
+		out.ReservationPools = make([]infrav1.ReservationPool, len(in.ReservationPools))
+		for i, pool := range in.ReservationPools {
+			out.ReservationPools[i] = infrav1.ReservationPool{
+				Name:           pool.Name,
+				ReservationIDs: copyStrings(pool.ReservationIDs),
+				Plan:           pool.Plan,
+			}
+		}
+	}
+	if in.LoadBalancer != nil {
+		out.LoadBalancer = &infrav1.LoadBalancer{ExistingID: in.LoadBalancer.ExistingID, ExistingName: in.LoadBalancer.ExistingName}
+	}
+	if in.LoadBalancerPools != nil {
+		out.LoadBalancerPools = make([]infrav1.LoadBalancerPool, len(in.LoadBalancerPools))
+		for i, pool := range in.LoadBalancerPools {
+			out.LoadBalancerPools[i] = infrav1.LoadBalancerPool(pool)
+		}
+	}
+	if in.EMLB != nil {
+		out.EMLB = &infrav1.EMLBConfig{Port: in.EMLB.Port, PoolNamePrefix: in.EMLB.PoolNamePrefix, LocationID: in.EMLB.LocationID}
+		if in.EMLB.HealthCheck != nil {
+			out.EMLB.HealthCheck = &infrav1.EMLBHealthCheck{IntervalSeconds: in.EMLB.HealthCheck.IntervalSeconds, TimeoutSeconds: in.EMLB.HealthCheck.TimeoutSeconds}
+		}
+	}
+	if in.VLANs != nil {
+		out.VLANs = make([]infrav1.VLAN, len(in.VLANs))
+		for i, vlan := range in.VLANs {
+			out.VLANs[i] = infrav1.VLAN{Name: vlan.Name, Metro: vlan.Metro, Description: vlan.Description, VXLAN: copyInt32(vlan.VXLAN)}
+		}
+	}
+	if in.MetalGateways != nil {
+		out.MetalGateways = make([]infrav1.MetalGateway, len(in.MetalGateways))
+		for i, gateway := range in.MetalGateways {
+			out.MetalGateways[i] = infrav1.MetalGateway(gateway)
+		}
+	}
+	out.MaxConcurrentPortConversions = copyInt32(in.MaxConcurrentPortConversions)
+}
+
+func convertPacketClusterSpecFromHub(in *infrav1.PacketClusterSpec, out *PacketClusterSpec) {
+	out.ProjectID = in.ProjectID
+	if in.CredentialsRef != nil {
+		out.CredentialsRef = &SecretKeyReference{Name: in.CredentialsRef.Name, Key: in.CredentialsRef.Key}
+	}
+	if in.Project != nil {
+		out.Project = &ManagedProject{OrganizationID: in.Project.OrganizationID, Name: in.Project.Name}
+	}
+	out.Placement = Placement{Metro: in.Metro, Facility: in.Facility}
+	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	out.VIPManager = VIPManagerType(in.VIPManager)
+	out.ElasticIPReclaimPolicy = ElasticIPReclaimPolicy(in.ElasticIPReclaimPolicy)
+	out.VIPReservationID = in.VIPReservationID
+	if in.ServiceIPPool != nil {
+		out.ServiceIPPool = &ServiceIPPool{Size: in.ServiceIPPool.Size}
+	}
+	out.BGPPeerAnnotations = in.BGPPeerAnnotations
+	out.Hibernate = in.Hibernate
+	out.DeletePolicy = DeletePolicy(in.DeletePolicy)
+	out.DeletionTimeoutSeconds = copyInt32(in.DeletionTimeoutSeconds)
+	out.MaxDevices = copyInt32(in.MaxDevices)
+	if in.ReservationPools != nil {
+		out.ReservationPools = make([]ReservationPool, len(in.ReservationPools))
+		for i, pool := range in.ReservationPools {
+			out.ReservationPools[i] = ReservationPool{
+				Name:           pool.Name,
+				ReservationIDs: copyStrings(pool.ReservationIDs),
+				Plan:           pool.Plan,
+			}
+		}
+	}
+	if in.LoadBalancer != nil {
+		out.LoadBalancer = &LoadBalancer{ExistingID: in.LoadBalancer.ExistingID, ExistingName: in.LoadBalancer.ExistingName}
+	}
+	if in.LoadBalancerPools != nil {
+		out.LoadBalancerPools = make([]LoadBalancerPool, len(in.LoadBalancerPools))
+		for i, pool := range in.LoadBalancerPools {
+			out.LoadBalancerPools[i] = LoadBalancerPool(pool)
+		}
+	}
+	if in.EMLB != nil {
+		out.EMLB = &EMLBConfig{Port: in.EMLB.Port, PoolNamePrefix: in.EMLB.PoolNamePrefix, LocationID: in.EMLB.LocationID}
+		if in.EMLB.HealthCheck != nil {
+			out.EMLB.HealthCheck = &EMLBHealthCheck{IntervalSeconds: in.EMLB.HealthCheck.IntervalSeconds, TimeoutSeconds: in.EMLB.HealthCheck.TimeoutSeconds}
+		}
+	}
+	if in.VLANs != nil {
+		out.VLANs = make([]VLAN, len(in.VLANs))
+		for i, vlan := range in.VLANs {
+			out.VLANs[i] = VLAN{Name: vlan.Name, Metro: vlan.Metro, Description: vlan.Description, VXLAN: copyInt32(vlan.VXLAN)}
+		}
+	}
+	if in.MetalGateways != nil {
+		out.MetalGateways = make([]MetalGateway, len(in.MetalGateways))
+		for i, gateway := range in.MetalGateways {
+			out.MetalGateways[i] = MetalGateway(gateway)
+		}
+	}
+	out.MaxConcurrentPortConversions = copyInt32(in.MaxConcurrentPortConversions)
+}
+
+func convertPacketClusterStatusToHub(in *PacketClusterStatus, out *infrav1.PacketClusterStatus) {
+	out.Ready = in.Ready
+	if in.ElasticIP != nil {
+		out.ElasticIP = &infrav1.ElasticIPStatus{ReservationID: in.ElasticIP.ReservationID, Address: in.ElasticIP.Address}
+	}
+	if in.ServiceIPPool != nil {
+		out.ServiceIPPool = &infrav1.ServiceIPPoolStatus{ReservationID: in.ServiceIPPool.ReservationID, CIDR: in.ServiceIPPool.CIDR}
+	}
+	if in.VLANs != nil {
+		out.VLANs = make([]infrav1.VLANStatus, len(in.VLANs))
+		for i, vlan := range in.VLANs {
+			out.VLANs[i] = infrav1.VLANStatus(vlan)
+		}
+	}
+	if in.MetalGateways != nil {
+		out.MetalGateways = make([]infrav1.MetalGatewayStatus, len(in.MetalGateways))
+		for i, gateway := range in.MetalGateways {
+			out.MetalGateways[i] = infrav1.MetalGatewayStatus(gateway)
+		}
+	}
+	if in.LoadBalancer != nil {
+		out.LoadBalancer = &infrav1.LoadBalancerStatus{
+			ID:         in.LoadBalancer.ID,
+			PortID:     in.LoadBalancer.PortID,
+			PortNumber: in.LoadBalancer.PortNumber,
+			Metro:      in.LoadBalancer.Metro,
+		}
+		for _, pool := range in.LoadBalancer.Pools {
+			out.LoadBalancer.Pools = append(out.LoadBalancer.Pools, infrav1.LoadBalancerPoolStatus(pool))
+		}
+	}
+	out.Conditions = in.Conditions
+}
+
+func convertPacketClusterStatusFromHub(in *infrav1.PacketClusterStatus, out *PacketClusterStatus) {
+	out.Ready = in.Ready
+	if in.ElasticIP != nil {
+		out.ElasticIP = &ElasticIPStatus{ReservationID: in.ElasticIP.ReservationID, Address: in.ElasticIP.Address}
+	}
+	if in.ServiceIPPool != nil {
+		out.ServiceIPPool = &ServiceIPPoolStatus{ReservationID: in.ServiceIPPool.ReservationID, CIDR: in.ServiceIPPool.CIDR}
+	}
+	if in.VLANs != nil {
+		out.VLANs = make([]VLANStatus, len(in.VLANs))
+		for i, vlan := range in.VLANs {
+			out.VLANs[i] = VLANStatus(vlan)
+		}
+	}
+	if in.MetalGateways != nil {
+		out.MetalGateways = make([]MetalGatewayStatus, len(in.MetalGateways))
+		for i, gateway := range in.MetalGateways {
+			out.MetalGateways[i] = MetalGatewayStatus(gateway)
+		}
+	}
+	if in.LoadBalancer != nil {
+		out.LoadBalancer = &LoadBalancerStatus{
+			ID:         in.LoadBalancer.ID,
+			PortID:     in.LoadBalancer.PortID,
+			PortNumber: in.LoadBalancer.PortNumber,
+			Metro:      in.LoadBalancer.Metro,
+		}
+		for _, pool := range in.LoadBalancer.Pools {
+			out.LoadBalancer.Pools = append(out.LoadBalancer.Pools, LoadBalancerPoolStatus(pool))
+		}
+	}
+	out.Conditions = in.Conditions
+}
+
+func convertPacketMachineSpecToHub(in *PacketMachineSpec, out *infrav1.PacketMachineSpec) {
+	out.OS = in.OS
+	out.BillingCycle = in.BillingCycle
+	out.MachineType = in.MachineType
+	out.SSHKeys = copyStrings(in.SSHKeys)
+	out.ProjectSSHKeyIDs = copyStrings(in.ProjectSSHKeyIDs)
+	out.Facility = in.Placement.Facility
+	out.Metro = in.Placement.Metro
+	out.IPXEUrl = in.IPXEUrl
+	if in.IPXEScriptSecretRef != nil {
+		out.IPXEScriptSecretRef = &infrav1.SecretKeyReference{Name: in.IPXEScriptSecretRef.Name, Key: in.IPXEScriptSecretRef.Key}
+	}
+	out.AlwaysPXE = in.AlwaysPXE
+	if in.CustomImage != nil {
+		out.CustomImage = &infrav1.CustomImage{URL: in.CustomImage.URL, Tag: in.CustomImage.Tag}
+	}
+	if in.OSHardening != nil {
+		out.OSHardening = &infrav1.OSHardening{DisableSSHPasswordAuth: in.OSHardening.DisableSSHPasswordAuth, KernelHardening: in.OSHardening.KernelHardening}
+	}
+	reservationIDs := copyStrings(in.HardwareReservation.IDs)
+	if in.HardwareReservation.NextAvailable {
+		reservationIDs = append(reservationIDs, nextAvailableReservation)
+	}
+	out.HardwareReservationID = strings.Join(reservationIDs, ",")
+	out.ReservationPool = in.HardwareReservation.Pool
+	if selector := in.HardwareReservation.Selector; selector != nil {
+		out.ReservationSelector = &infrav1.HardwareReservationSelector{
+			Plan:          selector.Plan,
+			Metro:         selector.Placement.Metro,
+			Facility:      selector.Placement.Facility,
+			Tags:          copyStrings(selector.Tags),
+			NextAvailable: selector.NextAvailable,
+		}
+	}
+	out.DeviceClaimName = in.DeviceClaimName
+	out.LoadBalancerPools = copyStrings(in.LoadBalancerPools)
+	out.VLANs = copyStrings(in.VLANs)
+	out.AddressesFromPools = copyTypedLocalObjectReferences(in.AddressesFromPools)
+	out.PrivateIPv4SubnetSize = copyInt32(in.PrivateIPv4SubnetSize)
+	out.PublicIPv4SubnetSize = copyInt32(in.PublicIPv4SubnetSize)
+	if in.IPAddresses != nil {
+		out.IPAddresses = make([]infrav1.DeviceIPAddress, len(in.IPAddresses))
+		for i, address := range in.IPAddresses {
+			out.IPAddresses[i] = infrav1.DeviceIPAddress{
+				AddressFamily:  address.AddressFamily,
+				Public:         address.Public,
+				CIDR:           copyInt32(address.CIDR),
+				IPReservations: copyStrings(address.IPReservations),
+			}
+		}
+	}
+	out.SpotPriceMax = in.SpotPriceMax
+	out.TerminationTime = in.TerminationTime.DeepCopy()
+	if in.ProviderID != nil {
+		providerID := *in.ProviderID
+		out.ProviderID = &providerID
+	}
+	out.Tags = infrav1.Tags(copyStrings(in.Tags))
+	out.DeletePolicy = infrav1.DeletePolicy(in.DeletePolicy)
+	out.DeletionTimeoutSeconds = copyInt32(in.DeletionTimeoutSeconds)
+	out.FailedDeviceRetries = in.FailedDeviceRetries
+}
+
+func convertPacketMachineSpecFromHub(in *infrav1.PacketMachineSpec, out *PacketMachineSpec) {
+	out.OS = in.OS
+	out.BillingCycle = in.BillingCycle
+	out.MachineType = in.MachineType
+	out.SSHKeys = copyStrings(in.SSHKeys)
+	out.ProjectSSHKeyIDs = copyStrings(in.ProjectSSHKeyIDs)
+	out.Placement = Placement{Metro: in.Metro, Facility: in.Facility}
+	out.IPXEUrl = in.IPXEUrl
+	if in.IPXEScriptSecretRef != nil {
+		out.IPXEScriptSecretRef = &SecretKeyReference{Name: in.IPXEScriptSecretRef.Name, Key: in.IPXEScriptSecretRef.Key}
+	}
+	out.AlwaysPXE = in.AlwaysPXE
+	if in.CustomImage != nil {
+		out.CustomImage = &CustomImage{URL: in.CustomImage.URL, Tag: in.CustomImage.Tag}
+	}
+	if in.OSHardening != nil {
+		out.OSHardening = &OSHardening{DisableSSHPasswordAuth: in.OSHardening.DisableSSHPasswordAuth, KernelHardening: in.OSHardening.KernelHardening}
+	}
+	out.HardwareReservation = hardwareReservationFromHub(in)
+	out.DeviceClaimName = in.DeviceClaimName
+	out.LoadBalancerPools = copyStrings(in.LoadBalancerPools)
+	out.VLANs = copyStrings(in.VLANs)
+	out.AddressesFromPools = copyTypedLocalObjectReferences(in.AddressesFromPools)
+	out.PrivateIPv4SubnetSize = copyInt32(in.PrivateIPv4SubnetSize)
+	out.PublicIPv4SubnetSize = copyInt32(in.PublicIPv4SubnetSize)
+	if in.IPAddresses != nil {
+		out.IPAddresses = make([]DeviceIPAddress, len(in.IPAddresses))
+		for i, address := range in.IPAddresses {
+			out.IPAddresses[i] = DeviceIPAddress{
+				AddressFamily:  address.AddressFamily,
+				Public:         address.Public,
+				CIDR:           copyInt32(address.CIDR),
+				IPReservations: copyStrings(address.IPReservations),
+			}
+		}
+	}
+	out.SpotPriceMax = in.SpotPriceMax
+	out.TerminationTime = in.TerminationTime.DeepCopy()
+	if in.ProviderID != nil {
+		providerID := *in.ProviderID
+		out.ProviderID = &providerID
+	}
+	out.Tags = Tags(copyStrings(in.Tags))
+	out.DeletePolicy = DeletePolicy(in.DeletePolicy)
+	out.DeletionTimeoutSeconds = copyInt32(in.DeletionTimeoutSeconds)
+	out.FailedDeviceRetries = in.FailedDeviceRetries
+}
+
+// hardwareReservationFromHub splits the comma separated hardware reservation IDs of a v1beta1 PacketMachine, next
+// to its reservation pool and selector.
+func hardwareReservationFromHub(in *infrav1.PacketMachineSpec) HardwareReservation {
+	out := HardwareReservation{Pool: in.ReservationPool}
+	if selector := in.ReservationSelector; selector != nil {
+		out.Selector = &HardwareReservationSelector{
+			Plan:          selector.Plan,
+			Placement:     Placement{Metro: selector.Metro, Facility: selector.Facility},
+			Tags:          copyStrings(selector.Tags),
+			NextAvailable: selector.NextAvailable,
+		}
+	}
+	if in.HardwareReservationID == "" {
+		return out
+	}
+	for _, id := range strings.Split(in.HardwareReservationID, ",") {
+		if id == nextAvailableReservation {
+			out.NextAvailable = true
+			continue
+		}
+		out.IDs = append(out.IDs, id)
+	}
+	return out
+}
+
+// restoreHardwareReservationID restores the hardware reservation IDs of a v1beta1 PacketMachine as they were
+// written, e.g. with next-available before other IDs, unless they were changed through v1beta2.
+func restoreHardwareReservationID(restored *infrav1.PacketMachineSpec, in *PacketMachineSpec, out *infrav1.PacketMachineSpec) {
+	if apiequality.Semantic.DeepEqual(hardwareReservationFromHub(restored), in.HardwareReservation) {
+		out.HardwareReservationID = restored.HardwareReservationID
+	}
+}
+
+func convertPacketMachineStatusToHub(in *PacketMachineStatus, out *infrav1.PacketMachineStatus) {
+	out.Ready = in.Ready
+	out.Addresses = in.Addresses
+	out.PublicIPv4Block = in.PublicIPv4Block
+	if in.InstanceStatus != nil {
+		status := infrav1.PacketResourceStatus(*in.InstanceStatus)
+		out.InstanceStatus = &status
+	}
+	out.ProvisioningPercentage = copyInt32(in.ProvisioningPercentage)
+	if in.LastEvent != nil {
+		out.LastEvent = &infrav1.DeviceEvent{Type: in.LastEvent.Type, Message: in.LastEvent.Message, Time: in.LastEvent.Time}
+	}
+	out.BootstrapDataHash = in.BootstrapDataHash
+	if in.Hardware != nil {
+		out.Hardware = &infrav1.HardwareStatus{Memory: in.Hardware.Memory}
+		for _, cpu := range in.Hardware.CPUs {
+			out.Hardware.CPUs = append(out.Hardware.CPUs, infrav1.HardwareCPU(cpu))
+		}
+		for _, drive := range in.Hardware.Drives {
+			out.Hardware.Drives = append(out.Hardware.Drives, infrav1.HardwareDrive(drive))
+		}
+		for _, nic := range in.Hardware.NICs {
+			out.Hardware.NICs = append(out.Hardware.NICs, infrav1.HardwareNIC(nic))
+		}
+	}
+	for _, session := range in.BGPSessions {
+		out.BGPSessions = append(out.BGPSessions, infrav1.BGPSession{
+			AddressFamily:      session.AddressFamily,
+			State:              infrav1.BGPSessionState(session.State),
+			LastTransitionTime: session.LastTransitionTime,
+		})
+	}
+	out.DeviceRetries = in.DeviceRetries
+	out.HardwareReservationID = in.HardwareReservationID
+	if in.LoadBalancerOrigin != nil {
+		out.LoadBalancerOrigin = &infrav1.LoadBalancerOrigin{PoolID: in.LoadBalancerOrigin.PoolID, OriginID: in.LoadBalancerOrigin.OriginID}
+	}
+	for _, origin := range in.LoadBalancerPoolOrigins {
+		out.LoadBalancerPoolOrigins = append(out.LoadBalancerPoolOrigins, infrav1.LoadBalancerPoolOrigin(origin))
+	}
+	out.FailureReason = in.FailureReason
+	out.FailureMessage = in.FailureMessage
+	out.Conditions = in.Conditions
+}
+
+func convertPacketMachineStatusFromHub(in *infrav1.PacketMachineStatus, out *PacketMachineStatus) {
+	out.Ready = in.Ready
+	out.Addresses = in.Addresses
+	out.PublicIPv4Block = in.PublicIPv4Block
+	if in.InstanceStatus != nil {
+		status := PacketResourceStatus(*in.InstanceStatus)
+		out.InstanceStatus = &status
+	}
+	out.ProvisioningPercentage = copyInt32(in.ProvisioningPercentage)
+	if in.LastEvent != nil {
+		out.LastEvent = &DeviceEvent{Type: in.LastEvent.Type, Message: in.LastEvent.Message, Time: in.LastEvent.Time}
+	}
+	out.BootstrapDataHash = in.BootstrapDataHash
+	if in.Hardware != nil {
+		out.Hardware = &HardwareStatus{Memory: in.Hardware.Memory}
+		for _, cpu := range in.Hardware.CPUs {
+			out.Hardware.CPUs = append(out.Hardware.CPUs, HardwareCPU(cpu))
+		}
+		for _, drive := range in.Hardware.Drives {
+			out.Hardware.Drives = append(out.Hardware.Drives, HardwareDrive(drive))
+		}
+		for _, nic := range in.Hardware.NICs {
+			out.Hardware.NICs = append(out.Hardware.NICs, HardwareNIC(nic))
+		}
+	}
+	for _, session := range in.BGPSessions {
+		out.BGPSessions = append(out.BGPSessions, BGPSession{
+			AddressFamily:      session.AddressFamily,
+			State:              BGPSessionState(session.State),
+			LastTransitionTime: session.LastTransitionTime,
+		})
+	}
+	out.DeviceRetries = in.DeviceRetries
+	out.HardwareReservationID = in.HardwareReservationID
+	if in.LoadBalancerOrigin != nil {
+		out.LoadBalancerOrigin = &LoadBalancerOrigin{PoolID: in.LoadBalancerOrigin.PoolID, OriginID: in.LoadBalancerOrigin.OriginID}
+	}
+	for _, origin := range in.LoadBalancerPoolOrigins {
+		out.LoadBalancerPoolOrigins = append(out.LoadBalancerPoolOrigins, LoadBalancerPoolOrigin(origin))
+	}
+	out.FailureReason = in.FailureReason
+	out.FailureMessage = in.FailureMessage
+	out.Conditions = in.Conditions
+}
+
+func copyStrings(in []string) []string {
+	if in == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(in)), in...)
+}
+
+func copyTypedLocalObjectReferences(in []corev1.TypedLocalObjectReference) []corev1.TypedLocalObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := make([]corev1.TypedLocalObjectReference, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func copyInt32(in *int32) *int32 {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"strings"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	fuzz "github.com/google/gofuzz"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestFuzzyConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	g := NewWithT(t)
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	t.Run("for PacketCluster", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme: scheme,
+		Hub:    &infrav1.PacketCluster{},
+		Spoke:  &PacketCluster{},
+	}))
+	t.Run("for PacketClusterTemplate", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme: scheme,
+		Hub:    &infrav1.PacketClusterTemplate{},
+		Spoke:  &PacketClusterTemplate{},
+	}))
+	t.Run("for PacketMachine", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme:      scheme,
+		Hub:         &infrav1.PacketMachine{},
+		Spoke:       &PacketMachine{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{fuzzFuncs},
+	}))
+	t.Run("for PacketMachineTemplate", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme:      scheme,
+		Hub:         &infrav1.PacketMachineTemplate{},
+		Spoke:       &PacketMachineTemplate{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{fuzzFuncs},
+	}))
+}
+
+func fuzzFuncs(_ runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		hardwareReservationFuzzer,
+		billingCycleFuzzer,
+	}
+}
+
+// billingCycleFuzzer only generates billing cycles the Equinix Metal SDK can unmarshal.
+func billingCycleFuzzer(in *metal.DeviceCreateInputBillingCycle, c fuzz.Continue) {
+	cycles := metal.AllowedDeviceCreateInputBillingCycleEnumValues
+	*in = cycles[c.Intn(len(cycles))]
+}
+
+// hardwareReservationFuzzer only generates valid reservation IDs, which cannot be empty, contain commas or be
+// next-available.
+func hardwareReservationFuzzer(in *HardwareReservation, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	ids := in.IDs[:0]
+	for _, id := range in.IDs {
+		if id != "" && id != nextAvailableReservation && !strings.Contains(id, ",") {
+			ids = append(ids, id)
+		}
+	}
+	in.IDs = ids
+}
+
+func TestConvertHardwareReservation(t *testing.T) {
+	tests := []struct {
+		name                  string
+		hardwareReservationID string
+		reservationPool       string
+		want                  HardwareReservation
+	}{
+		{
+			name: "no reservation",
+		},
+		{
+			name:                  "reservation IDs",
+			hardwareReservationID: "a,b",
+			want:                  HardwareReservation{IDs: []string{"a", "b"}},
+		},
+		{
+			name:                  "next available reservation",
+			hardwareReservationID: "next-available",
+			want:                  HardwareReservation{NextAvailable: true},
+		},
+		{
+			name:                  "next available reservation after IDs",
+			hardwareReservationID: "a,next-available",
+			want:                  HardwareReservation{IDs: []string{"a"}, NextAvailable: true},
+		},
+		{
+			name:            "reservation pool",
+			reservationPool: "pool",
+			want:            HardwareReservation{Pool: "pool"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			hub := &infrav1.PacketMachine{Spec: infrav1.PacketMachineSpec{
+				HardwareReservationID: tt.hardwareReservationID,
+				ReservationPool:       tt.reservationPool,
+			}}
+			spoke := &PacketMachine{}
+			g.Expect(spoke.ConvertFrom(hub)).To(Succeed())
+			g.Expect(spoke.Spec.HardwareReservation).To(Equal(tt.want))
+
+			// Objects created through v1beta2 have no v1beta1 data to restore.
+			spoke.Annotations = nil
+			restored := &infrav1.PacketMachine{}
+			g.Expect(spoke.ConvertTo(restored)).To(Succeed())
+			g.Expect(restored.Spec.HardwareReservationID).To(Equal(tt.hardwareReservationID))
+			g.Expect(restored.Spec.ReservationPool).To(Equal(tt.reservationPool))
+		})
+	}
+}
+
+func TestConvertHardwareReservationRestoresOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	hub := &infrav1.PacketMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine"},
+		Spec:       infrav1.PacketMachineSpec{HardwareReservationID: "next-available,a"},
+	}
+	spoke := &PacketMachine{}
+	g.Expect(spoke.ConvertFrom(hub)).To(Succeed())
+
+	restored := &infrav1.PacketMachine{}
+	g.Expect(spoke.ConvertTo(restored)).To(Succeed())
+	g.Expect(restored.Spec.HardwareReservationID).To(Equal("next-available,a"))
+
+	// Unless the reservations were changed through v1beta2.
+	g.Expect(spoke.ConvertFrom(hub)).To(Succeed())
+	spoke.Spec.HardwareReservation.IDs = []string{"b"}
+	g.Expect(spoke.ConvertTo(restored)).To(Succeed())
+	g.Expect(restored.Spec.HardwareReservationID).To(Equal("b,next-available"))
+}
+
+func TestConvertPlacement(t *testing.T) {
+	g := NewWithT(t)
+
+	hub := &infrav1.PacketCluster{Spec: infrav1.PacketClusterSpec{Metro: "da", Facility: "da11"}}
+	spoke := &PacketCluster{}
+	g.Expect(spoke.ConvertFrom(hub)).To(Succeed())
+	g.Expect(spoke.Spec.Placement).To(Equal(Placement{Metro: "da", Facility: "da11"}))
+
+	machine := &PacketMachine{Spec: PacketMachineSpec{Placement: Placement{Metro: "{{ .variables.metro }}"}}}
+	machineHub := &infrav1.PacketMachine{}
+	g.Expect(machine.ConvertTo(machineHub)).To(Succeed())
+	g.Expect(machineHub.Spec.Metro).To(Equal("{{ .variables.metro }}"))
+	g.Expect(machineHub.Spec.Facility).To(BeEmpty())
+}
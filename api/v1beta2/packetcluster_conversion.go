@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// ConvertTo converts this PacketCluster to the Hub version (v1beta1).
+func (src *PacketCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*infrav1.PacketCluster)
+	dst.ObjectMeta = src.ObjectMeta
+	convertPacketClusterSpecToHub(&src.Spec, &dst.Spec)
+	convertPacketClusterStatusToHub(&src.Status, &dst.Status)
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *PacketCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*infrav1.PacketCluster)
+	dst.ObjectMeta = src.ObjectMeta
+	convertPacketClusterSpecFromHub(&src.Spec, &dst.Spec)
+	convertPacketClusterStatusFromHub(&src.Status, &dst.Status)
+	return nil
+}
+
+// ConvertTo converts this PacketClusterList to the Hub version (v1beta1).
+func (src *PacketClusterList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*infrav1.PacketClusterList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]infrav1.PacketCluster, len(src.Items))
+	for i := range src.Items {
+		if err := src.Items[i].ConvertTo(&dst.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *PacketClusterList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*infrav1.PacketClusterList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]PacketCluster, len(src.Items))
+	for i := range src.Items {
+		if err := dst.Items[i].ConvertFrom(&src.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,478 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// PacketClusterSpec defines the desired state of PacketCluster.
+type PacketClusterSpec struct {
+	// ProjectID represents the Packet Project where this cluster will be placed into. Required unless Project is
+	// set, in which case it is set to the ID of the project created for the cluster.
+	// +optional
+	ProjectID string `json:"projectID,omitempty"`
+
+	// Project, when set, makes the controller create a dedicated Equinix Metal project for the cluster, manage all
+	// the resources of the cluster in it, and delete it with the cluster. Creating projects requires an API key of
+	// a user of the organization rather than a project API key.
+	// +optional
+	Project *ManagedProject `json:"project,omitempty"`
+
+	// CredentialsRef references a Secret, in the namespace of the PacketCluster, holding the Equinix Metal API key
+	// the resources of this cluster are managed with, for management clusters managing clusters in different
+	// Equinix accounts. Defaults to the API key of the controller manager. The Secret is kept from being
+	// deleted until the resources of the cluster are deleted.
+	// +optional
+	CredentialsRef *SecretKeyReference `json:"credentialsRef,omitempty"`
+
+	// Placement is where the devices of the cluster are created, unless their PacketMachines set their own.
+	// +optional
+	Placement Placement `json:"placement,omitempty"`
+
+	// ControlPlaneEndpoint represents the endpoint used to communicate with the control plane.
+	// +optional
+	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint"`
+
+	// VIPManager represents whether this cluster uses CPEM or kube-vip or Equinix Metal Load Balancer to
+	// manage its vip for the api server IP. NONE disables VIP management entirely, in which case
+	// ControlPlaneEndpoint must be set to an endpoint managed outside of the provider (e.g. a DNS name or
+	// an anycast load balancer) and no Elastic IP, BGP or load balancer resources are created.
+	// +kubebuilder:validation:Enum=CPEM;KUBE_VIP;EMLB;NONE
+	// +kubebuilder:default:=CPEM
+	VIPManager VIPManagerType `json:"vipManager"`
+
+	// ElasticIPReclaimPolicy is what happens to the control plane Elastic IP reserved for the cluster when the
+	// cluster is deleted. Defaults to Retain, so that the endpoint can be used again by a cluster with the same name.
+	// +kubebuilder:validation:Enum=Retain;Release
+	// +optional
+	ElasticIPReclaimPolicy ElasticIPReclaimPolicy `json:"elasticIPReclaimPolicy,omitempty"`
+
+	// VIPReservationID is the ID of an existing IP reservation of the project, such as a global anycast IP, to use
+	// as the control plane Elastic IP instead of reserving a new one. Only valid with the CPEM and KUBE_VIP VIP
+	// managers. The reservation is tagged for the cluster, and untagged rather than released when the cluster is
+	// deleted, whatever the ElasticIPReclaimPolicy.
+	// +optional
+	VIPReservationID string `json:"vipReservationID,omitempty"`
+
+	// ServiceIPPool requests a block of public IPv4 addresses to be reserved for this cluster so it can be
+	// announced over BGP for Services of type LoadBalancer (e.g. by kube-vip). The block is released when
+	// the cluster is deleted.
+	// +optional
+	ServiceIPPool *ServiceIPPool `json:"serviceIPPool,omitempty"`
+
+	// BGPPeerAnnotations enables BGP on the devices of the cluster and annotates their Nodes with the BGP peering
+	// information of the device, for BGP-capable CNIs such as Calico or Cilium to peer with the Equinix Metal routers.
+	// +optional
+	BGPPeerAnnotations bool `json:"bgpPeerAnnotations,omitempty"`
+
+	// Hibernate powers off the worker devices of the cluster without deleting them, keeping their hardware
+	// reservations and local data, and powers them back on once unset. Control plane devices keep running.
+	// +optional
+	Hibernate bool `json:"hibernate,omitempty"`
+
+	// DeletePolicy controls how the devices of the cluster are deleted. PacketMachines can override it.
+	// Defaults to Force.
+	// +kubebuilder:validation:Enum=Graceful;Force;ForceAfterTimeout
+	// +optional
+	DeletePolicy DeletePolicy `json:"deletePolicy,omitempty"`
+
+	// DeletionTimeoutSeconds is how long devices are deleted gracefully with the ForceAfterTimeout delete policy, and
+	// how long their deletion waits for the Nodes of their machines to be drained. PacketMachines can override it.
+	// Defaults to 600.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DeletionTimeoutSeconds *int32 `json:"deletionTimeoutSeconds,omitempty"`
+
+	// MaxDevices is the maximum number of devices of the cluster, counting the devices of its PacketMachines and
+	// PacketMachinePools, e.g. to keep a misconfigured MachineDeployment or autoscaler within budget. Machines
+	// beyond it wait with the DeviceQuotaExceeded reason rather than getting a device. Unlimited when unset.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxDevices *int32 `json:"maxDevices,omitempty"`
+
+	// ReservationPools are named groups of hardware reservations that PacketMachines can be allocated from by
+	// setting hardwareReservation.pool, instead of listing raw reservation IDs.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ReservationPools []ReservationPool `json:"reservationPools,omitempty"`
+
+	// LoadBalancer configures the Equinix Metal Load Balancer of the cluster with vipManager EMLB.
+	// +optional
+	LoadBalancer *LoadBalancer `json:"loadBalancer,omitempty"`
+
+	// LoadBalancerPools are additional Equinix Metal Load Balancer pools, each with its own listener port,
+	// that PacketMachines can register their devices in by setting loadBalancerPools, e.g. to expose an
+	// ingress controller running on the workers. Requires vipManager EMLB.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	LoadBalancerPools []LoadBalancerPool `json:"loadBalancerPools,omitempty"`
+
+	// EMLB customizes the Equinix Metal Load Balancer created for the cluster with vipManager EMLB.
+	// +optional
+	EMLB *EMLBConfig `json:"emlb,omitempty"`
+
+	// VLANs are Equinix Metal VLANs created with the cluster and deleted with it, that PacketMachines can attach
+	// their devices to by setting vlans.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	VLANs []VLAN `json:"vlans,omitempty"`
+
+	// MetalGateways are Equinix Metal Gateways routing VLANs of the cluster, created with the cluster and deleted
+	// with it.
+	// +listType=map
+	// +listMapKey=vlan
+	// +optional
+	MetalGateways []MetalGateway `json:"metalGateways,omitempty"`
+
+	// MaxConcurrentPortConversions, when set, is how many PacketMachines of the cluster may have the VLANs of their
+	// device changed at once, so that changing the network of many machines does not partition the cluster. The
+	// others wait with the WaitingForPortConversion reason until the Nodes of the converted machines are healthy
+	// again. Unlimited when unset.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentPortConversions *int32 `json:"maxConcurrentPortConversions,omitempty"`
+}
+
+// ManagedProject is an Equinix Metal project created for, and deleted with, a cluster.
+type ManagedProject struct {
+	// OrganizationID is the ID of the organization the project is created in.
+	// +kubebuilder:validation:MinLength=1
+	OrganizationID string `json:"organizationID"`
+
+	// Name of the project. Defaults to the namespace and name of the cluster.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// MetalGateway is an Equinix Metal Gateway routing a VLAN of the cluster, with the first address of an IP
+// reservation as the gateway of the VLAN.
+type MetalGateway struct {
+	// VLAN is the name of the VLAN of spec.vlans routed by the gateway. A VLAN has at most one gateway.
+	VLAN string `json:"vlan"`
+
+	// IPReservationID is the ID of an IP reservation of the project used by the gateway, e.g. a public IPv4 block
+	// of the metro of the VLAN. Mutually exclusive with privateIPv4SubnetSize.
+	// +optional
+	IPReservationID string `json:"ipReservationID,omitempty"`
+
+	// PrivateIPv4SubnetSize is the number of addresses of a private IPv4 block reserved for the gateway.
+	// Mutually exclusive with ipReservationID.
+	// +kubebuilder:validation:Enum=8;16;32;64;128
+	// +optional
+	PrivateIPv4SubnetSize int32 `json:"privateIPv4SubnetSize,omitempty"`
+}
+
+// VLAN is an Equinix Metal VLAN managed with the cluster.
+type VLAN struct {
+	// Name of the VLAN, referenced by the vlans of PacketMachines.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Metro of the VLAN. Defaults to the metro of the cluster. Devices can only be attached to the VLANs of their
+	// metro.
+	// +optional
+	Metro string `json:"metro,omitempty"`
+
+	// Description of the VLAN.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// VXLAN is the VLAN ID, assigned by Equinix Metal when unset.
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=3999
+	// +optional
+	VXLAN *int32 `json:"vxlan,omitempty"`
+}
+
+// LoadBalancer configures the Equinix Metal Load Balancer of a cluster.
+type LoadBalancer struct {
+	// ExistingID is the ID of an existing Equinix Metal Load Balancer the cluster uses instead of creating one, e.g.
+	// provisioned with Terraform. It must be in the location of the cluster and have the API server listener port
+	// of emlb.port, 6443 by default. Only the pools and origins of the cluster are managed on it, and it is not
+	// deleted with the cluster.
+	// +optional
+	ExistingID string `json:"existingID,omitempty"`
+
+	// ExistingName is the name of an existing Equinix Metal Load Balancer of the project, looked up instead of
+	// existingID.
+	// +optional
+	ExistingName string `json:"existingName,omitempty"`
+}
+
+// EMLBConfig customizes the Equinix Metal Load Balancer of a cluster.
+type EMLBConfig struct {
+	// Port is the listener port of the load balancer serving the API server, and the port of the control plane
+	// endpoint. Defaults to 6443.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// HealthCheck configures how the load balancer checks the devices of the pools it creates.
+	// +optional
+	HealthCheck *EMLBHealthCheck `json:"healthCheck,omitempty"`
+
+	// PoolNamePrefix is the prefix of the names of the pools created on the load balancer. Defaults to the name
+	// of the load balancer.
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	PoolNamePrefix string `json:"poolNamePrefix,omitempty"`
+
+	// LocationID is the ID of the load balancer location the load balancer is created in, for metros the provider
+	// does not know the location of yet. Defaults to the location of the metro of the cluster.
+	// +optional
+	LocationID string `json:"locationID,omitempty"`
+}
+
+// EMLBHealthCheck configures the health checks of the devices of Equinix Metal Load Balancer pools.
+type EMLBHealthCheck struct {
+	// IntervalSeconds is how often a device is checked.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+
+	// TimeoutSeconds is how long a check waits for a device to answer. Must be less than intervalSeconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// LoadBalancerPool is a named Equinix Metal Load Balancer pool served on a listener port of the cluster load balancer.
+type LoadBalancerPool struct {
+	// Name of the pool, referenced by the loadBalancerPools of PacketMachines.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Port is the listener port of the load balancer forwarding to the pool.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// TargetPort is the port traffic is forwarded to on the devices of the pool. Defaults to Port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	TargetPort int32 `json:"targetPort,omitempty"`
+
+	// ControlPlane adds every control plane machine of the cluster to the pool, e.g. for a TLS passthrough to the
+	// API servers next to the API server listener, without listing the pool on their PacketMachines.
+	// +optional
+	ControlPlane bool `json:"controlPlane,omitempty"`
+}
+
+// ReservationPool is a named group of hardware reservations.
+type ReservationPool struct {
+	// Name of the pool, referenced by the hardwareReservation.pool of PacketMachines.
+	Name string `json:"name"`
+
+	// ReservationIDs are the hardware reservations of the pool, allocated in order.
+	// +optional
+	ReservationIDs []string `json:"reservationIDs,omitempty"`
+
+	// Plan selects all the hardware reservations of the project for the given plan, e.g. "c3.small.x86".
+	// Mutually exclusive with ReservationIDs.
+	// +optional
+	Plan string `json:"plan,omitempty"`
+}
+
+// ElasticIPReclaimPolicy describes what happens to the control plane Elastic IP of a cluster when the cluster is
+// deleted.
+type ElasticIPReclaimPolicy string
+
+const (
+	// ElasticIPReclaimRetain keeps the Elastic IP, tagged with the name of the cluster.
+	ElasticIPReclaimRetain = ElasticIPReclaimPolicy("Retain")
+	// ElasticIPReclaimRelease releases the Elastic IP once the cluster is deleted.
+	ElasticIPReclaimRelease = ElasticIPReclaimPolicy("Release")
+)
+
+// ElasticIPStatus describes the control plane Elastic IP of a cluster.
+type ElasticIPStatus struct {
+	// ReservationID is the ID of the Equinix Metal IP reservation of the Elastic IP.
+	ReservationID string `json:"reservationID"`
+
+	// Address is the Elastic IP.
+	Address string `json:"address"`
+}
+
+// ServiceIPPool describes a public IPv4 block reserved for Services.
+type ServiceIPPool struct {
+	// Size is the number of public IPv4 addresses to reserve.
+	// +kubebuilder:validation:Enum=1;2;4;8;16
+	// +kubebuilder:default:=4
+	Size int32 `json:"size"`
+}
+
+// ServiceIPPoolStatus describes the public IPv4 block reserved for Services.
+type ServiceIPPoolStatus struct {
+	// ReservationID is the ID of the Equinix Metal IP reservation backing the pool.
+	ReservationID string `json:"reservationID"`
+
+	// CIDR is the reserved block in CIDR notation.
+	CIDR string `json:"cidr"`
+}
+
+// VLANStatus describes a VLAN created for the cluster.
+type VLANStatus struct {
+	// Name of the VLAN in spec.vlans.
+	Name string `json:"name"`
+
+	// ID is the ID of the Equinix Metal VLAN.
+	ID string `json:"id"`
+
+	// VXLAN is the VLAN ID.
+	// +optional
+	VXLAN int32 `json:"vxlan,omitempty"`
+
+	// Metro is the metro of the VLAN.
+	// +optional
+	Metro string `json:"metro,omitempty"`
+}
+
+// MetalGatewayStatus describes a Metal Gateway created for the cluster.
+type MetalGatewayStatus struct {
+	// VLAN is the name of the VLAN routed by the gateway.
+	VLAN string `json:"vlan"`
+
+	// ID is the ID of the Equinix Metal Gateway.
+	ID string `json:"id"`
+
+	// IPReservationID is the ID of the IP reservation of the gateway.
+	// +optional
+	IPReservationID string `json:"ipReservationID,omitempty"`
+
+	// Address is the address of the gateway in the VLAN.
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// CIDR is the IP block of the gateway, e.g. 10.0.0.0/29.
+	// +optional
+	CIDR string `json:"cidr,omitempty"`
+}
+
+// LoadBalancerStatus describes the Equinix Metal Load Balancer of a cluster.
+type LoadBalancerStatus struct {
+	// ID is the ID of the load balancer.
+	ID string `json:"id"`
+
+	// PortID is the ID of the listener port serving the API server.
+	// +optional
+	PortID string `json:"portID,omitempty"`
+
+	// PortNumber is the number of the listener port serving the API server.
+	// +optional
+	PortNumber int32 `json:"portNumber,omitempty"`
+
+	// Metro is the metro of the load balancer.
+	// +optional
+	Metro string `json:"metro,omitempty"`
+
+	// Pools are the pools created on the load balancer for the loadBalancerPools of the cluster.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Pools []LoadBalancerPoolStatus `json:"pools,omitempty"`
+}
+
+// LoadBalancerPoolStatus describes a pool created on the load balancer for a load balancer pool of the cluster.
+type LoadBalancerPoolStatus struct {
+	// Name is the name of the pool in spec.loadBalancerPools.
+	Name string `json:"name"`
+
+	// ID is the ID of the Equinix Metal Load Balancer pool.
+	ID string `json:"id"`
+}
+
+// PacketClusterStatus defines the observed state of PacketCluster.
+type PacketClusterStatus struct {
+	// Ready denotes that the cluster (infrastructure) is ready.
+	// +optional
+	Ready bool `json:"ready"`
+
+	// ElasticIP is the control plane Elastic IP of the cluster, with the CPEM and KUBE_VIP VIP managers.
+	// +optional
+	ElasticIP *ElasticIPStatus `json:"elasticIP,omitempty"`
+
+	// ServiceIPPool is the public IPv4 block reserved for Services, if one was requested.
+	// +optional
+	ServiceIPPool *ServiceIPPoolStatus `json:"serviceIPPool,omitempty"`
+
+	// VLANs are the VLANs created for the cluster.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	VLANs []VLANStatus `json:"vlans,omitempty"`
+
+	// MetalGateways are the Metal Gateways created for the cluster.
+	// +listType=map
+	// +listMapKey=vlan
+	// +optional
+	MetalGateways []MetalGatewayStatus `json:"metalGateways,omitempty"`
+
+	// LoadBalancer is the Equinix Metal Load Balancer of the cluster, with the EMLB VIP manager.
+	// +optional
+	LoadBalancer *LoadBalancerStatus `json:"loadBalancer,omitempty"`
+
+	// Conditions defines current service state of the PacketCluster.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=packetclusters,shortName=pcl,scope=Namespaced,categories=cluster-api
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this PacketCluster belongs"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="PacketCluster ready status"
+
+// PacketCluster is the Schema for the packetclusters API.
+type PacketCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PacketClusterSpec   `json:"spec,omitempty"`
+	Status PacketClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PacketClusterList contains a list of PacketCluster.
+type PacketClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PacketCluster `json:"items"`
+}
+
+// GetConditions returns the list of conditions for an PacketCluster API object.
+func (c *PacketCluster) GetConditions() clusterv1.Conditions {
+	return c.Status.Conditions
+}
+
+// SetConditions will set the given conditions on an PacketCluster object.
+func (c *PacketCluster) SetConditions(conditions clusterv1.Conditions) {
+	c.Status.Conditions = conditions
+}
+
+func init() {
+	objectTypes = append(objectTypes, &PacketCluster{}, &PacketClusterList{})
+}
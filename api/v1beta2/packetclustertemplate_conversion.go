@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// ConvertTo converts this PacketClusterTemplate to the Hub version (v1beta1).
+func (src *PacketClusterTemplate) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*infrav1.PacketClusterTemplate)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec.Template.ObjectMeta = src.Spec.Template.ObjectMeta
+	convertPacketClusterSpecToHub(&src.Spec.Template.Spec, &dst.Spec.Template.Spec)
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *PacketClusterTemplate) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*infrav1.PacketClusterTemplate)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec.Template.ObjectMeta = src.Spec.Template.ObjectMeta
+	convertPacketClusterSpecFromHub(&src.Spec.Template.Spec, &dst.Spec.Template.Spec)
+	return nil
+}
+
+// ConvertTo converts this PacketClusterTemplateList to the Hub version (v1beta1).
+func (src *PacketClusterTemplateList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*infrav1.PacketClusterTemplateList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]infrav1.PacketClusterTemplate, len(src.Items))
+	for i := range src.Items {
+		if err := src.Items[i].ConvertTo(&dst.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *PacketClusterTemplateList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*infrav1.PacketClusterTemplateList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]PacketClusterTemplate, len(src.Items))
+	for i := range src.Items {
+		if err := dst.Items[i].ConvertFrom(&src.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// PacketClusterTemplateSpec defines the desired state of PacketClusterTemplate.
+type PacketClusterTemplateSpec struct {
+	Template PacketClusterTemplateResource `json:"template"`
+}
+
+// PacketClusterTemplateResource describes the data needed to create a PacketCluster from a template.
+type PacketClusterTemplateResource struct {
+	// Standard object's metadata, applied to the PacketClusters created from the template.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	ObjectMeta clusterv1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the specification of the desired behavior of the cluster.
+	Spec PacketClusterSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=packetclustertemplates,shortName=pct,scope=Namespaced,categories=cluster-api
+
+// PacketClusterTemplate is the Schema for the packetclustertemplates API, used by ClusterClasses to create
+// PacketClusters.
+type PacketClusterTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PacketClusterTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PacketClusterTemplateList contains a list of PacketClusterTemplate.
+type PacketClusterTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PacketClusterTemplate `json:"items"`
+}
+
+func init() {
+	objectTypes = append(objectTypes, &PacketClusterTemplate{}, &PacketClusterTemplateList{})
+}
@@ -0,0 +1,488 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+)
+
+// PacketMachineSpec defines the desired state of PacketMachine.
+//
+// MachineType, Placement, IPXEUrl and Tags may be Go templates, e.g. "{{ .variables.workerMetro }}", so a
+// single PacketMachineTemplate can serve several worker pools. They are resolved once, when the device is created,
+// with .cluster.name, .cluster.namespace, .machine.name, .machine.role, .machineSet.name, .machineDeployment.name
+// and .variables, the Cluster topology variables including the overrides of the Machine's MachineDeployment.
+// IPXEUrl may also use .metro, the resolved metro of the machine.
+type PacketMachineSpec struct {
+	OS           string                              `json:"os"`
+	BillingCycle metal.DeviceCreateInputBillingCycle `json:"billingCycle,omitempty"`
+	MachineType  string                              `json:"machineType"`
+
+	// SSHKeys are public keys, in authorized_keys format, to authorize on the device.
+	// +optional
+	SSHKeys []string `json:"sshKeys,omitempty"`
+
+	// ProjectSSHKeyIDs are the IDs of SSH keys of the project to authorize on the device, in addition to SSHKeys.
+	// Equinix Metal authorizes the keys of the project and of its members when neither is set.
+	// +optional
+	ProjectSSHKeyIDs []string `json:"projectSSHKeyIDs,omitempty"`
+
+	// Placement is where the device is created, overriding the placement of the PacketCluster when its metro or
+	// facility is set.
+	// +optional
+	Placement Placement `json:"placement,omitempty"`
+
+	// IPXEUrl can be used to set the pxe boot url when using custom OSes with this provider.
+	// Note that OS should also be set to "custom_ipxe" if using this value.
+	// +optional
+	IPXEUrl string `json:"ipxeURL,omitempty"`
+
+	// IPXEScriptSecretRef references a Secret holding the iPXE script to boot the device with, for scripts that
+	// must not be publicly reachable. The script is passed to the device as its userdata, which Equinix Metal
+	// runs as the iPXE script when no URL is set, so it replaces the bootstrap data for this machine.
+	// Mutually exclusive with IPXEUrl; OS must be set to "custom_ipxe".
+	// +optional
+	IPXEScriptSecretRef *SecretKeyReference `json:"ipxeScriptSecretRef,omitempty"`
+
+	// AlwaysPXE makes the device boot from the network, with its iPXE script, on every boot rather than only on
+	// the first one, for operating systems managed by network boot such as Talos. OS must be set to "custom_ipxe",
+	// with IPXEUrl or IPXEScriptSecretRef.
+	// +optional
+	AlwaysPXE bool `json:"alwaysPXE,omitempty"`
+
+	// CustomImage installs a custom image on the device with the Equinix Metal custom images feature, on top of the
+	// operating system OS. Mutually exclusive with IPXEUrl, IPXEScriptSecretRef and AlwaysPXE, and with OS "custom_ipxe".
+	// +optional
+	CustomImage *CustomImage `json:"customImage,omitempty"`
+
+	// OSHardening applies a baseline hardening to the operating system of the device, with cloud-config merged into
+	// its bootstrap data. Requires cloud-config bootstrap data.
+	// +optional
+	OSHardening *OSHardening `json:"osHardening,omitempty"`
+
+	// HardwareReservation selects the hardware reservations the device is created on.
+	// +optional
+	HardwareReservation HardwareReservation `json:"hardwareReservation,omitempty"`
+
+	// DeviceClaimName is the name of a PacketDeviceClaim, in the namespace of the PacketMachine, whose device the
+	// machine uses instead of creating one. The claim decides what happens to the device when the machine is deleted.
+	// +optional
+	DeviceClaimName string `json:"deviceClaimName,omitempty"`
+
+	// LoadBalancerPools are the names of load balancer pools of the PacketCluster to register the device in.
+	// The device is added as an origin of each pool at its public IPv4 address.
+	// +optional
+	LoadBalancerPools []string `json:"loadBalancerPools,omitempty"`
+
+	// VLANs are the names of VLANs of the PacketCluster to attach the bond0 port of the device to, in hybrid bonded
+	// mode. The device stays reachable on its public and private addresses.
+	// +optional
+	VLANs []string `json:"vlans,omitempty"`
+
+	// AddressesFromPools are IP address pools to claim an address from for the device, one per pool, following the
+	// Cluster API IPAM contract, e.g. for its interfaces on the VLANs. The device is created once all the addresses
+	// are allocated, and they are passed to its bootstrap data as the ipAddresses template variable. The addresses
+	// are released before the device is deleted, once it is detached from its VLANs.
+	// +optional
+	AddressesFromPools []corev1.TypedLocalObjectReference `json:"addressesFromPools,omitempty"`
+
+	// PrivateIPv4SubnetSize is the prefix length of the private IPv4 block of the device, from 28 to 31, instead of
+	// the Equinix Metal default of 31. Use IPAddresses to also change the public blocks of the device.
+	// +kubebuilder:validation:Minimum=28
+	// +kubebuilder:validation:Maximum=31
+	// +optional
+	PrivateIPv4SubnetSize *int32 `json:"privateIPv4SubnetSize,omitempty"`
+
+	// PublicIPv4SubnetSize is the prefix length of the public IPv4 block of the device, from 28 to 31, instead of
+	// the Equinix Metal default of 31, for workloads needing a routable block on the host. The block is released
+	// with the device. Use IPAddresses to also change the other blocks of the device.
+	// +kubebuilder:validation:Minimum=28
+	// +kubebuilder:validation:Maximum=31
+	// +optional
+	PublicIPv4SubnetSize *int32 `json:"publicIPv4SubnetSize,omitempty"`
+
+	// IPAddresses are the address blocks the device is created with, instead of the Equinix Metal default of a public
+	// IPv4, a private IPv4 and a public IPv6 block. A private IPv4 block is required; leaving out the public blocks
+	// creates a device only reachable on its private network and its VLANs.
+	// +optional
+	IPAddresses []DeviceIPAddress `json:"ipAddresses,omitempty"`
+
+	// SpotPriceMax, when set, creates the device as a spot market instance bidding at most this price, in US dollars
+	// per hour, e.g. "0.50". Equinix Metal reclaims spot devices when they are outbid.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	SpotPriceMax string `json:"spotPriceMax,omitempty"`
+
+	// TerminationTime, when set, is when Equinix Metal deletes the device. When Equinix Metal gives a spot device
+	// another termination time because it was outbid, the machine is marked as failed for it to be remediated.
+	// +optional
+	TerminationTime *metav1.Time `json:"terminationTime,omitempty"`
+
+	// ProviderID is the unique identifier as specified by the cloud provider.
+	// +optional
+	ProviderID *string `json:"providerID,omitempty"`
+
+	// Tags is an optional set of tags to add to Packet resources managed by the Packet provider.
+	// +optional
+	Tags Tags `json:"tags,omitempty"`
+
+	// DeletePolicy controls how the device is deleted, overriding the one of the PacketCluster.
+	// +kubebuilder:validation:Enum=Graceful;Force;ForceAfterTimeout
+	// +optional
+	DeletePolicy DeletePolicy `json:"deletePolicy,omitempty"`
+
+	// DeletionTimeoutSeconds is how long the device is deleted gracefully with the ForceAfterTimeout delete policy,
+	// and how long the deletion waits for the Node of the machine to be drained, overriding the one of the
+	// PacketCluster. Defaults to 600.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DeletionTimeoutSeconds *int32 `json:"deletionTimeoutSeconds,omitempty"`
+
+	// FailedDeviceRetries is how many times a device that fails to provision is deleted and created again before the
+	// machine is marked as failed. Disabled when 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	FailedDeviceRetries int32 `json:"failedDeviceRetries,omitempty"`
+}
+
+// HardwareReservation selects the hardware reservations a device is created on. IDs, NextAvailable and Pool are
+// tried in this order; Pool and Selector are mutually exclusive with the others.
+type HardwareReservation struct {
+	// IDs are the hardware reservations to create the device on, tried in order.
+	// +kubebuilder:validation:items:Pattern=`^[^,]+$`
+	// +optional
+	IDs []string `json:"ids,omitempty"`
+
+	// NextAvailable lets Equinix Metal pick any provisionable hardware reservation of the plan of the machine.
+	// +optional
+	NextAvailable bool `json:"nextAvailable,omitempty"`
+
+	// Pool is the name of a reservation pool of the PacketCluster to allocate the device from.
+	// +optional
+	Pool string `json:"pool,omitempty"`
+
+	// Selector picks the hardware reservation to create the device on among the provisionable reservations of the
+	// project that match it.
+	// +optional
+	Selector *HardwareReservationSelector `json:"selector,omitempty"`
+}
+
+// HardwareReservationSelector selects hardware reservations by criteria. Matching reservations are tried in the
+// order of their IDs.
+type HardwareReservationSelector struct {
+	// Plan is the plan of the reservations. Defaults to the machine type of the PacketMachine.
+	// +optional
+	Plan string `json:"plan,omitempty"`
+
+	// Placement is where the reservations are. Defaults to where the device is created.
+	// +optional
+	Placement Placement `json:"placement,omitempty"`
+
+	// Tags are tags the reservations must all have.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// NextAvailable lets Equinix Metal pick any provisionable hardware reservation of the plan of the machine when
+	// none matches the selector.
+	// +optional
+	NextAvailable bool `json:"nextAvailable,omitempty"`
+}
+
+// CustomImage is a custom operating system image, published in a Git repository in the layout of the Equinix Metal
+// images.
+type CustomImage struct {
+	// URL is the URL of the Git repository of the image.
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// Tag is the commit or tag of the image in the repository.
+	// +kubebuilder:validation:MinLength=1
+	Tag string `json:"tag"`
+}
+
+// OSHardening is a baseline hardening of the operating system of a device, applied by cloud-init on its first boot.
+type OSHardening struct {
+	// DisableSSHPasswordAuth disables password authentication to the SSH server, so that only keys are accepted.
+	// +optional
+	DisableSSHPasswordAuth bool `json:"disableSSHPasswordAuth,omitempty"`
+
+	// KernelHardening restricts access to kernel pointers and logs, and ignores ICMP redirects, with sysctls that
+	// do not affect Kubernetes networking.
+	// +optional
+	KernelHardening bool `json:"kernelHardening,omitempty"`
+}
+
+// DeviceIPAddress is an address block a device is created with.
+type DeviceIPAddress struct {
+	// AddressFamily is the IP version of the block.
+	// +kubebuilder:validation:Enum=4;6
+	AddressFamily int32 `json:"addressFamily"`
+
+	// Public selects a public block rather than a private one. Private blocks are only available for IPv4.
+	// +optional
+	Public bool `json:"public,omitempty"`
+
+	// CIDR is the prefix length of the block, from 28 to 31 for IPv4 and from 120 to 127 for IPv6. Equinix Metal
+	// picks the default size of the block when unset.
+	// +optional
+	CIDR *int32 `json:"cidr,omitempty"`
+
+	// IPReservations are the IDs of IP reservations to assign the block from.
+	// +optional
+	IPReservations []string `json:"ipReservations,omitempty"`
+}
+
+// HardwareStatus describes the hardware of a device.
+type HardwareStatus struct {
+	// CPUs of the device.
+	// +optional
+	CPUs []HardwareCPU `json:"cpus,omitempty"`
+
+	// Memory is the total memory of the device, e.g. "64GB".
+	// +optional
+	Memory string `json:"memory,omitempty"`
+
+	// Drives of the device.
+	// +optional
+	Drives []HardwareDrive `json:"drives,omitempty"`
+
+	// NICs of the device.
+	// +optional
+	NICs []HardwareNIC `json:"nics,omitempty"`
+}
+
+// HardwareCPU describes a group of identical CPUs.
+type HardwareCPU struct {
+	// Count of CPUs.
+	Count int32 `json:"count"`
+
+	// Type of the CPUs, e.g. "Intel Xeon E-2278G 8-Core Processor @ 3.40GHz".
+	// +optional
+	Type string `json:"type,omitempty"`
+}
+
+// HardwareDrive describes a group of identical drives.
+type HardwareDrive struct {
+	// Count of drives.
+	Count int32 `json:"count"`
+
+	// Type of the drives, e.g. "SSD" or "NVME".
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Size of each drive, e.g. "480GB".
+	// +optional
+	Size string `json:"size,omitempty"`
+
+	// Category of the drives, e.g. "boot" or "storage".
+	// +optional
+	Category string `json:"category,omitempty"`
+}
+
+// HardwareNIC describes a group of identical network interfaces.
+type HardwareNIC struct {
+	// Count of network interfaces.
+	Count int32 `json:"count"`
+
+	// Type of the network interfaces, usually their speed, e.g. "10Gbps".
+	// +optional
+	Type string `json:"type,omitempty"`
+}
+
+// LoadBalancerOrigin describes the origin of a device in its pool of the API server on the load balancer of the
+// cluster.
+type LoadBalancerOrigin struct {
+	// PoolID is the ID of the pool created for the device.
+	// +optional
+	PoolID string `json:"poolID,omitempty"`
+
+	// OriginID is the ID of the origin of the device in the pool.
+	// +optional
+	OriginID string `json:"originID,omitempty"`
+}
+
+// LoadBalancerPoolOrigin describes the origin of a device in a load balancer pool of the cluster.
+type LoadBalancerPoolOrigin struct {
+	// Pool is the name of the pool in the loadBalancerPools of the cluster.
+	Pool string `json:"pool"`
+
+	// OriginID is the ID of the origin of the device in the pool.
+	OriginID string `json:"originID"`
+}
+
+// BGPSessionState is the state of a BGP session.
+// +kubebuilder:validation:Enum=up;down;unknown
+type BGPSessionState string
+
+// BGPSession describes a BGP session of the device.
+type BGPSession struct {
+	// AddressFamily of the session, ipv4 or ipv6.
+	AddressFamily string `json:"addressFamily"`
+
+	// State of the session.
+	State BGPSessionState `json:"state"`
+
+	// LastTransitionTime is the last time the session was established or lost.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// DeviceEvent is an event of the device reported by Equinix Metal.
+type DeviceEvent struct {
+	// Type of the event, e.g. provisioning.104.
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Message of the event.
+	Message string `json:"message"`
+
+	// Time of the event.
+	Time metav1.Time `json:"time"`
+}
+
+// PacketMachineStatus defines the observed state of PacketMachine.
+type PacketMachineStatus struct {
+	// Ready is true when the provider resource is ready.
+	// +optional
+	Ready bool `json:"ready"`
+
+	// Addresses contains the Packet device associated addresses.
+	Addresses []corev1.NodeAddress `json:"addresses,omitempty"`
+
+	// PublicIPv4Block is the public IPv4 block of the device, in CIDR notation.
+	// +optional
+	PublicIPv4Block string `json:"publicIPv4Block,omitempty"`
+
+	// InstanceStatus is the status of the Packet device instance for this machine.
+	// +optional
+	InstanceStatus *PacketResourceStatus `json:"instanceStatus,omitempty"`
+
+	// ProvisioningPercentage is how far the provisioning of the device is, from 0 to 100, while it is provisioned.
+	// +optional
+	ProvisioningPercentage *int32 `json:"provisioningPercentage,omitempty"`
+
+	// LastEvent is the latest provisioning event of the device, e.g. to find out why a provision is slow or stuck.
+	// +optional
+	LastEvent *DeviceEvent `json:"lastEvent,omitempty"`
+
+	// BootstrapDataHash is the SHA-256 hash of the bootstrap data the device was created with.
+	// +optional
+	BootstrapDataHash string `json:"bootstrapDataHash,omitempty"`
+
+	// Hardware describes the hardware of the device, as advertised by its plan.
+	// +optional
+	Hardware *HardwareStatus `json:"hardware,omitempty"`
+
+	// BGPSessions are the BGP sessions of the device, for clusters using kube-vip.
+	// +optional
+	BGPSessions []BGPSession `json:"bgpSessions,omitempty"`
+
+	// DeviceRetries is how many devices of the machine failed to provision and were created again, see
+	// spec.failedDeviceRetries.
+	// +optional
+	DeviceRetries int32 `json:"deviceRetries,omitempty"`
+
+	// HardwareReservationID is the ID of the hardware reservation the device was created on, if any.
+	// +optional
+	HardwareReservationID string `json:"hardwareReservationID,omitempty"`
+
+	// LoadBalancerOrigin is the origin of the device in its pool of the API server on the load balancer of the
+	// cluster, for control plane machines of clusters with the EMLB VIP manager.
+	// +optional
+	LoadBalancerOrigin *LoadBalancerOrigin `json:"loadBalancerOrigin,omitempty"`
+
+	// LoadBalancerPoolOrigins are the origins of the device in the load balancer pools of the cluster it belongs to.
+	// +listType=map
+	// +listMapKey=pool
+	// +optional
+	LoadBalancerPoolOrigins []LoadBalancerPoolOrigin `json:"loadBalancerPoolOrigins,omitempty"`
+
+	// Any transient errors that occur during the reconciliation of Machines
+	// can be added as events to the Machine object and/or logged in the
+	// controller's output.
+	// +optional
+	FailureReason *capierrors.MachineStatusError `json:"failureReason,omitempty"`
+
+	// FailureMessage will be set in the event that there is a terminal problem
+	// reconciling the Machine and will contain a more verbose string suitable
+	// for logging and human consumption.
+	//
+	// This field should not be set for transitive errors that a controller
+	// faces that are expected to be fixed automatically over
+	// time (like service outages), but instead indicate that something is
+	// fundamentally wrong with the Machine's spec or the configuration of
+	// the controller, and that manual intervention is required. Examples
+	// of terminal errors would be invalid combinations of settings in the
+	// spec, values that are unsupported by the controller, or the
+	// responsible controller itself being critically misconfigured.
+	//
+	// Any transient errors that occur during the reconciliation of Machines
+	// can be added as events to the Machine object and/or logged in the
+	// controller's output.
+	// +optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// Conditions defines current service state of the PacketMachine.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:subresource:status
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=packetmachines,shortName=pma,scope=Namespaced,categories=cluster-api
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this PacketMachine belongs"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.instanceStatus",description="Packet instance state"
+// +kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=".status.provisioningPercentage",description="Provisioning progress of the device, in percent",priority=1
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="Machine ready status"
+// +kubebuilder:printcolumn:name="InstanceID",type="string",JSONPath=".spec.providerID",description="Packet instance ID"
+// +kubebuilder:printcolumn:name="Machine",type="string",JSONPath=".metadata.ownerReferences[?(@.kind==\"Machine\")].name",description="Machine object which owns with this PacketMachine"
+
+// PacketMachine is the Schema for the packetmachines API.
+type PacketMachine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PacketMachineSpec   `json:"spec,omitempty"`
+	Status PacketMachineStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PacketMachineList contains a list of PacketMachine.
+type PacketMachineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PacketMachine `json:"items"`
+}
+
+// GetConditions returns the list of conditions for an PacketMachine API object.
+func (m *PacketMachine) GetConditions() clusterv1.Conditions {
+	return m.Status.Conditions
+}
+
+// SetConditions will set the given conditions on an PacketMachine object.
+func (m *PacketMachine) SetConditions(conditions clusterv1.Conditions) {
+	m.Status.Conditions = conditions
+}
+
+func init() {
+	objectTypes = append(objectTypes, &PacketMachine{}, &PacketMachineList{})
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// ConvertTo converts this PacketMachineTemplate to the Hub version (v1beta1).
+func (src *PacketMachineTemplate) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*infrav1.PacketMachineTemplate)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec.Template.ObjectMeta = src.Spec.Template.ObjectMeta
+	convertPacketMachineSpecToHub(&src.Spec.Template.Spec, &dst.Spec.Template.Spec)
+
+	// Manually restore data.
+	restored := &infrav1.PacketMachineTemplate{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	restoreHardwareReservationID(&restored.Spec.Template.Spec, &src.Spec.Template.Spec, &dst.Spec.Template.Spec)
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *PacketMachineTemplate) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*infrav1.PacketMachineTemplate)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec.Template.ObjectMeta = src.Spec.Template.ObjectMeta
+	convertPacketMachineSpecFromHub(&src.Spec.Template.Spec, &dst.Spec.Template.Spec)
+
+	// Preserve Hub data on down-conversion.
+	return utilconversion.MarshalData(src, dst)
+}
+
+// ConvertTo converts this PacketMachineTemplateList to the Hub version (v1beta1).
+func (src *PacketMachineTemplateList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*infrav1.PacketMachineTemplateList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]infrav1.PacketMachineTemplate, len(src.Items))
+	for i := range src.Items {
+		if err := src.Items[i].ConvertTo(&dst.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *PacketMachineTemplateList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*infrav1.PacketMachineTemplateList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]PacketMachineTemplate, len(src.Items))
+	for i := range src.Items {
+		if err := dst.Items[i].ConvertFrom(&src.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
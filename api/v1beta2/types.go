@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// PacketResourceStatus describes the status of a Packet resource.
+type PacketResourceStatus string
+
+// DeletePolicy describes how devices are deleted.
+type DeletePolicy string
+
+// Tags defines a slice of tags.
+type Tags []string
+
+// VIPManagerType describes if the VIP will be managed by CPEM or kube-vip or Equinix Metal Load Balancer,
+// or if the control plane endpoint is managed outside of the provider.
+type VIPManagerType string
+
+// Placement describes where devices are created.
+type Placement struct {
+	// Metro is the Equinix Metal metro, e.g. "da".
+	// +optional
+	Metro string `json:"metro,omitempty"`
+
+	// Facility is the Equinix Metal facility, e.g. "da11". Facilities are being retired in favor of metros.
+	// +optional
+	Facility string `json:"facility,omitempty"`
+}
+
+// SecretKeyReference references a key of a Secret in the same namespace as the referencing object.
+type SecretKeyReference struct {
+	// Name of the Secret.
+	Name string `json:"name"`
+
+	// Key within the Secret.
+	Key string `json:"key"`
+}
+
+// PacketMachineTemplateResource describes the data needed to create am PacketMachine from a template.
+type PacketMachineTemplateResource struct {
+	// Standard object's metadata, applied to the PacketMachines created from the template.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	ObjectMeta clusterv1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the specification of the desired behavior of the machine.
+	Spec PacketMachineSpec `json:"spec"`
+}
@@ -0,0 +1,386 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/emlb"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+const (
+	// driftMissing is a resource the management cluster refers to that does not exist.
+	driftMissing = "missing"
+	// driftOrphaned is a resource tagged for the cluster that no object of the management cluster refers to.
+	driftOrphaned = "orphaned"
+	// driftMisTagged is a resource of the cluster without the tags it would be found by.
+	driftMisTagged = "mis-tagged"
+)
+
+var errInvalidCluster = errors.New("invalid cluster, expected <namespace>/<name>")
+
+// drift is a difference between the desired state of a cluster and its Equinix Metal resources.
+type drift struct {
+	Kind     string
+	Resource string
+	ID       string
+	Detail   string
+
+	// device and namespace, name and clusterName are set for devices, to fix their drift.
+	device      *metal.Device
+	namespace   string
+	name        string
+	clusterName string
+}
+
+// auditor compares the objects of a cluster in the management cluster with its Equinix Metal resources.
+type auditor struct {
+	kubeClient  client.Client
+	metalClient *packet.Client
+	metalToken  string
+	in          *bufio.Reader
+	out         io.Writer
+	fix         bool
+	assumeYes   bool
+	namespace   string
+	clusterName string
+}
+
+func newAuditCommand() *cobra.Command {
+	var (
+		cluster   string
+		fix       bool
+		assumeYes bool
+	)
+
+	cmd := &cobra.Command{ //nolint:exhaustivestruct
+		Use:   "audit --cluster <namespace>/<name>",
+		Short: "Report the drift between a cluster and its Equinix Metal resources",
+		Long: `Compares the PacketCluster and PacketMachines of a Cluster of the management cluster pointed at by
+KUBECONFIG with the devices, Elastic IP, load balancer and VLANs of its Equinix Metal project, and
+reports the resources that are missing, orphaned or mis-tagged. With --fix, the tags of mis-tagged
+devices are restored and orphaned devices are deleted, each after confirmation unless --yes is set.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			namespace, name, ok := strings.Cut(cluster, "/")
+			if !ok || namespace == "" || name == "" {
+				return fmt.Errorf("%w: %q", errInvalidCluster, cluster)
+			}
+
+			metalAuthToken := os.Getenv(authTokenEnvVar)
+			if metalAuthToken == "" {
+				return fmt.Errorf("%s: %w", authTokenEnvVar, errMissingRequiredEnvVar)
+			}
+
+			kubeClient, err := newKubeClient()
+			if err != nil {
+				return err
+			}
+
+			a := &auditor{
+				kubeClient:  kubeClient,
+				metalClient: packet.NewClient(metalAuthToken),
+				metalToken:  metalAuthToken,
+				in:          bufio.NewReader(cmd.InOrStdin()),
+				out:         cmd.OutOrStdout(),
+				fix:         fix,
+				assumeYes:   assumeYes,
+				namespace:   namespace,
+				clusterName: name,
+			}
+			return a.run(cmd.Context())
+		},
+	}
+	cmd.Flags().StringVar(&cluster, "cluster", "", "Namespace and name of the Cluster to audit, as <namespace>/<name>")
+	cmd.Flags().BoolVar(&fix, "fix", false, "Restore the tags of mis-tagged devices and delete orphaned devices")
+	cmd.Flags().BoolVar(&assumeYes, "yes", false, "Do not ask for confirmation before each fix")
+	_ = cmd.MarkFlagRequired("cluster")
+
+	return cmd
+}
+
+func newKubeClient() (client.Client, error) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to set up the scheme: %w", err)
+	}
+	if err := infrav1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to set up the scheme: %w", err)
+	}
+	restConfig, err := config.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the kubeconfig: %w", err)
+	}
+	kubeClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the management cluster client: %w", err)
+	}
+	return kubeClient, nil
+}
+
+func (a *auditor) run(ctx context.Context) error {
+	cluster := &clusterv1.Cluster{}
+	if err := a.kubeClient.Get(ctx, client.ObjectKey{Namespace: a.namespace, Name: a.clusterName}, cluster); err != nil {
+		return fmt.Errorf("failed to get Cluster %s/%s: %w", a.namespace, a.clusterName, err)
+	}
+	if cluster.Spec.InfrastructureRef == nil {
+		return fmt.Errorf("%w: Cluster %s/%s has no infrastructureRef", errInvalidCluster, a.namespace, a.clusterName)
+	}
+	packetCluster := &infrav1.PacketCluster{}
+	if err := a.kubeClient.Get(ctx, client.ObjectKey{Namespace: a.namespace, Name: cluster.Spec.InfrastructureRef.Name}, packetCluster); err != nil {
+		return fmt.Errorf("failed to get PacketCluster %s/%s: %w", a.namespace, cluster.Spec.InfrastructureRef.Name, err)
+	}
+
+	clusterLabels := client.MatchingLabels{clusterv1.ClusterNameLabel: a.clusterName}
+	machines := &infrav1.PacketMachineList{}
+	if err := a.kubeClient.List(ctx, machines, client.InNamespace(a.namespace), clusterLabels); err != nil {
+		return fmt.Errorf("failed to list the PacketMachines of Cluster %s/%s: %w", a.namespace, a.clusterName, err)
+	}
+	pools := &infrav1.PacketMachinePoolList{}
+	if err := a.kubeClient.List(ctx, pools, client.InNamespace(a.namespace), clusterLabels); err != nil {
+		return fmt.Errorf("failed to list the PacketMachinePools of Cluster %s/%s: %w", a.namespace, a.clusterName, err)
+	}
+
+	projectID := packetCluster.Spec.ProjectID
+	devices, err := a.metalClient.GetDevicesByTags(ctx, projectID, []string{
+		packet.GenerateClusterTag(a.clusterName),
+		packet.GenerateNamespaceTag(a.namespace),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list the devices of Cluster %s/%s: %w", a.namespace, a.clusterName, err)
+	}
+
+	drifts := compareDevices(a.namespace, a.clusterName, machines.Items, pools.Items, devices)
+	for i := range drifts {
+		d := &drifts[i]
+		if d.Kind != driftMissing || d.device != nil {
+			continue
+		}
+		// The device is not tagged for the cluster, it may still exist with other tags.
+		dev, resp, err := a.metalClient.GetDevice(ctx, d.ID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		switch {
+		case resp != nil && resp.StatusCode == http.StatusNotFound:
+		case err != nil:
+			return fmt.Errorf("failed to get device %s: %w", d.ID, err)
+		default:
+			d.Kind = driftMisTagged
+			d.Detail = fmt.Sprintf("device of PacketMachine %s is not tagged for the cluster", d.name)
+			d.device = dev
+		}
+	}
+
+	networkDrifts, err := a.compareNetwork(ctx, packetCluster)
+	if err != nil {
+		return err
+	}
+	drifts = append(drifts, networkDrifts...)
+
+	if err := printDrifts(a.out, drifts); err != nil {
+		return err
+	}
+	if !a.fix {
+		return nil
+	}
+	return a.fixDrifts(ctx, drifts)
+}
+
+// compareDevices compares the devices tagged for a cluster with the devices its PacketMachines refer to. Devices of
+// its PacketMachinePools and of PacketDeviceClaims are managed through them and are not orphaned.
+func compareDevices(namespace, clusterName string, machines []infrav1.PacketMachine, pools []infrav1.PacketMachinePool, devices []metal.Device) []drift {
+	var drifts []drift
+
+	byID := map[string]*metal.Device{}
+	for i := range devices {
+		byID[devices[i].GetId()] = &devices[i]
+	}
+
+	referenced := map[string]bool{}
+	for i := range machines {
+		machine := &machines[i]
+		machineScope := &scope.MachineScope{PacketMachine: machine}
+		deviceID := machineScope.GetDeviceID()
+		if deviceID == "" {
+			continue
+		}
+		referenced[deviceID] = true
+
+		dev, ok := byID[deviceID]
+		if !ok {
+			drifts = append(drifts, drift{
+				Kind: driftMissing, Resource: "device", ID: deviceID,
+				Detail:    fmt.Sprintf("device of PacketMachine %s does not exist", machine.Name),
+				namespace: namespace, name: machine.Name, clusterName: clusterName,
+			})
+			continue
+		}
+		if !packet.ItemsInList(dev.Tags, packet.DefaultCreateTags(namespace, machine.Name, clusterName)) {
+			drifts = append(drifts, drift{
+				Kind: driftMisTagged, Resource: "device", ID: deviceID,
+				Detail: fmt.Sprintf("device of PacketMachine %s does not have its machine tag", machine.Name),
+				device: dev, namespace: namespace, name: machine.Name, clusterName: clusterName,
+			})
+		}
+	}
+
+	poolTags := make([]string, 0, len(pools))
+	for i := range pools {
+		poolTags = append(poolTags, packet.GenerateMachinePoolTag(pools[i].Name))
+	}
+	for i := range devices {
+		dev := &devices[i]
+		if referenced[dev.GetId()] || hasAnyTag(dev.Tags, poolTags) {
+			continue
+		}
+		if _, ok := packet.DeviceClaimFromTags(dev.Tags); ok {
+			continue
+		}
+		drifts = append(drifts, drift{
+			Kind: driftOrphaned, Resource: "device", ID: dev.GetId(),
+			Detail: fmt.Sprintf("device %s is not used by any PacketMachine or PacketMachinePool", dev.GetHostname()),
+			device: dev,
+		})
+	}
+
+	return drifts
+}
+
+func hasAnyTag(tags, candidates []string) bool {
+	for _, candidate := range candidates {
+		if packet.ItemsInList(tags, []string{candidate}) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareNetwork checks that the Elastic IP, load balancer and VLANs of the cluster exist.
+func (a *auditor) compareNetwork(ctx context.Context, packetCluster *infrav1.PacketCluster) ([]drift, error) {
+	var drifts []drift
+	projectID := packetCluster.Spec.ProjectID
+
+	switch packetCluster.Spec.VIPManager {
+	case infrav1.CPEMID, infrav1.KUBEVIPID:
+		_, err := a.metalClient.GetIPByClusterIdentifier(ctx, a.namespace, a.clusterName, projectID)
+		switch {
+		case errors.Is(err, packet.ErrControlPlanEndpointNotFound):
+			drifts = append(drifts, drift{Kind: driftMissing, Resource: "elastic IP", Detail: "no Elastic IP is tagged for the control plane"})
+		case err != nil:
+			return nil, fmt.Errorf("failed to get the Elastic IP of the cluster: %w", err)
+		}
+	case infrav1.EMLBVIPID:
+		lbID := emlb.LoadBalancerID(packetCluster)
+		if lbID == "" {
+			break
+		}
+		lbs, _, err := emlb.NewEMLB(a.metalToken, projectID, packetCluster.Spec.Metro).GetLoadBalancers(ctx) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		if err != nil {
+			return nil, fmt.Errorf("failed to list load balancers: %w", err)
+		}
+		found := false
+		for _, lb := range lbs.GetLoadbalancers() {
+			found = found || lb.GetId() == lbID
+		}
+		if !found {
+			drifts = append(drifts, drift{Kind: driftMissing, Resource: "load balancer", ID: lbID, Detail: "load balancer of the PacketCluster does not exist"})
+		}
+	}
+
+	for _, vlan := range packetCluster.Spec.VLANs {
+		vnet, err := a.metalClient.GetVLAN(ctx, a.clusterName, projectID, vlan.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get VLAN %s: %w", vlan.Name, err)
+		}
+		if vnet == nil {
+			drifts = append(drifts, drift{Kind: driftMissing, Resource: "VLAN", Detail: fmt.Sprintf("VLAN %s does not exist", vlan.Name)})
+		}
+	}
+
+	return drifts, nil
+}
+
+func printDrifts(out io.Writer, drifts []drift) error {
+	if len(drifts) == 0 {
+		_, err := fmt.Fprintln(out, "No drift found")
+		return err //nolint:wrapcheck
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DRIFT\tRESOURCE\tID\tDETAIL")
+	for _, d := range drifts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Kind, d.Resource, d.ID, d.Detail)
+	}
+	return w.Flush() //nolint:wrapcheck
+}
+
+// fixDrifts restores the tags of mis-tagged devices and deletes orphaned devices. Missing resources are left to the
+// controllers, which create them again.
+func (a *auditor) fixDrifts(ctx context.Context, drifts []drift) error {
+	var errs []error
+	for _, d := range drifts {
+		switch {
+		case d.Kind == driftMisTagged && d.device != nil:
+			if !a.confirm(fmt.Sprintf("Restore the tags of device %s of PacketMachine %s?", d.ID, d.name)) {
+				continue
+			}
+			if err := a.metalClient.MigrateDeviceTags(ctx, d.device, d.namespace, d.name, d.clusterName); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			fmt.Fprintf(a.out, "Restored the tags of device %s\n", d.ID)
+		case d.Kind == driftOrphaned && d.device != nil:
+			if !a.confirm(fmt.Sprintf("Delete orphaned device %s (%s)?", d.ID, d.device.GetHostname())) {
+				continue
+			}
+			_, err := a.metalClient.DevicesApi.DeleteDevice(ctx, d.ID).ForceDelete(ptr.Deref(d.device.Locked, false)).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to delete device %s: %w", d.ID, err))
+				continue
+			}
+			fmt.Fprintf(a.out, "Deleted device %s\n", d.ID)
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+func (a *auditor) confirm(question string) bool {
+	if a.assumeYes {
+		return true
+	}
+	fmt.Fprintf(a.out, "%s [y/N] ", question)
+	answer, _ := a.in.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+)
+
+func TestCompareDevices(t *testing.T) {
+	g := NewWithT(t)
+
+	machine := func(name, deviceID string) infrav1.PacketMachine {
+		m := infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		if deviceID != "" {
+			m.Spec.ProviderID = ptr.To("equinixmetal://" + deviceID)
+		}
+		return m
+	}
+	device := func(id string, tags ...string) metal.Device {
+		return metal.Device{Id: ptr.To(id), Hostname: ptr.To(id), Tags: tags}
+	}
+	clusterTags := []string{packet.GenerateClusterTag("capi"), packet.GenerateNamespaceTag("default")}
+
+	machines := []infrav1.PacketMachine{
+		machine("tagged", "dev-tagged"),
+		machine("untagged", "dev-untagged"),
+		machine("gone", "dev-gone"),
+		machine("provisioning", ""),
+	}
+	pools := []infrav1.PacketMachinePool{{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pool"}}}
+	devices := []metal.Device{
+		device("dev-tagged", packet.DefaultCreateTags("default", "tagged", "capi")...),
+		device("dev-untagged", clusterTags...),
+		device("dev-pool", append(clusterTags, packet.GenerateMachinePoolTag("pool"))...),
+		device("dev-orphan", clusterTags...),
+	}
+
+	drifts := compareDevices("default", "capi", machines, pools, devices)
+
+	got := map[string]string{}
+	for _, d := range drifts {
+		got[d.ID] = d.Kind
+	}
+	g.Expect(got).To(Equal(map[string]string{
+		"dev-untagged": driftMisTagged,
+		"dev-gone":     driftMissing,
+		"dev-orphan":   driftOrphaned,
+	}))
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Helps operators inspect the Equinix Metal resources of the clusters managed by the provider.
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	authTokenEnvVar = "PACKET_API_KEY" //nolint:gosec
+)
+
+var errMissingRequiredEnvVar = errors.New("required environment variable not set")
+
+func main() {
+	rootCmd := &cobra.Command{ //nolint:exhaustivestruct
+		Use:   "capp-helper",
+		Short: "Inspect the Equinix Metal resources of the clusters managed by the provider",
+	}
+	rootCmd.AddCommand(newAuditCommand())
+
+	if err := rootCmd.ExecuteContext(context.Background()); err != nil {
+		os.Exit(1)
+	}
+}
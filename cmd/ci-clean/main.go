@@ -42,10 +42,24 @@ const (
 var errMissingRequiredEnvVar = errors.New("required environment variable not set")
 
 func main() {
+	var (
+		output           string
+		detailedExitCode bool
+	)
+	c := &cleaner{out: os.Stdout}
+
 	rootCmd := &cobra.Command{ //nolint:exhaustivestruct
 		Use:   "ci-clean",
 		Short: "Clean up any stray resources in CI",
 		RunE: func(_ *cobra.Command, _ []string) error {
+			switch output {
+			case outputText:
+			case outputJSON:
+				c.json = true
+			default:
+				return fmt.Errorf("%w: %q", errInvalidOutput, output)
+			}
+
 			metalAuthToken := os.Getenv(authTokenEnvVar)
 			if metalAuthToken == "" {
 				return fmt.Errorf("%s: %w", authTokenEnvVar, errMissingRequiredEnvVar)
@@ -56,16 +70,24 @@ func main() {
 				return fmt.Errorf("%s: %w", projectIDEnvVar, errMissingRequiredEnvVar)
 			}
 
-			return cleanup(context.Background(), metalAuthToken, metalProjectID) //nolint:wrapcheck
+			return cleanup(context.Background(), c, metalAuthToken, metalProjectID) //nolint:wrapcheck
 		},
 	}
+	rootCmd.Flags().StringVarP(&output, "output", "o", outputText,
+		"Output format, text or json. The json output is a report of the deleted resources, written once done")
+	rootCmd.Flags().BoolVar(&detailedExitCode, "detailed-exitcode", false,
+		"Exit with 2 when resources were deleted, 0 when there was nothing to clean up and 1 on errors")
 
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	err := rootCmd.Execute()
+	if c.json {
+		if err := c.writeJSON(err); err != nil {
+			os.Exit(exitError)
+		}
 	}
+	os.Exit(c.exitCode(err, detailedExitCode))
 }
 
-func cleanup(ctx context.Context, metalAuthToken, metalProjectID string) error {
+func cleanup(ctx context.Context, c *cleaner, metalAuthToken, metalProjectID string) error {
 	metalClient := packet.NewClient(metalAuthToken)
 	var errs []error
 
@@ -74,7 +96,7 @@ func cleanup(ctx context.Context, metalAuthToken, metalProjectID string) error {
 		return fmt.Errorf("failed to list devices: %w", err)
 	}
 
-	if err := deleteDevices(ctx, metalClient, *devices); err != nil {
+	if err := deleteDevices(ctx, c, metalClient, *devices); err != nil {
 		errs = append(errs, err)
 	}
 
@@ -83,7 +105,7 @@ func cleanup(ctx context.Context, metalAuthToken, metalProjectID string) error {
 		return fmt.Errorf("failed to list ip addresses: %w", err)
 	}
 
-	if err := deleteIPs(ctx, metalClient, *ips); err != nil {
+	if err := deleteIPs(ctx, c, metalClient, *ips); err != nil {
 		errs = append(errs, err)
 	}
 
@@ -92,7 +114,7 @@ func cleanup(ctx context.Context, metalAuthToken, metalProjectID string) error {
 		return fmt.Errorf("failed to list ssh keys: %w", err)
 	}
 
-	if err := deleteKeys(ctx, metalClient, *keys); err != nil {
+	if err := deleteKeys(ctx, c, metalClient, *keys); err != nil {
 		errs = append(errs, err)
 	}
 
@@ -103,7 +125,7 @@ func cleanup(ctx context.Context, metalAuthToken, metalProjectID string) error {
 		return fmt.Errorf("failed to list load balancer pools: %w", err)
 	}
 
-	if err := deleteEMLBPools(ctx, emlbClient, loadBalancerPools); err != nil {
+	if err := deleteEMLBPools(ctx, c, emlbClient, loadBalancerPools); err != nil {
 		errs = append(errs, err)
 	}
 
@@ -112,23 +134,24 @@ func cleanup(ctx context.Context, metalAuthToken, metalProjectID string) error {
 		return fmt.Errorf("failed to list load balancers: %w", err)
 	}
 
-	if err := deleteEMLBs(ctx, emlbClient, loadBalancers); err != nil {
+	if err := deleteEMLBs(ctx, c, emlbClient, loadBalancers); err != nil {
 		errs = append(errs, err)
 	}
 
 	return kerrors.NewAggregate(errs)
 }
 
-func deleteDevices(ctx context.Context, metalClient *packet.Client, devices metal.DeviceList) error {
+func deleteDevices(ctx context.Context, c *cleaner, metalClient *packet.Client, devices metal.DeviceList) error {
 	var errs []error
 
 	for _, d := range devices.Devices {
 		if time.Since(d.GetCreatedAt()) > 4*time.Hour {
-			hostname := d.GetHostname()
-			fmt.Printf("Deleting device: %s\n", hostname)
-			_, err := metalClient.DevicesApi.DeleteDevice(ctx, d.GetId()).ForceDelete(false).Execute()
+			err := c.delete("device", d.GetId(), d.GetHostname(), d.GetCreatedAt(), func() error {
+				_, err := metalClient.DevicesApi.DeleteDevice(ctx, d.GetId()).ForceDelete(false).Execute()
+				return err
+			})
 			if err != nil {
-				errs = append(errs, fmt.Errorf("failed to delete device %q: %w", hostname, err))
+				errs = append(errs, err)
 			}
 		}
 	}
@@ -136,7 +159,7 @@ func deleteDevices(ctx context.Context, metalClient *packet.Client, devices meta
 	return kerrors.NewAggregate(errs)
 }
 
-func deleteIPs(ctx context.Context, metalClient *packet.Client, ips metal.IPReservationList) error {
+func deleteIPs(ctx context.Context, c *cleaner, metalClient *packet.Client, ips metal.IPReservationList) error {
 	var errs []error
 
 	for _, reservation := range ips.IpAddresses {
@@ -147,10 +170,12 @@ func deleteIPs(ctx context.Context, metalClient *packet.Client, ips metal.IPRese
 		if ip != nil && time.Since(ip.GetCreatedAt()) > 4*time.Hour {
 			for _, tag := range ip.Tags {
 				if strings.HasPrefix(tag, "cluster-api-provider-packet:cluster-id:") || strings.HasPrefix(tag, "usage=cloud-provider-equinix-metal-auto") {
-					fmt.Printf("Deleting IP: %s\n", ip.GetAddress())
-
-					if _, err := metalClient.IPAddressesApi.DeleteIPAddress(ctx, ip.GetId()).Execute(); err != nil {
-						errs = append(errs, fmt.Errorf("failed to delete ip address %q: %w", ip.GetAddress(), err))
+					err := c.delete("ip-address", ip.GetId(), ip.GetAddress(), ip.GetCreatedAt(), func() error {
+						_, err := metalClient.IPAddressesApi.DeleteIPAddress(ctx, ip.GetId()).Execute()
+						return err
+					})
+					if err != nil {
+						errs = append(errs, err)
 					}
 
 					break
@@ -162,15 +187,17 @@ func deleteIPs(ctx context.Context, metalClient *packet.Client, ips metal.IPRese
 	return kerrors.NewAggregate(errs)
 }
 
-func deleteKeys(ctx context.Context, metalClient *packet.Client, keys metal.SSHKeyList) error {
+func deleteKeys(ctx context.Context, c *cleaner, metalClient *packet.Client, keys metal.SSHKeyList) error {
 	var errs []error
 
 	for _, k := range keys.SshKeys {
 		if time.Since(k.GetCreatedAt()) > 4*time.Hour {
-			fmt.Printf("Deleting SSH Key: %s\n", k.GetLabel())
-			_, err := metalClient.SSHKeysApi.DeleteSSHKey(ctx, k.GetId()).Execute()
+			err := c.delete("ssh-key", k.GetId(), k.GetLabel(), k.GetCreatedAt(), func() error {
+				_, err := metalClient.SSHKeysApi.DeleteSSHKey(ctx, k.GetId()).Execute()
+				return err
+			})
 			if err != nil {
-				errs = append(errs, fmt.Errorf("failed to delete SSH Key %q: %w", k.GetLabel(), err))
+				errs = append(errs, err)
 			}
 		}
 	}
@@ -178,15 +205,17 @@ func deleteKeys(ctx context.Context, metalClient *packet.Client, keys metal.SSHK
 	return kerrors.NewAggregate(errs)
 }
 
-func deleteEMLBPools(ctx context.Context, emlbClient *emlb.EMLB, pools *lbaas.LoadBalancerPoolCollection) error {
+func deleteEMLBPools(ctx context.Context, c *cleaner, emlbClient *emlb.EMLB, pools *lbaas.LoadBalancerPoolCollection) error {
 	var errs []error
 
 	for _, pool := range pools.Pools {
 		if time.Since(pool.GetCreatedAt()) > 4*time.Hour {
-			fmt.Printf("Deleting Load Balancer: %s\n", pool.GetName())
-			_, err := emlbClient.DeleteLoadBalancerPool(ctx, pool.GetId())
+			err := c.delete("load-balancer-pool", pool.GetId(), pool.GetName(), pool.GetCreatedAt(), func() error {
+				_, err := emlbClient.DeleteLoadBalancerPool(ctx, pool.GetId())
+				return err
+			})
 			if err != nil {
-				errs = append(errs, fmt.Errorf("failed to delete Load Balancer %q: %w", pool.GetName(), err))
+				errs = append(errs, err)
 			}
 		}
 	}
@@ -194,15 +223,17 @@ func deleteEMLBPools(ctx context.Context, emlbClient *emlb.EMLB, pools *lbaas.Lo
 	return kerrors.NewAggregate(errs)
 }
 
-func deleteEMLBs(ctx context.Context, emlbClient *emlb.EMLB, lbs *lbaas.LoadBalancerCollection) error {
+func deleteEMLBs(ctx context.Context, c *cleaner, emlbClient *emlb.EMLB, lbs *lbaas.LoadBalancerCollection) error {
 	var errs []error
 
 	for _, lb := range lbs.Loadbalancers {
 		if time.Since(lb.GetCreatedAt()) > 4*time.Hour {
-			fmt.Printf("Deleting Load Balancer: %s\n", lb.GetName())
-			_, err := emlbClient.DeleteLoadBalancer(ctx, lb.GetId())
+			err := c.delete("load-balancer", lb.GetId(), lb.GetName(), lb.GetCreatedAt(), func() error {
+				_, err := emlbClient.DeleteLoadBalancer(ctx, lb.GetId())
+				return err
+			})
 			if err != nil {
-				errs = append(errs, fmt.Errorf("failed to delete Load Balancer %q: %w", lb.GetName(), err))
+				errs = append(errs, err)
 			}
 		}
 	}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+
+	// exitNothingToClean is the exit code when no resource had to be deleted.
+	exitNothingToClean = 0
+	// exitError is the exit code when resources could not be listed or deleted.
+	exitError = 1
+	// exitCleaned is the exit code when resources were deleted, with --detailed-exitcode.
+	exitCleaned = 2
+)
+
+var errInvalidOutput = errors.New("invalid output format")
+
+// action is what was done with a stray resource.
+type action string
+
+const (
+	actionDeleted = action("deleted")
+	actionFailed  = action("failed")
+)
+
+// result is the outcome of the deletion of a stray resource.
+type result struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Age    string `json:"age"`
+	Action action `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+// report is the machine-readable output of ci-clean.
+type report struct {
+	Resources []result `json:"resources"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// cleaner deletes stray resources and records what it did, printing it as it goes in the text output, or as a
+// report once done in the JSON output.
+type cleaner struct {
+	out    io.Writer
+	json   bool
+	report report
+}
+
+// delete deletes a resource with del and records the result.
+func (c *cleaner) delete(resourceType, id, name string, created time.Time, del func() error) error {
+	if !c.json {
+		fmt.Fprintf(c.out, "Deleting %s: %s\n", resourceType, name)
+	}
+
+	res := result{
+		Type:   resourceType,
+		ID:     id,
+		Name:   name,
+		Age:    time.Since(created).Round(time.Second).String(),
+		Action: actionDeleted,
+	}
+	err := del()
+	if err != nil {
+		res.Action = actionFailed
+		res.Error = err.Error()
+		err = fmt.Errorf("failed to delete %s %q: %w", resourceType, name, err)
+	}
+	c.report.Resources = append(c.report.Resources, res)
+	return err
+}
+
+// writeJSON writes the report, along with the error ci-clean failed with, if any.
+func (c *cleaner) writeJSON(err error) error {
+	rep := c.report
+	if rep.Resources == nil {
+		rep.Resources = []result{}
+	}
+	if err != nil {
+		rep.Error = err.Error()
+	}
+
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+// exitCode returns the exit code of ci-clean. Unless detailed, cleaning up resources succeeds like having nothing to
+// clean up.
+func (c *cleaner) exitCode(err error, detailed bool) int {
+	if err != nil {
+		return exitError
+	}
+	if detailed && len(c.report.Resources) > 0 {
+		return exitCleaned
+	}
+	return exitNothingToClean
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCleaner(t *testing.T) {
+	g := NewWithT(t)
+
+	var out bytes.Buffer
+	c := &cleaner{out: &out, json: true}
+	g.Expect(c.exitCode(nil, true)).To(Equal(exitNothingToClean))
+
+	created := time.Now().Add(-5 * time.Hour)
+	g.Expect(c.delete("device", "id-1", "device-1", created, func() error { return nil })).To(Succeed())
+	g.Expect(c.exitCode(nil, false)).To(Equal(exitNothingToClean))
+	g.Expect(c.exitCode(nil, true)).To(Equal(exitCleaned))
+
+	err := c.delete("ssh-key", "id-2", "key", created, func() error { return errors.New("forbidden") })
+	g.Expect(err).To(MatchError(ContainSubstring(`failed to delete ssh-key "key": forbidden`)))
+	g.Expect(c.exitCode(err, true)).To(Equal(exitError))
+
+	g.Expect(c.writeJSON(err)).To(Succeed())
+	var rep report
+	g.Expect(json.Unmarshal(out.Bytes(), &rep)).To(Succeed())
+	g.Expect(rep.Error).ToNot(BeEmpty())
+	g.Expect(rep.Resources).To(HaveLen(2))
+	g.Expect(rep.Resources[0]).To(Equal(result{Type: "device", ID: "id-1", Name: "device-1", Age: rep.Resources[0].Age, Action: actionDeleted}))
+	g.Expect(rep.Resources[0].Age).To(HavePrefix("5h0m"))
+	g.Expect(rep.Resources[1].Action).To(Equal(actionFailed))
+	g.Expect(rep.Resources[1].Error).To(Equal("forbidden"))
+}
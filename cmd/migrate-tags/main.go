@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Migrates the tags of the devices created by packngo based releases of the provider to the current tags.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+)
+
+const (
+	authTokenEnvVar = "PACKET_API_KEY" //nolint:gosec
+	projectIDEnvVar = "PROJECT_ID"
+)
+
+var errMissingRequiredEnvVar = errors.New("required environment variable not set")
+
+func main() {
+	var namespace string
+	var dryRun bool
+
+	rootCmd := &cobra.Command{ //nolint:exhaustivestruct
+		Use:   "migrate-tags",
+		Short: "Migrate the tags of devices created by packngo based releases to the current tags",
+		Long: `Finds the devices of the project tagged by packngo based releases of the provider, matches them
+with the Machines of the namespace in the management cluster pointed at by KUBECONFIG, and rewrites
+their tags so that they are found by the current releases instead of being created again.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			metalAuthToken := os.Getenv(authTokenEnvVar)
+			if metalAuthToken == "" {
+				return fmt.Errorf("%s: %w", authTokenEnvVar, errMissingRequiredEnvVar)
+			}
+
+			metalProjectID := os.Getenv(projectIDEnvVar)
+			if metalProjectID == "" {
+				return fmt.Errorf("%s: %w", projectIDEnvVar, errMissingRequiredEnvVar)
+			}
+
+			return migrate(cmd.Context(), metalAuthToken, metalProjectID, namespace, dryRun)
+		},
+	}
+	rootCmd.Flags().StringVar(&namespace, "namespace", "default", "Namespace of the Clusters the devices belong to")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only print the devices that would be migrated")
+
+	if err := rootCmd.ExecuteContext(context.Background()); err != nil {
+		os.Exit(1)
+	}
+}
+
+func migrate(ctx context.Context, metalAuthToken, metalProjectID, namespace string, dryRun bool) error {
+	metalClient := packet.NewClient(metalAuthToken)
+
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("failed to set up the scheme: %w", err)
+	}
+	restConfig, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load the kubeconfig: %w", err)
+	}
+	kubeClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("failed to create the management cluster client: %w", err)
+	}
+
+	machines := &clusterv1.MachineList{}
+	if err := kubeClient.List(ctx, machines, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list the Machines of namespace %s: %w", namespace, err)
+	}
+
+	devices, err := metalClient.GetLegacyDevices(ctx, metalProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	var errs []error
+	for i := range devices {
+		dev := &devices[i]
+		tags, _ := packet.GetLegacyDeviceTags(dev)
+
+		machine := findMachine(machines.Items, tags)
+		if machine == nil {
+			fmt.Printf("Skipping device %s (%s): no Machine %q of Cluster %q in namespace %s\n",
+				dev.GetId(), dev.GetHostname(), tags.Machine, tags.ClusterName, namespace)
+			continue
+		}
+
+		fmt.Printf("Migrating device %s (%s) of Machine %s/%s\n", dev.GetId(), dev.GetHostname(), namespace, machine.Name)
+		if dryRun {
+			continue
+		}
+		if err := migrateDevice(ctx, metalClient, dev, machine); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return kerrors.NewAggregate(errs)
+}
+
+func migrateDevice(ctx context.Context, metalClient *packet.Client, dev *metal.Device, machine *clusterv1.Machine) error {
+	if err := metalClient.MigrateDeviceTags(ctx, dev, machine.Namespace, machine.Name, machine.Spec.ClusterName); err != nil {
+		return fmt.Errorf("failed to migrate device %q: %w", dev.GetHostname(), err)
+	}
+	return nil
+}
+
+// findMachine returns the Machine of the cluster of the legacy tags whose name or UID is in the machine tag.
+func findMachine(machines []clusterv1.Machine, tags packet.LegacyDeviceTags) *clusterv1.Machine {
+	if tags.ClusterName == "" || tags.Machine == "" {
+		return nil
+	}
+	for i := range machines {
+		machine := &machines[i]
+		if machine.Spec.ClusterName != tags.ClusterName {
+			continue
+		}
+		if machine.Name == tags.Machine || string(machine.UID) == tags.Machine {
+			return machine
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Serves Cluster API Runtime SDK topology mutation hooks for Packet templates.
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/spf13/pflag"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/logs"
+	logsv1 "k8s.io/component-base/logs/api/v1"
+	"k8s.io/klog/v2"
+	runtimecatalog "sigs.k8s.io/cluster-api/exp/runtime/catalog"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/exp/runtime/server"
+	"sigs.k8s.io/cluster-api/util/flags"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"sigs.k8s.io/cluster-api-provider-packet/internal/runtimeextensions"
+)
+
+var (
+	setupLog       = ctrl.Log.WithName("setup")
+	catalog        = runtimecatalog.New()
+	webhookPort    int
+	webhookCertDir string
+	tlsOptions     = flags.TLSOptions{}
+	logOptions     = logs.NewOptions()
+)
+
+func init() {
+	_ = runtimehooksv1.AddToCatalog(catalog)
+}
+
+func initFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&webhookPort,
+		"webhook-port",
+		9443,
+		"Webhook Server port",
+	)
+
+	fs.StringVar(&webhookCertDir,
+		"webhook-cert-dir",
+		"/tmp/k8s-webhook-server/serving-certs",
+		"Webhook Server Certificate Directory, is the directory that contains the server key and certificate",
+	)
+
+	flags.AddTLSOptions(fs,
+		&tlsOptions,
+	)
+
+	logsv1.AddFlags(logOptions, fs)
+}
+
+func main() {
+	initFlags(pflag.CommandLine)
+	pflag.CommandLine.SetNormalizeFunc(cliflag.WordSepNormalizeFunc)
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+
+	if err := logsv1.ValidateAndApply(logOptions, nil); err != nil {
+		setupLog.Error(err, "unable to start extension")
+		os.Exit(1)
+	}
+
+	// klog.Background will automatically use the right logger.
+	ctrl.SetLogger(klog.Background())
+
+	tlsOptionOverrides, err := flags.GetTLSOptionOverrideFuncs(tlsOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to add TLS settings to the webhook server")
+		os.Exit(1)
+	}
+
+	webhookServer, err := server.New(server.Options{
+		Catalog: catalog,
+		Port:    webhookPort,
+		CertDir: webhookCertDir,
+		TLSOpts: tlsOptionOverrides,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to create webhook server")
+		os.Exit(1)
+	}
+
+	handler := runtimeextensions.NewTopologyMutationHandler()
+
+	for _, h := range []server.ExtensionHandler{
+		{
+			Hook:        runtimehooksv1.GeneratePatches,
+			Name:        "generate-patches",
+			HandlerFunc: handler.GeneratePatches,
+		},
+		{
+			Hook:        runtimehooksv1.ValidateTopology,
+			Name:        "validate-topology",
+			HandlerFunc: handler.ValidateTopology,
+		},
+		{
+			Hook:        runtimehooksv1.DiscoverVariables,
+			Name:        "discover-variables",
+			HandlerFunc: handler.DiscoverVariables,
+		},
+	} {
+		if err := webhookServer.AddExtensionHandler(h); err != nil {
+			setupLog.Error(err, "unable to add extension handler", "name", h.Name)
+			os.Exit(1)
+		}
+	}
+
+	setupLog.Info("starting runtime extension server")
+	if err := webhookServer.Start(ctrl.SetupSignalHandler()); err != nil {
+		setupLog.Error(err, "problem running runtime extension server")
+		os.Exit(1)
+	}
+}
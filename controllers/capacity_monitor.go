@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	packet "sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+)
+
+// capacityLevels maps the capacity levels of the Equinix Metal capacity API to the values of capp_capacity_level,
+// so that lower values mean less capacity.
+var capacityLevels = map[string]float64{
+	"unavailable": 0,
+	"limited":     1,
+	"normal":      2,
+}
+
+var capacityLevel = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "capp_capacity_level",
+	Help: "Equinix Metal capacity of the plans and metros of the PacketMachineTemplates: 0 unavailable, 1 limited, 2 normal.",
+}, []string{"metro", "plan"})
+
+func init() {
+	metrics.Registry.MustRegister(capacityLevel)
+}
+
+// metroPlan is a plan in a metro.
+type metroPlan struct {
+	metro string
+	plan  string
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=packetmachinetemplates,verbs=get;list;watch
+
+// CapacityMonitor periodically exports the capacity of the plans and metros the PacketMachineTemplates create
+// devices with, so that operators can be warned before a scale-up fails for lack of capacity.
+type CapacityMonitor struct {
+	Client       client.Client
+	PacketClient *packet.Client
+
+	// Interval between two capacity queries.
+	Interval time.Duration
+}
+
+// Start exports the capacity until the context is cancelled. It implements manager.Runnable.
+func (r *CapacityMonitor) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("capacity-monitor")
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Export(ctx); err != nil {
+			log.Error(err, "failed to export capacity")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (r *CapacityMonitor) NeedLeaderElection() bool {
+	return true
+}
+
+// Export queries the capacity of the plans and metros of the PacketMachineTemplates once, and exports it.
+func (r *CapacityMonitor) Export(ctx context.Context) error {
+	wanted, err := r.metroPlans(ctx)
+	if err != nil {
+		return err
+	}
+
+	capacity, _, err := r.PacketClient.CapacityApi.FindCapacityForMetro(ctx).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the capacity of the metros: %w", err)
+	}
+
+	// Templates that are gone, and levels that are unknown, are not exported anymore.
+	capacityLevel.Reset()
+	for mp := range wanted {
+		level, ok := capacity.GetCapacity()[mp.metro][mp.plan]
+		if !ok {
+			continue
+		}
+		if value, ok := capacityLevels[level.GetLevel()]; ok {
+			capacityLevel.WithLabelValues(mp.metro, mp.plan).Set(value)
+		}
+	}
+	return nil
+}
+
+// metroPlans returns the plans and metros of the PacketMachineTemplates. Templates without a metro create devices
+// in the metro of their cluster, found with their cluster name label, and are skipped when it is unknown.
+func (r *CapacityMonitor) metroPlans(ctx context.Context) (sets.Set[metroPlan], error) {
+	templates := &infrav1.PacketMachineTemplateList{}
+	if err := r.Client.List(ctx, templates); err != nil {
+		return nil, fmt.Errorf("failed to list PacketMachineTemplates: %w", err)
+	}
+
+	wanted := sets.New[metroPlan]()
+	for _, template := range templates.Items {
+		spec := template.Spec.Template.Spec
+		metro := spec.Metro
+		if metro == "" && spec.Facility == "" {
+			if clusterName, ok := template.Labels[clusterv1.ClusterNameLabel]; ok {
+				packetCluster := &infrav1.PacketCluster{}
+				if err := r.Client.Get(ctx, client.ObjectKey{Namespace: template.Namespace, Name: clusterName}, packetCluster); err == nil {
+					metro = packetCluster.Spec.Metro
+				}
+			}
+		}
+		if metro == "" || spec.MachineType == "" {
+			continue
+		}
+		wanted.Insert(metroPlan{metro: strings.ToLower(metro), plan: spec.MachineType})
+	}
+	return wanted, nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func TestCapacityMonitorExport(t *testing.T) {
+	g := NewWithT(t)
+
+	metalClient := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.URL.Path).To(Equal("/capacity/metros"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"capacity": {
+			"da": {"c3.small.x86": {"level": "limited"}, "m3.large.x86": {"level": "normal"}},
+			"sv": {"c3.small.x86": {"level": "unavailable"}}
+		}}`))
+	}))
+
+	template := func(name, metro, plan string, labels map[string]string) *infrav1.PacketMachineTemplate {
+		return &infrav1.PacketMachineTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+			Spec: infrav1.PacketMachineTemplateSpec{Template: infrav1.PacketMachineTemplateResource{
+				Spec: infrav1.PacketMachineSpec{Metro: metro, MachineType: plan},
+			}},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scopetest.Scheme()).WithObjects(
+		template("control-plane", "DA", "c3.small.x86", nil),
+		// Templates without a metro use the one of their cluster.
+		template("workers", "", "c3.small.x86", map[string]string{clusterv1.ClusterNameLabel: "cluster"}),
+		&infrav1.PacketCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+			Spec:       infrav1.PacketClusterSpec{Metro: "sv"},
+		},
+	).Build()
+
+	monitor := &CapacityMonitor{Client: c, PacketClient: metalClient}
+	g.Expect(monitor.Export(context.Background())).To(Succeed())
+
+	g.Expect(testutil.CollectAndCount(capacityLevel)).To(Equal(2))
+	g.Expect(testutil.ToFloat64(capacityLevel.WithLabelValues("da", "c3.small.x86"))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(capacityLevel.WithLabelValues("sv", "c3.small.x86"))).To(Equal(0.0))
+}
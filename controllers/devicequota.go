@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+)
+
+// deviceQuotaRequeue is how often machines waiting for the device quota of their cluster check it again.
+const deviceQuotaRequeue = time.Minute
+
+// deviceQuotas tracks the device creations in progress of the clusters with a maxDevices, so that concurrent
+// reconciliations of PacketMachines and PacketMachinePools do not both take the last device of the quota.
+var deviceQuotas = &deviceQuotaTracker{clusters: map[string]*clusterDeviceQuota{}}
+
+// deviceQuotaTracker holds the device quota state of the clusters with device creations in progress.
+type deviceQuotaTracker struct {
+	mu       sync.Mutex
+	clusters map[string]*clusterDeviceQuota
+}
+
+// clusterDeviceQuota is the device quota state of a cluster.
+type clusterDeviceQuota struct {
+	// count serializes the counts of the devices of the cluster.
+	count sync.Mutex
+	// pending is the number of devices reserved and not created yet, users the number of reservations holding on to
+	// the state. Both are guarded by the mutex of the tracker.
+	pending int
+	users   int
+}
+
+// reserveDevices reserves up to want devices in the quota of the cluster, counting the devices tagged with the
+// cluster and the devices other reconciliations are creating. release must be called once the reserved devices are
+// created, or given up on. The reservation is only serialized with the other reservations of the same cluster, the
+// devices themselves are created concurrently.
+func reserveDevices(ctx context.Context, metalClient *packet.Client, cluster *clusterv1.Cluster, packetCluster *infrav1.PacketCluster, want int) (reserved int, release func(), err error) {
+	if packetCluster.Spec.MaxDevices == nil {
+		return want, func() {}, nil
+	}
+	return deviceQuotas.reserve(cluster.Namespace+"/"+cluster.Name, int(*packetCluster.Spec.MaxDevices), want, func() (int, error) {
+		devices, err := metalClient.GetDevicesByTags(ctx, packetCluster.Spec.ProjectID, []string{
+			packet.GenerateClusterTag(cluster.Name),
+			packet.GenerateNamespaceTag(cluster.Namespace),
+		})
+		return len(devices), err
+	})
+}
+
+// reserve reserves up to want devices out of the maxDevices of the cluster, of which count returns the number of
+// existing devices.
+func (t *deviceQuotaTracker) reserve(key string, maxDevices, want int, count func() (int, error)) (int, func(), error) {
+	t.mu.Lock()
+	quota, ok := t.clusters[key]
+	if !ok {
+		quota = &clusterDeviceQuota{}
+		t.clusters[key] = quota
+	}
+	quota.users++
+	t.mu.Unlock()
+
+	quota.count.Lock()
+	defer quota.count.Unlock()
+
+	// Take the devices being created before counting, so that a device created meanwhile is counted twice rather
+	// than not at all. Only releases change them until the count is unlocked.
+	t.mu.Lock()
+	pending := quota.pending
+	t.mu.Unlock()
+
+	existing, err := count()
+	if err != nil {
+		t.release(key, quota, 0)
+		return 0, nil, err
+	}
+
+	t.mu.Lock()
+	reserved := min(want, max(maxDevices-existing-pending, 0))
+	quota.pending += reserved
+	t.mu.Unlock()
+
+	var once sync.Once
+	return reserved, func() { once.Do(func() { t.release(key, quota, reserved) }) }, nil
+}
+
+// release gives back the devices reserved for the cluster, forgetting about the cluster once it has no reservations.
+func (t *deviceQuotaTracker) release(key string, quota *clusterDeviceQuota, reserved int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	quota.pending -= reserved
+	quota.users--
+	if quota.users == 0 {
+		delete(t.clusters, key)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expclusterv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func TestMachinePoolDeviceQuota(t *testing.T) {
+	g := NewWithT(t)
+
+	// The cluster already has the device of a PacketMachine, devices of other clusters do not count.
+	api := &fakeDeviceAPI{devices: []metal.Device{
+		{Id: ptr.To("machine"), Tags: packet.DefaultCreateTags(scopetest.Namespace, "machine", scopetest.ClusterName)},
+		{Id: ptr.To("other"), Tags: packet.DefaultCreateTags(scopetest.Namespace, "machine", "other")},
+	}}
+	metalClient := packettest.NewClient(t, api)
+	r := &PacketMachinePoolReconciler{PacketClient: metalClient}
+
+	bootstrap := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bootstrap", Namespace: scopetest.Namespace},
+		Data:       map[string][]byte{"value": []byte("#cloud-config\n")},
+	}
+	packetCluster := &infrav1.PacketCluster{
+		Spec: infrav1.PacketClusterSpec{ProjectID: "project", Metro: "da", MaxDevices: ptr.To[int32](3)},
+	}
+	packetMachinePool := &infrav1.PacketMachinePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: scopetest.Namespace},
+		Spec: infrav1.PacketMachinePoolSpec{Template: infrav1.PacketMachinePoolDeviceSpec{
+			OS:          "ubuntu_22_04",
+			MachineType: "c3.small.x86",
+		}},
+	}
+	poolScope, err := scope.NewMachinePoolScope(scope.MachinePoolScopeParams{
+		Client: fake.NewClientBuilder().WithScheme(scopetest.Scheme()).WithObjects(bootstrap).Build(),
+		Cluster: &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: scopetest.ClusterName, Namespace: scopetest.Namespace},
+			Status:     clusterv1.ClusterStatus{InfrastructureReady: true},
+		},
+		MachinePool: &expclusterv1.MachinePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: scopetest.Namespace},
+			Spec: expclusterv1.MachinePoolSpec{
+				Replicas: ptr.To[int32](4),
+				Template: clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{
+					Bootstrap: clusterv1.Bootstrap{DataSecretName: ptr.To("bootstrap")},
+				}},
+			},
+		},
+		PacketCluster:     packetCluster,
+		PacketMachinePool: packetMachinePool,
+		Patcher:           &scopetest.Patcher{},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	ctx := context.Background()
+
+	// The pool only gets the devices left in the quota of the cluster.
+	_, err = r.reconcile(ctx, poolScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(api.devices).To(HaveLen(4))
+	g.Expect(packetMachinePool.Spec.ProviderIDList).To(HaveLen(2))
+
+	result, err := r.reconcile(ctx, poolScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(deviceQuotaRequeue))
+	g.Expect(api.devices).To(HaveLen(4))
+	g.Expect(conditions.GetReason(packetMachinePool, infrav1.DevicesReadyCondition)).To(Equal(infrav1.DeviceQuotaExceededReason))
+
+	// Raising the quota lets the pool scale up to its replicas.
+	packetCluster.Spec.MaxDevices = ptr.To[int32](10)
+	_, err = r.reconcile(ctx, poolScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(api.devices).To(HaveLen(6))
+	g.Expect(packetMachinePool.Spec.ProviderIDList).To(HaveLen(4))
+	g.Expect(conditions.GetReason(packetMachinePool, infrav1.DevicesReadyCondition)).To(Equal(infrav1.ScalingUpReason))
+}
+
+func TestDeviceQuotaTracker(t *testing.T) {
+	g := NewWithT(t)
+
+	tracker := &deviceQuotaTracker{clusters: map[string]*clusterDeviceQuota{}}
+	existing := 1
+	count := func() (int, error) { return existing, nil }
+
+	// Devices being created count against the quota until they are released.
+	reserved, releaseFirst, err := tracker.reserve("ns/a", 3, 1, count)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reserved).To(Equal(1))
+	reserved, releaseSecond, err := tracker.reserve("ns/a", 3, 2, count)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reserved).To(Equal(1))
+	reserved, releaseNone, err := tracker.reserve("ns/a", 3, 1, count)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reserved).To(BeZero())
+	releaseNone()
+
+	// Other clusters have their own quota.
+	reserved, releaseOther, err := tracker.reserve("ns/b", 3, 2, count)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reserved).To(Equal(2))
+	releaseOther()
+
+	// Once created, the devices are counted instead.
+	existing = 3
+	releaseFirst()
+	releaseFirst()
+	releaseSecond()
+	reserved, releaseLast, err := tracker.reserve("ns/a", 3, 1, count)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(reserved).To(BeZero())
+	releaseLast()
+
+	g.Expect(tracker.clusters).To(BeEmpty())
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	packet "sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+)
+
+// IPReservationGarbageCollector periodically releases the control plane IP reservations of clusters that no longer
+// exist in the management cluster. ElasticIPs are deliberately kept when a cluster is deleted unless its
+// elasticIPReclaimPolicy is Release, see the PacketCluster docs, which otherwise slowly exhausts the project quota
+// over many create/delete cycles.
+//
+// Reservations are tagged with the name of their Cluster only, so one is kept while a Cluster of that name exists in
+// any namespace, and the projects swept must not be shared with clusters managed from elsewhere. Reservations still
+// referenced by a PacketCluster, as its Elastic IP or the host of its API server endpoint, and reservations carrying
+// tags of their own, such as adopted VIP reservations, are never released.
+type IPReservationGarbageCollector struct {
+	Client       client.Client
+	PacketClient *packet.Client
+
+	// Interval between two sweeps.
+	Interval time.Duration
+	// DryRun only logs the reservations that would be released.
+	DryRun bool
+	// ProjectIDs are swept in addition to the projects of the existing PacketClusters, so that reservations are
+	// also collected once the last cluster of a project is gone.
+	ProjectIDs []string
+}
+
+// Start sweeps the projects until the context is cancelled. It implements manager.Runnable.
+func (r *IPReservationGarbageCollector) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("ip-reservation-gc")
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Collect(ctx); err != nil {
+			log.Error(err, "failed to garbage collect IP reservations")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (r *IPReservationGarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Collect releases the stale control plane IP reservations once.
+func (r *IPReservationGarbageCollector) Collect(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	packetClusters := &infrav1.PacketClusterList{}
+	if err := r.Client.List(ctx, packetClusters); err != nil {
+		return fmt.Errorf("failed to list PacketClusters: %w", err)
+	}
+
+	var errs []error
+	clusters := sets.New[client.ObjectKey]()
+	// Reservations referenced by a PacketCluster, by ID or address, whatever their tags.
+	inUse := sets.New[string]()
+	projectIDs := sets.New[string](r.ProjectIDs...)
+	// Projects of clusters with their own credentials are swept with them.
+	projectClients := map[string]*packet.Client{}
+	for i := range packetClusters.Items {
+		packetCluster := &packetClusters.Items[i]
+		clusters.Insert(owningCluster(packetCluster))
+		inUse.Insert(packetCluster.Spec.VIPReservationID, packetCluster.Spec.ControlPlaneEndpoint.Host)
+		if eip := packetCluster.Status.ElasticIP; eip != nil {
+			inUse.Insert(eip.ReservationID, eip.Address)
+		}
+		if packetCluster.Spec.ProjectID == "" {
+			continue
+		}
+		projectIDs.Insert(packetCluster.Spec.ProjectID)
+		if packetCluster.Spec.CredentialsRef != nil {
+			metalClient, err := r.PacketClient.ClientForCluster(ctx, r.Client, packetCluster)
+			if err != nil {
+				// Leave the project alone rather than sweeping it with credentials that may not reach it.
+				errs = append(errs, err)
+			}
+			projectClients[packetCluster.Spec.ProjectID] = metalClient
+		}
+	}
+	inUse.Delete("")
+	// ElasticIPs are tagged with the name of their Cluster, without its namespace.
+	clusterNames := sets.New[string]()
+	for cluster := range clusters {
+		clusterNames.Insert(cluster.Name)
+	}
+
+	for _, projectID := range sets.List(projectIDs) {
+		metalClient, ok := projectClients[projectID]
+		if !ok {
+			metalClient = r.PacketClient
+		} else if metalClient == nil {
+			continue
+		}
+
+		ipReservations, err := metalClient.ListElasticIPs(ctx, projectID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list IP reservations of project %s: %w", projectID, err))
+			continue
+		}
+
+		for _, ipReservation := range ipReservations {
+			clusterName, _ := packet.ElasticIPClusterName(ipReservation)
+			if clusterNames.Has(clusterName) {
+				continue
+			}
+
+			log := log.WithValues("project", projectID, "cluster", clusterName, "reservation", ipReservation.GetId(), "address", ipReservation.GetAddress())
+			if inUse.Has(ipReservation.GetId()) || inUse.Has(ipReservation.GetAddress()) {
+				log.Info("Skipping stale IP reservation that is still used by a PacketCluster")
+				continue
+			}
+			if len(ipReservation.Tags) > 1 {
+				log.Info("Skipping stale IP reservation that has tags of its own")
+				continue
+			}
+			if len(ipReservation.Assignments) > 0 {
+				log.Info("Skipping stale IP reservation that is still assigned")
+				continue
+			}
+			if r.DryRun {
+				log.Info("Would release stale IP reservation (dry run)")
+				continue
+			}
+
+			if err := metalClient.DeleteIPReservation(ctx, ipReservation.GetId()); err != nil {
+				errs = append(errs, fmt.Errorf("failed to release IP reservation %s: %w", ipReservation.GetId(), err))
+				continue
+			}
+			log.Info("Released stale IP reservation")
+		}
+	}
+
+	return kerrors.NewAggregate(errs)
+}
+
+// owningCluster returns the Cluster a PacketCluster belongs to: its owner, or the Cluster of its cluster name label
+// until it is owned, in the namespace of the PacketCluster.
+func owningCluster(packetCluster *infrav1.PacketCluster) client.ObjectKey {
+	key := client.ObjectKey{Namespace: packetCluster.Namespace, Name: packetCluster.Name}
+	for _, ref := range packetCluster.OwnerReferences {
+		if ref.Kind == "Cluster" && strings.HasPrefix(ref.APIVersion, clusterv1.GroupVersion.Group+"/") {
+			key.Name = ref.Name
+			return key
+		}
+	}
+	if name, ok := packetCluster.Labels[clusterv1.ClusterNameLabel]; ok && name != "" {
+		key.Name = name
+	}
+	return key
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func TestIPReservationGarbageCollectorCollect(t *testing.T) {
+	g := NewWithT(t)
+
+	eip := func(id, address string, tags ...string) metal.IPReservation {
+		return metal.IPReservation{Id: ptr.To(id), Type: metal.IPRESERVATIONTYPE_PUBLIC_IPV4, Address: ptr.To(address), Tags: tags}
+	}
+	api := &fakeServiceIPPoolAPI{reservations: []metal.IPReservation{
+		// The unassigned kube-vip Elastic IP of a Cluster whose PacketCluster has another name.
+		eip("owned", "147.75.0.1", "cluster-api-provider-packet:cluster-id:owner"),
+		// The Elastic IP of a Cluster whose PacketCluster is not owned yet.
+		eip("labelled", "147.75.0.2", "cluster-api-provider-packet:cluster-id:labelled"),
+		// The API server endpoint of a live cluster, tagged for a cluster that is gone.
+		eip("endpoint", "147.75.0.3", "cluster-api-provider-packet:cluster-id:gone"),
+		// An adopted VIP reservation of a cluster that is gone.
+		eip("adopted", "147.75.0.4", "cluster-api-provider-packet:cluster-id:gone", "anycast"),
+		// The Elastic IP of a cluster that is gone, still assigned.
+		{
+			Id:          ptr.To("assigned"),
+			Type:        metal.IPRESERVATIONTYPE_PUBLIC_IPV4,
+			Address:     ptr.To("147.75.0.5"),
+			Tags:        []string{"cluster-api-provider-packet:cluster-id:gone"},
+			Assignments: []metal.IPAssignment{{Id: ptr.To("assignment")}},
+		},
+		// The Elastic IP of a cluster that is gone.
+		eip("stale", "147.75.0.6", "cluster-api-provider-packet:cluster-id:gone"),
+		// Reservations without the tag are not the controller's.
+		eip("other", "147.75.0.7"),
+	}}
+	metalClient := packettest.NewClient(t, api)
+
+	packetClusters := []*infrav1.PacketCluster{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "owner-infra",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+					Name:       "owner",
+				}},
+			},
+			Spec: infrav1.PacketClusterSpec{
+				ProjectID:            "project",
+				ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "147.75.0.3", Port: 6443},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "other",
+				Name:      "labelled-infra",
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: "labelled"},
+			},
+			Spec: infrav1.PacketClusterSpec{ProjectID: "project"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scopetest.Scheme()).WithObjects(packetClusters[0], packetClusters[1]).Build()
+	gc := &IPReservationGarbageCollector{Client: c, PacketClient: metalClient, DryRun: true}
+	ctx := context.Background()
+
+	// Nothing is released in a dry run.
+	g.Expect(gc.Collect(ctx)).To(Succeed())
+	g.Expect(api.requests).To(Equal([]string{"GET /projects/project/ips"}))
+
+	// Only the stale Elastic IP is released.
+	gc.DryRun = false
+	api.requests = nil
+	g.Expect(gc.Collect(ctx)).To(Succeed())
+	g.Expect(api.requests).To(Equal([]string{"GET /projects/project/ips", "DELETE /ips/stale"}))
+}
+
+func TestOwningCluster(t *testing.T) {
+	tests := []struct {
+		name          string
+		packetCluster *infrav1.PacketCluster
+		want          string
+	}{
+		{
+			name: "owner reference",
+			packetCluster: &infrav1.PacketCluster{ObjectMeta: metav1.ObjectMeta{
+				Name:   "infra",
+				Labels: map[string]string{clusterv1.ClusterNameLabel: "label"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "v1", Kind: "Cluster", Name: "core"},
+					{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster", Name: "owner"},
+				},
+			}},
+			want: "owner",
+		},
+		{
+			name: "cluster name label",
+			packetCluster: &infrav1.PacketCluster{ObjectMeta: metav1.ObjectMeta{
+				Name:   "infra",
+				Labels: map[string]string{clusterv1.ClusterNameLabel: "label"},
+			}},
+			want: "label",
+		},
+		{
+			name:          "own name",
+			packetCluster: &infrav1.PacketCluster{ObjectMeta: metav1.ObjectMeta{Name: "infra"}},
+			want:          "infra",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			tt.packetCluster.Namespace = "default"
+			g.Expect(owningCluster(tt.packetCluster)).To(Equal(client.ObjectKey{Namespace: "default", Name: tt.want}))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// locationRetiredRequeue is how often objects whose location is no longer available try again. Moving them to
+// another location changes their spec, which is reconciled right away, so there is no point in trying often.
+const locationRetiredRequeue = 30 * time.Minute
+
+// location describes where resources are created: the facility when set, as it takes precedence, else the metro.
+func location(facility, metro string) string {
+	if facility != "" {
+		return "facility " + facility
+	}
+	return "metro " + metro
+}
+
+// machineLocation describes where the device of a machine is created, see location. The facility and metro of the
+// PacketMachine take precedence over the ones of its PacketCluster.
+func machineLocation(machineScope *scope.MachineScope) string {
+	if spec := machineScope.PacketMachine.Spec; spec.Facility != "" || spec.Metro != "" {
+		return location(spec.Facility, spec.Metro)
+	}
+	return location(machineScope.PacketCluster.Spec.Facility, machineScope.PacketCluster.Spec.Metro)
+}
+
+// markLocationRetired reports on obj, with the LocationRetired condition, that Equinix Metal no longer creates
+// resources in its location, recording a warning event the first time, and returns the result to retry with.
+func markLocationRetired(obj conditions.Setter, where string, err error) ctrl.Result {
+	if !conditions.Has(obj, infrav1.LocationRetiredCondition) {
+		record.Warnf(obj, infrav1.LocationNotAvailableReason, "The %s is no longer available: %s", where, err)
+	}
+	conditions.Set(obj, &clusterv1.Condition{
+		Type:     infrav1.LocationRetiredCondition,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterv1.ConditionSeverityError,
+		Reason:   infrav1.LocationNotAvailableReason,
+		Message: fmt.Sprintf("The %s is no longer available, set another metro and remove the facility from the spec: %s",
+			where, err),
+	})
+	return ctrl.Result{RequeueAfter: locationRetiredRequeue}
+}
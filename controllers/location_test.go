@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+func TestMachineLocation(t *testing.T) {
+	g := NewWithT(t)
+
+	machineScope := &scope.MachineScope{
+		PacketMachine: &infrav1.PacketMachine{},
+		PacketCluster: &infrav1.PacketCluster{Spec: infrav1.PacketClusterSpec{Metro: "da"}},
+	}
+	g.Expect(machineLocation(machineScope)).To(Equal("metro da"))
+
+	machineScope.PacketMachine.Spec.Facility = "ewr1"
+	g.Expect(machineLocation(machineScope)).To(Equal("facility ewr1"))
+}
+
+func TestMarkLocationRetired(t *testing.T) {
+	g := NewWithT(t)
+
+	packetCluster := &infrav1.PacketCluster{}
+	result := markLocationRetired(packetCluster, "facility ewr1", errors.New("facility ewr1 is no longer available"))
+
+	g.Expect(result.RequeueAfter).To(Equal(locationRetiredRequeue))
+	g.Expect(conditions.IsTrue(packetCluster, infrav1.LocationRetiredCondition)).To(BeTrue())
+	g.Expect(conditions.GetSeverity(packetCluster, infrav1.LocationRetiredCondition)).To(HaveValue(Equal(clusterv1.ConditionSeverityError)))
+	g.Expect(conditions.GetMessage(packetCluster, infrav1.LocationRetiredCondition)).To(ContainSubstring("set another metro"))
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+var (
+	devicesCreatedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capp_devices_created_total",
+		Help: "Number of Equinix Metal devices created, by controller and cluster.",
+	}, append([]string{"controller"}, scope.ClusterMetricLabels...))
+
+	devicesDeletedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capp_devices_deleted_total",
+		Help: "Number of Equinix Metal devices deleted, by controller and cluster.",
+	}, append([]string{"controller"}, scope.ClusterMetricLabels...))
+
+	deviceProvisioningDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "capp_device_provisioning_duration_seconds",
+		Help:    "Time from the creation of the devices of PacketMachines until they were found active, by plan and cluster.",
+		Buckets: []float64{60, 180, 300, 450, 600, 900, 1200, 1800, 2700, 3600},
+	}, append([]string{"plan"}, scope.ClusterMetricLabels...))
+)
+
+func init() {
+	metrics.Registry.MustRegister(devicesCreatedTotal, devicesDeletedTotal, deviceProvisioningDuration)
+}
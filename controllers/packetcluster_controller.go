@@ -21,13 +21,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,21 +44,48 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
 	"sigs.k8s.io/cluster-api-provider-packet/internal/emlb"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/sharding"
 	packet "sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
 	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
 )
 
+const (
+	// kubeVIPConfigMapName is the ConfigMap read by the kube-vip cloud provider in the workload cluster.
+	kubeVIPConfigMapName = "kubevip"
+	// kubeVIPCIDRGlobalKey is the kube-vip cloud provider key for a CIDR usable by Services in any namespace.
+	kubeVIPCIDRGlobalKey = "cidr-global"
+	// serviceIPPoolRequeueAfter is how long to wait before retrying to publish the Service IP pool to the
+	// workload cluster while its control plane is not yet available.
+	serviceIPPoolRequeueAfter = 30 * time.Second
+)
+
 // PacketClusterReconciler reconciles a PacketCluster object.
 type PacketClusterReconciler struct {
 	client.Client
 	WatchFilterValue string
 	PacketClient     *packet.Client
+
+	// Shard, when set, restricts the reconciled objects to the clusters of a shard.
+	Shard *sharding.Shard
+
+	// DeletionTimeout, when set, is how long the Equinix Metal resources of a deleted cluster may fail to be deleted
+	// before manual intervention is requested.
+	DeletionTimeout time.Duration
+
+	// APICallWarningThreshold, when set, is the number of Equinix Metal API calls above which a reconciliation is
+	// reported with a warning event listing the calls by endpoint.
+	APICallWarningThreshold int
+
+	// Audit, when set, records the changes made to the infrastructure of the clusters.
+	Audit *audit.EventAggregator
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=packetclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=packetclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update;patch
 
 func (r *PacketClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	log := ctrl.LoggerFrom(ctx)
@@ -102,6 +137,22 @@ func (r *PacketClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}()
 
+	// Account the Equinix Metal API calls made for this cluster against its budget, and label their metrics with it.
+	budgetKey := util.ObjectKey(cluster).String()
+	ctx = packet.WithClusterBudget(ctx, budgetKey)
+	ctx = packet.WithMetricLabels(ctx, clusterScope.MetricLabels())
+	defer reconcileThrottledCondition(r.PacketClient, packetcluster, budgetKey)
+	ctx, apiCalls := packet.WithAPICallAccounting(ctx)
+	defer reportAPICalls(packetcluster, apiCalls, r.APICallWarningThreshold)
+
+	// Manage the resources of the cluster with its own credentials, if it has any.
+	metalClient, err := r.PacketClient.ClientForCluster(ctx, r.Client, packetcluster)
+	if err != nil {
+		record.Warnf(packetcluster, "CredentialsUnavailable", "%s", err)
+		return ctrl.Result{}, err
+	}
+	ctx = packet.WithClient(ctx, metalClient)
+
 	// Handle deleted clusters
 	if !cluster.DeletionTimestamp.IsZero() {
 		return ctrl.Result{}, r.reconcileDelete(ctx, clusterScope)
@@ -114,37 +165,81 @@ func (r *PacketClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, nil
 	}
 
-	err = r.reconcileNormal(ctx, clusterScope)
-	return ctrl.Result{}, err
+	return r.reconcileNormal(ctx, clusterScope)
 }
 
-func (r *PacketClusterReconciler) reconcileNormal(ctx context.Context, clusterScope *scope.ClusterScope) error {
+func (r *PacketClusterReconciler) reconcileNormal(ctx context.Context, clusterScope *scope.ClusterScope) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx).WithValues("cluster", clusterScope.Cluster.Name)
 	log.Info("Reconciling PacketCluster")
 
 	packetCluster := clusterScope.PacketCluster
 
+	if err := r.reconcileCredentials(ctx, packetCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileProject(ctx, clusterScope); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !conditions.IsTrue(packetCluster, infrav1.ProjectReadyCondition) {
+		if err := r.validateProject(ctx, packetCluster); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	switch {
 	case packetCluster.Spec.VIPManager == infrav1.EMLBVIPID:
-		if !packetCluster.Spec.ControlPlaneEndpoint.IsValid() {
-			// Create new EMLB object
-			lb := emlb.NewEMLB(r.PacketClient.GetConfig().DefaultHeader["X-Auth-Token"], packetCluster.Spec.ProjectID, packetCluster.Spec.Metro)
+		emlb.MigrateClusterAnnotations(packetCluster)
 
+		// Create new EMLB object
+		lb := emlb.NewEMLB(r.metalClient(ctx).GetConfig().DefaultHeader["X-Auth-Token"], packetCluster.Spec.ProjectID, packetCluster.Spec.Metro)
+
+		if !packetCluster.Spec.ControlPlaneEndpoint.IsValid() {
 			if err := lb.ReconcileLoadBalancer(ctx, clusterScope); err != nil {
 				log.Error(err, "Error Reconciling EMLB")
-				return err
+				return ctrl.Result{}, err
+			}
+		}
+
+		if len(packetCluster.Spec.LoadBalancerPools) > 0 {
+			if err := lb.ReconcileLoadBalancerPools(ctx, clusterScope); err != nil {
+				log.Error(err, "Error Reconciling EMLB pools")
+				return ctrl.Result{}, err
 			}
 		}
 	case packetCluster.Spec.VIPManager == infrav1.KUBEVIPID:
 		log.Info("KUBE_VIP VIPManager Detected")
-		if err := r.PacketClient.EnableProjectBGP(ctx, packetCluster.Spec.ProjectID); err != nil {
+		if err := r.metalClient(ctx).EnableProjectBGP(ctx, packetCluster.Spec.ProjectID); err != nil {
 			log.Error(err, "error enabling bgp for project")
-			return err
+			return ctrl.Result{}, err
+		}
+	case packetCluster.Spec.VIPManager == infrav1.NONEVIPID:
+		// The endpoint is managed outside of the provider, so there is nothing to create, but
+		// infrastructure cannot be ready until the user has told us where the API server lives.
+		if !packetCluster.Spec.ControlPlaneEndpoint.IsValid() {
+			log.Info("Waiting for a user-managed ControlPlaneEndpoint to be set")
+			conditions.MarkFalse(packetCluster, infrav1.NetworkInfrastructureReadyCondition, infrav1.ControlPlaneEndpointNotSetReason, clusterv1.ConditionSeverityWarning,
+				"controlPlaneEndpoint must be set when vipManager is %s", infrav1.NONEVIPID)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// The BGP sessions the Nodes are annotated with need BGP on the project, which kube-vip enables above.
+	if packetCluster.Spec.BGPPeerAnnotations && packetCluster.Spec.VIPManager != infrav1.KUBEVIPID {
+		if err := r.metalClient(ctx).EnableProjectBGP(ctx, packetCluster.Spec.ProjectID); err != nil {
+			log.Error(err, "error enabling bgp for project")
+			return ctrl.Result{}, err
 		}
 	}
 
-	if packetCluster.Spec.VIPManager != infrav1.EMLBVIPID {
-		ipReserv, err := r.PacketClient.GetIPByClusterIdentifier(ctx, clusterScope.Namespace(), clusterScope.Name(), packetCluster.Spec.ProjectID)
+	if packetCluster.Spec.VIPReservationID != "" {
+		if err := r.reconcileVIPReservation(ctx, clusterScope); err != nil {
+			log.Error(err, "error using the VIP reservation")
+			return ctrl.Result{}, err
+		}
+	} else if packetCluster.Spec.VIPManager != infrav1.EMLBVIPID && packetCluster.Spec.VIPManager != infrav1.NONEVIPID {
+		ipReserv, err := r.metalClient(ctx).GetIPByClusterIdentifier(ctx, clusterScope.Namespace(), clusterScope.Name(), packetCluster.Spec.ProjectID)
 		switch {
 		case errors.Is(err, packet.ErrControlPlanEndpointNotFound):
 			// Parse metro and facility from the cluster spec
@@ -159,31 +254,140 @@ func (r *PacketClusterReconciler) reconcileNormal(ctx context.Context, clusterSc
 			}
 
 			// There is not an ElasticIP with the right tags, at this point we can create one
-			ip, err := r.PacketClient.CreateIP(ctx, clusterScope.Namespace(), clusterScope.Name(), packetCluster.Spec.ProjectID, facility, metro)
+			ipReserv, err := r.metalClient(ctx).CreateIP(ctx, clusterScope.Namespace(), clusterScope.Name(), packetCluster.Spec.ProjectID, facility, metro)
+			if packet.IsLocationUnavailable(err) {
+				// Retrying does not help until the cluster is moved to another location
+				where := location(facility, metro)
+				conditions.MarkFalse(packetCluster, infrav1.NetworkInfrastructureReadyCondition, infrav1.LocationNotAvailableReason, clusterv1.ConditionSeverityError,
+					"The %s is no longer available", where)
+				return markLocationRetired(packetCluster, where, err), nil
+			}
 			if err != nil {
 				log.Error(err, "error reserving an ip")
-				return err
+				return ctrl.Result{}, err
 			}
+			r.Audit.Record(ctx, util.ObjectKey(clusterScope.Cluster), audit.ElasticIPReserved, "PacketCluster/"+packetCluster.Name, ipReserv.GetAddress(),
+				"Reserved the control plane elastic IP in project %s", packetCluster.Spec.ProjectID)
 			packetCluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{
-				Host: ip.To4().String(),
+				Host: ipReserv.GetAddress(),
 				Port: 6443,
 			}
+			packetCluster.Status.ElasticIP = &infrav1.ElasticIPStatus{ReservationID: ipReserv.GetId(), Address: ipReserv.GetAddress()}
+			conditions.Delete(packetCluster, infrav1.LocationRetiredCondition)
 		case err != nil:
 			log.Error(err, "error getting cluster IP")
-			return err
+			return ctrl.Result{}, err
 		default:
 			// If there is an ElasticIP with the right tag just use it again
 			packetCluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{
 				Host: ipReserv.GetAddress(),
 				Port: 6443,
 			}
+			packetCluster.Status.ElasticIP = &infrav1.ElasticIPStatus{ReservationID: ipReserv.GetId(), Address: ipReserv.GetAddress()}
 		}
 	}
 
+	// Metal Gateways are reconciled even when VLANs fail, as removed gateways must be deleted before their VLANs.
+	vlanErr := r.reconcileVLANs(ctx, clusterScope)
+	gatewayErr := r.reconcileMetalGateways(ctx, clusterScope)
+	if err := kerrors.NewAggregate([]error{vlanErr, gatewayErr}); err != nil {
+		log.Error(err, "error reconciling VLANs")
+		return ctrl.Result{}, err
+	}
+
 	packetCluster.Status.Ready = true
 	conditions.MarkTrue(packetCluster, infrav1.NetworkInfrastructureReadyCondition)
 
-	return nil
+	return r.reconcileServiceIPPool(ctx, clusterScope)
+}
+
+// validateProject checks the project of the cluster can be managed before any resource is created in it, reporting
+// the outcome with the ProjectReady condition. Once successful, the project is not checked again.
+func (r *PacketClusterReconciler) validateProject(ctx context.Context, packetCluster *infrav1.PacketCluster) error {
+	err := r.metalClient(ctx).ValidateProject(ctx, packetCluster.Spec.ProjectID)
+
+	reason := infrav1.ProjectValidationFailedReason
+	switch {
+	case err == nil:
+		conditions.MarkTrue(packetCluster, infrav1.ProjectReadyCondition)
+		return nil
+	case errors.Is(err, packet.ErrProjectNotFound):
+		reason = infrav1.ProjectNotFoundReason
+	case errors.Is(err, packet.ErrProjectAccessDenied), errors.Is(err, packet.ErrReadOnlyAPIKey):
+		reason = infrav1.ProjectAccessDeniedReason
+	}
+
+	if !conditions.IsFalse(packetCluster, infrav1.ProjectReadyCondition) || conditions.GetReason(packetCluster, infrav1.ProjectReadyCondition) != reason {
+		record.Warnf(packetCluster, reason, "%s", err.Error())
+	}
+	conditions.MarkFalse(packetCluster, infrav1.ProjectReadyCondition, reason, clusterv1.ConditionSeverityError, "%s", err.Error())
+	return fmt.Errorf("invalid project: %w", err)
+}
+
+// reconcileServiceIPPool reserves the public IPv4 block requested in spec.serviceIPPool and publishes it to the
+// kube-vip cloud provider ConfigMap in the workload cluster once its control plane is up.
+func (r *PacketClusterReconciler) reconcileServiceIPPool(ctx context.Context, clusterScope *scope.ClusterScope) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("cluster", clusterScope.Cluster.Name)
+
+	packetCluster := clusterScope.PacketCluster
+	if packetCluster.Spec.ServiceIPPool == nil {
+		return ctrl.Result{}, nil
+	}
+
+	if packetCluster.Status.ServiceIPPool == nil {
+		reservation, err := r.metalClient(ctx).GetServiceIPPool(ctx, clusterScope.Namespace(), clusterScope.Name(), packetCluster.Spec.ProjectID)
+		if errors.Is(err, packet.ErrServiceIPPoolNotFound) {
+			facility := packetCluster.Spec.Facility
+			metro := packetCluster.Spec.Metro
+
+			// If both specified, metro takes precedence over facility
+			if metro != "" {
+				facility = ""
+			}
+
+			reservation, err = r.metalClient(ctx).CreateServiceIPPool(ctx, clusterScope.Namespace(), clusterScope.Name(), packetCluster.Spec.ProjectID, facility, metro, packetCluster.Spec.ServiceIPPool.Size)
+		}
+		if err != nil {
+			log.Error(err, "error reserving the service IP pool")
+			conditions.MarkFalse(packetCluster, infrav1.ServiceIPPoolReadyCondition, infrav1.ServiceIPPoolReservationFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return ctrl.Result{}, err
+		}
+		packetCluster.Status.ServiceIPPool = &infrav1.ServiceIPPoolStatus{
+			ReservationID: reservation.GetId(),
+			CIDR:          fmt.Sprintf("%s/%d", reservation.GetNetwork(), reservation.GetCidr()),
+		}
+	}
+
+	if !conditions.IsTrue(clusterScope.Cluster, clusterv1.ControlPlaneInitializedCondition) {
+		conditions.MarkFalse(packetCluster, infrav1.ServiceIPPoolReadyCondition, infrav1.WaitingForControlPlaneReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{RequeueAfter: serviceIPPoolRequeueAfter}, nil
+	}
+
+	workloadClient, err := remote.NewClusterClient(ctx, "packetcluster-controller", r.Client, util.ObjectKey(clusterScope.Cluster))
+	if err != nil {
+		conditions.MarkFalse(packetCluster, infrav1.ServiceIPPoolReadyCondition, infrav1.ServiceIPPoolConfigMapFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, fmt.Errorf("failed to create workload cluster client: %w", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeVIPConfigMapName,
+			Namespace: metav1.NamespaceSystem,
+		},
+	}
+	if _, err := controllerutil.CreateOrPatch(ctx, workloadClient, configMap, func() error {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[kubeVIPCIDRGlobalKey] = packetCluster.Status.ServiceIPPool.CIDR
+		return nil
+	}); err != nil {
+		conditions.MarkFalse(packetCluster, infrav1.ServiceIPPoolReadyCondition, infrav1.ServiceIPPoolConfigMapFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, fmt.Errorf("failed to publish service IP pool to the workload cluster: %w", err)
+	}
+
+	conditions.MarkTrue(packetCluster, infrav1.ServiceIPPoolReadyCondition)
+	return ctrl.Result{}, nil
 }
 
 func (r *PacketClusterReconciler) reconcileDelete(ctx context.Context, clusterScope *scope.ClusterScope) error {
@@ -192,31 +396,156 @@ func (r *PacketClusterReconciler) reconcileDelete(ctx context.Context, clusterSc
 
 	packetCluster := clusterScope.PacketCluster
 
-	if packetCluster.Spec.VIPManager == infrav1.EMLBVIPID {
-		// Create new EMLB object
-		lb := emlb.NewEMLB(r.PacketClient.GetConfig().DefaultHeader["X-Auth-Token"], packetCluster.Spec.ProjectID, packetCluster.Spec.Metro)
+	// Every resource is attempted, so that all the ones left are reported at once.
+	var remaining []string
+	var errs []error
+	deleteResource := func(resource string, deleteFunc func() error) {
+		if err := deleteFunc(); err != nil {
+			remaining = append(remaining, fmt.Sprintf("%s: %s", resource, err))
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", resource, err))
+		}
+	}
+
+	// A cluster still waiting for its managed project has no resources, other than maybe the project.
+	if packetCluster.Spec.Project == nil || packetCluster.Spec.ProjectID != "" {
+		if packetCluster.Spec.VIPManager == infrav1.EMLBVIPID {
+			emlb.MigrateClusterAnnotations(packetCluster)
+
+			// Create new EMLB object
+			lb := emlb.NewEMLB(r.metalClient(ctx).GetConfig().DefaultHeader["X-Auth-Token"], packetCluster.Spec.ProjectID, packetCluster.Spec.Metro)
 
-		if err := lb.DeleteClusterLoadBalancer(ctx, clusterScope); err != nil {
-			return fmt.Errorf("failed to delete load balancer: %w", err)
+			deleteResource("load balancer pools", func() error { return lb.DeleteLoadBalancerPools(ctx, clusterScope) })
+			deleteResource("load balancer", func() error { return lb.DeleteClusterLoadBalancer(ctx, clusterScope) })
 		}
+
+		// Unlike the control plane Elastic IP, the Service IP pool is owned by the cluster, so release it.
+		deleteResource("service IP pool", func() error { return r.deleteServiceIPPool(ctx, clusterScope) })
+		// The control plane Elastic IP is kept for a cluster with the same name, unless it is to be released.
+		deleteResource("elastic IP", func() error { return r.releaseElasticIP(ctx, clusterScope) })
+		deleteResource("metal gateways", func() error { return r.deleteMetalGateways(ctx, clusterScope) })
+		deleteResource("VLANs", func() error { return r.deleteVLANs(ctx, clusterScope) })
+	}
+
+	// The managed project can only be deleted once it is empty.
+	if len(errs) == 0 {
+		deleteResource("project", func() error { return r.deleteProject(ctx, clusterScope) })
+	}
+
+	if len(errs) > 0 {
+		r.reportRemainingResources(ctx, clusterScope, remaining)
+		return kerrors.NewAggregate(errs)
+	}
+	conditions.MarkTrue(packetCluster, infrav1.ExternalResourcesDeletedCondition)
+
+	// The resources of the cluster are gone, so its credentials are no longer needed.
+	if err := r.releaseCredentials(ctx, packetCluster); err != nil {
+		return err
 	}
-	// Initially I created this handler to remove an elastic IP when a cluster
-	// gets delete, but it does not sound like a good idea.  It is better to
-	// leave to the users the ability to decide if they want to keep and resign
-	// the IP or if they do not need it anymore
 
 	// Cluster is deleted so remove the finalizer.
+	r.metalClient(ctx).ForgetClusterBudget(util.ObjectKey(clusterScope.Cluster).String())
+	packet.ForgetClusterMetrics(clusterScope.Cluster.Namespace, clusterScope.Cluster.Name)
 	controllerutil.RemoveFinalizer(packetCluster, infrav1.ClusterFinalizer)
 	return nil
 }
 
+// reportRemainingResources lists the Equinix Metal resources of a deleted cluster that failed to be deleted, and
+// requests manual intervention once they keep failing for longer than DeletionTimeout.
+func (r *PacketClusterReconciler) reportRemainingResources(ctx context.Context, clusterScope *scope.ClusterScope, remaining []string) {
+	packetCluster := clusterScope.PacketCluster
+	message := strings.Join(remaining, "; ")
+
+	deletingFor := time.Since(clusterScope.Cluster.DeletionTimestamp.Time)
+	if r.DeletionTimeout <= 0 || deletingFor < r.DeletionTimeout {
+		conditions.MarkFalse(packetCluster, infrav1.ExternalResourcesDeletedCondition, infrav1.ExternalResourcesRemainingReason,
+			clusterv1.ConditionSeverityWarning, "%s", message)
+		return
+	}
+
+	if conditions.GetReason(packetCluster, infrav1.ExternalResourcesDeletedCondition) != infrav1.DeletionTimedOutReason {
+		ctrl.LoggerFrom(ctx).Info("Cluster deletion timed out, manual intervention required", "timeout", r.DeletionTimeout, "remaining", message)
+		record.Warnf(packetCluster, "ManualInterventionRequired",
+			"Equinix Metal resources of the cluster still fail to be deleted %s after the deletion started, manual intervention required: %s",
+			deletingFor.Round(time.Second), message)
+	}
+	conditions.MarkFalse(packetCluster, infrav1.ExternalResourcesDeletedCondition, infrav1.DeletionTimedOutReason,
+		clusterv1.ConditionSeverityError, "%s", message)
+}
+
+func (r *PacketClusterReconciler) deleteServiceIPPool(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	packetCluster := clusterScope.PacketCluster
+	if packetCluster.Spec.ServiceIPPool == nil && packetCluster.Status.ServiceIPPool == nil {
+		return nil
+	}
+
+	var reservationID string
+	if packetCluster.Status.ServiceIPPool != nil {
+		reservationID = packetCluster.Status.ServiceIPPool.ReservationID
+	} else {
+		// The reservation may have been created without the status being persisted, look it up by tag.
+		reservation, err := r.metalClient(ctx).GetServiceIPPool(ctx, clusterScope.Namespace(), clusterScope.Name(), packetCluster.Spec.ProjectID)
+		if errors.Is(err, packet.ErrServiceIPPoolNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		reservationID = reservation.GetId()
+	}
+
+	if err := r.metalClient(ctx).DeleteServiceIPPool(ctx, reservationID); err != nil {
+		return err
+	}
+	packetCluster.Status.ServiceIPPool = nil
+	return nil
+}
+
+// releaseElasticIP releases the control plane Elastic IP of a deleted cluster with the Release reclaim policy.
+func (r *PacketClusterReconciler) releaseElasticIP(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	packetCluster := clusterScope.PacketCluster
+	// The reservation of the user is never released, whatever the reclaim policy.
+	if packetCluster.Spec.VIPReservationID != "" {
+		return r.releaseVIPReservation(ctx, clusterScope)
+	}
+	if packetCluster.ElasticIPReclaimPolicy() != infrav1.ElasticIPReclaimRelease {
+		return nil
+	}
+	if packetCluster.Spec.VIPManager != infrav1.CPEMID && packetCluster.Spec.VIPManager != infrav1.KUBEVIPID {
+		return nil
+	}
+
+	eip := packetCluster.Status.ElasticIP
+	if eip == nil {
+		// The reservation may have been created without the status being persisted, look it up by tag.
+		ipReserv, err := r.metalClient(ctx).GetIPByClusterIdentifier(ctx, clusterScope.Namespace(), clusterScope.Name(), packetCluster.Spec.ProjectID)
+		if errors.Is(err, packet.ErrControlPlanEndpointNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		eip = &infrav1.ElasticIPStatus{ReservationID: ipReserv.GetId(), Address: ipReserv.GetAddress()}
+	}
+
+	if err := r.metalClient(ctx).DeleteIPReservation(ctx, eip.ReservationID); err != nil {
+		record.Warnf(packetCluster, "ElasticIPReleaseFailed", "Failed to release elastic IP %s: %s", eip.Address, err)
+		return err
+	}
+	record.Eventf(packetCluster, "ElasticIPReleased", "Released elastic IP %s", eip.Address)
+	r.Audit.Record(ctx, util.ObjectKey(clusterScope.Cluster), audit.ElasticIPReleased, "PacketCluster/"+packetCluster.Name, eip.Address,
+		"Released the control plane elastic IP of the deleted cluster")
+	packetCluster.Status.ElasticIP = nil
+	return nil
+}
+
 func (r *PacketClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
 	log := ctrl.LoggerFrom(ctx)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(options).
-		For(&infrav1.PacketCluster{}).
+		For(&infrav1.PacketCluster{}, builder.WithPredicates(ignoreStatusUpdates(log, "packetcluster"))).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(log, r.WatchFilterValue)).
+		WithEventFilter(r.Shard.Predicate(log)).
 		WithEventFilter(predicates.ResourceIsNotExternallyManaged(log)).
 		Watches(
 			&clusterv1.Cluster{},
@@ -226,6 +555,31 @@ func (r *PacketClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 		Complete(r)
 }
 
+// reconcileThrottledCondition reports on obj whether the cluster is over its Equinix Metal API call budget.
+func reconcileThrottledCondition(packetClient *packet.Client, obj conditions.Setter, cluster string) {
+	if !packetClient.ClusterThrottled(cluster) {
+		conditions.Delete(obj, infrav1.ThrottledByProviderCondition)
+		return
+	}
+
+	conditions.Set(obj, &clusterv1.Condition{
+		Type:     infrav1.ThrottledByProviderCondition,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterv1.ConditionSeverityWarning,
+		Reason:   infrav1.APIBudgetExceededReason,
+		Message:  fmt.Sprintf("Equinix Metal API calls for cluster %s are being throttled", cluster),
+	})
+}
+
+// reportAPICalls records a warning event on obj when a reconciliation made more than threshold Equinix Metal API
+// calls, with the number of calls by endpoint.
+func reportAPICalls(obj runtime.Object, calls *packet.APICalls, threshold int) {
+	if threshold <= 0 || calls.Total() <= threshold {
+		return
+	}
+	record.Warnf(obj, "ExpensiveReconcile", "Reconciliation made %d Equinix Metal API calls: %s", calls.Total(), calls.Breakdown())
+}
+
 // MachineNotFound error representing that the requested device was not yet found.
 type MachineNotFound struct {
 	err string
@@ -243,3 +597,8 @@ type MachineNoIP struct {
 func (e *MachineNoIP) Error() string {
 	return e.err
 }
+
+// metalClient returns the Equinix Metal client of the cluster being reconciled, see packet.ClientForCluster.
+func (r *PacketClusterReconciler) metalClient(ctx context.Context) *packet.Client {
+	return packet.ClientFromContext(ctx, r.PacketClient)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+func TestReportRemainingResources(t *testing.T) {
+	g := NewWithT(t)
+
+	deletionTimestamp := metav1.NewTime(time.Now().Add(-time.Hour))
+	clusterScope := &scope.ClusterScope{
+		Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deletionTimestamp}},
+		PacketCluster: &infrav1.PacketCluster{},
+	}
+	remaining := []string{"load balancer: 500 Internal Server Error", "service IP pool: 422 Unprocessable Entity"}
+
+	r := &PacketClusterReconciler{DeletionTimeout: 2 * time.Hour}
+	r.reportRemainingResources(context.Background(), clusterScope, remaining)
+
+	condition := conditions.Get(clusterScope.PacketCluster, infrav1.ExternalResourcesDeletedCondition)
+	g.Expect(condition).ToNot(BeNil())
+	g.Expect(condition.Reason).To(Equal(infrav1.ExternalResourcesRemainingReason))
+	g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+	g.Expect(condition.Message).To(Equal("load balancer: 500 Internal Server Error; service IP pool: 422 Unprocessable Entity"))
+
+	r.DeletionTimeout = 30 * time.Minute
+	r.reportRemainingResources(context.Background(), clusterScope, remaining)
+
+	condition = conditions.Get(clusterScope.PacketCluster, infrav1.ExternalResourcesDeletedCondition)
+	g.Expect(condition.Reason).To(Equal(infrav1.DeletionTimedOutReason))
+	g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityError))
+}
+
+func TestReleaseElasticIP(t *testing.T) {
+	g := NewWithT(t)
+
+	var requests []string
+	metalClient := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/project/ips":
+			_, _ = w.Write([]byte(`{"ip_addresses": [{"id": "eip", "address": "147.75.0.1", "type": "public_ipv4",
+				"tags": ["cluster-api-provider-packet:cluster-id:cluster"]}]}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/ips/eip":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	r := &PacketClusterReconciler{PacketClient: metalClient}
+
+	packetCluster := &infrav1.PacketCluster{
+		Spec: infrav1.PacketClusterSpec{ProjectID: "project", VIPManager: infrav1.CPEMID},
+		Status: infrav1.PacketClusterStatus{
+			ElasticIP: &infrav1.ElasticIPStatus{ReservationID: "eip", Address: "147.75.0.1"},
+		},
+	}
+	clusterScope := &scope.ClusterScope{
+		Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		PacketCluster: packetCluster,
+	}
+	ctx := context.Background()
+
+	// The Elastic IP is kept by default.
+	g.Expect(r.releaseElasticIP(ctx, clusterScope)).To(Succeed())
+	g.Expect(requests).To(BeEmpty())
+	g.Expect(packetCluster.Status.ElasticIP).ToNot(BeNil())
+
+	// It is released with the Release reclaim policy.
+	packetCluster.Spec.ElasticIPReclaimPolicy = infrav1.ElasticIPReclaimRelease
+	g.Expect(r.releaseElasticIP(ctx, clusterScope)).To(Succeed())
+	g.Expect(requests).To(Equal([]string{"DELETE /ips/eip"}))
+	g.Expect(packetCluster.Status.ElasticIP).To(BeNil())
+
+	// An Elastic IP missing from the status is found by its tag.
+	requests = nil
+	g.Expect(r.releaseElasticIP(ctx, clusterScope)).To(Succeed())
+	g.Expect(requests).To(Equal([]string{"GET /projects/project/ips", "DELETE /ips/eip"}))
+}
+
+// fakeServiceIPPoolAPI serves the IP reservations of a project, created and deleted through the API.
+type fakeServiceIPPoolAPI struct {
+	reservations []metal.IPReservation
+	requests     []string
+}
+
+func (f *fakeServiceIPPoolAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/projects/project/ips":
+		var list metal.IPReservationList
+		for i := range f.reservations {
+			list.IpAddresses = append(list.IpAddresses, metal.IPReservationListIpAddressesInner{IPReservation: &f.reservations[i]})
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case r.Method == http.MethodPost && r.URL.Path == "/projects/project/ips":
+		var input metal.IPReservationRequestInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		reservation := metal.IPReservation{
+			Id:      ptr.To(fmt.Sprintf("pool-%d", len(f.reservations))),
+			Type:    metal.IPRESERVATIONTYPE_PUBLIC_IPV4,
+			Network: ptr.To("147.75.1.0"),
+			Cidr:    ptr.To[int32](29),
+			Tags:    input.Tags,
+		}
+		f.reservations = append(f.reservations, reservation)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(reservation)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/ips/"):
+		id := strings.TrimPrefix(r.URL.Path, "/ips/")
+		f.reservations = slices.DeleteFunc(f.reservations, func(reservation metal.IPReservation) bool { return reservation.GetId() == id })
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestReconcileServiceIPPool(t *testing.T) {
+	g := NewWithT(t)
+
+	// The pool of a cluster of the same name in another namespace of the project.
+	api := &fakeServiceIPPoolAPI{reservations: []metal.IPReservation{{
+		Id:      ptr.To("other-pool"),
+		Type:    metal.IPRESERVATIONTYPE_PUBLIC_IPV4,
+		Network: ptr.To("147.75.2.0"),
+		Cidr:    ptr.To[int32](29),
+		Tags:    []string{"cluster-api-provider-packet:service-pool:other/cluster"},
+	}}}
+	metalClient := packettest.NewClient(t, api)
+	r := &PacketClusterReconciler{PacketClient: metalClient}
+
+	packetCluster := &infrav1.PacketCluster{
+		Spec: infrav1.PacketClusterSpec{
+			ProjectID:     "project",
+			Metro:         "da",
+			ServiceIPPool: &infrav1.ServiceIPPool{Size: 8},
+		},
+	}
+	clusterScope := &scope.ClusterScope{
+		Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster"}},
+		PacketCluster: packetCluster,
+	}
+	ctx := context.Background()
+
+	// The pool is reserved and recorded in the status, and published once the control plane is up.
+	result, err := r.reconcileServiceIPPool(ctx, clusterScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(serviceIPPoolRequeueAfter))
+	g.Expect(api.reservations).To(HaveLen(2))
+	g.Expect(api.reservations[1].Tags).To(ConsistOf("cluster-api-provider-packet:service-pool:default/cluster"))
+	g.Expect(packetCluster.Status.ServiceIPPool).To(Equal(&infrav1.ServiceIPPoolStatus{ReservationID: "pool-1", CIDR: "147.75.1.0/29"}))
+	g.Expect(conditions.GetReason(packetCluster, infrav1.ServiceIPPoolReadyCondition)).To(Equal(infrav1.WaitingForControlPlaneReason))
+
+	// A pool reserved without its status being persisted is found again by its tag, never the one of the other
+	// namespace.
+	packetCluster.Status.ServiceIPPool = nil
+	_, err = r.reconcileServiceIPPool(ctx, clusterScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(api.reservations).To(HaveLen(2))
+	g.Expect(packetCluster.Status.ServiceIPPool.ReservationID).To(Equal("pool-1"))
+
+	// The pool is released with the cluster, even when its status was not persisted.
+	packetCluster.Status.ServiceIPPool = nil
+	api.requests = nil
+	g.Expect(r.deleteServiceIPPool(ctx, clusterScope)).To(Succeed())
+	g.Expect(api.requests).To(Equal([]string{"GET /projects/project/ips", "DELETE /ips/pool-1"}))
+	g.Expect(api.reservations).To(HaveLen(1))
+	g.Expect(api.reservations[0].GetId()).To(Equal("other-pool"))
+
+	// Nothing is left to release afterwards.
+	api.requests = nil
+	g.Expect(r.deleteServiceIPPool(ctx, clusterScope)).To(Succeed())
+	g.Expect(api.requests).To(Equal([]string{"GET /projects/project/ips"}))
+}
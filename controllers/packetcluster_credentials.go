@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// credentialsFinalizer returns the finalizer a PacketCluster keeps on its credentials Secret, so that the Secret
+// outlives the cluster, e.g. when their namespace is deleted, and the resources of the cluster can still be deleted
+// with it. Every cluster has its own finalizer, as clusters may share a Secret.
+func credentialsFinalizer(packetCluster *infrav1.PacketCluster) string {
+	name := packetCluster.Name
+	// The name of a finalizer is at most 63 characters long.
+	if len(name) > 63 {
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(name))
+		name = fmt.Sprintf("%s-%08x", name[:54], hash.Sum32())
+	}
+	return infrav1.ClusterFinalizer + "/" + name
+}
+
+// reconcileCredentials keeps the credentials Secret of the cluster from being deleted while the cluster exists, and
+// lets go of the Secrets the cluster no longer references.
+func (r *PacketClusterReconciler) reconcileCredentials(ctx context.Context, packetCluster *infrav1.PacketCluster) error {
+	finalizer := credentialsFinalizer(packetCluster)
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.InNamespace(packetCluster.Namespace)); err != nil {
+		return fmt.Errorf("failed to list the secrets of namespace %s: %w", packetCluster.Namespace, err)
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		referenced := packetCluster.Spec.CredentialsRef != nil && secret.Name == packetCluster.Spec.CredentialsRef.Name
+
+		patch := client.MergeFrom(secret.DeepCopy())
+		switch {
+		case referenced && secret.DeletionTimestamp.IsZero():
+			if !controllerutil.AddFinalizer(secret, finalizer) {
+				continue
+			}
+		case !referenced:
+			if !controllerutil.RemoveFinalizer(secret, finalizer) {
+				continue
+			}
+		default:
+			// Finalizers cannot be added to Secrets being deleted, the cluster has to do without.
+			continue
+		}
+		if err := r.Patch(ctx, secret, patch); err != nil {
+			return fmt.Errorf("failed to update the finalizers of credentials secret %s: %w", secret.Name, err)
+		}
+	}
+	return nil
+}
+
+// releaseCredentials lets the credentials Secret of a deleted cluster be deleted, once the resources of the cluster
+// are deleted.
+func (r *PacketClusterReconciler) releaseCredentials(ctx context.Context, packetCluster *infrav1.PacketCluster) error {
+	ref := packetCluster.Spec.CredentialsRef
+	if ref == nil {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: packetCluster.Namespace, Name: ref.Name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to retrieve credentials secret %s: %w", ref.Name, err)
+	}
+
+	finalizer := credentialsFinalizer(packetCluster)
+	if !controllerutil.ContainsFinalizer(secret, finalizer) {
+		return nil
+	}
+	patch := client.MergeFrom(secret.DeepCopy())
+	controllerutil.RemoveFinalizer(secret, finalizer)
+	if err := r.Patch(ctx, secret, patch); err != nil {
+		return fmt.Errorf("failed to remove the finalizer of credentials secret %s: %w", ref.Name, err)
+	}
+	return nil
+}
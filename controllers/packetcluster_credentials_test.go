@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func TestReconcileCredentials(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	secret := func(name string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: scopetest.Namespace},
+			Data:       map[string][]byte{"apiKey": []byte(name)},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scopetest.Scheme()).WithObjects(secret("team-a"), secret("team-b")).Build()
+	r := &PacketClusterReconciler{Client: c}
+
+	packetCluster := &infrav1.PacketCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: scopetest.Namespace},
+		Spec:       infrav1.PacketClusterSpec{CredentialsRef: &infrav1.SecretKeyReference{Name: "team-a", Key: "apiKey"}},
+	}
+	finalizers := func(name string) []string {
+		current := &corev1.Secret{}
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: scopetest.Namespace, Name: name}, current)).To(Succeed())
+		return current.Finalizers
+	}
+
+	// The Secret the cluster references is kept until the cluster is deleted.
+	g.Expect(r.reconcileCredentials(ctx, packetCluster)).To(Succeed())
+	g.Expect(finalizers("team-a")).To(ConsistOf(infrav1.ClusterFinalizer + "/cluster"))
+	g.Expect(finalizers("team-b")).To(BeEmpty())
+
+	// Another cluster sharing the Secret has its own finalizer.
+	other := packetCluster.DeepCopy()
+	other.Name = "other"
+	g.Expect(r.reconcileCredentials(ctx, other)).To(Succeed())
+	g.Expect(finalizers("team-a")).To(ConsistOf(infrav1.ClusterFinalizer+"/cluster", infrav1.ClusterFinalizer+"/other"))
+
+	// Moving the cluster to other credentials lets go of the previous Secret.
+	packetCluster.Spec.CredentialsRef.Name = "team-b"
+	g.Expect(r.reconcileCredentials(ctx, packetCluster)).To(Succeed())
+	g.Expect(finalizers("team-a")).To(ConsistOf(infrav1.ClusterFinalizer + "/other"))
+	g.Expect(finalizers("team-b")).To(ConsistOf(infrav1.ClusterFinalizer + "/cluster"))
+
+	// Once its resources are deleted, the cluster no longer needs its Secret.
+	g.Expect(r.releaseCredentials(ctx, packetCluster)).To(Succeed())
+	g.Expect(finalizers("team-b")).To(BeEmpty())
+	g.Expect(finalizers("team-a")).To(ConsistOf(infrav1.ClusterFinalizer + "/other"))
+
+	// Long cluster names still make valid finalizers.
+	packetCluster.Name = strings.Repeat("a", 100)
+	finalizer := credentialsFinalizer(packetCluster)
+	g.Expect(strings.TrimPrefix(finalizer, infrav1.ClusterFinalizer+"/")).To(HaveLen(63))
+	g.Expect(finalizer).ToNot(Equal(credentialsFinalizer(other)))
+}
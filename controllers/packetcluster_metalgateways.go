@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// reconcileMetalGateways creates the Metal Gateways of spec.metalGateways once their VLAN exists and deletes the
+// ones removed from it, recording the gateways of the cluster in status.metalGateways.
+func (r *PacketClusterReconciler) reconcileMetalGateways(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	log := ctrl.LoggerFrom(ctx)
+	packetCluster := clusterScope.PacketCluster
+	if len(packetCluster.Spec.MetalGateways) == 0 && len(packetCluster.Status.MetalGateways) == 0 {
+		conditions.Delete(packetCluster, infrav1.MetalGatewaysReadyCondition)
+		return nil
+	}
+
+	statuses := make([]infrav1.MetalGatewayStatus, 0, len(packetCluster.Spec.MetalGateways))
+	wanted := map[string]bool{}
+	var errs []error
+	for _, gateway := range packetCluster.Spec.MetalGateways {
+		wanted[gateway.VLAN] = true
+		if status := packetCluster.MetalGatewayStatus(gateway.VLAN); status != nil {
+			statuses = append(statuses, *status)
+			continue
+		}
+
+		vlan := packetCluster.VLANStatus(gateway.VLAN)
+		if vlan == nil {
+			conditions.MarkFalse(packetCluster, infrav1.MetalGatewaysReadyCondition, infrav1.WaitingForVLANReason, clusterv1.ConditionSeverityInfo,
+				"Metal Gateway of VLAN %s is waiting for the VLAN to be created", gateway.VLAN)
+			errs = append(errs, fmt.Errorf("VLAN %s of the metal gateway has not been created", gateway.VLAN))
+			continue
+		}
+
+		// The gateway may have been created without the status being persisted, look it up by VLAN first.
+		gw, err := r.metalClient(ctx).GetMetalGateway(ctx, packetCluster.Spec.ProjectID, vlan.ID)
+		if errors.Is(err, packet.ErrMetalGatewayNotFound) {
+			gw, err = r.metalClient(ctx).CreateMetalGateway(ctx, packetCluster.Spec.ProjectID, vlan.ID, gateway)
+			if err == nil {
+				log.Info("Created Metal Gateway", "vlan", gateway.VLAN, "id", gw.GetId())
+				record.Eventf(packetCluster, "MetalGatewayCreated", "Created Metal Gateway for VLAN %s", gateway.VLAN)
+			}
+		}
+		if err != nil {
+			conditions.MarkFalse(packetCluster, infrav1.MetalGatewaysReadyCondition, infrav1.MetalGatewayCreationFailedReason, clusterv1.ConditionSeverityError,
+				"Metal Gateway of VLAN %s: %s", gateway.VLAN, err)
+			errs = append(errs, err)
+			continue
+		}
+		statuses = append(statuses, metalGatewayStatus(gateway.VLAN, gw))
+	}
+
+	for _, status := range packetCluster.Status.MetalGateways {
+		if wanted[status.VLAN] {
+			continue
+		}
+		if err := r.metalClient(ctx).DeleteMetalGateway(ctx, status.ID); err != nil {
+			conditions.MarkFalse(packetCluster, infrav1.MetalGatewaysReadyCondition, infrav1.MetalGatewayDeletionFailedReason, clusterv1.ConditionSeverityWarning,
+				"Metal Gateway of VLAN %s: %s", status.VLAN, err)
+			statuses = append(statuses, status)
+			errs = append(errs, fmt.Errorf("failed to delete metal gateway of VLAN %s: %w", status.VLAN, err))
+			continue
+		}
+		log.Info("Deleted Metal Gateway", "vlan", status.VLAN, "id", status.ID)
+		record.Eventf(packetCluster, "MetalGatewayDeleted", "Deleted Metal Gateway of VLAN %s removed from the cluster", status.VLAN)
+	}
+
+	packetCluster.Status.MetalGateways = statuses
+	if len(errs) > 0 {
+		return kerrors.NewAggregate(errs)
+	}
+	conditions.MarkTrue(packetCluster, infrav1.MetalGatewaysReadyCondition)
+	return nil
+}
+
+// deleteMetalGateways deletes the Metal Gateways of a deleted cluster, before its VLANs can be deleted.
+func (r *PacketClusterReconciler) deleteMetalGateways(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	packetCluster := clusterScope.PacketCluster
+
+	gateways := packetCluster.Status.MetalGateways
+	for _, gateway := range packetCluster.Spec.MetalGateways {
+		vlan := packetCluster.VLANStatus(gateway.VLAN)
+		if packetCluster.MetalGatewayStatus(gateway.VLAN) != nil || vlan == nil {
+			continue
+		}
+		// The gateway may have been created without the status being persisted, look it up by VLAN.
+		gw, err := r.metalClient(ctx).GetMetalGateway(ctx, packetCluster.Spec.ProjectID, vlan.ID)
+		if errors.Is(err, packet.ErrMetalGatewayNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		gateways = append(gateways, infrav1.MetalGatewayStatus{VLAN: gateway.VLAN, ID: gw.GetId()})
+	}
+
+	var remaining []infrav1.MetalGatewayStatus
+	var errs []error
+	for _, gateway := range gateways {
+		if err := r.metalClient(ctx).DeleteMetalGateway(ctx, gateway.ID); err != nil {
+			remaining = append(remaining, gateway)
+			errs = append(errs, fmt.Errorf("metal gateway of VLAN %s: %w", gateway.VLAN, err))
+		}
+	}
+	packetCluster.Status.MetalGateways = remaining
+	return kerrors.NewAggregate(errs)
+}
+
+func metalGatewayStatus(vlan string, gateway *metal.MetalGateway) infrav1.MetalGatewayStatus {
+	address, cidr := packet.MetalGatewayAddress(gateway)
+	return infrav1.MetalGatewayStatus{
+		VLAN:            vlan,
+		ID:              gateway.GetId(),
+		IPReservationID: gateway.IpReservation.GetId(),
+		Address:         address,
+		CIDR:            cidr,
+	}
+}
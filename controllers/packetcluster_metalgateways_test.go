@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// fakeMetalGatewayAPI serves the Metal Gateways of a project, created with a private IPv4 block and deleted through
+// the API.
+type fakeMetalGatewayAPI struct {
+	gateways []metal.MetalGateway
+	created  int
+}
+
+func (f *fakeMetalGatewayAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/projects/project/metal-gateways":
+		inner := make([]metal.MetalGatewayListMetalGatewaysInner, len(f.gateways))
+		for i := range f.gateways {
+			inner[i] = metal.MetalGatewayListMetalGatewaysInner{MetalGateway: &f.gateways[i]}
+		}
+		_ = json.NewEncoder(w).Encode(metal.MetalGatewayList{MetalGateways: inner})
+	case r.Method == http.MethodPost && r.URL.Path == "/projects/project/metal-gateways":
+		var input metal.MetalGatewayCreateInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		f.created++
+		gateway := metal.MetalGateway{
+			Id:             ptr.To(fmt.Sprintf("gateway-%d", f.created)),
+			State:          ptr.To(metal.METALGATEWAYSTATE_READY),
+			VirtualNetwork: &metal.VirtualNetwork{Id: &input.VirtualNetworkId},
+			IpReservation: &metal.IPReservation{
+				Id:      ptr.To(fmt.Sprintf("reservation-%d", f.created)),
+				Network: ptr.To(fmt.Sprintf("10.0.%d.0", f.created)),
+				Gateway: ptr.To(fmt.Sprintf("10.0.%d.1", f.created)),
+				Cidr:    ptr.To[int32](29),
+				Type:    metal.IPRESERVATIONTYPE_PRIVATE_IPV4,
+			},
+		}
+		f.gateways = append(f.gateways, gateway)
+		_ = json.NewEncoder(w).Encode(gateway)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/metal-gateways/"):
+		id := strings.TrimPrefix(r.URL.Path, "/metal-gateways/")
+		for i := range f.gateways {
+			if f.gateways[i].GetId() == id {
+				_ = json.NewEncoder(w).Encode(f.gateways[i])
+				f.gateways = append(f.gateways[:i], f.gateways[i+1:]...)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestReconcileMetalGateways(t *testing.T) {
+	g := NewWithT(t)
+
+	api := &fakeMetalGatewayAPI{}
+	metalClient := packettest.NewClient(t, api)
+	r := &PacketClusterReconciler{PacketClient: metalClient}
+
+	packetCluster := &infrav1.PacketCluster{
+		Spec: infrav1.PacketClusterSpec{
+			ProjectID: "project",
+			VLANs:     []infrav1.VLAN{{Name: "storage"}, {Name: "backup"}},
+			MetalGateways: []infrav1.MetalGateway{
+				{VLAN: "storage", PrivateIPv4SubnetSize: 8},
+				{VLAN: "backup", PrivateIPv4SubnetSize: 8},
+			},
+		},
+		Status: infrav1.PacketClusterStatus{
+			VLANs: []infrav1.VLANStatus{{Name: "storage", ID: "vlan-storage"}},
+		},
+	}
+	clusterScope := &scope.ClusterScope{
+		Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		PacketCluster: packetCluster,
+	}
+	ctx := context.Background()
+
+	// Gateways are created once their VLAN exists.
+	g.Expect(r.reconcileMetalGateways(ctx, clusterScope)).ToNot(Succeed())
+	g.Expect(packetCluster.Status.MetalGateways).To(Equal([]infrav1.MetalGatewayStatus{
+		{VLAN: "storage", ID: "gateway-1", IPReservationID: "reservation-1", Address: "10.0.1.1", CIDR: "10.0.1.0/29"},
+	}))
+	g.Expect(conditions.GetReason(packetCluster, infrav1.MetalGatewaysReadyCondition)).To(Equal(infrav1.WaitingForVLANReason))
+
+	packetCluster.Status.VLANs = append(packetCluster.Status.VLANs, infrav1.VLANStatus{Name: "backup", ID: "vlan-backup"})
+	g.Expect(r.reconcileMetalGateways(ctx, clusterScope)).To(Succeed())
+	g.Expect(packetCluster.Status.MetalGateways).To(HaveLen(2))
+	g.Expect(api.gateways).To(HaveLen(2))
+	g.Expect(conditions.IsTrue(packetCluster, infrav1.MetalGatewaysReadyCondition)).To(BeTrue())
+
+	// Gateways created without their status being persisted are found again by their VLAN.
+	packetCluster.Status.MetalGateways = nil
+	g.Expect(r.reconcileMetalGateways(ctx, clusterScope)).To(Succeed())
+	g.Expect(packetCluster.Status.MetalGateways).To(HaveLen(2))
+	g.Expect(api.gateways).To(HaveLen(2))
+
+	// Gateways removed from the spec are deleted.
+	packetCluster.Spec.MetalGateways = packetCluster.Spec.MetalGateways[:1]
+	g.Expect(r.reconcileMetalGateways(ctx, clusterScope)).To(Succeed())
+	g.Expect(packetCluster.Status.MetalGateways).To(HaveLen(1))
+	g.Expect(api.gateways).To(HaveLen(1))
+
+	// The remaining gateways are deleted with the cluster.
+	g.Expect(r.deleteMetalGateways(ctx, clusterScope)).To(Succeed())
+	g.Expect(packetCluster.Status.MetalGateways).To(BeEmpty())
+	g.Expect(api.gateways).To(BeEmpty())
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// managedProjectName returns the name of the project created for a cluster with spec.project.
+func managedProjectName(clusterScope *scope.ClusterScope) string {
+	if name := clusterScope.PacketCluster.Spec.Project.Name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%s", clusterScope.Namespace(), clusterScope.Name())
+}
+
+// findManagedProject returns the project created for a cluster with spec.project, found by its name and tags, so
+// that a project created without its ID being saved is not created again.
+func (r *PacketClusterReconciler) findManagedProject(ctx context.Context, clusterScope *scope.ClusterScope) (*metal.Project, error) {
+	return r.metalClient(ctx).GetProjectByTags(ctx, clusterScope.PacketCluster.Spec.Project.OrganizationID,
+		managedProjectName(clusterScope), packet.ProjectTags(clusterScope.Namespace(), clusterScope.Name()))
+}
+
+// reconcileProject creates the project of a cluster with spec.project that does not have one yet, and sets the
+// projectID of the cluster to it.
+func (r *PacketClusterReconciler) reconcileProject(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	packetCluster := clusterScope.PacketCluster
+	if packetCluster.Spec.Project == nil || packetCluster.Spec.ProjectID != "" {
+		return nil
+	}
+
+	project, err := r.findManagedProject(ctx, clusterScope)
+	if errors.Is(err, packet.ErrProjectNotFound) {
+		project, err = r.metalClient(ctx).CreateProject(ctx, packetCluster.Spec.Project.OrganizationID,
+			managedProjectName(clusterScope), packet.ProjectTags(clusterScope.Namespace(), clusterScope.Name()))
+		if err == nil {
+			record.Eventf(packetCluster, "ProjectCreated", "Created project %s (%s)", project.GetName(), project.GetId())
+			r.Audit.Record(ctx, util.ObjectKey(clusterScope.Cluster), audit.ProjectCreated, "PacketCluster/"+packetCluster.Name, project.GetId(),
+				"Created project %s in organization %s", project.GetName(), packetCluster.Spec.Project.OrganizationID)
+		}
+	}
+	if err != nil {
+		reason := infrav1.ProjectCreationFailedReason
+		if errors.Is(err, packet.ErrProjectAccessDenied) {
+			reason = infrav1.ProjectAccessDeniedReason
+		}
+		if conditions.GetReason(packetCluster, infrav1.ProjectReadyCondition) != reason {
+			record.Warnf(packetCluster, reason, "%s", err.Error())
+		}
+		conditions.MarkFalse(packetCluster, infrav1.ProjectReadyCondition, reason, clusterv1.ConditionSeverityError, "%s", err.Error())
+		return fmt.Errorf("failed to create the project of the cluster: %w", err)
+	}
+
+	packetCluster.Spec.ProjectID = project.GetId()
+	return nil
+}
+
+// deleteProject deletes the project created for a deleted cluster with spec.project, once all the resources of the
+// cluster in it were deleted.
+func (r *PacketClusterReconciler) deleteProject(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	packetCluster := clusterScope.PacketCluster
+	if packetCluster.Spec.Project == nil {
+		return nil
+	}
+
+	projectID := packetCluster.Spec.ProjectID
+	if projectID == "" {
+		// The project may have been created without its ID being saved.
+		project, err := r.findManagedProject(ctx, clusterScope)
+		if errors.Is(err, packet.ErrProjectNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		projectID = project.GetId()
+	}
+
+	if err := r.metalClient(ctx).DeleteProject(ctx, projectID); err != nil {
+		return err
+	}
+	record.Eventf(packetCluster, "ProjectDeleted", "Deleted project %s", projectID)
+	r.Audit.Record(ctx, util.ObjectKey(clusterScope.Cluster), audit.ProjectDeleted, "PacketCluster/"+packetCluster.Name, projectID,
+		"Deleted the project of the deleted cluster")
+	return nil
+}
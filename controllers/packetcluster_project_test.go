@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// fakeProjectAPI serves the projects of an organization, created and deleted through the API.
+type fakeProjectAPI struct {
+	projects []metal.Project
+	created  int
+	forbid   bool
+}
+
+func (f *fakeProjectAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/organizations/org/projects":
+		_ = json.NewEncoder(w).Encode(metal.ProjectList{Projects: f.projects, Meta: &metal.Meta{CurrentPage: ptr.To[int32](1), LastPage: ptr.To[int32](1)}})
+	case r.Method == http.MethodPost && r.URL.Path == "/organizations/org/projects":
+		if f.forbid {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors": ["You are not authorized to create projects"]}`))
+			return
+		}
+		var input metal.ProjectCreateInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		f.created++
+		project := metal.Project{Id: ptr.To("created-project"), Name: &input.Name, Tags: input.Tags}
+		f.projects = append(f.projects, project)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(project)
+	case r.Method == http.MethodDelete && r.URL.Path == "/projects/created-project":
+		f.projects = nil
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestReconcileProject(t *testing.T) {
+	g := NewWithT(t)
+
+	api := &fakeProjectAPI{
+		// A project of the same name not created for the cluster is left alone.
+		projects: []metal.Project{{Id: ptr.To("other-project"), Name: ptr.To("default-cluster")}},
+	}
+	metalClient := packettest.NewClient(t, api)
+	r := &PacketClusterReconciler{PacketClient: metalClient}
+
+	packetCluster := &infrav1.PacketCluster{
+		Spec: infrav1.PacketClusterSpec{Project: &infrav1.ManagedProject{OrganizationID: "org"}},
+	}
+	clusterScope := &scope.ClusterScope{
+		Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster"}},
+		PacketCluster: packetCluster,
+	}
+	ctx := context.Background()
+
+	g.Expect(r.reconcileProject(ctx, clusterScope)).To(Succeed())
+	g.Expect(packetCluster.Spec.ProjectID).To(Equal("created-project"))
+	g.Expect(api.created).To(Equal(1))
+	g.Expect(api.projects[1].GetName()).To(Equal("default-cluster"))
+	g.Expect(api.projects[1].Tags).To(ConsistOf(packet.ProjectTags("default", "cluster")))
+
+	// A project created without its ID being saved is found again rather than created twice.
+	packetCluster.Spec.ProjectID = ""
+	g.Expect(r.reconcileProject(ctx, clusterScope)).To(Succeed())
+	g.Expect(packetCluster.Spec.ProjectID).To(Equal("created-project"))
+	g.Expect(api.created).To(Equal(1))
+
+	g.Expect(r.deleteProject(ctx, clusterScope)).To(Succeed())
+	g.Expect(api.projects).To(BeEmpty())
+	g.Expect(r.deleteProject(ctx, clusterScope)).To(Succeed())
+}
+
+func TestReconcileProjectAccessDenied(t *testing.T) {
+	g := NewWithT(t)
+
+	metalClient := packettest.NewClient(t, &fakeProjectAPI{forbid: true})
+	r := &PacketClusterReconciler{PacketClient: metalClient}
+
+	packetCluster := &infrav1.PacketCluster{
+		Spec: infrav1.PacketClusterSpec{Project: &infrav1.ManagedProject{OrganizationID: "org", Name: "ephemeral"}},
+	}
+	clusterScope := &scope.ClusterScope{
+		Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster"}},
+		PacketCluster: packetCluster,
+	}
+
+	g.Expect(r.reconcileProject(context.Background(), clusterScope)).ToNot(Succeed())
+	g.Expect(packetCluster.Spec.ProjectID).To(BeEmpty())
+	g.Expect(conditions.GetReason(packetCluster, infrav1.ProjectReadyCondition)).To(Equal(infrav1.ProjectAccessDeniedReason))
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// reconcileVIPReservation uses the IP reservation of spec.vipReservationID as the control plane Elastic IP of the
+// cluster instead of reserving one. The reservation is tagged for the cluster, so that its machines find it like an
+// Elastic IP reserved by the controller.
+func (r *PacketClusterReconciler) reconcileVIPReservation(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	packetCluster := clusterScope.PacketCluster
+	reservationID := packetCluster.Spec.VIPReservationID
+
+	ipReserv, err := r.metalClient(ctx).AdoptIPReservation(ctx, reservationID, clusterScope.Name(), packetCluster.Spec.ProjectID)
+	if errors.Is(err, packet.ErrIPReservationNotFound) || errors.Is(err, packet.ErrIPReservationInUse) {
+		if conditions.GetReason(packetCluster, infrav1.NetworkInfrastructureReadyCondition) != infrav1.VIPReservationUnavailableReason {
+			record.Warnf(packetCluster, infrav1.VIPReservationUnavailableReason, "%s", err.Error())
+		}
+		conditions.MarkFalse(packetCluster, infrav1.NetworkInfrastructureReadyCondition, infrav1.VIPReservationUnavailableReason, clusterv1.ConditionSeverityError,
+			"%s", err.Error())
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to use IP reservation %s: %w", reservationID, err)
+	}
+
+	if packetCluster.Status.ElasticIP == nil {
+		record.Eventf(packetCluster, "VIPReservationAdopted", "Using IP reservation %s (%s) as the control plane endpoint", reservationID, ipReserv.GetAddress())
+	}
+	// An endpoint set by the user, such as a DNS name of the address, is kept.
+	if !packetCluster.Spec.ControlPlaneEndpoint.IsValid() {
+		packetCluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{
+			Host: ipReserv.GetAddress(),
+			Port: 6443,
+		}
+	}
+	packetCluster.Status.ElasticIP = &infrav1.ElasticIPStatus{ReservationID: ipReserv.GetId(), Address: ipReserv.GetAddress()}
+	return nil
+}
+
+// releaseVIPReservation removes the tag of a deleted cluster from the IP reservation of spec.vipReservationID, so that
+// it is neither used by a cluster with the same name nor released as a stale Elastic IP.
+func (r *PacketClusterReconciler) releaseVIPReservation(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	packetCluster := clusterScope.PacketCluster
+	if err := r.metalClient(ctx).ReleaseIPReservation(ctx, packetCluster.Spec.VIPReservationID, clusterScope.Name(), packetCluster.Spec.ProjectID); err != nil {
+		return fmt.Errorf("failed to untag IP reservation %s: %w", packetCluster.Spec.VIPReservationID, err)
+	}
+	packetCluster.Status.ElasticIP = nil
+	return nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// reconcileVLANs creates the VLANs of spec.vlans that do not exist yet and deletes the VLANs removed from it,
+// recording the VLANs of the cluster in status.vlans.
+func (r *PacketClusterReconciler) reconcileVLANs(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	log := ctrl.LoggerFrom(ctx)
+	packetCluster := clusterScope.PacketCluster
+	if len(packetCluster.Spec.VLANs) == 0 && len(packetCluster.Status.VLANs) == 0 {
+		conditions.Delete(packetCluster, infrav1.VLANsReadyCondition)
+		return nil
+	}
+
+	statuses := make([]infrav1.VLANStatus, 0, len(packetCluster.Spec.VLANs))
+	wanted := map[string]bool{}
+	var errs []error
+	for _, vlan := range packetCluster.Spec.VLANs {
+		wanted[vlan.Name] = true
+		if status := packetCluster.VLANStatus(vlan.Name); status != nil {
+			statuses = append(statuses, *status)
+			continue
+		}
+
+		metro := vlan.Metro
+		if metro == "" {
+			metro = packetCluster.Spec.Metro
+		}
+		// The VLAN may have been created without the status being persisted, look it up by tag first.
+		vn, err := r.metalClient(ctx).GetVLAN(ctx, clusterScope.Name(), packetCluster.Spec.ProjectID, vlan.Name)
+		if errors.Is(err, packet.ErrVLANNotFound) {
+			vn, err = r.metalClient(ctx).CreateVLAN(ctx, clusterScope.Name(), packetCluster.Spec.ProjectID, metro, vlan)
+			if err == nil {
+				log.Info("Created VLAN", "vlan", vlan.Name, "id", vn.GetId(), "vxlan", vn.GetVxlan())
+				record.Eventf(packetCluster, "VLANCreated", "Created VLAN %s with VXLAN %d in metro %s", vlan.Name, vn.GetVxlan(), metro)
+				r.Audit.Record(ctx, util.ObjectKey(clusterScope.Cluster), audit.VLANCreated, "PacketCluster/"+packetCluster.Name, vn.GetId(),
+					"Created VLAN %s with VXLAN %d in metro %s", vlan.Name, vn.GetVxlan(), metro)
+			}
+		}
+		if err != nil {
+			conditions.MarkFalse(packetCluster, infrav1.VLANsReadyCondition, infrav1.VLANCreationFailedReason, clusterv1.ConditionSeverityError,
+				"VLAN %s: %s", vlan.Name, err)
+			errs = append(errs, err)
+			continue
+		}
+		statuses = append(statuses, infrav1.VLANStatus{Name: vlan.Name, ID: vn.GetId(), VXLAN: vn.GetVxlan(), Metro: metro})
+	}
+
+	for _, status := range packetCluster.Status.VLANs {
+		if wanted[status.Name] {
+			continue
+		}
+		if err := r.metalClient(ctx).DeleteVLAN(ctx, status.ID); err != nil {
+			// VLANs cannot be deleted while devices are attached to them, the deletion is retried until they are not.
+			conditions.MarkFalse(packetCluster, infrav1.VLANsReadyCondition, infrav1.VLANDeletionFailedReason, clusterv1.ConditionSeverityWarning,
+				"VLAN %s: %s", status.Name, err)
+			statuses = append(statuses, status)
+			errs = append(errs, fmt.Errorf("failed to delete VLAN %s: %w", status.Name, err))
+			continue
+		}
+		log.Info("Deleted VLAN", "vlan", status.Name, "id", status.ID)
+		record.Eventf(packetCluster, "VLANDeleted", "Deleted VLAN %s removed from the cluster", status.Name)
+		r.Audit.Record(ctx, util.ObjectKey(clusterScope.Cluster), audit.VLANDeleted, "PacketCluster/"+packetCluster.Name, status.ID,
+			"Deleted VLAN %s removed from the cluster", status.Name)
+	}
+
+	packetCluster.Status.VLANs = statuses
+	if len(errs) > 0 {
+		return kerrors.NewAggregate(errs)
+	}
+	conditions.MarkTrue(packetCluster, infrav1.VLANsReadyCondition)
+	return nil
+}
+
+// deleteVLANs deletes the VLANs of a deleted cluster.
+func (r *PacketClusterReconciler) deleteVLANs(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	packetCluster := clusterScope.PacketCluster
+
+	vlans := packetCluster.Status.VLANs
+	for _, vlan := range packetCluster.Spec.VLANs {
+		if packetCluster.VLANStatus(vlan.Name) != nil {
+			continue
+		}
+		// The VLAN may have been created without the status being persisted, look it up by tag.
+		vn, err := r.metalClient(ctx).GetVLAN(ctx, clusterScope.Name(), packetCluster.Spec.ProjectID, vlan.Name)
+		if errors.Is(err, packet.ErrVLANNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		vlans = append(vlans, infrav1.VLANStatus{Name: vlan.Name, ID: vn.GetId()})
+	}
+
+	var remaining []infrav1.VLANStatus
+	var errs []error
+	for _, vlan := range vlans {
+		if err := r.metalClient(ctx).DeleteVLAN(ctx, vlan.ID); err != nil {
+			remaining = append(remaining, vlan)
+			errs = append(errs, fmt.Errorf("VLAN %s: %w", vlan.Name, err))
+			continue
+		}
+		r.Audit.Record(ctx, util.ObjectKey(clusterScope.Cluster), audit.VLANDeleted, "PacketCluster/"+packetCluster.Name, vlan.ID,
+			"Deleted VLAN %s (cluster deleted)", vlan.Name)
+	}
+	packetCluster.Status.VLANs = remaining
+	return kerrors.NewAggregate(errs)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// fakeVLANAPI serves the VLANs of a project, created and deleted through the API. VLANs with attached devices cannot
+// be deleted.
+type fakeVLANAPI struct {
+	vlans []metal.VirtualNetwork
+	inUse map[string]bool
+}
+
+func (f *fakeVLANAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/projects/project/virtual-networks":
+		_ = json.NewEncoder(w).Encode(metal.VirtualNetworkList{VirtualNetworks: f.vlans})
+	case r.Method == http.MethodPost && r.URL.Path == "/projects/project/virtual-networks":
+		var input metal.VirtualNetworkCreateInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		vxlan := ptr.Deref(input.Vxlan, int32(1000+len(f.vlans)))
+		vn := metal.VirtualNetwork{Id: ptr.To(fmt.Sprintf("vlan-%d", vxlan)), Vxlan: &vxlan, MetroCode: input.Metro, Tags: input.Tags}
+		f.vlans = append(f.vlans, vn)
+		_ = json.NewEncoder(w).Encode(vn)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/virtual-networks/"):
+		id := strings.TrimPrefix(r.URL.Path, "/virtual-networks/")
+		if f.inUse[id] {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"errors": ["VLAN is in use"]}`))
+			return
+		}
+		for i := range f.vlans {
+			if f.vlans[i].GetId() == id {
+				f.vlans = append(f.vlans[:i], f.vlans[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// recordingSink keeps the audit entries written to it.
+type recordingSink struct {
+	actions []audit.Action
+}
+
+func (s *recordingSink) Write(_ context.Context, _ client.ObjectKey, entry audit.Entry) error {
+	s.actions = append(s.actions, entry.Action)
+	return nil
+}
+
+func TestReconcileVLANs(t *testing.T) {
+	g := NewWithT(t)
+
+	api := &fakeVLANAPI{inUse: map[string]bool{}}
+	metalClient := packettest.NewClient(t, api)
+	sink := &recordingSink{}
+	r := &PacketClusterReconciler{PacketClient: metalClient, Audit: audit.NewEventAggregator(sink)}
+
+	packetCluster := &infrav1.PacketCluster{
+		Spec: infrav1.PacketClusterSpec{
+			ProjectID: "project",
+			Metro:     "da",
+			VLANs: []infrav1.VLAN{
+				{Name: "storage", VXLAN: ptr.To[int32](100)},
+				{Name: "backup", Metro: "sv"},
+			},
+		},
+	}
+	clusterScope := &scope.ClusterScope{
+		Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		PacketCluster: packetCluster,
+	}
+	ctx := context.Background()
+
+	// The VLANs of the spec are created and recorded in the status.
+	g.Expect(r.reconcileVLANs(ctx, clusterScope)).To(Succeed())
+	g.Expect(api.vlans).To(HaveLen(2))
+	g.Expect(packetCluster.Status.VLANs).To(Equal([]infrav1.VLANStatus{
+		{Name: "storage", ID: "vlan-100", VXLAN: 100, Metro: "da"},
+		{Name: "backup", ID: "vlan-1001", VXLAN: 1001, Metro: "sv"},
+	}))
+	g.Expect(conditions.IsTrue(packetCluster, infrav1.VLANsReadyCondition)).To(BeTrue())
+	g.Expect(sink.actions).To(Equal([]audit.Action{audit.VLANCreated, audit.VLANCreated}))
+
+	// VLANs created without their status being persisted are found again by their tag.
+	packetCluster.Status.VLANs = nil
+	g.Expect(r.reconcileVLANs(ctx, clusterScope)).To(Succeed())
+	g.Expect(api.vlans).To(HaveLen(2))
+	g.Expect(packetCluster.Status.VLANs).To(HaveLen(2))
+
+	// VLANs removed from the spec are deleted once no device is attached to them.
+	packetCluster.Spec.VLANs = packetCluster.Spec.VLANs[:1]
+	api.inUse["vlan-1001"] = true
+	g.Expect(r.reconcileVLANs(ctx, clusterScope)).ToNot(Succeed())
+	g.Expect(packetCluster.Status.VLANs).To(HaveLen(2))
+	g.Expect(conditions.GetReason(packetCluster, infrav1.VLANsReadyCondition)).To(Equal(infrav1.VLANDeletionFailedReason))
+
+	api.inUse["vlan-1001"] = false
+	g.Expect(r.reconcileVLANs(ctx, clusterScope)).To(Succeed())
+	g.Expect(packetCluster.Status.VLANs).To(HaveLen(1))
+	g.Expect(api.vlans).To(HaveLen(1))
+
+	// The remaining VLANs are deleted with the cluster.
+	g.Expect(r.deleteVLANs(ctx, clusterScope)).To(Succeed())
+	g.Expect(packetCluster.Status.VLANs).To(BeEmpty())
+	g.Expect(api.vlans).To(BeEmpty())
+	g.Expect(sink.actions).To(Equal([]audit.Action{audit.VLANCreated, audit.VLANCreated, audit.VLANDeleted, audit.VLANDeleted}))
+}
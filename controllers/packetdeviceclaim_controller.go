@@ -0,0 +1,349 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/sharding"
+	packet "sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// deviceClaimRequeue is how often claims are reconciled while waiting for a device, or for their device to be unused.
+const deviceClaimRequeue = time.Minute
+
+// PacketDeviceClaimReconciler reconciles a PacketDeviceClaim object.
+type PacketDeviceClaimReconciler struct {
+	client.Client
+	PacketClient *packet.Client
+
+	// WatchFilterValue is the label value used to filter events prior to reconciliation.
+	WatchFilterValue string
+
+	// Shard, when set, restricts the reconciled objects to the clusters of a shard.
+	Shard *sharding.Shard
+
+	// Audit, when set, records the changes made to the infrastructure of the clusters.
+	Audit *audit.EventAggregator
+
+	// bindLock serializes the binding of claims, so that the claims of a pool are not bound to the same device.
+	bindLock sync.Mutex
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=packetdeviceclaims,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=packetdeviceclaims/status,verbs=get;update;patch
+
+func (r *PacketDeviceClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Fetch the PacketDeviceClaim instance.
+	claim := &infrav1.PacketDeviceClaim{}
+	if err := r.Client.Get(ctx, req.NamespacedName, claim); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Fetch the Cluster.
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, claim.ObjectMeta)
+	if err != nil {
+		log.Info("PacketDeviceClaim is missing cluster label or cluster does not exist")
+		return ctrl.Result{}, err
+	}
+
+	log = log.WithValues("Cluster", klog.KObj(cluster))
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	// Return early if the object or Cluster is paused.
+	if annotations.IsPaused(cluster, claim) {
+		log.Info("PacketDeviceClaim or linked Cluster is marked as paused. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	// Fetch the Packet Cluster
+	packetCluster := &infrav1.PacketCluster{}
+	packetClusterNamespacedName := client.ObjectKey{
+		Namespace: claim.Namespace,
+		Name:      cluster.Spec.InfrastructureRef.Name,
+	}
+	if err := r.Client.Get(ctx, packetClusterNamespacedName, packetCluster); err != nil {
+		log.Info("PacketCluster is not available yet")
+		return ctrl.Result{}, nil
+	}
+
+	claimScope, err := scope.NewDeviceClaimScope(scope.DeviceClaimScopeParams{
+		Client:            r.Client,
+		Cluster:           cluster,
+		PacketCluster:     packetCluster,
+		PacketDeviceClaim: claim,
+	})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create scope: %w", err)
+	}
+
+	// Always close the scope when exiting this function so we can persist any PacketDeviceClaim changes.
+	defer func() {
+		if err := claimScope.Close(ctx); err != nil && rerr == nil {
+			log.Error(err, "failed to patch packetdeviceclaim")
+			rerr = err
+		}
+	}()
+
+	// Label the Equinix Metal API calls made for this claim with its cluster.
+	ctx = packet.WithMetricLabels(ctx, claimScope.MetricLabels())
+
+	// Manage the devices with the credentials of the cluster, if it has any.
+	metalClient, err := r.PacketClient.ClientForCluster(ctx, r.Client, packetCluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	ctx = packet.WithClient(ctx, metalClient)
+
+	// Handle deleted claims
+	if !claim.ObjectMeta.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, claimScope)
+	}
+
+	// Add finalizer first if not set to avoid the race condition between init and delete.
+	if !controllerutil.ContainsFinalizer(claim, infrav1.DeviceClaimFinalizer) {
+		controllerutil.AddFinalizer(claim, infrav1.DeviceClaimFinalizer)
+		return ctrl.Result{}, nil
+	}
+	return r.reconcile(ctx, claimScope)
+}
+
+func (r *PacketDeviceClaimReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	clusterToPacketDeviceClaims, err := util.ClusterToTypedObjectsMapper(mgr.GetClient(), &infrav1.PacketDeviceClaimList{}, mgr.GetScheme())
+	if err != nil {
+		return fmt.Errorf("failed to create mapper for Cluster to PacketDeviceClaims: %w", err)
+	}
+
+	err = ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.PacketDeviceClaim{}).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(log, r.WatchFilterValue)).
+		WithEventFilter(r.Shard.Predicate(log)).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(clusterToPacketDeviceClaims),
+		).
+		// The claims follow the machines using their devices.
+		Watches(
+			&infrav1.PacketMachine{},
+			handler.EnqueueRequestsFromMapFunc(packetMachineToDeviceClaim),
+		).Complete(r)
+	if err != nil {
+		return fmt.Errorf("failed setting up with a controller manager: %w", err)
+	}
+	return nil
+}
+
+// packetMachineToDeviceClaim maps a PacketMachine to the PacketDeviceClaim it uses, if any.
+func packetMachineToDeviceClaim(_ context.Context, o client.Object) []reconcile.Request {
+	packetMachine, ok := o.(*infrav1.PacketMachine)
+	if !ok || packetMachine.Spec.DeviceClaimName == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: packetMachine.Namespace, Name: packetMachine.Spec.DeviceClaimName}}}
+}
+
+func (r *PacketDeviceClaimReconciler) reconcile(ctx context.Context, claimScope *scope.DeviceClaimScope) (ctrl.Result, error) {
+	claim := claimScope.PacketDeviceClaim
+
+	if claim.Status.Bound {
+		dev, err := r.claimedDevice(ctx, claimScope)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if dev != nil {
+			claim.Status.Machine, _ = packet.MachineNameFromTags(dev.Tags)
+			conditions.MarkTrue(claim, infrav1.DeviceBoundCondition)
+			return ctrl.Result{}, nil
+		}
+
+		// The device was deleted, or its claim tag removed, outside of Cluster API: the claim is bound again.
+		record.Warnf(claim, infrav1.DeviceNotFoundReason, "Device %s is no longer bound to the claim", claim.Status.DeviceID)
+		claim.Status.Bound = false
+		claim.Status.DeviceID = ""
+		claim.Status.Machine = ""
+	}
+
+	return r.bind(ctx, claimScope)
+}
+
+// bind binds an unbound claim to the device of its spec, or to the oldest unclaimed active device of its pool.
+func (r *PacketDeviceClaimReconciler) bind(ctx context.Context, claimScope *scope.DeviceClaimScope) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	claim := claimScope.PacketDeviceClaim
+	spec := claim.Spec
+
+	r.bindLock.Lock()
+	defer r.bindLock.Unlock()
+
+	var candidates []metal.Device
+	switch {
+	case spec.DeviceID != "":
+		dev, resp, err := r.metalClient(ctx).GetDevice(ctx, spec.DeviceID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				conditions.MarkFalse(claim, infrav1.DeviceBoundCondition, infrav1.DeviceNotFoundReason, clusterv1.ConditionSeverityError,
+					"Device %s does not exist", spec.DeviceID)
+				return ctrl.Result{RequeueAfter: deviceClaimRequeue}, nil
+			}
+			return ctrl.Result{}, fmt.Errorf("failed to retrieve device %s: %w", spec.DeviceID, err)
+		}
+		candidates = append(candidates, *dev)
+	case len(spec.PoolTags) > 0:
+		devices, err := r.metalClient(ctx).GetDevicesByTags(ctx, claimScope.PacketCluster.Spec.ProjectID, spec.PoolTags)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to list the devices of the pool: %w", err)
+		}
+		candidates = devices
+	default:
+		conditions.MarkFalse(claim, infrav1.DeviceBoundCondition, infrav1.WaitingForDeviceReason, clusterv1.ConditionSeverityError,
+			"One of spec.deviceID and spec.poolTags is required")
+		return ctrl.Result{}, nil
+	}
+
+	claimName := claimScope.Namespace() + "/" + claimScope.Name()
+	for i := range candidates {
+		dev := &candidates[i]
+		if name, ok := packet.DeviceClaimFromTags(dev.Tags); !ok || name != claimName {
+			if err := packet.CheckClaimable(dev); err != nil {
+				if spec.DeviceID != "" {
+					conditions.MarkFalse(claim, infrav1.DeviceBoundCondition, infrav1.DeviceInUseReason, clusterv1.ConditionSeverityWarning, "%s", err)
+					return ctrl.Result{RequeueAfter: deviceClaimRequeue}, nil
+				}
+				log.V(4).Info("Skipping device of the pool", "device-id", dev.GetId(), "reason", err.Error())
+				continue
+			}
+			if err := r.metalClient(ctx).ClaimDevice(ctx, dev, claimScope.Namespace(), claimScope.Name()); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to claim device %s: %w", dev.GetId(), err)
+			}
+		}
+
+		log.Info("Bound device", "device-id", dev.GetId())
+		record.Eventf(claim, "DeviceBound", "Bound device %s", dev.GetId())
+		claim.Status.Bound = true
+		claim.Status.DeviceID = dev.GetId()
+		conditions.MarkTrue(claim, infrav1.DeviceBoundCondition)
+		return ctrl.Result{}, nil
+	}
+
+	conditions.MarkFalse(claim, infrav1.DeviceBoundCondition, infrav1.WaitingForDeviceReason, clusterv1.ConditionSeverityInfo,
+		"No unclaimed active device is tagged %s", strings.Join(spec.PoolTags, ", "))
+	return ctrl.Result{RequeueAfter: deviceClaimRequeue}, nil
+}
+
+func (r *PacketDeviceClaimReconciler) reconcileDelete(ctx context.Context, claimScope *scope.DeviceClaimScope) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.Info("Reconciling Delete PacketDeviceClaim")
+	claim := claimScope.PacketDeviceClaim
+
+	dev, err := r.claimedDevice(ctx, claimScope)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if dev == nil {
+		controllerutil.RemoveFinalizer(claim, infrav1.DeviceClaimFinalizer)
+		return ctrl.Result{}, nil
+	}
+
+	// Like PersistentVolumeClaims, claims are only released once their device is no longer used.
+	if machine, ok := packet.MachineNameFromTags(dev.Tags); ok {
+		claim.Status.Machine = machine
+		conditions.MarkFalse(claim, infrav1.DeviceBoundCondition, infrav1.DeviceInUseReason, clusterv1.ConditionSeverityInfo,
+			"Device %s is used by Machine %s", dev.GetId(), machine)
+		return ctrl.Result{RequeueAfter: deviceClaimRequeue}, nil
+	}
+
+	switch claim.ReclaimPolicy() {
+	case infrav1.DeviceReclaimDelete:
+		if _, err := r.metalClient(ctx).DevicesApi.DeleteDevice(ctx, dev.GetId()).Execute(); err != nil { //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+			return ctrl.Result{}, fmt.Errorf("failed to delete device %s: %w", dev.GetId(), err)
+		}
+		record.Eventf(claim, "DeviceDeleted", "Deleted device %s", dev.GetId())
+		devicesDeletedTotal.MustCurryWith(claimScope.MetricLabels()).WithLabelValues("packetdeviceclaim").Inc()
+		r.recordAudit(ctx, claimScope, audit.DeviceDeleted, dev.GetId(), "Deleted device %s (claim deleted)", dev.GetHostname())
+	default:
+		if err := r.metalClient(ctx).UnclaimDevice(ctx, dev); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to unbind device %s: %w", dev.GetId(), err)
+		}
+		record.Eventf(claim, "DeviceUnbound", "Unbound device %s", dev.GetId())
+	}
+
+	controllerutil.RemoveFinalizer(claim, infrav1.DeviceClaimFinalizer)
+	return ctrl.Result{}, nil
+}
+
+// claimedDevice returns the device bound to the claim, or nil when the claim is not bound, or its device no longer
+// exists or no longer carries the tag of the claim.
+func (r *PacketDeviceClaimReconciler) claimedDevice(ctx context.Context, claimScope *scope.DeviceClaimScope) (*metal.Device, error) {
+	claim := claimScope.PacketDeviceClaim
+	if claim.Status.DeviceID == "" {
+		return nil, nil
+	}
+
+	dev, resp, err := r.metalClient(ctx).GetDevice(ctx, claim.Status.DeviceID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve device %s: %w", claim.Status.DeviceID, err)
+	}
+	if dev.GetState() == metal.DEVICESTATE_DEPROVISIONING ||
+		!packet.ItemsInList(dev.Tags, []string{packet.GenerateDeviceClaimTag(claimScope.Namespace(), claimScope.Name())}) {
+		return nil, nil
+	}
+	return dev, nil
+}
+
+func (r *PacketDeviceClaimReconciler) recordAudit(ctx context.Context, claimScope *scope.DeviceClaimScope, action audit.Action, resource, format string, args ...interface{}) {
+	r.Audit.Record(ctx, util.ObjectKey(claimScope.Cluster), action, "PacketDeviceClaim/"+claimScope.Name(), resource, format, args...)
+}
+
+// metalClient returns the Equinix Metal client of the cluster being reconciled, see packet.ClientForCluster.
+func (r *PacketDeviceClaimReconciler) metalClient(ctx context.Context) *packet.Client {
+	return packet.ClientFromContext(ctx, r.PacketClient)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func newDeviceClaimScope(g *WithT, claim *infrav1.PacketDeviceClaim) *scope.DeviceClaimScope {
+	claimScope, err := scope.NewDeviceClaimScope(scope.DeviceClaimScopeParams{
+		Client:            fake.NewClientBuilder().WithScheme(scopetest.Scheme()).Build(),
+		Cluster:           &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: scopetest.ClusterName, Namespace: scopetest.Namespace}},
+		PacketCluster:     &infrav1.PacketCluster{Spec: infrav1.PacketClusterSpec{ProjectID: "project"}},
+		PacketDeviceClaim: claim,
+		Patcher:           &scopetest.Patcher{},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	return claimScope
+}
+
+func TestPacketDeviceClaimReconcile(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	api := &fakeDeviceAPI{devices: []metal.Device{
+		{Id: ptr.To("used"), State: ptr.To(metal.DEVICESTATE_ACTIVE), CreatedAt: ptr.To(now.Add(-3 * time.Hour)),
+			Tags: []string{"pool", packet.GenerateMachineNameTag("other")}},
+		{Id: ptr.To("provisioning"), State: ptr.To(metal.DEVICESTATE_PROVISIONING), CreatedAt: ptr.To(now.Add(-2 * time.Hour)),
+			Tags: []string{"pool"}},
+		{Id: ptr.To("free"), State: ptr.To(metal.DEVICESTATE_ACTIVE), CreatedAt: ptr.To(now.Add(-time.Hour)),
+			Tags: []string{"pool"}},
+	}}
+	metalClient := packettest.NewClient(t, api)
+	r := &PacketDeviceClaimReconciler{PacketClient: metalClient}
+	ctx := context.Background()
+
+	claim := &infrav1.PacketDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: scopetest.Namespace},
+		Spec:       infrav1.PacketDeviceClaimSpec{PoolTags: []string{"pool"}},
+	}
+	claimScope := newDeviceClaimScope(g, claim)
+
+	// The claim is bound to the oldest active device of its pool no machine uses.
+	_, err := r.reconcile(ctx, claimScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(claim.Status.Bound).To(BeTrue())
+	g.Expect(claim.Status.DeviceID).To(Equal("free"))
+	g.Expect(conditions.IsTrue(claim, infrav1.DeviceBoundCondition)).To(BeTrue())
+	g.Expect(api.device("free").Tags).To(ConsistOf("pool", packet.GenerateDeviceClaimTag(scopetest.Namespace, "claim")))
+
+	// Another claim of the pool waits for a device.
+	other := &infrav1.PacketDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: scopetest.Namespace},
+		Spec:       infrav1.PacketDeviceClaimSpec{PoolTags: []string{"pool"}},
+	}
+	result, err := r.reconcile(ctx, newDeviceClaimScope(g, other))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(deviceClaimRequeue))
+	g.Expect(other.Status.Bound).To(BeFalse())
+	g.Expect(conditions.GetReason(other, infrav1.DeviceBoundCondition)).To(Equal(infrav1.WaitingForDeviceReason))
+
+	// The claim reports the machine its device is assigned to, and is not released while the device is used.
+	g.Expect(metalClient.AssignClaimedDevice(ctx, api.device("free"), scopetest.Namespace, "claim", "machine", scopetest.ClusterName)).To(Succeed())
+	_, err = r.reconcile(ctx, claimScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(claim.Status.Machine).To(Equal("machine"))
+
+	controllerutil.AddFinalizer(claim, infrav1.DeviceClaimFinalizer)
+	result, err = r.reconcileDelete(ctx, claimScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(deviceClaimRequeue))
+	g.Expect(claim.Finalizers).To(ContainElement(infrav1.DeviceClaimFinalizer))
+	g.Expect(conditions.GetReason(claim, infrav1.DeviceBoundCondition)).To(Equal(infrav1.DeviceInUseReason))
+
+	// Once the machine is gone, the device returns to its pool.
+	g.Expect(metalClient.DetachDevice(ctx, api.device("free"))).To(Succeed())
+	_, err = r.reconcileDelete(ctx, claimScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(claim.Finalizers).To(BeEmpty())
+	g.Expect(api.device("free").Tags).To(ConsistOf("pool"))
+}
+
+func TestPacketDeviceClaimReconcileDelete(t *testing.T) {
+	g := NewWithT(t)
+
+	api := &fakeDeviceAPI{devices: []metal.Device{
+		{Id: ptr.To("device"), State: ptr.To(metal.DEVICESTATE_ACTIVE), Tags: []string{packet.GenerateMachineNameTag("other")}},
+	}}
+	metalClient := packettest.NewClient(t, api)
+	r := &PacketDeviceClaimReconciler{PacketClient: metalClient}
+	ctx := context.Background()
+
+	claim := &infrav1.PacketDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: scopetest.Namespace},
+		Spec:       infrav1.PacketDeviceClaimSpec{DeviceID: "device", ReclaimPolicy: infrav1.DeviceReclaimDelete},
+	}
+	claimScope := newDeviceClaimScope(g, claim)
+
+	// A device managed by a machine cannot be claimed.
+	_, err := r.reconcile(ctx, claimScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(claim.Status.Bound).To(BeFalse())
+	g.Expect(conditions.GetReason(claim, infrav1.DeviceBoundCondition)).To(Equal(infrav1.DeviceInUseReason))
+
+	// Deleting an unbound claim leaves the device alone.
+	controllerutil.AddFinalizer(claim, infrav1.DeviceClaimFinalizer)
+	_, err = r.reconcileDelete(ctx, claimScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(claim.Finalizers).To(BeEmpty())
+	g.Expect(api.devices).To(HaveLen(1))
+
+	// The device of a bound claim is deleted with the claim.
+	api.device("device").Tags = nil
+	_, err = r.reconcile(ctx, claimScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(claim.Status.Bound).To(BeTrue())
+
+	controllerutil.AddFinalizer(claim, infrav1.DeviceClaimFinalizer)
+	_, err = r.reconcileDelete(ctx, claimScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(claim.Finalizers).To(BeEmpty())
+	g.Expect(api.devices).To(BeEmpty())
+}
+
+func TestPacketMachineClaimedDevice(t *testing.T) {
+	g := NewWithT(t)
+
+	api := &fakeDeviceAPI{devices: []metal.Device{
+		{Id: ptr.To("device"), State: ptr.To(metal.DEVICESTATE_ACTIVE),
+			Tags: []string{"pool", packet.GenerateDeviceClaimTag(scopetest.Namespace, "claim")}},
+	}}
+	metalClient := packettest.NewClient(t, api)
+	ctx := context.Background()
+
+	claim := &infrav1.PacketDeviceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "claim",
+			Namespace: scopetest.Namespace,
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: scopetest.ClusterName},
+		},
+	}
+	machineScope, c, err := scopetest.NewMachineScopeBuilder().
+		WithPacketMachine(&infrav1.PacketMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: scopetest.MachineName, Namespace: scopetest.Namespace},
+			Spec:       infrav1.PacketMachineSpec{DeviceClaimName: "claim"},
+		}).
+		WithObjects(claim).
+		WithPatcher(&scopetest.Patcher{}).
+		Build()
+	g.Expect(err).ToNot(HaveOccurred())
+	r := &PacketMachineReconciler{Client: c, PacketClient: metalClient}
+
+	// The machine waits for its claim to be bound.
+	dev, err := r.claimedDevice(ctx, machineScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(dev).To(BeNil())
+	g.Expect(conditions.GetReason(machineScope.PacketMachine, infrav1.DeviceReadyCondition)).To(Equal(infrav1.WaitingForDeviceClaimReason))
+
+	// The device of the bound claim is assigned to the machine.
+	claim.Status = infrav1.PacketDeviceClaimStatus{Bound: true, DeviceID: "device"}
+	g.Expect(c.Update(ctx, claim)).To(Succeed())
+	dev, err = r.claimedDevice(ctx, machineScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(dev.GetId()).To(Equal("device"))
+	g.Expect(api.device("device").Tags).To(ContainElements(
+		"pool", packet.GenerateDeviceClaimTag(scopetest.Namespace, "claim"), packet.GenerateMachineNameTag(scopetest.MachineName)))
+	g.Expect(verifyDeviceIdentity(machineScope, dev)).To(Succeed())
+
+	// Deleting the machine returns the device to its claim.
+	controllerutil.AddFinalizer(machineScope.PacketMachine, infrav1.MachineFinalizer)
+	_, err = r.returnClaimedDevice(ctx, machineScope, dev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(machineScope.PacketMachine.Finalizers).To(BeEmpty())
+	g.Expect(api.devices).To(HaveLen(1))
+	g.Expect(api.device("device").Tags).To(ContainElement(packet.GenerateDeviceClaimTag(scopetest.Namespace, "claim")))
+	g.Expect(api.device("device").Tags).ToNot(ContainElement(packet.GenerateMachineNameTag(scopetest.MachineName)))
+}
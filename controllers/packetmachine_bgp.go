@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// bgpSessionPollInterval is how often the BGP sessions of a device are checked while some are not established.
+const bgpSessionPollInterval = 30 * time.Second
+
+// reconcileBGPSessions records the state of the BGP sessions of the device, which kube-vip relies on to fail the
+// control plane endpoint over, and reports sessions that stay down for longer than BGPSessionTimeout.
+func (r *PacketMachineReconciler) reconcileBGPSessions(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) (ctrl.Result, error) {
+	packetMachine := machineScope.PacketMachine
+
+	sessions, err := r.metalClient(ctx).GetBGPSessions(ctx, dev.GetId())
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to retrieve the BGP sessions of device %s: %w", dev.GetId(), err)
+	}
+
+	now := metav1.Now()
+	packetMachine.Status.BGPSessions = bgpSessionStatuses(packetMachine.Status.BGPSessions, sessions, now)
+
+	var down []string
+	var downSince time.Time
+	for _, session := range packetMachine.Status.BGPSessions {
+		if session.State == infrav1.BGPSessionUp {
+			continue
+		}
+		down = append(down, fmt.Sprintf("%s (%s)", session.AddressFamily, session.State))
+		if downSince.IsZero() || session.LastTransitionTime.Time.Before(downSince) {
+			downSince = session.LastTransitionTime.Time
+		}
+	}
+
+	if len(packetMachine.Status.BGPSessions) > 0 && len(down) == 0 {
+		conditions.MarkTrue(packetMachine, infrav1.BGPSessionsReadyCondition)
+		return ctrl.Result{}, nil
+	}
+
+	if len(down) == 0 {
+		conditions.MarkFalse(packetMachine, infrav1.BGPSessionsReadyCondition, infrav1.BGPSessionsDownReason, clusterv1.ConditionSeverityInfo,
+			"device has no BGP session")
+		return ctrl.Result{RequeueAfter: bgpSessionPollInterval}, nil
+	}
+
+	severity := clusterv1.ConditionSeverityInfo
+	if r.BGPSessionTimeout > 0 && now.Sub(downSince) > r.BGPSessionTimeout {
+		severity = clusterv1.ConditionSeverityWarning
+		if previous := conditions.GetSeverity(packetMachine, infrav1.BGPSessionsReadyCondition); previous == nil || *previous != clusterv1.ConditionSeverityWarning {
+			ctrl.LoggerFrom(ctx).Info("BGP sessions are down", "device-id", dev.GetId(), "sessions", down)
+			record.Warnf(packetMachine, infrav1.BGPSessionsDownReason,
+				"BGP sessions %s of device %s are down since %s, kube-vip cannot fail the control plane endpoint over to it",
+				strings.Join(down, ", "), dev.GetId(), downSince.Format(time.RFC3339))
+		}
+	}
+	conditions.MarkFalse(packetMachine, infrav1.BGPSessionsReadyCondition, infrav1.BGPSessionsDownReason, severity,
+		"BGP sessions not established: %s", strings.Join(down, ", "))
+	return ctrl.Result{RequeueAfter: bgpSessionPollInterval}, nil
+}
+
+// bgpSessionStatuses returns the status of the BGP sessions of a device. The transition time of a session only
+// changes when it is established or lost, and sessions seen for the first time are down since their creation.
+func bgpSessionStatuses(previous []infrav1.BGPSession, sessions []metal.BgpSession, now metav1.Time) []infrav1.BGPSession {
+	statuses := make([]infrav1.BGPSession, 0, len(sessions))
+	for _, session := range sessions {
+		status := infrav1.BGPSession{
+			AddressFamily:      string(session.GetAddressFamily()),
+			State:              packet.BGPSessionState(session),
+			LastTransitionTime: now,
+		}
+
+		known := false
+		for _, prev := range previous {
+			if prev.AddressFamily != status.AddressFamily {
+				continue
+			}
+			known = true
+			if (prev.State == infrav1.BGPSessionUp) == (status.State == infrav1.BGPSessionUp) {
+				status.LastTransitionTime = prev.LastTransitionTime
+			}
+		}
+		if !known && status.State != infrav1.BGPSessionUp && session.CreatedAt != nil {
+			status.LastTransitionTime = metav1.NewTime(*session.CreatedAt)
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
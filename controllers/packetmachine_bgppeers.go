@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// bgpPeerAnnotationPrefix prefixes the BGP peering annotations of Nodes. The annotations follow the ones of the
+// Equinix Metal cloud provider, metal.equinix.com/bgp-peers-<n>-<key>, so CNI configurations written for it work
+// as is.
+const bgpPeerAnnotationPrefix = "metal.equinix.com/bgp-peers-"
+
+// reconcileBGPPeerAnnotations annotates the Node of the machine with the BGP peering information of its device, for
+// BGP-capable CNIs to peer with the Equinix Metal routers without discovering them by hand.
+func (r *PacketMachineReconciler) reconcileBGPPeerAnnotations(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) error {
+	nodeRef := machineScope.Machine.Status.NodeRef
+	if nodeRef == nil {
+		// The Machine is updated, and the PacketMachine reconciled again, once the Node registers.
+		return nil
+	}
+
+	// Like kube-vip, the CNI needs a BGP session on the device to peer over.
+	if err := r.metalClient(ctx).EnsureNodeBGPEnabled(ctx, dev.GetId()); err != nil {
+		return fmt.Errorf("failed to enable bgp on device %s: %w", dev.GetId(), err)
+	}
+	neighbors, _, err := r.metalClient(ctx).DevicesApi.GetBgpNeighborData(ctx, dev.GetId()).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the BGP neighbors of device %s: %w", dev.GetId(), err)
+	}
+
+	workloadClient, err := remote.NewClusterClient(ctx, "packetmachine-controller", r.Client, util.ObjectKey(machineScope.Cluster))
+	if err != nil {
+		return fmt.Errorf("failed to create workload cluster client: %w", err)
+	}
+	if err := annotateNodeWithBGPPeers(ctx, workloadClient, nodeRef.Name, bgpPeerAnnotations(neighbors.BgpNeighbors)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// bgpPeerAnnotations returns the Node annotations describing the BGP peers of a device, one set of annotations per
+// peer IP. The MD5 password of the sessions is left out, as annotations are readable by anyone who can read Nodes.
+func bgpPeerAnnotations(neighbors []metal.BgpNeighborData) map[string]string {
+	annotations := map[string]string{}
+	n := 0
+	for _, neighbor := range neighbors {
+		for _, peerIP := range neighbor.PeerIps {
+			prefix := fmt.Sprintf("%s%d-", bgpPeerAnnotationPrefix, n)
+			annotations[prefix+"node-asn"] = strconv.Itoa(int(neighbor.GetCustomerAs()))
+			annotations[prefix+"peer-asn"] = strconv.Itoa(int(neighbor.GetPeerAs()))
+			annotations[prefix+"peer-ip"] = peerIP
+			annotations[prefix+"src-ip"] = neighbor.GetCustomerIp()
+			n++
+		}
+	}
+	return annotations
+}
+
+// annotateNodeWithBGPPeers sets the BGP peering annotations of a Node, removing the ones of peers that are gone.
+func annotateNodeWithBGPPeers(ctx context.Context, workloadClient client.Client, nodeName string, annotations map[string]string) error {
+	node := &corev1.Node{}
+	if err := workloadClient.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		return fmt.Errorf("failed to get Node %s: %w", nodeName, err)
+	}
+
+	base := node.DeepCopy()
+	changed := false
+	for key := range node.Annotations {
+		if _, ok := annotations[key]; !ok && strings.HasPrefix(key, bgpPeerAnnotationPrefix) {
+			delete(node.Annotations, key)
+			changed = true
+		}
+	}
+	for key, value := range annotations {
+		if node.Annotations[key] != value {
+			if node.Annotations == nil {
+				node.Annotations = map[string]string{}
+			}
+			node.Annotations[key] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := workloadClient.Patch(ctx, node, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to annotate Node %s with its BGP peers: %w", nodeName, err)
+	}
+	ctrl.LoggerFrom(ctx).Info("Annotated Node with its BGP peers", "node", nodeName)
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAnnotateNodeWithBGPPeers(t *testing.T) {
+	g := NewWithT(t)
+
+	annotations := bgpPeerAnnotations([]metal.BgpNeighborData{
+		{
+			AddressFamily: ptr.To(int32(4)),
+			CustomerAs:    ptr.To(int32(65000)),
+			CustomerIp:    ptr.To("10.67.50.3"),
+			PeerAs:        ptr.To(int32(65530)),
+			PeerIps:       []string{"169.254.255.1", "169.254.255.2"},
+			Md5Password:   ptr.To("secret"),
+		},
+	})
+	g.Expect(annotations).To(Equal(map[string]string{
+		"metal.equinix.com/bgp-peers-0-node-asn": "65000",
+		"metal.equinix.com/bgp-peers-0-peer-asn": "65530",
+		"metal.equinix.com/bgp-peers-0-peer-ip":  "169.254.255.1",
+		"metal.equinix.com/bgp-peers-0-src-ip":   "10.67.50.3",
+		"metal.equinix.com/bgp-peers-1-node-asn": "65000",
+		"metal.equinix.com/bgp-peers-1-peer-asn": "65530",
+		"metal.equinix.com/bgp-peers-1-peer-ip":  "169.254.255.2",
+		"metal.equinix.com/bgp-peers-1-src-ip":   "10.67.50.3",
+	}))
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name: "node",
+		Annotations: map[string]string{
+			"metal.equinix.com/bgp-peers-2-peer-ip": "169.254.255.3",
+			"unrelated":                             "kept",
+		},
+	}}
+	workloadClient := fake.NewClientBuilder().WithObjects(node).Build()
+
+	g.Expect(annotateNodeWithBGPPeers(context.Background(), workloadClient, "node", annotations)).To(Succeed())
+
+	g.Expect(workloadClient.Get(context.Background(), client.ObjectKey{Name: "node"}, node)).To(Succeed())
+	g.Expect(node.Annotations).To(HaveKeyWithValue("unrelated", "kept"))
+	g.Expect(node.Annotations).ToNot(HaveKey("metal.equinix.com/bgp-peers-2-peer-ip"))
+	for key, value := range annotations {
+		g.Expect(node.Annotations).To(HaveKeyWithValue(key, value))
+	}
+}
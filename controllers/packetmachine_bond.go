@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// bondPollInterval is how long the ports of a device are given to bond again before they are reported.
+const bondPollInterval = time.Minute
+
+// reconcileNetworkBond bonds the disbonded ports of a device again, once, and reports the ports that stay disbonded,
+// e.g. because the LACP partner of the bond is misconfigured.
+func (r *PacketMachineReconciler) reconcileNetworkBond(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) (ctrl.Result, error) {
+	packetMachine := machineScope.PacketMachine
+
+	ports := disbondedPorts(dev)
+	if len(ports) == 0 {
+		conditions.MarkTrue(packetMachine, infrav1.NetworkBondReadyCondition)
+		return ctrl.Result{}, nil
+	}
+
+	names := make([]string, 0, len(ports))
+	for _, port := range ports {
+		names = append(names, port.GetName())
+	}
+
+	switch conditions.GetReason(packetMachine, infrav1.NetworkBondReadyCondition) {
+	case infrav1.PortsDisbondedReason:
+		return ctrl.Result{}, nil
+	case infrav1.RebondingPortsReason:
+		// Bonding the ports takes a moment, give it time before escalating.
+		if since := time.Since(conditions.GetLastTransitionTime(packetMachine, infrav1.NetworkBondReadyCondition).Time); since < bondPollInterval {
+			return ctrl.Result{RequeueAfter: bondPollInterval - since}, nil
+		}
+		ctrl.LoggerFrom(ctx).Info("Ports are still disbonded after bonding them again", "device-id", dev.GetId(), "ports", names)
+		record.Warnf(packetMachine, infrav1.PortsDisbondedReason,
+			"Ports %s of device %s are still disbonded after bonding them again, check the LACP configuration of the device",
+			strings.Join(names, ", "), dev.GetId())
+		conditions.MarkFalse(packetMachine, infrav1.NetworkBondReadyCondition, infrav1.PortsDisbondedReason, clusterv1.ConditionSeverityWarning,
+			"ports %s are disbonded", strings.Join(names, ", "))
+		return ctrl.Result{}, nil
+	}
+
+	for _, port := range ports {
+		if _, _, err := r.metalClient(ctx).PortsApi.BondPort(ctx, port.GetId()).BulkEnable(false).Execute(); err != nil { //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+			return ctrl.Result{}, fmt.Errorf("failed to bond port %s of device %s: %w", port.GetName(), dev.GetId(), err)
+		}
+	}
+	ctrl.LoggerFrom(ctx).Info("Bonded disbonded ports again", "device-id", dev.GetId(), "ports", names)
+	record.Eventf(packetMachine, infrav1.RebondingPortsReason, "Bonding disbonded ports %s of device %s again", strings.Join(names, ", "), dev.GetId())
+	conditions.MarkFalse(packetMachine, infrav1.NetworkBondReadyCondition, infrav1.RebondingPortsReason, clusterv1.ConditionSeverityInfo,
+		"bonding ports %s again", strings.Join(names, ", "))
+	return ctrl.Result{RequeueAfter: bondPollInterval}, nil
+}
+
+// disbondedPorts returns the network ports of a device that belong to a bond but are not bonded. Ports disbonded
+// on purpose, by converting the device to a hybrid or layer 2 individual network type, are not returned.
+func disbondedPorts(dev *metal.Device) []metal.Port {
+	bonded := map[string]bool{}
+	for _, port := range dev.NetworkPorts {
+		if port.GetType() != metal.PORTTYPE_NETWORK_BOND_PORT {
+			continue
+		}
+		switch port.GetNetworkType() {
+		case metal.PORTNETWORKTYPE_LAYER3, metal.PORTNETWORKTYPE_HYBRID_BONDED, metal.PORTNETWORKTYPE_LAYER2_BONDED:
+			bonded[port.GetName()] = true
+		}
+	}
+
+	var ports []metal.Port
+	for _, port := range dev.NetworkPorts {
+		if port.GetType() != metal.PORTTYPE_NETWORK_PORT || port.Bond == nil || !bonded[port.Bond.GetName()] {
+			continue
+		}
+		if data, ok := port.GetDataOk(); ok && !data.GetBonded() {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+func bondedDevice(networkType metal.PortNetworkType, eth1Bonded bool) *metal.Device {
+	return &metal.Device{
+		Id: ptr.To("device"),
+		NetworkPorts: []metal.Port{
+			{Id: ptr.To("bond0"), Name: ptr.To("bond0"), Type: metal.PORTTYPE_NETWORK_BOND_PORT.Ptr(), NetworkType: networkType.Ptr()},
+			{
+				Id: ptr.To("eth0"), Name: ptr.To("eth0"), Type: metal.PORTTYPE_NETWORK_PORT.Ptr(),
+				Bond: &metal.BondPortData{Name: ptr.To("bond0")}, Data: &metal.PortData{Bonded: ptr.To(true)},
+			},
+			{
+				Id: ptr.To("eth1"), Name: ptr.To("eth1"), Type: metal.PORTTYPE_NETWORK_PORT.Ptr(),
+				Bond: &metal.BondPortData{Name: ptr.To("bond0")}, Data: &metal.PortData{Bonded: ptr.To(eth1Bonded)},
+			},
+		},
+	}
+}
+
+func TestDisbondedPorts(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(disbondedPorts(bondedDevice(metal.PORTNETWORKTYPE_LAYER3, true))).To(BeEmpty())
+	g.Expect(disbondedPorts(bondedDevice(metal.PORTNETWORKTYPE_LAYER3, false))).To(HaveLen(1))
+	g.Expect(disbondedPorts(bondedDevice(metal.PORTNETWORKTYPE_HYBRID_BONDED, false))).To(HaveLen(1))
+	// eth1 is disbonded on purpose in hybrid mode.
+	g.Expect(disbondedPorts(bondedDevice(metal.PORTNETWORKTYPE_HYBRID, false))).To(BeEmpty())
+}
+
+func TestReconcileNetworkBond(t *testing.T) {
+	g := NewWithT(t)
+
+	var requests []string
+	client := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "eth1"}`))
+	}))
+	r := &PacketMachineReconciler{PacketClient: client}
+
+	machineScope := &scope.MachineScope{
+		Machine:       &clusterv1.Machine{},
+		PacketMachine: &infrav1.PacketMachine{},
+	}
+	ctx := context.Background()
+
+	// Disbonded ports are bonded again once.
+	result, err := r.reconcileNetworkBond(ctx, machineScope, bondedDevice(metal.PORTNETWORKTYPE_LAYER3, false))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(bondPollInterval))
+	g.Expect(requests).To(Equal([]string{"POST /ports/eth1/bond"}))
+	g.Expect(conditions.GetReason(machineScope.PacketMachine, infrav1.NetworkBondReadyCondition)).To(Equal(infrav1.RebondingPortsReason))
+
+	// They are given time to bond.
+	requests = nil
+	result, err = r.reconcileNetworkBond(ctx, machineScope, bondedDevice(metal.PORTNETWORKTYPE_LAYER3, false))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+	g.Expect(requests).To(BeEmpty())
+	g.Expect(conditions.GetReason(machineScope.PacketMachine, infrav1.NetworkBondReadyCondition)).To(Equal(infrav1.RebondingPortsReason))
+
+	// Ports still disbonded afterwards are escalated, and not bonded again.
+	for i := range machineScope.PacketMachine.Status.Conditions {
+		machineScope.PacketMachine.Status.Conditions[i].LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * bondPollInterval))
+	}
+	for i := 0; i < 2; i++ {
+		result, err = r.reconcileNetworkBond(ctx, machineScope, bondedDevice(metal.PORTNETWORKTYPE_LAYER3, false))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result.IsZero()).To(BeTrue())
+		g.Expect(requests).To(BeEmpty())
+		g.Expect(conditions.GetReason(machineScope.PacketMachine, infrav1.NetworkBondReadyCondition)).To(Equal(infrav1.PortsDisbondedReason))
+		g.Expect(*conditions.GetSeverity(machineScope.PacketMachine, infrav1.NetworkBondReadyCondition)).To(Equal(clusterv1.ConditionSeverityWarning))
+	}
+
+	// Bonded ports clear the condition.
+	_, err = r.reconcileNetworkBond(ctx, machineScope, bondedDevice(metal.PORTNETWORKTYPE_LAYER3, true))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(conditions.IsTrue(machineScope.PacketMachine, infrav1.NetworkBondReadyCondition)).To(BeTrue())
+}
@@ -21,7 +21,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
@@ -30,10 +32,12 @@ import (
 	"k8s.io/klog/v2"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -43,15 +47,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
 	"sigs.k8s.io/cluster-api-provider-packet/internal/emlb"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/readonly"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/sharding"
 	packet "sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
 	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
 	clog "sigs.k8s.io/cluster-api/util/log"
 )
 
-const (
-	force = true
-)
+// readOnlyRequeueInterval is how often a read-only manager reconciles the PacketMachines whose device it would have
+// created or deleted, to notice the active manager doing it.
+const readOnlyRequeueInterval = time.Minute
 
 var (
 	errMissingDevice = errors.New("machine does not exist")
@@ -66,13 +73,84 @@ type PacketMachineReconciler struct {
 
 	// WatchFilterValue is the label value used to filter events prior to reconciliation.
 	WatchFilterValue string
+
+	// Shard, when set, restricts the reconciled objects to the clusters of a shard.
+	Shard *sharding.Shard
+
+	// ReadOnly leaves creating, recreating and deleting devices to the active manager, see readonly.
+	ReadOnly bool
+
+	// DeprovisionTimeout, when set, keeps deleted PacketMachines until their device is fully deprovisioned, or for
+	// at most this long, so that replacement machines do not fail on capacity still held by the old device.
+	DeprovisionTimeout time.Duration
+
+	// BootDiagnostics enables the collection of boot diagnostics for machines that fail to provision, or whose Node
+	// does not show up within BootstrapTimeout of the device creation.
+	BootDiagnostics  bool
+	BootstrapTimeout time.Duration
+
+	// DeleteDuplicateDevices enables deleting the devices carrying the tags of a PacketMachine other than the one it
+	// uses, instead of only reporting them with the DuplicateDevices condition.
+	DeleteDuplicateDevices bool
+
+	// BondRemediation enables bonding the network ports of running devices again when they are found disbonded.
+	BondRemediation bool
+
+	// RootPasswordSecrets enables storing the root password of new devices in a <packetmachine>-root-password Secret.
+	RootPasswordSecrets bool
+
+	// HostnameReconciliation enables renaming running devices whose hostname differs from the one of their machine.
+	HostnameReconciliation bool
+
+	// Audit, when set, records the changes made to the infrastructure of the clusters.
+	Audit *audit.EventAggregator
+
+	// ProviderIDPrefix is the prefix of the providerIDs given to new machines, one of scope.ProviderIDFormats.
+	// Defaults to scope.ProviderIDPrefix.
+	ProviderIDPrefix string
+
+	// PlatformLabels enables labeling PacketMachines with the kubernetes.io/arch and kubernetes.io/os of their device.
+	PlatformLabels bool
+
+	// HardwareReservationTimeout, when set, fails PacketMachines whose hardware reservations are still all busy
+	// this long after they were created.
+	HardwareReservationTimeout time.Duration
+
+	// BGPSessionTimeout is how long the BGP sessions of a device of a kube-vip cluster may stay down before they are
+	// reported with a warning.
+	BGPSessionTimeout time.Duration
+
+	// APICallWarningThreshold, when set, is the number of Equinix Metal API calls above which a reconciliation is
+	// reported with a warning event listing the calls by endpoint.
+	APICallWarningThreshold int
+
+	// DevicePollMaxInterval is the longest provisioning devices go without being checked, see devicePollInterval.
+	DevicePollMaxInterval time.Duration
+
+	// MaintenanceCheckInterval, when set, is how often the events of running devices are checked for maintenances
+	// scheduled by Equinix Metal, which are reported with the MaintenanceScheduled condition.
+	MaintenanceCheckInterval time.Duration
+
+	// MaintenanceCordonLeadTime, when set, is how long ahead of a maintenance of its device the Node of a machine is
+	// cordoned, unless the PacketMachine has the tolerate-maintenance annotation. Maintenances are tolerated when 0.
+	MaintenanceCordonLeadTime time.Duration
+
+	adoptLock sync.Mutex
+
+	maintenanceLock   sync.Mutex
+	maintenanceChecks map[string]time.Time
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=packetmachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=packetmachines/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;machinesets;machines;machines/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=patch;update
 // +kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=kubeadmconfigs;kubeadmconfigs/status,verbs=get;list;watch
-// +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddressclaims,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=ipam.cluster.x-k8s.io,resources=ipaddresses,verbs=get;list;watch
 
 func (r *PacketMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
 	log := ctrl.LoggerFrom(ctx)
@@ -164,6 +242,22 @@ func (r *PacketMachineReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}()
 
+	// Account the Equinix Metal API calls made for this machine against the budget of its cluster, and label their
+	// metrics with it.
+	budgetKey := util.ObjectKey(cluster).String()
+	ctx = packet.WithClusterBudget(ctx, budgetKey)
+	ctx = packet.WithMetricLabels(ctx, machineScope.MetricLabels())
+	defer reconcileThrottledCondition(r.PacketClient, packetmachine, budgetKey)
+	ctx, apiCalls := packet.WithAPICallAccounting(ctx)
+	defer reportAPICalls(packetmachine, apiCalls, r.APICallWarningThreshold)
+
+	// Manage the device with the credentials of the cluster, if it has any.
+	metalClient, err := r.PacketClient.ClientForCluster(ctx, r.Client, packetcluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	ctx = packet.WithClient(ctx, metalClient)
+
 	// Add finalizer first if not set to avoid the race condition between init and delete.
 	// Note: Finalizers in general can only be added when the deletionTimestamp is not set.
 	if packetmachine.ObjectMeta.DeletionTimestamp.IsZero() && !controllerutil.ContainsFinalizer(packetmachine, infrav1.MachineFinalizer) {
@@ -173,8 +267,7 @@ func (r *PacketMachineReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	// Handle deleted machines
 	if !packetmachine.ObjectMeta.DeletionTimestamp.IsZero() {
-		err = r.reconcileDelete(ctx, machineScope)
-		return ctrl.Result{}, err
+		return r.reconcileDelete(ctx, machineScope)
 	}
 	return r.reconcile(ctx, machineScope)
 }
@@ -188,9 +281,10 @@ func (r *PacketMachineReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 	}
 
 	err = ctrl.NewControllerManagedBy(mgr).
-		For(&infrav1.PacketMachine{}).
+		For(&infrav1.PacketMachine{}, builder.WithPredicates(ignoreStatusUpdates(log, "packetmachine"))).
 		WithOptions(options).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(log, r.WatchFilterValue)).
+		WithEventFilter(r.Shard.Predicate(log)).
 		Watches(
 			&clusterv1.Machine{},
 			handler.EnqueueRequestsFromMapFunc(util.MachineToInfrastructureMapFunc(infrav1.GroupVersion.WithKind("PacketMachine"))),
@@ -199,6 +293,7 @@ func (r *PacketMachineReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 			&infrav1.PacketCluster{},
 			handler.EnqueueRequestsFromMapFunc(r.PacketClusterToPacketMachines),
 		).
+		Owns(&ipamv1.IPAddressClaim{}).
 		Watches(
 			&clusterv1.Cluster{},
 			handler.EnqueueRequestsFromMapFunc(clusterToPacketMachines),
@@ -295,7 +390,7 @@ func (r *PacketMachineReconciler) reconcile(ctx context.Context, machineScope *s
 		// If we already have a device ID, then retrieve the device using the
 		// device ID. This means that the Machine has already been created
 		// and we successfully recorded the device ID.
-		dev, resp, err = r.PacketClient.GetDevice(ctx, deviceID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		dev, resp, err = r.metalClient(ctx).GetDevice(ctx, deviceID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
 		if err != nil {
 			if resp != nil {
 				if resp.StatusCode == http.StatusNotFound {
@@ -313,28 +408,82 @@ func (r *PacketMachineReconciler) reconcile(ctx context.Context, machineScope *s
 
 			return ctrl.Result{}, err
 		}
+
+		if err := verifyDeviceIdentity(machineScope, dev); err != nil {
+			log.Error(err, "refusing to manage device", "device-id", deviceID)
+			reportDeviceIdentityMismatch(machineScope, err)
+			conditions.MarkFalse(machineScope.PacketMachine, infrav1.DeviceReadyCondition, infrav1.DeviceNotOwnedReason, clusterv1.ConditionSeverityError, err.Error())
+			return ctrl.Result{RequeueAfter: deviceIdentityRecheckInterval}, nil
+		}
+		conditions.Delete(machineScope.PacketMachine, infrav1.DeviceIdentityMismatchCondition)
 	}
 
-	if dev == nil {
+	if dev == nil || conditions.Has(machineScope.PacketMachine, infrav1.DuplicateDevicesCondition) {
 		// We don't yet have a device ID, check to see if we've already
 		// created a device by using the tags that we assign to devices
-		// on creation.
-		dev, err = r.PacketClient.GetDeviceByTags(
-			ctx,
-			machineScope.PacketCluster.Spec.ProjectID,
-			packet.DefaultCreateTags(machineScope.Namespace(), machineScope.Machine.Name, machineScope.Cluster.Name),
-		)
+		// on creation. Devices keep being looked up by tags while
+		// duplicates are reported.
+		dev, err = r.reconcileDuplicateDevices(ctx, machineScope, dev)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if dev == nil && machineScope.PacketMachine.Spec.DeviceClaimName != "" {
+		// Machines with a PacketDeviceClaim use the device of the claim rather than creating one.
+		dev, err = r.claimedDevice(ctx, machineScope)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if dev == nil {
+			return ctrl.Result{RequeueAfter: deviceClaimWaitInterval}, nil
+		}
+	}
+
+	if dev == nil {
+		// Machines moved from another MachineDeployment take over the device released by their previous Machine.
+		dev, err = r.adoptReleasedDevice(ctx, machineScope)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if dev == nil {
+		// Devices created by packngo based releases carry tags the device is not found by anymore.
+		dev, err = r.adoptLegacyDevice(ctx, machineScope)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
+	// Addresses are claimed before the device is created, as they are passed to its bootstrap data.
+	ipAddresses, err := r.reconcileIPAddressClaims(ctx, machineScope)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if dev == nil && ipAddressesPending(machineScope, ipAddresses) {
+		log.Info("Waiting for the IP addresses of the machine to be allocated")
+		return ctrl.Result{}, nil
+	}
+
 	if dev == nil {
 		// We weren't able to find a device by either device ID or by tags,
 		// so we need to create a new device.
 
-		// Avoid a flickering condition between InstanceProvisionStarted and InstanceProvisionFailed if there's a persistent failure with createInstance
-		if conditions.GetReason(machineScope.PacketMachine, infrav1.DeviceReadyCondition) != infrav1.InstanceProvisionFailedReason {
+		if r.ReadOnly {
+			log.Info("Read-only mode, not creating device")
+			return ctrl.Result{RequeueAfter: readOnlyRequeueInterval}, nil
+		}
+
+		// Templated spec fields are resolved once, right before the device is created, and persisted by the patch below.
+		if err := machineScope.ResolveSpecTemplates(); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to resolve PacketMachine spec templates: %w", err)
+		}
+
+		// Avoid a flickering condition between InstanceProvisionStarted and InstanceProvisionFailed if there's a persistent failure with createInstance,
+		// or WaitingForHardwareReservation while the reservations are busy, or DeviceQuotaExceeded while the cluster is at its maxDevices
+		if reason := conditions.GetReason(machineScope.PacketMachine, infrav1.DeviceReadyCondition); reason != infrav1.InstanceProvisionFailedReason &&
+			reason != infrav1.WaitingForHardwareReservationReason && reason != infrav1.DeviceQuotaExceededReason {
 			conditions.MarkFalse(machineScope.PacketMachine, infrav1.DeviceReadyCondition, infrav1.InstanceProvisionStartedReason, clusterv1.ConditionSeverityInfo, "")
 			if patchErr := machineScope.PatchObject(ctx); patchErr != nil {
 				log.Error(patchErr, "failed to patch conditions")
@@ -345,6 +494,7 @@ func (r *PacketMachineReconciler) reconcile(ctx context.Context, machineScope *s
 		createDeviceReq := packet.CreateDeviceRequest{
 			MachineScope: machineScope,
 			ExtraTags:    packet.DefaultCreateTags(machineScope.Namespace(), machineScope.Machine.Name, machineScope.Cluster.Name),
+			IPAddresses:  ipAddresses,
 		}
 
 		// when a node is a control plane node we need the elastic IP
@@ -355,7 +505,7 @@ func (r *PacketMachineReconciler) reconcile(ctx context.Context, machineScope *s
 			var emlbID string
 			switch machineScope.PacketCluster.Spec.VIPManager {
 			case infrav1.CPEMID, infrav1.KUBEVIPID:
-				controlPlaneEndpoint, _ = r.PacketClient.GetIPByClusterIdentifier(
+				controlPlaneEndpoint, _ = r.metalClient(ctx).GetIPByClusterIdentifier(
 					ctx,
 					machineScope.Cluster.Namespace,
 					machineScope.Cluster.Name,
@@ -371,90 +521,244 @@ func (r *PacketMachineReconciler) reconcile(ctx context.Context, machineScope *s
 			case infrav1.EMLBVIPID:
 				controlPlaneEndpointAddress = machineScope.Cluster.Spec.ControlPlaneEndpoint.Host
 				cpemLBConfig = "emlb:///" + machineScope.PacketCluster.Spec.Metro
-				emlbID = machineScope.PacketCluster.Annotations["equinix.com/loadbalancerID"]
+				emlbID = emlb.LoadBalancerID(machineScope.PacketCluster)
+			case infrav1.NONEVIPID:
+				controlPlaneEndpointAddress = machineScope.Cluster.Spec.ControlPlaneEndpoint.Host
 			}
 			createDeviceReq.ControlPlaneEndpoint = controlPlaneEndpointAddress
 			createDeviceReq.CPEMLBConfig = cpemLBConfig
 			createDeviceReq.EMLBID = emlbID
 		}
-		dev, err = r.PacketClient.NewDevice(ctx, createDeviceReq)
+
+		reserved, releaseDeviceQuota, err := reserveDevices(ctx, r.metalClient(ctx), machineScope.Cluster, machineScope.PacketCluster, 1)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to check the device quota of the cluster: %w", err)
+		}
+		if reserved == 0 {
+			releaseDeviceQuota()
+			return r.waitForDeviceQuota(ctx, machineScope), nil
+		}
+
+		dev, err = r.metalClient(ctx).NewDevice(ctx, createDeviceReq)
+		releaseDeviceQuota()
+		batched := errors.Is(err, packet.ErrDeviceBatchPending)
+		var unavailable *packet.ReservationsUnavailableError
 
 		switch {
-		// TODO: find a better way than parsing the error messages for this.
-		case err != nil && strings.Contains(err.Error(), " no available hardware reservations "):
+		case errors.Is(err, readonly.ErrReadOnly):
+			// The creation was rejected in read-only mode, which is no failure of the machine
+			log.Info("Read-only mode, device not created", "reason", err.Error())
+			return ctrl.Result{RequeueAfter: readOnlyRequeueInterval}, nil
+		case errors.As(err, &unavailable):
+			// Retry once the busy reservations may have become available, rather than going through them again right away
+			return r.waitForHardwareReservation(ctx, machineScope, unavailable)
+		case packet.IsReservationBusy(err):
 			// Do not treat an error indicating there are no hardware reservations available as fatal
-			return ctrl.Result{}, fmt.Errorf("failed to create machine %s: %w", machineScope.Name(), err)
-		case err != nil && strings.Contains(err.Error(), "Server is not provisionable"):
-			// Do not treat an error indicating that reserved hardware is not provisionable as fatal
 			// This occurs when reserved hardware is in the process of being deprovisioned
 			return ctrl.Result{}, fmt.Errorf("failed to create machine %s: %w", machineScope.Name(), err)
-		case err != nil && strings.Contains(err.Error(), " unexpected EOF"):
+		case packet.IsLocationUnavailable(err):
+			// Retrying does not help until the machine is moved to another location, which is no reason to fail it
+			where := machineLocation(machineScope)
+			conditions.MarkFalse(machineScope.PacketMachine, infrav1.DeviceReadyCondition, infrav1.LocationNotAvailableReason, clusterv1.ConditionSeverityError,
+				"The %s is no longer available", where)
+			return markLocationRetired(machineScope.PacketMachine, where, err), nil
+		case packet.IsQuotaExceeded(err):
+			// Wait for the limits of the project to be raised or for devices to be removed
+			return r.waitForProjectQuota(ctx, machineScope, err), nil
+		case packet.IsResponseLost(err):
 			// Do not treat unexpected EOF as fatal, provisioning likely is proceeding
+		case packet.IsRetryable(err):
+			// Do not treat rate limiting, running out of API call budget or server errors as fatal
+			return ctrl.Result{}, fmt.Errorf("failed to create machine %s: %w", machineScope.Name(), err)
+		case errors.Is(err, packet.ErrDeviceBatchPending):
+			// The device was created as part of a batch and is found by its tags once the batch is processed
+		case errors.Is(err, packet.ErrBootstrapDataTooLarge):
+			// Retrying does not help until the bootstrap configuration of the machine is made smaller
+			errs := fmt.Errorf("failed to create machine %s: %w", machineScope.Name(), err)
+			machineScope.SetFailureReason(capierrors.CreateMachineError)
+			machineScope.SetFailureMessage(errs)
+			conditions.MarkFalse(machineScope.PacketMachine, infrav1.DeviceReadyCondition, infrav1.BootstrapDataTooLargeReason, clusterv1.ConditionSeverityError, err.Error())
+			record.Warnf(machineScope.PacketMachine, "BootstrapDataTooLarge", "%s", err)
+
+			return ctrl.Result{}, nil
 		case err != nil:
 			errs := fmt.Errorf("failed to create machine %s: %w", machineScope.Name(), err)
 			machineScope.SetFailureReason(capierrors.CreateMachineError)
 			machineScope.SetFailureMessage(errs)
 			conditions.MarkFalse(machineScope.PacketMachine, infrav1.DeviceReadyCondition, infrav1.InstanceProvisionFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			r.collectBootDiagnostics(ctx, machineScope, nil, errs.Error())
 
 			return ctrl.Result{}, errs
 		}
+		conditions.Delete(machineScope.PacketMachine, infrav1.LocationRetiredCondition)
+
+		// NewDevice rendered the userdata from the bootstrap data cached in the scope, so this is what the device got.
+		bootstrapDataHash, err := machineScope.GetBootstrapDataHash(ctx)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		machineScope.SetBootstrapDataHash(bootstrapDataHash)
+		conditions.MarkTrue(machineScope.PacketMachine, infrav1.BootstrapDataUpToDateCondition)
+
+		if dev != nil {
+			devicesCreatedTotal.MustCurryWith(machineScope.MetricLabels()).WithLabelValues("packetmachine").Inc()
+			r.recordAudit(ctx, machineScope, audit.DeviceCreated, dev.GetId(), "Created device %s", dev.GetHostname())
+		}
+		if dev == nil && batched {
+			log.Info("Device creation batched, waiting for the device to be created")
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+	} else if err := r.reconcileBootstrapData(ctx, machineScope); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if r.PlatformLabels {
+		setPlatformLabels(machineScope.PacketMachine)
 	}
 
-	// we do not need to set this as equinixmetal://<id> because SetProviderID() does the formatting for us
-	machineScope.SetProviderID(dev.GetId())
+	r.reconcileProviderID(ctx, machineScope, dev.GetId())
 	machineScope.SetInstanceStatus(infrav1.PacketResourceStatus(dev.GetState()))
 
 	if machineScope.PacketCluster.Spec.VIPManager == infrav1.KUBEVIPID {
-		if err := r.PacketClient.EnsureNodeBGPEnabled(ctx, dev.GetId()); err != nil {
+		if err := r.metalClient(ctx).EnsureNodeBGPEnabled(ctx, dev.GetId()); err != nil {
 			// Do not treat an error enabling bgp on machine as fatal
 			return ctrl.Result{RequeueAfter: time.Second * 20}, fmt.Errorf("failed to enable bgp on machine %s: %w", machineScope.Name(), err)
 		}
 	}
 
-	deviceAddr := r.PacketClient.GetDeviceAddresses(dev)
+	deviceAddr := r.metalClient(ctx).GetDeviceAddresses(dev)
+	addrs = append(addrs, ipAddressesToNodeAddresses(ipAddresses)...)
 	machineScope.SetAddresses(append(addrs, deviceAddr...))
+	machineScope.SetPublicIPv4Block(packet.DevicePublicIPv4Block(dev))
+	if hardware := packet.DeviceHardware(dev); hardware != nil {
+		machineScope.SetHardware(hardware)
+	}
+	machineScope.SetHardwareReservationID(packet.DeviceHardwareReservationID(dev))
+	machineScope.SetProvisioningProgress(packet.DeviceProvisioningPercentage(dev), packet.DeviceLastEvent(dev))
+
+	if hibernating, result, err := r.reconcileHibernation(ctx, machineScope, dev); hibernating || err != nil {
+		return result, err
+	}
+
+	if r.reconcileSpotReclaim(ctx, machineScope, dev) {
+		return ctrl.Result{}, nil
+	}
 
 	// Proceed to reconcile the PacketMachine state.
 	var result reconcile.Result
 
 	switch infrav1.PacketResourceStatus(dev.GetState()) {
 	case infrav1.PacketResourceStatusNew, infrav1.PacketResourceStatusQueued, infrav1.PacketResourceStatusProvisioning:
-		log.Info("Machine instance is pending", "instance-id", machineScope.ProviderID())
+		log.Info("Machine instance is pending", "instance-id", machineScope.ProviderID(), "progress", dev.GetProvisioningPercentage())
 		machineScope.SetNotReady()
-		result = ctrl.Result{RequeueAfter: 10 * time.Second}
+		result = ctrl.Result{RequeueAfter: devicePollInterval(dev, r.DevicePollMaxInterval)}
 	case infrav1.PacketResourceStatusRunning:
 		log.Info("Machine instance is active", "instance-id", machineScope.ProviderID())
 
 		switch {
 		case machineScope.PacketCluster.Spec.VIPManager == infrav1.CPEMID:
-			controlPlaneEndpoint, _ = r.PacketClient.GetIPByClusterIdentifier(
+			controlPlaneEndpoint, _ = r.metalClient(ctx).GetIPByClusterIdentifier(
 				ctx,
 				machineScope.Cluster.Namespace,
 				machineScope.Cluster.Name,
 				machineScope.PacketCluster.Spec.ProjectID)
-			if len(controlPlaneEndpoint.Assignments) == 0 && machineScope.IsControlPlane() {
-				apiRequest := r.PacketClient.DevicesApi.CreateIPAssignment(ctx, *dev.Id).IPAssignmentInput(metal.IPAssignmentInput{
-					Address: controlPlaneEndpoint.GetAddress(),
-				})
-				if _, _, err := apiRequest.Execute(); err != nil { //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+			if machineScope.IsControlPlane() {
+				if err := r.assignControlPlaneEIP(ctx, machineScope, dev, controlPlaneEndpoint); err != nil {
 					log.Error(err, "err assigining elastic ip to control plane. retrying...")
 					return ctrl.Result{RequeueAfter: time.Second * 20}, nil
 				}
 			}
 		case machineScope.PacketCluster.Spec.VIPManager == infrav1.EMLBVIPID:
-			if machineScope.IsControlPlane() {
-				// Create new EMLB object
-				lb := emlb.NewEMLB(r.PacketClient.GetConfig().DefaultHeader["X-Auth-Token"], machineScope.PacketCluster.Spec.ProjectID, machineScope.PacketCluster.Spec.Metro)
+			emlb.MigrateMachineAnnotations(machineScope.PacketMachine)
 
+			// Create new EMLB object
+			lb := emlb.NewEMLB(r.metalClient(ctx).GetConfig().DefaultHeader["X-Auth-Token"], machineScope.PacketCluster.Spec.ProjectID, machineScope.PacketCluster.Spec.Metro)
+
+			if machineScope.IsControlPlane() {
 				if err := lb.ReconcileVIPOrigin(ctx, machineScope, deviceAddr); err != nil {
 					return ctrl.Result{}, err
 				}
 			}
+
+			if err := lb.ReconcileLoadBalancerPoolOrigins(ctx, machineScope, deviceAddr); err != nil {
+				return ctrl.Result{}, err
+			}
 		}
 
+		// The device is seen active for the first time, unless the machine was ready before, e.g. before a hibernation.
+		if !machineScope.PacketMachine.Status.Ready && machineScope.Machine.Status.NodeRef == nil && dev.CreatedAt != nil {
+			deviceProvisioningDuration.MustCurryWith(machineScope.MetricLabels()).WithLabelValues(machineScope.PacketMachine.Spec.MachineType).Observe(time.Since(*dev.CreatedAt).Seconds())
+		}
 		machineScope.SetReady()
 		conditions.MarkTrue(machineScope.PacketMachine, infrav1.DeviceReadyCondition)
 
+		if r.BootstrapTimeout > 0 && machineScope.Machine.Status.NodeRef == nil && dev.CreatedAt != nil && time.Since(*dev.CreatedAt) > r.BootstrapTimeout {
+			r.collectBootDiagnostics(ctx, machineScope, dev, fmt.Sprintf("no Node registered %s after the device creation", r.BootstrapTimeout))
+		}
+
+		result = ctrl.Result{}
+		if machineScope.PacketCluster.Spec.VIPManager == infrav1.KUBEVIPID {
+			if result, err = r.reconcileBGPSessions(ctx, machineScope, dev); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if machineScope.PacketCluster.Spec.BGPPeerAnnotations {
+			if err := r.reconcileBGPPeerAnnotations(ctx, machineScope, dev); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if err := r.reconcileRootPassword(ctx, machineScope, dev); err != nil {
+			return ctrl.Result{}, err
+		}
+		if r.HostnameReconciliation {
+			if err := r.reconcileHostname(ctx, machineScope, dev); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if r.BondRemediation {
+			bondResult, err := r.reconcileNetworkBond(ctx, machineScope, dev)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			result = util.LowestNonZeroResult(result, bondResult)
+		}
+		vlanResult, err := r.reconcileVLANs(ctx, machineScope, dev)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		result = util.LowestNonZeroResult(result, vlanResult)
+		maintenanceResult, err := r.reconcileMaintenance(ctx, machineScope, dev)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		result = util.LowestNonZeroResult(result, maintenanceResult)
+	case infrav1.PacketResourceStatusFailed:
+		machineScope.SetNotReady()
+		if r.ReadOnly {
+			// Recreating the device, or failing the machine, is left to the active manager
+			log.Info("Read-only mode, not handling failed device", "device-id", machineScope.ProviderID())
+			return ctrl.Result{RequeueAfter: readOnlyRequeueInterval}, nil
+		}
+		if recreate, err := r.recreateFailedDevice(ctx, machineScope, dev); recreate || err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("Equinix Metal device failed to provision", "device-id", machineScope.ProviderID(), "retries", machineScope.PacketMachine.Status.DeviceRetries)
+		// The failure is only recorded once, as the machine is reconciled again until it is deleted.
+		if machineScope.PacketMachine.Status.FailureReason == nil {
+			r.recordAudit(ctx, machineScope, audit.DeviceProvisioningFailed, dev.GetId(), "Device %s failed to provision after %d retries",
+				dev.GetHostname(), machineScope.PacketMachine.Status.DeviceRetries)
+		}
+		machineScope.SetFailureReason(capierrors.CreateMachineError)
+		machineScope.SetFailureMessage(fmt.Errorf("device failed to provision after %d retries", machineScope.PacketMachine.Status.DeviceRetries)) //nolint:goerr113
+		if event := machineScope.PacketMachine.Status.LastEvent; event != nil {
+			conditions.MarkFalse(machineScope.PacketMachine, infrav1.DeviceReadyCondition, infrav1.InstanceProvisionFailedReason, clusterv1.ConditionSeverityError,
+				"device %s failed to provision, last event: %s", dev.GetId(), event.Message)
+		} else {
+			conditions.MarkFalse(machineScope.PacketMachine, infrav1.DeviceReadyCondition, infrav1.InstanceProvisionFailedReason, clusterv1.ConditionSeverityError,
+				"device %s failed to provision", dev.GetId())
+		}
+		r.collectBootDiagnostics(ctx, machineScope, dev, "device failed to provision")
+
 		result = ctrl.Result{}
 	default:
 		machineScope.SetNotReady()
@@ -462,57 +766,235 @@ func (r *PacketMachineReconciler) reconcile(ctx context.Context, machineScope *s
 		machineScope.SetFailureReason(capierrors.UpdateMachineError)
 		machineScope.SetFailureMessage(fmt.Errorf("instance status %q is unexpected", dev.GetState())) //nolint:goerr113
 		conditions.MarkUnknown(machineScope.PacketMachine, infrav1.DeviceReadyCondition, "", "")
+		r.collectBootDiagnostics(ctx, machineScope, dev, fmt.Sprintf("device is in unexpected state %q", dev.GetState()))
 
 		result = ctrl.Result{}
 	}
 
 	// If Metro or Facility has changed in the spec, verify that the facility's metro is compatible with the requested spec change.
-	deviceFacility := dev.Facility.Code
-	deviceMetro := dev.Metro.Code
+	if err := checkDeviceLocation(machineScope.PacketMachine.Spec, dev); err != nil {
+		conditions.MarkFalse(machineScope.PacketMachine, infrav1.DeviceReadyCondition, infrav1.InstanceLocationMismatchReason, clusterv1.ConditionSeverityWarning, err.Error())
+		record.Warnf(machineScope.PacketMachine, "DeviceLocationMismatch", "%s", err)
+		return ctrl.Result{}, err
+	}
 
-	if machineScope.PacketMachine.Spec.Facility != "" && machineScope.PacketMachine.Spec.Facility != *deviceFacility {
-		return ctrl.Result{}, fmt.Errorf("%w: %s != %s", errFacilityMatch, machineScope.PacketMachine.Spec.Facility, *deviceFacility)
+	return result, nil
+}
+
+// checkDeviceLocation returns an error when the facility or metro of the PacketMachine spec, if set, does not match
+// the one of its device.
+func checkDeviceLocation(spec infrav1.PacketMachineSpec, dev *metal.Device) error {
+	facility := dev.GetFacility()
+	if spec.Facility != "" && spec.Facility != facility.GetCode() {
+		return fmt.Errorf("%w: spec.facility %q != device facility %q", errFacilityMatch, spec.Facility, facility.GetCode())
 	}
 
-	if machineScope.PacketMachine.Spec.Metro != "" && machineScope.PacketMachine.Spec.Metro != *deviceMetro {
-		return ctrl.Result{}, fmt.Errorf("%w: %s != %s", errMetroMatch, machineScope.PacketMachine.Spec.Facility, *deviceMetro)
+	metro := dev.GetMetro()
+	if spec.Metro != "" && spec.Metro != metro.GetCode() {
+		return fmt.Errorf("%w: spec.metro %q != device metro %q", errMetroMatch, spec.Metro, metro.GetCode())
 	}
 
-	return result, nil
+	return nil
 }
 
-func (r *PacketMachineReconciler) reconcileDelete(ctx context.Context, machineScope *scope.MachineScope) error {
+// reconcileHibernation powers worker devices off while their cluster is hibernated and back on afterwards.
+// It reports whether the device is hibernated, or waking up, in which case the rest of the reconcile is skipped.
+func (r *PacketMachineReconciler) reconcileHibernation(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) (bool, ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	packetMachine := machineScope.PacketMachine
+	state := infrav1.PacketResourceStatus(dev.GetState())
+
+	if machineScope.PacketCluster.Spec.Hibernate && !machineScope.IsControlPlane() {
+		// Powered off Nodes go NotReady, keep MachineHealthChecks from replacing them. Remember whether the
+		// annotation was ours so that one set by the user survives the hibernation.
+		if !isHibernated(packetMachine) {
+			addedSkipRemediation := !annotations.HasSkipRemediation(machineScope.Machine)
+			annotations.AddAnnotations(packetMachine, map[string]string{infrav1.HibernatedAnnotation: strconv.FormatBool(addedSkipRemediation)})
+		}
+		if err := r.setMachineSkipRemediation(ctx, machineScope.Machine, true); err != nil {
+			return true, ctrl.Result{}, err
+		}
+
+		conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.InstanceHibernatedReason, clusterv1.ConditionSeverityInfo,
+			"Device is powered off while the cluster is hibernated")
+
+		switch state {
+		case infrav1.PacketResourceStatusRunning:
+			log.Info("Powering off device for cluster hibernation", "device-id", dev.GetId())
+			if err := r.metalClient(ctx).PowerOffDevice(ctx, dev.GetId()); err != nil {
+				return true, ctrl.Result{}, fmt.Errorf("failed to power off device %s: %w", dev.GetId(), err)
+			}
+			record.Eventf(packetMachine, "Hibernating", "Powering off device %s while the cluster is hibernated", dev.GetId())
+			return true, ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		case infrav1.PacketResourceStatusInactive:
+			return true, ctrl.Result{}, nil
+		default:
+			return true, ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+	}
+
+	if !isHibernated(packetMachine) {
+		return false, ctrl.Result{}, nil
+	}
+
+	switch state {
+	case infrav1.PacketResourceStatusInactive:
+		log.Info("Powering on device after cluster hibernation", "device-id", dev.GetId())
+		if err := r.metalClient(ctx).PowerOnDevice(ctx, dev.GetId()); err != nil {
+			return true, ctrl.Result{}, fmt.Errorf("failed to power on device %s: %w", dev.GetId(), err)
+		}
+		record.Eventf(packetMachine, "Resuming", "Powering on device %s after the cluster hibernation", dev.GetId())
+		conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.InstanceHibernatedReason, clusterv1.ConditionSeverityInfo,
+			"Device is powering on after the cluster hibernation")
+		return true, ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	case infrav1.PacketResourceStatusPoweringOff, infrav1.PacketResourceStatusPoweringOn:
+		return true, ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// The device is back up, let MachineHealthChecks look after it again.
+	if packetMachine.Annotations[infrav1.HibernatedAnnotation] == "true" {
+		if err := r.setMachineSkipRemediation(ctx, machineScope.Machine, false); err != nil {
+			return true, ctrl.Result{}, err
+		}
+	}
+	delete(packetMachine.Annotations, infrav1.HibernatedAnnotation)
+	return false, ctrl.Result{}, nil
+}
+
+func isHibernated(packetMachine *infrav1.PacketMachine) bool {
+	_, ok := packetMachine.GetAnnotations()[infrav1.HibernatedAnnotation]
+	return ok
+}
+
+// setMachineSkipRemediation adds or removes the Cluster API annotation that excludes a Machine from remediation.
+func (r *PacketMachineReconciler) setMachineSkipRemediation(ctx context.Context, machine *clusterv1.Machine, skip bool) error {
+	if annotations.HasSkipRemediation(machine) == skip {
+		return nil
+	}
+
+	base := machine.DeepCopy()
+	if skip {
+		annotations.AddAnnotations(machine, map[string]string{clusterv1.MachineSkipRemediationAnnotation: ""})
+	} else {
+		delete(machine.Annotations, clusterv1.MachineSkipRemediationAnnotation)
+	}
+	if err := r.Client.Patch(ctx, machine, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to patch Machine %s: %w", machine.Name, err)
+	}
+	return nil
+}
+
+// reconcileProviderID records the device ID as the PacketMachine providerID. PacketMachines created by older
+// releases of the provider may still carry a legacy packet:// providerID. Those are migrated in place as long
+// as no Node has registered yet; once a Node exists its providerID is immutable and Cluster API matches Nodes
+// by it, so the legacy value is kept and the user is asked to recreate the Node instead.
+//
+// When the manager generates packet:// providerIDs for compatibility with downstream tooling, the existing
+// equinixmetal:// providerIDs are kept as they are instead, for the same reason.
+func (r *PacketMachineReconciler) reconcileProviderID(ctx context.Context, machineScope *scope.MachineScope, deviceID string) {
+	log := ctrl.LoggerFrom(ctx)
+
+	prefix := r.ProviderIDPrefix
+	if prefix == "" {
+		prefix = scope.ProviderIDPrefix
+	}
+
+	providerID := machineScope.ProviderID()
+	if providerID == "" || strings.HasPrefix(providerID, prefix) {
+		// we do not need to set this as <prefix><id> because SetProviderIDWithPrefix() does the formatting for us
+		machineScope.SetProviderIDWithPrefix(prefix, deviceID)
+		return
+	}
+	if !machineScope.HasLegacyProviderID() {
+		return
+	}
+
+	legacyProviderID := machineScope.ProviderID()
+	nodeRef := machineScope.Machine.Status.NodeRef
+
+	if nodeRef != nil {
+		log.Info("PacketMachine uses a legacy providerID which cannot be migrated automatically", "providerID", legacyProviderID, "node", nodeRef.Name)
+		conditions.MarkFalse(machineScope.PacketMachine, infrav1.ProviderIDMigratedCondition, infrav1.LegacyProviderIDReason, clusterv1.ConditionSeverityWarning,
+			"Node %s is registered with legacy providerID %s", nodeRef.Name, legacyProviderID)
+		record.Warnf(machineScope.PacketMachine, infrav1.LegacyProviderIDReason,
+			"Node %s is registered with legacy providerID %s; the Node must be deleted and re-registered to use the %s prefix", nodeRef.Name, legacyProviderID, scope.ProviderIDPrefix)
+		return
+	}
+
+	machineScope.SetProviderID(deviceID)
+	log.Info("Migrated legacy providerID", "from", legacyProviderID, "to", machineScope.ProviderID())
+	conditions.MarkTrue(machineScope.PacketMachine, infrav1.ProviderIDMigratedCondition)
+	record.Eventf(machineScope.PacketMachine, "ProviderIDMigrated", "Migrated providerID from %s to %s", legacyProviderID, machineScope.ProviderID())
+}
+
+// reconcileBootstrapData detects bootstrap data that changed after the device was created, e.g. because of a CA rotation,
+// while the device may still be bootstrapping with the stale copy.
+func (r *PacketMachineReconciler) reconcileBootstrapData(ctx context.Context, machineScope *scope.MachineScope) error {
+	createdWith := machineScope.PacketMachine.Status.BootstrapDataHash
+
+	// Devices created before the hash was recorded can't be checked, and once the Node has joined
+	// the bootstrap data has been consumed successfully.
+	if createdWith == "" || machineScope.Machine.Status.NodeRef != nil {
+		return nil
+	}
+
+	current, err := machineScope.GetBootstrapDataHash(ctx)
+	if err != nil {
+		return err
+	}
+
+	if current == createdWith {
+		conditions.MarkTrue(machineScope.PacketMachine, infrav1.BootstrapDataUpToDateCondition)
+		return nil
+	}
+
+	if !conditions.IsFalse(machineScope.PacketMachine, infrav1.BootstrapDataUpToDateCondition) {
+		ctrl.LoggerFrom(ctx).Info("Bootstrap data changed after the device was created")
+		record.Warnf(machineScope.PacketMachine, infrav1.BootstrapDataChangedReason,
+			"Bootstrap data changed after device %s was created; if the Node does not join, delete Machine %s so it is replaced with a device using the current data",
+			machineScope.GetDeviceID(), machineScope.Machine.Name)
+	}
+	conditions.MarkFalse(machineScope.PacketMachine, infrav1.BootstrapDataUpToDateCondition, infrav1.BootstrapDataChangedReason, clusterv1.ConditionSeverityWarning,
+		"device was created with stale bootstrap data")
+	return nil
+}
+
+func (r *PacketMachineReconciler) reconcileDelete(ctx context.Context, machineScope *scope.MachineScope) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx, "machine", machineScope.Machine.Name, "cluster", machineScope.Cluster.Name)
 	log.Info("Reconciling Delete PacketMachine")
 
+	if r.ReadOnly {
+		// The active manager deletes the device and removes the finalizer
+		log.Info("Read-only mode, not deleting device")
+		return ctrl.Result{RequeueAfter: readOnlyRequeueInterval}, nil
+	}
+
 	packetmachine := machineScope.PacketMachine
 	deviceID := machineScope.GetDeviceID()
 
 	var device *metal.Device
+	var duplicates []string
 
 	if deviceID == "" {
 		// If no device ID was recorded, check to see if there are any instances
 		// that match by tags
-		dev, err := r.PacketClient.GetDeviceByTags(
-			ctx,
-			machineScope.PacketCluster.Spec.ProjectID,
-			packet.DefaultCreateTags(machineScope.Namespace(), machineScope.Machine.Name, machineScope.Cluster.Name),
-		)
+		dev, tagged, err := r.findDeviceByTags(ctx, machineScope, nil)
 		if err != nil {
-			return err
+			return ctrl.Result{}, err
 		}
+		duplicates = tagged
 
 		if dev == nil {
 			log.Info("Server not found by tags, nothing left to do")
 			controllerutil.RemoveFinalizer(packetmachine, infrav1.MachineFinalizer)
-			return nil
+			return ctrl.Result{}, nil
 		}
 
 		device = dev
 	} else {
 		var resp *http.Response
 		// Otherwise, try to retrieve the device by the providerID
-		dev, resp, err := r.PacketClient.GetDevice(ctx, deviceID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		dev, resp, err := r.metalClient(ctx).GetDevice(ctx, deviceID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
 		if err != nil {
 			if resp != nil {
 				if resp.StatusCode == http.StatusNotFound {
@@ -520,45 +1002,219 @@ func (r *PacketMachineReconciler) reconcileDelete(ctx context.Context, machineSc
 					// Probably somebody manually deleted the server from the UI or via API.
 					log.Info("Server not found by id, nothing left to do")
 					controllerutil.RemoveFinalizer(packetmachine, infrav1.MachineFinalizer)
-					return nil
+					return ctrl.Result{}, nil
 				}
 
 				if resp.StatusCode == http.StatusForbidden {
 					// When a server fails to provision it will return a 403
 					log.Info("Server appears to have failed provisioning, nothing left to do")
 					controllerutil.RemoveFinalizer(packetmachine, infrav1.MachineFinalizer)
-					return nil
+					return ctrl.Result{}, nil
 				}
 			}
 
-			return fmt.Errorf("error retrieving machine status %s: %w", packetmachine.Name, err)
+			return ctrl.Result{}, fmt.Errorf("error retrieving machine status %s: %w", packetmachine.Name, err)
 		}
 
 		device = dev
+
+		if err := verifyDeviceIdentity(machineScope, device); err != nil {
+			// The device of the providerID is left alone, the device of the machine, if any, is found by its tags.
+			log.Error(err, "refusing to delete device", "device-id", deviceID)
+			reportDeviceIdentityMismatch(machineScope, err)
+			dev, _, err := r.findDeviceByTags(ctx, machineScope, nil)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if dev == nil {
+				log.Info("Server not found by tags, nothing left to do")
+				controllerutil.RemoveFinalizer(packetmachine, infrav1.MachineFinalizer)
+				return ctrl.Result{}, nil
+			}
+			device = dev
+		} else if conditions.Has(packetmachine, infrav1.DuplicateDevicesCondition) {
+			if _, duplicates, err = r.findDeviceByTags(ctx, machineScope, device); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 	}
 
 	// We should never get there but this is a safety check
 	if device == nil {
 		controllerutil.RemoveFinalizer(packetmachine, infrav1.MachineFinalizer)
-		return fmt.Errorf("%w: %s", errMissingDevice, packetmachine.Name)
+		return ctrl.Result{}, fmt.Errorf("%w: %s", errMissingDevice, packetmachine.Name)
+	}
+
+	// Duplicate devices were created for this machine only, so they go away with it.
+	if err := r.deleteDuplicateDevices(ctx, machineScope, duplicates); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch device.GetState() {
+	case metal.DEVICESTATE_DELETED:
+		log.Info("Server deleted, nothing left to do")
+		controllerutil.RemoveFinalizer(packetmachine, infrav1.MachineFinalizer)
+		return ctrl.Result{}, nil
+	case metal.DEVICESTATE_DEPROVISIONING:
+		// The device was already deleted, only its deprovisioning is left to wait for.
+		return r.waitForDeprovision(ctx, machineScope), nil
+	}
+
+	if result, waiting := waitForDrain(ctx, machineScope, time.Now()); waiting {
+		return result, nil
 	}
 
 	if machineScope.PacketCluster.Spec.VIPManager == infrav1.EMLBVIPID {
-		if machineScope.IsControlPlane() {
-			// Create new EMLB object
-			lb := emlb.NewEMLB(r.PacketClient.GetConfig().DefaultHeader["X-Auth-Token"], machineScope.PacketCluster.Spec.ProjectID, packetmachine.Spec.Metro)
+		emlb.MigrateMachineAnnotations(machineScope.PacketMachine)
 
+		// Create new EMLB object
+		lb := emlb.NewEMLB(r.metalClient(ctx).GetConfig().DefaultHeader["X-Auth-Token"], machineScope.PacketCluster.Spec.ProjectID, packetmachine.Spec.Metro)
+
+		if machineScope.IsControlPlane() {
 			if err := lb.DeleteLoadBalancerOrigin(ctx, machineScope); err != nil {
-				return fmt.Errorf("failed to delete load balancer origin: %w", err)
+				return ctrl.Result{}, fmt.Errorf("failed to delete load balancer origin: %w", err)
 			}
 		}
+
+		if err := lb.DeleteLoadBalancerPoolOrigins(ctx, machineScope); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to delete load balancer pool origins: %w", err)
+		}
 	}
 
-	apiRequest := r.PacketClient.DevicesApi.DeleteDevice(ctx, device.GetId()).ForceDelete(force)
+	// The device stops using its addresses on the VLANs before they are released, and they are released before the
+	// device is deleted.
+	if err := r.detachDeviceVLANs(ctx, machineScope, device); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.deleteIPAddressClaims(ctx, machineScope); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if _, ok := packet.DeviceClaimFromTags(device.Tags); ok {
+		return r.returnClaimedDevice(ctx, machineScope, device)
+	}
+
+	if _, ok := packetmachine.Annotations[infrav1.ReleaseDeviceAnnotation]; ok {
+		return r.releaseDevice(ctx, machineScope, device)
+	}
+
+	force := forceDeleteDevice(machineScope, time.Now())
+	apiRequest := r.metalClient(ctx).DevicesApi.DeleteDevice(ctx, device.GetId()).ForceDelete(force)
 	if _, err := apiRequest.Execute(); err != nil { //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
-		return fmt.Errorf("failed to delete the machine: %w", err)
+		// Graceful deletions are retried until accepted, or until they time out with ForceAfterTimeout.
+		return ctrl.Result{}, fmt.Errorf("failed to delete the machine (force: %t): %w", force, err)
 	}
+	devicesDeletedTotal.MustCurryWith(machineScope.MetricLabels()).WithLabelValues("packetmachine").Inc()
+	r.recordAudit(ctx, machineScope, audit.DeviceDeleted, device.GetId(), "Deleted device %s (force: %t)", device.GetHostname(), force)
 
-	controllerutil.RemoveFinalizer(packetmachine, infrav1.MachineFinalizer)
-	return nil
+	return r.waitForDeprovision(ctx, machineScope), nil
+}
+
+// waitForHardwareReservation requeues a PacketMachine whose hardware reservations are all busy, listing them with the
+// WaitingForHardwareReservation reason, until HardwareReservationTimeout is reached.
+func (r *PacketMachineReconciler) waitForHardwareReservation(ctx context.Context, machineScope *scope.MachineScope, unavailable *packet.ReservationsUnavailableError) (ctrl.Result, error) {
+	packetMachine := machineScope.PacketMachine
+
+	if r.HardwareReservationTimeout > 0 && time.Since(packetMachine.CreationTimestamp.Time) > r.HardwareReservationTimeout {
+		errs := fmt.Errorf("no hardware reservation became available within %s: %s", r.HardwareReservationTimeout, unavailable.Summary())
+		machineScope.SetFailureReason(capierrors.CreateMachineError)
+		machineScope.SetFailureMessage(errs)
+		conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.InstanceProvisionFailedReason, clusterv1.ConditionSeverityError, errs.Error())
+		return ctrl.Result{}, errs
+	}
+
+	ctrl.LoggerFrom(ctx).Info("Hardware reservations are busy, waiting", "retry-after", unavailable.RetryAfter, "reservations", unavailable.Summary())
+	conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.WaitingForHardwareReservationReason, clusterv1.ConditionSeverityInfo,
+		"%s", unavailable.Summary())
+	return ctrl.Result{RequeueAfter: unavailable.RetryAfter}, nil
+}
+
+// setPlatformLabels labels a PacketMachine with the architecture and OS family of its device, as derived from its
+// plan and operating system.
+func setPlatformLabels(packetMachine *infrav1.PacketMachine) {
+	if infrav1.IsTemplated(packetMachine.Spec.MachineType) {
+		return
+	}
+	if packetMachine.Labels == nil {
+		packetMachine.Labels = map[string]string{}
+	}
+	packetMachine.Labels[corev1.LabelArchStable] = packet.PlanArchitecture(packetMachine.Spec.MachineType)
+	packetMachine.Labels[corev1.LabelOSStable] = packet.OSFamily(packetMachine.Spec.OS)
+}
+
+// waitForDeviceQuota reports that the cluster of the machine has reached its maxDevices, retrying the device creation
+// later in case devices of the cluster were deleted.
+func (r *PacketMachineReconciler) waitForDeviceQuota(ctx context.Context, machineScope *scope.MachineScope) ctrl.Result {
+	log := ctrl.LoggerFrom(ctx)
+	packetMachine := machineScope.PacketMachine
+	maxDevices := *machineScope.PacketCluster.Spec.MaxDevices
+
+	log.Info("Cluster has reached its maximum number of devices, waiting to create the device", "maxDevices", maxDevices)
+	if conditions.GetReason(packetMachine, infrav1.DeviceReadyCondition) != infrav1.DeviceQuotaExceededReason {
+		record.Warnf(packetMachine, infrav1.DeviceQuotaExceededReason, "Cluster %s has reached its maximum of %d devices", machineScope.Cluster.Name, maxDevices)
+	}
+	conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.DeviceQuotaExceededReason, clusterv1.ConditionSeverityWarning,
+		"Cluster has reached its maximum of %d devices", maxDevices)
+	return ctrl.Result{RequeueAfter: deviceQuotaRequeue}
+}
+
+// waitForProjectQuota requeues a PacketMachine whose device could not be created because its project or
+// organization reached one of its Equinix Metal limits.
+func (r *PacketMachineReconciler) waitForProjectQuota(ctx context.Context, machineScope *scope.MachineScope, err error) ctrl.Result {
+	log := ctrl.LoggerFrom(ctx)
+	packetMachine := machineScope.PacketMachine
+
+	log.Info("Project has reached one of its limits, waiting to create the device", "error", err.Error())
+	if conditions.GetReason(packetMachine, infrav1.DeviceReadyCondition) != infrav1.DeviceQuotaExceededReason {
+		record.Warnf(packetMachine, infrav1.DeviceQuotaExceededReason, "Project %s has reached one of its limits: %s", machineScope.PacketCluster.Spec.ProjectID, err)
+	}
+	conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.DeviceQuotaExceededReason, clusterv1.ConditionSeverityWarning,
+		"Project has reached one of its limits: %s", err)
+	return ctrl.Result{RequeueAfter: deviceQuotaRequeue}
+}
+
+// waitForDeprovision removes the finalizer of a PacketMachine whose device was deleted, unless DeprovisionTimeout is
+// set and the device may still be deprovisioning, in which case the PacketMachine is requeued to check again.
+func (r *PacketMachineReconciler) waitForDeprovision(ctx context.Context, machineScope *scope.MachineScope) ctrl.Result {
+	log := ctrl.LoggerFrom(ctx)
+	packetmachine := machineScope.PacketMachine
+
+	if r.DeprovisionTimeout <= 0 {
+		controllerutil.RemoveFinalizer(packetmachine, infrav1.MachineFinalizer)
+		return ctrl.Result{}
+	}
+
+	if time.Since(packetmachine.GetDeletionTimestamp().Time) >= r.DeprovisionTimeout {
+		log.Info("Timed out waiting for the server to be deprovisioned, releasing the PacketMachine", "timeout", r.DeprovisionTimeout)
+		controllerutil.RemoveFinalizer(packetmachine, infrav1.MachineFinalizer)
+		return ctrl.Result{}
+	}
+
+	log.Info("Waiting for the server to be deprovisioned")
+	conditions.MarkFalse(packetmachine, infrav1.DeviceReadyCondition, infrav1.InstanceDeprovisioningReason, clusterv1.ConditionSeverityInfo, "")
+	return ctrl.Result{RequeueAfter: 30 * time.Second}
+}
+
+// forceDeleteDevice reports whether the device of the PacketMachine is to be force deleted, according to its delete
+// policy.
+func forceDeleteDevice(machineScope *scope.MachineScope, now time.Time) bool {
+	switch machineScope.DeletePolicy() {
+	case infrav1.DeletePolicyGraceful:
+		return false
+	case infrav1.DeletePolicyForceAfterTimeout:
+		deletionTimestamp := machineScope.PacketMachine.GetDeletionTimestamp()
+		return deletionTimestamp != nil && now.Sub(deletionTimestamp.Time) >= machineScope.DeletionTimeout()
+	default:
+		return true
+	}
+}
+
+// recordAudit records a change made to the infrastructure of the cluster of a machine.
+func (r *PacketMachineReconciler) recordAudit(ctx context.Context, machineScope *scope.MachineScope, action audit.Action, resource, format string, args ...interface{}) {
+	r.Audit.Record(ctx, util.ObjectKey(machineScope.Cluster), action, "PacketMachine/"+machineScope.Name(), resource, format, args...)
+}
+
+// metalClient returns the Equinix Metal client of the cluster being reconciled, see packet.ClientForCluster.
+func (r *PacketMachineReconciler) metalClient(ctx context.Context) *packet.Client {
+	return packet.ClientFromContext(ctx, r.PacketClient)
 }
@@ -0,0 +1,445 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func TestCheckDeviceLocation(t *testing.T) {
+	dev := &metal.Device{
+		Facility: &metal.Facility{Code: ptr.To("da11")},
+		Metro:    &metal.DeviceMetro{Code: ptr.To("da")},
+	}
+
+	tests := []struct {
+		name    string
+		spec    infrav1.PacketMachineSpec
+		dev     *metal.Device
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name: "location inherited from the cluster",
+			dev:  dev,
+		},
+		{
+			name: "matching facility and metro",
+			spec: infrav1.PacketMachineSpec{Facility: "da11", Metro: "da"},
+			dev:  dev,
+		},
+		{
+			name:    "facility mismatch",
+			spec:    infrav1.PacketMachineSpec{Facility: "sv15"},
+			dev:     dev,
+			wantErr: errFacilityMatch,
+			wantMsg: `spec.facility "sv15" != device facility "da11"`,
+		},
+		{
+			name:    "metro mismatch",
+			spec:    infrav1.PacketMachineSpec{Metro: "sv"},
+			dev:     dev,
+			wantErr: errMetroMatch,
+			wantMsg: `spec.metro "sv" != device metro "da"`,
+		},
+		{
+			name:    "device without location",
+			spec:    infrav1.PacketMachineSpec{Metro: "sv"},
+			dev:     &metal.Device{},
+			wantErr: errMetroMatch,
+			wantMsg: `spec.metro "sv" != device metro ""`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := checkDeviceLocation(tt.spec, tt.dev)
+			if tt.wantErr == nil {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(tt.wantErr))
+			g.Expect(err.Error()).To(ContainSubstring(tt.wantMsg))
+		})
+	}
+}
+
+func TestSetPlatformLabels(t *testing.T) {
+	g := NewWithT(t)
+
+	packetMachine := &infrav1.PacketMachine{Spec: infrav1.PacketMachineSpec{MachineType: "c3.large.arm64", OS: "ubuntu_22_04"}}
+	setPlatformLabels(packetMachine)
+	g.Expect(packetMachine.Labels).To(Equal(map[string]string{
+		"kubernetes.io/arch": "arm64",
+		"kubernetes.io/os":   "linux",
+	}))
+
+	templated := &infrav1.PacketMachine{Spec: infrav1.PacketMachineSpec{MachineType: "{{ .variables.plan }}"}}
+	setPlatformLabels(templated)
+	g.Expect(templated.Labels).To(BeEmpty())
+}
+
+func TestBGPSessionStatuses(t *testing.T) {
+	g := NewWithT(t)
+
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	lost := metav1.NewTime(created.Add(time.Hour))
+	now := metav1.NewTime(created.Add(2 * time.Hour))
+
+	previous := []infrav1.BGPSession{
+		{AddressFamily: "ipv4", State: infrav1.BGPSessionDown, LastTransitionTime: lost},
+		{AddressFamily: "ipv6", State: infrav1.BGPSessionUp, LastTransitionTime: lost},
+	}
+	sessions := []metal.BgpSession{
+		{AddressFamily: metal.BGPSESSIONADDRESSFAMILY_IPV4, Status: ptr.To("unknown")},
+		{AddressFamily: metal.BGPSESSIONADDRESSFAMILY_IPV6, Status: ptr.To("up down")},
+	}
+
+	statuses := bgpSessionStatuses(previous, sessions, now)
+	g.Expect(statuses).To(Equal([]infrav1.BGPSession{
+		// Still not established, so it is still down since it was lost.
+		{AddressFamily: "ipv4", State: infrav1.BGPSessionUnknown, LastTransitionTime: lost},
+		{AddressFamily: "ipv6", State: infrav1.BGPSessionDown, LastTransitionTime: now},
+	}))
+
+	sessions = []metal.BgpSession{
+		{AddressFamily: metal.BGPSESSIONADDRESSFAMILY_IPV4, Status: ptr.To("up,up")},
+		{AddressFamily: metal.BGPSESSIONADDRESSFAMILY_IPV6, CreatedAt: &created},
+	}
+	statuses = bgpSessionStatuses(nil, sessions, now)
+	g.Expect(statuses).To(Equal([]infrav1.BGPSession{
+		{AddressFamily: "ipv4", State: infrav1.BGPSessionUp, LastTransitionTime: now},
+		{AddressFamily: "ipv6", State: infrav1.BGPSessionUnknown, LastTransitionTime: metav1.NewTime(created)},
+	}))
+}
+
+func TestReconcileProviderID(t *testing.T) {
+	tests := []struct {
+		name       string
+		prefix     string
+		providerID *string
+		nodeRef    *corev1.ObjectReference
+		want       string
+	}{
+		{
+			name: "new machine",
+			want: "equinixmetal://device",
+		},
+		{
+			name:       "legacy providerID migrated",
+			providerID: ptr.To("packet://device"),
+			want:       "equinixmetal://device",
+		},
+		{
+			name:       "legacy providerID of a registered Node kept",
+			providerID: ptr.To("packet://device"),
+			nodeRef:    &corev1.ObjectReference{Name: "node"},
+			want:       "packet://device",
+		},
+		{
+			name:   "new machine in compatibility mode",
+			prefix: scope.LegacyProviderIDPrefix,
+			want:   "packet://device",
+		},
+		{
+			name:       "existing providerID kept in compatibility mode",
+			prefix:     scope.LegacyProviderIDPrefix,
+			providerID: ptr.To("equinixmetal://device"),
+			want:       "equinixmetal://device",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &PacketMachineReconciler{ProviderIDPrefix: tt.prefix}
+			machineScope := &scope.MachineScope{
+				Machine:       &clusterv1.Machine{Status: clusterv1.MachineStatus{NodeRef: tt.nodeRef}},
+				PacketMachine: &infrav1.PacketMachine{Spec: infrav1.PacketMachineSpec{ProviderID: tt.providerID}},
+			}
+
+			r.reconcileProviderID(context.Background(), machineScope, "device")
+			g.Expect(machineScope.ProviderID()).To(Equal(tt.want))
+			g.Expect(machineScope.GetDeviceID()).To(Equal("device"))
+		})
+	}
+}
+
+func TestReconcileHibernation(t *testing.T) {
+	tests := []struct {
+		name         string
+		hibernate    bool
+		controlPlane bool
+		state        string
+		// hibernated is the value of the hibernated annotation of the PacketMachine, if any.
+		hibernated *string
+		// userSkip is whether the user excluded the Machine from remediation.
+		userSkip       bool
+		wantHandled    bool
+		wantRequests   []string
+		wantRequeue    bool
+		wantHibernated *string
+		wantSkip       bool
+	}{
+		{
+			name:  "not hibernated",
+			state: "active",
+		},
+		{
+			name:         "control plane devices keep running",
+			hibernate:    true,
+			controlPlane: true,
+			state:        "active",
+		},
+		{
+			name:           "running worker powered off",
+			hibernate:      true,
+			state:          "active",
+			wantHandled:    true,
+			wantRequests:   []string{"POST /devices/device/actions"},
+			wantRequeue:    true,
+			wantHibernated: ptr.To("true"),
+			wantSkip:       true,
+		},
+		{
+			name:           "powered off worker left alone",
+			hibernate:      true,
+			state:          "inactive",
+			hibernated:     ptr.To("true"),
+			wantHandled:    true,
+			wantHibernated: ptr.To("true"),
+			wantSkip:       true,
+		},
+		{
+			name:           "remediation excluded by the user is remembered",
+			hibernate:      true,
+			state:          "powering_off",
+			userSkip:       true,
+			wantHandled:    true,
+			wantRequeue:    true,
+			wantHibernated: ptr.To("false"),
+			wantSkip:       true,
+		},
+		{
+			name:           "powered off worker powered on after the hibernation",
+			state:          "inactive",
+			hibernated:     ptr.To("true"),
+			userSkip:       true,
+			wantHandled:    true,
+			wantRequests:   []string{"POST /devices/device/actions"},
+			wantRequeue:    true,
+			wantHibernated: ptr.To("true"),
+			wantSkip:       true,
+		},
+		{
+			name:       "running worker remediated again after the hibernation",
+			state:      "active",
+			hibernated: ptr.To("true"),
+			userSkip:   true,
+		},
+		{
+			name:       "remediation excluded by the user kept after the hibernation",
+			state:      "active",
+			hibernated: ptr.To("false"),
+			userSkip:   true,
+			wantSkip:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var requests []string
+			metalClient := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+
+			machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine", Labels: map[string]string{}, Annotations: map[string]string{}}}
+			if tt.controlPlane {
+				machine.Labels[clusterv1.MachineControlPlaneLabel] = ""
+			}
+			if tt.userSkip {
+				machine.Annotations[clusterv1.MachineSkipRemediationAnnotation] = ""
+			}
+			packetMachine := &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}}}
+			if tt.hibernated != nil {
+				packetMachine.Annotations[infrav1.HibernatedAnnotation] = *tt.hibernated
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scopetest.Scheme()).WithObjects(machine).Build()
+			r := &PacketMachineReconciler{Client: c, PacketClient: metalClient}
+			machineScope := &scope.MachineScope{
+				Machine:       machine,
+				PacketCluster: &infrav1.PacketCluster{Spec: infrav1.PacketClusterSpec{Hibernate: tt.hibernate}},
+				PacketMachine: packetMachine,
+			}
+
+			handled, result, err := r.reconcileHibernation(context.Background(), machineScope, &metal.Device{Id: ptr.To("device"), State: (*metal.DeviceState)(ptr.To(tt.state))})
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(handled).To(Equal(tt.wantHandled))
+			g.Expect(result.RequeueAfter > 0).To(Equal(tt.wantRequeue))
+			g.Expect(requests).To(Equal(tt.wantRequests))
+
+			if tt.wantHibernated != nil {
+				g.Expect(packetMachine.Annotations).To(HaveKeyWithValue(infrav1.HibernatedAnnotation, *tt.wantHibernated))
+			} else {
+				g.Expect(packetMachine.Annotations).ToNot(HaveKey(infrav1.HibernatedAnnotation))
+			}
+			g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+			_, skip := machine.Annotations[clusterv1.MachineSkipRemediationAnnotation]
+			g.Expect(skip).To(Equal(tt.wantSkip))
+		})
+	}
+}
+
+func TestWaitForDeprovision(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       time.Duration
+		deletedAgo    time.Duration
+		wantFinalizer bool
+		wantReason    string
+	}{
+		{
+			name:       "released right away by default",
+			deletedAgo: time.Minute,
+		},
+		{
+			name:          "kept while the device deprovisions",
+			timeout:       time.Hour,
+			deletedAgo:    time.Minute,
+			wantFinalizer: true,
+			wantReason:    infrav1.InstanceDeprovisioningReason,
+		},
+		{
+			name:       "released once the timeout elapsed",
+			timeout:    time.Hour,
+			deletedAgo: 2 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			deletionTimestamp := metav1.NewTime(time.Now().Add(-tt.deletedAgo))
+			packetMachine := &infrav1.PacketMachine{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deletionTimestamp, Finalizers: []string{infrav1.MachineFinalizer}},
+			}
+			r := &PacketMachineReconciler{DeprovisionTimeout: tt.timeout}
+
+			result := r.waitForDeprovision(context.Background(), &scope.MachineScope{PacketMachine: packetMachine})
+			g.Expect(controllerutil.ContainsFinalizer(packetMachine, infrav1.MachineFinalizer)).To(Equal(tt.wantFinalizer))
+			g.Expect(result.RequeueAfter > 0).To(Equal(tt.wantFinalizer))
+			g.Expect(conditions.GetReason(packetMachine, infrav1.DeviceReadyCondition)).To(Equal(tt.wantReason))
+		})
+	}
+}
+
+func TestReconcileReadOnly(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	newMachineScope := func() *scope.MachineScope {
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: scopetest.ClusterName, Namespace: scopetest.Namespace},
+			Status:     clusterv1.ClusterStatus{InfrastructureReady: true},
+		}
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      scopetest.MachineName,
+				Namespace: scopetest.Namespace,
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: scopetest.ClusterName},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: scopetest.ClusterName,
+				Bootstrap:   clusterv1.Bootstrap{DataSecretName: ptr.To("bootstrap")},
+			},
+		}
+		bootstrap := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "bootstrap", Namespace: scopetest.Namespace},
+			Data:       map[string][]byte{"value": []byte("#cloud-config\n")},
+		}
+		machineScope, _, err := scopetest.NewMachineScopeBuilder().
+			WithCluster(cluster).
+			WithMachine(machine).
+			WithPacketCluster(&infrav1.PacketCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: scopetest.ClusterName, Namespace: scopetest.Namespace},
+				Spec:       infrav1.PacketClusterSpec{ProjectID: "project", Metro: "da"},
+			}).
+			WithPacketMachine(&infrav1.PacketMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      scopetest.MachineName,
+					Namespace: scopetest.Namespace,
+					Labels:    map[string]string{clusterv1.ClusterNameLabel: scopetest.ClusterName},
+				},
+				Spec: infrav1.PacketMachineSpec{OS: "ubuntu_22_04", MachineType: "c3.small.x86"},
+			}).
+			WithObjects(bootstrap).
+			WithPatcher(&scopetest.Patcher{}).
+			Build()
+		g.Expect(err).ToNot(HaveOccurred())
+		return machineScope
+	}
+
+	// The creation of the device is rejected by the read-only transport, which does not fail the machine.
+	api := &fakeDeviceAPI{}
+	metalClient := packettest.NewClient(t, api)
+	metalClient.SetReadOnly()
+	r := &PacketMachineReconciler{PacketClient: metalClient}
+
+	machineScope := newMachineScope()
+	result, err := r.reconcile(ctx, machineScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(readOnlyRequeueInterval))
+	g.Expect(machineScope.PacketMachine.Status.FailureReason).To(BeNil())
+	g.Expect(machineScope.PacketMachine.Status.FailureMessage).To(BeNil())
+	g.Expect(conditions.GetReason(machineScope.PacketMachine, infrav1.DeviceReadyCondition)).ToNot(Equal(infrav1.InstanceProvisionFailedReason))
+
+	// A read-only manager does not even try to create the device.
+	r = &PacketMachineReconciler{PacketClient: packettest.NewClient(t, api), ReadOnly: true}
+	machineScope = newMachineScope()
+	result, err = r.reconcile(ctx, machineScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(readOnlyRequeueInterval))
+	g.Expect(api.devices).To(BeEmpty())
+	g.Expect(machineScope.PacketMachine.Status.FailureReason).To(BeNil())
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// deviceClaimWaitInterval is how often PacketMachines are reconciled while waiting for their PacketDeviceClaim.
+const deviceClaimWaitInterval = 30 * time.Second
+
+// claimedDevice assigns the device bound to the PacketDeviceClaim of the PacketMachine to the machine. It returns nil,
+// with the reason in the DeviceReady condition, while the claim is missing, unbound, or its device is used by another
+// machine.
+func (r *PacketMachineReconciler) claimedDevice(ctx context.Context, machineScope *scope.MachineScope) (*metal.Device, error) {
+	packetMachine := machineScope.PacketMachine
+	claimName := packetMachine.Spec.DeviceClaimName
+
+	claim := &infrav1.PacketDeviceClaim{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: machineScope.Namespace(), Name: claimName}, claim); err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.WaitingForDeviceClaimReason, clusterv1.ConditionSeverityInfo,
+				"PacketDeviceClaim %s does not exist", claimName)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get PacketDeviceClaim %s: %w", claimName, err)
+	}
+	if claim.Labels[clusterv1.ClusterNameLabel] != machineScope.Cluster.Name {
+		conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.WaitingForDeviceClaimReason, clusterv1.ConditionSeverityError,
+			"PacketDeviceClaim %s does not belong to cluster %s", claimName, machineScope.Cluster.Name)
+		return nil, nil
+	}
+	if !claim.Status.Bound || !claim.DeletionTimestamp.IsZero() {
+		conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.WaitingForDeviceClaimReason, clusterv1.ConditionSeverityInfo,
+			"PacketDeviceClaim %s is not bound", claimName)
+		return nil, nil
+	}
+
+	// Machines referring to the same claim are reconciled concurrently and must not take the same device.
+	r.adoptLock.Lock()
+	defer r.adoptLock.Unlock()
+
+	dev, _, err := r.metalClient(ctx).GetDevice(ctx, claim.Status.DeviceID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve device %s of PacketDeviceClaim %s: %w", claim.Status.DeviceID, claimName, err)
+	}
+	err = r.metalClient(ctx).AssignClaimedDevice(ctx, dev, claim.Namespace, claim.Name, machineScope.Machine.Name, machineScope.Cluster.Name)
+	if errors.Is(err, packet.ErrDeviceInUse) || errors.Is(err, packet.ErrDeviceNotClaimed) {
+		conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.WaitingForDeviceClaimReason, clusterv1.ConditionSeverityWarning, "%s", err)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl.LoggerFrom(ctx).Info("Using device of PacketDeviceClaim", "device-id", dev.GetId(), "claim", claimName)
+	record.Eventf(packetMachine, "DeviceClaimed", "Using device %s of PacketDeviceClaim %s", dev.GetId(), claimName)
+	return dev, nil
+}
+
+// returnClaimedDevice hands the device of a deleted PacketMachine back to its PacketDeviceClaim instead of deleting
+// it. The claim decides what happens to the device when it is deleted in turn.
+func (r *PacketMachineReconciler) returnClaimedDevice(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) (ctrl.Result, error) {
+	packetMachine := machineScope.PacketMachine
+
+	if err := r.metalClient(ctx).DetachDevice(ctx, dev); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to return device to its claim: %w", err)
+	}
+
+	claimName, _ := packet.DeviceClaimFromTags(dev.Tags)
+	ctrl.LoggerFrom(ctx).Info("Returned device to its claim", "device-id", dev.GetId(), "claim", claimName)
+	record.Eventf(packetMachine, "DeviceReturned", "Returned device %s to PacketDeviceClaim %s instead of deleting it", dev.GetId(), claimName)
+	controllerutil.RemoveFinalizer(packetMachine, infrav1.MachineFinalizer)
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+const (
+	// bootDiagnosticsKey is the ConfigMap key boot diagnostics are stored under.
+	bootDiagnosticsKey = "diagnostics.json"
+	// bootDiagnosticsLabel marks the ConfigMaps holding boot diagnostics.
+	bootDiagnosticsLabel = "infrastructure.cluster.x-k8s.io/packet-boot-diagnostics"
+)
+
+// bootDiagnostics is the evidence gathered about a machine that failed to come up.
+type bootDiagnostics struct {
+	Reason            string                 `json:"reason"`
+	CollectedAt       time.Time              `json:"collectedAt"`
+	Machine           string                 `json:"machine"`
+	PacketMachine     string                 `json:"packetMachine"`
+	BootstrapDataHash string                 `json:"bootstrapDataHash,omitempty"`
+	Device            *bootDiagnosticsDevice `json:"device,omitempty"`
+	Events            []bootDiagnosticsEvent `json:"events,omitempty"`
+	Ports             []bootDiagnosticsPort  `json:"ports,omitempty"`
+	Errors            []string               `json:"errors,omitempty"`
+}
+
+type bootDiagnosticsDevice struct {
+	ID        string     `json:"id"`
+	State     string     `json:"state"`
+	Plan      string     `json:"plan,omitempty"`
+	Facility  string     `json:"facility,omitempty"`
+	Metro     string     `json:"metro,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+type bootDiagnosticsEvent struct {
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	Type      string     `json:"type,omitempty"`
+	Message   string     `json:"message,omitempty"`
+}
+
+type bootDiagnosticsPort struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	NetworkType string `json:"networkType,omitempty"`
+	Bonded      bool   `json:"bonded"`
+	MAC         string `json:"mac,omitempty"`
+}
+
+// bootDiagnosticsName returns the name of the ConfigMap holding the boot diagnostics of a PacketMachine.
+func bootDiagnosticsName(machineScope *scope.MachineScope) string {
+	return machineScope.Name() + "-boot-diagnostics"
+}
+
+// collectBootDiagnostics stores what is known about a machine that failed to provision or bootstrap in a ConfigMap
+// next to its PacketMachine. The ConfigMap is not owned by the PacketMachine, so the evidence outlives the machine
+// when it has to be deleted. Diagnostics are collected once per PacketMachine, failures are only logged.
+func (r *PacketMachineReconciler) collectBootDiagnostics(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device, reason string) {
+	if !r.BootDiagnostics {
+		return
+	}
+
+	log := ctrl.LoggerFrom(ctx)
+	key := client.ObjectKey{Namespace: machineScope.Namespace(), Name: bootDiagnosticsName(machineScope)}
+
+	if err := r.Client.Get(ctx, key, &corev1.ConfigMap{}); err == nil {
+		return
+	} else if !apierrors.IsNotFound(err) {
+		log.Error(err, "failed to check for boot diagnostics")
+		return
+	}
+
+	diagnostics := bootDiagnostics{
+		Reason:            reason,
+		CollectedAt:       time.Now().UTC(),
+		Machine:           machineScope.Machine.Name,
+		PacketMachine:     machineScope.Name(),
+		BootstrapDataHash: machineScope.PacketMachine.Status.BootstrapDataHash,
+	}
+
+	if dev != nil {
+		plan := dev.GetPlan()
+		facility := dev.GetFacility()
+		metro := dev.GetMetro()
+		diagnostics.Device = &bootDiagnosticsDevice{
+			ID:        dev.GetId(),
+			State:     string(dev.GetState()),
+			Plan:      plan.GetSlug(),
+			Facility:  facility.GetCode(),
+			Metro:     metro.GetCode(),
+			CreatedAt: dev.CreatedAt,
+		}
+
+		for _, port := range dev.NetworkPorts {
+			data := port.GetData()
+			diagnostics.Ports = append(diagnostics.Ports, bootDiagnosticsPort{
+				Name:        port.GetName(),
+				Type:        string(port.GetType()),
+				NetworkType: string(port.GetNetworkType()),
+				Bonded:      data.GetBonded(),
+				MAC:         data.GetMac(),
+			})
+		}
+
+		events, _, err := r.metalClient(ctx).EventsApi.FindDeviceEvents(ctx, dev.GetId()).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		if err != nil {
+			diagnostics.Errors = append(diagnostics.Errors, fmt.Sprintf("failed to retrieve device events: %v", err))
+		} else {
+			for _, event := range events.Events {
+				diagnostics.Events = append(diagnostics.Events, bootDiagnosticsEvent{
+					CreatedAt: event.CreatedAt,
+					Type:      event.GetType(),
+					Message:   event.GetInterpolated(),
+				})
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(diagnostics, "", "  ")
+	if err != nil {
+		log.Error(err, "failed to encode boot diagnostics")
+		return
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel: machineScope.Cluster.Name,
+				bootDiagnosticsLabel:       "",
+			},
+		},
+		Data: map[string]string{
+			bootDiagnosticsKey: string(data),
+		},
+	}
+	if err := r.Client.Create(ctx, configMap); err != nil && !apierrors.IsAlreadyExists(err) {
+		log.Error(err, "failed to store boot diagnostics")
+		return
+	}
+
+	log.Info("Collected boot diagnostics", "configMap", key.Name, "reason", reason)
+	record.Eventf(machineScope.PacketMachine, "BootDiagnosticsCollected", "Boot diagnostics stored in ConfigMap %s: %s", key.Name, reason)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func TestCollectBootDiagnostics(t *testing.T) {
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine-boot-diagnostics"},
+		Data:       map[string]string{bootDiagnosticsKey: "{}"},
+	}
+
+	tests := []struct {
+		name      string
+		disabled  bool
+		dev       *metal.Device
+		eventsErr bool
+		existing  bool
+		// want checks the diagnostics stored, nil when none are.
+		want func(g *WithT, diagnostics bootDiagnostics)
+	}{
+		{
+			name:     "disabled",
+			disabled: true,
+			dev:      &metal.Device{Id: ptr.To("device")},
+		},
+		{
+			name: "device with its events and ports",
+			dev: &metal.Device{
+				Id:    ptr.To("device"),
+				State: metal.DEVICESTATE_FAILED.Ptr(),
+				Plan:  &metal.Plan{Slug: ptr.To("c3.small.x86")},
+				Metro: &metal.DeviceMetro{Code: ptr.To("da")},
+				NetworkPorts: []metal.Port{{
+					Name: ptr.To("bond0"),
+					Data: &metal.PortData{Bonded: ptr.To(true)},
+				}},
+			},
+			want: func(g *WithT, diagnostics bootDiagnostics) {
+				g.Expect(diagnostics.Reason).To(Equal("device failed to provision"))
+				g.Expect(diagnostics.Machine).To(Equal("machine"))
+				g.Expect(diagnostics.BootstrapDataHash).To(Equal("hash"))
+				g.Expect(diagnostics.Device).To(Equal(&bootDiagnosticsDevice{ID: "device", State: "failed", Plan: "c3.small.x86", Metro: "da"}))
+				g.Expect(diagnostics.Ports).To(Equal([]bootDiagnosticsPort{{Name: "bond0", Bonded: true}}))
+				g.Expect(diagnostics.Events).To(Equal([]bootDiagnosticsEvent{{Type: "provisioning.104", Message: "Provision failed"}}))
+				g.Expect(diagnostics.Errors).To(BeEmpty())
+			},
+		},
+		{
+			name:      "events unavailable",
+			dev:       &metal.Device{Id: ptr.To("device")},
+			eventsErr: true,
+			want: func(g *WithT, diagnostics bootDiagnostics) {
+				g.Expect(diagnostics.Events).To(BeEmpty())
+				g.Expect(diagnostics.Errors).To(ConsistOf(ContainSubstring("failed to retrieve device events")))
+			},
+		},
+		{
+			name: "machine without a device",
+			want: func(g *WithT, diagnostics bootDiagnostics) {
+				g.Expect(diagnostics.Device).To(BeNil())
+				g.Expect(diagnostics.Events).To(BeEmpty())
+			},
+		},
+		{
+			name:     "collected once",
+			dev:      &metal.Device{Id: ptr.To("device")},
+			existing: true,
+			want: func(g *WithT, diagnostics bootDiagnostics) {
+				g.Expect(diagnostics).To(Equal(bootDiagnostics{}))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			metalClient := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tt.eventsErr {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"events": [{"type": "provisioning.104", "interpolated": "Provision failed"}]}`))
+			}))
+
+			builder := fake.NewClientBuilder().WithScheme(scopetest.Scheme())
+			if tt.existing {
+				builder = builder.WithObjects(existing.DeepCopy())
+			}
+			c := builder.Build()
+			r := &PacketMachineReconciler{Client: c, PacketClient: metalClient, BootDiagnostics: !tt.disabled}
+
+			machineScope := &scope.MachineScope{
+				Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster"}},
+				Machine: &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine"}},
+				PacketMachine: &infrav1.PacketMachine{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine"},
+					Status:     infrav1.PacketMachineStatus{BootstrapDataHash: "hash"},
+				},
+			}
+			r.collectBootDiagnostics(context.Background(), machineScope, tt.dev, "device failed to provision")
+
+			configMap := &corev1.ConfigMap{}
+			err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "machine-boot-diagnostics"}, configMap)
+			if tt.want == nil {
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(configMap.Labels).To(Or(BeNil(), HaveKeyWithValue(clusterv1.ClusterNameLabel, "cluster")))
+
+			var diagnostics bootDiagnostics
+			g.Expect(json.Unmarshal([]byte(configMap.Data[bootDiagnosticsKey]), &diagnostics)).To(Succeed())
+			tt.want(g, diagnostics)
+		})
+	}
+}
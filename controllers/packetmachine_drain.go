@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// drainRequeueInterval is how often the deletion of a device waiting for its Node to be drained is retried.
+const drainRequeueInterval = 15 * time.Second
+
+// waitForDrain reports whether the deletion of the device of a PacketMachine is to wait for Cluster API to finish
+// draining the Node of its Machine. Cluster API drains Nodes before deleting the infrastructure of Machines, but a
+// PacketMachine deleted with its Machine, e.g. by deleting a namespace, would otherwise pull the device from under
+// the workloads still being evicted. The deletion goes on once the Machine was drained, its drain was skipped, or the
+// deletion timeout of the machine expired.
+func waitForDrain(ctx context.Context, machineScope *scope.MachineScope, now time.Time) (ctrl.Result, bool) {
+	log := ctrl.LoggerFrom(ctx)
+	machine := machineScope.Machine
+	packetMachine := machineScope.PacketMachine
+
+	if machine == nil || machine.DeletionTimestamp.IsZero() || !conditions.IsFalse(machine, clusterv1.DrainingSucceededCondition) {
+		return ctrl.Result{}, false
+	}
+
+	deletionTimestamp := packetMachine.GetDeletionTimestamp()
+	if deletionTimestamp != nil && now.Sub(deletionTimestamp.Time) >= machineScope.DeletionTimeout() {
+		log.Info("Timed out waiting for the Node to be drained, deleting the device", "timeout", machineScope.DeletionTimeout())
+		record.Warnf(packetMachine, "DrainTimeout", "Deleting the device of a Machine whose Node is not drained after %s", machineScope.DeletionTimeout())
+		return ctrl.Result{}, false
+	}
+
+	log.Info("Waiting for the Node to be drained before deleting the device")
+	conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.WaitingForDrainReason, clusterv1.ConditionSeverityInfo,
+		"%s", conditions.GetMessage(machine, clusterv1.DrainingSucceededCondition))
+	return ctrl.Result{RequeueAfter: drainRequeueInterval}, true
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+func TestWaitForDrain(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	deletion := metav1.NewTime(time.Now())
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine", DeletionTimestamp: &deletion}}
+	packetMachine := &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{Name: "machine", DeletionTimestamp: &deletion}}
+	machineScope := &scope.MachineScope{
+		Machine:       machine,
+		PacketCluster: new(infrav1.PacketCluster),
+		PacketMachine: packetMachine,
+	}
+
+	// Machines that are not drained by Cluster API are not waited for.
+	_, waiting := waitForDrain(ctx, machineScope, deletion.Time)
+	g.Expect(waiting).To(BeFalse())
+
+	// Machines being drained are waited for.
+	conditions.MarkFalse(machine, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "Draining the node")
+	result, waiting := waitForDrain(ctx, machineScope, deletion.Time)
+	g.Expect(waiting).To(BeTrue())
+	g.Expect(result.RequeueAfter).To(Equal(drainRequeueInterval))
+	g.Expect(conditions.GetReason(packetMachine, infrav1.DeviceReadyCondition)).To(Equal(infrav1.WaitingForDrainReason))
+
+	// Until the deletion timeout expires.
+	_, waiting = waitForDrain(ctx, machineScope, deletion.Add(10*time.Minute))
+	g.Expect(waiting).To(BeFalse())
+	packetMachine.Spec.DeletionTimeoutSeconds = ptr.To[int32](3600)
+	_, waiting = waitForDrain(ctx, machineScope, deletion.Add(10*time.Minute))
+	g.Expect(waiting).To(BeTrue())
+
+	// Or the Machine is drained.
+	conditions.MarkTrue(machine, clusterv1.DrainingSucceededCondition)
+	_, waiting = waitForDrain(ctx, machineScope, deletion.Time)
+	g.Expect(waiting).To(BeFalse())
+}
+
+func TestForceDeleteDevice(t *testing.T) {
+	g := NewWithT(t)
+
+	deletion := metav1.NewTime(time.Now())
+	machineScope := &scope.MachineScope{
+		PacketCluster: new(infrav1.PacketCluster),
+		PacketMachine: &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deletion}},
+	}
+	g.Expect(forceDeleteDevice(machineScope, deletion.Time)).To(BeTrue())
+
+	machineScope.PacketMachine.Spec.DeletePolicy = infrav1.DeletePolicyGraceful
+	g.Expect(forceDeleteDevice(machineScope, deletion.Add(time.Hour))).To(BeFalse())
+
+	machineScope.PacketMachine.Spec.DeletePolicy = infrav1.DeletePolicyForceAfterTimeout
+	machineScope.PacketCluster.Spec.DeletionTimeoutSeconds = ptr.To[int32](60)
+	g.Expect(forceDeleteDevice(machineScope, deletion.Add(30*time.Second))).To(BeFalse())
+	g.Expect(forceDeleteDevice(machineScope, deletion.Add(time.Minute))).To(BeTrue())
+}
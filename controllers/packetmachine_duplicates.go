@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// findDeviceByTags returns the device of the machine found by the tags assigned on creation, along with the other
+// devices carrying them. The adopted device is kept when set, otherwise the oldest device is adopted.
+func (r *PacketMachineReconciler) findDeviceByTags(ctx context.Context, machineScope *scope.MachineScope, adopted *metal.Device) (*metal.Device, []string, error) {
+	devices, err := r.metalClient(ctx).GetDevicesByTags(
+		ctx,
+		machineScope.PacketCluster.Spec.ProjectID,
+		packet.DefaultCreateTags(machineScope.Namespace(), machineScope.Machine.Name, machineScope.Cluster.Name),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if adopted == nil && len(devices) > 0 {
+		adopted = &devices[0]
+	}
+
+	var duplicates []string
+	for _, device := range devices {
+		if device.GetId() != adopted.GetId() {
+			duplicates = append(duplicates, device.GetId())
+		}
+	}
+	return adopted, duplicates, nil
+}
+
+// reconcileDuplicateDevices reports the devices carrying the tags of the machine other than the one it uses with the
+// DuplicateDevices condition, deleting them when DeleteDuplicateDevices is set.
+func (r *PacketMachineReconciler) reconcileDuplicateDevices(ctx context.Context, machineScope *scope.MachineScope, adopted *metal.Device) (*metal.Device, error) {
+	adopted, duplicates, err := r.findDeviceByTags(ctx, machineScope, adopted)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(duplicates) > 0 && r.DeleteDuplicateDevices {
+		if err := r.deleteDuplicateDevices(ctx, machineScope, duplicates); err != nil {
+			return nil, err
+		}
+		duplicates = nil
+	}
+
+	if len(duplicates) == 0 {
+		conditions.Delete(machineScope.PacketMachine, infrav1.DuplicateDevicesCondition)
+		return adopted, nil
+	}
+
+	if !conditions.Has(machineScope.PacketMachine, infrav1.DuplicateDevicesCondition) {
+		ctrl.LoggerFrom(ctx).Info("Found duplicate devices", "device-id", adopted.GetId(), "duplicates", duplicates)
+		record.Warnf(machineScope.PacketMachine, infrav1.DuplicateDevicesFoundReason,
+			"Devices %s carry the tags of the machine but device %s is used, delete them to avoid leaking them",
+			strings.Join(duplicates, ", "), adopted.GetId())
+	}
+	conditions.Set(machineScope.PacketMachine, &clusterv1.Condition{
+		Type:     infrav1.DuplicateDevicesCondition,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterv1.ConditionSeverityWarning,
+		Reason:   infrav1.DuplicateDevicesFoundReason,
+		Message:  fmt.Sprintf("device %s is used, duplicate devices: %s", adopted.GetId(), strings.Join(duplicates, ", ")),
+	})
+	return adopted, nil
+}
+
+// deleteDuplicateDevices force deletes devices created for the machine that it does not use.
+func (r *PacketMachineReconciler) deleteDuplicateDevices(ctx context.Context, machineScope *scope.MachineScope, duplicates []string) error {
+	for _, deviceID := range duplicates {
+		resp, err := r.metalClient(ctx).DevicesApi.DeleteDevice(ctx, deviceID).ForceDelete(true).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return fmt.Errorf("failed to delete duplicate device %s: %w", deviceID, err)
+		}
+		devicesDeletedTotal.MustCurryWith(machineScope.MetricLabels()).WithLabelValues("packetmachine").Inc()
+		ctrl.LoggerFrom(ctx).Info("Deleted duplicate device", "duplicate-device-id", deviceID)
+		record.Eventf(machineScope.PacketMachine, "DuplicateDeviceDeleted", "Deleted duplicate device %s", deviceID)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// eipAssignmentBackoff is how often assigning the control plane elastic IP is retried while the API still reports
+// it assigned to a device it was just removed from.
+var eipAssignmentBackoff = wait.Backoff{
+	Duration: 2 * time.Second,
+	Factor:   2,
+	Steps:    3,
+}
+
+// assignControlPlaneEIP assigns the control plane elastic IP to the device of a control plane machine, unless another
+// live device holds it. When a control plane machine is replaced quickly, the device of the old machine may still
+// hold the IP while it is deleted: that assignment is removed first, so that the new one does not conflict with it.
+func (r *PacketMachineReconciler) assignControlPlaneEIP(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device, eip *metal.IPReservation) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if eip == nil {
+		return packet.ErrControlPlanEndpointNotFound
+	}
+
+	var stale []metal.IPAssignment
+	for _, assignment := range eip.GetAssignments() {
+		deviceID := path.Base(assignment.AssignedTo.GetHref())
+		if deviceID == dev.GetId() {
+			return nil
+		}
+		deleting, err := r.isDeviceBeingDeleted(ctx, machineScope, deviceID)
+		if err != nil {
+			return err
+		}
+		if !deleting {
+			log.V(2).Info("Elastic IP is assigned to another control plane device", "address", eip.GetAddress(), "device-id", deviceID)
+			return nil
+		}
+		stale = append(stale, assignment)
+	}
+
+	for _, assignment := range stale {
+		if err := r.metalClient(ctx).UnassignIP(ctx, assignment.GetId()); err != nil {
+			return fmt.Errorf("failed to unassign elastic IP %s from device %s: %w", eip.GetAddress(),
+				path.Base(assignment.AssignedTo.GetHref()), err)
+		}
+		log.Info("Unassigned elastic IP from a device being deleted", "address", eip.GetAddress(),
+			"device-id", path.Base(assignment.AssignedTo.GetHref()))
+		r.recordAudit(ctx, machineScope, audit.ElasticIPUnassigned, eip.GetAddress(), "Unassigned elastic IP from device %s being deleted",
+			path.Base(assignment.AssignedTo.GetHref()))
+	}
+
+	// The API may take a moment to notice the assignments removed above.
+	var lastErr error
+	if err := wait.ExponentialBackoffWithContext(ctx, eipAssignmentBackoff, func(ctx context.Context) (bool, error) {
+		lastErr = r.metalClient(ctx).AssignIP(ctx, dev.GetId(), eip.GetAddress())
+		if errors.Is(lastErr, packet.ErrIPAssignmentConflict) {
+			return false, nil
+		}
+		return lastErr == nil, lastErr
+	}); err != nil {
+		if wait.Interrupted(err) && lastErr != nil {
+			return lastErr
+		}
+		return err
+	}
+
+	if len(stale) > 0 {
+		r.recordAudit(ctx, machineScope, audit.ElasticIPMoved, eip.GetAddress(), "Moved elastic IP from %d device(s) being deleted to device %s",
+			len(stale), dev.GetId())
+		record.Eventf(machineScope.PacketMachine, "ElasticIPReassigned", "Reassigned elastic IP %s from %d device(s) being deleted",
+			eip.GetAddress(), len(stale))
+	} else {
+		r.recordAudit(ctx, machineScope, audit.ElasticIPAssigned, eip.GetAddress(), "Assigned elastic IP to device %s", dev.GetId())
+	}
+	return nil
+}
+
+// isDeviceBeingDeleted reports whether a device is gone, being deprovisioned, or belongs to a Machine of the cluster
+// that is gone or being deleted.
+func (r *PacketMachineReconciler) isDeviceBeingDeleted(ctx context.Context, machineScope *scope.MachineScope, deviceID string) (bool, error) {
+	dev, resp, err := r.metalClient(ctx).DevicesApi.FindDeviceById(ctx, deviceID).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get device %s: %w", deviceID, err)
+	}
+
+	switch dev.GetState() {
+	case metal.DEVICESTATE_DELETED, metal.DEVICESTATE_DEPROVISIONING:
+		return true, nil
+	}
+
+	// Devices of other clusters, or created outside of the provider, are never taken the IP from.
+	if !packet.ItemsInList(dev.Tags, []string{
+		packet.GenerateClusterTag(machineScope.Cluster.Name),
+		packet.GenerateNamespaceTag(machineScope.Namespace()),
+	}) {
+		return false, nil
+	}
+	name, ok := packet.MachineNameFromTags(dev.Tags)
+	if !ok {
+		return false, nil
+	}
+
+	machine := &clusterv1.Machine{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: machineScope.Namespace(), Name: name}, machine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get Machine %s of device %s: %w", name, deviceID, err)
+	}
+	return !machine.DeletionTimestamp.IsZero(), nil
+}
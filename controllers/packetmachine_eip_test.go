@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+func TestAssignControlPlaneEIP(t *testing.T) {
+	defer func(backoff wait.Backoff) { eipAssignmentBackoff = backoff }(eipAssignmentBackoff)
+	eipAssignmentBackoff = wait.Backoff{Duration: time.Millisecond, Steps: 3}
+
+	eip := &metal.IPReservation{
+		Address: ptr.To("192.0.2.10"),
+		Assignments: []metal.IPAssignment{
+			{Id: ptr.To("assignment"), AssignedTo: &metal.Href{Href: "/metal/v1/devices/old"}},
+		},
+	}
+	tags := packet.DefaultCreateTags("default", "old-machine", "capi")
+
+	tests := []struct {
+		name         string
+		oldDevice    metal.Device
+		oldMachine   *clusterv1.Machine
+		conflicts    int
+		wantErr      bool
+		wantRequests []string
+	}{
+		{
+			name:      "old device deprovisioning",
+			oldDevice: metal.Device{Id: ptr.To("old"), State: metal.DEVICESTATE_DEPROVISIONING.Ptr(), Tags: tags},
+			conflicts: 1,
+			wantRequests: []string{
+				"GET /devices/old",
+				"DELETE /ips/assignment",
+				"POST /devices/new/ips",
+				"POST /devices/new/ips",
+			},
+		},
+		{
+			name:      "old machine being deleted",
+			oldDevice: metal.Device{Id: ptr.To("old"), State: metal.DEVICESTATE_ACTIVE.Ptr(), Tags: tags},
+			oldMachine: &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+				Name: "old-machine", Namespace: "default",
+				DeletionTimestamp: ptr.To(metav1.Now()), Finalizers: []string{clusterv1.MachineFinalizer},
+			}},
+			wantRequests: []string{
+				"GET /devices/old",
+				"DELETE /ips/assignment",
+				"POST /devices/new/ips",
+			},
+		},
+		{
+			name:      "old machine still running",
+			oldDevice: metal.Device{Id: ptr.To("old"), State: metal.DEVICESTATE_ACTIVE.Ptr(), Tags: tags},
+			oldMachine: &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+				Name: "old-machine", Namespace: "default",
+			}},
+			wantRequests: []string{"GET /devices/old"},
+		},
+		{
+			name:      "conflict persists",
+			oldDevice: metal.Device{Id: ptr.To("old"), State: metal.DEVICESTATE_DEPROVISIONING.Ptr(), Tags: tags},
+			conflicts: 3,
+			wantErr:   true,
+			wantRequests: []string{
+				"GET /devices/old",
+				"DELETE /ips/assignment",
+				"POST /devices/new/ips",
+				"POST /devices/new/ips",
+				"POST /devices/new/ips",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var requests []string
+			conflicts := tt.conflicts
+			packetClient := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				switch r.Method {
+				case http.MethodGet:
+					_ = json.NewEncoder(w).Encode(tt.oldDevice)
+				case http.MethodDelete:
+					w.WriteHeader(http.StatusNoContent)
+				case http.MethodPost:
+					if conflicts > 0 {
+						conflicts--
+						w.WriteHeader(http.StatusUnprocessableEntity)
+						_, _ = w.Write([]byte(`{"errors": ["Address is already assigned"]}`))
+						return
+					}
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"id": "assignment-new"}`))
+				}
+			}))
+
+			scheme := runtime.NewScheme()
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if tt.oldMachine != nil {
+				builder = builder.WithObjects(tt.oldMachine)
+			}
+			r := &PacketMachineReconciler{Client: builder.Build(), PacketClient: packetClient}
+
+			machineScope := &scope.MachineScope{
+				Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "capi", Namespace: "default"}},
+				Machine:       &clusterv1.Machine{},
+				PacketMachine: &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{Name: "new-machine", Namespace: "default"}},
+			}
+
+			err := r.assignControlPlaneEIP(context.Background(), machineScope, &metal.Device{Id: ptr.To("new")}, eip)
+			if tt.wantErr {
+				g.Expect(err).To(MatchError(packet.ErrIPAssignmentConflict))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+			g.Expect(requests).To(Equal(tt.wantRequests))
+		})
+	}
+}
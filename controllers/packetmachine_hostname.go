@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// reconcileHostname renames the device of a machine when its hostname drifted from the one of the machine, through
+// the device update API rather than by recreating the device.
+func (r *PacketMachineReconciler) reconcileHostname(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) error {
+	hostname := machineScope.Hostname()
+	if dev.GetHostname() == hostname {
+		return nil
+	}
+
+	if _, _, err := r.metalClient(ctx).DevicesApi.UpdateDevice(ctx, dev.GetId()).DeviceUpdateInput(metal.DeviceUpdateInput{Hostname: &hostname}).Execute(); err != nil { //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		return fmt.Errorf("failed to rename device %s to %s: %w", dev.GetId(), hostname, err)
+	}
+	ctrl.LoggerFrom(ctx).Info("Renamed device", "device-id", dev.GetId(), "from", dev.GetHostname(), "to", hostname)
+	record.Eventf(machineScope.PacketMachine, "DeviceRenamed", "Renamed device %s from %s to %s", dev.GetId(), dev.GetHostname(), hostname)
+	r.recordAudit(ctx, machineScope, audit.DeviceRenamed, dev.GetId(), "Renamed device from %s to %s", dev.GetHostname(), hostname)
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+func TestReconcileHostname(t *testing.T) {
+	g := NewWithT(t)
+
+	var updates []metal.DeviceUpdateInput
+	client := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.Method + " " + r.URL.Path).To(Equal("PUT /devices/device"))
+		var input metal.DeviceUpdateInput
+		g.Expect(json.NewDecoder(r.Body).Decode(&input)).To(Succeed())
+		updates = append(updates, input)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "device"}`))
+	}))
+	r := &PacketMachineReconciler{PacketClient: client}
+
+	machineScope := &scope.MachineScope{
+		PacketMachine: &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{Name: "machine"}},
+	}
+	ctx := context.Background()
+
+	// Devices with the hostname of their machine are left alone.
+	g.Expect(r.reconcileHostname(ctx, machineScope, &metal.Device{Id: ptr.To("device"), Hostname: ptr.To("machine")})).To(Succeed())
+	g.Expect(updates).To(BeEmpty())
+
+	// Drifted devices are renamed in place.
+	g.Expect(r.reconcileHostname(ctx, machineScope, &metal.Device{Id: ptr.To("device"), Hostname: ptr.To("renamed")})).To(Succeed())
+	g.Expect(updates).To(HaveLen(1))
+	g.Expect(updates[0].GetHostname()).To(Equal("machine"))
+}
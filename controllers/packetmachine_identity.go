@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	packet "sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// deviceIdentityRecheckInterval is how often PacketMachines whose providerID refers to another device are
+// reconciled again, to notice the device or the providerID being fixed.
+const deviceIdentityRecheckInterval = 5 * time.Minute
+
+var errDeviceIdentityMismatch = errors.New("device does not belong to the machine")
+
+// verifyDeviceIdentity checks that the device found by the providerID of a PacketMachine is the device of the
+// machine, rather than another device the recorded ID came to refer to, e.g. after restoring a backup of the
+// management cluster. The device belongs to the machine when it carries its tags, current or legacy, or its hostname.
+func verifyDeviceIdentity(machineScope *scope.MachineScope, dev *metal.Device) error {
+	machine := machineScope.Machine
+	clusterName := machineScope.Cluster.Name
+
+	if packet.ItemsInList(dev.Tags, packet.DefaultCreateTags(machineScope.Namespace(), machine.Name, clusterName)) {
+		return nil
+	}
+	if legacy, ok := packet.GetLegacyDeviceTags(dev); ok && legacy.ClusterName == clusterName &&
+		(legacy.Machine == machine.Name || legacy.Machine == string(machine.UID)) {
+		return nil
+	}
+	if dev.GetHostname() == machineScope.Hostname() {
+		return nil
+	}
+	return fmt.Errorf("%w: device %s is named %q and has neither the tags nor the hostname of the machine",
+		errDeviceIdentityMismatch, dev.GetId(), dev.GetHostname())
+}
+
+// reportDeviceIdentityMismatch reports a PacketMachine whose providerID refers to a device of something else with
+// the DeviceIdentityMismatch condition. The device is neither used nor deleted for the machine.
+func reportDeviceIdentityMismatch(machineScope *scope.MachineScope, err error) {
+	packetMachine := machineScope.PacketMachine
+	if !conditions.Has(packetMachine, infrav1.DeviceIdentityMismatchCondition) {
+		record.Warnf(packetMachine, infrav1.DeviceNotOwnedReason, "Refusing to manage the device of providerID %s: %s", machineScope.ProviderID(), err)
+	}
+	conditions.Set(packetMachine, &clusterv1.Condition{
+		Type:     infrav1.DeviceIdentityMismatchCondition,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterv1.ConditionSeverityError,
+		Reason:   infrav1.DeviceNotOwnedReason,
+		Message:  err.Error(),
+	})
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+func TestVerifyDeviceIdentity(t *testing.T) {
+	machineScope := &scope.MachineScope{
+		Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		Machine: &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine", UID: "machine-uid"}},
+		PacketMachine: &infrav1.PacketMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "packet-machine", Namespace: "default"},
+			Spec:       infrav1.PacketMachineSpec{ProviderID: ptr.To("equinixmetal://device")},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		device  metal.Device
+		matches bool
+	}{
+		{
+			name:    "tags of the machine",
+			device:  metal.Device{Tags: packet.DefaultCreateTags("default", "machine", "cluster"), Hostname: ptr.To("renamed")},
+			matches: true,
+		},
+		{
+			name: "legacy tags of the machine",
+			device: metal.Device{Tags: []string{
+				"cluster-api-provider-packet:cluster-id:cluster",
+				"cluster-api-provider-packet:machine-uid:machine-uid",
+			}},
+			matches: true,
+		},
+		{
+			name:    "hostname of the machine",
+			device:  metal.Device{Hostname: ptr.To("packet-machine")},
+			matches: true,
+		},
+		{
+			name:   "tags of another machine",
+			device: metal.Device{Tags: packet.DefaultCreateTags("default", "other", "cluster"), Hostname: ptr.To("other")},
+		},
+		{
+			name:   "tags of the machine in another namespace",
+			device: metal.Device{Tags: packet.DefaultCreateTags("other", "machine", "cluster"), Hostname: ptr.To("other")},
+		},
+		{
+			name:   "untagged device",
+			device: metal.Device{Hostname: ptr.To("database")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			tt.device.Id = ptr.To("device")
+
+			err := verifyDeviceIdentity(machineScope, &tt.device)
+			if tt.matches {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(errDeviceIdentityMismatch))
+		})
+	}
+}
+
+func TestReportDeviceIdentityMismatch(t *testing.T) {
+	g := NewWithT(t)
+
+	machineScope := &scope.MachineScope{PacketMachine: &infrav1.PacketMachine{}}
+	reportDeviceIdentityMismatch(machineScope, errDeviceIdentityMismatch)
+
+	g.Expect(conditions.IsTrue(machineScope.PacketMachine, infrav1.DeviceIdentityMismatchCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(machineScope.PacketMachine, infrav1.DeviceIdentityMismatchCondition)).To(Equal(infrav1.DeviceNotOwnedReason))
+}
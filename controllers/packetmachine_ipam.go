@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// ipAddressClaimName returns the name of the IPAddressClaim of a PacketMachine for its pool at index i of
+// AddressesFromPools.
+func ipAddressClaimName(machineScope *scope.MachineScope, i int) string {
+	return fmt.Sprintf("%s-%d", machineScope.Name(), i)
+}
+
+// reconcileIPAddressClaims claims an address from each of the AddressesFromPools of a PacketMachine, following the
+// Cluster API IPAM contract, and returns the addresses allocated so far, in the order of the pools. The claims are
+// owned by the PacketMachine, whose reconciliation is triggered once their addresses are allocated.
+func (r *PacketMachineReconciler) reconcileIPAddressClaims(ctx context.Context, machineScope *scope.MachineScope) ([]ipamv1.IPAddressSpec, error) {
+	packetMachine := machineScope.PacketMachine
+	if len(packetMachine.Spec.AddressesFromPools) == 0 {
+		conditions.Delete(packetMachine, infrav1.IPAddressesClaimedCondition)
+		return nil, nil
+	}
+
+	var addresses []ipamv1.IPAddressSpec
+	var pending []string
+	for i, pool := range packetMachine.Spec.AddressesFromPools {
+		claim := &ipamv1.IPAddressClaim{}
+		key := client.ObjectKey{Namespace: machineScope.Namespace(), Name: ipAddressClaimName(machineScope, i)}
+		err := r.Client.Get(ctx, key, claim)
+		if apierrors.IsNotFound(err) {
+			claim = &ipamv1.IPAddressClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: key.Namespace,
+					Name:      key.Name,
+					Labels: map[string]string{
+						clusterv1.ClusterNameLabel: machineScope.Cluster.Name,
+					},
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: infrav1.GroupVersion.String(),
+						Kind:       "PacketMachine",
+						Name:       packetMachine.Name,
+						UID:        packetMachine.UID,
+						Controller: ptr.To(true),
+					}},
+				},
+				Spec: ipamv1.IPAddressClaimSpec{PoolRef: pool},
+			}
+			if err := r.Client.Create(ctx, claim); err != nil {
+				return nil, fmt.Errorf("failed to create IPAddressClaim %s: %w", key.Name, err)
+			}
+			ctrl.LoggerFrom(ctx).Info("Claimed IP address", "claim", key.Name, "pool", pool.Name)
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get IPAddressClaim %s: %w", key.Name, err)
+		}
+
+		if claim.Status.AddressRef.Name == "" {
+			pending = append(pending, key.Name)
+			continue
+		}
+		address := &ipamv1.IPAddress{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: key.Namespace, Name: claim.Status.AddressRef.Name}, address); err != nil {
+			if apierrors.IsNotFound(err) {
+				pending = append(pending, key.Name)
+				continue
+			}
+			return nil, fmt.Errorf("failed to get IPAddress %s: %w", claim.Status.AddressRef.Name, err)
+		}
+		addresses = append(addresses, address.Spec)
+	}
+
+	if len(pending) > 0 {
+		conditions.MarkFalse(packetMachine, infrav1.IPAddressesClaimedCondition, infrav1.WaitingForIPAddressesReason, clusterv1.ConditionSeverityInfo,
+			"waiting for the addresses of IPAddressClaims %v", pending)
+		return addresses, nil
+	}
+	conditions.MarkTrue(packetMachine, infrav1.IPAddressesClaimedCondition)
+	return addresses, nil
+}
+
+// ipAddressesPending returns whether some of the addresses of the AddressesFromPools of a PacketMachine are not
+// allocated yet.
+func ipAddressesPending(machineScope *scope.MachineScope, addresses []ipamv1.IPAddressSpec) bool {
+	return len(addresses) < len(machineScope.PacketMachine.Spec.AddressesFromPools)
+}
+
+// ipAddressesToNodeAddresses returns the claimed addresses of a device as addresses of its machine.
+func ipAddressesToNodeAddresses(addresses []ipamv1.IPAddressSpec) []corev1.NodeAddress {
+	out := make([]corev1.NodeAddress, 0, len(addresses))
+	for _, address := range addresses {
+		out = append(out, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: address.Address})
+	}
+	return out
+}
+
+// detachDeviceVLANs detaches the device of a deleted PacketMachine from the VLANs of its cluster, so that it stops
+// using the addresses configured on them before they are released. VLANs not managed by the cluster are left alone.
+func (r *PacketMachineReconciler) detachDeviceVLANs(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) error {
+	port, err := packet.DeviceBondPort(dev)
+	if errors.Is(err, packet.ErrBondPortNotFound) {
+		// Without a bond port, the device is not attached to any VLAN.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	attached := map[string]bool{}
+	for _, vn := range port.VirtualNetworks {
+		attached[vn.GetId()] = true
+	}
+
+	for _, vlan := range machineScope.PacketCluster.Status.VLANs {
+		if !attached[vlan.ID] {
+			continue
+		}
+		if err := r.metalClient(ctx).DetachVLAN(ctx, port.GetId(), vlan.ID); err != nil {
+			return fmt.Errorf("failed to detach device %s from VLAN %s: %w", dev.GetId(), vlan.Name, err)
+		}
+		ctrl.LoggerFrom(ctx).Info("Detached device from VLAN", "device-id", dev.GetId(), "vlan", vlan.Name)
+		record.Eventf(machineScope.PacketMachine, "VLANDetached", "Detached device %s from VLAN %s", dev.GetId(), vlan.Name)
+	}
+	return nil
+}
+
+// deleteIPAddressClaims releases the addresses claimed for a deleted PacketMachine. It is called once the device is
+// detached from the VLANs the addresses are configured on, so that an address allocated again to another machine is
+// not still reachable on the device, and before the device is deleted, rather than racing with its deletion.
+func (r *PacketMachineReconciler) deleteIPAddressClaims(ctx context.Context, machineScope *scope.MachineScope) error {
+	for i := range machineScope.PacketMachine.Spec.AddressesFromPools {
+		claim := &ipamv1.IPAddressClaim{ObjectMeta: metav1.ObjectMeta{
+			Namespace: machineScope.Namespace(),
+			Name:      ipAddressClaimName(machineScope, i),
+		}}
+		if err := r.Client.Delete(ctx, claim); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete IPAddressClaim %s: %w", claim.Name, err)
+		}
+	}
+	return nil
+}
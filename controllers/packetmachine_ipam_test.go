@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func TestReconcileIPAddressClaims(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	pool := func(name string) corev1.TypedLocalObjectReference {
+		return corev1.TypedLocalObjectReference{APIGroup: ptr.To("ipam.cluster.x-k8s.io"), Kind: "InClusterIPPool", Name: name}
+	}
+	machineScope, c, err := scopetest.NewMachineScopeBuilder().
+		WithPacketMachine(&infrav1.PacketMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: scopetest.Namespace, Name: scopetest.MachineName},
+			Spec:       infrav1.PacketMachineSpec{AddressesFromPools: []corev1.TypedLocalObjectReference{pool("storage"), pool("backup")}},
+		}).
+		Build()
+	g.Expect(err).ToNot(HaveOccurred())
+	r := &PacketMachineReconciler{Client: c}
+
+	// An address is claimed from each pool, and the machine waits for them to be allocated.
+	addresses, err := r.reconcileIPAddressClaims(ctx, machineScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ipAddressesPending(machineScope, addresses)).To(BeTrue())
+	g.Expect(conditions.GetReason(machineScope.PacketMachine, infrav1.IPAddressesClaimedCondition)).To(Equal(infrav1.WaitingForIPAddressesReason))
+
+	claims := &ipamv1.IPAddressClaimList{}
+	g.Expect(c.List(ctx, claims)).To(Succeed())
+	g.Expect(claims.Items).To(HaveLen(2))
+	for _, claim := range claims.Items {
+		g.Expect(metav1.IsControlledBy(&claim, machineScope.PacketMachine)).To(BeTrue())
+	}
+
+	// Once the IPAM provider allocated them, the addresses are returned in the order of the pools.
+	for i, address := range []string{"10.0.0.2", "10.1.0.2"} {
+		claim := &ipamv1.IPAddressClaim{}
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: scopetest.Namespace, Name: ipAddressClaimName(machineScope, i)}, claim)).To(Succeed())
+		g.Expect(c.Create(ctx, &ipamv1.IPAddress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: scopetest.Namespace, Name: claim.Name},
+			Spec:       ipamv1.IPAddressSpec{ClaimRef: corev1.LocalObjectReference{Name: claim.Name}, PoolRef: claim.Spec.PoolRef, Address: address, Prefix: 24},
+		})).To(Succeed())
+		claim.Status.AddressRef.Name = claim.Name
+		g.Expect(c.Update(ctx, claim)).To(Succeed())
+	}
+
+	addresses, err = r.reconcileIPAddressClaims(ctx, machineScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ipAddressesPending(machineScope, addresses)).To(BeFalse())
+	g.Expect(ipAddressesToNodeAddresses(addresses)).To(Equal([]corev1.NodeAddress{
+		{Type: corev1.NodeInternalIP, Address: "10.0.0.2"},
+		{Type: corev1.NodeInternalIP, Address: "10.1.0.2"},
+	}))
+	g.Expect(conditions.IsTrue(machineScope.PacketMachine, infrav1.IPAddressesClaimedCondition)).To(BeTrue())
+}
+
+func TestReconcileDeleteReleasesIPAddressesInOrder(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	// The requests to Equinix Metal and the deletions of the IPAddressClaims, in the order they happened.
+	var calls []string
+	device, err := json.Marshal(map[string]interface{}{
+		"id":    "device",
+		"state": "active",
+		"tags":  packet.DefaultCreateTags(scopetest.Namespace, scopetest.MachineName, scopetest.ClusterName),
+		"network_ports": []map[string]interface{}{{
+			"id":               "bond0",
+			"name":             "bond0",
+			"type":             "NetworkBondPort",
+			"virtual_networks": []map[string]interface{}{{"id": "vlan-storage"}},
+		}},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	metalClient := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet:
+			_, _ = w.Write(device)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			_, _ = w.Write([]byte(`{"id": "bond0"}`))
+		}
+	}))
+
+	packetCluster := &infrav1.PacketCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: scopetest.Namespace, Name: scopetest.ClusterName},
+		Status:     infrav1.PacketClusterStatus{VLANs: []infrav1.VLANStatus{{Name: "storage", ID: "vlan-storage"}}},
+	}
+	packetMachine := &infrav1.PacketMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: scopetest.Namespace, Name: scopetest.MachineName},
+		Spec: infrav1.PacketMachineSpec{
+			ProviderID:         ptr.To("equinixmetal://device"),
+			VLANs:              []string{"storage"},
+			AddressesFromPools: []corev1.TypedLocalObjectReference{{Kind: "InClusterIPPool", Name: "storage"}},
+		},
+	}
+	claim := &ipamv1.IPAddressClaim{ObjectMeta: metav1.ObjectMeta{Namespace: scopetest.Namespace, Name: scopetest.MachineName + "-0"}}
+	machineScope, c, err := scopetest.NewMachineScopeBuilder().
+		WithPacketCluster(packetCluster).
+		WithPacketMachine(packetMachine).
+		WithObjects(claim).
+		Build()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	r := &PacketMachineReconciler{
+		Client: interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				calls = append(calls, "delete IPAddressClaim "+obj.GetName())
+				return c.Delete(ctx, obj, opts...)
+			},
+		}),
+		PacketClient: metalClient,
+	}
+
+	// The device is detached from its VLANs, then its addresses are released, and only then is it deleted.
+	_, err = r.reconcileDelete(ctx, machineScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(calls).To(Equal([]string{
+		"GET /devices/device",
+		"POST /ports/bond0/unassign",
+		"delete IPAddressClaim " + claim.Name,
+		"DELETE /devices/device",
+	}))
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// adoptLegacyDevice looks for a device created for the machine by a packngo based release of the provider, whose tags
+// the device is not found by anymore, and migrates it to the current tags instead of creating a duplicate. It returns
+// nil when there is none.
+func (r *PacketMachineReconciler) adoptLegacyDevice(ctx context.Context, machineScope *scope.MachineScope) (*metal.Device, error) {
+	dev, err := r.metalClient(ctx).GetLegacyDevice(ctx, machineScope.PacketCluster.Spec.ProjectID,
+		machineScope.Cluster.Name, machineScope.Machine.Name, string(machineScope.Machine.UID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up legacy devices: %w", err)
+	}
+	if dev == nil {
+		return nil, nil
+	}
+
+	switch dev.GetState() {
+	case metal.DEVICESTATE_DELETED, metal.DEVICESTATE_DEPROVISIONING:
+		return nil, nil
+	}
+
+	if err := r.metalClient(ctx).MigrateDeviceTags(ctx, dev, machineScope.Namespace(), machineScope.Machine.Name, machineScope.Cluster.Name); err != nil {
+		return nil, err
+	}
+	ctrl.LoggerFrom(ctx).Info("Migrated the legacy tags of device", "device-id", dev.GetId())
+	record.Eventf(machineScope.PacketMachine, "LegacyDeviceAdopted", "Adopted device %s and migrated its legacy tags", dev.GetId())
+	return dev, nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// reconcileMaintenance reports the maintenance Equinix Metal scheduled for the device of a machine with the
+// MaintenanceScheduled condition, cordoning the Node of the machine ahead of it when MaintenanceCordonLeadTime is set
+// and uncordoning it once the maintenance is over. The events of the device are checked every
+// MaintenanceCheckInterval.
+func (r *PacketMachineReconciler) reconcileMaintenance(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) (ctrl.Result, error) {
+	if r.MaintenanceCheckInterval <= 0 {
+		return ctrl.Result{}, nil
+	}
+	result := ctrl.Result{RequeueAfter: r.MaintenanceCheckInterval}
+	now := time.Now()
+	if !r.maintenanceCheckDue(dev.GetId(), now) {
+		return result, nil
+	}
+
+	maintenance, err := r.metalClient(ctx).GetDeviceMaintenance(ctx, dev.GetId())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	packetMachine := machineScope.PacketMachine
+	cordoned := conditions.GetReason(packetMachine, infrav1.MaintenanceScheduledCondition) == infrav1.NodeCordonedForMaintenanceReason
+	if maintenance == nil {
+		if cordoned {
+			if err := r.setNodeUnschedulable(ctx, machineScope, false); err != nil {
+				return ctrl.Result{}, err
+			}
+			record.Eventf(packetMachine, "NodeUncordoned", "Uncordoned the Node of the machine, the maintenance of device %s is over", dev.GetId())
+		}
+		conditions.Delete(packetMachine, infrav1.MaintenanceScheduledCondition)
+		r.forgetMaintenanceCheck(dev.GetId())
+		return result, nil
+	}
+
+	if !conditions.Has(packetMachine, infrav1.MaintenanceScheduledCondition) {
+		record.Warnf(packetMachine, infrav1.MaintenancePendingReason, "Equinix Metal scheduled a maintenance of device %s: %s", dev.GetId(), maintenance.Message)
+	}
+
+	reason := infrav1.MaintenancePendingReason
+	cordonAt, cordon := r.maintenanceCordonTime(packetMachine, maintenance)
+	switch {
+	case cordoned:
+		reason = infrav1.NodeCordonedForMaintenanceReason
+	case cordon && !now.Before(cordonAt):
+		if err := r.setNodeUnschedulable(ctx, machineScope, true); err != nil {
+			return ctrl.Result{}, err
+		}
+		if machineScope.Machine.Status.NodeRef != nil {
+			record.Eventf(packetMachine, infrav1.NodeCordonedForMaintenanceReason, "Cordoned the Node of the machine ahead of the maintenance of device %s", dev.GetId())
+			reason = infrav1.NodeCordonedForMaintenanceReason
+		}
+	case cordon && cordonAt.Sub(now) < r.MaintenanceCheckInterval:
+		// Check again in time to cordon the Node.
+		result.RequeueAfter = cordonAt.Sub(now)
+		r.forgetMaintenanceCheck(dev.GetId())
+	}
+
+	message := "Maintenance of the device in progress: " + maintenance.Message
+	if !maintenance.Started {
+		message = "Maintenance of the device scheduled: " + maintenance.Message
+	}
+	conditions.Set(packetMachine, &clusterv1.Condition{
+		Type:     infrav1.MaintenanceScheduledCondition,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterv1.ConditionSeverityWarning,
+		Reason:   reason,
+		Message:  message,
+	})
+	return result, nil
+}
+
+// maintenanceCordonTime returns when the Node of the machine is to be cordoned ahead of a maintenance, and whether it
+// is to be cordoned at all. Maintenances whose start is unknown are cordoned for right away.
+func (r *PacketMachineReconciler) maintenanceCordonTime(packetMachine *infrav1.PacketMachine, maintenance *packet.DeviceMaintenance) (time.Time, bool) {
+	if r.MaintenanceCordonLeadTime <= 0 || packetMachine.Annotations[infrav1.TolerateMaintenanceAnnotation] == "true" {
+		return time.Time{}, false
+	}
+	if maintenance.Start.IsZero() {
+		return time.Time{}, true
+	}
+	return maintenance.Start.Add(-r.MaintenanceCordonLeadTime), true
+}
+
+// maintenanceCheckDue reports whether the events of the device are to be checked for maintenance, recording the
+// check, so that reconciliations triggered by other changes do not list them again before MaintenanceCheckInterval.
+func (r *PacketMachineReconciler) maintenanceCheckDue(deviceID string, now time.Time) bool {
+	r.maintenanceLock.Lock()
+	defer r.maintenanceLock.Unlock()
+
+	if last, ok := r.maintenanceChecks[deviceID]; ok && now.Sub(last) < r.MaintenanceCheckInterval {
+		return false
+	}
+	if r.maintenanceChecks == nil {
+		r.maintenanceChecks = map[string]time.Time{}
+	}
+	r.maintenanceChecks[deviceID] = now
+	return true
+}
+
+// forgetMaintenanceCheck has the events of the device checked at the next reconciliation.
+func (r *PacketMachineReconciler) forgetMaintenanceCheck(deviceID string) {
+	r.maintenanceLock.Lock()
+	defer r.maintenanceLock.Unlock()
+
+	delete(r.maintenanceChecks, deviceID)
+}
+
+// setNodeUnschedulable cordons, or uncordons, the Node of the machine, if it has one.
+func (r *PacketMachineReconciler) setNodeUnschedulable(ctx context.Context, machineScope *scope.MachineScope, unschedulable bool) error {
+	nodeRef := machineScope.Machine.Status.NodeRef
+	if nodeRef == nil {
+		return nil
+	}
+
+	workloadClient, err := remote.NewClusterClient(ctx, "packetmachine-controller", r.Client, util.ObjectKey(machineScope.Cluster))
+	if err != nil {
+		return fmt.Errorf("failed to create workload cluster client: %w", err)
+	}
+	return setNodeUnschedulable(ctx, workloadClient, nodeRef.Name, unschedulable)
+}
+
+// setNodeUnschedulable cordons, or uncordons, a Node.
+func setNodeUnschedulable(ctx context.Context, workloadClient client.Client, nodeName string, unschedulable bool) error {
+	node := &corev1.Node{}
+	if err := workloadClient.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		return fmt.Errorf("failed to get Node %s: %w", nodeName, err)
+	}
+	if node.Spec.Unschedulable == unschedulable {
+		return nil
+	}
+
+	base := node.DeepCopy()
+	node.Spec.Unschedulable = unschedulable
+	if err := workloadClient.Patch(ctx, node, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to set Node %s unschedulable to %t: %w", nodeName, unschedulable, err)
+	}
+	ctrl.LoggerFrom(ctx).Info("Changed whether the Node is schedulable for the maintenance of its device", "node", nodeName, "unschedulable", unschedulable)
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+)
+
+func TestMaintenanceCordonTime(t *testing.T) {
+	g := NewWithT(t)
+
+	start := time.Date(2024, 5, 3, 2, 0, 0, 0, time.UTC)
+	maintenance := &packet.DeviceMaintenance{Start: start}
+	packetMachine := &infrav1.PacketMachine{}
+
+	// Maintenances are tolerated by default.
+	r := &PacketMachineReconciler{}
+	_, cordon := r.maintenanceCordonTime(packetMachine, maintenance)
+	g.Expect(cordon).To(BeFalse())
+
+	r.MaintenanceCordonLeadTime = time.Hour
+	cordonAt, cordon := r.maintenanceCordonTime(packetMachine, maintenance)
+	g.Expect(cordon).To(BeTrue())
+	g.Expect(cordonAt).To(Equal(start.Add(-time.Hour)))
+
+	// Maintenances of unknown start are cordoned for right away.
+	cordonAt, cordon = r.maintenanceCordonTime(packetMachine, &packet.DeviceMaintenance{})
+	g.Expect(cordon).To(BeTrue())
+	g.Expect(cordonAt.IsZero()).To(BeTrue())
+
+	packetMachine.Annotations = map[string]string{infrav1.TolerateMaintenanceAnnotation: "true"}
+	_, cordon = r.maintenanceCordonTime(packetMachine, maintenance)
+	g.Expect(cordon).To(BeFalse())
+}
+
+func TestMaintenanceCheckDue(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	r := &PacketMachineReconciler{MaintenanceCheckInterval: time.Hour}
+	g.Expect(r.maintenanceCheckDue("device", now)).To(BeTrue())
+	g.Expect(r.maintenanceCheckDue("device", now.Add(time.Minute))).To(BeFalse())
+	g.Expect(r.maintenanceCheckDue("other", now.Add(time.Minute))).To(BeTrue())
+	g.Expect(r.maintenanceCheckDue("device", now.Add(time.Hour))).To(BeTrue())
+
+	r.forgetMaintenanceCheck("device")
+	g.Expect(r.maintenanceCheckDue("device", now.Add(time.Hour+time.Minute))).To(BeTrue())
+}
+
+func TestSetNodeUnschedulable(t *testing.T) {
+	g := NewWithT(t)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	workloadClient := fake.NewClientBuilder().WithObjects(node).Build()
+
+	g.Expect(setNodeUnschedulable(context.Background(), workloadClient, "node", true)).To(Succeed())
+	g.Expect(workloadClient.Get(context.Background(), client.ObjectKey{Name: "node"}, node)).To(Succeed())
+	g.Expect(node.Spec.Unschedulable).To(BeTrue())
+
+	g.Expect(setNodeUnschedulable(context.Background(), workloadClient, "node", false)).To(Succeed())
+	g.Expect(workloadClient.Get(context.Background(), client.ObjectKey{Name: "node"}, node)).To(Succeed())
+	g.Expect(node.Spec.Unschedulable).To(BeFalse())
+
+	g.Expect(setNodeUnschedulable(context.Background(), workloadClient, "missing", true)).ToNot(Succeed())
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+)
+
+// devicePollBaseInterval is how often a device is checked again right after its creation.
+const devicePollBaseInterval = 10 * time.Second
+
+// devicePollInterval returns how long to wait before checking a provisioning device again. The interval starts at
+// devicePollBaseInterval and doubles as the device keeps provisioning, up to maxInterval, so that the many devices of
+// a large cluster do not each poll the API every few seconds for the whole of their provisioning.
+func devicePollInterval(dev *metal.Device, maxInterval time.Duration) time.Duration {
+	if maxInterval <= devicePollBaseInterval || dev.CreatedAt == nil {
+		return devicePollBaseInterval
+	}
+
+	provisioningFor := time.Since(dev.GetCreatedAt())
+	interval := devicePollBaseInterval
+	for interval < maxInterval && 2*interval <= provisioningFor {
+		interval *= 2
+	}
+	return min(interval, maxInterval)
+}
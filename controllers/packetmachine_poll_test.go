@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+)
+
+func TestDevicePollInterval(t *testing.T) {
+	createdAgo := func(d time.Duration) *metal.Device {
+		return &metal.Device{CreatedAt: ptr.To(time.Now().Add(-d))}
+	}
+
+	tests := []struct {
+		name        string
+		dev         *metal.Device
+		maxInterval time.Duration
+		want        time.Duration
+	}{
+		{name: "unknown creation time", dev: &metal.Device{}, maxInterval: time.Minute, want: 10 * time.Second},
+		{name: "just created", dev: createdAgo(5 * time.Second), maxInterval: time.Minute, want: 10 * time.Second},
+		{name: "provisioning for 30s", dev: createdAgo(30 * time.Second), maxInterval: time.Minute, want: 20 * time.Second},
+		{name: "provisioning for 50s", dev: createdAgo(50 * time.Second), maxInterval: time.Minute, want: 40 * time.Second},
+		{name: "provisioning for 10m", dev: createdAgo(10 * time.Minute), maxInterval: time.Minute, want: time.Minute},
+		{name: "longer maximum", dev: createdAgo(10 * time.Minute), maxInterval: 5 * time.Minute, want: 5 * time.Minute},
+		{name: "backoff disabled", dev: createdAgo(10 * time.Minute), maxInterval: 10 * time.Second, want: 10 * time.Second},
+		{name: "unset maximum", dev: createdAgo(10 * time.Minute), want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(devicePollInterval(tt.dev, tt.maxInterval)).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+var (
+	// portConversionLock serializes the port conversions of clusters with a maxConcurrentPortConversions, so that
+	// concurrent reconciliations of PacketMachines do not both take the last one.
+	portConversionLock sync.Mutex
+	// portConversions are the PacketMachines whose port conversion started, until it completes, as their
+	// PortConversionInProgress reason may not be in the cache yet.
+	portConversions = map[types.NamespacedName]bool{}
+)
+
+// isPortConversionInProgress reports whether the VLANs of the device of a PacketMachine were changed and its Node is
+// not healthy again yet.
+func isPortConversionInProgress(packetMachine *infrav1.PacketMachine) bool {
+	return conditions.GetReason(packetMachine, infrav1.VLANsAttachedCondition) == infrav1.PortConversionInProgressReason
+}
+
+// acquirePortConversion reports whether the VLANs of the device of a PacketMachine may be changed, as fewer than the
+// maxConcurrentPortConversions of its cluster are in progress. Machines without a Node yet are not part of the
+// cluster network and are never held back. When it returns true, the conversion must be marked as in progress.
+func (r *PacketMachineReconciler) acquirePortConversion(ctx context.Context, machineScope *scope.MachineScope) (bool, error) {
+	maxConversions := machineScope.PacketCluster.Spec.MaxConcurrentPortConversions
+	if maxConversions == nil || machineScope.Machine.Status.NodeRef == nil {
+		return true, nil
+	}
+
+	portConversionLock.Lock()
+	defer portConversionLock.Unlock()
+
+	machines := &infrav1.PacketMachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(machineScope.Namespace()),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: machineScope.Cluster.Name}); err != nil {
+		return false, err
+	}
+
+	self := types.NamespacedName{Namespace: machineScope.Namespace(), Name: machineScope.Name()}
+	inProgress := 0
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		key := types.NamespacedName{Namespace: machine.Namespace, Name: machine.Name}
+		if key != self && (isPortConversionInProgress(machine) || portConversions[key]) {
+			inProgress++
+		}
+	}
+	if inProgress >= int(*maxConversions) {
+		return false, nil
+	}
+
+	portConversions[self] = true
+	return true, nil
+}
+
+// releasePortConversion forgets the port conversion of a PacketMachine once it completed.
+func releasePortConversion(machineScope *scope.MachineScope) {
+	portConversionLock.Lock()
+	defer portConversionLock.Unlock()
+
+	delete(portConversions, types.NamespacedName{Namespace: machineScope.Namespace(), Name: machineScope.Name()})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// recreateFailedDevice deletes a device that failed to provision, for another one to be created in its place on the
+// next reconciliation, as long as the machine has retries left. It reports whether the device is being recreated.
+func (r *PacketMachineReconciler) recreateFailedDevice(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) (bool, error) {
+	packetMachine := machineScope.PacketMachine
+	if packetMachine.Status.DeviceRetries >= packetMachine.Spec.FailedDeviceRetries {
+		return false, nil
+	}
+	// Once the Machine took over the provider ID of the device, it cannot be given the one of another device.
+	if machineScope.Machine.Spec.ProviderID != nil {
+		return false, nil
+	}
+
+	r.collectBootDiagnostics(ctx, machineScope, dev, "device failed to provision")
+
+	// The device is still listed while it is deprovisioned, and must not be found by the tags of the machine again.
+	if err := r.metalClient(ctx).DetachDevice(ctx, dev); err != nil {
+		return false, err
+	}
+	resp, err := r.metalClient(ctx).DevicesApi.DeleteDevice(ctx, dev.GetId()).ForceDelete(true).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return false, fmt.Errorf("failed to delete failed device %s: %w", dev.GetId(), err)
+	}
+	devicesDeletedTotal.MustCurryWith(machineScope.MetricLabels()).WithLabelValues("packetmachine").Inc()
+
+	packetMachine.Status.DeviceRetries++
+	packetMachine.Status.InstanceStatus = nil
+	packetMachine.Spec.ProviderID = nil
+
+	ctrl.LoggerFrom(ctx).Info("Deleted device that failed to provision", "device-id", dev.GetId(),
+		"retry", packetMachine.Status.DeviceRetries, "max-retries", packetMachine.Spec.FailedDeviceRetries)
+	record.Warnf(packetMachine, "DeviceFailed", "Device %s failed to provision, creating another one (retry %d of %d)",
+		dev.GetId(), packetMachine.Status.DeviceRetries, packetMachine.Spec.FailedDeviceRetries)
+	r.recordAudit(ctx, machineScope, audit.DeviceProvisioningFailed, dev.GetId(), "Device %s failed to provision, deleted it to create another one (retry %d of %d)",
+		dev.GetHostname(), packetMachine.Status.DeviceRetries, packetMachine.Spec.FailedDeviceRetries)
+	conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.InstanceRecreatingReason, clusterv1.ConditionSeverityWarning,
+		"device %s failed to provision, creating another one (retry %d of %d)",
+		dev.GetId(), packetMachine.Status.DeviceRetries, packetMachine.Spec.FailedDeviceRetries)
+	return true, nil
+}
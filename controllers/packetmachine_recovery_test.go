@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+func TestRecreateFailedDevice(t *testing.T) {
+	g := NewWithT(t)
+
+	var requests []string
+	client := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "failed"}`))
+	}))
+	r := &PacketMachineReconciler{PacketClient: client}
+
+	dev := &metal.Device{Id: ptr.To("failed"), Tags: packet.DefaultCreateTags("default", "machine", "cluster")}
+	machineScope := &scope.MachineScope{
+		Cluster:       &clusterv1.Cluster{},
+		Machine:       &clusterv1.Machine{},
+		PacketCluster: &infrav1.PacketCluster{},
+		PacketMachine: &infrav1.PacketMachine{
+			Spec: infrav1.PacketMachineSpec{ProviderID: ptr.To("equinixmetal://failed"), FailedDeviceRetries: 1},
+		},
+	}
+
+	recreate, err := r.recreateFailedDevice(context.Background(), machineScope, dev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(recreate).To(BeTrue())
+	g.Expect(requests).To(Equal([]string{"PUT /devices/failed", "DELETE /devices/failed"}))
+	g.Expect(dev.Tags).ToNot(ContainElement(packet.GenerateMachineNameTag("machine")))
+	g.Expect(machineScope.PacketMachine.Spec.ProviderID).To(BeNil())
+	g.Expect(machineScope.PacketMachine.Status.DeviceRetries).To(Equal(int32(1)))
+	g.Expect(conditions.GetReason(machineScope.PacketMachine, infrav1.DeviceReadyCondition)).To(Equal(infrav1.InstanceRecreatingReason))
+
+	// The retries are exhausted.
+	requests = nil
+	recreate, err = r.recreateFailedDevice(context.Background(), machineScope, dev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(recreate).To(BeFalse())
+	g.Expect(requests).To(BeEmpty())
+
+	// Machines that already took over the provider ID are not given another device.
+	machineScope.PacketMachine.Spec.FailedDeviceRetries = 2
+	machineScope.Machine.Spec.ProviderID = ptr.To("equinixmetal://failed")
+	recreate, err = r.recreateFailedDevice(context.Background(), machineScope, dev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(recreate).To(BeFalse())
+	g.Expect(requests).To(BeEmpty())
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+var errDeviceNotAdoptable = errors.New("device cannot be adopted")
+
+// adoptReleasedDevice adopts the device named by the adopt-device annotation of the PacketMachine or, when the
+// annotation is empty, the oldest compatible device released for the MachineDeployment of the machine. It returns nil
+// when there is nothing to adopt.
+func (r *PacketMachineReconciler) adoptReleasedDevice(ctx context.Context, machineScope *scope.MachineScope) (*metal.Device, error) {
+	log := ctrl.LoggerFrom(ctx)
+	packetMachine := machineScope.PacketMachine
+	deviceID, ok := packetMachine.Annotations[infrav1.AdoptDeviceAnnotation]
+	machineDeployment := machineScope.Machine.Labels[clusterv1.MachineDeploymentNameLabel]
+	if !ok || (deviceID == "" && machineDeployment == "") {
+		return nil, nil
+	}
+
+	// Machines of the same MachineDeployment are reconciled concurrently and must not adopt the same device.
+	r.adoptLock.Lock()
+	defer r.adoptLock.Unlock()
+
+	var candidates []metal.Device
+	if deviceID != "" {
+		dev, _, err := r.metalClient(ctx).GetDevice(ctx, deviceID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve device %s to adopt: %w", deviceID, err)
+		}
+		candidates = append(candidates, *dev)
+	} else {
+		released, err := r.metalClient(ctx).GetReleasedDevices(ctx,
+			machineScope.PacketCluster.Spec.ProjectID, machineScope.Namespace(), machineScope.Cluster.Name, machineDeployment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the devices released for MachineDeployment %s: %w", machineDeployment, err)
+		}
+		candidates = released
+	}
+
+	for i := range candidates {
+		dev := &candidates[i]
+		if err := checkAdoptable(machineScope, dev); err != nil {
+			if deviceID != "" {
+				// The device was asked for explicitly, so no other device is created in its place.
+				record.Warnf(packetMachine, "DeviceNotAdoptable", "Device %s cannot be adopted: %s", dev.GetId(), err)
+				return nil, err
+			}
+			log.V(4).Info("Skipping released device", "device-id", dev.GetId(), "reason", err.Error())
+			continue
+		}
+
+		dev, err := r.metalClient(ctx).AdoptDevice(ctx, dev, machineScope.Namespace(), machineScope.Machine.Name, machineScope.Cluster.Name)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Adopted released device", "device-id", dev.GetId())
+		record.Eventf(packetMachine, "DeviceAdopted", "Adopted released device %s", dev.GetId())
+
+		// The Node of the device was deleted with its previous Machine, restarting the kubelet registers it again.
+		if dev.GetState() == metal.DEVICESTATE_ACTIVE {
+			if err := r.metalClient(ctx).RebootDevice(ctx, dev.GetId()); err != nil {
+				record.Warnf(packetMachine, "DeviceRebootFailed",
+					"Failed to reboot adopted device %s, reboot it for its Node to register again: %s", dev.GetId(), err)
+			}
+		}
+		return dev, nil
+	}
+
+	return nil, nil
+}
+
+// checkAdoptable returns an error when the device was not released by a machine of the cluster of the PacketMachine,
+// or could not have been created for it.
+func checkAdoptable(machineScope *scope.MachineScope, dev *metal.Device) error {
+	spec := machineScope.PacketMachine.Spec
+
+	if !packet.IsReleased(dev) {
+		return packet.ErrDeviceNotReleased
+	}
+	if !packet.ItemsInList(dev.Tags, []string{
+		packet.GenerateClusterTag(machineScope.Cluster.Name),
+		packet.GenerateNamespaceTag(machineScope.Namespace()),
+	}) {
+		return fmt.Errorf("%w: it belongs to another cluster", errDeviceNotAdoptable)
+	}
+
+	switch dev.GetState() {
+	case metal.DEVICESTATE_DELETED, metal.DEVICESTATE_DEPROVISIONING, metal.DEVICESTATE_FAILED:
+		return fmt.Errorf("%w: it is %s", errDeviceNotAdoptable, dev.GetState())
+	}
+
+	role := infrav1.WorkerTag
+	if machineScope.IsControlPlane() {
+		role = infrav1.ControlPlaneTag
+	}
+	if !packet.ItemsInList(dev.Tags, []string{role}) {
+		return fmt.Errorf("%w: it is not tagged %s", errDeviceNotAdoptable, role)
+	}
+
+	if plan := dev.GetPlan(); !infrav1.IsTemplated(spec.MachineType) && plan.GetSlug() != spec.MachineType {
+		return fmt.Errorf("%w: its plan %s is not %s", errDeviceNotAdoptable, plan.GetSlug(), spec.MachineType)
+	}
+	if err := checkDeviceLocation(spec, dev); err != nil {
+		return fmt.Errorf("%w: %w", errDeviceNotAdoptable, err)
+	}
+	return nil
+}
+
+// releaseDevice keeps the device of a deleted PacketMachine for another machine to adopt, instead of deleting it.
+func (r *PacketMachineReconciler) releaseDevice(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) (ctrl.Result, error) {
+	packetMachine := machineScope.PacketMachine
+	machineDeployment := packetMachine.Annotations[infrav1.ReleaseDeviceAnnotation]
+
+	if err := r.metalClient(ctx).ReleaseDevice(ctx, dev, machineDeployment); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to release device: %w", err)
+	}
+
+	ctrl.LoggerFrom(ctx).Info("Released device", "device-id", dev.GetId(), "machine-deployment", machineDeployment)
+	record.Eventf(packetMachine, "DeviceReleased", "Released device %s instead of deleting it", dev.GetId())
+	controllerutil.RemoveFinalizer(packetMachine, infrav1.MachineFinalizer)
+	return ctrl.Result{}, nil
+}
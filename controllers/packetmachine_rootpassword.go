@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+const (
+	// rootPasswordKey is the Secret key the root password of a device is stored under.
+	rootPasswordKey = "password"
+	// rootPasswordLabel marks the Secrets holding root passwords.
+	rootPasswordLabel = "infrastructure.cluster.x-k8s.io/packet-root-password"
+)
+
+// rootPasswordName returns the name of the Secret holding the root password of the device of a PacketMachine.
+func rootPasswordName(machineScope *scope.MachineScope) string {
+	return machineScope.Name() + "-root-password"
+}
+
+// reconcileRootPassword stores the root password of a device in a Secret next to its PacketMachine. Equinix Metal only
+// returns the password of operating systems that set one, for a limited time after provisioning, so it is stored once
+// and never updated. The Secret is owned by the PacketMachine and deleted with it.
+func (r *PacketMachineReconciler) reconcileRootPassword(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) error {
+	password := dev.GetRootPassword()
+	if !r.RootPasswordSecrets || password == "" {
+		return nil
+	}
+
+	key := client.ObjectKey{Namespace: machineScope.Namespace(), Name: rootPasswordName(machineScope)}
+	if err := r.Client.Get(ctx, key, &corev1.Secret{}); err == nil {
+		return nil
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	packetMachine := machineScope.PacketMachine
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels: map[string]string{
+				clusterv1.ClusterNameLabel: machineScope.Cluster.Name,
+				rootPasswordLabel:          "",
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: infrav1.GroupVersion.String(),
+				Kind:       "PacketMachine",
+				Name:       packetMachine.Name,
+				UID:        packetMachine.UID,
+				Controller: ptr.To(true),
+			}},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			rootPasswordKey: []byte(password),
+		},
+	}
+	if err := r.Client.Create(ctx, secret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	ctrl.LoggerFrom(ctx).Info("Stored the device root password", "secret", key.Name)
+	record.Eventf(packetMachine, "RootPasswordStored", "Root password of device %s stored in Secret %s", dev.GetId(), key.Name)
+	return nil
+}
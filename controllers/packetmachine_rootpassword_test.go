@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func TestReconcileRootPassword(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	r := &PacketMachineReconciler{Client: fake.NewClientBuilder().WithScheme(scopetest.Scheme()).Build()}
+	machineScope := &scope.MachineScope{
+		Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "capi"}},
+		PacketMachine: &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine", UID: "uid"}},
+	}
+	dev := &metal.Device{Id: ptr.To("device"), RootPassword: ptr.To("secret")}
+	key := client.ObjectKey{Namespace: "default", Name: "machine-root-password"}
+
+	// Nothing is stored unless enabled.
+	g.Expect(r.reconcileRootPassword(ctx, machineScope, dev)).To(Succeed())
+	g.Expect(r.Client.Get(ctx, key, &corev1.Secret{})).NotTo(Succeed())
+
+	r.RootPasswordSecrets = true
+	g.Expect(r.reconcileRootPassword(ctx, machineScope, dev)).To(Succeed())
+	secret := &corev1.Secret{}
+	g.Expect(r.Client.Get(ctx, key, secret)).To(Succeed())
+	g.Expect(secret.Data).To(HaveKeyWithValue(rootPasswordKey, []byte("secret")))
+	g.Expect(secret.OwnerReferences).To(HaveLen(1))
+	g.Expect(secret.OwnerReferences[0].UID).To(BeEquivalentTo("uid"))
+
+	// The stored password is kept once the API no longer returns it.
+	g.Expect(r.reconcileRootPassword(ctx, machineScope, &metal.Device{Id: ptr.To("device")})).To(Succeed())
+	g.Expect(r.Client.Get(ctx, key, secret)).To(Succeed())
+	g.Expect(secret.Data).To(HaveKeyWithValue(rootPasswordKey, []byte("secret")))
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// spotReclaimTime returns when Equinix Metal reclaims a spot market device, or nil when it is not reclaimed. Equinix
+// Metal sets the termination time of the spot devices that are outbid; the termination time of the PacketMachine spec
+// the device was created with is not a reclaim.
+func spotReclaimTime(spec infrav1.PacketMachineSpec, dev *metal.Device) *time.Time {
+	if !dev.GetSpotInstance() || dev.TerminationTime == nil {
+		return nil
+	}
+	if spec.TerminationTime != nil && spec.TerminationTime.Time.Equal(dev.TerminationTime.Truncate(time.Second)) {
+		return nil
+	}
+	return dev.TerminationTime
+}
+
+// reconcileSpotReclaim marks the machine of a spot market device that Equinix Metal is reclaiming as failed, for the
+// MachineHealthChecks of the cluster to replace it before the device is deleted. It reports whether the device is
+// reclaimed.
+func (r *PacketMachineReconciler) reconcileSpotReclaim(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) bool {
+	reclaimAt := spotReclaimTime(machineScope.PacketMachine.Spec, dev)
+	if reclaimAt == nil {
+		return false
+	}
+
+	packetMachine := machineScope.PacketMachine
+	if conditions.GetReason(packetMachine, infrav1.DeviceReadyCondition) != infrav1.InstanceSpotReclaimedReason {
+		ctrl.LoggerFrom(ctx).Info("Spot market device is reclaimed", "device-id", dev.GetId(), "termination-time", reclaimAt)
+		record.Warnf(packetMachine, "SpotInstanceReclaimed", "Spot market device %s is reclaimed by Equinix Metal at %s",
+			dev.GetId(), reclaimAt.Format(time.RFC3339))
+	}
+	machineScope.SetFailureReason(capierrors.UpdateMachineError)
+	machineScope.SetFailureMessage(fmt.Errorf("spot market device %s is reclaimed at %s", dev.GetId(), reclaimAt.Format(time.RFC3339))) //nolint:goerr113
+	conditions.MarkFalse(packetMachine, infrav1.DeviceReadyCondition, infrav1.InstanceSpotReclaimedReason, clusterv1.ConditionSeverityWarning,
+		"spot market device %s is reclaimed at %s", dev.GetId(), reclaimAt.Format(time.RFC3339))
+	return true
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestSpotReclaimTime(t *testing.T) {
+	g := NewWithT(t)
+
+	planned := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	outbid := planned.Add(-time.Hour)
+
+	// On-demand devices are not reclaimed, whatever their termination time.
+	g.Expect(spotReclaimTime(infrav1.PacketMachineSpec{}, &metal.Device{TerminationTime: &outbid})).To(BeNil())
+
+	// Spot devices without termination time run until deleted.
+	g.Expect(spotReclaimTime(infrav1.PacketMachineSpec{}, &metal.Device{SpotInstance: ptr.To(true)})).To(BeNil())
+
+	// The termination time the device was created with is not a reclaim.
+	spec := infrav1.PacketMachineSpec{TerminationTime: &metav1.Time{Time: planned}}
+	g.Expect(spotReclaimTime(spec, &metal.Device{SpotInstance: ptr.To(true), TerminationTime: &planned})).To(BeNil())
+
+	// Another termination time is set when the device is outbid.
+	g.Expect(spotReclaimTime(spec, &metal.Device{SpotInstance: ptr.To(true), TerminationTime: &outbid})).To(Equal(&outbid))
+	g.Expect(spotReclaimTime(infrav1.PacketMachineSpec{}, &metal.Device{SpotInstance: ptr.To(true), TerminationTime: &outbid})).To(Equal(&outbid))
+}
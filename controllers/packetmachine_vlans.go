@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// vlanWaitInterval is how often PacketMachines are reconciled while the VLANs they refer to are not created yet.
+const vlanWaitInterval = 30 * time.Second
+
+// portConversionSettleTime is how long after the VLANs of a device changed its Node must still be healthy for the
+// port conversion to be complete.
+const portConversionSettleTime = time.Minute
+
+// reconcileVLANs attaches the bond port of a device to the VLANs of its PacketMachine, and detaches it from the other
+// VLANs of the cluster. VLANs not managed by the cluster are left alone.
+func (r *PacketMachineReconciler) reconcileVLANs(ctx context.Context, machineScope *scope.MachineScope, dev *metal.Device) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	packetMachine := machineScope.PacketMachine
+	packetCluster := machineScope.PacketCluster
+
+	wanted := map[string]bool{}
+	var missing []string
+	for _, name := range packetMachine.Spec.VLANs {
+		wanted[name] = true
+		if packetCluster.VLANStatus(name) == nil {
+			missing = append(missing, name)
+		}
+	}
+	if len(wanted) == 0 && len(packetCluster.Status.VLANs) == 0 {
+		conditions.Delete(packetMachine, infrav1.VLANsAttachedCondition)
+		return ctrl.Result{}, nil
+	}
+
+	port, err := packet.DeviceBondPort(dev)
+	if err != nil && len(wanted) == 0 {
+		conditions.Delete(packetMachine, infrav1.VLANsAttachedCondition)
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		conditions.MarkFalse(packetMachine, infrav1.VLANsAttachedCondition, infrav1.VLANAttachFailedReason, clusterv1.ConditionSeverityError, "%s", err)
+		return ctrl.Result{}, nil
+	}
+	attached := map[string]bool{}
+	for _, vn := range port.VirtualNetworks {
+		attached[vn.GetId()] = true
+	}
+
+	var attach, detach []infrav1.VLANStatus
+	for _, vlan := range packetCluster.Status.VLANs {
+		switch {
+		case wanted[vlan.Name] && !attached[vlan.ID]:
+			attach = append(attach, vlan)
+		case !wanted[vlan.Name] && attached[vlan.ID]:
+			detach = append(detach, vlan)
+		}
+	}
+
+	switch {
+	case len(attach) > 0 || len(detach) > 0:
+		acquired, err := r.acquirePortConversion(ctx, machineScope)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !acquired {
+			conditions.MarkFalse(packetMachine, infrav1.VLANsAttachedCondition, infrav1.WaitingForPortConversionReason, clusterv1.ConditionSeverityInfo,
+				"waiting for the port conversions of %d other machines of the cluster to complete", *packetCluster.Spec.MaxConcurrentPortConversions)
+			return ctrl.Result{RequeueAfter: vlanWaitInterval}, nil
+		}
+
+		for _, vlan := range attach {
+			if err := r.metalClient(ctx).AttachVLAN(ctx, port.GetId(), vlan.ID); err != nil {
+				conditions.MarkFalse(packetMachine, infrav1.VLANsAttachedCondition, infrav1.VLANAttachFailedReason, clusterv1.ConditionSeverityWarning,
+					"failed to attach VLAN %s: %s", vlan.Name, err)
+				return ctrl.Result{}, err
+			}
+			log.Info("Attached device to VLAN", "device-id", dev.GetId(), "vlan", vlan.Name)
+			record.Eventf(packetMachine, "VLANAttached", "Attached device %s to VLAN %s", dev.GetId(), vlan.Name)
+			r.recordAudit(ctx, machineScope, audit.VLANAttached, vlan.ID, "Attached device %s to VLAN %s", dev.GetId(), vlan.Name)
+		}
+		for _, vlan := range detach {
+			if err := r.metalClient(ctx).DetachVLAN(ctx, port.GetId(), vlan.ID); err != nil {
+				conditions.MarkFalse(packetMachine, infrav1.VLANsAttachedCondition, infrav1.VLANAttachFailedReason, clusterv1.ConditionSeverityWarning,
+					"failed to detach VLAN %s: %s", vlan.Name, err)
+				return ctrl.Result{}, err
+			}
+			log.Info("Detached device from VLAN", "device-id", dev.GetId(), "vlan", vlan.Name)
+			record.Eventf(packetMachine, "VLANDetached", "Detached device %s from VLAN %s", dev.GetId(), vlan.Name)
+			r.recordAudit(ctx, machineScope, audit.VLANDetached, vlan.ID, "Detached device %s from VLAN %s", dev.GetId(), vlan.Name)
+		}
+
+		if packetCluster.Spec.MaxConcurrentPortConversions != nil && machineScope.Machine.Status.NodeRef != nil {
+			conditions.MarkFalse(packetMachine, infrav1.VLANsAttachedCondition, infrav1.PortConversionInProgressReason, clusterv1.ConditionSeverityInfo,
+				"VLANs of device %s changed, waiting for the Node to be healthy", dev.GetId())
+			return ctrl.Result{RequeueAfter: vlanWaitInterval}, nil
+		}
+	case isPortConversionInProgress(packetMachine):
+		// The Node may still be reported healthy right after the change, give it time to notice a broken network.
+		changedAt := conditions.GetLastTransitionTime(packetMachine, infrav1.VLANsAttachedCondition)
+		if (changedAt != nil && time.Since(changedAt.Time) < portConversionSettleTime) ||
+			!conditions.IsTrue(machineScope.Machine, clusterv1.MachineNodeHealthyCondition) {
+			return ctrl.Result{RequeueAfter: vlanWaitInterval}, nil
+		}
+		releasePortConversion(machineScope)
+		log.Info("Port conversion completed", "device-id", dev.GetId())
+		record.Eventf(packetMachine, "PortConversionCompleted", "Node of device %s is healthy after its VLANs changed", dev.GetId())
+	}
+
+	if len(missing) > 0 {
+		conditions.MarkFalse(packetMachine, infrav1.VLANsAttachedCondition, infrav1.WaitingForVLANReason, clusterv1.ConditionSeverityInfo,
+			"VLANs %s are not created by the PacketCluster", strings.Join(missing, ", "))
+		return ctrl.Result{RequeueAfter: vlanWaitInterval}, nil
+	}
+	conditions.MarkTrue(packetMachine, infrav1.VLANsAttachedCondition)
+	return ctrl.Result{}, nil
+}
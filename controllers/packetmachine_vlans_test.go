@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func TestReconcileMachineVLANs(t *testing.T) {
+	g := NewWithT(t)
+
+	var requests []string
+	client := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "bond0"}`))
+	}))
+	sink := &recordingSink{}
+	r := &PacketMachineReconciler{PacketClient: client, Audit: audit.NewEventAggregator(sink)}
+
+	packetCluster := &infrav1.PacketCluster{Status: infrav1.PacketClusterStatus{VLANs: []infrav1.VLANStatus{
+		{Name: "storage", ID: "vlan-storage"},
+		{Name: "backup", ID: "vlan-backup"},
+	}}}
+	machineScope := &scope.MachineScope{
+		Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "capi"}},
+		Machine:       &clusterv1.Machine{},
+		PacketCluster: packetCluster,
+		PacketMachine: &infrav1.PacketMachine{Spec: infrav1.PacketMachineSpec{VLANs: []string{"storage", "metrics"}}},
+	}
+	dev := &metal.Device{
+		Id: ptr.To("device"),
+		NetworkPorts: []metal.Port{{
+			Id:              ptr.To("bond0"),
+			Name:            ptr.To("bond0"),
+			Type:            ptr.To(metal.PORTTYPE_NETWORK_BOND_PORT),
+			VirtualNetworks: []metal.VirtualNetwork{{Id: ptr.To("vlan-backup")}},
+		}},
+	}
+	ctx := context.Background()
+
+	// The device is attached to the VLANs of the machine, detached from the other VLANs of the cluster, and waits
+	// for the VLANs the cluster did not create yet.
+	result, err := r.reconcileVLANs(ctx, machineScope, dev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(vlanWaitInterval))
+	g.Expect(requests).To(Equal([]string{"POST /ports/bond0/assign", "POST /ports/bond0/unassign"}))
+	g.Expect(conditions.GetReason(machineScope.PacketMachine, infrav1.VLANsAttachedCondition)).To(Equal(infrav1.WaitingForVLANReason))
+	g.Expect(sink.actions).To(Equal([]audit.Action{audit.VLANAttached, audit.VLANDetached}))
+
+	// Once the VLANs exist and are attached, nothing is left to do.
+	packetCluster.Status.VLANs = append(packetCluster.Status.VLANs, infrav1.VLANStatus{Name: "metrics", ID: "vlan-metrics"})
+	dev.NetworkPorts[0].VirtualNetworks = []metal.VirtualNetwork{{Id: ptr.To("vlan-storage")}, {Id: ptr.To("vlan-metrics")}}
+	requests = nil
+	result, err = r.reconcileVLANs(ctx, machineScope, dev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.IsZero()).To(BeTrue())
+	g.Expect(requests).To(BeEmpty())
+	g.Expect(conditions.IsTrue(machineScope.PacketMachine, infrav1.VLANsAttachedCondition)).To(BeTrue())
+}
+
+func TestReconcileMachineVLANsPortConversions(t *testing.T) {
+	g := NewWithT(t)
+
+	var requests []string
+	client := packettest.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "bond0"}`))
+	}))
+
+	labels := map[string]string{clusterv1.ClusterNameLabel: "capi"}
+	converting := &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "converting", Labels: labels}}
+	conditions.MarkFalse(converting, infrav1.VLANsAttachedCondition, infrav1.PortConversionInProgressReason, clusterv1.ConditionSeverityInfo, "")
+	packetMachine := &infrav1.PacketMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine", Labels: labels},
+		Spec:       infrav1.PacketMachineSpec{VLANs: []string{"storage"}},
+	}
+
+	r := &PacketMachineReconciler{
+		Client:       fake.NewClientBuilder().WithScheme(scopetest.Scheme()).WithObjects(converting, packetMachine).Build(),
+		PacketClient: client,
+	}
+
+	machineScope := &scope.MachineScope{
+		Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "capi"}},
+		Machine: &clusterv1.Machine{Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "node"}}},
+		PacketCluster: &infrav1.PacketCluster{
+			Spec:   infrav1.PacketClusterSpec{MaxConcurrentPortConversions: ptr.To[int32](1)},
+			Status: infrav1.PacketClusterStatus{VLANs: []infrav1.VLANStatus{{Name: "storage", ID: "vlan-storage"}}},
+		},
+		PacketMachine: packetMachine,
+	}
+	dev := &metal.Device{
+		Id: ptr.To("device"),
+		NetworkPorts: []metal.Port{{
+			Id:   ptr.To("bond0"),
+			Name: ptr.To("bond0"),
+			Type: ptr.To(metal.PORTTYPE_NETWORK_BOND_PORT),
+		}},
+	}
+	ctx := context.Background()
+
+	// The machine waits while another machine of the cluster is converting.
+	result, err := r.reconcileVLANs(ctx, machineScope, dev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(vlanWaitInterval))
+	g.Expect(requests).To(BeEmpty())
+	g.Expect(conditions.GetReason(packetMachine, infrav1.VLANsAttachedCondition)).To(Equal(infrav1.WaitingForPortConversionReason))
+
+	// Once it completed, the VLANs of the device are changed and the conversion is in progress until the Node is
+	// healthy again.
+	conditions.MarkTrue(converting, infrav1.VLANsAttachedCondition)
+	g.Expect(r.Client.Update(ctx, converting)).To(Succeed())
+	result, err = r.reconcileVLANs(ctx, machineScope, dev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(vlanWaitInterval))
+	g.Expect(requests).To(Equal([]string{"POST /ports/bond0/assign"}))
+	g.Expect(conditions.GetReason(packetMachine, infrav1.VLANsAttachedCondition)).To(Equal(infrav1.PortConversionInProgressReason))
+
+	dev.NetworkPorts[0].VirtualNetworks = []metal.VirtualNetwork{{Id: ptr.To("vlan-storage")}}
+	result, err = r.reconcileVLANs(ctx, machineScope, dev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(vlanWaitInterval))
+	g.Expect(isPortConversionInProgress(packetMachine)).To(BeTrue())
+
+	conditions.MarkTrue(machineScope.Machine, clusterv1.MachineNodeHealthyCondition)
+	for i := range packetMachine.Status.Conditions {
+		packetMachine.Status.Conditions[i].LastTransitionTime = metav1.NewTime(time.Now().Add(-portConversionSettleTime))
+	}
+	result, err = r.reconcileVLANs(ctx, machineScope, dev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.IsZero()).To(BeTrue())
+	g.Expect(conditions.IsTrue(packetMachine, infrav1.VLANsAttachedCondition)).To(BeTrue())
+	g.Expect(portConversions).To(BeEmpty())
+}
@@ -0,0 +1,424 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	expclusterv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	exputil "sigs.k8s.io/cluster-api/exp/util"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/sharding"
+	packet "sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// machinePoolProvisioningRequeue is how often machine pools are reconciled while their devices are provisioned.
+const machinePoolProvisioningRequeue = 30 * time.Second
+
+// PacketMachinePoolReconciler reconciles a PacketMachinePool object.
+type PacketMachinePoolReconciler struct {
+	client.Client
+	PacketClient *packet.Client
+
+	// WatchFilterValue is the label value used to filter events prior to reconciliation.
+	WatchFilterValue string
+
+	// Shard, when set, restricts the reconciled objects to the clusters of a shard.
+	Shard *sharding.Shard
+
+	// Audit, when set, records the changes made to the infrastructure of the clusters.
+	Audit *audit.EventAggregator
+
+	// ProviderIDPrefix is the prefix of the providerIDs of the devices, one of scope.ProviderIDFormats.
+	// Defaults to scope.ProviderIDPrefix.
+	ProviderIDPrefix string
+
+	// APICallWarningThreshold, when set, is the number of Equinix Metal API calls above which a reconciliation is
+	// reported with a warning event listing the calls by endpoint.
+	APICallWarningThreshold int
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=packetmachinepools,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=packetmachinepools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinepools;machinepools/status,verbs=get;list;watch
+
+func (r *PacketMachinePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Fetch the PacketMachinePool instance.
+	packetMachinePool := &infrav1.PacketMachinePool{}
+	if err := r.Client.Get(ctx, req.NamespacedName, packetMachinePool); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Fetch the MachinePool.
+	machinePool, err := exputil.GetOwnerMachinePool(ctx, r.Client, packetMachinePool.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get owner machine pool: %w", err)
+	}
+	if machinePool == nil {
+		log.Info("MachinePool Controller has not yet set OwnerRef")
+		return ctrl.Result{}, nil
+	}
+
+	log = log.WithValues("MachinePool", klog.KObj(machinePool))
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	// Fetch the Cluster.
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, machinePool.ObjectMeta)
+	if err != nil {
+		log.Info("MachinePool is missing cluster label or cluster does not exist")
+		return ctrl.Result{}, err
+	}
+
+	log = log.WithValues("Cluster", klog.KObj(cluster))
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	// Return early if the object or Cluster is paused.
+	if annotations.IsPaused(cluster, packetMachinePool) {
+		log.Info("PacketMachinePool or linked Cluster is marked as paused. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	// Fetch the Packet Cluster
+	packetCluster := &infrav1.PacketCluster{}
+	packetClusterNamespacedName := client.ObjectKey{
+		Namespace: packetMachinePool.Namespace,
+		Name:      cluster.Spec.InfrastructureRef.Name,
+	}
+	if err := r.Client.Get(ctx, packetClusterNamespacedName, packetCluster); err != nil {
+		log.Info("PacketCluster is not available yet")
+		return ctrl.Result{}, nil
+	}
+
+	poolScope, err := scope.NewMachinePoolScope(scope.MachinePoolScopeParams{
+		Client:            r.Client,
+		Cluster:           cluster,
+		MachinePool:       machinePool,
+		PacketCluster:     packetCluster,
+		PacketMachinePool: packetMachinePool,
+	})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create scope: %w", err)
+	}
+
+	// Always close the scope when exiting this function so we can persist any PacketMachinePool changes.
+	defer func() {
+		if err := poolScope.Close(ctx); err != nil && rerr == nil {
+			log.Error(err, "failed to patch packetmachinepool")
+			rerr = err
+		}
+	}()
+
+	// Account the Equinix Metal API calls made for this pool against the budget of its cluster, and label their
+	// metrics with it.
+	budgetKey := util.ObjectKey(cluster).String()
+	ctx = packet.WithClusterBudget(ctx, budgetKey)
+	ctx = packet.WithMetricLabels(ctx, poolScope.MetricLabels())
+	defer reconcileThrottledCondition(r.PacketClient, packetMachinePool, budgetKey)
+	ctx, apiCalls := packet.WithAPICallAccounting(ctx)
+	defer reportAPICalls(packetMachinePool, apiCalls, r.APICallWarningThreshold)
+
+	// Manage the devices with the credentials of the cluster, if it has any.
+	metalClient, err := r.PacketClient.ClientForCluster(ctx, r.Client, packetCluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	ctx = packet.WithClient(ctx, metalClient)
+
+	// Handle deleted machine pools
+	if !packetMachinePool.ObjectMeta.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, poolScope)
+	}
+
+	// Add finalizer first if not set to avoid the race condition between init and delete.
+	if !controllerutil.ContainsFinalizer(packetMachinePool, infrav1.MachinePoolFinalizer) {
+		controllerutil.AddFinalizer(packetMachinePool, infrav1.MachinePoolFinalizer)
+		return ctrl.Result{}, nil
+	}
+	return r.reconcile(ctx, poolScope)
+}
+
+func (r *PacketMachinePoolReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	clusterToPacketMachinePools, err := util.ClusterToTypedObjectsMapper(mgr.GetClient(), &infrav1.PacketMachinePoolList{}, mgr.GetScheme())
+	if err != nil {
+		return fmt.Errorf("failed to create mapper for Cluster to PacketMachinePools: %w", err)
+	}
+
+	err = ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.PacketMachinePool{}).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(log, r.WatchFilterValue)).
+		WithEventFilter(r.Shard.Predicate(log)).
+		Watches(
+			&expclusterv1.MachinePool{},
+			handler.EnqueueRequestsFromMapFunc(exputil.MachinePoolToInfrastructureMapFunc(infrav1.GroupVersion.WithKind("PacketMachinePool"), log)),
+		).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(clusterToPacketMachinePools),
+		).Complete(r)
+	if err != nil {
+		return fmt.Errorf("failed setting up with a controller manager: %w", err)
+	}
+	return nil
+}
+
+func (r *PacketMachinePoolReconciler) reconcile(ctx context.Context, poolScope *scope.MachinePoolScope) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	packetMachinePool := poolScope.PacketMachinePool
+
+	if !poolScope.Cluster.Status.InfrastructureReady {
+		log.Info("Cluster infrastructure is not ready yet")
+		conditions.MarkFalse(packetMachinePool, infrav1.DevicesReadyCondition, infrav1.WaitingForClusterInfrastructureReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, nil
+	}
+
+	devices, err := r.devices(ctx, poolScope)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Devices that failed to provision are replaced.
+	var healthy []metal.Device
+	for i := range devices {
+		if devices[i].GetState() == metal.DEVICESTATE_FAILED {
+			if err := r.deleteDevice(ctx, poolScope, &devices[i], "failed to provision"); err != nil {
+				return ctrl.Result{}, err
+			}
+			continue
+		}
+		healthy = append(healthy, devices[i])
+	}
+	devices = healthy
+
+	desired := int(poolScope.DesiredReplicas())
+	switch {
+	case len(devices) == desired:
+		conditions.MarkFalse(packetMachinePool, infrav1.DevicesReadyCondition, infrav1.InstanceNotReadyReason, clusterv1.ConditionSeverityInfo,
+			"%d of %d devices are running", countRunning(devices), desired)
+	case len(devices) < desired:
+		if poolScope.MachinePool.Spec.Template.Spec.Bootstrap.DataSecretName == nil {
+			log.Info("Bootstrap data secret is not yet available")
+			conditions.MarkFalse(packetMachinePool, infrav1.DevicesReadyCondition, infrav1.WaitingForBootstrapDataReason, clusterv1.ConditionSeverityInfo, "")
+			r.setInstances(poolScope, devices)
+			return ctrl.Result{}, nil
+		}
+		reserved, releaseDeviceQuota, err := reserveDevices(ctx, r.metalClient(ctx), poolScope.Cluster, poolScope.PacketCluster, desired-len(devices))
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to check the device quota of the cluster: %w", err)
+		}
+		defer releaseDeviceQuota()
+		target := len(devices) + reserved
+		if target == len(devices) {
+			maxDevices := *poolScope.PacketCluster.Spec.MaxDevices
+			if conditions.GetReason(packetMachinePool, infrav1.DevicesReadyCondition) != infrav1.DeviceQuotaExceededReason {
+				record.Warnf(packetMachinePool, infrav1.DeviceQuotaExceededReason, "Cluster %s has reached its maximum of %d devices", poolScope.Cluster.Name, maxDevices)
+			}
+			conditions.MarkFalse(packetMachinePool, infrav1.DevicesReadyCondition, infrav1.DeviceQuotaExceededReason, clusterv1.ConditionSeverityWarning,
+				"Cluster has reached its maximum of %d devices, %d of %d devices are created", maxDevices, len(devices), desired)
+			r.setInstances(poolScope, devices)
+			return ctrl.Result{RequeueAfter: deviceQuotaRequeue}, nil
+		}
+		conditions.MarkFalse(packetMachinePool, infrav1.DevicesReadyCondition, infrav1.ScalingUpReason, clusterv1.ConditionSeverityInfo,
+			"Scaling up from %d to %d devices", len(devices), desired)
+		for len(devices) < target {
+			dev, err := r.metalClient(ctx).NewMachinePoolDevice(ctx, packet.CreateMachinePoolDeviceRequest{
+				MachinePoolScope: poolScope,
+				Hostname:         fmt.Sprintf("%s-%s", poolScope.Name(), utilrand.String(5)),
+			})
+			if err != nil {
+				r.setInstances(poolScope, devices)
+				if errors.Is(err, packet.ErrInvalidRequest) || errors.Is(err, packet.ErrBootstrapDataTooLarge) {
+					// The template of the pool needs fixing, devices are not created again until then.
+					reason := infrav1.InstanceProvisionFailedReason
+					if errors.Is(err, packet.ErrBootstrapDataTooLarge) {
+						reason = infrav1.BootstrapDataTooLargeReason
+					}
+					packetMachinePool.Status.FailureReason = ptr.To(capierrors.CreateMachineError)
+					packetMachinePool.Status.FailureMessage = ptr.To(err.Error())
+					conditions.MarkFalse(packetMachinePool, infrav1.DevicesReadyCondition, reason, clusterv1.ConditionSeverityError, err.Error())
+					record.Warnf(packetMachinePool, "FailedCreate", "Failed to create device: %s", err)
+					return ctrl.Result{}, nil
+				}
+				return ctrl.Result{}, fmt.Errorf("failed to create device: %w", err)
+			}
+			record.Eventf(packetMachinePool, "SuccessfulCreate", "Created device %s", dev.GetHostname())
+			devicesCreatedTotal.MustCurryWith(poolScope.MetricLabels()).WithLabelValues("packetmachinepool").Inc()
+			r.recordAudit(ctx, poolScope, audit.DeviceCreated, dev.GetId(), "Created device %s", dev.GetHostname())
+			devices = append(devices, *dev)
+		}
+	case len(devices) > desired:
+		conditions.MarkFalse(packetMachinePool, infrav1.DevicesReadyCondition, infrav1.ScalingDownReason, clusterv1.ConditionSeverityInfo,
+			"Scaling down from %d to %d devices", len(devices), desired)
+		devices = scaleDownOrder(devices)
+		for _, dev := range devices[desired:] {
+			dev := dev
+			if err := r.deleteDevice(ctx, poolScope, &dev, "scaling down"); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		devices = devices[:desired]
+	}
+
+	r.setInstances(poolScope, devices)
+	if packetMachinePool.Status.Ready {
+		conditions.MarkTrue(packetMachinePool, infrav1.DevicesReadyCondition)
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: machinePoolProvisioningRequeue}, nil
+}
+
+func (r *PacketMachinePoolReconciler) reconcileDelete(ctx context.Context, poolScope *scope.MachinePoolScope) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.Info("Reconciling Delete PacketMachinePool")
+
+	devices, err := r.devices(ctx, poolScope)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for i := range devices {
+		if err := r.deleteDevice(ctx, poolScope, &devices[i], "machine pool deleted"); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(poolScope.PacketMachinePool, infrav1.MachinePoolFinalizer)
+	return ctrl.Result{}, nil
+}
+
+// devices returns the devices of the pool, oldest first. Devices being deprovisioned are already gone from the pool.
+func (r *PacketMachinePoolReconciler) devices(ctx context.Context, poolScope *scope.MachinePoolScope) ([]metal.Device, error) {
+	tags := packet.MachinePoolCreateTags(poolScope.Namespace(), poolScope.Name(), poolScope.Cluster.Name)
+	devices, err := r.metalClient(ctx).GetDevicesByTags(ctx, poolScope.PacketCluster.Spec.ProjectID, tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the devices of the machine pool: %w", err)
+	}
+
+	var current []metal.Device
+	for _, dev := range devices {
+		if dev.GetState() != metal.DEVICESTATE_DEPROVISIONING {
+			current = append(current, dev)
+		}
+	}
+	return current, nil
+}
+
+func (r *PacketMachinePoolReconciler) deleteDevice(ctx context.Context, poolScope *scope.MachinePoolScope, dev *metal.Device, reason string) error {
+	if _, err := r.metalClient(ctx).DevicesApi.DeleteDevice(ctx, dev.GetId()).Execute(); err != nil { //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		return fmt.Errorf("failed to delete device %s: %w", dev.GetId(), err)
+	}
+	record.Eventf(poolScope.PacketMachinePool, "SuccessfulDelete", "Deleted device %s: %s", dev.GetHostname(), reason)
+	devicesDeletedTotal.MustCurryWith(poolScope.MetricLabels()).WithLabelValues("packetmachinepool").Inc()
+	r.recordAudit(ctx, poolScope, audit.DeviceDeleted, dev.GetId(), "Deleted device %s: %s", dev.GetHostname(), reason)
+	return nil
+}
+
+// setInstances records the devices of the pool in its providerID list and status. The pool is ready when all the
+// devices its MachinePool wants are running.
+func (r *PacketMachinePoolReconciler) setInstances(poolScope *scope.MachinePoolScope, devices []metal.Device) {
+	prefix := r.ProviderIDPrefix
+	if prefix == "" {
+		prefix = scope.ProviderIDPrefix
+	}
+
+	packetMachinePool := poolScope.PacketMachinePool
+	providerIDs := make([]string, 0, len(devices))
+	instances := make([]infrav1.PacketMachinePoolInstance, 0, len(devices))
+	for i := range devices {
+		dev := &devices[i]
+		providerID := prefix + dev.GetId()
+		providerIDs = append(providerIDs, providerID)
+		instances = append(instances, infrav1.PacketMachinePoolInstance{
+			ProviderID:     providerID,
+			Hostname:       dev.GetHostname(),
+			InstanceStatus: infrav1.PacketResourceStatus(dev.GetState()),
+			Addresses:      r.PacketClient.GetDeviceAddresses(dev),
+		})
+	}
+	sort.Strings(providerIDs)
+
+	packetMachinePool.Spec.ProviderIDList = providerIDs
+	packetMachinePool.Status.Instances = instances
+	packetMachinePool.Status.Replicas = countRunning(devices)
+	packetMachinePool.Status.Ready = len(devices) == int(poolScope.DesiredReplicas()) && int(packetMachinePool.Status.Replicas) == len(devices)
+}
+
+// countRunning returns the number of active devices.
+func countRunning(devices []metal.Device) int32 {
+	var running int32
+	for i := range devices {
+		if devices[i].GetState() == metal.DEVICESTATE_ACTIVE {
+			running++
+		}
+	}
+	return running
+}
+
+// scaleDownOrder sorts the devices of a pool in the order they are kept in when scaling down: running devices
+// before the others, and older devices before newer ones, so the devices deleted first are the ones that do not
+// run yet, then the newest ones.
+func scaleDownOrder(devices []metal.Device) []metal.Device {
+	sorted := append([]metal.Device(nil), devices...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iActive := sorted[i].GetState() == metal.DEVICESTATE_ACTIVE
+		jActive := sorted[j].GetState() == metal.DEVICESTATE_ACTIVE
+		if iActive != jActive {
+			return iActive
+		}
+		return sorted[i].GetCreatedAt().Before(sorted[j].GetCreatedAt())
+	})
+	return sorted
+}
+
+func (r *PacketMachinePoolReconciler) recordAudit(ctx context.Context, poolScope *scope.MachinePoolScope, action audit.Action, resource, format string, args ...interface{}) {
+	r.Audit.Record(ctx, util.ObjectKey(poolScope.Cluster), action, "PacketMachinePool/"+poolScope.Name(), resource, format, args...)
+}
+
+// metalClient returns the Equinix Metal client of the cluster being reconciled, see packet.ClientForCluster.
+func (r *PacketMachinePoolReconciler) metalClient(ctx context.Context) *packet.Client {
+	return packet.ClientFromContext(ctx, r.PacketClient)
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expclusterv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/packettest"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+// fakeDeviceAPI serves the devices of a project, created and deleted through the API.
+type fakeDeviceAPI struct {
+	mu      sync.Mutex
+	devices []metal.Device
+}
+
+func (f *fakeDeviceAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/projects/project/devices":
+		_ = json.NewEncoder(w).Encode(metal.DeviceList{Devices: f.devices})
+	case r.Method == http.MethodPost && r.URL.Path == "/projects/project/devices":
+		var input struct {
+			Hostname string   `json:"hostname"`
+			Tags     []string `json:"tags"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		dev := metal.Device{
+			Id:        ptr.To(fmt.Sprintf("device-%d", len(f.devices))),
+			Hostname:  ptr.To(input.Hostname),
+			State:     ptr.To(metal.DEVICESTATE_PROVISIONING),
+			Tags:      input.Tags,
+			CreatedAt: ptr.To(time.Now().Add(time.Duration(len(f.devices)) * time.Second)),
+		}
+		f.devices = append(f.devices, dev)
+		_ = json.NewEncoder(w).Encode(dev)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/devices/"):
+		if dev := f.device(strings.TrimPrefix(r.URL.Path, "/devices/")); dev != nil {
+			_ = json.NewEncoder(w).Encode(dev)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/devices/"):
+		dev := f.device(strings.TrimPrefix(r.URL.Path, "/devices/"))
+		if dev == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var input metal.DeviceUpdateInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		dev.Tags = input.Tags
+		_ = json.NewEncoder(w).Encode(dev)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/devices/"):
+		id := strings.TrimPrefix(r.URL.Path, "/devices/")
+		for i := range f.devices {
+			if f.devices[i].GetId() == id {
+				f.devices = append(f.devices[:i], f.devices[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeDeviceAPI) device(id string) *metal.Device {
+	for i := range f.devices {
+		if f.devices[i].GetId() == id {
+			return &f.devices[i]
+		}
+	}
+	return nil
+}
+
+func (f *fakeDeviceAPI) setState(state metal.DeviceState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.devices {
+		f.devices[i].State = ptr.To(state)
+	}
+}
+
+func TestPacketMachinePoolReconcile(t *testing.T) {
+	g := NewWithT(t)
+
+	api := &fakeDeviceAPI{}
+	metalClient := packettest.NewClient(t, api)
+	r := &PacketMachinePoolReconciler{PacketClient: metalClient}
+
+	bootstrap := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bootstrap", Namespace: scopetest.Namespace},
+		Data:       map[string][]byte{"value": []byte("#cloud-config\n")},
+	}
+	machinePool := &expclusterv1.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: scopetest.Namespace},
+		Spec: expclusterv1.MachinePoolSpec{
+			Replicas: ptr.To[int32](2),
+			Template: clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{
+				Bootstrap: clusterv1.Bootstrap{DataSecretName: ptr.To("bootstrap")},
+			}},
+		},
+	}
+	packetMachinePool := &infrav1.PacketMachinePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: scopetest.Namespace},
+		Spec: infrav1.PacketMachinePoolSpec{Template: infrav1.PacketMachinePoolDeviceSpec{
+			OS:          "ubuntu_22_04",
+			MachineType: "c3.small.x86",
+		}},
+	}
+	poolScope, err := scope.NewMachinePoolScope(scope.MachinePoolScopeParams{
+		Client: fake.NewClientBuilder().WithScheme(scopetest.Scheme()).WithObjects(bootstrap).Build(),
+		Cluster: &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: scopetest.ClusterName, Namespace: scopetest.Namespace},
+			Status:     clusterv1.ClusterStatus{InfrastructureReady: true},
+		},
+		MachinePool: machinePool,
+		PacketCluster: &infrav1.PacketCluster{
+			Spec: infrav1.PacketClusterSpec{ProjectID: "project", Metro: "da"},
+		},
+		PacketMachinePool: packetMachinePool,
+		Patcher:           &scopetest.Patcher{},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	ctx := context.Background()
+
+	// The pool scales up to the replicas of its MachinePool.
+	result, err := r.reconcile(ctx, poolScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(machinePoolProvisioningRequeue))
+	g.Expect(api.devices).To(HaveLen(2))
+	g.Expect(api.devices[0].GetHostname()).To(HavePrefix("pool-"))
+	g.Expect(api.devices[0].Tags).To(ContainElements(packet.GenerateMachinePoolTag("pool"), infrav1.WorkerTag))
+	g.Expect(packetMachinePool.Spec.ProviderIDList).To(ConsistOf("equinixmetal://device-0", "equinixmetal://device-1"))
+	g.Expect(packetMachinePool.Status.Ready).To(BeFalse())
+	g.Expect(packetMachinePool.Status.Instances).To(HaveLen(2))
+
+	// It is ready once its devices run.
+	api.setState(metal.DEVICESTATE_ACTIVE)
+	_, err = r.reconcile(ctx, poolScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(packetMachinePool.Status.Ready).To(BeTrue())
+	g.Expect(packetMachinePool.Status.Replicas).To(BeEquivalentTo(2))
+
+	// Scaling down deletes the newest devices first.
+	machinePool.Spec.Replicas = ptr.To[int32](1)
+	_, err = r.reconcile(ctx, poolScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(api.devices).To(HaveLen(1))
+	g.Expect(api.devices[0].GetId()).To(Equal("device-0"))
+	g.Expect(packetMachinePool.Spec.ProviderIDList).To(ConsistOf("equinixmetal://device-0"))
+	g.Expect(packetMachinePool.Status.Ready).To(BeTrue())
+
+	// Deleting the pool deletes its devices.
+	_, err = r.reconcileDelete(ctx, poolScope)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(api.devices).To(BeEmpty())
+}
+
+func TestScaleDownOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	device := func(id string, state metal.DeviceState, age time.Duration) metal.Device {
+		return metal.Device{Id: ptr.To(id), State: ptr.To(state), CreatedAt: ptr.To(now.Add(-age))}
+	}
+	devices := scaleDownOrder([]metal.Device{
+		device("new", metal.DEVICESTATE_ACTIVE, time.Minute),
+		device("provisioning", metal.DEVICESTATE_PROVISIONING, time.Hour),
+		device("old", metal.DEVICESTATE_ACTIVE, time.Hour),
+	})
+
+	var ids []string
+	for _, dev := range devices {
+		ids = append(ids, dev.GetId())
+	}
+	g.Expect(ids).To(Equal([]string{"old", "new", "provisioning"}))
+}
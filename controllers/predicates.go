@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var updateEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "capp_update_events_total",
+	Help: "Number of update events of the objects reconciled by a controller, by whether they triggered a reconciliation.",
+}, []string{"controller", "reconciled"})
+
+func init() {
+	metrics.Registry.MustRegister(updateEventsTotal)
+}
+
+// ignoreStatusUpdates filters out the update events of objects whose spec and metadata did not change, such as the
+// ones of a controller patching the status of the object it reconciles, which would otherwise reconcile the object a
+// second time. Periodic resyncs, which deliver an unchanged object, are let through.
+func ignoreStatusUpdates(log logr.Logger, controller string) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			reconcile := specOrMetadataChanged(e.ObjectOld, e.ObjectNew)
+			updateEventsTotal.WithLabelValues(controller, strconv.FormatBool(reconcile)).Inc()
+			if !reconcile {
+				log.V(6).Info("Only the status changed, ignoring", "namespace", e.ObjectNew.GetNamespace(), "name", e.ObjectNew.GetName())
+			}
+			return reconcile
+		},
+	}
+}
+
+// specOrMetadataChanged reports whether an update changed the spec of an object, through its generation, or the
+// metadata its reconciliation depends on, or is a resync.
+func specOrMetadataChanged(oldObj, newObj client.Object) bool {
+	if oldObj == nil || newObj == nil || oldObj.GetResourceVersion() == newObj.GetResourceVersion() {
+		return true
+	}
+
+	return oldObj.GetGeneration() != newObj.GetGeneration() ||
+		!reflect.DeepEqual(oldObj.GetAnnotations(), newObj.GetAnnotations()) ||
+		!reflect.DeepEqual(oldObj.GetLabels(), newObj.GetLabels()) ||
+		!reflect.DeepEqual(oldObj.GetFinalizers(), newObj.GetFinalizers()) ||
+		!reflect.DeepEqual(oldObj.GetOwnerReferences(), newObj.GetOwnerReferences()) ||
+		!oldObj.GetDeletionTimestamp().Equal(newObj.GetDeletionTimestamp())
+}
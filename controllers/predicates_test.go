@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestIgnoreStatusUpdates(t *testing.T) {
+	g := NewWithT(t)
+
+	p := ignoreStatusUpdates(logr.Discard(), "test")
+	old := &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{Name: "machine", ResourceVersion: "1", Generation: 1}}
+
+	update := func(mutate func(*infrav1.PacketMachine)) bool {
+		updated := old.DeepCopy()
+		updated.ResourceVersion = "2"
+		mutate(updated)
+		return p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})
+	}
+
+	g.Expect(update(func(m *infrav1.PacketMachine) { m.Status.Ready = true })).To(BeFalse())
+	g.Expect(update(func(m *infrav1.PacketMachine) { m.Generation = 2 })).To(BeTrue())
+	g.Expect(update(func(m *infrav1.PacketMachine) { m.Annotations = map[string]string{"a": "b"} })).To(BeTrue())
+	g.Expect(update(func(m *infrav1.PacketMachine) { m.Labels = map[string]string{"a": "b"} })).To(BeTrue())
+	g.Expect(update(func(m *infrav1.PacketMachine) { m.Finalizers = []string{infrav1.MachineFinalizer} })).To(BeTrue())
+	g.Expect(update(func(m *infrav1.PacketMachine) {
+		m.OwnerReferences = []metav1.OwnerReference{{Kind: "Machine", Name: "machine"}}
+	})).To(BeTrue())
+	g.Expect(update(func(m *infrav1.PacketMachine) { m.DeletionTimestamp = &metav1.Time{} })).To(BeTrue())
+
+	// A resync delivers the object unchanged.
+	g.Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: old.DeepCopy()})).To(BeTrue())
+
+	g.Expect(testutil.ToFloat64(updateEventsTotal.WithLabelValues("test", "false"))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(updateEventsTotal.WithLabelValues("test", "true"))).To(Equal(7.0))
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// DefaultBufferSize is the number of entries an AsyncSink buffers by default.
+const DefaultBufferSize = 1000
+
+// ErrBufferFull is returned when an entry is dropped because the buffer of an AsyncSink is full.
+var ErrBufferFull = errors.New("audit entry buffer is full")
+
+// AsyncSink buffers the entries and writes them to a sink from a background worker, so that a slow or unreachable
+// sink, such as a webhook, does not hold up the reconciles recording entries. The worker runs once the AsyncSink is
+// added to the manager. Entries still buffered when the manager stops are lost.
+type AsyncSink struct {
+	sink    Sink
+	entries chan asyncEntry
+}
+
+// asyncEntry is an entry waiting to be written, with the logger of the reconcile that recorded it.
+type asyncEntry struct {
+	log     logr.Logger
+	cluster client.ObjectKey
+	entry   Entry
+}
+
+var (
+	_ Sink                           = &AsyncSink{}
+	_ manager.Runnable               = &AsyncSink{}
+	_ manager.LeaderElectionRunnable = &AsyncSink{}
+)
+
+// NewAsyncSink returns an AsyncSink writing to sink, buffering up to size entries, or DefaultBufferSize.
+func NewAsyncSink(sink Sink, size int) *AsyncSink {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &AsyncSink{sink: sink, entries: make(chan asyncEntry, size)}
+}
+
+// Write implements Sink. It only queues the entry, dropping it when the buffer is full.
+func (s *AsyncSink) Write(ctx context.Context, cluster client.ObjectKey, entry Entry) error {
+	select {
+	case s.entries <- asyncEntry{log: ctrl.LoggerFrom(ctx), cluster: cluster, entry: entry}:
+		return nil
+	default:
+		return ErrBufferFull
+	}
+}
+
+// Start implements manager.Runnable, writing the buffered entries until ctx is done. The sink is closed then, if
+// it can be.
+func (s *AsyncSink) Start(ctx context.Context) error {
+	if closer, ok := s.sink.(io.Closer); ok {
+		defer func() { _ = closer.Close() }()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case queued := <-s.entries:
+			writeCtx, cancel := context.WithTimeout(ctrl.LoggerInto(ctx, queued.log), webhookTimeout)
+			if err := s.sink.Write(writeCtx, queued.cluster, queued.entry); err != nil {
+				queued.log.Error(err, "failed to write audit entry", "action", queued.entry.Action, "resource", queued.entry.Resource)
+			}
+			cancel()
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The entries are recorded by the leader, but are
+// written whenever the buffer has some.
+func (s *AsyncSink) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// channelSink passes the entries written to it to a channel.
+type channelSink chan Entry
+
+func (s channelSink) Write(_ context.Context, _ client.ObjectKey, entry Entry) error {
+	s <- entry
+	return nil
+}
+
+func TestAsyncSink(t *testing.T) {
+	g := NewWithT(t)
+
+	written := make(channelSink)
+	sink := NewAsyncSink(written, 2)
+
+	// Entries are buffered until the worker runs, and dropped once the buffer is full.
+	g.Expect(sink.Write(context.Background(), client.ObjectKey{}, Entry{Resource: "device-a"})).To(Succeed())
+	g.Expect(sink.Write(context.Background(), client.ObjectKey{}, Entry{Resource: "device-b"})).To(Succeed())
+	g.Expect(sink.Write(context.Background(), client.ObjectKey{}, Entry{Resource: "device-c"})).To(MatchError(ErrBufferFull))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sink.Start(ctx) }()
+
+	var entry Entry
+	g.Eventually(written).Should(Receive(&entry))
+	g.Expect(entry.Resource).To(Equal("device-a"))
+	g.Eventually(written).Should(Receive(&entry))
+	g.Expect(entry.Resource).To(Equal("device-b"))
+
+	// Entries recorded while the worker runs are written as well.
+	g.Expect(sink.Write(context.Background(), client.ObjectKey{}, Entry{Resource: "device-c"})).To(Succeed())
+	g.Eventually(written).Should(Receive(&entry))
+	g.Expect(entry.Resource).To(Equal("device-c"))
+
+	cancel()
+	g.Eventually(done).Should(Receive(BeNil()))
+	g.Expect(sink.NeedLeaderElection()).To(BeFalse())
+}
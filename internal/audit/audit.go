@@ -0,0 +1,260 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records the changes the controllers make to the Equinix Metal infrastructure of a cluster, as an
+// ordered trail security teams can consume without scraping the controller logs.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Action is a change made to the infrastructure of a cluster.
+type Action string
+
+const (
+	// DeviceCreated is recorded when a device is created for a machine.
+	DeviceCreated Action = "DeviceCreated"
+	// DeviceDeleted is recorded when the device of a machine is deleted.
+	DeviceDeleted Action = "DeviceDeleted"
+	// DeviceRenamed is recorded when a device is renamed after its hostname drifted.
+	DeviceRenamed Action = "DeviceRenamed"
+	// ElasticIPReserved is recorded when the control plane elastic IP of a cluster is reserved.
+	ElasticIPReserved Action = "ElasticIPReserved"
+	// ElasticIPReleased is recorded when the control plane elastic IP of a deleted cluster is released.
+	ElasticIPReleased Action = "ElasticIPReleased"
+	// ElasticIPAssigned is recorded when an elastic IP is assigned to a device.
+	ElasticIPAssigned Action = "ElasticIPAssigned"
+	// ElasticIPUnassigned is recorded when an elastic IP is unassigned from a device.
+	ElasticIPUnassigned Action = "ElasticIPUnassigned"
+	// ProjectCreated is recorded when the managed project of a cluster is created.
+	ProjectCreated Action = "ProjectCreated"
+	// ProjectDeleted is recorded when the managed project of a deleted cluster is deleted.
+	ProjectDeleted Action = "ProjectDeleted"
+	// DeviceProvisioningFailed is recorded when the device of a machine fails to provision.
+	DeviceProvisioningFailed Action = "DeviceProvisioningFailed"
+	// ElasticIPMoved is recorded when the control plane elastic IP is assigned to a device after being unassigned from
+	// the devices being deleted that held it.
+	ElasticIPMoved Action = "ElasticIPMoved"
+	// VLANCreated is recorded when a VLAN of a cluster is created.
+	VLANCreated Action = "VLANCreated"
+	// VLANDeleted is recorded when a VLAN is removed from a cluster, or deleted with it.
+	VLANDeleted Action = "VLANDeleted"
+	// VLANAttached is recorded when a device is attached to a VLAN of its cluster.
+	VLANAttached Action = "VLANAttached"
+	// VLANDetached is recorded when a device is detached from a VLAN of its cluster.
+	VLANDetached Action = "VLANDetached"
+)
+
+const (
+	// ConfigMapKey is the key of the audit ConfigMaps the entries are stored under, one JSON entry per line.
+	ConfigMapKey = "audit.jsonl"
+	// ConfigMapLabel marks the ConfigMaps holding the audit trail of a cluster.
+	ConfigMapLabel = "infrastructure.cluster.x-k8s.io/packet-audit"
+	// DefaultMaxEntries is the number of entries audit ConfigMaps keep by default, well under the size limit of
+	// ConfigMaps.
+	DefaultMaxEntries = 1000
+)
+
+// webhookTimeout bounds the requests to webhook sinks, and the writes of AsyncSinks.
+const webhookTimeout = 10 * time.Second
+
+// Entry is a change made to the infrastructure of a cluster.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Cluster is the namespaced name of the Cluster.
+	Cluster string `json:"cluster"`
+	Action  Action `json:"action"`
+	// Object is the kind and name of the object the change was made for, e.g. PacketMachine/worker-a-x7k2p.
+	Object string `json:"object"`
+	// Resource is the Equinix Metal resource that changed, e.g. a device ID or an IP address.
+	Resource string `json:"resource"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Sink stores the entries of the audit trail of a cluster.
+type Sink interface {
+	Write(ctx context.Context, cluster client.ObjectKey, entry Entry) error
+}
+
+// EventAggregator writes the changes made to the infrastructure of clusters to sinks. A nil EventAggregator records
+// nothing, so that auditing can be left disabled without checks at the call sites.
+type EventAggregator struct {
+	sinks []Sink
+}
+
+// NewEventAggregator returns an EventAggregator writing to sinks, or nil when there are none.
+func NewEventAggregator(sinks ...Sink) *EventAggregator {
+	if len(sinks) == 0 {
+		return nil
+	}
+	return &EventAggregator{sinks: sinks}
+}
+
+// Record writes an entry to the sinks, stamping it with the current time. The object is the kind and name of the
+// object the change was made for, e.g. PacketMachine/worker-a-x7k2p. Failing to write the entry is logged
+// rather than returned, as the change it describes already happened. Recording to a nil EventAggregator, when
+// auditing is disabled, is a no-op.
+func (a *EventAggregator) Record(ctx context.Context, cluster client.ObjectKey, action Action, object, resource, format string, args ...interface{}) {
+	if a == nil {
+		return
+	}
+
+	entry := Entry{
+		Time:     time.Now().UTC(),
+		Cluster:  cluster.String(),
+		Action:   action,
+		Object:   object,
+		Resource: resource,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	for _, sink := range a.sinks {
+		if err := sink.Write(ctx, cluster, entry); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed to write audit entry", "action", action, "resource", resource)
+		}
+	}
+}
+
+// ConfigMapName returns the name of the ConfigMap holding the audit trail of a cluster.
+func ConfigMapName(cluster string) string {
+	return cluster + "-infra-audit"
+}
+
+// ConfigMapSink appends the entries to a ConfigMap per cluster, next to the Cluster. The ConfigMap is not owned by
+// the Cluster, so that the trail outlives it.
+type ConfigMapSink struct {
+	Client client.Client
+
+	// MaxEntries is the number of entries kept, the oldest ones are dropped first. Defaults to DefaultMaxEntries.
+	MaxEntries int
+}
+
+var _ Sink = &ConfigMapSink{}
+
+// Write implements Sink.
+func (s *ConfigMapSink) Write(ctx context.Context, cluster client.ObjectKey, entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	maxEntries := s.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: ConfigMapName(cluster.Name)}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		if err := s.Client.Get(ctx, key, configMap); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get audit ConfigMap %s: %w", key, err)
+			}
+			configMap.Namespace = key.Namespace
+			configMap.Name = key.Name
+			configMap.Labels = map[string]string{
+				clusterv1.ClusterNameLabel: cluster.Name,
+				ConfigMapLabel:             "",
+			}
+			configMap.Data = map[string]string{ConfigMapKey: string(line) + "\n"}
+			if err := s.Client.Create(ctx, configMap); err != nil {
+				if apierrors.IsAlreadyExists(err) {
+					// Created concurrently, append to it instead.
+					return apierrors.NewConflict(corev1.Resource("configmaps"), key.Name, err)
+				}
+				return fmt.Errorf("failed to create audit ConfigMap %s: %w", key, err)
+			}
+			return nil
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[ConfigMapKey] = appendEntry(configMap.Data[ConfigMapKey], string(line), maxEntries)
+		if err := s.Client.Update(ctx, configMap); err != nil {
+			if apierrors.IsConflict(err) {
+				return err
+			}
+			return fmt.Errorf("failed to update audit ConfigMap %s: %w", key, err)
+		}
+		return nil
+	})
+}
+
+// appendEntry appends a line to a trail, dropping the oldest lines beyond maxEntries.
+func appendEntry(trail, line string, maxEntries int) string {
+	lines := append(strings.Split(strings.TrimSuffix(trail, "\n"), "\n"), line)
+	if lines[0] == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > maxEntries {
+		lines = lines[len(lines)-maxEntries:]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// WebhookSink posts the entries, as JSON, to a URL.
+type WebhookSink struct {
+	URL string
+
+	// Client makes the requests. Defaults to a client with a timeout of 10 seconds.
+	Client *http.Client
+}
+
+var _ Sink = &WebhookSink{}
+
+// Write implements Sink.
+func (s *WebhookSink) Write(ctx context.Context, _ client.ObjectKey, entry Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	return post(ctx, s.Client, s.URL, "application/json", body)
+}
+
+// post posts a body to the URL of a webhook sink.
+func post(ctx context.Context, httpClient *http.Client, url, contentType string, body []byte) error {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: webhookTimeout}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create audit webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post audit entry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("failed to post audit entry: audit webhook returned %s", resp.Status) //nolint:goerr113
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNilEventAggregator(t *testing.T) {
+	g := NewWithT(t)
+
+	aggregator := NewEventAggregator()
+	g.Expect(aggregator).To(BeNil())
+	// Recording with auditing disabled is a no-op.
+	aggregator.Record(context.Background(), client.ObjectKey{Namespace: "default", Name: "cluster"}, DeviceCreated, "PacketMachine/machine", "device", "Created device")
+}
+
+func TestConfigMapSink(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := fake.NewClientBuilder().Build()
+	aggregator := NewEventAggregator(&ConfigMapSink{Client: c, MaxEntries: 2})
+	cluster := client.ObjectKey{Namespace: "default", Name: "cluster"}
+
+	aggregator.Record(ctx, cluster, DeviceCreated, "PacketMachine/a", "device-a", "Created device %s", "a")
+	aggregator.Record(ctx, cluster, DeviceCreated, "PacketMachine/b", "device-b", "Created device %s", "b")
+	aggregator.Record(ctx, cluster, DeviceDeleted, "PacketMachine/a", "device-a", "Deleted device %s", "a")
+
+	configMap := &corev1.ConfigMap{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cluster-infra-audit"}, configMap)).To(Succeed())
+	g.Expect(configMap.Labels).To(HaveKeyWithValue("cluster.x-k8s.io/cluster-name", "cluster"))
+
+	// The oldest entries are dropped beyond MaxEntries, the others are kept in order.
+	lines := strings.Split(strings.TrimSuffix(configMap.Data[ConfigMapKey], "\n"), "\n")
+	g.Expect(lines).To(HaveLen(2))
+	var entries []Entry
+	for _, line := range lines {
+		var entry Entry
+		g.Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+		entries = append(entries, entry)
+	}
+	g.Expect(entries[0].Action).To(Equal(DeviceCreated))
+	g.Expect(entries[0].Resource).To(Equal("device-b"))
+	g.Expect(entries[1].Action).To(Equal(DeviceDeleted))
+	g.Expect(entries[1].Cluster).To(Equal("default/cluster"))
+	g.Expect(entries[1].Message).To(Equal("Deleted device a"))
+}
+
+func TestWebhookSink(t *testing.T) {
+	g := NewWithT(t)
+
+	var received []Entry
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var entry Entry
+		g.Expect(json.NewDecoder(r.Body).Decode(&entry)).To(Succeed())
+		received = append(received, entry)
+	}))
+	defer server.Close()
+
+	sink := &WebhookSink{URL: server.URL}
+	g.Expect(sink.Write(context.Background(), client.ObjectKey{}, Entry{Action: ElasticIPAssigned, Resource: "1.2.3.4"})).To(Succeed())
+	g.Expect(received).To(HaveLen(1))
+	g.Expect(received[0].Resource).To(Equal("1.2.3.4"))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	g.Expect((&WebhookSink{URL: failing.URL}).Write(context.Background(), client.ObjectKey{}, Entry{})).ToNot(Succeed())
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CloudEventsContentType is the media type of CloudEvents in the structured JSON format.
+	CloudEventsContentType = "application/cloudevents+json"
+	// CloudEventTypePrefix prefixes the action of an entry in the type of its CloudEvent, e.g.
+	// io.x-k8s.cluster.infrastructure.packet.DeviceCreated.
+	CloudEventTypePrefix = "io.x-k8s.cluster.infrastructure.packet."
+	// DefaultCloudEventSource is the source of the CloudEvents of the entries by default.
+	DefaultCloudEventSource = "sigs.k8s.io/cluster-api-provider-packet"
+	// DefaultNATSSubject is the subject CloudEvents are published to on NATS by default.
+	DefaultNATSSubject = "capp.events"
+)
+
+// CloudEvent is an entry in the structured JSON format of CloudEvents 1.0.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	// Cluster is an extension attribute holding the namespaced name of the Cluster, so that consumers can filter on
+	// it without decoding the data.
+	Cluster string `json:"cluster"`
+	Data    Entry  `json:"data"`
+}
+
+// NewCloudEvent returns the CloudEvent of an entry. Its subject is the object the change was made for.
+func NewCloudEvent(source string, entry Entry) CloudEvent {
+	if source == "" {
+		source = DefaultCloudEventSource
+	}
+	return CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              string(uuid.NewUUID()),
+		Source:          source,
+		Type:            CloudEventTypePrefix + string(entry.Action),
+		Subject:         entry.Object,
+		Time:            entry.Time,
+		DataContentType: "application/json",
+		Cluster:         entry.Cluster,
+		Data:            entry,
+	}
+}
+
+// NewCloudEventsSink returns the sink publishing CloudEvents to a URL: they are posted to http and https URLs, and
+// published to the subject on nats and tls URLs, authenticating with the NATS credentials file, if any.
+func NewCloudEventsSink(rawURL, source, subject, natsCredentials string) (Sink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CloudEvents sink URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return &CloudEventsWebhookSink{URL: rawURL, Source: source}, nil
+	case natsScheme, natsTLSScheme:
+		if subject == "" {
+			subject = DefaultNATSSubject
+		}
+		if err := validateNATSSubject(subject); err != nil {
+			return nil, err
+		}
+		var options []nats.Option
+		if natsCredentials != "" {
+			options = append(options, nats.UserCredentials(natsCredentials))
+		}
+		return NewNATSSink(rawURL, subject, source, options...)
+	default:
+		return nil, fmt.Errorf("unsupported CloudEvents sink URL scheme %q, expected http, https, nats or tls", u.Scheme) //nolint:goerr113
+	}
+}
+
+// CloudEventsWebhookSink posts the entries, as CloudEvents in the structured JSON format, to a URL.
+type CloudEventsWebhookSink struct {
+	URL string
+
+	// Source is the source of the CloudEvents. Defaults to DefaultCloudEventSource.
+	Source string
+
+	// Client makes the requests. Defaults to a client with a timeout of 10 seconds.
+	Client *http.Client
+}
+
+var _ Sink = &CloudEventsWebhookSink{}
+
+// Write implements Sink.
+func (s *CloudEventsWebhookSink) Write(ctx context.Context, _ client.ObjectKey, entry Entry) error {
+	body, err := json.Marshal(NewCloudEvent(s.Source, entry))
+	if err != nil {
+		return fmt.Errorf("failed to encode CloudEvent: %w", err)
+	}
+	return post(ctx, s.Client, s.URL, CloudEventsContentType, body)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNewCloudEvent(t *testing.T) {
+	g := NewWithT(t)
+
+	entry := Entry{
+		Time:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Cluster:  "default/cluster",
+		Action:   DeviceProvisioningFailed,
+		Object:   "PacketMachine/worker-a",
+		Resource: "device-a",
+	}
+	event := NewCloudEvent("", entry)
+	g.Expect(event.SpecVersion).To(Equal("1.0"))
+	g.Expect(event.ID).ToNot(BeEmpty())
+	g.Expect(event.Source).To(Equal(DefaultCloudEventSource))
+	g.Expect(event.Type).To(Equal("io.x-k8s.cluster.infrastructure.packet.DeviceProvisioningFailed"))
+	g.Expect(event.Subject).To(Equal("PacketMachine/worker-a"))
+	g.Expect(event.Time).To(Equal(entry.Time))
+	g.Expect(event.Cluster).To(Equal("default/cluster"))
+	g.Expect(event.Data).To(Equal(entry))
+
+	// Every event gets its own ID.
+	g.Expect(NewCloudEvent("mgmt-eu", entry).ID).ToNot(Equal(event.ID))
+	g.Expect(NewCloudEvent("mgmt-eu", entry).Source).To(Equal("mgmt-eu"))
+}
+
+func TestNewCloudEventsSink(t *testing.T) {
+	g := NewWithT(t)
+
+	sink, err := NewCloudEventsSink("https://events.example.com/capp", "", "", "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(sink).To(BeAssignableToTypeOf(&CloudEventsWebhookSink{}))
+
+	addr, _ := fakeNATSServer(t)
+	sink, err = NewCloudEventsSink("nats://token@"+addr, "", "", "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(sink).To(BeAssignableToTypeOf(&NATSSink{}))
+	g.Expect(sink.(*NATSSink).Subject).To(Equal(DefaultNATSSubject))
+	g.Expect(sink.(*NATSSink).Close()).To(Succeed())
+
+	_, err = NewCloudEventsSink("nats://"+addr, "", "capp.>", "")
+	g.Expect(err).To(MatchError(ContainSubstring("invalid NATS subject")))
+	_, err = NewCloudEventsSink("kafka://broker:9092", "", "", "")
+	g.Expect(err).To(MatchError(ContainSubstring("unsupported CloudEvents sink URL scheme")))
+}
+
+func TestCloudEventsWebhookSink(t *testing.T) {
+	g := NewWithT(t)
+
+	var received []CloudEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.Header.Get("Content-Type")).To(Equal(CloudEventsContentType))
+		var event CloudEvent
+		g.Expect(json.NewDecoder(r.Body).Decode(&event)).To(Succeed())
+		received = append(received, event)
+	}))
+	defer server.Close()
+
+	sink := &CloudEventsWebhookSink{URL: server.URL, Source: "mgmt-eu"}
+	g.Expect(sink.Write(context.Background(), client.ObjectKey{}, Entry{Action: ElasticIPMoved, Resource: "1.2.3.4"})).To(Succeed())
+	g.Expect(received).To(HaveLen(1))
+	g.Expect(received[0].Type).To(Equal(CloudEventTypePrefix + "ElasticIPMoved"))
+	g.Expect(received[0].Source).To(Equal("mgmt-eu"))
+	g.Expect(received[0].Data.Resource).To(Equal("1.2.3.4"))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	natsScheme    = "nats"
+	natsTLSScheme = "tls"
+)
+
+// NATSSink publishes the entries, as CloudEvents in the structured JSON format, to a subject of NATS servers. It
+// keeps a connection to the servers, which reconnects when they go away and buffers the entries published meanwhile.
+type NATSSink struct {
+	Subject string
+
+	// Source is the source of the CloudEvents. Defaults to DefaultCloudEventSource.
+	Source string
+
+	conn *nats.Conn
+}
+
+var _ Sink = &NATSSink{}
+
+// NewNATSSink connects to the NATS servers of a comma separated list of nats:// URLs, or tls:// URLs to require TLS.
+// The client authenticates with the user and password, or the token as the user, of the URLs, or with the options,
+// e.g. nats.UserCredentials for JWT and NKey authentication. Failing to connect at first is not an
+// error, the connection is retried in the background.
+func NewNATSSink(servers, subject, source string, options ...nats.Option) (*NATSSink, error) {
+	log := ctrl.Log.WithName("audit").WithValues("subject", subject)
+	options = append([]nats.Option{
+		nats.Name("cluster-api-provider-packet"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Error(err, "Disconnected from NATS server")
+			}
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			log.Error(err, "NATS server returned an error")
+		}),
+	}, options...)
+
+	conn, err := nats.Connect(servers, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS servers: %w", err)
+	}
+	return &NATSSink{Subject: subject, Source: source, conn: conn}, nil
+}
+
+// Write implements Sink.
+func (s *NATSSink) Write(_ context.Context, _ client.ObjectKey, entry Entry) error {
+	payload, err := json.Marshal(NewCloudEvent(s.Source, entry))
+	if err != nil {
+		return fmt.Errorf("failed to encode CloudEvent: %w", err)
+	}
+	if err := s.conn.Publish(s.Subject, payload); err != nil {
+		return fmt.Errorf("failed to publish CloudEvent to NATS subject %s: %w", s.Subject, err)
+	}
+	return nil
+}
+
+// Close publishes the entries still buffered and closes the connection to the servers.
+func (s *NATSSink) Close() error {
+	return s.conn.Drain()
+}
+
+// validateNATSSubject checks that a subject can be published to: NATS subjects are tokens separated by dots, and
+// cannot contain whitespace or the wildcards of subscriptions.
+func validateNATSSubject(subject string) error {
+	for _, token := range strings.Split(subject, ".") {
+		if token == "" || token == "*" || token == ">" || strings.ContainsAny(token, " \t\r\n") {
+			return fmt.Errorf("invalid NATS subject %q", subject) //nolint:goerr113
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// natsConnect is the part of the CONNECT message of a NATS client the tests look at.
+type natsConnect struct {
+	User string `json:"user"`
+	Pass string `json:"pass"`
+}
+
+// natsPublish is a message published to the fake NATS server.
+type natsPublish struct {
+	connect natsConnect
+	subject string
+	payload []byte
+}
+
+// fakeNATSServer speaks enough of the NATS protocol for a client to connect and publish messages, which it returns.
+func fakeNATSServer(t *testing.T) (string, <-chan natsPublish) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	published := make(chan natsPublish, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveNATS(conn, published)
+		}
+	}()
+	return listener.Addr().String(), published
+}
+
+func serveNATS(conn net.Conn, published chan<- natsPublish) {
+	defer conn.Close()
+	fmt.Fprint(conn, "INFO {\"server_id\":\"fake\",\"version\":\"2.10.0\",\"proto\":1,\"auth_required\":true,\"max_payload\":1048576}\r\n")
+
+	var connect natsConnect
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			_ = json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &connect)
+		case strings.HasPrefix(line, "PUB "):
+			fields := strings.Fields(line)
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			published <- natsPublish{connect: connect, subject: fields[1], payload: payload[:size]}
+		case line == "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		}
+	}
+}
+
+func TestNATSSink(t *testing.T) {
+	g := NewWithT(t)
+
+	addr, published := fakeNATSServer(t)
+	sink, err := NewNATSSink("nats://capp:secret@"+addr, "capp.events", "")
+	g.Expect(err).ToNot(HaveOccurred())
+	defer sink.Close()
+
+	// Both entries are published on the same connection.
+	g.Expect(sink.Write(context.Background(), client.ObjectKey{}, Entry{Action: DeviceCreated, Resource: "device-a"})).To(Succeed())
+	g.Expect(sink.Write(context.Background(), client.ObjectKey{}, Entry{Action: DeviceDeleted, Resource: "device-a"})).To(Succeed())
+	g.Expect(sink.conn.Flush()).To(Succeed())
+
+	for _, action := range []Action{DeviceCreated, DeviceDeleted} {
+		var msg natsPublish
+		g.Eventually(published).Should(Receive(&msg))
+		g.Expect(msg.connect.User).To(Equal("capp"))
+		g.Expect(msg.connect.Pass).To(Equal("secret"))
+		g.Expect(msg.subject).To(Equal("capp.events"))
+		var event CloudEvent
+		g.Expect(json.Unmarshal(msg.payload, &event)).To(Succeed())
+		g.Expect(event.Type).To(Equal(CloudEventTypePrefix + string(action)))
+		g.Expect(event.Data.Resource).To(Equal("device-a"))
+	}
+	g.Expect(sink.conn.Stats().Reconnects).To(BeZero())
+}
+
+func TestValidateNATSSubject(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(validateNATSSubject("capp.events")).To(Succeed())
+	g.Expect(validateNATSSubject("capp")).To(Succeed())
+	g.Expect(validateNATSSubject("capp.*")).ToNot(Succeed())
+	g.Expect(validateNATSSubject("capp..events")).ToNot(Succeed())
+	g.Expect(validateNATSSubject("capp events")).ToNot(Succeed())
+}
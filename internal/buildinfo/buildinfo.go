@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package buildinfo reports the version of the provider and the optional features it runs with, so that fleet
+// tooling can tell which provider versions manage which clusters.
+package buildinfo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"sigs.k8s.io/cluster-api-provider-packet/version"
+)
+
+// ConfigMapName is the name of the ConfigMap the build information is published in.
+const ConfigMapName = "cluster-api-provider-packet-build-info"
+
+// InstallationLabel is the label of the metrics of the provider that holds the identifier of its installation.
+const InstallationLabel = "installation"
+
+// metricPrefix is the prefix of the names of the metrics of the provider, as opposed to the ones of its libraries.
+const metricPrefix = "capp_"
+
+// publishRetryInterval is how often publishing the ConfigMap is retried after a failure.
+const publishRetryInterval = 30 * time.Second
+
+var (
+	buildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capp_build_info",
+		Help: "Version of the provider and the Cluster API contract it implements, always 1.",
+	}, []string{"version", "git_commit", "git_tree_state", "go_version", "metal_sdk_version", "contract"})
+
+	featureEnabled = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capp_feature_enabled",
+		Help: "Optional features the provider runs with, always 1.",
+	}, []string{"feature"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(buildInfo, featureEnabled)
+}
+
+// Register reports the build information and the enabled features in the metrics.
+func Register(features []string) {
+	info := version.Get()
+	buildInfo.WithLabelValues(info.GitVersion, info.GitCommit, info.GitTreeState, info.GoVersion, info.MetalSdkVersion,
+		version.ClusterAPIContract).Set(1)
+	for _, feature := range features {
+		featureEnabled.WithLabelValues(feature).Set(1)
+	}
+}
+
+// SetInstallation adds the identifier of the installation of the provider to its metrics as the installation label, so
+// that the metrics of the providers of several management clusters can be aggregated and still told apart. It must be
+// called before the metrics are served; nothing changes when the identifier is empty.
+func SetInstallation(installation string) {
+	if installation == "" {
+		return
+	}
+	metrics.Registry = &installationRegistry{RegistererGatherer: metrics.Registry, installation: installation}
+}
+
+// installationRegistry adds the installation label to the metrics of the provider it gathers.
+type installationRegistry struct {
+	metrics.RegistererGatherer
+	installation string
+}
+
+// Gather implements prometheus.Gatherer.
+func (r *installationRegistry) Gather() ([]*dto.MetricFamily, error) {
+	families, err := r.RegistererGatherer.Gather()
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), metricPrefix) {
+			continue
+		}
+		for _, metric := range family.Metric {
+			metric.Label = append(metric.Label, &dto.LabelPair{Name: ptr.To(InstallationLabel), Value: ptr.To(r.installation)})
+			sort.Slice(metric.Label, func(i, j int) bool { return metric.Label[i].GetName() < metric.Label[j].GetName() })
+		}
+	}
+	return families, err
+}
+
+// Data returns the content of the build information ConfigMap.
+func Data(features []string) map[string]string {
+	info := version.Get()
+	return map[string]string{
+		"version":         info.GitVersion,
+		"gitCommit":       info.GitCommit,
+		"gitTreeState":    info.GitTreeState,
+		"buildDate":       info.BuildDate,
+		"goVersion":       info.GoVersion,
+		"metalSdkVersion": info.MetalSdkVersion,
+		"contract":        version.ClusterAPIContract,
+		"features":        strings.Join(features, ","),
+	}
+}
+
+// Reporter publishes the build information in a ConfigMap once the manager is elected leader.
+type Reporter struct {
+	Client client.Client
+
+	// Namespace of the ConfigMap.
+	Namespace string
+	// Features are the optional features enabled on the manager.
+	Features []string
+}
+
+// Start publishes the ConfigMap, retrying until it succeeds or the context is cancelled. It implements
+// manager.Runnable.
+func (r *Reporter) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("build-info")
+
+	_ = wait.PollUntilContextCancel(ctx, publishRetryInterval, true, func(ctx context.Context) (bool, error) {
+		if err := r.Publish(ctx); err != nil {
+			log.Error(err, "failed to publish the build information")
+			return false, nil
+		}
+		return true, nil
+	})
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (r *Reporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Publish creates or updates the ConfigMap.
+func (r *Reporter) Publish(ctx context.Context) error {
+	configMap := &corev1.ConfigMap{}
+	configMap.Namespace = r.Namespace
+	configMap.Name = ConfigMapName
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Data = Data(r.Features)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to write ConfigMap %s/%s: %w", r.Namespace, ConfigMapName, err)
+	}
+	return nil
+}
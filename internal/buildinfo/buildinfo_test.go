@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildinfo
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-packet/version"
+)
+
+func TestReporterPublish(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	c := fake.NewClientBuilder().Build()
+	reporter := &Reporter{Client: c, Namespace: "capp-system", Features: []string{"sharding", "read-only"}}
+
+	g.Expect(reporter.Publish(ctx)).To(Succeed())
+
+	configMap := &corev1.ConfigMap{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: "capp-system", Name: ConfigMapName}, configMap)).To(Succeed())
+	g.Expect(configMap.Data).To(HaveKeyWithValue("version", version.Get().GitVersion))
+	g.Expect(configMap.Data).To(HaveKeyWithValue("contract", version.ClusterAPIContract))
+	g.Expect(configMap.Data).To(HaveKeyWithValue("features", "sharding,read-only"))
+
+	// Publishing again updates the ConfigMap in place.
+	reporter.Features = nil
+	g.Expect(reporter.Publish(ctx)).To(Succeed())
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: "capp-system", Name: ConfigMapName}, configMap)).To(Succeed())
+	g.Expect(configMap.Data).To(HaveKeyWithValue("features", ""))
+}
+
+func TestInstallationRegistry(t *testing.T) {
+	g := NewWithT(t)
+
+	registry := prometheus.NewRegistry()
+	provider := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "capp_test_total"}, []string{"cluster_name"})
+	library := prometheus.NewCounter(prometheus.CounterOpts{Name: "library_test_total"})
+	registry.MustRegister(provider, library)
+	provider.WithLabelValues("cluster").Inc()
+	library.Inc()
+
+	families, err := (&installationRegistry{RegistererGatherer: registry, installation: "mgmt-eu"}).Gather()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(families).To(HaveLen(2))
+
+	labels := map[string]map[string]string{}
+	for _, family := range families {
+		labels[family.GetName()] = map[string]string{}
+		for _, label := range family.Metric[0].Label {
+			labels[family.GetName()][label.GetName()] = label.GetValue()
+		}
+	}
+	g.Expect(labels["capp_test_total"]).To(Equal(map[string]string{"cluster_name": "cluster", InstallationLabel: "mgmt-eu"}))
+	g.Expect(labels["library_test_total"]).To(BeEmpty())
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emlb
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// Older versions of the provider kept the state of the load balancer in annotations, which are migrated to the status
+// of PacketClusters and PacketMachines.
+const (
+	// loadBalancerIDAnnotation is the anotation key representing the ID of the allocated LoadBalancer for a PacketCluster.
+	loadBalancerIDAnnotation = "equinix.com/loadbalancerID"
+	// loadBalancerPortNumberAnnotation is the anotation key representing the allocated listner port number for a PacketCluster.
+	loadBalancerPortNumberAnnotation = "equinix.com/loadbalancerPortNumber"
+	// loadBalancerMetroAnnotation is the anotation key representing the metro of the loadbalancer for a PacketCluster.
+	loadBalancerMetroAnnotation = "equinix.com/loadbalancerMetro"
+	// loadBalancerPoolIDAnnotation is the anotation key representing the ID of the origin pool for a PacketCluster.
+	loadBalancerPoolIDAnnotation = "equinix.com/loadbalancerpoolID"
+	// loadBalancerPoolOriginIDAnnotation is the anotation key representing the origin ID of a PacketMachine.
+	loadBalancerOriginIDAnnotation = "equinix.com/loadbalanceroriginID"
+	// loadBalancerNamedPoolIDAnnotationPrefix is the anotation key prefix representing the ID of a named load balancer pool of a PacketCluster.
+	loadBalancerNamedPoolIDAnnotationPrefix = "equinix.com/loadbalancerpoolID-"
+	// loadBalancerNamedOriginIDAnnotationPrefix is the anotation key prefix representing the origin ID of a PacketMachine in a named load balancer pool.
+	loadBalancerNamedOriginIDAnnotationPrefix = "equinix.com/loadbalanceroriginID-"
+)
+
+// MigrateClusterAnnotations moves the state of the load balancer of a PacketCluster from its annotations to its
+// status. The state is copied to the status first, and the annotations are only removed by a later reconciliation,
+// once the status was saved, so that the state is not lost if saving the status fails.
+func MigrateClusterAnnotations(packetCluster *infrav1.PacketCluster) {
+	if packetCluster.Status.LoadBalancer == nil {
+		packetCluster.Status.LoadBalancer = loadBalancerStatusFromAnnotations(packetCluster.Annotations)
+		return
+	}
+	for annotation := range packetCluster.Annotations {
+		switch {
+		case annotation == loadBalancerIDAnnotation,
+			annotation == loadBalancerPortNumberAnnotation,
+			annotation == loadBalancerMetroAnnotation,
+			strings.HasPrefix(annotation, loadBalancerNamedPoolIDAnnotationPrefix):
+			delete(packetCluster.Annotations, annotation)
+		}
+	}
+}
+
+// MigrateMachineAnnotations moves the origins of the device of a PacketMachine from its annotations to its status,
+// like MigrateClusterAnnotations.
+func MigrateMachineAnnotations(packetMachine *infrav1.PacketMachine) {
+	status := &packetMachine.Status
+	if status.LoadBalancerOrigin == nil && len(status.LoadBalancerPoolOrigins) == 0 {
+		status.LoadBalancerOrigin, status.LoadBalancerPoolOrigins = originsFromAnnotations(packetMachine.Annotations)
+		return
+	}
+	for annotation := range packetMachine.Annotations {
+		switch {
+		case annotation == loadBalancerPoolIDAnnotation,
+			annotation == loadBalancerOriginIDAnnotation,
+			strings.HasPrefix(annotation, loadBalancerNamedOriginIDAnnotationPrefix):
+			delete(packetMachine.Annotations, annotation)
+		}
+	}
+}
+
+// LoadBalancerID returns the ID of the load balancer of a PacketCluster, or "" if it has none yet.
+func LoadBalancerID(packetCluster *infrav1.PacketCluster) string {
+	if status := clusterLoadBalancerStatus(packetCluster); status != nil {
+		return status.ID
+	}
+	return ""
+}
+
+// clusterLoadBalancerStatus returns the state of the load balancer of a PacketCluster, from its annotations if it was
+// not migrated yet, e.g. when read by the PacketMachine controller.
+func clusterLoadBalancerStatus(packetCluster *infrav1.PacketCluster) *infrav1.LoadBalancerStatus {
+	if packetCluster.Status.LoadBalancer != nil {
+		return packetCluster.Status.LoadBalancer
+	}
+	return loadBalancerStatusFromAnnotations(packetCluster.Annotations)
+}
+
+func loadBalancerStatusFromAnnotations(annotations map[string]string) *infrav1.LoadBalancerStatus {
+	lbID := annotations[loadBalancerIDAnnotation]
+	if lbID == "" {
+		return nil
+	}
+
+	status := &infrav1.LoadBalancerStatus{ID: lbID, Metro: annotations[loadBalancerMetroAnnotation]}
+	if portNumber, err := strconv.ParseInt(annotations[loadBalancerPortNumberAnnotation], 10, 32); err == nil {
+		status.PortNumber = int32(portNumber)
+	}
+	for annotation, poolID := range annotations {
+		if name, ok := strings.CutPrefix(annotation, loadBalancerNamedPoolIDAnnotationPrefix); ok && poolID != "" {
+			status.Pools = append(status.Pools, infrav1.LoadBalancerPoolStatus{Name: name, ID: poolID})
+		}
+	}
+	slices.SortFunc(status.Pools, func(a, b infrav1.LoadBalancerPoolStatus) int { return strings.Compare(a.Name, b.Name) })
+	return status
+}
+
+func originsFromAnnotations(annotations map[string]string) (*infrav1.LoadBalancerOrigin, []infrav1.LoadBalancerPoolOrigin) {
+	var origin *infrav1.LoadBalancerOrigin
+	if annotations[loadBalancerPoolIDAnnotation] != "" || annotations[loadBalancerOriginIDAnnotation] != "" {
+		origin = &infrav1.LoadBalancerOrigin{
+			PoolID:   annotations[loadBalancerPoolIDAnnotation],
+			OriginID: annotations[loadBalancerOriginIDAnnotation],
+		}
+	}
+
+	var poolOrigins []infrav1.LoadBalancerPoolOrigin
+	for annotation, originID := range annotations {
+		if name, ok := strings.CutPrefix(annotation, loadBalancerNamedOriginIDAnnotationPrefix); ok && originID != "" {
+			poolOrigins = append(poolOrigins, infrav1.LoadBalancerPoolOrigin{Pool: name, OriginID: originID})
+		}
+	}
+	slices.SortFunc(poolOrigins, func(a, b infrav1.LoadBalancerPoolOrigin) int { return strings.Compare(a.Pool, b.Pool) })
+	return origin, poolOrigins
+}
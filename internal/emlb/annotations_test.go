@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emlb
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestMigrateClusterAnnotations(t *testing.T) {
+	g := NewWithT(t)
+
+	packetCluster := &infrav1.PacketCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				loadBalancerIDAnnotation:                               "lb-1",
+				loadBalancerPortNumberAnnotation:                       "6443",
+				loadBalancerMetroAnnotation:                            "da",
+				loadBalancerNamedPoolIDAnnotationPrefix + "https":      "pool-2",
+				loadBalancerNamedPoolIDAnnotationPrefix + "http":       "pool-1",
+				"cluster.x-k8s.io/managed-by":                          "",
+				loadBalancerNamedPoolIDAnnotationPrefix + "incomplete": "",
+			},
+		},
+	}
+	want := &infrav1.LoadBalancerStatus{
+		ID:         "lb-1",
+		PortNumber: 6443,
+		Metro:      "da",
+		Pools: []infrav1.LoadBalancerPoolStatus{
+			{Name: "http", ID: "pool-1"},
+			{Name: "https", ID: "pool-2"},
+		},
+	}
+
+	// Machines can read the state of clusters that were not migrated yet.
+	g.Expect(LoadBalancerID(packetCluster)).To(Equal("lb-1"))
+
+	// The state is copied to the status first, keeping the annotations.
+	MigrateClusterAnnotations(packetCluster)
+	g.Expect(packetCluster.Status.LoadBalancer).To(Equal(want))
+	g.Expect(packetCluster.Annotations).To(HaveKey(loadBalancerIDAnnotation))
+
+	// The annotations are removed once the status exists.
+	MigrateClusterAnnotations(packetCluster)
+	g.Expect(packetCluster.Status.LoadBalancer).To(Equal(want))
+	g.Expect(packetCluster.Annotations).To(Equal(map[string]string{"cluster.x-k8s.io/managed-by": ""}))
+	g.Expect(LoadBalancerID(packetCluster)).To(Equal("lb-1"))
+
+	// Clusters without a load balancer have nothing to migrate.
+	packetCluster = &infrav1.PacketCluster{}
+	MigrateClusterAnnotations(packetCluster)
+	g.Expect(packetCluster.Status.LoadBalancer).To(BeNil())
+	g.Expect(LoadBalancerID(packetCluster)).To(BeEmpty())
+}
+
+func TestMigrateMachineAnnotations(t *testing.T) {
+	g := NewWithT(t)
+
+	packetMachine := &infrav1.PacketMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				loadBalancerPoolIDAnnotation:                        "pool-0",
+				loadBalancerOriginIDAnnotation:                      "origin-0",
+				loadBalancerNamedOriginIDAnnotationPrefix + "https": "origin-2",
+				loadBalancerNamedOriginIDAnnotationPrefix + "http":  "origin-1",
+				"cluster.x-k8s.io/cloned-from-name":                 "template",
+			},
+		},
+	}
+
+	MigrateMachineAnnotations(packetMachine)
+	g.Expect(packetMachine.Status.LoadBalancerOrigin).To(Equal(&infrav1.LoadBalancerOrigin{PoolID: "pool-0", OriginID: "origin-0"}))
+	g.Expect(packetMachine.Status.LoadBalancerPoolOrigins).To(Equal([]infrav1.LoadBalancerPoolOrigin{
+		{Pool: "http", OriginID: "origin-1"},
+		{Pool: "https", OriginID: "origin-2"},
+	}))
+	g.Expect(packetMachine.Annotations).To(HaveLen(5))
+
+	MigrateMachineAnnotations(packetMachine)
+	g.Expect(packetMachine.Annotations).To(Equal(map[string]string{"cluster.x-k8s.io/cloned-from-name": "template"}))
+	g.Expect(packetMachine.Status.LoadBalancerPoolOrigins).To(HaveLen(2))
+}
+
+func Test_loadBalancerPoolIDs(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(loadBalancerPoolID(nil, "http")).To(BeEmpty())
+
+	lbStatus := &infrav1.LoadBalancerStatus{ID: "lb-1"}
+	setLoadBalancerPoolID(lbStatus, "http", "pool-1")
+	setLoadBalancerPoolID(lbStatus, "https", "pool-2")
+	setLoadBalancerPoolID(lbStatus, "http", "pool-3")
+	g.Expect(lbStatus.Pools).To(Equal([]infrav1.LoadBalancerPoolStatus{
+		{Name: "http", ID: "pool-3"},
+		{Name: "https", ID: "pool-2"},
+	}))
+	g.Expect(loadBalancerPoolID(lbStatus, "https")).To(Equal("pool-2"))
+	g.Expect(loadBalancerPoolID(lbStatus, "missing")).To(BeEmpty())
+
+	packetMachine := &infrav1.PacketMachine{}
+	setLoadBalancerPoolOriginID(packetMachine, "http", "origin-1")
+	setLoadBalancerPoolOriginID(packetMachine, "http", "origin-2")
+	g.Expect(packetMachine.Status.LoadBalancerPoolOrigins).To(Equal([]infrav1.LoadBalancerPoolOrigin{{Pool: "http", OriginID: "origin-2"}}))
+	g.Expect(loadBalancerPoolOriginID(packetMachine, "http")).To(Equal("origin-2"))
+	g.Expect(loadBalancerPoolOriginID(packetMachine, "https")).To(BeEmpty())
+}
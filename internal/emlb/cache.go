@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emlb
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCacheTTL is how long a Load Balancer API lookup is reused before it is fetched again.
+	defaultCacheTTL = 30 * time.Second
+)
+
+// lookupCaches cache the Load Balancer API lookups of EMLB clients, one cache per API key and project so that the
+// lookups of a cluster are never served to a cluster using other credentials. EMLB objects are created for every
+// reconcile, so the caches live at the package level to be useful across reconciles and machines.
+var lookupCaches = struct {
+	sync.Mutex
+	now func() time.Time
+	// pruned is when the caches were last pruned.
+	pruned time.Time
+	caches map[string]*ttlCache
+}{now: time.Now, caches: map[string]*ttlCache{}}
+
+// lookupCacheFor returns the lookup cache of the EMLB clients of an API key and project. The key is hashed so that
+// it is not kept around in the clear.
+func lookupCacheFor(metalAPIKey, projectID string) *ttlCache {
+	key := fmt.Sprintf("%x/%s", sha256.Sum256([]byte(metalAPIKey)), projectID)
+
+	lookupCaches.Lock()
+	defer lookupCaches.Unlock()
+	now := lookupCaches.now()
+	if now.Sub(lookupCaches.pruned) >= defaultCacheTTL {
+		pruneLookupCaches(now)
+	}
+	cache, ok := lookupCaches.caches[key]
+	if !ok {
+		cache = newTTLCache(defaultCacheTTL)
+		cache.now = lookupCaches.now
+		lookupCaches.caches[key] = cache
+	}
+	cache.lastUsed = now
+	return cache
+}
+
+// pruneLookupCaches drops the caches left without entries that no client was handed for a TTL, so that the caches
+// of deleted clusters and rotated API keys do not pile up. A client still holding a dropped cache only loses the
+// sharing of its lookups. It must be called with lookupCaches locked.
+func pruneLookupCaches(now time.Time) {
+	for key, cache := range lookupCaches.caches {
+		if cache.prune() && now.Sub(cache.lastUsed) >= defaultCacheTTL {
+			delete(lookupCaches.caches, key)
+		}
+	}
+	lookupCaches.pruned = now
+}
+
+// ttlCache is a minimal, concurrency safe key/value cache whose entries expire after a fixed TTL.
+type ttlCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]cacheEntry
+
+	// lastUsed is when the cache was last handed to a client, guarded by lookupCaches.
+	lastUsed time.Time
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+// get returns the cached value for key, if present and not expired.
+func (c *ttlCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// prune removes the expired entries of the cache, and reports whether it is left empty.
+func (c *ttlCache) prune() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	return len(c.entries) == 0
+}
+
+// set stores value under key until the cache TTL elapses.
+func (c *ttlCache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry{
+		value:   value,
+		expires: c.now().Add(c.ttl),
+	}
+}
+
+// invalidate removes the given keys from the cache.
+func (c *ttlCache) invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+}
+
+// invalidatePrefix removes every key starting with prefix from the cache.
+func (c *ttlCache) invalidatePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// getLookup returns a copy of the lookup cached under key, so that callers never share the cached object, nor change
+// it for the other clients.
+func getLookup[T any](c *ttlCache, key string) (*T, bool) {
+	cached, ok := c.get(key)
+	if !ok {
+		return nil, false
+	}
+	lookup := new(T)
+	if err := json.Unmarshal(cached.([]byte), lookup); err != nil {
+		return nil, false
+	}
+	return lookup, true
+}
+
+// setLookup caches a copy of lookup under key. The lbaas models are made of pointers and slices and have no deep
+// copy, so lookups are cached as their JSON encoding, which they came from in the first place. Lookups that cannot be
+// encoded are not cached.
+func setLookup(c *ttlCache, key string, lookup interface{}) {
+	data, err := json.Marshal(lookup)
+	if err != nil {
+		return
+	}
+	c.set(key, data)
+}
+
+func loadBalancerCacheKey(lbID string) string {
+	return fmt.Sprintf("loadbalancer/%s", lbID)
+}
+
+func portCacheKeyPrefix(lbID string) string {
+	return fmt.Sprintf("port/%s/", lbID)
+}
+
+func portCacheKey(lbID string, portNumber int32) string {
+	return fmt.Sprintf("%s%d", portCacheKeyPrefix(lbID), portNumber)
+}
+
+func poolCacheKey(poolID string) string {
+	return fmt.Sprintf("pool/%s", poolID)
+}
+
+func originsCacheKey(poolID string) string {
+	return fmt.Sprintf("origins/%s", poolID)
+}
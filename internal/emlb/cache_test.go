@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emlb
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	lbaas "sigs.k8s.io/cluster-api-provider-packet/internal/lbaas/v1"
+)
+
+func Test_ttlCache(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	c := newTTLCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.set(loadBalancerCacheKey("lb-1"), "lb")
+	c.set(portCacheKey("lb-1", 6443), "port")
+	c.set(poolCacheKey("pool-1"), "pool")
+
+	got, ok := c.get(loadBalancerCacheKey("lb-1"))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(got).To(Equal("lb"))
+
+	// Entries expire once the TTL has elapsed.
+	now = now.Add(2 * time.Minute)
+	_, ok = c.get(loadBalancerCacheKey("lb-1"))
+	g.Expect(ok).To(BeFalse())
+	_, ok = c.get(poolCacheKey("pool-1"))
+	g.Expect(ok).To(BeFalse())
+}
+
+func Test_ttlCacheInvalidate(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newTTLCache(time.Minute)
+	c.set(loadBalancerCacheKey("lb-1"), "lb")
+	c.set(portCacheKey("lb-1", 6443), "port-1")
+	c.set(portCacheKey("lb-2", 6443), "port-2")
+	c.set(poolCacheKey("pool-1"), "pool")
+	c.set(originsCacheKey("pool-1"), "origins")
+
+	c.invalidate(poolCacheKey("pool-1"), originsCacheKey("pool-1"))
+	_, ok := c.get(poolCacheKey("pool-1"))
+	g.Expect(ok).To(BeFalse())
+	_, ok = c.get(originsCacheKey("pool-1"))
+	g.Expect(ok).To(BeFalse())
+
+	c.invalidatePrefix(portCacheKeyPrefix("lb-1"))
+	_, ok = c.get(portCacheKey("lb-1", 6443))
+	g.Expect(ok).To(BeFalse())
+	_, ok = c.get(portCacheKey("lb-2", 6443))
+	g.Expect(ok).To(BeTrue())
+	_, ok = c.get(loadBalancerCacheKey("lb-1"))
+	g.Expect(ok).To(BeTrue())
+}
+
+func Test_lookupCopies(t *testing.T) {
+	g := NewWithT(t)
+
+	c := newTTLCache(time.Minute)
+	lb := &lbaas.LoadBalancer{Id: "lb-1", Name: "my-cluster-capp-vip", Ips: []string{"192.0.2.1"}}
+	setLookup(c, loadBalancerCacheKey("lb-1"), lb)
+
+	// Neither the cached object nor the ones served from the cache are shared with callers.
+	lb.Ips[0] = "192.0.2.2"
+	got, ok := getLookup[lbaas.LoadBalancer](c, loadBalancerCacheKey("lb-1"))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(got.Name).To(Equal("my-cluster-capp-vip"))
+	g.Expect(got.Ips).To(Equal([]string{"192.0.2.1"}))
+
+	got.Ips[0] = "192.0.2.3"
+	again, ok := getLookup[lbaas.LoadBalancer](c, loadBalancerCacheKey("lb-1"))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(again).NotTo(BeIdenticalTo(got))
+	g.Expect(again.Ips).To(Equal([]string{"192.0.2.1"}))
+}
+
+func Test_lookupCacheFor(t *testing.T) {
+	g := NewWithT(t)
+
+	tenantA := lookupCacheFor("key-a", "project-a")
+	tenantA.set(loadBalancerCacheKey("lb-1"), "lb")
+
+	// Clients of the same credentials share their lookups.
+	g.Expect(lookupCacheFor("key-a", "project-a")).To(BeIdenticalTo(tenantA))
+	g.Expect(NewEMLB("key-a", "project-a", "da").lookupCache).To(BeIdenticalTo(tenantA))
+
+	// Clients of other credentials or projects never see them.
+	for _, other := range []*ttlCache{lookupCacheFor("key-b", "project-a"), lookupCacheFor("key-a", "project-b")} {
+		_, ok := other.get(loadBalancerCacheKey("lb-1"))
+		g.Expect(ok).To(BeFalse())
+	}
+}
+
+func Test_lookupCacheForPrunesIdleCaches(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	lookupCaches.Lock()
+	lookupCaches.now = func() time.Time { return now }
+	lookupCaches.Unlock()
+	t.Cleanup(func() {
+		lookupCaches.Lock()
+		lookupCaches.now = time.Now
+		lookupCaches.Unlock()
+	})
+
+	active := lookupCacheFor("prune-key-a", "project-a")
+	active.set(loadBalancerCacheKey("lb-1"), "lb")
+	idle := lookupCacheFor("prune-key-b", "project-a")
+	idle.set(loadBalancerCacheKey("lb-2"), "lb")
+
+	// Once their entries expired and no client asked for them for a TTL, caches are dropped, unless they got new
+	// entries in the meantime.
+	now = now.Add(2 * defaultCacheTTL)
+	active.set(loadBalancerCacheKey("lb-1"), "lb")
+	lookupCacheFor("prune-key-c", "project-a")
+
+	g.Expect(lookupCacheFor("prune-key-a", "project-a")).To(BeIdenticalTo(active))
+	g.Expect(lookupCacheFor("prune-key-b", "project-a")).NotTo(BeIdenticalTo(idle))
+}
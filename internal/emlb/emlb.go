@@ -24,31 +24,23 @@ import (
 	"net/http"
 	"os"
 	"reflect"
-	"strconv"
+	"slices"
 
 	corev1 "k8s.io/api/core/v1"
 	lbaas "sigs.k8s.io/cluster-api-provider-packet/internal/lbaas/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/readonly"
 	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
 )
 
 const (
 	// providerID is the provider id key used to talk to the Load Balancer as a Service API.
 	providerID = "loadpvd-gOB_-byp5ebFo7A3LHv2B"
-	// loadBalancerIDAnnotation is the anotation key representing the ID of the allocated LoadBalancer for a PacketCluster.
-	loadBalancerIDAnnotation = "equinix.com/loadbalancerID"
-	// loadBalancerPortNumberAnnotation is the anotation key representing the allocated listner port number for a PacketCluster.
-	loadBalancerPortNumberAnnotation = "equinix.com/loadbalancerPortNumber"
-	// loadBalancerMetroAnnotation is the anotation key representing the metro of the loadbalancer for a PacketCluster.
-	loadBalancerMetroAnnotation = "equinix.com/loadbalancerMetro"
-	// loadBalancerVIPPort is the port number of the API Server.
-	loadBalancerVIPPort = 6443 // TODO Change this to a env variable
-	// loadBalancerPoolIDAnnotation is the anotation key representing the ID of the origin pool for a PacketCluster.
-	loadBalancerPoolIDAnnotation = "equinix.com/loadbalancerpoolID"
-	// loadBalancerPoolOriginIDAnnotation is the anotation key representing the origin ID of a PacketMachine.
-	loadBalancerOriginIDAnnotation = "equinix.com/loadbalanceroriginID"
+	// apiServerPort is the port the API server listens on on the control plane devices.
+	apiServerPort = 6443
 	// loadbalancerTokenExchangeURL is the default URL to use for Token Exchange to talk to the Equinix Metal Load Balancer API.
 	loadbalancerTokenExchnageURL = "https://iam.metalctrl.io/api-keys/exchange" //nolint:gosec
 )
@@ -77,6 +69,18 @@ type EMLB struct {
 	metro          string
 	projectID      string
 	TokenExchanger *TokenExchanger
+
+	// lookupCache caches the lookups of the clients sharing the API key and project of this one.
+	lookupCache *ttlCache
+}
+
+// readOnly keeps the load balancer clients from changing any load balancer resource.
+var readOnly bool
+
+// SetReadOnly keeps the load balancer clients created from then on from changing any load balancer resource, see
+// readonly.NewTransport.
+func SetReadOnly() {
+	readOnly = true
 }
 
 // NewEMLB creates a new Equinix Metal Load Balancer API client object.
@@ -84,36 +88,54 @@ func NewEMLB(metalAPIKey, projectID, metro string) *EMLB {
 	manager := &EMLB{}
 	emlbConfig := lbaas.NewConfiguration()
 	emlbConfig.Debug = checkDebugEnabled()
+	if readOnly {
+		emlbConfig.HTTPClient = &http.Client{Transport: readonly.NewTransport(http.DefaultTransport)}
+	}
 
 	manager.client = lbaas.NewAPIClient(emlbConfig)
 	manager.TokenExchanger = &TokenExchanger{
 		metalAPIKey:      metalAPIKey,
 		tokenExchangeURL: loadbalancerTokenExchnageURL,
-		client:           manager.client.GetConfig().HTTPClient,
+		// The token exchange does not change anything and is allowed in read-only mode.
+		client: http.DefaultClient,
 	}
 	manager.projectID = projectID
 	manager.metro = metro
+	manager.lookupCache = lookupCacheFor(metalAPIKey, projectID)
 
 	return manager
 }
 
 // ReconcileLoadBalancer creates a new Equinix Metal Load Balancer and associates it with the given ClusterScope.
-func (e *EMLB) ReconcileLoadBalancer(ctx context.Context, clusterScope *scope.ClusterScope) error {
+func (e *EMLB) ReconcileLoadBalancer(ctx context.Context, clusterScope *scope.ClusterScope) (err error) {
+	defer func() { recordOperation("reconcile_load_balancer", clusterScope.MetricLabels(), err) }()
+
 	log := ctrl.LoggerFrom(ctx)
 
 	packetCluster := clusterScope.PacketCluster
 	clusterName := packetCluster.Name
 
-	// See if the cluster already has an EMLB ID in its packetCluster annotations
-	lbID, exists := packetCluster.Annotations[loadBalancerIDAnnotation]
-	if !exists {
-		lbID = ""
+	// See if the cluster already has an EMLB ID in its packetCluster status
+	lbID := ""
+	if packetCluster.Status.LoadBalancer != nil {
+		lbID = packetCluster.Status.LoadBalancer.ID
+	}
+
+	// An existing load balancer is adopted instead of creating one.
+	if lbID == "" && packetCluster.Spec.LoadBalancer != nil {
+		lb, err := e.existingLoadBalancer(ctx, packetCluster.Spec.LoadBalancer, packetCluster.Spec.EMLB)
+		if err != nil {
+			log.Error(err, "Existing Load Balancer cannot be used")
+			return err
+		}
+		lbID = lb.GetId()
+		log.Info("Adopting existing EMLB", "Load Balancer ID", lbID, "Load Balancer Name", lb.GetName())
 	}
 
 	log.Info("Reconciling EMLB", "Cluster Metro", e.metro, "Cluster Name", clusterName, "Project ID", e.projectID, "Load Balancer ID", lbID)
 
 	// Attempt to create the load balancer
-	lb, lbPort, err := e.ensureLoadBalancer(ctx, lbID, getResourceName(clusterName, "capp-vip"), loadBalancerVIPPort)
+	lb, lbPort, err := e.ensureLoadBalancer(ctx, lbID, getResourceName(clusterName, "capp-vip"), packetCluster.Spec.EMLB)
 	if err != nil {
 		log.Error(err, "Ensure Load Balancer failed.")
 		return err
@@ -124,34 +146,35 @@ func (e *EMLB) ReconcileLoadBalancer(ctx context.Context, clusterScope *scope.Cl
 	// Set the ControlPlaneEndpoint field on the PacketCluster object.
 	packetCluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{
 		Host: lb.GetIps()[0],
-		Port: loadBalancerVIPPort,
+		Port: lbPort.GetNumber(),
 	}
 
-	// Get a string version of the EMLB Listener port number
-	portNumber := strconv.Itoa(int(lbPort.GetNumber()))
-
-	// Set the packetcluster object's annotations with load balancer info for future reference
-	packetCluster.Annotations[loadBalancerIDAnnotation] = lb.GetId()
-	packetCluster.Annotations[loadBalancerPortNumberAnnotation] = portNumber
-	packetCluster.Annotations[loadBalancerMetroAnnotation] = e.metro
+	// Set the packetcluster object's status with load balancer info for future reference, keeping its pools
+	if packetCluster.Status.LoadBalancer == nil {
+		packetCluster.Status.LoadBalancer = &infrav1.LoadBalancerStatus{}
+	}
+	packetCluster.Status.LoadBalancer.ID = lb.GetId()
+	packetCluster.Status.LoadBalancer.PortID = lbPort.GetId()
+	packetCluster.Status.LoadBalancer.PortNumber = lbPort.GetNumber()
+	packetCluster.Status.LoadBalancer.Metro = e.metro
 
 	return nil
 }
 
 // ReconcileVIPOrigin adds the external IP of a new device to the EMLB Load balancer origin pool.
-func (e *EMLB) ReconcileVIPOrigin(ctx context.Context, machineScope *scope.MachineScope, deviceAddr []corev1.NodeAddress) error {
+func (e *EMLB) ReconcileVIPOrigin(ctx context.Context, machineScope *scope.MachineScope, deviceAddr []corev1.NodeAddress) (err error) {
+	defer func() { recordOperation("reconcile_vip_origin", machineScope.MetricLabels(), err) }()
+
 	log := ctrl.LoggerFrom(ctx)
 
 	packetCluster := machineScope.PacketCluster
 
-	// See if the cluster already has an EMLB ID in its packetCluster annotations.
-	lbID, exists := packetCluster.Annotations[loadBalancerIDAnnotation]
-	if !exists {
-		lbID = ""
-	}
-	if lbID == "" {
-		return fmt.Errorf("no Equinix Metal Load Balancer found in cluster's annotations")
+	// See if the cluster already has an EMLB ID in its packetCluster status.
+	lbStatus := clusterLoadBalancerStatus(packetCluster)
+	if lbStatus == nil || lbStatus.ID == "" {
+		return fmt.Errorf("no Equinix Metal Load Balancer found in cluster's status")
 	}
+	lbID := lbStatus.ID
 
 	// Fetch the Load Balancer object.
 	lb, _, err := e.getLoadBalancer(ctx, lbID)
@@ -159,23 +182,13 @@ func (e *EMLB) ReconcileVIPOrigin(ctx context.Context, machineScope *scope.Machi
 		return err
 	}
 
-	// See if the EMLB already has a Port ID in its packetCluster annotations.
-	lbPortNumber, exists := packetCluster.Annotations[loadBalancerPortNumberAnnotation]
-	if !exists {
-		lbPortNumber = ""
-	}
-	if lbPortNumber == "" {
-		return fmt.Errorf("no Equinix Metal Load Balancer Port Numberfound in cluster's annotations")
-	}
-
-	// Get an int version of the listener port number.
-	portNumber, err := strconv.ParseInt(lbPortNumber, 10, 32)
-	if err != nil {
-		return err
+	// See if the EMLB already has a listener port number in its packetCluster status.
+	if lbStatus.PortNumber == 0 {
+		return fmt.Errorf("no Equinix Metal Load Balancer Port Number found in cluster's status")
 	}
 
 	// Get the entire listener port object.
-	lbPort, err := e.getLoadBalancerPort(ctx, lbID, int32(portNumber))
+	lbPort, err := e.getLoadBalancerPort(ctx, lbID, lbStatus.PortNumber)
 	if err != nil {
 		return err
 	}
@@ -183,14 +196,16 @@ func (e *EMLB) ReconcileVIPOrigin(ctx context.Context, machineScope *scope.Machi
 	// Fetch the listener port id.
 	lbPortID := lbPort.GetId()
 
-	// See if the cluster already has an EMLB Origin Pool ID in its packetCluster annotations.
-	lbPoolID, exists := machineScope.PacketMachine.Annotations[loadBalancerPoolIDAnnotation]
-	if !exists {
-		lbPoolID = ""
+	// See if the machine already has an EMLB Origin Pool ID in its packetMachine status.
+	origin := machineScope.PacketMachine.Status.LoadBalancerOrigin
+	if origin == nil {
+		origin = &infrav1.LoadBalancerOrigin{}
+		machineScope.PacketMachine.Status.LoadBalancerOrigin = origin
 	}
+	lbPoolID := origin.PoolID
 
 	// Get the Load Balancer pool or create it.
-	lbPool, err := e.ensureLoadBalancerPool(ctx, lbPoolID, lb.GetName())
+	lbPool, err := e.ensureLoadBalancerPool(ctx, lbPoolID, poolNamePrefix(lb, packetCluster.Spec.EMLB), packetCluster.Spec.EMLB)
 	if err != nil {
 		log.Error(err, "LB Pool Creation/Validation Failed", "EMLB ID", lbID, "Pool ID", lbPoolID)
 		return err
@@ -200,16 +215,13 @@ func (e *EMLB) ReconcileVIPOrigin(ctx context.Context, machineScope *scope.Machi
 	lbPoolID = lbPool.GetId()
 
 	// Note the new Origin Pool ID for future reference
-	machineScope.PacketMachine.Annotations[loadBalancerPoolIDAnnotation] = lbPoolID
+	origin.PoolID = lbPoolID
 
-	// See if the PacketMachine already has an EMLB Origin ID in its packetCluster annotations.
-	lbOriginID, exists := machineScope.PacketMachine.Annotations[loadBalancerOriginIDAnnotation]
-	if !exists {
-		lbOriginID = ""
-	}
+	// See if the PacketMachine already has an EMLB Origin ID in its packetMachine status.
+	lbOriginID := origin.OriginID
 
 	// Get the Load Balancer origin or create it.
-	lbOrigin, err := e.ensureLoadBalancerOrigin(ctx, lbOriginID, lbPoolID, lb.GetName(), deviceAddr)
+	lbOrigin, err := e.ensureLoadBalancerOrigin(ctx, lbOriginID, lbPoolID, lb.GetName(), deviceAddr, apiServerPort)
 	if err != nil {
 		log.Error(err, "LB Pool Creation/Validation Failed", "EMLB ID", lbID, "Pool ID", lbPoolID, "Origin ID", lbOriginID)
 		return err
@@ -219,7 +231,7 @@ func (e *EMLB) ReconcileVIPOrigin(ctx context.Context, machineScope *scope.Machi
 	lbOriginID = lbOrigin.GetId()
 
 	// Note the PacketMachine's new EMLB Origin ID for future reference
-	machineScope.PacketMachine.Annotations[loadBalancerOriginIDAnnotation] = lbOriginID
+	origin.OriginID = lbOriginID
 
 	// Update the Load Balancer's Listener Port to point at the pool
 	lbPort, err = e.updateListenerPort(ctx, lbPoolID, lbPortID)
@@ -232,16 +244,23 @@ func (e *EMLB) ReconcileVIPOrigin(ctx context.Context, machineScope *scope.Machi
 }
 
 // DeleteClusterLoadBalancer deletes the Equinix Metal Load Balancer associated with a given ClusterScope.
-func (e *EMLB) DeleteClusterLoadBalancer(ctx context.Context, clusterScope *scope.ClusterScope) error {
+func (e *EMLB) DeleteClusterLoadBalancer(ctx context.Context, clusterScope *scope.ClusterScope) (err error) {
+	defer func() { recordOperation("delete_load_balancer", clusterScope.MetricLabels(), err) }()
+
 	log := ctrl.LoggerFrom(ctx)
 
 	packetCluster := clusterScope.PacketCluster
 	clusterName := packetCluster.Name
 
-	// Make sure the cluster already has an EMLB ID in its packetCluster annotations, otherwise abort.
-	lbID, exists := packetCluster.Annotations[loadBalancerIDAnnotation]
-	if !exists || (lbID == "") {
-		log.Info("no Equinix Metal Load Balancer found in cluster's annotations, skipping EMLB delete")
+	// Make sure the cluster already has an EMLB ID in its packetCluster status, otherwise abort.
+	lbID := LoadBalancerID(packetCluster)
+	if lbID == "" {
+		log.Info("no Equinix Metal Load Balancer found in cluster's status, skipping EMLB delete")
+		return nil
+	}
+
+	if existing := packetCluster.Spec.LoadBalancer; existing != nil && (existing.ExistingID != "" || existing.ExistingName != "") {
+		log.Info("Equinix Metal Load Balancer was not created for the cluster, skipping EMLB delete", "Load Balancer ID", lbID)
 		return nil
 	}
 
@@ -259,17 +278,20 @@ func (e *EMLB) DeleteClusterLoadBalancer(ctx context.Context, clusterScope *scop
 }
 
 // DeleteLoadBalancerOrigin deletes the Equinix Metal Load Balancer associated with a given ClusterScope.
-func (e *EMLB) DeleteLoadBalancerOrigin(ctx context.Context, machineScope *scope.MachineScope) error {
+func (e *EMLB) DeleteLoadBalancerOrigin(ctx context.Context, machineScope *scope.MachineScope) (err error) {
+	defer func() { recordOperation("delete_vip_origin", machineScope.MetricLabels(), err) }()
+
 	// Initially, we're creating a single pool per origin, logic below needs to be updated if we move to a shared load balancer pool model.
 	log := ctrl.LoggerFrom(ctx)
 
 	clusterName := machineScope.Cluster.Name
 
-	// Make sure the machine has an EMLB Pool ID in its packetMachine annotations, otherwise abort.
-	lbPoolID, exists := machineScope.PacketMachine.Annotations[loadBalancerPoolIDAnnotation]
-	if !exists || (lbPoolID == "") {
-		return fmt.Errorf("no Equinix Metal Load Balancer Pool found in machine's annotations")
+	// Make sure the machine has an EMLB Pool ID in its packetMachine status, otherwise abort.
+	origin := machineScope.PacketMachine.Status.LoadBalancerOrigin
+	if origin == nil || origin.PoolID == "" {
+		return fmt.Errorf("no Equinix Metal Load Balancer Pool found in machine's status")
 	}
+	lbPoolID := origin.PoolID
 
 	log.Info("Deleting EMLB Pool", "Cluster Metro", e.metro, "Cluster Name", clusterName, "Project ID", e.projectID, "Pool ID", lbPoolID)
 
@@ -284,6 +306,159 @@ func (e *EMLB) DeleteLoadBalancerOrigin(ctx context.Context, machineScope *scope
 	return err
 }
 
+// ReconcileLoadBalancerPools ensures the named load balancer pools of a PacketCluster exist and are served on their
+// listener ports of the cluster's Equinix Metal Load Balancer.
+func (e *EMLB) ReconcileLoadBalancerPools(ctx context.Context, clusterScope *scope.ClusterScope) (err error) {
+	defer func() { recordOperation("reconcile_pools", clusterScope.MetricLabels(), err) }()
+
+	log := ctrl.LoggerFrom(ctx)
+
+	packetCluster := clusterScope.PacketCluster
+
+	lbStatus := packetCluster.Status.LoadBalancer
+	if lbStatus == nil || lbStatus.ID == "" {
+		return fmt.Errorf("no Equinix Metal Load Balancer found in cluster's status")
+	}
+	lbID := lbStatus.ID
+
+	lb, _, err := e.getLoadBalancer(ctx, lbID)
+	if err != nil {
+		return err
+	}
+
+	for _, pool := range packetCluster.Spec.LoadBalancerPools {
+		// Get the Load Balancer pool or create it.
+		lbPool, err := e.ensureLoadBalancerPool(ctx, loadBalancerPoolID(lbStatus, pool.Name), getResourceName(poolNamePrefix(lb, packetCluster.Spec.EMLB), pool.Name), packetCluster.Spec.EMLB)
+		if err != nil {
+			log.Error(err, "LB Pool Creation/Validation Failed", "EMLB ID", lbID, "Pool", pool.Name)
+			return err
+		}
+
+		// Note the new Origin Pool ID for future reference
+		setLoadBalancerPoolID(lbStatus, pool.Name, lbPool.GetId())
+
+		// Get the listener port of the pool or create it.
+		lbPort, err := e.ensureListenerPort(ctx, lb, pool.Port)
+		if err != nil {
+			log.Error(err, "LB Port Creation/Validation Failed", "EMLB ID", lbID, "Pool", pool.Name, "Port", pool.Port)
+			return err
+		}
+
+		if slices.Contains(lbPort.GetPoolIds(), lbPool.GetId()) {
+			continue
+		}
+
+		// Update the Load Balancer's Listener Port to point at the pool
+		if _, err := e.updateListenerPort(ctx, lbPool.GetId(), lbPort.GetId()); err != nil {
+			log.Error(err, "LB Port Update Failed", "EMLB ID", lbID, "Pool ID", lbPool.GetId(), "Port ID", lbPort.GetId())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReconcileLoadBalancerPoolOrigins adds the external IP of a device to the named load balancer pools its PacketMachine
+// belongs to, and removes it from the pools it no longer belongs to.
+func (e *EMLB) ReconcileLoadBalancerPoolOrigins(ctx context.Context, machineScope *scope.MachineScope, deviceAddr []corev1.NodeAddress) (err error) {
+	defer func() { recordOperation("reconcile_pool_origins", machineScope.MetricLabels(), err) }()
+
+	log := ctrl.LoggerFrom(ctx)
+
+	packetCluster := machineScope.PacketCluster
+	lbStatus := clusterLoadBalancerStatus(packetCluster)
+
+	names := machineLoadBalancerPools(packetCluster.Spec.LoadBalancerPools, machineScope.PacketMachine.Spec.LoadBalancerPools, machineScope.IsControlPlane())
+	for _, name := range names {
+		pool := findLoadBalancerPool(packetCluster.Spec.LoadBalancerPools, name)
+		if pool == nil {
+			return fmt.Errorf("load balancer pool %q is not defined on the PacketCluster", name)
+		}
+
+		lbPoolID := loadBalancerPoolID(lbStatus, name)
+		if lbPoolID == "" {
+			return fmt.Errorf("no Equinix Metal Load Balancer Pool %q found in cluster's status", name)
+		}
+
+		lbOriginID := loadBalancerPoolOriginID(machineScope.PacketMachine, name)
+
+		// Get the Load Balancer origin or create it.
+		lbOrigin, err := e.ensureLoadBalancerOrigin(ctx, lbOriginID, lbPoolID, machineScope.Name(), deviceAddr, loadBalancerPoolTargetPort(pool))
+		if err != nil {
+			log.Error(err, "LB Origin Creation/Validation Failed", "Pool ID", lbPoolID, "Origin ID", lbOriginID)
+			return err
+		}
+
+		// Note the PacketMachine's new EMLB Origin ID for future reference
+		setLoadBalancerPoolOriginID(machineScope.PacketMachine, name, lbOrigin.GetId())
+	}
+
+	// Remove the device from the pools it was dropped from.
+	return e.deleteLoadBalancerPoolOrigins(ctx, machineScope, func(name string) bool {
+		return !slices.Contains(names, name)
+	})
+}
+
+// DeleteLoadBalancerPoolOrigins removes a PacketMachine's device from the named load balancer pools it was added to.
+func (e *EMLB) DeleteLoadBalancerPoolOrigins(ctx context.Context, machineScope *scope.MachineScope) (err error) {
+	defer func() { recordOperation("delete_pool_origins", machineScope.MetricLabels(), err) }()
+
+	return e.deleteLoadBalancerPoolOrigins(ctx, machineScope, func(string) bool { return true })
+}
+
+// deleteLoadBalancerPoolOrigins removes a PacketMachine's device from the named load balancer pools it was added to
+// and that match remove.
+func (e *EMLB) deleteLoadBalancerPoolOrigins(ctx context.Context, machineScope *scope.MachineScope, remove func(name string) bool) error {
+	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
+	log := ctrl.LoggerFrom(ctx)
+
+	status := &machineScope.PacketMachine.Status
+	for _, origin := range slices.Clone(status.LoadBalancerPoolOrigins) {
+		if origin.OriginID == "" || !remove(origin.Pool) {
+			continue
+		}
+
+		log.Info("Deleting EMLB Origin", "Pool", origin.Pool, "Origin ID", origin.OriginID)
+
+		resp, err := e.client.OriginsApi.DeleteLoadBalancerOrigin(ctx, origin.OriginID).Execute()
+		e.lookupCache.invalidatePrefix("origins/")
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return fmt.Errorf("failed to delete load balancer origin %s: %w", origin.OriginID, err)
+		}
+		status.LoadBalancerPoolOrigins = slices.DeleteFunc(status.LoadBalancerPoolOrigins, func(o infrav1.LoadBalancerPoolOrigin) bool {
+			return o.Pool == origin.Pool
+		})
+	}
+
+	return nil
+}
+
+// DeleteLoadBalancerPools deletes the named load balancer pools of a PacketCluster.
+func (e *EMLB) DeleteLoadBalancerPools(ctx context.Context, clusterScope *scope.ClusterScope) (err error) {
+	defer func() { recordOperation("delete_pools", clusterScope.MetricLabels(), err) }()
+
+	log := ctrl.LoggerFrom(ctx)
+
+	lbStatus := clusterScope.PacketCluster.Status.LoadBalancer
+	if lbStatus == nil {
+		return nil
+	}
+
+	for _, pool := range slices.Clone(lbStatus.Pools) {
+		log.Info("Deleting EMLB Pool", "Pool", pool.Name, "Pool ID", pool.ID)
+
+		resp, err := e.DeleteLoadBalancerPool(ctx, pool.ID)
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return fmt.Errorf("failed to delete load balancer pool %s: %w", pool.ID, err)
+		}
+		lbStatus.Pools = slices.DeleteFunc(lbStatus.Pools, func(p infrav1.LoadBalancerPoolStatus) bool {
+			return p.Name == pool.Name
+		})
+	}
+
+	return nil
+}
+
 // GetLoadBalancers returns a Load Balancer Collection of all the Equinix Metal Load Balancers in a project.
 func (e *EMLB) GetLoadBalancers(ctx context.Context) (*lbaas.LoadBalancerCollection, *http.Response, error) {
 	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
@@ -301,24 +476,69 @@ func (e *EMLB) GetLoadBalancerPools(ctx context.Context) (*lbaas.LoadBalancerPoo
 }
 
 // getLoadBalancer Returns a Load Balancer object given an id.
+// The response is nil when the Load Balancer was served from the lookup cache.
 func (e *EMLB) getLoadBalancer(ctx context.Context, id string) (*lbaas.LoadBalancer, *http.Response, error) {
+	if cached, ok := getLookup[lbaas.LoadBalancer](e.lookupCache, loadBalancerCacheKey(id)); ok {
+		return cached, nil, nil
+	}
+
 	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
 
 	LoadBalancer, resp, err := e.client.LoadBalancersApi.GetLoadBalancer(ctx, id).Execute()
+	if err == nil {
+		setLookup(e.lookupCache, loadBalancerCacheKey(id), LoadBalancer)
+	}
 	return LoadBalancer, resp, err
 }
 
 // getLoadBalancerPort Returns a Load Balancer Port object given an id.
 func (e *EMLB) getLoadBalancerPort(ctx context.Context, id string, portNumber int32) (*lbaas.LoadBalancerPort, error) {
+	if cached, ok := getLookup[lbaas.LoadBalancerPort](e.lookupCache, portCacheKey(id, portNumber)); ok {
+		return cached, nil
+	}
+
 	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
 
 	LoadBalancerPort, _, err := e.client.PortsApi.GetLoadBalancerPort(ctx, id, portNumber).Execute()
+	if err == nil {
+		setLookup(e.lookupCache, portCacheKey(id, portNumber), LoadBalancerPort)
+	}
 	return LoadBalancerPort, err
 }
 
+// getLoadBalancerPool Returns a Load Balancer Pool object given an id.
+func (e *EMLB) getLoadBalancerPool(ctx context.Context, poolID string) (*lbaas.LoadBalancerPool, error) {
+	if cached, ok := getLookup[lbaas.LoadBalancerPool](e.lookupCache, poolCacheKey(poolID)); ok {
+		return cached, nil
+	}
+
+	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
+
+	LoadBalancerPool, _, err := e.client.PoolsApi.GetLoadBalancerPool(ctx, poolID).Execute()
+	if err == nil {
+		setLookup(e.lookupCache, poolCacheKey(poolID), LoadBalancerPool)
+	}
+	return LoadBalancerPool, err
+}
+
+// getLoadBalancerPoolOrigins Returns the origins of a Load Balancer Pool given the pool id.
+func (e *EMLB) getLoadBalancerPoolOrigins(ctx context.Context, poolID string) (*lbaas.LoadBalancerPoolOriginCollection, error) {
+	if cached, ok := getLookup[lbaas.LoadBalancerPoolOriginCollection](e.lookupCache, originsCacheKey(poolID)); ok {
+		return cached, nil
+	}
+
+	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
+
+	LoadBalancerPoolOrigins, _, err := e.client.PoolsApi.ListLoadBalancerPoolOrigins(ctx, poolID).Execute()
+	if err == nil {
+		setLookup(e.lookupCache, originsCacheKey(poolID), LoadBalancerPoolOrigins)
+	}
+	return LoadBalancerPoolOrigins, err
+}
+
 // EnsureLoadBalancerOrigin takes the devices list of IP addresses in a Load Balancer Origin Pool and ensures an origin
-// for the first IPv4 address in the list exists.
-func (e *EMLB) ensureLoadBalancerOrigin(ctx context.Context, originID, poolID, lbName string, deviceAddr []corev1.NodeAddress) (*lbaas.LoadBalancerPoolOrigin, error) {
+// for the first IPv4 address in the list exists on the given port.
+func (e *EMLB) ensureLoadBalancerOrigin(ctx context.Context, originID, poolID, lbName string, deviceAddr []corev1.NodeAddress, port int32) (*lbaas.LoadBalancerPoolOrigin, error) {
 	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
 	log := ctrl.LoggerFrom(ctx)
 
@@ -327,6 +547,7 @@ func (e *EMLB) ensureLoadBalancerOrigin(ctx context.Context, originID, poolID, l
 		if err != nil {
 			return nil, err
 		}
+		target.Port = port
 		originCreated, _, err := e.createOrigin(ctx, poolID, getResourceName(lbName, "origin"), target)
 		if err != nil {
 			return nil, err
@@ -336,7 +557,7 @@ func (e *EMLB) ensureLoadBalancerOrigin(ctx context.Context, originID, poolID, l
 	}
 
 	// Regardless of whether we just created it, fetch the loadbalancer pool object.
-	lbOrigins, _, err := e.client.PoolsApi.ListLoadBalancerPoolOrigins(ctx, poolID).Execute()
+	lbOrigins, err := e.getLoadBalancerPoolOrigins(ctx, poolID)
 	if err != nil {
 		return nil, err
 	}
@@ -354,6 +575,7 @@ func (e *EMLB) ensureLoadBalancerOrigin(ctx context.Context, originID, poolID, l
 		if err != nil {
 			return nil, err
 		}
+		target.Port = port
 		if lbOrigin.Target == target.IP {
 			if *lbOrigin.GetPortNumber().Int32 == target.Port {
 				found = &lbOrigins.Origins[i]
@@ -362,6 +584,7 @@ func (e *EMLB) ensureLoadBalancerOrigin(ctx context.Context, originID, poolID, l
 		}
 		log.Info("Pool Origin with ID does not have correct IP address")
 		_, err = e.client.OriginsApi.DeleteLoadBalancerOrigin(ctx, lbOrigin.Id).Execute()
+		e.lookupCache.invalidate(originsCacheKey(poolID))
 		if err != nil {
 			return nil, err
 		}
@@ -370,13 +593,17 @@ func (e *EMLB) ensureLoadBalancerOrigin(ctx context.Context, originID, poolID, l
 	return found, err
 }
 
-// ensureLoadBalancerPool checks if the poolID exists and if not, creates it.
-func (e *EMLB) ensureLoadBalancerPool(ctx context.Context, poolID, lbName string) (*lbaas.LoadBalancerPool, error) {
+// ensureLoadBalancerPool checks if the poolID exists and if not, creates it with the health checks of the cluster.
+func (e *EMLB) ensureLoadBalancerPool(ctx context.Context, poolID, prefix string, config *infrav1.EMLBConfig) (*lbaas.LoadBalancerPool, error) {
 	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
 
 	// Pool doesn't exist, so let's create it.
 	if poolID == "" {
-		poolCreated, _, err := e.createPool(ctx, getResourceName(lbName, "pool"))
+		var healthCheck *infrav1.EMLBHealthCheck
+		if config != nil {
+			healthCheck = config.HealthCheck
+		}
+		poolCreated, _, err := e.createPool(ctx, getResourceName(prefix, "pool"), healthCheck)
 		if err != nil {
 			return nil, err
 		}
@@ -385,19 +612,20 @@ func (e *EMLB) ensureLoadBalancerPool(ctx context.Context, poolID, lbName string
 	}
 
 	// Regardless of whether we just created it, fetch the loadbalancer pool object.
-	lbPool, _, err := e.client.PoolsApi.GetLoadBalancerPool(ctx, poolID).Execute()
-	return lbPool, err
+	return e.getLoadBalancerPool(ctx, poolID)
 }
 
 // ensureLoadBalancer Takes a  Load Balancer id and ensures those pools and ensures it exists.
-func (e *EMLB) ensureLoadBalancer(ctx context.Context, lbID, lbname string, portNumber int32) (*lbaas.LoadBalancer, *lbaas.LoadBalancerPort, error) {
+func (e *EMLB) ensureLoadBalancer(ctx context.Context, lbID, lbname string, config *infrav1.EMLBConfig) (*lbaas.LoadBalancer, *lbaas.LoadBalancerPort, error) {
 	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
 
+	portNumber := config.ListenerPort()
+
 	// EMLB doesn't exist, so let's create it.
 	if lbID == "" {
-		locationID, ok := lbMetros[e.metro]
-		if !ok {
-			return nil, nil, fmt.Errorf("could not determine load balancer location for metro %v; valid values are %v", e.metro, reflect.ValueOf(lbMetros).MapKeys())
+		locationID, err := e.locationID(config)
+		if err != nil {
+			return nil, nil, err
 		}
 
 		lbCreated, _, err := e.createLoadBalancer(ctx, lbname, locationID, providerID)
@@ -428,6 +656,67 @@ func (e *EMLB) ensureLoadBalancer(ctx context.Context, lbID, lbname string, port
 	return lb, lbPort, err
 }
 
+// existingLoadBalancer returns the existing Load Balancer the cluster is configured to use, checking it can serve the
+// API server of the cluster.
+func (e *EMLB) existingLoadBalancer(ctx context.Context, config *infrav1.LoadBalancer, emlbConfig *infrav1.EMLBConfig) (*lbaas.LoadBalancer, error) {
+	var lb *lbaas.LoadBalancer
+	switch {
+	case config.ExistingID != "":
+		var err error
+		if lb, _, err = e.getLoadBalancer(ctx, config.ExistingID); err != nil {
+			return nil, fmt.Errorf("failed to get load balancer %s: %w", config.ExistingID, err)
+		}
+	case config.ExistingName != "":
+		lbs, _, err := e.GetLoadBalancers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list load balancers: %w", err)
+		}
+		if lb, err = findLoadBalancerByName(lbs.GetLoadbalancers(), config.ExistingName); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("no existing load balancer configured")
+	}
+
+	locationID, err := e.locationID(emlbConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateExistingLoadBalancer(lb, locationID, emlbConfig.ListenerPort()); err != nil {
+		return nil, err
+	}
+	return lb, nil
+}
+
+// locationID returns the ID of the location the Load Balancer of a cluster is in: the location it is configured
+// with, or the location of the metro of the cluster.
+func (e *EMLB) locationID(config *infrav1.EMLBConfig) (string, error) {
+	if config != nil && config.LocationID != "" {
+		return config.LocationID, nil
+	}
+	locationID, ok := lbMetros[e.metro]
+	if !ok {
+		return "", fmt.Errorf("could not determine load balancer location for metro %v; valid values are %v, or set emlb.locationID", e.metro, reflect.ValueOf(lbMetros).MapKeys())
+	}
+	return locationID, nil
+}
+
+// ensureListenerPort returns the listener port of the Load Balancer with the given number, creating it if needed.
+func (e *EMLB) ensureListenerPort(ctx context.Context, lb *lbaas.LoadBalancer, portNumber int32) (*lbaas.LoadBalancerPort, error) {
+	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
+
+	exists := slices.ContainsFunc(lb.GetPorts(), func(port lbaas.LoadBalancerPort) bool {
+		return port.GetNumber() == portNumber
+	})
+	if !exists {
+		if _, _, err := e.createListenerPort(ctx, lb.GetId(), getResourceName(lb.GetName(), fmt.Sprintf("port-%d", portNumber)), portNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.getLoadBalancerPort(ctx, lb.GetId(), portNumber)
+}
+
 func (e *EMLB) createLoadBalancer(ctx context.Context, lbName, locationID, providerID string) (*lbaas.ResourceCreatedResponse, *http.Response, error) {
 	lbCreateRequest := lbaas.LoadBalancerCreate{
 		Name:       lbName,
@@ -444,16 +733,21 @@ func (e *EMLB) createListenerPort(ctx context.Context, lbID, portName string, po
 		Number: portNumber,
 	}
 
+	defer e.lookupCache.invalidate(loadBalancerCacheKey(lbID), portCacheKey(lbID, portNumber))
 	return e.client.PortsApi.CreateLoadBalancerPort(ctx, lbID).LoadBalancerPortCreate(portRequest).Execute()
 }
 
-func (e *EMLB) createPool(ctx context.Context, name string) (*lbaas.ResourceCreatedResponse, *http.Response, error) {
+func (e *EMLB) createPool(ctx context.Context, name string, healthCheck *infrav1.EMLBHealthCheck) (*lbaas.ResourceCreatedResponse, *http.Response, error) {
 	createPoolRequest := lbaas.LoadBalancerPoolCreate{
 		Name: name,
 		Protocol: lbaas.LoadBalancerPoolCreateProtocol{
 			LoadBalancerPoolProtocol: lbaas.LOADBALANCERPOOLPROTOCOL_TCP.Ptr(),
 		},
 	}
+	// The health check settings of pools are not part of the generated client, they are sent as additional properties.
+	if properties := poolHealthCheckProperties(healthCheck); properties != nil {
+		createPoolRequest.AdditionalProperties = map[string]interface{}{"health_check": properties}
+	}
 	return e.client.ProjectsApi.CreatePool(ctx, e.projectID).LoadBalancerPoolCreate(createPoolRequest).Execute()
 }
 
@@ -465,18 +759,25 @@ func (e *EMLB) createOrigin(ctx context.Context, poolID, originName string, targ
 		Active:     true,
 		PoolId:     poolID,
 	}
+
+	defer e.lookupCache.invalidate(poolCacheKey(poolID), originsCacheKey(poolID))
 	return e.client.PoolsApi.CreateLoadBalancerPoolOrigin(ctx, poolID).LoadBalancerPoolOriginCreate(createOriginRequest).Execute()
 }
 
 // DeleteLoadBalancer deletes an Equinix Metal Load Balancer given an ID.
 func (e *EMLB) DeleteLoadBalancer(ctx context.Context, lbID string) (*http.Response, error) {
 	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
+
+	e.lookupCache.invalidate(loadBalancerCacheKey(lbID))
+	e.lookupCache.invalidatePrefix(portCacheKeyPrefix(lbID))
 	return e.client.LoadBalancersApi.DeleteLoadBalancer(ctx, lbID).Execute()
 }
 
 // DeleteLoadBalancerPool deletes an Equinix Metal Load Balancer Origin Pool given an ID.
 func (e *EMLB) DeleteLoadBalancerPool(ctx context.Context, poolID string) (*http.Response, error) {
 	ctx = context.WithValue(ctx, lbaas.ContextOAuth2, e.TokenExchanger)
+
+	e.lookupCache.invalidate(poolCacheKey(poolID), originsCacheKey(poolID))
 	return e.client.PoolsApi.DeleteLoadBalancerPool(ctx, poolID).Execute()
 }
 
@@ -489,7 +790,9 @@ func (e *EMLB) updateListenerPort(ctx context.Context, poolID, lbPortID string)
 	}
 
 	// Do the actual listener port update.
+	// Cached ports are keyed by load balancer and port number rather than port ID, so drop all of them.
 	lbPort, _, err := e.client.PortsApi.UpdateLoadBalancerPort(ctx, lbPortID).LoadBalancerPortUpdate(portUpdateRequest).Execute()
+	e.lookupCache.invalidatePrefix("port/")
 	if err != nil {
 		return nil, err
 	}
@@ -501,6 +804,146 @@ func getResourceName[T any](loadBalancerName string, resourceType T) string {
 	return fmt.Sprintf("%v-%v", loadBalancerName, resourceType)
 }
 
+// findLoadBalancerPool returns the load balancer pool with the given name, or nil.
+// findLoadBalancerByName returns the Load Balancer with the given name, which must be unique in the project.
+func findLoadBalancerByName(lbs []lbaas.LoadBalancer, name string) (*lbaas.LoadBalancer, error) {
+	var found *lbaas.LoadBalancer
+	for i := range lbs {
+		if lbs[i].GetName() != name {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("multiple load balancers are named %s, use existingID", name)
+		}
+		found = &lbs[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no load balancer named %s found", name)
+	}
+	return found, nil
+}
+
+// validateExistingLoadBalancer checks an existing Load Balancer is in the location of the cluster and has the listener
+// port of the API server.
+func validateExistingLoadBalancer(lb *lbaas.LoadBalancer, locationID string, portNumber int32) error {
+	if location := lb.GetLocation(); location.GetId() != "" && location.GetId() != locationID {
+		return fmt.Errorf("load balancer %s is not in location %s", lb.GetName(), locationID)
+	}
+	hasPort := slices.ContainsFunc(lb.GetPorts(), func(port lbaas.LoadBalancerPort) bool {
+		return port.GetNumber() == portNumber
+	})
+	if !hasPort {
+		return fmt.Errorf("load balancer %s has no listener port %d", lb.GetName(), portNumber)
+	}
+	return nil
+}
+
+// poolNamePrefix returns the prefix of the names of the pools created on the Load Balancer of a cluster.
+func poolNamePrefix(lb *lbaas.LoadBalancer, config *infrav1.EMLBConfig) string {
+	if config != nil && config.PoolNamePrefix != "" {
+		return config.PoolNamePrefix
+	}
+	return lb.GetName()
+}
+
+// poolHealthCheckProperties returns the health check settings of a Load Balancer pool, or nil to use the defaults of
+// the Load Balancer.
+func poolHealthCheckProperties(healthCheck *infrav1.EMLBHealthCheck) map[string]interface{} {
+	if healthCheck == nil {
+		return nil
+	}
+	properties := map[string]interface{}{}
+	if healthCheck.IntervalSeconds != 0 {
+		properties["interval"] = healthCheck.IntervalSeconds
+	}
+	if healthCheck.TimeoutSeconds != 0 {
+		properties["timeout"] = healthCheck.TimeoutSeconds
+	}
+	if len(properties) == 0 {
+		return nil
+	}
+	return properties
+}
+
+// loadBalancerPoolID returns the ID of the load balancer pool created for the named pool of a cluster, or "".
+func loadBalancerPoolID(lbStatus *infrav1.LoadBalancerStatus, name string) string {
+	if lbStatus == nil {
+		return ""
+	}
+	for _, pool := range lbStatus.Pools {
+		if pool.Name == name {
+			return pool.ID
+		}
+	}
+	return ""
+}
+
+// setLoadBalancerPoolID notes the ID of the load balancer pool created for the named pool of a cluster.
+func setLoadBalancerPoolID(lbStatus *infrav1.LoadBalancerStatus, name, id string) {
+	for i := range lbStatus.Pools {
+		if lbStatus.Pools[i].Name == name {
+			lbStatus.Pools[i].ID = id
+			return
+		}
+	}
+	lbStatus.Pools = append(lbStatus.Pools, infrav1.LoadBalancerPoolStatus{Name: name, ID: id})
+}
+
+// loadBalancerPoolOriginID returns the ID of the origin of the device of a machine in the named pool of its cluster,
+// or "".
+func loadBalancerPoolOriginID(packetMachine *infrav1.PacketMachine, pool string) string {
+	for _, origin := range packetMachine.Status.LoadBalancerPoolOrigins {
+		if origin.Pool == pool {
+			return origin.OriginID
+		}
+	}
+	return ""
+}
+
+// setLoadBalancerPoolOriginID notes the ID of the origin of the device of a machine in the named pool of its cluster.
+func setLoadBalancerPoolOriginID(packetMachine *infrav1.PacketMachine, pool, id string) {
+	origins := packetMachine.Status.LoadBalancerPoolOrigins
+	for i := range origins {
+		if origins[i].Pool == pool {
+			origins[i].OriginID = id
+			return
+		}
+	}
+	packetMachine.Status.LoadBalancerPoolOrigins = append(origins, infrav1.LoadBalancerPoolOrigin{Pool: pool, OriginID: id})
+}
+
+func findLoadBalancerPool(pools []infrav1.LoadBalancerPool, name string) *infrav1.LoadBalancerPool {
+	for i := range pools {
+		if pools[i].Name == name {
+			return &pools[i]
+		}
+	}
+	return nil
+}
+
+// machineLoadBalancerPools returns the names of the load balancer pools a machine belongs to: the pools it opted
+// into, followed by the pools of all control plane machines if it is one.
+func machineLoadBalancerPools(pools []infrav1.LoadBalancerPool, optedIn []string, controlPlane bool) []string {
+	names := slices.Clone(optedIn)
+	if !controlPlane {
+		return names
+	}
+	for _, pool := range pools {
+		if pool.ControlPlane && !slices.Contains(names, pool.Name) {
+			names = append(names, pool.Name)
+		}
+	}
+	return names
+}
+
+// loadBalancerPoolTargetPort returns the port the devices of a load balancer pool receive traffic on.
+func loadBalancerPoolTargetPort(pool *infrav1.LoadBalancerPool) int32 {
+	if pool.TargetPort != 0 {
+		return pool.TargetPort
+	}
+	return pool.Port
+}
+
 func checkDebugEnabled() bool {
 	_, legacyVarIsSet := os.LookupEnv("PACKNGO_DEBUG")
 	return legacyVarIsSet
@@ -509,7 +952,7 @@ func checkDebugEnabled() bool {
 func convertToTarget(devaddr corev1.NodeAddress) *Target {
 	target := &Target{
 		IP:   devaddr.Address,
-		Port: loadBalancerVIPPort,
+		Port: apiServerPort,
 	}
 
 	return target
@@ -24,6 +24,10 @@ import (
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	lbaas "sigs.k8s.io/cluster-api-provider-packet/internal/lbaas/v1"
 )
 
 func Test_getResourceName(t *testing.T) {
@@ -80,7 +84,7 @@ func Test_convertToTarget(t *testing.T) {
 			},
 			want: &Target{
 				IP:   "10.2.1.5",
-				Port: loadBalancerVIPPort,
+				Port: apiServerPort,
 			},
 		},
 		{
@@ -93,7 +97,7 @@ func Test_convertToTarget(t *testing.T) {
 			},
 			want: &Target{
 				IP:   "1.2.3.4",
-				Port: loadBalancerVIPPort,
+				Port: apiServerPort,
 			},
 		},
 		{
@@ -106,7 +110,7 @@ func Test_convertToTarget(t *testing.T) {
 			},
 			want: &Target{
 				IP:   "",
-				Port: loadBalancerVIPPort,
+				Port: apiServerPort,
 			},
 		},
 	}
@@ -149,7 +153,7 @@ func Test_getExternalIPv4Target(t *testing.T) {
 			},
 			want: &Target{
 				IP:   "1.2.3.4",
-				Port: loadBalancerVIPPort,
+				Port: apiServerPort,
 			},
 		},
 		{
@@ -196,3 +200,96 @@ func TestNewEMLB(t *testing.T) {
 	// assert metro is correct
 	g.Expect(emlb.metro).To(Equal(metro))
 }
+
+func Test_loadBalancerPoolTargetPort(t *testing.T) {
+	g := NewWithT(t)
+
+	pools := []infrav1.LoadBalancerPool{
+		{Name: "http", Port: 80, TargetPort: 30080},
+		{Name: "https", Port: 443},
+	}
+
+	g.Expect(findLoadBalancerPool(pools, "ingress")).To(BeNil())
+	g.Expect(loadBalancerPoolTargetPort(findLoadBalancerPool(pools, "http"))).To(Equal(int32(30080)))
+	g.Expect(loadBalancerPoolTargetPort(findLoadBalancerPool(pools, "https"))).To(Equal(int32(443)))
+}
+
+func Test_machineLoadBalancerPools(t *testing.T) {
+	g := NewWithT(t)
+
+	pools := []infrav1.LoadBalancerPool{
+		{Name: "http", Port: 80},
+		{Name: "passthrough", Port: 443, TargetPort: 6443, ControlPlane: true},
+	}
+
+	g.Expect(machineLoadBalancerPools(pools, nil, false)).To(BeEmpty())
+	g.Expect(machineLoadBalancerPools(pools, []string{"http"}, false)).To(Equal([]string{"http"}))
+	g.Expect(machineLoadBalancerPools(pools, nil, true)).To(Equal([]string{"passthrough"}))
+	g.Expect(machineLoadBalancerPools(pools, []string{"passthrough", "http"}, true)).To(Equal([]string{"passthrough", "http"}))
+}
+
+func Test_findLoadBalancerByName(t *testing.T) {
+	g := NewWithT(t)
+
+	lbs := []lbaas.LoadBalancer{
+		{Id: "lb-1", Name: "shared"},
+		{Id: "lb-2", Name: "ingress"},
+		{Id: "lb-3", Name: "ingress"},
+	}
+
+	lb, err := findLoadBalancerByName(lbs, "shared")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(lb.GetId()).To(Equal("lb-1"))
+
+	_, err = findLoadBalancerByName(lbs, "ingress")
+	g.Expect(err).To(MatchError(ContainSubstring("multiple load balancers")))
+
+	_, err = findLoadBalancerByName(lbs, "missing")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func Test_validateExistingLoadBalancer(t *testing.T) {
+	g := NewWithT(t)
+
+	lb := &lbaas.LoadBalancer{
+		Name:     "shared",
+		Location: &lbaas.LoadBalancerLocation{Id: ptr.To(lbMetros["da"])},
+		Ports:    []lbaas.LoadBalancerPort{{Number: ptr.To[int32](443)}, {Number: ptr.To[int32](6443)}},
+	}
+	g.Expect(validateExistingLoadBalancer(lb, lbMetros["da"], 6443)).To(Succeed())
+	g.Expect(validateExistingLoadBalancer(lb, lbMetros["da"], 443)).To(Succeed())
+	g.Expect(validateExistingLoadBalancer(lb, lbMetros["sv"], 6443)).To(MatchError(ContainSubstring("not in location")))
+
+	lb.Ports = lb.Ports[:1]
+	g.Expect(validateExistingLoadBalancer(lb, lbMetros["da"], 6443)).To(MatchError(ContainSubstring("no listener port 6443")))
+}
+
+func Test_locationID(t *testing.T) {
+	g := NewWithT(t)
+
+	e := NewEMLB("", "project", "da")
+	g.Expect(e.locationID(nil)).To(Equal(lbMetros["da"]))
+	g.Expect(e.locationID(&infrav1.EMLBConfig{LocationID: "lctnloc-custom"})).To(Equal("lctnloc-custom"))
+
+	_, err := NewEMLB("", "project", "fr").locationID(&infrav1.EMLBConfig{Port: 443})
+	g.Expect(err).To(MatchError(ContainSubstring("emlb.locationID")))
+}
+
+func Test_poolNamePrefix(t *testing.T) {
+	g := NewWithT(t)
+
+	lb := &lbaas.LoadBalancer{Name: "my-cluster-capp-vip"}
+	g.Expect(poolNamePrefix(lb, nil)).To(Equal("my-cluster-capp-vip"))
+	g.Expect(poolNamePrefix(lb, &infrav1.EMLBConfig{PoolNamePrefix: "prod"})).To(Equal("prod"))
+}
+
+func Test_poolHealthCheckProperties(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(poolHealthCheckProperties(nil)).To(BeNil())
+	g.Expect(poolHealthCheckProperties(&infrav1.EMLBHealthCheck{})).To(BeNil())
+	g.Expect(poolHealthCheckProperties(&infrav1.EMLBHealthCheck{IntervalSeconds: 10, TimeoutSeconds: 3})).To(Equal(map[string]interface{}{
+		"interval": int32(10),
+		"timeout":  int32(3),
+	}))
+}
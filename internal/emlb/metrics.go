@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emlb
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+var operationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "capp_emlb_operations_total",
+	Help: "Number of Equinix Metal Load Balancer reconciliations and deletions, by operation, result and cluster.",
+}, append([]string{"operation", "result"}, scope.ClusterMetricLabels...))
+
+func init() {
+	metrics.Registry.MustRegister(operationsTotal)
+}
+
+// recordOperation counts an operation of the load balancer manager on the resources of a cluster with its outcome.
+func recordOperation(operation string, labels prometheus.Labels, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	operationsTotal.MustCurryWith(labels).WithLabelValues(operation, result).Inc()
+}
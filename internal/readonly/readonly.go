@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package readonly keeps a manager from changing anything, by rejecting the changes to Equinix Metal and Kubernetes
+// resources before they are made. The rejected changes are logged, which shows what the manager would have done.
+package readonly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrReadOnly is returned for the changes rejected in read-only mode.
+var ErrReadOnly = errors.New("change rejected in read-only mode")
+
+// NewTransport returns a transport that only lets the requests through that do not change anything.
+func NewTransport(next http.RoundTripper) http.RoundTripper {
+	return &transport{next: next}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.next.RoundTrip(req)
+	}
+
+	log.FromContext(req.Context()).Info("Read-only mode, not sending request", "method", req.Method, "url", req.URL.Redacted())
+	return nil, fmt.Errorf("%w: %s %s", ErrReadOnly, req.Method, req.URL.Path)
+}
+
+// NewClient returns a client.NewClientFunc for a manager whose clients cannot change any object.
+func NewClient(config *rest.Config, options client.Options) (client.Client, error) {
+	c, err := client.NewWithWatch(config, options)
+	if err != nil {
+		return nil, err
+	}
+	return wrapClient(c), nil
+}
+
+// wrapClient rejects every change made with the client. The updates of the status of objects are dropped rather than
+// failed, so that the reconciles of a read-only manager do not fail only for patching the status of their objects,
+// while it does not overwrite the conditions set by the active manager either.
+func wrapClient(c client.WithWatch) client.WithWatch {
+	return interceptor.NewClient(c, interceptor.Funcs{
+		Create: func(ctx context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+			return reject(ctx, "create", obj)
+		},
+		Update: func(ctx context.Context, _ client.WithWatch, obj client.Object, _ ...client.UpdateOption) error {
+			return reject(ctx, "update", obj)
+		},
+		Patch: func(ctx context.Context, _ client.WithWatch, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+			return reject(ctx, "patch", obj)
+		},
+		Delete: func(ctx context.Context, _ client.WithWatch, obj client.Object, _ ...client.DeleteOption) error {
+			return reject(ctx, "delete", obj)
+		},
+		DeleteAllOf: func(ctx context.Context, _ client.WithWatch, obj client.Object, _ ...client.DeleteAllOfOption) error {
+			return reject(ctx, "delete all of", obj)
+		},
+		SubResourceCreate: func(ctx context.Context, _ client.Client, subResourceName string, obj client.Object, _ client.Object, _ ...client.SubResourceCreateOption) error {
+			return reject(ctx, "create "+subResourceName+" of", obj)
+		},
+		SubResourceUpdate: func(ctx context.Context, _ client.Client, subResourceName string, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+			if subResourceName == "status" {
+				return drop(ctx, "update status of", obj)
+			}
+			return reject(ctx, "update "+subResourceName+" of", obj)
+		},
+		SubResourcePatch: func(ctx context.Context, _ client.Client, subResourceName string, obj client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+			if subResourceName == "status" {
+				return drop(ctx, "patch status of", obj)
+			}
+			return reject(ctx, "patch "+subResourceName+" of", obj)
+		},
+	})
+}
+
+func drop(ctx context.Context, verb string, obj client.Object) error {
+	log.FromContext(ctx).V(4).Info("Read-only mode, not writing status", "verb", verb,
+		"kind", fmt.Sprintf("%T", obj), "namespace", obj.GetNamespace(), "name", obj.GetName())
+	return nil
+}
+
+func reject(ctx context.Context, verb string, obj client.Object) error {
+	log.FromContext(ctx).Info("Read-only mode, not writing object", "verb", verb,
+		"kind", fmt.Sprintf("%T", obj), "namespace", obj.GetNamespace(), "name", obj.GetName())
+	return fmt.Errorf("%w: %s %s/%s", ErrReadOnly, verb, obj.GetNamespace(), obj.GetName())
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readonly
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestTransport(t *testing.T) {
+	g := NewWithT(t)
+
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+	}))
+	defer server.Close()
+
+	c := &http.Client{Transport: NewTransport(http.DefaultTransport)}
+
+	resp, err := c.Get(server.URL + "/devices/device")
+	g.Expect(err).ToNot(HaveOccurred())
+	resp.Body.Close()
+
+	_, err = c.Post(server.URL+"/projects/project/devices", "application/json", http.NoBody) //nolint:bodyclose // the request is rejected
+	g.Expect(err).To(MatchError(ErrReadOnly))
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/devices/device", http.NoBody)
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = c.Do(req) //nolint:bodyclose // the request is rejected
+	g.Expect(err).To(MatchError(ErrReadOnly))
+
+	g.Expect(methods).To(Equal([]string{http.MethodGet}))
+}
+
+func TestClient(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	g.Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	machine := &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machine"}}
+	c := wrapClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine).WithStatusSubresource(machine).Build())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+
+	patch := client.MergeFrom(machine.DeepCopy())
+	machine.Spec.ProviderID = ptr.To("equinixmetal://device")
+	machine.Status.Ready = true
+	g.Expect(c.Patch(ctx, machine, patch)).To(MatchError(ErrReadOnly))
+	// The status is not written either, but patching it does not fail the reconciles.
+	g.Expect(c.Status().Patch(ctx, machine, patch)).To(Succeed())
+	g.Expect(c.Status().Update(ctx, machine)).To(Succeed())
+	g.Expect(c.Delete(ctx, machine)).To(MatchError(ErrReadOnly))
+	g.Expect(c.Create(ctx, &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"}})).To(MatchError(ErrReadOnly))
+
+	stored := &infrav1.PacketMachine{}
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(machine), stored)).To(Succeed())
+	g.Expect(stored.Spec.ProviderID).To(BeNil())
+	g.Expect(stored.Status.Ready).To(BeFalse())
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package runtimeextensions implements Cluster API Runtime SDK topology mutation hooks for Packet templates.
+package runtimeextensions
+
+import (
+	"context"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/exp/runtime/topologymutation"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+const (
+	// MetroVariable overrides the metro of PacketMachineTemplates.
+	MetroVariable = "packetMetro"
+	// MachineTypeVariable overrides the plan of PacketMachineTemplates.
+	MachineTypeVariable = "packetMachineType"
+	// TagsVariable adds tags to PacketMachineTemplates.
+	TagsVariable = "packetTags"
+)
+
+// TopologyMutationHandler mutates Packet templates of ClusterClass based clusters from topology variables.
+// Variables can be set for the whole Cluster or overridden per MachineDeployment, which allows, for example,
+// a different plan or metro per worker pool without a dedicated ClusterClass patch for each.
+type TopologyMutationHandler struct {
+	decoder runtime.Decoder
+}
+
+// NewTopologyMutationHandler returns a TopologyMutationHandler.
+func NewTopologyMutationHandler() *TopologyMutationHandler {
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+
+	return &TopologyMutationHandler{
+		decoder: serializer.NewCodecFactory(scheme).UniversalDecoder(infrav1.GroupVersion),
+	}
+}
+
+// GeneratePatches implements the GeneratePatches hook.
+func (h *TopologyMutationHandler) GeneratePatches(ctx context.Context, req *runtimehooksv1.GeneratePatchesRequest, resp *runtimehooksv1.GeneratePatchesResponse) {
+	log := ctrl.LoggerFrom(ctx)
+	log.Info("GeneratePatches is called")
+
+	topologymutation.WalkTemplates(ctx, h.decoder, req, resp, func(ctx context.Context, obj runtime.Object, variables map[string]apiextensionsv1.JSON, _ runtimehooksv1.HolderReference) error {
+		if template, ok := obj.(*infrav1.PacketMachineTemplate); ok {
+			return patchPacketMachineTemplate(ctx, template, variables)
+		}
+		return nil
+	})
+}
+
+// ValidateTopology implements the ValidateTopology hook. Variables are validated by Cluster API against the
+// schemas returned by DiscoverVariables, so there is nothing left to check.
+func (h *TopologyMutationHandler) ValidateTopology(ctx context.Context, _ *runtimehooksv1.ValidateTopologyRequest, resp *runtimehooksv1.ValidateTopologyResponse) {
+	log := ctrl.LoggerFrom(ctx)
+	log.Info("ValidateTopology called")
+
+	resp.Status = runtimehooksv1.ResponseStatusSuccess
+}
+
+// DiscoverVariables implements the DiscoverVariables hook.
+func (h *TopologyMutationHandler) DiscoverVariables(ctx context.Context, _ *runtimehooksv1.DiscoverVariablesRequest, resp *runtimehooksv1.DiscoverVariablesResponse) {
+	log := ctrl.LoggerFrom(ctx)
+	log.Info("DiscoverVariables called")
+
+	resp.Status = runtimehooksv1.ResponseStatusSuccess
+	resp.Variables = []clusterv1.ClusterClassVariable{
+		{
+			Name:     MetroVariable,
+			Required: false,
+			Schema: clusterv1.VariableSchema{
+				OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+					Type:        "string",
+					Description: "Metro to provision the machines in, overriding the one of the PacketMachineTemplate.",
+				},
+			},
+		},
+		{
+			Name:     MachineTypeVariable,
+			Required: false,
+			Schema: clusterv1.VariableSchema{
+				OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+					Type:        "string",
+					Description: "Equinix Metal plan of the machines, overriding the one of the PacketMachineTemplate.",
+				},
+			},
+		},
+		{
+			Name:     TagsVariable,
+			Required: false,
+			Schema: clusterv1.VariableSchema{
+				OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+					Type:        "array",
+					Description: "Tags to add to the devices, on top of the ones of the PacketMachineTemplate.",
+					Items: &clusterv1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+		},
+	}
+}
+
+// patchPacketMachineTemplate applies the Packet topology variables to a PacketMachineTemplate.
+func patchPacketMachineTemplate(ctx context.Context, template *infrav1.PacketMachineTemplate, variables map[string]apiextensionsv1.JSON) error {
+	log := ctrl.LoggerFrom(ctx)
+	spec := &template.Spec.Template.Spec
+
+	metro, err := topologymutation.GetStringVariable(variables, MetroVariable)
+	switch {
+	case topologymutation.IsNotFoundError(err):
+	case err != nil:
+		return fmt.Errorf("failed to read %s variable: %w", MetroVariable, err)
+	default:
+		log.Info("Setting metro", "metro", metro)
+		// Facility and Metro are mutually exclusive, the metro from the topology wins.
+		spec.Facility = ""
+		spec.Metro = metro
+	}
+
+	machineType, err := topologymutation.GetStringVariable(variables, MachineTypeVariable)
+	switch {
+	case topologymutation.IsNotFoundError(err):
+	case err != nil:
+		return fmt.Errorf("failed to read %s variable: %w", MachineTypeVariable, err)
+	default:
+		log.Info("Setting machine type", "machineType", machineType)
+		spec.MachineType = machineType
+	}
+
+	var tags []string
+	err = topologymutation.GetObjectVariableInto(variables, TagsVariable, &tags)
+	switch {
+	case topologymutation.IsNotFoundError(err):
+	case err != nil:
+		return fmt.Errorf("failed to read %s variable: %w", TagsVariable, err)
+	default:
+		log.Info("Adding tags", "tags", tags)
+		for _, tag := range tags {
+			if !containsTag(spec.Tags, tag) {
+				spec.Tags = append(spec.Tags, tag)
+			}
+		}
+	}
+
+	return nil
+}
+
+func containsTag(tags infrav1.Tags, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeextensions
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func Test_patchPacketMachineTemplate(t *testing.T) {
+	g := NewWithT(t)
+
+	template := &infrav1.PacketMachineTemplate{}
+	template.Spec.Template.Spec = infrav1.PacketMachineSpec{
+		Facility:    "da11",
+		MachineType: "c3.small.x86",
+		Tags:        infrav1.Tags{"env:dev"},
+	}
+
+	variables := map[string]apiextensionsv1.JSON{
+		MetroVariable:       {Raw: []byte(`"sv"`)},
+		MachineTypeVariable: {Raw: []byte(`"m3.small.x86"`)},
+		TagsVariable:        {Raw: []byte(`["env:dev","pool:gpu"]`)},
+	}
+
+	g.Expect(patchPacketMachineTemplate(context.Background(), template, variables)).To(Succeed())
+	g.Expect(template.Spec.Template.Spec.Facility).To(BeEmpty())
+	g.Expect(template.Spec.Template.Spec.Metro).To(Equal("sv"))
+	g.Expect(template.Spec.Template.Spec.MachineType).To(Equal("m3.small.x86"))
+	g.Expect(template.Spec.Template.Spec.Tags).To(Equal(infrav1.Tags{"env:dev", "pool:gpu"}))
+}
+
+func Test_patchPacketMachineTemplateNoVariables(t *testing.T) {
+	g := NewWithT(t)
+
+	template := &infrav1.PacketMachineTemplate{}
+	template.Spec.Template.Spec = infrav1.PacketMachineSpec{
+		Metro:       "da",
+		MachineType: "c3.small.x86",
+	}
+	original := template.DeepCopy()
+
+	g.Expect(patchPacketMachineTemplate(context.Background(), template, nil)).To(Succeed())
+	g.Expect(template).To(Equal(original))
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharding splits the clusters of a management cluster between several manager replicas.
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// ByCluster assigns the objects of a cluster to a shard by the name and namespace of the cluster.
+	ByCluster = "cluster"
+	// ByNamespace assigns all the objects of a namespace to the same shard.
+	ByNamespace = "namespace"
+)
+
+var (
+	// ErrInvalidShard is returned when the shard configuration is inconsistent.
+	ErrInvalidShard = errors.New("invalid shard")
+
+	shardInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capp_shard_info",
+		Help: "Shard of the clusters reconciled by this manager replica, always 1.",
+	}, []string{"shard_index", "shard_count", "shard_by"})
+
+	shardEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capp_shard_events_total",
+		Help: "Number of watch events seen by this manager replica, by whether their cluster belongs to its shard.",
+	}, []string{"owned"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(shardInfo, shardEventsTotal)
+}
+
+// Shard selects the clusters a manager replica reconciles, out of Count shards. Objects are assigned by a stable hash
+// so every replica computes the same assignment without coordination.
+type Shard struct {
+	Index int
+	Count int
+	By    string
+}
+
+// New validates the shard configuration and reports it in the shard metrics.
+func New(index, count int, by string) (*Shard, error) {
+	if count < 1 || index < 0 || index >= count {
+		return nil, fmt.Errorf("%w: index %d must be within [0, %d)", ErrInvalidShard, index, count)
+	}
+	if by != ByCluster && by != ByNamespace {
+		return nil, fmt.Errorf("%w: shards are assigned by %s or %s, not %q", ErrInvalidShard, ByCluster, ByNamespace, by)
+	}
+
+	shardInfo.WithLabelValues(strconv.Itoa(index), strconv.Itoa(count), by).Set(1)
+	return &Shard{Index: index, Count: count, By: by}, nil
+}
+
+// Owns reports whether the object belongs to the shard. A nil Shard owns everything.
+func (s *Shard) Owns(obj client.Object) bool {
+	if s == nil || s.Count <= 1 {
+		return true
+	}
+
+	key := obj.GetNamespace()
+	if s.By == ByCluster {
+		key += "/" + clusterName(obj)
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+	return int(hash.Sum32()%uint32(s.Count)) == s.Index
+}
+
+// Predicate filters out the events of objects that do not belong to the shard.
+func (s *Shard) Predicate(log logr.Logger) predicate.Funcs {
+	owns := func(obj client.Object) bool {
+		owned := s.Owns(obj)
+		shardEventsTotal.WithLabelValues(strconv.FormatBool(owned)).Inc()
+		if !owned {
+			log.V(6).Info("Object belongs to another shard, ignoring", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		}
+		return owned
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return owns(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return owns(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return owns(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return owns(e.Object) },
+	}
+}
+
+// clusterName returns the name of the Cluster an object belongs to: its own name for Clusters, otherwise its cluster
+// name label or its owning Cluster, falling back to its own name.
+func clusterName(obj client.Object) string {
+	if _, ok := obj.(*clusterv1.Cluster); ok {
+		return obj.GetName()
+	}
+	if name, ok := obj.GetLabels()[clusterv1.ClusterNameLabel]; ok {
+		return name
+	}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Kind == "Cluster" {
+			return ref.Name
+		}
+	}
+	return obj.GetName()
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestNew(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := New(2, 2, ByCluster)
+	g.Expect(err).To(MatchError(ErrInvalidShard))
+	_, err = New(0, 2, "label")
+	g.Expect(err).To(MatchError(ErrInvalidShard))
+
+	shard, err := New(1, 2, ByNamespace)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(shard).To(Equal(&Shard{Index: 1, Count: 2, By: ByNamespace}))
+}
+
+func TestOwns(t *testing.T) {
+	g := NewWithT(t)
+
+	var nilShard *Shard
+	g.Expect(nilShard.Owns(&clusterv1.Cluster{})).To(BeTrue())
+
+	shards := []*Shard{
+		{Index: 0, Count: 3, By: ByCluster},
+		{Index: 1, Count: 3, By: ByCluster},
+		{Index: 2, Count: 3, By: ByCluster},
+	}
+
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("cluster-%d", i)
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		packetCluster := &infrav1.PacketCluster{ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            name + "-infra",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Cluster", Name: name}},
+		}}
+		packetMachine := &infrav1.PacketMachine{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name + "-md-0-abcde",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: name},
+		}}
+
+		// Every object of a cluster belongs to exactly one shard, the same one.
+		owners := 0
+		for _, shard := range shards {
+			if shard.Owns(cluster) {
+				owners++
+				g.Expect(shard.Owns(packetCluster)).To(BeTrue(), "PacketCluster of %s", name)
+				g.Expect(shard.Owns(packetMachine)).To(BeTrue(), "PacketMachine of %s", name)
+			}
+		}
+		g.Expect(owners).To(Equal(1), "shards owning %s", name)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhookcert serves the webhook certificate straight from the Secret issued by cert-manager.
+package webhookcert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrCertificateNotLoaded is returned when a TLS handshake happens before a certificate was loaded.
+	ErrCertificateNotLoaded = errors.New("webhook certificate has not been loaded")
+	// ErrSecretMissingKey is returned when the certificate Secret lacks the certificate or the key.
+	ErrSecretMissingKey = errors.New("webhook certificate secret is missing a key")
+)
+
+// Watcher keeps the webhook serving certificate in sync with a kubernetes.io/tls Secret, such as the one
+// cert-manager maintains for a Certificate. Unlike a mounted secret volume, a renewed certificate is picked up
+// as soon as the next poll happens rather than whenever the kubelet refreshes the volume.
+type Watcher struct {
+	// Client reads the Secret. It should not be backed by the manager cache, which does not hold Secrets.
+	Client client.Reader
+	// Secret is the Secret holding the certificate.
+	Secret types.NamespacedName
+	// Interval is how often the Secret is checked for a renewed certificate.
+	Interval time.Duration
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// Load reads the Secret and replaces the served certificate if it changed.
+func (w *Watcher) Load(ctx context.Context) error {
+	secret := &corev1.Secret{}
+	if err := w.Client.Get(ctx, w.Secret, secret); err != nil {
+		return fmt.Errorf("failed to get webhook certificate secret %s: %w", w.Secret, err)
+	}
+
+	certPEM, ok := secret.Data[corev1.TLSCertKey]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSecretMissingKey, corev1.TLSCertKey)
+	}
+	keyPEM, ok := secret.Data[corev1.TLSPrivateKeyKey]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSecretMissingKey, corev1.TLSPrivateKeyKey)
+	}
+
+	w.mu.RLock()
+	unchanged := bytes.Equal(certPEM, w.certPEM) && bytes.Equal(keyPEM, w.keyPEM)
+	w.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("failed to parse webhook certificate from secret %s: %w", w.Secret, err)
+	}
+
+	w.mu.Lock()
+	w.cert = &cert
+	w.certPEM = certPEM
+	w.keyPEM = keyPEM
+	w.mu.Unlock()
+
+	ctrl.LoggerFrom(ctx).Info("Loaded webhook certificate", "secret", w.Secret.String())
+	return nil
+}
+
+// GetCertificate returns the current certificate. It is meant to be used as tls.Config.GetCertificate.
+func (w *Watcher) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.cert == nil {
+		return nil, ErrCertificateNotLoaded
+	}
+	return w.cert, nil
+}
+
+// Start polls the Secret until the context is cancelled. It implements manager.Runnable.
+func (w *Watcher) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := w.Load(ctx); err != nil {
+				// Keep serving the previous certificate, it is likely still valid.
+				log.Error(err, "failed to reload webhook certificate")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every replica serves webhooks.
+func (w *Watcher) NeedLeaderElection() bool {
+	return false
+}
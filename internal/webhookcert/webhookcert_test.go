@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookcert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newKeyPair(t *testing.T, commonName string) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestWatcherLoad(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	certPEM, keyPEM := newKeyPair(t, "first")
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-service-cert", Namespace: "capp-system"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	w := &Watcher{
+		Client:   c,
+		Secret:   types.NamespacedName{Namespace: "capp-system", Name: "webhook-service-cert"},
+		Interval: time.Minute,
+	}
+
+	_, err := w.GetCertificate(nil)
+	g.Expect(err).To(MatchError(ErrCertificateNotLoaded))
+
+	g.Expect(w.Load(ctx)).To(Succeed())
+	first, err := w.GetCertificate(nil)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// Loading an unchanged secret keeps the same certificate.
+	g.Expect(w.Load(ctx)).To(Succeed())
+	same, err := w.GetCertificate(nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(same).To(BeIdenticalTo(first))
+
+	// A rotated certificate replaces the served one.
+	secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey] = newKeyPair(t, "second")
+	g.Expect(c.Update(ctx, secret)).To(Succeed())
+	g.Expect(w.Load(ctx)).To(Succeed())
+	rotated, err := w.GetCertificate(nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(rotated).ToNot(BeIdenticalTo(first))
+
+	// A broken secret keeps the last good certificate.
+	delete(secret.Data, corev1.TLSPrivateKeyKey)
+	g.Expect(c.Update(ctx, secret)).To(Succeed())
+	g.Expect(w.Load(ctx)).To(MatchError(ErrSecretMissingKey))
+	current, err := w.GetCertificate(nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(current).To(BeIdenticalTo(rotated))
+}
@@ -19,17 +19,21 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	goruntime "runtime"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	cgrecord "k8s.io/client-go/tools/record"
 	cliflag "k8s.io/component-base/cli/flag"
@@ -38,6 +42,8 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/remote"
+	expclusterv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/flags"
 	"sigs.k8s.io/cluster-api/util/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -50,8 +56,16 @@ import (
 	logsv1 "k8s.io/component-base/logs/api/v1"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	infrav1beta2 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta2"
 	"sigs.k8s.io/cluster-api-provider-packet/controllers"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/audit"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/buildinfo"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/emlb"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/readonly"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/sharding"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/webhookcert"
 	packet "sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -64,8 +78,11 @@ var (
 func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = infrav1.AddToScheme(scheme)
+	_ = infrav1beta2.AddToScheme(scheme)
 	_ = clusterv1.AddToScheme(scheme)
 	_ = bootstrapv1.AddToScheme(scheme)
+	_ = expclusterv1.AddToScheme(scheme)
+	_ = ipamv1.AddToScheme(scheme)
 }
 
 var (
@@ -76,6 +93,8 @@ var (
 	healthAddr                  string
 	watchFilterValue            string
 	webhookCertDir              string
+	webhookCertSecret           string
+	webhookCertReloadInterval   time.Duration
 	packetClusterConcurrency    int
 	packetMachineConcurrency    int
 	webhookPort                 int
@@ -86,11 +105,54 @@ var (
 	enableContentionProfiling   bool
 	restConfigQPS               float32
 	restConfigBurst             int
+	metalAPIQPS                 float64
+	metalAPIBurst               int
+	metalAPIClusterQPS          float64
+	metalAPIClusterBurst        int
+	deviceDeprovisionTimeout    time.Duration
+	bootDiagnostics             bool
+	rootPasswordSecrets         bool
+	bootstrapTimeout            time.Duration
+	devicePollMaxInterval       time.Duration
+	maintenanceCheckInterval    time.Duration
+	maintenanceCordonLeadTime   time.Duration
+	ipReservationGCInterval     time.Duration
+	ipReservationGCDryRun       bool
+	ipReservationGCProjectIDs   []string
+	capacityMetricsInterval     time.Duration
+	deviceBatchWindow           time.Duration
+	deviceBatchSize             int
+	platformLabels              bool
+	deleteDuplicateDevices      bool
+	bgpSessionTimeout           time.Duration
+	hardwareReservationTimeout  time.Duration
+	clusterDeletionTimeout      time.Duration
+	shardCount                  int
+	shardIndex                  int
+	shardBy                     string
+	readOnly                    bool
+	buildInfoNamespace          string
+	installationID              string
+	apiCallWarningThreshold     int
+	providerIDFormat            string
+	bondRemediation             bool
+	hostnameReconciliation      bool
+	machinePool                 bool
+	auditConfigMap              bool
+	auditWebhookURL             string
+	cloudEventsURL              string
+	cloudEventsSource           string
+	cloudEventsNATSSubject      string
+	cloudEventsNATSCredentials  string
+	shard                       *sharding.Shard
 	tlsOptions                  = flags.TLSOptions{}
 	diagnosticsOptions          = flags.DiagnosticsOptions{}
 	logOptions                  = logs.NewOptions()
 )
 
+// Add RBAC for the build information ConfigMap.
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+
 // Add RBAC for the authorized diagnostics endpoint.
 // +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
 // +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
@@ -133,6 +195,18 @@ func main() {
 
 	diagnosticsOpts := flags.GetDiagnosticsOptions(diagnosticsOptions)
 
+	var certWatcher *webhookcert.Watcher
+	if webhookCertSecret != "" {
+		certWatcher, err = setupWebhookCertWatcher(restConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to load the webhook certificate from secret", "secret", webhookCertSecret)
+			os.Exit(1)
+		}
+		tlsOptionOverrides = append(tlsOptionOverrides, func(c *tls.Config) {
+			c.GetCertificate = certWatcher.GetCertificate
+		})
+	}
+
 	var watchNamespaces map[string]cache.Config
 	if watchNamespace != "" {
 		setupLog.Info("Watching cluster-api objects only in namespace for reconciliation", "namespace", watchNamespace)
@@ -145,10 +219,28 @@ func main() {
 		goruntime.SetBlockProfileRate(1)
 	}
 
+	leaderElectionID := "controller-leader-election-capp"
+	if shardCount > 1 {
+		shard, err = sharding.New(shardIndex, shardCount, shardBy)
+		if err != nil {
+			setupLog.Error(err, "unable to configure sharding")
+			os.Exit(1)
+		}
+		setupLog.Info("Reconciling a shard of the clusters", "shard-index", shardIndex, "shard-count", shardCount, "shard-by", shardBy)
+		// Every shard elects its own leader.
+		leaderElectionID = fmt.Sprintf("%s-shard-%d", leaderElectionID, shardIndex)
+	}
+
+	if readOnly {
+		setupLog.Info("Running in read-only mode, no changes are made")
+		// A read-only manager runs next to the active one, e.g. as a standby, and must not take its lease.
+		leaderElectionID += "-read-only"
+	}
+
 	ctrlOptions := ctrl.Options{
 		Scheme:                     scheme,
 		LeaderElection:             enableLeaderElection,
-		LeaderElectionID:           "controller-leader-election-capp",
+		LeaderElectionID:           leaderElectionID,
 		LeaseDuration:              &leaderElectionLeaseDuration,
 		RenewDeadline:              &leaderElectionRenewDeadline,
 		RetryPeriod:                &leaderElectionRetryPeriod,
@@ -178,18 +270,30 @@ func main() {
 		EventBroadcaster: broadcaster,
 	}
 
+	if readOnly {
+		ctrlOptions.NewClient = readonly.NewClient
+	}
+
 	mgr, err := ctrl.NewManager(restConfig, ctrlOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	if certWatcher != nil {
+		if err := mgr.Add(certWatcher); err != nil {
+			setupLog.Error(err, "unable to add webhook certificate watcher")
+			os.Exit(1)
+		}
+	}
+
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 
 	setupChecks(mgr)
 	setupReconcilers(ctx, mgr)
 	setupWebhooks(mgr)
+	setupBuildInfo(mgr)
 
 	if profilerAddress != "" {
 		setupLog.Info(fmt.Sprintf("Profiler listening for requests at %s", profilerAddress))
@@ -211,6 +315,29 @@ func main() {
 	}
 }
 
+func setupWebhookCertWatcher(restConfig *rest.Config) (*webhookcert.Watcher, error) {
+	namespace, name, ok := strings.Cut(webhookCertSecret, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("--webhook-cert-secret must be in the form namespace/name, got %q", webhookCertSecret)
+	}
+
+	// The manager cache does not hold Secrets and has not been started yet, so use a direct client.
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+
+	certWatcher := &webhookcert.Watcher{
+		Client:   c,
+		Secret:   types.NamespacedName{Namespace: namespace, Name: name},
+		Interval: webhookCertReloadInterval,
+	}
+	if err := certWatcher.Load(context.Background()); err != nil {
+		return nil, err
+	}
+	return certWatcher, nil
+}
+
 func setupChecks(mgr ctrl.Manager) {
 	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
 		setupLog.Error(err, "unable to create ready check")
@@ -230,26 +357,208 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		setupLog.Error(err, "unable to get Packet client")
 		os.Exit(1)
 	}
+	client.SetAPIRateLimit(metalAPIQPS, metalAPIBurst)
+	if readOnly {
+		client.SetReadOnly()
+		emlb.SetReadOnly()
+	}
+	if metalAPIClusterQPS > 0 {
+		client.SetClusterAPIBudget(metalAPIClusterQPS, metalAPIClusterBurst)
+	}
+	if deviceBatchWindow > 0 {
+		client.SetDeviceBatching(deviceBatchWindow, deviceBatchSize)
+	}
+
+	providerIDPrefix, ok := scope.ProviderIDFormats[providerIDFormat]
+	if !ok {
+		setupLog.Error(nil, "unknown providerID format, expected \"equinixmetal\" or \"packet\"", "provider-id-format", providerIDFormat)
+		os.Exit(1)
+	}
+
+	var auditSinks []audit.Sink
+	if auditConfigMap {
+		auditSinks = append(auditSinks, &audit.ConfigMapSink{Client: mgr.GetClient()})
+	}
+	// Sinks outside of the management cluster are written to in the background, so that they cannot hold up the
+	// reconciles when they are slow or down.
+	var externalSinks []audit.Sink
+	if auditWebhookURL != "" {
+		externalSinks = append(externalSinks, &audit.WebhookSink{URL: auditWebhookURL})
+	}
+	if cloudEventsURL != "" {
+		sink, err := audit.NewCloudEventsSink(cloudEventsURL, cloudEventsSource, cloudEventsNATSSubject, cloudEventsNATSCredentials)
+		if err != nil {
+			setupLog.Error(err, "invalid CloudEvents sink", "cloudevents-url", cloudEventsURL)
+			os.Exit(1)
+		}
+		externalSinks = append(externalSinks, sink)
+	}
+	for _, sink := range externalSinks {
+		asyncSink := audit.NewAsyncSink(sink, audit.DefaultBufferSize)
+		if err := mgr.Add(asyncSink); err != nil {
+			setupLog.Error(err, "unable to add audit sink")
+			os.Exit(1)
+		}
+		auditSinks = append(auditSinks, asyncSink)
+	}
+	auditor := audit.NewEventAggregator(auditSinks...)
 
 	if err := (&controllers.PacketClusterReconciler{
-		Client:           mgr.GetClient(),
-		WatchFilterValue: watchFilterValue,
-		PacketClient:     client,
+		Client:                  mgr.GetClient(),
+		WatchFilterValue:        watchFilterValue,
+		PacketClient:            client,
+		Shard:                   shard,
+		DeletionTimeout:         clusterDeletionTimeout,
+		APICallWarningThreshold: apiCallWarningThreshold,
+		Audit:                   auditor,
 	}).SetupWithManager(ctx, mgr, controller.Options{MaxConcurrentReconciles: packetClusterConcurrency}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PacketCluster")
 		os.Exit(1)
 	}
 
 	if err := (&controllers.PacketMachineReconciler{
-		Client:           mgr.GetClient(),
-		WatchFilterValue: watchFilterValue,
-		PacketClient:     client,
+		Client:                     mgr.GetClient(),
+		WatchFilterValue:           watchFilterValue,
+		PacketClient:               client,
+		Shard:                      shard,
+		ReadOnly:                   readOnly,
+		DeprovisionTimeout:         deviceDeprovisionTimeout,
+		BootDiagnostics:            bootDiagnostics,
+		BootstrapTimeout:           bootstrapTimeout,
+		RootPasswordSecrets:        rootPasswordSecrets,
+		PlatformLabels:             platformLabels,
+		DeleteDuplicateDevices:     deleteDuplicateDevices,
+		BGPSessionTimeout:          bgpSessionTimeout,
+		HardwareReservationTimeout: hardwareReservationTimeout,
+		APICallWarningThreshold:    apiCallWarningThreshold,
+		DevicePollMaxInterval:      devicePollMaxInterval,
+		MaintenanceCheckInterval:   maintenanceCheckInterval,
+		MaintenanceCordonLeadTime:  maintenanceCordonLeadTime,
+		ProviderIDPrefix:           providerIDPrefix,
+		BondRemediation:            bondRemediation,
+		HostnameReconciliation:     hostnameReconciliation,
+		Audit:                      auditor,
 	}).SetupWithManager(ctx, mgr, controller.Options{
 		MaxConcurrentReconciles: packetMachineConcurrency,
 	}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PacketMachine")
 		os.Exit(1)
 	}
+
+	// Binding devices to claims only makes changes, which a read-only manager leaves to the active one.
+	if !readOnly {
+		if err := (&controllers.PacketDeviceClaimReconciler{
+			Client:           mgr.GetClient(),
+			WatchFilterValue: watchFilterValue,
+			PacketClient:     client,
+			Shard:            shard,
+			Audit:            auditor,
+		}).SetupWithManager(ctx, mgr, controller.Options{}); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "PacketDeviceClaim")
+			os.Exit(1)
+		}
+	}
+
+	if machinePool {
+		if err := (&controllers.PacketMachinePoolReconciler{
+			Client:                  mgr.GetClient(),
+			WatchFilterValue:        watchFilterValue,
+			PacketClient:            client,
+			Shard:                   shard,
+			ProviderIDPrefix:        providerIDPrefix,
+			APICallWarningThreshold: apiCallWarningThreshold,
+			Audit:                   auditor,
+		}).SetupWithManager(ctx, mgr, controller.Options{
+			MaxConcurrentReconciles: packetMachineConcurrency,
+		}); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "PacketMachinePool")
+			os.Exit(1)
+		}
+	}
+
+	// The garbage collector sweeps whole projects, so only the first shard of the active manager runs it.
+	if ipReservationGCInterval > 0 && !readOnly && (shard == nil || shard.Index == 0) {
+		if watchNamespace != "" {
+			// Clusters of the other namespaces would be invisible and their reservations released.
+			setupLog.Error(nil, "--ip-reservation-gc-interval cannot be used together with --namespace")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&controllers.IPReservationGarbageCollector{
+			Client:       mgr.GetClient(),
+			PacketClient: client,
+			Interval:     ipReservationGCInterval,
+			DryRun:       ipReservationGCDryRun,
+			ProjectIDs:   ipReservationGCProjectIDs,
+		}); err != nil {
+			setupLog.Error(err, "unable to create IP reservation garbage collector")
+			os.Exit(1)
+		}
+	}
+
+	// Capacity is the same for every shard and manager, so only the first shard of the active manager exports it.
+	if capacityMetricsInterval > 0 && !readOnly && (shard == nil || shard.Index == 0) {
+		if err := mgr.Add(&controllers.CapacityMonitor{
+			Client:       mgr.GetClient(),
+			PacketClient: client,
+			Interval:     capacityMetricsInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to create capacity monitor")
+			os.Exit(1)
+		}
+	}
+}
+
+func setupBuildInfo(mgr ctrl.Manager) {
+	features := enabledFeatures()
+	buildinfo.SetInstallation(installationID)
+	buildinfo.Register(features)
+
+	// Every shard runs the same build, so only the first one publishes it. A read-only manager may run another one.
+	if buildInfoNamespace == "" || readOnly || (shard != nil && shard.Index != 0) {
+		return
+	}
+	if err := mgr.Add(&buildinfo.Reporter{
+		Client:    mgr.GetClient(),
+		Namespace: buildInfoNamespace,
+		Features:  features,
+	}); err != nil {
+		setupLog.Error(err, "unable to create build information reporter")
+		os.Exit(1)
+	}
+}
+
+// enabledFeatures returns the optional features enabled by the flags of the manager.
+func enabledFeatures() []string {
+	var features []string
+	for _, feature := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"read-only", readOnly},
+		{"sharding", shardCount > 1},
+		{"cluster-api-budget", metalAPIClusterQPS > 0},
+		{"device-batching", deviceBatchWindow > 0},
+		{"boot-diagnostics", bootDiagnostics},
+		{"root-password-secrets", rootPasswordSecrets},
+		{"platform-labels", platformLabels},
+		{"delete-duplicate-devices", deleteDuplicateDevices},
+		{"ip-reservation-gc", ipReservationGCInterval > 0 && !readOnly},
+		{"capacity-metrics", capacityMetricsInterval > 0 && !readOnly},
+		{"legacy-provider-id", providerIDFormat == "packet"},
+		{"bond-remediation", bondRemediation},
+		{"hostname-reconciliation", hostnameReconciliation},
+		{"audit-configmap", auditConfigMap},
+		{"audit-webhook", auditWebhookURL != ""},
+		{"cloudevents", cloudEventsURL != ""},
+		{"machine-pool", machinePool},
+		{"maintenance-checks", maintenanceCheckInterval > 0},
+		{"maintenance-cordon", maintenanceCheckInterval > 0 && maintenanceCordonLeadTime > 0},
+	} {
+		if feature.enabled {
+			features = append(features, feature.name)
+		}
+	}
+	return features
 }
 
 func setupWebhooks(mgr ctrl.Manager) {
@@ -257,6 +566,10 @@ func setupWebhooks(mgr ctrl.Manager) {
 		setupLog.Error(err, "unable to create webhook", "webhook", "PacketCluster")
 		os.Exit(1)
 	}
+	if err := (&infrav1.PacketClusterTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "PacketClusterTemplate")
+		os.Exit(1)
+	}
 	if err := (&infrav1.PacketMachine{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "PacketMachine")
 		os.Exit(1)
@@ -361,6 +674,252 @@ func initFlags(fs *pflag.FlagSet) {
 		"Webhook Server Certificate Directory, is the directory that contains the server key and certificate",
 	)
 
+	fs.StringVar(&webhookCertSecret,
+		"webhook-cert-secret",
+		"",
+		"Namespace/name of a kubernetes.io/tls Secret (e.g. issued by cert-manager) to serve the webhook certificate from. When set, the certificate is reloaded on rotation and --webhook-cert-dir is ignored.",
+	)
+
+	fs.DurationVar(&webhookCertReloadInterval,
+		"webhook-cert-reload-interval",
+		time.Minute,
+		"How often the Secret set with --webhook-cert-secret is checked for a renewed certificate",
+	)
+
+	fs.Float64Var(&metalAPIQPS,
+		"metal-api-qps",
+		10,
+		"Maximum Equinix Metal API calls per second made by all the controllers together. Not limited when 0, in which case only the rate limiting responses of the API are honored.",
+	)
+
+	fs.IntVar(&metalAPIBurst,
+		"metal-api-burst",
+		20,
+		"Maximum burst of Equinix Metal API calls made by all the controllers together, see --metal-api-qps",
+	)
+
+	fs.Float64Var(&metalAPIClusterQPS,
+		"metal-api-cluster-qps",
+		0,
+		"Maximum Equinix Metal API calls per second made on behalf of a single cluster. Disabled when 0.",
+	)
+
+	fs.IntVar(&metalAPIClusterBurst,
+		"metal-api-cluster-burst",
+		50,
+		"Maximum burst of Equinix Metal API calls made on behalf of a single cluster, see --metal-api-cluster-qps",
+	)
+
+	fs.DurationVar(&deviceDeprovisionTimeout,
+		"device-deprovision-timeout",
+		0,
+		"How long deleted PacketMachines are kept while their device deprovisions, so that its capacity is released before replacements are created. Disabled when 0.",
+	)
+
+	fs.BoolVar(&bootDiagnostics,
+		"boot-diagnostics",
+		false,
+		"Collect the device events, network port states and bootstrap data hash of machines that fail to provision or bootstrap into a <packetmachine>-boot-diagnostics ConfigMap.",
+	)
+
+	fs.DurationVar(&bootstrapTimeout,
+		"bootstrap-timeout",
+		20*time.Minute,
+		"How long after its device creation a machine without a Node is considered failed to bootstrap, see --boot-diagnostics. Disabled when 0.",
+	)
+
+	fs.DurationVar(&devicePollMaxInterval,
+		"device-poll-max-interval",
+		time.Minute,
+		"The longest provisioning devices go without being checked. Devices are checked every 10s after their creation, backing off exponentially up to this interval. Set to 10s to always check every 10s.",
+	)
+
+	fs.DurationVar(&maintenanceCheckInterval,
+		"maintenance-check-interval",
+		0,
+		"How often the events of running devices are checked for maintenances scheduled by Equinix Metal, reported with the MaintenanceScheduled condition of their PacketMachine. Disabled when 0.",
+	)
+
+	fs.DurationVar(&maintenanceCordonLeadTime,
+		"maintenance-cordon-lead-time",
+		0,
+		"How long ahead of a maintenance of its device the Node of a machine is cordoned, see --maintenance-check-interval. PacketMachines annotated with packetmachine.infrastructure.cluster.x-k8s.io/tolerate-maintenance=true are never cordoned. Maintenances are tolerated when 0.",
+	)
+
+	fs.BoolVar(&rootPasswordSecrets,
+		"root-password-secrets",
+		false,
+		"Store the root password Equinix Metal returns for new devices in a <packetmachine>-root-password Secret, for emergency access.",
+	)
+
+	fs.BoolVar(&platformLabels,
+		"platform-labels",
+		false,
+		"Label PacketMachines with the kubernetes.io/arch and kubernetes.io/os derived from their plan and operating system",
+	)
+
+	fs.BoolVar(&deleteDuplicateDevices,
+		"delete-duplicate-devices",
+		false,
+		"Delete the devices carrying the tags of a PacketMachine other than the one it uses, instead of only reporting them with the DuplicateDevices condition",
+	)
+
+	fs.DurationVar(&clusterDeletionTimeout,
+		"cluster-deletion-timeout",
+		30*time.Minute,
+		"How long the Equinix Metal resources of a deleted cluster may fail to be deleted before a ManualInterventionRequired event is recorded, 0 to never",
+	)
+
+	fs.DurationVar(&hardwareReservationTimeout,
+		"hardware-reservation-timeout",
+		0,
+		"How long after their creation PacketMachines wait for one of their hardware reservations to become available before failing, 0 to wait forever",
+	)
+
+	fs.DurationVar(&bgpSessionTimeout,
+		"bgp-session-timeout",
+		10*time.Minute,
+		"How long the BGP sessions of a device of a kube-vip cluster may stay down before the BGPSessionsReady condition becomes a warning, 0 to never warn",
+	)
+
+	fs.IntVar(&shardCount,
+		"shard-count",
+		1,
+		"Number of shards the clusters are split between, each reconciled by its own manager deployment. Sharding is disabled when 1.",
+	)
+
+	fs.IntVar(&shardIndex,
+		"shard-index",
+		0,
+		"Shard reconciled by this manager, from 0 to --shard-count - 1",
+	)
+
+	fs.StringVar(&shardBy,
+		"shard-by",
+		sharding.ByCluster,
+		fmt.Sprintf("How clusters are assigned to shards, by %q or by %q", sharding.ByCluster, sharding.ByNamespace),
+	)
+
+	fs.DurationVar(&ipReservationGCInterval,
+		"ip-reservation-gc-interval",
+		0,
+		"How often to release the control plane IP reservations of clusters that no longer exist. Disabled when 0. The projects swept must only hold clusters managed by this management cluster.",
+	)
+
+	fs.BoolVar(&ipReservationGCDryRun,
+		"ip-reservation-gc-dry-run",
+		false,
+		"Only log the IP reservations that would be released, see --ip-reservation-gc-interval",
+	)
+
+	fs.StringSliceVar(&ipReservationGCProjectIDs,
+		"ip-reservation-gc-project-ids",
+		nil,
+		"Additional Equinix Metal projects to sweep for stale IP reservations, on top of the projects of the existing PacketClusters",
+	)
+
+	fs.DurationVar(&capacityMetricsInterval,
+		"capacity-metrics-interval",
+		0,
+		"Interval at which the Equinix Metal capacity of the plans and metros of the PacketMachineTemplates is exported in the capp_capacity_level metric. Disabled when 0",
+	)
+
+	fs.DurationVar(&deviceBatchWindow,
+		"device-batch-window",
+		0,
+		"How long device creations wait for the creations of sibling machines in the same project, to create them together with the batch device creation API. Disabled when 0.",
+	)
+
+	fs.IntVar(&apiCallWarningThreshold,
+		"api-call-warning-threshold",
+		50,
+		"Number of Equinix Metal API calls above which a single reconciliation records a warning event on the reconciled object, with the calls by endpoint. Disabled when 0.",
+	)
+
+	fs.IntVar(&deviceBatchSize,
+		"device-batch-size",
+		10,
+		"Maximum number of devices created in a single batch, see --device-batch-window",
+	)
+
+	fs.BoolVar(&readOnly,
+		"read-only",
+		false,
+		"Only observe the Equinix Metal and Kubernetes resources, rejecting and logging every change, including to the status of objects. Devices are neither created nor deleted, and PacketDeviceClaims, the IP reservation garbage collector and the capacity metrics are left to the active manager. Useful for a passive standby manager, or to audit what a new version would do.",
+	)
+
+	fs.StringVar(&buildInfoNamespace,
+		"build-info-namespace",
+		os.Getenv("POD_NAMESPACE"),
+		"Namespace of the ConfigMap the version and the enabled features of the provider are published in. Defaults to the namespace of the manager, not published when empty.",
+	)
+
+	fs.StringVar(&installationID,
+		"installation-id",
+		"",
+		"Identifier of this installation of the provider, e.g. the name of its management cluster, added as the installation label to its capp_* metrics so that the metrics of several management clusters can be aggregated.",
+	)
+
+	fs.BoolVar(&bondRemediation,
+		"bond-remediation",
+		false,
+		"Bond the network ports of running devices again when they are found disbonded, once, and report the ports that stay disbonded with the NetworkBondReady condition",
+	)
+
+	fs.BoolVar(&hostnameReconciliation,
+		"hostname-reconciliation",
+		true,
+		"Rename running devices whose hostname differs from the one of their machine, e.g. after a rename in the Equinix Metal console. Set to false to leave the hostname of devices alone after their creation",
+	)
+
+	fs.BoolVar(&auditConfigMap,
+		"audit-configmap",
+		false,
+		"Record the changes made to the Equinix Metal infrastructure of each cluster, such as device creations and deletions and elastic IP assignments, in a <cluster>-infra-audit ConfigMap next to the Cluster",
+	)
+
+	fs.StringVar(&auditWebhookURL,
+		"audit-webhook-url",
+		"",
+		"URL the changes made to the Equinix Metal infrastructure of the clusters are posted to, as JSON, one request per change. Disabled when empty",
+	)
+
+	fs.StringVar(&cloudEventsURL,
+		"cloudevents-url",
+		"",
+		"URL the lifecycle events of the Equinix Metal infrastructure of the clusters, such as device creations, deletions and provisioning failures and elastic IP moves, are sent to as CloudEvents: posted to http(s):// URLs, or published to --cloudevents-nats-subject on nats:// and tls:// URLs of NATS servers. Disabled when empty",
+	)
+
+	fs.StringVar(&cloudEventsSource,
+		"cloudevents-source",
+		audit.DefaultCloudEventSource,
+		"Source of the CloudEvents sent to --cloudevents-url, e.g. to tell management clusters apart",
+	)
+
+	fs.StringVar(&cloudEventsNATSSubject,
+		"cloudevents-nats-subject",
+		audit.DefaultNATSSubject,
+		"NATS subject the CloudEvents are published to when --cloudevents-url is a NATS server",
+	)
+
+	fs.StringVar(&cloudEventsNATSCredentials,
+		"cloudevents-nats-credentials",
+		"",
+		"Path to a NATS credentials file, with the JWT and NKey seed authenticating to the NATS servers of --cloudevents-url instead of the user and password, or token, of the URL",
+	)
+
+	fs.BoolVar(&machinePool,
+		"machine-pool",
+		false,
+		"Enable the experimental PacketMachinePool controller, for Cluster API MachinePools of Equinix Metal devices. Requires the MachinePool feature of Cluster API",
+	)
+
+	fs.StringVar(&providerIDFormat,
+		"provider-id-format",
+		"equinixmetal",
+		"Format of the providerIDs given to new machines, \"equinixmetal\" for equinixmetal://<device-id> or \"packet\" for the legacy packet://<device-id> still expected by some tooling. Both formats are accepted on existing machines.",
+	)
+
 	fs.StringVar(&healthAddr,
 		"health-addr",
 		":9440",
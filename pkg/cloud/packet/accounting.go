@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// idPathSegment matches the IDs in the paths of the Equinix Metal API, which are UUIDs.
+var idPathSegment = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// APICalls tallies the Equinix Metal API calls made with a context, by endpoint.
+type APICalls struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+type apiCallsKey struct{}
+
+// WithAPICallAccounting returns a context whose Equinix Metal API calls are tallied in the returned APICalls.
+func WithAPICallAccounting(ctx context.Context) (context.Context, *APICalls) {
+	calls := &APICalls{counts: map[string]int{}}
+	return context.WithValue(ctx, apiCallsKey{}, calls), calls
+}
+
+func (c *APICalls) add(endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[endpoint]++
+}
+
+// Total returns the number of calls made.
+func (c *APICalls) Total() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := 0
+	for _, count := range c.counts {
+		total += count
+	}
+	return total
+}
+
+// Breakdown lists the number of calls made by endpoint, most called first.
+func (c *APICalls) Breakdown() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	endpoints := make([]string, 0, len(c.counts))
+	for endpoint := range c.counts {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if c.counts[endpoints[i]] != c.counts[endpoints[j]] {
+			return c.counts[endpoints[i]] > c.counts[endpoints[j]]
+		}
+		return endpoints[i] < endpoints[j]
+	})
+
+	breakdown := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		breakdown = append(breakdown, fmt.Sprintf("%s: %d", endpoint, c.counts[endpoint]))
+	}
+	return strings.Join(breakdown, ", ")
+}
+
+// apiEndpoint returns the endpoint of a request, with the IDs of its path replaced, e.g. "GET /devices/{id}".
+func apiEndpoint(req *http.Request) string {
+	segments := strings.Split(strings.TrimPrefix(req.URL.Path, "/metal/v1"), "/")
+	for i, segment := range segments {
+		if idPathSegment.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return req.Method + " " + strings.Join(segments, "/")
+}
+
+// accountingTransport tallies the requests made with a context set up by WithAPICallAccounting.
+type accountingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *accountingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if calls, ok := req.Context().Value(apiCallsKey{}).(*APICalls); ok {
+		calls.add(apiEndpoint(req))
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+)
+
+func TestAPICallAccounting(t *testing.T) {
+	g := NewWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.GetConfig().Servers = metal.ServerConfigurations{{URL: server.URL + "/metal/v1"}}
+
+	ctx, calls := WithAPICallAccounting(context.Background())
+	for _, id := range []string{"0d6a1b1c-6f5e-4c3d-9a5b-8f1e2d3c4b5a", "1e7b2c2d-7a6f-4d4e-8b6c-9a2f3e4d5c6b"} {
+		_, _, err := client.DevicesApi.FindDeviceById(ctx, id).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+	_, _, err := client.DevicesApi.FindProjectDevices(ctx, "0d6a1b1c-6f5e-4c3d-9a5b-8f1e2d3c4b5a").Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// Calls made without accounting are not tallied.
+	_, _, err = client.DevicesApi.FindDeviceById(context.Background(), "device").Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(calls.Total()).To(Equal(3))
+	g.Expect(calls.Breakdown()).To(Equal("GET /devices/{id}: 2, GET /projects/{id}/devices: 1"))
+}
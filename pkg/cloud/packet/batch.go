@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	// ErrDeviceBatchPending is returned when a device creation was accepted as part of a batch, but the device is not
+	// known yet. The device is found by its tags once the batch has been processed.
+	ErrDeviceBatchPending = errors.New("device creation batch is pending")
+	// ErrDeviceBatchFailed is returned when the device creation of a batch entry failed.
+	ErrDeviceBatchFailed = errors.New("device creation batch failed")
+
+	// errDeviceBatchUnavailable is returned to the device creations that could not be batched, either because no
+	// other creation happened within the batch window or because the API does not support batches. These
+	// creations are made individually.
+	errDeviceBatchUnavailable = errors.New("device creation batch unavailable")
+)
+
+// deviceBatchTimeout is how long the creation of a batch may take. Batches are created on behalf of several
+// reconciles, so they are not cancelled with any of them.
+const deviceBatchTimeout = time.Minute
+
+// createDeviceBatchFunc creates the devices of a batch in a project.
+type createDeviceBatchFunc func(ctx context.Context, projectID string, input metal.InstancesBatchCreateInput) (*metal.BatchesList, *http.Response, error)
+
+// deviceBatcher groups the device creations made in the same project within a short window, so that scaling up by
+// many replicas results in a single batch creation rather than one API call per device.
+type deviceBatcher struct {
+	window  time.Duration
+	maxSize int
+	create  createDeviceBatchFunc
+
+	mu      sync.Mutex
+	pending map[string]*deviceBatch
+}
+
+// deviceBatch is a batch of device creations waiting to be sent.
+type deviceBatch struct {
+	entries []metal.InstancesBatchCreateInputBatchesInner
+	results []error
+	devices []string
+	// full is closed once the batch reached its maximum size, done once its results are known.
+	full chan struct{}
+	done chan struct{}
+}
+
+// SetDeviceBatching enables the batching of device creations made in the same project within window of each other,
+// in batches of at most maxSize devices.
+func (p *Client) SetDeviceBatching(window time.Duration, maxSize int) {
+	p.batcher = &deviceBatcher{
+		window:  window,
+		maxSize: maxSize,
+		create: func(ctx context.Context, projectID string, input metal.InstancesBatchCreateInput) (*metal.BatchesList, *http.Response, error) {
+			return p.BatchesApi.CreateDeviceBatch(ctx, projectID).InstancesBatchCreateInput(input).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		},
+	}
+}
+
+// add queues the creation of a device and waits for the batch it joined to be sent, returning the ID of the device.
+// The first creation of a batch starts the sending of the batch, once the batch window expires or the batch is full.
+func (b *deviceBatcher) add(ctx context.Context, projectID string, entry metal.InstancesBatchCreateInputBatchesInner) (string, error) {
+	b.mu.Lock()
+	if b.pending == nil {
+		b.pending = map[string]*deviceBatch{}
+	}
+	batch, joined := b.pending[projectID]
+	if !joined {
+		batch = &deviceBatch{full: make(chan struct{}), done: make(chan struct{})}
+		b.pending[projectID] = batch
+	}
+	index := len(batch.entries)
+	batch.entries = append(batch.entries, entry)
+	if len(batch.entries) >= b.maxSize {
+		// Later creations start a new batch.
+		delete(b.pending, projectID)
+		close(batch.full)
+	}
+	b.mu.Unlock()
+
+	if !joined {
+		go b.send(projectID, batch)
+	}
+
+	select {
+	case <-batch.done:
+		return batch.devices[index], batch.results[index]
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// send waits for the batch to fill up and creates its devices.
+func (b *deviceBatcher) send(projectID string, batch *deviceBatch) {
+	timer := time.NewTimer(b.window)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-batch.full:
+	}
+
+	b.mu.Lock()
+	if b.pending[projectID] == batch {
+		delete(b.pending, projectID)
+	}
+	entries := batch.entries
+	b.mu.Unlock()
+
+	batch.devices = make([]string, len(entries))
+	batch.results = make([]error, len(entries))
+	defer close(batch.done)
+
+	if len(entries) == 1 {
+		batch.results[0] = errDeviceBatchUnavailable
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deviceBatchTimeout)
+	defer cancel()
+
+	list, resp, err := b.create(ctx, projectID, metal.InstancesBatchCreateInput{Batches: entries})
+	if err != nil && resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented) {
+		err = errDeviceBatchUnavailable
+	} else {
+		err = newAPIError(resp, err)
+	}
+	batch.devices, batch.results = deviceBatchResults(list, len(entries), err)
+}
+
+// deviceBatchResults maps the batches returned by the API back to the entries they were created for.
+func deviceBatchResults(list *metal.BatchesList, size int, err error) ([]string, []error) {
+	devices := make([]string, size)
+	results := make([]error, size)
+
+	for i := range results {
+		switch {
+		case err != nil:
+			results[i] = err
+		case i >= len(list.GetBatches()):
+			results[i] = ErrDeviceBatchPending
+		case len(list.Batches[i].ErrorMessages) > 0:
+			results[i] = fmt.Errorf("%w: %s", ErrDeviceBatchFailed, strings.Join(list.Batches[i].ErrorMessages, "; "))
+		case len(list.Batches[i].Devices) == 0:
+			results[i] = ErrDeviceBatchPending
+		default:
+			devices[i] = path.Base(list.Batches[i].Devices[0].GetHref())
+		}
+	}
+
+	return devices, results
+}
+
+// deviceBatchEntry returns the batch entry creating the same device as the request.
+func deviceBatchEntry(input *metal.DeviceCreateInMetroInput) metal.InstancesBatchCreateInputBatchesInner {
+	return metal.InstancesBatchCreateInputBatchesInner{
+		Quantity:              ptr.To[int32](1),
+		Hostname:              input.Hostname,
+		Metro:                 input.Metro,
+		BillingCycle:          input.BillingCycle,
+		Plan:                  input.Plan,
+		OperatingSystem:       input.OperatingSystem,
+		IpxeScriptUrl:         input.IpxeScriptUrl,
+		AlwaysPxe:             input.AlwaysPxe,
+		Tags:                  input.Tags,
+		Userdata:              input.Userdata,
+		Customdata:            input.Customdata,
+		IpAddresses:           input.IpAddresses,
+		PrivateIpv4SubnetSize: input.PrivateIpv4SubnetSize,
+		PublicIpv4SubnetSize:  input.PublicIpv4SubnetSize,
+		SshKeys:               input.SshKeys,
+		ProjectSshKeys:        input.ProjectSshKeys,
+		SpotInstance:          input.SpotInstance,
+		SpotPriceMax:          input.SpotPriceMax,
+		TerminationTime:       input.TerminationTime,
+	}
+}
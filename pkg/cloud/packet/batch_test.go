@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestDeviceBatcher(t *testing.T) {
+	g := NewWithT(t)
+
+	var calls int
+	batcher := &deviceBatcher{
+		window:  time.Hour,
+		maxSize: 3,
+		create: func(_ context.Context, _ string, input metal.InstancesBatchCreateInput) (*metal.BatchesList, *http.Response, error) {
+			calls++
+			list := &metal.BatchesList{}
+			for _, entry := range input.Batches {
+				batch := metal.Batch{}
+				if entry.GetHostname() == "failed" {
+					batch.ErrorMessages = []string{"no capacity"}
+				} else {
+					batch.Devices = []metal.Href{{Href: "/metal/v1/devices/" + entry.GetHostname()}}
+				}
+				list.Batches = append(list.Batches, batch)
+			}
+			return list, nil, nil
+		},
+	}
+
+	hostnames := []string{"a", "b", "failed"}
+	devices := make([]string, len(hostnames))
+	errs := make([]error, len(hostnames))
+
+	var wg sync.WaitGroup
+	for i, hostname := range hostnames {
+		wg.Add(1)
+		go func(i int, hostname string) {
+			defer wg.Done()
+			devices[i], errs[i] = batcher.add(context.Background(), "project", metal.InstancesBatchCreateInputBatchesInner{Hostname: ptr.To(hostname)})
+		}(i, hostname)
+	}
+	wg.Wait()
+
+	// The batch was full, so it was sent without waiting for the window to expire.
+	g.Expect(calls).To(Equal(1))
+	g.Expect(devices[:2]).To(ConsistOf("a", "b"))
+	g.Expect(errs[:2]).To(ConsistOf(BeNil(), BeNil()))
+	g.Expect(errs[2]).To(MatchError(ErrDeviceBatchFailed))
+	g.Expect(errs[2].Error()).To(ContainSubstring("no capacity"))
+}
+
+func TestDeviceBatcherSingleCreation(t *testing.T) {
+	g := NewWithT(t)
+
+	batcher := &deviceBatcher{
+		window:  time.Millisecond,
+		maxSize: 10,
+		create: func(context.Context, string, metal.InstancesBatchCreateInput) (*metal.BatchesList, *http.Response, error) {
+			return nil, nil, errors.New("unexpected batch")
+		},
+	}
+
+	_, err := batcher.add(context.Background(), "project", metal.InstancesBatchCreateInputBatchesInner{})
+	g.Expect(err).To(MatchError(errDeviceBatchUnavailable))
+}
+
+func TestDeviceBatcherCancelledCreation(t *testing.T) {
+	g := NewWithT(t)
+
+	metalClient := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"errors": ["Instance quota limit reached"]}`))
+	}))
+	metalClient.SetDeviceBatching(time.Hour, 2)
+
+	// The reconcile starting the batch gives up on it, the batch is still sent for the others.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := metalClient.batcher.add(ctx, "project", metal.InstancesBatchCreateInputBatchesInner{Hostname: ptr.To("a")})
+	g.Expect(err).To(MatchError(context.Canceled))
+
+	_, err = metalClient.batcher.add(context.Background(), "project", metal.InstancesBatchCreateInputBatchesInner{Hostname: ptr.To("b")})
+	g.Expect(IsQuotaExceeded(err)).To(BeTrue())
+}
+
+func Test_deviceBatchResults(t *testing.T) {
+	g := NewWithT(t)
+
+	list := &metal.BatchesList{Batches: []metal.Batch{
+		{Devices: []metal.Href{{Href: "/metal/v1/devices/d1"}}},
+		{},
+	}}
+	devices, results := deviceBatchResults(list, 3, nil)
+	g.Expect(devices).To(Equal([]string{"d1", "", ""}))
+	g.Expect(results[0]).ToNot(HaveOccurred())
+	g.Expect(results[1]).To(MatchError(ErrDeviceBatchPending))
+	g.Expect(results[2]).To(MatchError(ErrDeviceBatchPending))
+
+	errBatch := errors.New("batch failed")
+	_, results = deviceBatchResults(nil, 2, errBatch)
+	g.Expect(results).To(Equal([]error{errBatch, errBatch}))
+}
+
+func Test_deviceBatchEntry(t *testing.T) {
+	g := NewWithT(t)
+
+	input := &metal.DeviceCreateInMetroInput{
+		Metro:           "da",
+		Hostname:        ptr.To("machine"),
+		Plan:            "c3.small.x86",
+		OperatingSystem: "custom_ipxe",
+		Tags:            []string{"cluster"},
+		Userdata:        ptr.To("#cloud-config\n"),
+		Customdata:      deviceCustomData(&infrav1.CustomImage{URL: "https://github.com/example/images.git", Tag: "0123abc"}),
+	}
+
+	// The batch creates the device with its custom image.
+	entry := deviceBatchEntry(input)
+	g.Expect(entry.GetQuantity()).To(Equal(int32(1)))
+	g.Expect(entry.GetHostname()).To(Equal("machine"))
+	g.Expect(entry.GetMetro()).To(Equal("da"))
+	g.Expect(entry.GetUserdata()).To(Equal("#cloud-config\n"))
+	g.Expect(entry.Customdata).To(Equal(input.Customdata))
+	g.Expect(entry.Customdata).ToNot(BeEmpty())
+}
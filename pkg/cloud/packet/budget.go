@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+const (
+	// throttledWindow is how long a cluster is reported as throttled after it last exceeded its budget.
+	throttledWindow = time.Minute
+)
+
+var (
+	// ErrAPIBudgetExceeded is returned when a cluster exceeds its Equinix Metal API call budget.
+	ErrAPIBudgetExceeded = errors.New("equinix metal api call budget exceeded for cluster")
+
+	apiThrottledRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capp_metal_api_throttled_requests_total",
+		Help: "Number of Equinix Metal API requests rejected because the cluster exceeded its call budget.",
+	}, scope.ClusterMetricLabels)
+)
+
+func init() {
+	metrics.Registry.MustRegister(apiThrottledRequestsTotal)
+}
+
+type clusterBudgetKey struct{}
+
+// WithClusterBudget returns a context whose Equinix Metal API calls are accounted against the given cluster,
+// usually its namespace/name.
+func WithClusterBudget(ctx context.Context, cluster string) context.Context {
+	return context.WithValue(ctx, clusterBudgetKey{}, cluster)
+}
+
+func clusterFromContext(ctx context.Context) string {
+	cluster, _ := ctx.Value(clusterBudgetKey{}).(string)
+	return cluster
+}
+
+// apiBudget is a set of token buckets, one per cluster.
+type apiBudget struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	now      func() time.Time
+	clusters map[string]*clusterBudget
+}
+
+type clusterBudget struct {
+	limiter       *rate.Limiter
+	lastThrottled time.Time
+}
+
+func newAPIBudget(qps float64, burst int) *apiBudget {
+	return &apiBudget{
+		limit:    rate.Limit(qps),
+		burst:    burst,
+		now:      time.Now,
+		clusters: map[string]*clusterBudget{},
+	}
+}
+
+// allow takes a token from the cluster bucket, reporting whether there was one.
+func (b *apiBudget) allow(cluster string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb, ok := b.clusters[cluster]
+	if !ok {
+		cb = &clusterBudget{limiter: rate.NewLimiter(b.limit, b.burst)}
+		b.clusters[cluster] = cb
+	}
+
+	now := b.now()
+	if cb.limiter.AllowN(now, 1) {
+		return true
+	}
+	cb.lastThrottled = now
+	return false
+}
+
+// throttled reports whether the cluster exceeded its budget recently.
+func (b *apiBudget) throttled(cluster string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb, ok := b.clusters[cluster]
+	if !ok || cb.lastThrottled.IsZero() {
+		return false
+	}
+	return b.now().Sub(cb.lastThrottled) < throttledWindow
+}
+
+// forget drops the bucket of a cluster that is gone.
+func (b *apiBudget) forget(cluster string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.clusters, cluster)
+}
+
+// budgetTransport rejects requests of clusters that exceeded their budget before they reach the API.
+type budgetTransport struct {
+	next   http.RoundTripper
+	budget *apiBudget
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cluster := clusterFromContext(req.Context())
+	if cluster == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	if !t.budget.allow(cluster) {
+		apiThrottledRequestsTotal.With(metricLabelsFromContext(req.Context())).Inc()
+		return nil, fmt.Errorf("%w %s", ErrAPIBudgetExceeded, cluster)
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// SetClusterAPIBudget limits the Equinix Metal API calls made on behalf of each cluster, see WithClusterBudget,
+// to qps calls per second with bursts of up to burst calls. This keeps a single cluster stuck in a hot loop from
+// getting the shared project token rate limited for every other cluster.
+func (p *Client) SetClusterAPIBudget(qps float64, burst int) {
+	cfg := p.GetConfig()
+
+	next := http.DefaultTransport
+	if cfg.HTTPClient != nil && cfg.HTTPClient.Transport != nil {
+		next = cfg.HTTPClient.Transport
+	}
+
+	p.budget = newAPIBudget(qps, burst)
+	cfg.HTTPClient = &http.Client{
+		Transport: &budgetTransport{next: next, budget: p.budget},
+	}
+}
+
+// ClusterThrottled reports whether the cluster recently exceeded its Equinix Metal API call budget.
+func (p *Client) ClusterThrottled(cluster string) bool {
+	if p.budget == nil {
+		return false
+	}
+	return p.budget.throttled(cluster)
+}
+
+// ForgetClusterBudget releases the API call budget of a deleted cluster.
+func (p *Client) ForgetClusterBudget(cluster string) {
+	if p.budget == nil {
+		return
+	}
+	p.budget.forget(cluster)
+}
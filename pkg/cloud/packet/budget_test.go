@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func Test_apiBudget(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	b := newAPIBudget(1, 2)
+	b.now = func() time.Time { return now }
+
+	g.Expect(b.allow("ns/noisy")).To(BeTrue())
+	g.Expect(b.allow("ns/noisy")).To(BeTrue())
+	g.Expect(b.allow("ns/noisy")).To(BeFalse())
+	g.Expect(b.throttled("ns/noisy")).To(BeTrue())
+
+	// Other clusters have their own bucket.
+	g.Expect(b.allow("ns/quiet")).To(BeTrue())
+	g.Expect(b.throttled("ns/quiet")).To(BeFalse())
+
+	// The bucket refills over time and the cluster stops being reported as throttled.
+	now = now.Add(2 * throttledWindow)
+	g.Expect(b.throttled("ns/noisy")).To(BeFalse())
+	g.Expect(b.allow("ns/noisy")).To(BeTrue())
+}
+
+func Test_budgetTransport(t *testing.T) {
+	g := NewWithT(t)
+
+	var calls int
+	transport := &budgetTransport{
+		next: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}),
+		budget: newAPIBudget(0, 1),
+	}
+
+	newRequest := func(ctx context.Context) *http.Request {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.equinix.com/metal/v1/projects", http.NoBody)
+		g.Expect(err).ToNot(HaveOccurred())
+		return req
+	}
+
+	ctx := WithClusterBudget(context.Background(), "ns/cluster")
+	_, err := transport.RoundTrip(newRequest(ctx)) //nolint:bodyclose
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = transport.RoundTrip(newRequest(ctx)) //nolint:bodyclose
+	g.Expect(err).To(MatchError(ErrAPIBudgetExceeded))
+
+	// Requests that are not made on behalf of a cluster are never throttled.
+	_, err = transport.RoundTrip(newRequest(context.Background())) //nolint:bodyclose
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(calls).To(Equal(2))
+}
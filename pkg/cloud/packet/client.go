@@ -24,15 +24,23 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 
 	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1beta1"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/internal/readonly"
 	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
 	"sigs.k8s.io/cluster-api-provider-packet/version"
 )
@@ -45,6 +53,8 @@ const (
 	// DefaultLocalASN sets the Local ASN for BGP to our default of 65000.
 	DefaultLocalASN = 65000
 	legacyDebugVar  = "PACKNGO_DEBUG" // For backwards compatibility with packngo
+
+	elasticIPIdentifierPrefix = "cluster-api-provider-packet:cluster-id:"
 )
 
 var (
@@ -60,11 +70,29 @@ var (
 	ErrMissingEnvVar = errors.New("missing required env var")
 	// ErrInvalidRequest is returned when the request is invalid.
 	ErrInvalidRequest = errors.New("invalid request")
+	// ErrServiceIPPoolNotFound is returned when no Service IP pool reservation exists for the cluster.
+	ErrServiceIPPoolNotFound = errors.New("service IP pool not found")
+	// ErrIPAssignmentConflict is returned when an IP cannot be assigned to a device because it is still assigned to
+	// another one.
+	ErrIPAssignmentConflict = errors.New("IP is still assigned to another device")
+	// ErrIPReservationNotFound is returned when an IP reservation does not exist in the project.
+	ErrIPReservationNotFound = errors.New("IP reservation not found")
+	// ErrIPReservationInUse is returned when an IP reservation is already the control plane Elastic IP of another
+	// cluster.
+	ErrIPReservationInUse = errors.New("IP reservation is in use by another cluster")
 )
 
 // Client is a wrapper around the Equinix Metal API client.
 type Client struct {
 	*metal.APIClient
+
+	budget       *apiBudget
+	reservations reservationClaims
+	batcher      *deviceBatcher
+
+	// derived caches the clients of the credentials Secrets of clusters, see ClientForCluster.
+	derivedMu sync.Mutex
+	derived   map[string]*Client
 }
 
 // NewClient creates a new Client for the given Packet credentials.
@@ -77,7 +105,8 @@ func NewClient(packetAPIKey string) *Client {
 		configuration.AddDefaultHeader("X-Auth-Token", token)
 		configuration.AddDefaultHeader("X-Consumer-Token", clientName)
 		configuration.UserAgent = fmt.Sprintf(clientUAFormat, version.Get(), configuration.UserAgent)
-		metalClient := &Client{metal.NewAPIClient(configuration)}
+		configuration.HTTPClient = &http.Client{Transport: &accountingTransport{next: &metricsTransport{next: http.DefaultTransport}}}
+		metalClient := &Client{APIClient: metal.NewAPIClient(configuration)}
 		return metalClient
 	}
 
@@ -99,6 +128,98 @@ func (p *Client) GetDevice(ctx context.Context, deviceID string) (*metal.Device,
 	return dev, resp, err
 }
 
+// DevicePublicIPv4Block returns the public IPv4 block of the device in CIDR notation, or "" when it has none.
+func DevicePublicIPv4Block(dev *metal.Device) string {
+	for _, addr := range dev.IpAddresses {
+		if addr.GetPublic() && addr.GetAddressFamily() == 4 && addr.GetManagement() {
+			return fmt.Sprintf("%s/%d", addr.GetNetwork(), addr.GetCidr())
+		}
+	}
+	return ""
+}
+
+// DeviceProvisioningPercentage returns how far the provisioning of the device is, or nil when it is not provisioned.
+func DeviceProvisioningPercentage(dev *metal.Device) *int32 {
+	percentage, ok := dev.GetProvisioningPercentageOk()
+	if !ok {
+		return nil
+	}
+	return ptr.To(int32(*percentage))
+}
+
+// DeviceLastEvent returns the latest provisioning event of the device, or nil when it has none.
+func DeviceLastEvent(dev *metal.Device) *infrav1.DeviceEvent {
+	var last *metal.Event
+	for i, event := range dev.ProvisioningEvents {
+		if last == nil || event.GetCreatedAt().After(last.GetCreatedAt()) {
+			last = &dev.ProvisioningEvents[i]
+		}
+	}
+	if last == nil {
+		return nil
+	}
+
+	message := last.GetInterpolated()
+	if message == "" {
+		message = last.GetBody()
+	}
+	return &infrav1.DeviceEvent{
+		Type:    last.GetType(),
+		Message: message,
+		Time:    metav1.NewTime(last.GetCreatedAt()),
+	}
+}
+
+// DeviceHardwareReservationID returns the ID of the hardware reservation the device was created on, or "" when it
+// was not created on one. Unless it is included, the API only returns a link to the reservation.
+func DeviceHardwareReservationID(dev *metal.Device) string {
+	reservation, ok := dev.GetHardwareReservationOk()
+	if !ok {
+		return ""
+	}
+	if id := reservation.GetId(); id != "" {
+		return id
+	}
+	if href := reservation.GetHref(); href != "" {
+		return path.Base(href)
+	}
+	return ""
+}
+
+// DeviceHardware returns the hardware of the device as advertised by its plan, or nil when the plan has no specs.
+func DeviceHardware(dev *metal.Device) *infrav1.HardwareStatus {
+	specs := dev.GetPlan().Specs
+	if specs == nil {
+		return nil
+	}
+
+	hardware := &infrav1.HardwareStatus{}
+	if specs.Memory != nil {
+		hardware.Memory = specs.Memory.GetTotal()
+	}
+	for _, cpu := range specs.Cpus {
+		hardware.CPUs = append(hardware.CPUs, infrav1.HardwareCPU{
+			Count: cpu.GetCount(),
+			Type:  cpu.GetType(),
+		})
+	}
+	for _, drive := range specs.Drives {
+		hardware.Drives = append(hardware.Drives, infrav1.HardwareDrive{
+			Count:    drive.GetCount(),
+			Type:     drive.GetType(),
+			Size:     drive.GetSize(),
+			Category: drive.GetCategory(),
+		})
+	}
+	for _, nic := range specs.Nics {
+		hardware.NICs = append(hardware.NICs, infrav1.HardwareNIC{
+			Count: nic.GetCount(),
+			Type:  nic.GetType(),
+		})
+	}
+	return hardware
+}
+
 // CreateDeviceRequest is an object representing the API request to create a Device.
 type CreateDeviceRequest struct {
 	ExtraTags            []string
@@ -106,6 +227,8 @@ type CreateDeviceRequest struct {
 	ControlPlaneEndpoint string
 	CPEMLBConfig         string
 	EMLBID               string
+	// IPAddresses are the addresses claimed for the device from the AddressesFromPools of its PacketMachine.
+	IPAddresses []ipamv1.IPAddressSpec
 }
 
 // NewDevice creates a new device.
@@ -118,27 +241,42 @@ func (p *Client) NewDevice(ctx context.Context, req CreateDeviceRequest) (*metal
 			return nil, fmt.Errorf("os should be set to custom_pxe when using pxe urls: %w", ErrInvalidRequest)
 		}
 	}
+	if packetMachineSpec.IPXEScriptSecretRef != nil {
+		if packetMachineSpec.IPXEUrl != "" {
+			return nil, fmt.Errorf("ipxeURL and ipxeScriptSecretRef are mutually exclusive: %w", ErrInvalidRequest)
+		}
+		if packetMachineSpec.OS != ipxeOS {
+			return nil, fmt.Errorf("os should be set to custom_pxe when using an ipxe script: %w", ErrInvalidRequest)
+		}
+	}
+	if packetMachineSpec.AlwaysPXE && packetMachineSpec.OS != ipxeOS {
+		return nil, fmt.Errorf("os should be set to custom_pxe when always booting from the network: %w", ErrInvalidRequest)
+	}
+	if packetMachineSpec.CustomImage != nil && packetMachineSpec.OS == ipxeOS {
+		return nil, fmt.Errorf("os should be set to the operating system of the custom image: %w", ErrInvalidRequest)
+	}
 
 	userDataRaw, err := req.MachineScope.GetRawBootstrapData(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve bootstrap data from secret: %w", err)
 	}
+	bootstrapFormat, err := req.MachineScope.GetBootstrapDataFormat(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve bootstrap data format: %w", err)
+	}
 
-	stringWriter := &strings.Builder{}
 	userData := string(userDataRaw)
 	userDataValues := map[string]interface{}{
 		"kubernetesVersion": ptr.Deref(req.MachineScope.Machine.Spec.Version, ""),
+		"arch":              PlanArchitecture(packetMachineSpec.MachineType),
+		"os":                OSFamily(packetMachineSpec.OS),
+		"ipAddresses":       userDataIPAddresses(req.IPAddresses),
 	}
 
 	tags := make([]string, 0, len(packetMachineSpec.Tags)+len(req.ExtraTags))
 	copy(tags, packetMachineSpec.Tags)
 	tags = append(tags, req.ExtraTags...)
 
-	tmpl, err := template.New("user-data").Parse(userData)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing userdata template: %w", err)
-	}
-
 	if req.MachineScope.IsControlPlane() {
 		// control plane machines should get the API key injected
 		userDataValues["apiKey"] = p.APIClient.GetConfig().DefaultHeader["X-Auth-Token"]
@@ -160,11 +298,29 @@ func (p *Client) NewDevice(ctx context.Context, req CreateDeviceRequest) (*metal
 		tags = append(tags, infrav1.WorkerTag)
 	}
 
-	if err := tmpl.Execute(stringWriter, userDataValues); err != nil {
-		return nil, fmt.Errorf("error executing userdata template: %w", err)
+	userData, err = renderUserData(userData, bootstrapFormat, userDataValues)
+	if err != nil {
+		return nil, err
+	}
+	if userData, err = hardenUserData(userData, bootstrapFormat, packetMachineSpec.OSHardening); err != nil {
+		return nil, err
 	}
 
-	userData = stringWriter.String()
+	ipxeScriptURL := &req.MachineScope.PacketMachine.Spec.IPXEUrl
+	if packetMachineSpec.IPXEScriptSecretRef != nil {
+		// Equinix Metal runs the userdata as the iPXE script when custom_ipxe is used without a script URL,
+		// so private scripts never have to be served from a public location.
+		script, err := req.MachineScope.GetRawIPXEScript(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve ipxe script from secret: %w", err)
+		}
+		userData = string(script)
+		ipxeScriptURL = nil
+		bootstrapFormat = ""
+	}
+	if userData, err = fitUserData(userData, bootstrapFormat); err != nil {
+		return nil, err
+	}
 
 	// If Metro or Facility are specified at the Machine level, we ignore the
 	// values set at the Cluster level
@@ -176,62 +332,273 @@ func (p *Client) NewDevice(ctx context.Context, req CreateDeviceRequest) (*metal
 		facility = packetMachineSpec.Facility
 	}
 
-	hostname := req.MachineScope.Name()
+	hostname := req.MachineScope.Hostname()
+
+	var alwaysPXE *bool
+	if packetMachineSpec.AlwaysPXE {
+		alwaysPXE = ptr.To(true)
+	}
+
+	spotInstance, spotPriceMax := deviceSpotMarket(packetMachineSpec.SpotPriceMax)
+	var terminationTime *time.Time
+	if packetMachineSpec.TerminationTime != nil {
+		terminationTime = ptr.To(packetMachineSpec.TerminationTime.UTC())
+	}
 
 	serverCreateOpts := metal.CreateDeviceRequest{}
 
 	if facility != "" {
 		serverCreateOpts.DeviceCreateInFacilityInput = &metal.DeviceCreateInFacilityInput{
-			Hostname:        &hostname,
-			Facility:        []string{facility},
-			BillingCycle:    &req.MachineScope.PacketMachine.Spec.BillingCycle,
-			Plan:            req.MachineScope.PacketMachine.Spec.MachineType,
-			OperatingSystem: req.MachineScope.PacketMachine.Spec.OS,
-			IpxeScriptUrl:   &req.MachineScope.PacketMachine.Spec.IPXEUrl,
-			Tags:            tags,
-			Userdata:        &userData,
+			Hostname:              &hostname,
+			Facility:              []string{facility},
+			BillingCycle:          &req.MachineScope.PacketMachine.Spec.BillingCycle,
+			Plan:                  req.MachineScope.PacketMachine.Spec.MachineType,
+			OperatingSystem:       req.MachineScope.PacketMachine.Spec.OS,
+			IpxeScriptUrl:         ipxeScriptURL,
+			AlwaysPxe:             alwaysPXE,
+			Customdata:            deviceCustomData(packetMachineSpec.CustomImage),
+			Tags:                  tags,
+			Userdata:              &userData,
+			IpAddresses:           deviceIPAddresses(packetMachineSpec.IPAddresses),
+			PrivateIpv4SubnetSize: packetMachineSpec.PrivateIPv4SubnetSize,
+			PublicIpv4SubnetSize:  packetMachineSpec.PublicIPv4SubnetSize,
+			SshKeys:               deviceSSHKeys(packetMachineSpec.SSHKeys),
+			ProjectSshKeys:        packetMachineSpec.ProjectSSHKeyIDs,
+			SpotInstance:          spotInstance,
+			SpotPriceMax:          spotPriceMax,
+			TerminationTime:       terminationTime,
 		}
 	} else {
 		serverCreateOpts.DeviceCreateInMetroInput = &metal.DeviceCreateInMetroInput{
-			Hostname:        &hostname,
-			Metro:           metro,
-			BillingCycle:    &req.MachineScope.PacketMachine.Spec.BillingCycle,
-			Plan:            req.MachineScope.PacketMachine.Spec.MachineType,
-			OperatingSystem: req.MachineScope.PacketMachine.Spec.OS,
-			IpxeScriptUrl:   &req.MachineScope.PacketMachine.Spec.IPXEUrl,
-			Tags:            tags,
-			Userdata:        &userData,
+			Hostname:              &hostname,
+			Metro:                 metro,
+			BillingCycle:          &req.MachineScope.PacketMachine.Spec.BillingCycle,
+			Plan:                  req.MachineScope.PacketMachine.Spec.MachineType,
+			OperatingSystem:       req.MachineScope.PacketMachine.Spec.OS,
+			IpxeScriptUrl:         ipxeScriptURL,
+			AlwaysPxe:             alwaysPXE,
+			Customdata:            deviceCustomData(packetMachineSpec.CustomImage),
+			Tags:                  tags,
+			Userdata:              &userData,
+			IpAddresses:           deviceIPAddresses(packetMachineSpec.IPAddresses),
+			PrivateIpv4SubnetSize: packetMachineSpec.PrivateIPv4SubnetSize,
+			PublicIpv4SubnetSize:  packetMachineSpec.PublicIPv4SubnetSize,
+			SshKeys:               deviceSSHKeys(packetMachineSpec.SSHKeys),
+			ProjectSshKeys:        packetMachineSpec.ProjectSSHKeyIDs,
+			SpotInstance:          spotInstance,
+			SpotPriceMax:          spotPriceMax,
+			TerminationTime:       terminationTime,
 		}
 	}
 
-	reservationIDs := strings.Split(packetMachineSpec.HardwareReservationID, ",")
+	reservationIDs, err := p.hardwareReservationIDs(ctx, req.MachineScope, facility, metro)
+	if err != nil {
+		return nil, err
+	}
 
 	// If there are no reservationIDs to process, go ahead and return early
-	if len(reservationIDs) <= 1 {
+	if len(reservationIDs) == 0 {
+		if p.batcher != nil && serverCreateOpts.DeviceCreateInMetroInput != nil {
+			deviceID, err := p.batcher.add(ctx, req.MachineScope.PacketCluster.Spec.ProjectID, deviceBatchEntry(serverCreateOpts.DeviceCreateInMetroInput))
+			switch {
+			case errors.Is(err, errDeviceBatchUnavailable):
+				// Create the device on its own.
+			case err != nil:
+				return nil, err
+			default:
+				dev, _, err := p.GetDevice(ctx, deviceID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+				return dev, err
+			}
+		}
+
 		apiRequest := p.DevicesApi.CreateDevice(ctx, req.MachineScope.PacketCluster.Spec.ProjectID)
-		dev, _, err := apiRequest.CreateDeviceRequest(serverCreateOpts).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
-		return dev, err
+		dev, resp, err := apiRequest.CreateDeviceRequest(serverCreateOpts).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		return dev, newAPIError(resp, err)
 	}
 
-	// Do a naive loop through the list of reservationIDs, continuing if we hit any error
-	// TODO: if we can determine how to differentiate a failure based on the reservation
-	// being in use vs other errors, then we can make this a bit smarter in the future.
+	fallbacks := reservationFallbacksTotal.MustCurryWith(req.MachineScope.MetricLabels())
+
+	// Do a naive loop through the list of reservationIDs, skipping the busy ones and backing off from the ones that
+	// turn out to be busy, and reporting them all if none is available.
 	var lastErr error
+	unavailable := &ReservationsUnavailableError{Err: ErrReservationPoolExhausted}
+	var retryAt time.Time
+	retryAfter := func(t time.Time) {
+		if retryAt.IsZero() || t.Before(retryAt) {
+			retryAt = t
+		}
+	}
 
 	for _, resID := range reservationIDs {
 		reservationID := resID
-		serverCreateOpts.DeviceCreateInFacilityInput.HardwareReservationId = &reservationID
+		// Equinix Metal picks a different reservation for every creation asking for the next available one, so it is
+		// never claimed nor backed off from.
+		tracked := reservationID != nextAvailableReservation
+		if until := p.reservations.busyUntil(reservationID); tracked && !until.IsZero() {
+			unavailable.Attempts = append(unavailable.Attempts, ReservationAttempt{ReservationID: reservationID, Reason: "busy until " + until.Format(time.RFC3339)})
+			retryAfter(until)
+			fallbacks.WithLabelValues("busy").Inc()
+			continue
+		}
+		// Skip reservations another machine is being created on.
+		if tracked && !p.reservations.claim(reservationID) {
+			unavailable.Attempts = append(unavailable.Attempts, ReservationAttempt{ReservationID: reservationID, Reason: "used by another device creation"})
+			retryAfter(time.Now().Add(reservationBackoffBase))
+			fallbacks.WithLabelValues("claimed").Inc()
+			continue
+		}
+		if serverCreateOpts.DeviceCreateInFacilityInput != nil {
+			serverCreateOpts.DeviceCreateInFacilityInput.HardwareReservationId = &reservationID
+		} else {
+			serverCreateOpts.DeviceCreateInMetroInput.HardwareReservationId = &reservationID
+		}
 		apiRequest := p.DevicesApi.CreateDevice(ctx, req.MachineScope.PacketCluster.Spec.ProjectID)
-		dev, _, err := apiRequest.CreateDeviceRequest(serverCreateOpts).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
-		if err != nil {
+		dev, resp, err := apiRequest.CreateDeviceRequest(serverCreateOpts).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		err = newAPIError(resp, err)
+		switch {
+		case IsReservationBusy(err):
+			if tracked {
+				retryAfter(p.reservations.markBusy(reservationID))
+			} else {
+				retryAfter(time.Now().Add(reservationBackoffBase))
+			}
+			unavailable.Attempts = append(unavailable.Attempts, ReservationAttempt{ReservationID: reservationID, Reason: err.Error()})
+			unavailable.Err = err
+			fallbacks.WithLabelValues("busy").Inc()
+			continue
+		case err != nil:
+			if tracked {
+				p.reservations.release(reservationID)
+			}
 			lastErr = err
+			fallbacks.WithLabelValues("failed").Inc()
 			continue
 		}
 
+		if tracked {
+			p.reservations.markUsed(reservationID)
+		}
 		return dev, nil
 	}
 
-	return nil, lastErr
+	// Errors other than busy reservations are not retried any better by waiting.
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	unavailable.RetryAfter = max(time.Until(retryAt).Round(time.Second), time.Second)
+	return nil, unavailable
+}
+
+// deviceIPAddresses converts the address blocks of a PacketMachineSpec to the ones of a device creation request.
+func deviceIPAddresses(addresses []infrav1.DeviceIPAddress) []metal.IPAddress {
+	if len(addresses) == 0 {
+		return nil
+	}
+	out := make([]metal.IPAddress, 0, len(addresses))
+	for _, address := range addresses {
+		family := metal.IPADDRESSADDRESSFAMILY__4
+		if address.AddressFamily == 6 {
+			family = metal.IPADDRESSADDRESSFAMILY__6
+		}
+		out = append(out, metal.IPAddress{
+			AddressFamily:  &family,
+			Public:         ptr.To(address.Public),
+			Cidr:           address.CIDR,
+			IpReservations: address.IPReservations,
+		})
+	}
+	return out
+}
+
+// userDataIPAddresses returns the claimed addresses of a device as the values of the ipAddresses template variable,
+// keyed like the other template variables.
+func userDataIPAddresses(addresses []ipamv1.IPAddressSpec) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(addresses))
+	for _, address := range addresses {
+		out = append(out, map[string]interface{}{
+			"address": address.Address,
+			"prefix":  address.Prefix,
+			"gateway": address.Gateway,
+		})
+	}
+	return out
+}
+
+// deviceSpotMarket returns whether a device is created as a spot market instance, and its maximum bid, for the
+// SpotPriceMax of a PacketMachineSpec.
+func deviceSpotMarket(priceMax string) (*bool, *float32) {
+	if priceMax == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(priceMax, 32)
+	if err != nil {
+		return nil, nil
+	}
+	return ptr.To(true), ptr.To(float32(price))
+}
+
+// deviceSSHKeys converts the public keys of a PacketMachineSpec to the ones of a device creation request, skipping
+// empty keys, e.g. left by an unset template variable.
+func deviceSSHKeys(keys []string) []metal.SSHKeyInput {
+	var out []metal.SSHKeyInput
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			out = append(out, metal.SSHKeyInput{Key: ptr.To(key)})
+		}
+	}
+	return out
+}
+
+// deviceCustomData returns the custom data a device is created with to install the custom image, or nil.
+func deviceCustomData(image *infrav1.CustomImage) map[string]interface{} {
+	if image == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"image_repo": image.URL,
+		"image_tag":  image.Tag,
+	}
+}
+
+// renderUserData templates bootstrap data with values. Only cloud-config is templated, Ignition and Talos configs
+// are passed to the device as they are, as their own syntax may clash with the template delimiters.
+func renderUserData(userData string, format scope.BootstrapFormat, values map[string]interface{}) (string, error) {
+	if format != scope.BootstrapFormatCloudConfig {
+		return userData, nil
+	}
+
+	tmpl, err := template.New("user-data").Parse(userData)
+	if err != nil {
+		return "", fmt.Errorf("error parsing userdata template: %w", err)
+	}
+
+	stringWriter := &strings.Builder{}
+	if err := tmpl.Execute(stringWriter, values); err != nil {
+		return "", fmt.Errorf("error executing userdata template: %w", err)
+	}
+	return stringWriter.String(), nil
+}
+
+// PowerOffDevice powers off the device, keeping it and its hardware reservation.
+func (p *Client) PowerOffDevice(ctx context.Context, deviceID string) error {
+	return p.performDeviceAction(ctx, deviceID, metal.DEVICEACTIONINPUTTYPE_POWER_OFF)
+}
+
+// PowerOnDevice powers on the device.
+func (p *Client) PowerOnDevice(ctx context.Context, deviceID string) error {
+	return p.performDeviceAction(ctx, deviceID, metal.DEVICEACTIONINPUTTYPE_POWER_ON)
+}
+
+// RebootDevice reboots the device, keeping its disks.
+func (p *Client) RebootDevice(ctx context.Context, deviceID string) error {
+	return p.performDeviceAction(ctx, deviceID, metal.DEVICEACTIONINPUTTYPE_REBOOT)
+}
+
+func (p *Client) performDeviceAction(ctx context.Context, deviceID string, action metal.DeviceActionInputType) error {
+	_, err := p.DevicesApi.PerformAction(ctx, deviceID).DeviceActionInput(metal.DeviceActionInput{
+		Type: action,
+	}).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	return err
 }
 
 // GetDeviceAddresses returns the addresses of the device.
@@ -251,24 +618,48 @@ func (p *Client) GetDeviceAddresses(device *metal.Device) []corev1.NodeAddress {
 	return addrs
 }
 
-// GetDeviceByTags returns the first device that matches all of the tags.
+// SetReadOnly keeps the client from changing any Equinix Metal resource, see readonly.NewTransport.
+func (p *Client) SetReadOnly() {
+	cfg := p.GetConfig()
+
+	next := http.DefaultTransport
+	if cfg.HTTPClient != nil && cfg.HTTPClient.Transport != nil {
+		next = cfg.HTTPClient.Transport
+	}
+	cfg.HTTPClient = &http.Client{Transport: readonly.NewTransport(next)}
+}
+
+// GetDeviceByTags returns the oldest device that matches all of the tags.
 func (p *Client) GetDeviceByTags(ctx context.Context, project string, tags []string) (*metal.Device, error) {
+	devices, err := p.GetDevicesByTags(ctx, project, tags)
+	if err != nil || len(devices) == 0 {
+		return nil, err
+	}
+	return &devices[0], nil
+}
+
+// GetDevicesByTags returns all the devices that match all of the tags, oldest first.
+func (p *Client) GetDevicesByTags(ctx context.Context, project string, tags []string) ([]metal.Device, error) {
 	devices, _, err := p.DevicesApi.FindProjectDevices(ctx, project).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving devices: %w", err)
 	}
-	// returns the first one that matches all of the tags
+
+	var matches []metal.Device
 	for _, device := range devices.Devices {
 		if ItemsInList(device.Tags, tags) {
-			return &device, nil
+			matches = append(matches, device)
 		}
 	}
-	return nil, nil
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].GetCreatedAt().Before(matches[j].GetCreatedAt())
+	})
+	return matches, nil
 }
 
 // CreateIP reserves an IP via Packet API. The request fails straight if no IP are available for the specified project.
 // This prevent the cluster to become ready.
-func (p *Client) CreateIP(ctx context.Context, _, clusterName, projectID, facility, metro string) (net.IP, error) {
+func (p *Client) CreateIP(ctx context.Context, _, clusterName, projectID, facility, metro string) (*metal.IPReservation, error) {
 	failOnApprovalRequired := true
 	req := metal.IPReservationRequestInput{
 		Type:                   "public_ipv4",
@@ -284,18 +675,106 @@ func (p *Client) CreateIP(ctx context.Context, _, clusterName, projectID, facili
 		IPReservationRequestInput: &req,
 	}).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
 	if err != nil {
-		return nil, err
+		return nil, newAPIError(resp, err)
 	}
 	if resp.StatusCode == http.StatusUnprocessableEntity {
 		return nil, ErrElasticIPQuotaExceeded
 	}
 
 	rawIP := r.IPReservation.GetAddress()
-	ip := net.ParseIP(rawIP)
-	if ip == nil {
+	if ip := net.ParseIP(rawIP); ip == nil || ip.To4() == nil {
 		return nil, fmt.Errorf("failed to parse IP: %s, %w", rawIP, ErrInvalidIP)
 	}
-	return ip, nil
+	return r.IPReservation, nil
+}
+
+// CreateServiceIPPool reserves a block of public IPv4 addresses for the cluster Services. Like CreateIP, the request
+// fails straight if the block cannot be allocated without approval.
+func (p *Client) CreateServiceIPPool(ctx context.Context, namespace, clusterName, projectID, facility, metro string, size int32) (*metal.IPReservation, error) {
+	failOnApprovalRequired := true
+	req := metal.IPReservationRequestInput{
+		Type:                   "public_ipv4",
+		Quantity:               size,
+		FailOnApprovalRequired: &failOnApprovalRequired,
+		Tags:                   []string{generateServiceIPPoolIdentifier(namespace, clusterName)},
+	}
+	if facility != "" {
+		req.Facility = &facility
+	}
+	if metro != "" {
+		req.Metro = &metro
+	}
+
+	apiRequest := p.IPAddressesApi.RequestIPReservation(ctx, projectID)
+	r, resp, err := apiRequest.RequestIPReservationRequest(metal.RequestIPReservationRequest{
+		IPReservationRequestInput: &req,
+	}).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnprocessableEntity {
+		return nil, ErrElasticIPQuotaExceeded
+	}
+
+	return r.IPReservation, nil
+}
+
+// GetServiceIPPool returns the Service IP pool reservation for the given cluster.
+func (p *Client) GetServiceIPPool(ctx context.Context, namespace, clusterName, projectID string) (*metal.IPReservation, error) {
+	identifier := generateServiceIPPoolIdentifier(namespace, clusterName)
+	reservedIPs, _, err := p.IPAddressesApi.FindIPReservations(ctx, projectID).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return nil, err
+	}
+	for _, reservedIPWrapper := range reservedIPs.IpAddresses {
+		ipReservation := reservedIPWrapper.IPReservation
+		if ipReservation == nil {
+			continue
+		}
+		for _, tag := range ipReservation.Tags {
+			if tag == identifier {
+				return ipReservation, nil
+			}
+		}
+	}
+	return nil, ErrServiceIPPoolNotFound
+}
+
+// DeleteServiceIPPool releases the Service IP pool reservation with the given ID. A reservation that is already
+// gone is not an error.
+func (p *Client) DeleteServiceIPPool(ctx context.Context, reservationID string) error {
+	return p.DeleteIPReservation(ctx, reservationID)
+}
+
+// DeleteIPReservation releases the IP reservation with the given ID. A reservation that is already gone is not
+// an error.
+func (p *Client) DeleteIPReservation(ctx context.Context, reservationID string) error {
+	resp, err := p.IPAddressesApi.DeleteIPAddress(ctx, reservationID).Execute()
+	if resp != nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+	}
+	return err
+}
+
+// AssignIP assigns an IP address to a device. It returns ErrIPAssignmentConflict when the API refuses the assignment,
+// as it does while the address is assigned to another device.
+func (p *Client) AssignIP(ctx context.Context, deviceID, address string) error {
+	_, resp, err := p.DevicesApi.CreateIPAssignment(ctx, deviceID).IPAssignmentInput(metal.IPAssignmentInput{
+		Address: address,
+	}).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil && resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+		return fmt.Errorf("failed to assign %s to device %s: %w: %w", address, deviceID, ErrIPAssignmentConflict, err)
+	}
+	return err
+}
+
+// UnassignIP removes the IP assignment with the given ID from its device. An assignment that is already gone is not
+// an error.
+func (p *Client) UnassignIP(ctx context.Context, assignmentID string) error {
+	return p.DeleteIPReservation(ctx, assignmentID)
 }
 
 // EnableProjectBGP enables bgp on the project.
@@ -364,6 +843,36 @@ func (p *Client) EnsureNodeBGPEnabled(ctx context.Context, id string) error {
 	return err
 }
 
+// GetBGPSessions returns the BGP sessions of the device.
+func (p *Client) GetBGPSessions(ctx context.Context, deviceID string) ([]metal.BgpSession, error) {
+	sessions, _, err := p.DevicesApi.FindBgpSessions(ctx, deviceID).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return nil, err
+	}
+	return sessions.BgpSessions, nil
+}
+
+// BGPSessionState returns the state of a BGP session. Its status holds one value per switch the device is connected
+// to, so the session is only up once it is up with every switch.
+func BGPSessionState(session metal.BgpSession) infrav1.BGPSessionState {
+	statuses := strings.FieldsFunc(session.GetStatus(), func(r rune) bool { return r == ',' || r == ' ' })
+	if len(statuses) == 0 {
+		return infrav1.BGPSessionUnknown
+	}
+
+	state := infrav1.BGPSessionUp
+	for _, status := range statuses {
+		switch infrav1.BGPSessionState(status) {
+		case infrav1.BGPSessionUp:
+		case infrav1.BGPSessionDown:
+			return infrav1.BGPSessionDown
+		default:
+			state = infrav1.BGPSessionUnknown
+		}
+	}
+	return state
+}
+
 // GetIPByClusterIdentifier returns the IP reservation for the given cluster identifier.
 func (p *Client) GetIPByClusterIdentifier(ctx context.Context, _, name, projectID string) (*metal.IPReservation, error) {
 	var err error
@@ -387,8 +896,112 @@ func (p *Client) GetIPByClusterIdentifier(ctx context.Context, _, name, projectI
 	return ipReservation, ErrControlPlanEndpointNotFound
 }
 
+// AdoptIPReservation returns the existing IP reservation with the given ID, tagging it as the control plane Elastic
+// IP of the cluster so that it is found like one created by CreateIP. It returns ErrIPReservationNotFound when the
+// project has no such reservation, and ErrIPReservationInUse when it is tagged for another cluster.
+func (p *Client) AdoptIPReservation(ctx context.Context, reservationID, clusterName, projectID string) (*metal.IPReservation, error) {
+	reservedIPs, _, err := p.IPAddressesApi.FindIPReservations(ctx, projectID).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return nil, err
+	}
+
+	var ipReservation *metal.IPReservation
+	for _, reservedIPWrapper := range reservedIPs.IpAddresses {
+		if reservedIPWrapper.IPReservation != nil && reservedIPWrapper.IPReservation.GetId() == reservationID {
+			ipReservation = reservedIPWrapper.IPReservation
+			break
+		}
+	}
+	if ipReservation == nil {
+		return nil, fmt.Errorf("%w: %s", ErrIPReservationNotFound, reservationID)
+	}
+
+	if owner, ok := ElasticIPClusterName(ipReservation); ok {
+		if owner != clusterName {
+			return nil, fmt.Errorf("%w: %s is tagged for cluster %s", ErrIPReservationInUse, reservationID, owner)
+		}
+		return ipReservation, nil
+	}
+
+	tags := append(slices.Clone(ipReservation.Tags), generateElasticIPIdentifier(clusterName))
+	if err := p.updateIPReservationTags(ctx, reservationID, tags); err != nil {
+		return nil, fmt.Errorf("failed to tag IP reservation %s: %w", reservationID, err)
+	}
+	ipReservation.Tags = tags
+	return ipReservation, nil
+}
+
+// ReleaseIPReservation removes the tag AdoptIPReservation added to the IP reservation with the given ID, leaving the
+// reservation itself to its owner. A reservation that is already gone or untagged is not an error.
+func (p *Client) ReleaseIPReservation(ctx context.Context, reservationID, clusterName, projectID string) error {
+	reservedIPs, _, err := p.IPAddressesApi.FindIPReservations(ctx, projectID).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return err
+	}
+
+	eipIdentifier := generateElasticIPIdentifier(clusterName)
+	for _, reservedIPWrapper := range reservedIPs.IpAddresses {
+		ipReservation := reservedIPWrapper.IPReservation
+		if ipReservation == nil || ipReservation.GetId() != reservationID {
+			continue
+		}
+		if !slices.Contains(ipReservation.Tags, eipIdentifier) {
+			return nil
+		}
+		tags := slices.DeleteFunc(slices.Clone(ipReservation.Tags), func(tag string) bool { return tag == eipIdentifier })
+		return p.updateIPReservationTags(ctx, reservationID, tags)
+	}
+	return nil
+}
+
+// updateIPReservationTags replaces the tags of an IP reservation.
+func (p *Client) updateIPReservationTags(ctx context.Context, reservationID string, tags []string) error {
+	if tags == nil {
+		// An empty, rather than omitted, list is needed to remove the last tag.
+		tags = []string{}
+	}
+	_, _, err := p.IPAddressesApi.UpdateIPAddress(ctx, reservationID).IPAssignmentUpdateInput(metal.IPAssignmentUpdateInput{
+		Tags: tags,
+	}).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	return err
+}
+
+// ListElasticIPs returns the control plane IP reservations of the project, whichever cluster they belong to.
+func (p *Client) ListElasticIPs(ctx context.Context, projectID string) ([]*metal.IPReservation, error) {
+	reservedIPs, _, err := p.IPAddressesApi.FindIPReservations(ctx, projectID).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return nil, err
+	}
+	var ipReservations []*metal.IPReservation
+	for _, reservedIPWrapper := range reservedIPs.IpAddresses {
+		if _, ok := ElasticIPClusterName(reservedIPWrapper.IPReservation); ok {
+			ipReservations = append(ipReservations, reservedIPWrapper.IPReservation)
+		}
+	}
+	return ipReservations, nil
+}
+
+// ElasticIPClusterName returns the name of the cluster a control plane IP reservation was created for.
+func ElasticIPClusterName(ipReservation *metal.IPReservation) (string, bool) {
+	if ipReservation == nil {
+		return "", false
+	}
+	for _, tag := range ipReservation.Tags {
+		if name, ok := strings.CutPrefix(tag, elasticIPIdentifierPrefix); ok && name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
 func generateElasticIPIdentifier(name string) string {
-	return fmt.Sprintf("cluster-api-provider-packet:cluster-id:%s", name)
+	return elasticIPIdentifierPrefix + name
+}
+
+// generateServiceIPPoolIdentifier returns the tag of the Service IP pool of a cluster, which includes its namespace
+// as clusters of the same name may live in several namespaces of a project.
+func generateServiceIPPoolIdentifier(namespace, name string) string {
+	return fmt.Sprintf("cluster-api-provider-packet:service-pool:%s/%s", namespace, name)
 }
 
 // This function provides backwards compatibility for the packngo
@@ -0,0 +1,300 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestElasticIPClusterName(t *testing.T) {
+	tests := []struct {
+		name          string
+		ipReservation *metal.IPReservation
+		want          string
+		wantOK        bool
+	}{
+		{
+			name: "nil reservation",
+		},
+		{
+			name:          "control plane ElasticIP",
+			ipReservation: &metal.IPReservation{Tags: []string{"foo", generateElasticIPIdentifier("capi")}},
+			want:          "capi",
+			wantOK:        true,
+		},
+		{
+			name:          "service IP pool",
+			ipReservation: &metal.IPReservation{Tags: []string{generateServiceIPPoolIdentifier("default", "capi")}},
+		},
+		{
+			name:          "empty cluster name",
+			ipReservation: &metal.IPReservation{Tags: []string{elasticIPIdentifierPrefix}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, ok := ElasticIPClusterName(tt.ipReservation)
+			g.Expect(ok).To(Equal(tt.wantOK))
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestDeviceHardware(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(DeviceHardware(&metal.Device{})).To(BeNil())
+
+	dev := &metal.Device{
+		Plan: &metal.Plan{
+			Specs: &metal.PlanSpecs{
+				Cpus:   []metal.PlanSpecsCpusInner{{Count: ptr.To[int32](1), Type: ptr.To("Intel Xeon E-2278G 8-Core Processor @ 3.40GHz")}},
+				Memory: &metal.PlanSpecsMemory{Total: ptr.To("32GB")},
+				Drives: []metal.PlanSpecsDrivesInner{{Count: ptr.To[int32](2), Type: ptr.To("SSD"), Size: ptr.To("480GB")}},
+				Nics:   []metal.PlanSpecsNicsInner{{Count: ptr.To[int32](2), Type: ptr.To("10Gbps")}},
+			},
+		},
+	}
+	g.Expect(DeviceHardware(dev)).To(Equal(&infrav1.HardwareStatus{
+		CPUs:   []infrav1.HardwareCPU{{Count: 1, Type: "Intel Xeon E-2278G 8-Core Processor @ 3.40GHz"}},
+		Memory: "32GB",
+		Drives: []infrav1.HardwareDrive{{Count: 2, Type: "SSD", Size: "480GB"}},
+		NICs:   []infrav1.HardwareNIC{{Count: 2, Type: "10Gbps"}},
+	}))
+}
+
+func TestDeviceHardwareReservationID(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(DeviceHardwareReservationID(&metal.Device{})).To(BeEmpty())
+	g.Expect(DeviceHardwareReservationID(&metal.Device{
+		HardwareReservation: &metal.HardwareReservation{Id: ptr.To("r1")},
+	})).To(Equal("r1"))
+	g.Expect(DeviceHardwareReservationID(&metal.Device{
+		HardwareReservation: &metal.HardwareReservation{Href: ptr.To("/metal/v1/hardware-reservations/r2")},
+	})).To(Equal("r2"))
+}
+
+func TestDeviceProvisioningProgress(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(DeviceProvisioningPercentage(&metal.Device{})).To(BeNil())
+	g.Expect(DeviceLastEvent(&metal.Device{})).To(BeNil())
+
+	first := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	dev := &metal.Device{
+		ProvisioningPercentage: ptr.To[float32](42.5),
+		ProvisioningEvents: []metal.Event{
+			{Type: ptr.To("provisioning.104"), Interpolated: ptr.To("Connected to magic install system"), CreatedAt: ptr.To(first.Add(time.Minute))},
+			{Type: ptr.To("provisioning.101"), Interpolated: ptr.To("Provision started"), CreatedAt: ptr.To(first)},
+		},
+	}
+	g.Expect(DeviceProvisioningPercentage(dev)).To(Equal(ptr.To[int32](42)))
+	g.Expect(DeviceLastEvent(dev)).To(Equal(&infrav1.DeviceEvent{
+		Type:    "provisioning.104",
+		Message: "Connected to magic install system",
+		Time:    metav1.NewTime(first.Add(time.Minute)),
+	}))
+}
+
+func TestDeviceIPAddresses(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(deviceIPAddresses(nil)).To(BeNil())
+
+	addresses := deviceIPAddresses([]infrav1.DeviceIPAddress{
+		{AddressFamily: 4, CIDR: ptr.To[int32](28)},
+		{AddressFamily: 6, Public: true, IPReservations: []string{"reservation"}},
+	})
+	g.Expect(addresses).To(Equal([]metal.IPAddress{
+		{AddressFamily: ptr.To(metal.IPADDRESSADDRESSFAMILY__4), Public: ptr.To(false), Cidr: ptr.To[int32](28)},
+		{AddressFamily: ptr.To(metal.IPADDRESSADDRESSFAMILY__6), Public: ptr.To(true), IpReservations: []string{"reservation"}},
+	}))
+}
+
+func TestDevicePublicIPv4Block(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(DevicePublicIPv4Block(&metal.Device{})).To(BeEmpty())
+
+	dev := &metal.Device{IpAddresses: []metal.IPAssignment{
+		{Public: ptr.To(false), AddressFamily: ptr.To[int32](4), Management: ptr.To(true), Network: ptr.To("10.0.0.0"), Cidr: ptr.To[int32](31)},
+		{Public: ptr.To(true), AddressFamily: ptr.To[int32](6), Management: ptr.To(true), Network: ptr.To("2604:1380::"), Cidr: ptr.To[int32](127)},
+		{Public: ptr.To(true), AddressFamily: ptr.To[int32](4), Management: ptr.To(false), Network: ptr.To("147.75.0.8"), Cidr: ptr.To[int32](32)},
+		{Public: ptr.To(true), AddressFamily: ptr.To[int32](4), Management: ptr.To(true), Network: ptr.To("147.75.1.0"), Cidr: ptr.To[int32](29)},
+	}}
+	g.Expect(DevicePublicIPv4Block(dev)).To(Equal("147.75.1.0/29"))
+}
+
+func TestDeviceSSHKeys(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(deviceSSHKeys(nil)).To(BeNil())
+	g.Expect(deviceSSHKeys([]string{""})).To(BeNil())
+	g.Expect(deviceSSHKeys([]string{"ssh-ed25519 AAAA user@host\n", " "})).To(Equal([]metal.SSHKeyInput{
+		{Key: ptr.To("ssh-ed25519 AAAA user@host")},
+	}))
+}
+
+func TestDeviceCustomData(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(deviceCustomData(nil)).To(BeNil())
+	g.Expect(deviceCustomData(&infrav1.CustomImage{URL: "https://github.com/example/images.git", Tag: "0123abc"})).To(Equal(map[string]interface{}{
+		"image_repo": "https://github.com/example/images.git",
+		"image_tag":  "0123abc",
+	}))
+}
+
+func TestDeviceSpotMarket(t *testing.T) {
+	g := NewWithT(t)
+
+	spot, price := deviceSpotMarket("")
+	g.Expect(spot).To(BeNil())
+	g.Expect(price).To(BeNil())
+
+	spot, price = deviceSpotMarket("0.25")
+	g.Expect(spot).To(Equal(ptr.To(true)))
+	g.Expect(price).To(Equal(ptr.To[float32](0.25)))
+}
+
+func TestPlatform(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(PlanArchitecture("c3.large.arm64")).To(Equal("arm64"))
+	g.Expect(PlanArchitecture("c2.large.arm")).To(Equal("arm64"))
+	g.Expect(PlanArchitecture("m3.large.x86")).To(Equal("amd64"))
+	g.Expect(PlanArchitecture("")).To(Equal("amd64"))
+
+	g.Expect(OSFamily("ubuntu_22_04")).To(Equal("linux"))
+	g.Expect(OSFamily("custom_ipxe")).To(Equal("linux"))
+	g.Expect(OSFamily("windows_2022")).To(Equal("windows"))
+}
+
+func TestGetDevicesByTags(t *testing.T) {
+	g := NewWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"devices": [
+			{"id": "newer", "created_at": "2024-05-02T00:00:00Z", "tags": ["machine", "cluster"]},
+			{"id": "other", "created_at": "2024-05-01T00:00:00Z", "tags": ["cluster"]},
+			{"id": "older", "created_at": "2024-05-01T00:00:00Z", "tags": ["cluster", "machine"]}
+		]}`))
+	}))
+	defer server.Close()
+
+	configuration := metal.NewConfiguration()
+	configuration.Servers = metal.ServerConfigurations{{URL: server.URL}}
+	client := &Client{APIClient: metal.NewAPIClient(configuration)}
+
+	devices, err := client.GetDevicesByTags(context.Background(), "project", []string{"machine", "cluster"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(devices).To(HaveLen(2))
+	g.Expect(devices[0].GetId()).To(Equal("older"))
+	g.Expect(devices[1].GetId()).To(Equal("newer"))
+
+	device, err := client.GetDeviceByTags(context.Background(), "project", []string{"machine", "cluster"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(device.GetId()).To(Equal("older"))
+}
+
+func TestAdoptIPReservation(t *testing.T) {
+	var patches []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPatch {
+			body := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			patches = append(patches, body)
+			_, _ = w.Write([]byte(`{"id": "anycast", "address": "147.75.0.1", "type": "global_ipv4"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ip_addresses": [
+			{"id": "anycast", "address": "147.75.0.1", "type": "global_ipv4", "tags": ["user"]},
+			{"id": "adopted", "address": "147.75.0.2", "type": "public_ipv4", "tags": ["cluster-api-provider-packet:cluster-id:capi"]},
+			{"id": "taken", "address": "147.75.0.3", "type": "public_ipv4", "tags": ["cluster-api-provider-packet:cluster-id:other"]}
+		]}`))
+	}))
+	defer server.Close()
+
+	configuration := metal.NewConfiguration()
+	configuration.Servers = metal.ServerConfigurations{{URL: server.URL}}
+	client := &Client{APIClient: metal.NewAPIClient(configuration)}
+
+	t.Run("tags the reservation for the cluster", func(t *testing.T) {
+		g := NewWithT(t)
+		patches = nil
+
+		ipReservation, err := client.AdoptIPReservation(context.Background(), "anycast", "capi", "project")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ipReservation.GetAddress()).To(Equal("147.75.0.1"))
+		g.Expect(patches).To(ConsistOf(HaveKeyWithValue("tags", ConsistOf("user", generateElasticIPIdentifier("capi")))))
+	})
+
+	t.Run("uses a reservation already tagged for the cluster", func(t *testing.T) {
+		g := NewWithT(t)
+		patches = nil
+
+		ipReservation, err := client.AdoptIPReservation(context.Background(), "adopted", "capi", "project")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ipReservation.GetAddress()).To(Equal("147.75.0.2"))
+		g.Expect(patches).To(BeEmpty())
+	})
+
+	t.Run("refuses a reservation of another cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := client.AdoptIPReservation(context.Background(), "taken", "capi", "project")
+		g.Expect(err).To(MatchError(ErrIPReservationInUse))
+	})
+
+	t.Run("reports a missing reservation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := client.AdoptIPReservation(context.Background(), "missing", "capi", "project")
+		g.Expect(err).To(MatchError(ErrIPReservationNotFound))
+	})
+
+	t.Run("untags the reservation", func(t *testing.T) {
+		g := NewWithT(t)
+		patches = nil
+
+		g.Expect(client.ReleaseIPReservation(context.Background(), "adopted", "capi", "project")).To(Succeed())
+		g.Expect(patches).To(ConsistOf(HaveKeyWithValue("tags", BeEmpty())))
+
+		patches = nil
+		g.Expect(client.ReleaseIPReservation(context.Background(), "anycast", "capi", "project")).To(Succeed())
+		g.Expect(client.ReleaseIPReservation(context.Background(), "missing", "capi", "project")).To(Succeed())
+		g.Expect(patches).To(BeEmpty())
+	})
+}
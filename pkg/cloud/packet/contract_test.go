@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+// The tests of this file pin the shapes of the Equinix Metal API requests the provider makes, and of the API
+// responses it relies on, independently of the SDK types. An SDK upgrade that renames a field, changes its JSON
+// name or stops sending it fails them, rather than silently changing what the provider does.
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+// contractDevice is a device as returned by the API, trimmed to the fields the provider reads.
+const contractDevice = `{
+	"id": "5f1e6d3c-2a4b-4c8d-9e0f-1a2b3c4d5e6f",
+	"hostname": "test-machine",
+	"state": "active",
+	"created_at": "2024-05-01T10:00:00Z",
+	"tags": ["cluster-api-provider-packet:cluster-id:test-cluster"],
+	"metro": {"code": "da"},
+	"facility": {"code": "da11"},
+	"plan": {
+		"slug": "c3.small.x86",
+		"specs": {
+			"cpus": [{"count": 1, "type": "Intel Xeon E-2278G"}],
+			"memory": {"total": "32GB"},
+			"drives": [{"count": 2, "size": "480GB", "type": "SSD", "category": "boot"}],
+			"nics": [{"count": 2, "type": "10Gbps"}]
+		}
+	},
+	"ip_addresses": [
+		{"address": "147.75.0.10", "address_family": 4, "public": true},
+		{"address": "10.70.0.2", "address_family": 4, "public": false}
+	],
+	"network_ports": [
+		{"id": "bond0", "name": "bond0", "type": "NetworkBondPort", "network_type": "layer3"},
+		{"id": "eth0", "name": "eth0", "type": "NetworkPort", "bond": {"name": "bond0"}, "data": {"bonded": true, "mac": "aa:bb:cc:dd:ee:ff"}}
+	]
+}`
+
+// newTestClient returns a client of the Equinix Metal API served by handler, until the test ends. The tests of other
+// packages use packettest.NewClient instead.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient("token")
+	client.GetConfig().Servers = metal.ServerConfigurations{{URL: server.URL}}
+	return client
+}
+
+// contractServer serves contractDevice on every request, recording the method, path and JSON body of the requests.
+func contractServer(t *testing.T) (*Client, *[]contractRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []contractRequest
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := contractRequest{Method: r.Method, Path: r.URL.Path}
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&request.Body); err != nil {
+				t.Errorf("failed to decode request body: %v", err)
+			}
+		}
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(contractDevice))
+	}))
+	return client, &requests
+}
+
+type contractRequest struct {
+	Method string
+	Path   string
+	Body   map[string]interface{}
+}
+
+func TestContractCreateDevice(t *testing.T) {
+	g := NewWithT(t)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bootstrap", Namespace: scopetest.Namespace},
+		Data:       map[string][]byte{"value": []byte("#cloud-config\n")},
+	}
+	packetMachine := &infrav1.PacketMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: scopetest.MachineName, Namespace: scopetest.Namespace},
+		Spec: infrav1.PacketMachineSpec{
+			OS:           "custom_ipxe",
+			IPXEUrl:      "https://boot.example.com/ipxe",
+			AlwaysPXE:    true,
+			BillingCycle: "hourly",
+			MachineType:  "c3.small.x86",
+			Metro:        "da",
+			Tags:         infrav1.Tags{"custom"},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: scopetest.MachineName, Namespace: scopetest.Namespace},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: scopetest.ClusterName,
+			Bootstrap:   clusterv1.Bootstrap{DataSecretName: ptr.To("bootstrap")},
+		},
+	}
+	packetCluster := &infrav1.PacketCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: scopetest.ClusterName, Namespace: scopetest.Namespace},
+		Spec:       infrav1.PacketClusterSpec{ProjectID: "project"},
+	}
+	machineScope, _, err := scopetest.NewMachineScopeBuilder().
+		WithMachine(machine).
+		WithPacketMachine(packetMachine).
+		WithPacketCluster(packetCluster).
+		WithObjects(secret).
+		Build()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	client, requests := contractServer(t)
+	_, err = client.NewDevice(context.Background(), CreateDeviceRequest{MachineScope: machineScope, ExtraTags: []string{"extra"}})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(*requests).To(HaveLen(1))
+	request := (*requests)[0]
+	g.Expect(request.Method + " " + request.Path).To(Equal("POST /projects/project/devices"))
+	g.Expect(request.Body).To(HaveKeyWithValue("hostname", "test-machine"))
+	g.Expect(request.Body).To(HaveKeyWithValue("metro", "da"))
+	g.Expect(request.Body).To(HaveKeyWithValue("plan", "c3.small.x86"))
+	g.Expect(request.Body).To(HaveKeyWithValue("operating_system", "custom_ipxe"))
+	g.Expect(request.Body).To(HaveKeyWithValue("billing_cycle", "hourly"))
+	g.Expect(request.Body).To(HaveKeyWithValue("ipxe_script_url", "https://boot.example.com/ipxe"))
+	g.Expect(request.Body).To(HaveKeyWithValue("always_pxe", true))
+	g.Expect(request.Body).To(HaveKeyWithValue("userdata", "#cloud-config\n"))
+	g.Expect(request.Body).To(HaveKeyWithValue("tags", ContainElements("extra", infrav1.WorkerTag)))
+	g.Expect(request.Body).ToNot(HaveKey("facility"))
+}
+
+func TestContractDevice(t *testing.T) {
+	g := NewWithT(t)
+
+	client, requests := contractServer(t)
+	dev, _, err := client.GetDevice(context.Background(), "5f1e6d3c-2a4b-4c8d-9e0f-1a2b3c4d5e6f") //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect((*requests)[0].Method + " " + (*requests)[0].Path).To(Equal("GET /devices/5f1e6d3c-2a4b-4c8d-9e0f-1a2b3c4d5e6f"))
+
+	g.Expect(dev.GetState()).To(Equal(metal.DEVICESTATE_ACTIVE))
+	g.Expect(dev.GetHostname()).To(Equal("test-machine"))
+	g.Expect(dev.CreatedAt).ToNot(BeNil())
+	g.Expect(dev.GetMetro().Code).To(Equal(ptr.To("da")))
+	g.Expect(client.GetDeviceAddresses(dev)).To(Equal([]corev1.NodeAddress{
+		{Type: corev1.NodeExternalIP, Address: "147.75.0.10"},
+		{Type: corev1.NodeInternalIP, Address: "10.70.0.2"},
+	}))
+	g.Expect(DeviceHardware(dev)).To(Equal(&infrav1.HardwareStatus{
+		CPUs:   []infrav1.HardwareCPU{{Count: 1, Type: "Intel Xeon E-2278G"}},
+		Memory: "32GB",
+		Drives: []infrav1.HardwareDrive{{Count: 2, Size: "480GB", Type: "SSD", Category: "boot"}},
+		NICs:   []infrav1.HardwareNIC{{Count: 2, Type: "10Gbps"}},
+	}))
+
+	g.Expect(dev.NetworkPorts).To(HaveLen(2))
+	g.Expect(dev.NetworkPorts[0].GetType()).To(Equal(metal.PORTTYPE_NETWORK_BOND_PORT))
+	g.Expect(dev.NetworkPorts[0].GetNetworkType()).To(Equal(metal.PORTNETWORKTYPE_LAYER3))
+	g.Expect(dev.NetworkPorts[1].Bond.GetName()).To(Equal("bond0"))
+	g.Expect(dev.NetworkPorts[1].GetData().Bonded).To(Equal(ptr.To(true)))
+}
+
+func TestContractIPRequests(t *testing.T) {
+	g := NewWithT(t)
+
+	client, requests := contractServer(t)
+	g.Expect(client.AssignIP(context.Background(), "device", "147.75.0.20")).To(Succeed())
+	g.Expect((*requests)[0].Method + " " + (*requests)[0].Path).To(Equal("POST /devices/device/ips"))
+	g.Expect((*requests)[0].Body).To(Equal(map[string]interface{}{"address": "147.75.0.20"}))
+
+	// The device served by the contract server is not an IP reservation, only the request is checked.
+	_, _ = client.CreateIP(context.Background(), "", "test-cluster", "project", "", "da")
+	request := (*requests)[1]
+	g.Expect(request.Method + " " + request.Path).To(Equal("POST /projects/project/ips"))
+	g.Expect(request.Body).To(HaveKeyWithValue("type", "public_ipv4"))
+	g.Expect(request.Body).To(HaveKeyWithValue("quantity", BeNumerically("==", 1)))
+	g.Expect(request.Body).To(HaveKeyWithValue("metro", "da"))
+	g.Expect(request.Body).To(HaveKeyWithValue("fail_on_approval_required", true))
+	g.Expect(request.Body).To(HaveKeyWithValue("tags", ConsistOf(generateElasticIPIdentifier("test-cluster"))))
+}
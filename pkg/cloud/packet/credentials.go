@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// ErrCredentialsMissingKey is returned when the credentials Secret of a cluster lacks the key it is referenced with.
+var ErrCredentialsMissingKey = errors.New("error retrieving credentials: secret key is missing")
+
+// WithAPIKey returns a client making its calls with another API key, e.g. the one of a cluster managed in another
+// Equinix account. It shares the transport of p, and so its API call budget, read-only mode and accounting, and
+// batches device creations like p does.
+func (p *Client) WithAPIKey(apiKey string) *Client {
+	token := strings.TrimSpace(apiKey)
+	if token == "" || token == p.GetConfig().DefaultHeader["X-Auth-Token"] {
+		return p
+	}
+
+	configuration := *p.GetConfig()
+	configuration.DefaultHeader = make(map[string]string, len(p.GetConfig().DefaultHeader))
+	for header, value := range p.GetConfig().DefaultHeader {
+		configuration.DefaultHeader[header] = value
+	}
+	configuration.AddDefaultHeader("X-Auth-Token", token)
+
+	derived := &Client{APIClient: metal.NewAPIClient(&configuration), budget: p.budget}
+	if p.batcher != nil {
+		derived.SetDeviceBatching(p.batcher.window, p.batcher.maxSize)
+	}
+	return derived
+}
+
+// ClientForCluster returns the client managing the resources of a PacketCluster: one with the API key of its
+// credentialsRef, read from a Secret in its namespace, or p when it has none. The clients are cached by Secret, for
+// device batching to work across reconciles, and replaced when the API key of their Secret changes.
+func (p *Client) ClientForCluster(ctx context.Context, c client.Client, packetCluster *infrav1.PacketCluster) (*Client, error) {
+	ref := packetCluster.Spec.CredentialsRef
+	if ref == nil {
+		return p, nil
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: packetCluster.Namespace, Name: ref.Name}
+	if err := c.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to retrieve credentials secret for PacketCluster %s/%s: %w", packetCluster.Namespace, packetCluster.Name, err)
+	}
+
+	apiKey := strings.TrimSpace(string(secret.Data[ref.Key]))
+	if apiKey == "" {
+		return nil, fmt.Errorf("%w: %s", ErrCredentialsMissingKey, ref.Key)
+	}
+	return p.clientForSecret(key.String()+"/"+ref.Key, apiKey), nil
+}
+
+// clientForSecret returns the cached client of the API key held by a Secret key, replacing it when the API key
+// changed.
+func (p *Client) clientForSecret(secretKey, apiKey string) *Client {
+	p.derivedMu.Lock()
+	defer p.derivedMu.Unlock()
+
+	if derived, ok := p.derived[secretKey]; ok && derived.GetConfig().DefaultHeader["X-Auth-Token"] == apiKey {
+		return derived
+	}
+
+	derived := p.WithAPIKey(apiKey)
+	if derived == p {
+		delete(p.derived, secretKey)
+		return p
+	}
+	if p.derived == nil {
+		p.derived = map[string]*Client{}
+	}
+	p.derived[secretKey] = derived
+	return derived
+}
+
+type clientKey struct{}
+
+// WithClient returns a context whose Equinix Metal API calls are made with the given client, usually the one of the
+// cluster being reconciled.
+func WithClient(ctx context.Context, c *Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// ClientFromContext returns the client of the context, or fallback when it has none.
+func ClientFromContext(ctx context.Context, fallback *Client) *Client {
+	if c, ok := ctx.Value(clientKey{}).(*Client); ok && c != nil {
+		return c
+	}
+	return fallback
+}
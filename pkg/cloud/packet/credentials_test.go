@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestWithAPIKey(t *testing.T) {
+	g := NewWithT(t)
+
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("X-Auth-Token"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "device"}`))
+	}))
+	defer server.Close()
+
+	manager := NewClient("manager")
+	manager.GetConfig().Servers = metal.ServerConfigurations{{URL: server.URL}}
+
+	g.Expect(manager.WithAPIKey("")).To(BeIdenticalTo(manager))
+	g.Expect(manager.WithAPIKey("manager")).To(BeIdenticalTo(manager))
+
+	tenant := manager.WithAPIKey(" tenant\n")
+	g.Expect(tenant).ToNot(BeIdenticalTo(manager))
+
+	_, _, err := tenant.GetDevice(context.Background(), "device") //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	g.Expect(err).ToNot(HaveOccurred())
+	_, _, err = manager.GetDevice(context.Background(), "device") //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	g.Expect(err).ToNot(HaveOccurred())
+	// The manager client keeps its own API key.
+	g.Expect(tokens).To(Equal([]string{"tenant", "manager"}))
+}
+
+func TestClientForCluster(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "default"},
+		Data:       map[string][]byte{"apiKey": []byte("tenant")},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+	manager := NewClient("manager")
+
+	packetCluster := &infrav1.PacketCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"}}
+	metalClient, err := manager.ClientForCluster(ctx, c, packetCluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(metalClient).To(BeIdenticalTo(manager))
+
+	packetCluster.Spec.CredentialsRef = &infrav1.SecretKeyReference{Name: "credentials", Key: "apiKey"}
+	metalClient, err = manager.ClientForCluster(ctx, c, packetCluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(metalClient.GetConfig().DefaultHeader["X-Auth-Token"]).To(Equal("tenant"))
+
+	// The client is cached by Secret until its API key is rotated.
+	cached, err := manager.ClientForCluster(ctx, c, packetCluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cached).To(BeIdenticalTo(metalClient))
+	secret.Data["apiKey"] = []byte("rotated")
+	g.Expect(c.Update(ctx, secret)).To(Succeed())
+	rotated, err := manager.ClientForCluster(ctx, c, packetCluster)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(rotated.GetConfig().DefaultHeader["X-Auth-Token"]).To(Equal("rotated"))
+	g.Expect(manager.derived).To(HaveLen(1))
+
+	packetCluster.Spec.CredentialsRef.Key = "missing"
+	_, err = manager.ClientForCluster(ctx, c, packetCluster)
+	g.Expect(err).To(MatchError(ErrCredentialsMissingKey))
+
+	// The client of the context wins over the fallback.
+	g.Expect(ClientFromContext(ctx, manager)).To(BeIdenticalTo(manager))
+	g.Expect(ClientFromContext(WithClient(ctx, metalClient), manager)).To(BeIdenticalTo(metalClient))
+}
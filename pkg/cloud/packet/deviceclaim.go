@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+)
+
+var (
+	// ErrDeviceInUse is returned when claiming a device that is already claimed or managed by a machine or a machine
+	// pool.
+	ErrDeviceInUse = errors.New("device is in use")
+	// ErrDeviceNotClaimed is returned when assigning a device that is not bound to the expected PacketDeviceClaim.
+	ErrDeviceNotClaimed = errors.New("device is not bound to the claim")
+)
+
+// DeviceClaimFromTags returns the namespaced name of the PacketDeviceClaim a device is bound to, from the tags of the
+// device.
+func DeviceClaimFromTags(tags []string) (string, bool) {
+	for _, tag := range tags {
+		if name, ok := strings.CutPrefix(tag, claimTag+":"); ok && name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// CheckClaimable returns an error when the device cannot be bound to a PacketDeviceClaim: it is already claimed,
+// managed by a machine or a machine pool, released for adoption, or not active.
+func CheckClaimable(dev *metal.Device) error {
+	if _, ok := DeviceClaimFromTags(dev.Tags); ok {
+		return fmt.Errorf("%w: %s is bound to another claim", ErrDeviceInUse, dev.GetId())
+	}
+	for _, tag := range dev.Tags {
+		if strings.HasPrefix(tag, machineUIDTag+":") || strings.HasPrefix(tag, poolTag+":") || tag == releasedTag {
+			return fmt.Errorf("%w: %s is managed by the tag %s", ErrDeviceInUse, dev.GetId(), tag)
+		}
+	}
+	if state := dev.GetState(); state != metal.DEVICESTATE_ACTIVE {
+		return fmt.Errorf("%w: %s is %s", ErrDeviceInUse, dev.GetId(), state)
+	}
+	return nil
+}
+
+// ClaimDevice binds a device to a PacketDeviceClaim by tagging it with the claim tag.
+func (p *Client) ClaimDevice(ctx context.Context, dev *metal.Device, namespace, name string) error {
+	if err := CheckClaimable(dev); err != nil {
+		return err
+	}
+	return p.updateDeviceTags(ctx, dev, append(append([]string{}, dev.Tags...), GenerateDeviceClaimTag(namespace, name)))
+}
+
+// AssignClaimedDevice assigns a device bound to a PacketDeviceClaim to a PacketMachine by adding the tags the device
+// would have been created with for the machine. The claim tag is kept, so that the device goes back to the claim when
+// the machine is deleted.
+func (p *Client) AssignClaimedDevice(ctx context.Context, dev *metal.Device, claimNamespace, claimName, name, clusterName string) error {
+	if !ItemsInList(dev.Tags, []string{GenerateDeviceClaimTag(claimNamespace, claimName)}) {
+		return fmt.Errorf("%w: %s is not bound to %s/%s", ErrDeviceNotClaimed, dev.GetId(), claimNamespace, claimName)
+	}
+	if machine, ok := MachineNameFromTags(dev.Tags); ok && machine != name {
+		return fmt.Errorf("%w: %s is used by machine %s", ErrDeviceInUse, dev.GetId(), machine)
+	}
+	return p.retagDevice(ctx, dev, DefaultCreateTags(claimNamespace, name, clusterName))
+}
+
+// UnclaimDevice unbinds a device from its PacketDeviceClaim, removing the claim tag and the tags added for the machines
+// it was assigned to. The other tags, e.g. the tags of the pool the device was claimed from, are kept.
+func (p *Client) UnclaimDevice(ctx context.Context, dev *metal.Device) error {
+	tags := make([]string, 0, len(dev.Tags))
+	for _, tag := range dev.Tags {
+		switch {
+		case strings.HasPrefix(tag, claimTag+":"),
+			strings.HasPrefix(tag, machineUIDTag+":"),
+			strings.HasPrefix(tag, clusterIDTag+":"),
+			strings.HasPrefix(tag, namespaceTag+":"):
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return p.updateDeviceTags(ctx, dev, tags)
+}
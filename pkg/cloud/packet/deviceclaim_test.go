@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+)
+
+func TestCheckClaimable(t *testing.T) {
+	tests := []struct {
+		name  string
+		state metal.DeviceState
+		tags  []string
+		ok    bool
+	}{
+		{name: "active", state: metal.DEVICESTATE_ACTIVE, tags: []string{"pool"}, ok: true},
+		{name: "provisioning", state: metal.DEVICESTATE_PROVISIONING, tags: []string{"pool"}},
+		{name: "claimed", state: metal.DEVICESTATE_ACTIVE, tags: []string{GenerateDeviceClaimTag("default", "claim")}},
+		{name: "machine", state: metal.DEVICESTATE_ACTIVE, tags: DefaultCreateTags("default", "machine", "cluster")},
+		{name: "machine pool", state: metal.DEVICESTATE_ACTIVE, tags: MachinePoolCreateTags("default", "pool", "cluster")},
+		{name: "released", state: metal.DEVICESTATE_ACTIVE, tags: []string{releasedTag}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := CheckClaimable(&metal.Device{Id: ptr.To("device"), State: ptr.To(tt.state), Tags: tt.tags})
+			if tt.ok {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(ErrDeviceInUse))
+			}
+		})
+	}
+}
+
+func TestDeviceClaimFromTags(t *testing.T) {
+	g := NewWithT(t)
+
+	name, ok := DeviceClaimFromTags([]string{"pool", GenerateDeviceClaimTag("default", "claim")})
+	g.Expect(ok).To(BeTrue())
+	g.Expect(name).To(Equal("default/claim"))
+
+	_, ok = DeviceClaimFromTags([]string{"pool"})
+	g.Expect(ok).To(BeFalse())
+}
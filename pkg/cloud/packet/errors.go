@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+)
+
+// APIError is an error response of the Equinix Metal API, with the status code and the messages of its body, so that
+// callers can tell failures apart without parsing the error strings of the SDK.
+type APIError struct {
+	StatusCode int
+	Messages   []string
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// newAPIError wraps the error of an Equinix Metal API call in an APIError when the API responded, and returns it as
+// is otherwise.
+func newAPIError(resp *http.Response, err error) error {
+	if err == nil || resp == nil {
+		return err
+	}
+	apiErr := &APIError{StatusCode: resp.StatusCode, Err: err}
+	var openAPIErr *metal.GenericOpenAPIError
+	if errors.As(err, &openAPIErr) {
+		var body metal.Error
+		if json.Unmarshal(openAPIErr.Body(), &body) == nil {
+			apiErr.Messages = body.GetErrors()
+			if body.GetError() != "" {
+				apiErr.Messages = append(apiErr.Messages, body.GetError())
+			}
+		}
+	}
+	return apiErr
+}
+
+// hasMessage reports whether one of the messages of an API error response contains one of the substrings, ignoring
+// case.
+func (e *APIError) hasMessage(substrings ...string) bool {
+	for _, message := range e.Messages {
+		message = strings.ToLower(message)
+		for _, s := range substrings {
+			if strings.Contains(message, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsReservationBusy reports whether a device creation failed because its hardware reservations are not available
+// yet, e.g. because their previous devices are still deprovisioning.
+func IsReservationBusy(err error) bool {
+	var unavailable *ReservationsUnavailableError
+	if errors.As(err, &unavailable) || errors.Is(err, ErrReservationPoolExhausted) || errors.Is(err, ErrNoMatchingReservations) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity &&
+		apiErr.hasMessage("no available hardware reservations", "not provisionable")
+}
+
+// IsQuotaExceeded reports whether a request failed because the project or organization reached one of its limits,
+// which only a change of the limits or the removal of resources resolves.
+func IsQuotaExceeded(err error) bool {
+	if errors.Is(err, ErrElasticIPQuotaExceeded) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) &&
+		(apiErr.StatusCode == http.StatusUnprocessableEntity || apiErr.StatusCode == http.StatusForbidden) &&
+		apiErr.hasMessage("quota", "limit reached", "maximum number of")
+}
+
+// IsLocationUnavailable reports whether a request failed because its facility or metro is no longer available, e.g.
+// because Equinix Metal retired the facility, which only moving to another location resolves.
+func IsLocationUnavailable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) &&
+		(apiErr.StatusCode == http.StatusUnprocessableEntity || apiErr.StatusCode == http.StatusNotFound) &&
+		apiErr.hasMessage("no longer available", "retired", "decommissioned", "not a valid facility", "not a valid metro",
+			"invalid facility", "invalid metro", "unknown facility", "unknown metro")
+}
+
+// IsRetryable reports whether a request failed for a reason that is expected to go away by itself, so that it is to
+// be retried rather than reported as a failure: busy hardware reservations, rate limiting, the API call budget of
+// the cluster, and server side or connection errors.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsReservationBusy(err) || errors.Is(err, ErrAPIBudgetExceeded) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	// The API did not respond, the SDK reports connection errors by their message only.
+	return IsResponseLost(err) || strings.Contains(err.Error(), "connection reset") ||
+		strings.Contains(err.Error(), "connection refused") || strings.Contains(err.Error(), "timeout")
+}
+
+// IsResponseLost reports whether the connection to the API was lost before its response was read. The request may
+// have been processed, e.g. a device may be provisioning, so it is not to be sent again right away.
+func IsResponseLost(err error) bool {
+	var apiErr *APIError
+	return err != nil && !errors.As(err, &apiErr) && strings.Contains(err.Error(), "unexpected EOF")
+}
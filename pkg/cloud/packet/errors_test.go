@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		wantBusy     bool
+		wantQuota    bool
+		wantLocation bool
+		wantRetrying bool
+	}{
+		{
+			name:         "busy hardware reservation",
+			status:       http.StatusUnprocessableEntity,
+			body:         `{"errors": ["Oh snap, something went wrong! We've logged the error and will take a look - please reach out to us if you continue having trouble.", "Server is not provisionable"]}`,
+			wantBusy:     true,
+			wantRetrying: true,
+		},
+		{
+			name:         "no available hardware reservations",
+			status:       http.StatusUnprocessableEntity,
+			body:         `{"errors": ["There are no available hardware reservations for c3.small.x86 in da"]}`,
+			wantBusy:     true,
+			wantRetrying: true,
+		},
+		{
+			name:      "quota exceeded",
+			status:    http.StatusForbidden,
+			body:      `{"error": "You have reached the maximum number of devices for this project"}`,
+			wantQuota: true,
+		},
+		{
+			name:         "retired facility",
+			status:       http.StatusUnprocessableEntity,
+			body:         `{"errors": ["Facility ewr1 is no longer available, please use a metro instead"]}`,
+			wantLocation: true,
+		},
+		{
+			name:         "unknown metro",
+			status:       http.StatusNotFound,
+			body:         `{"errors": ["xx is not a valid metro"]}`,
+			wantLocation: true,
+		},
+		{
+			name:   "invalid request",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"errors": ["Operating system is not supported by the plan"]}`,
+		},
+		{
+			name:         "rate limited",
+			status:       http.StatusTooManyRequests,
+			body:         `{"errors": ["Too many requests"]}`,
+			wantRetrying: true,
+		},
+		{
+			name:         "server error",
+			status:       http.StatusServiceUnavailable,
+			body:         `not json`,
+			wantRetrying: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			configuration := metal.NewConfiguration()
+			configuration.Servers = metal.ServerConfigurations{{URL: server.URL}}
+			client := metal.NewAPIClient(configuration)
+
+			_, resp, err := client.DevicesApi.FindDeviceById(context.Background(), "device").Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+			err = fmt.Errorf("failed to create device: %w", newAPIError(resp, err))
+
+			var apiErr *APIError
+			g.Expect(errors.As(err, &apiErr)).To(BeTrue())
+			g.Expect(apiErr.StatusCode).To(Equal(tt.status))
+			g.Expect(IsReservationBusy(err)).To(Equal(tt.wantBusy))
+			g.Expect(IsQuotaExceeded(err)).To(Equal(tt.wantQuota))
+			g.Expect(IsLocationUnavailable(err)).To(Equal(tt.wantLocation))
+			g.Expect(IsRetryable(err)).To(Equal(tt.wantRetrying))
+			g.Expect(IsResponseLost(err)).To(BeFalse())
+		})
+	}
+}
+
+func TestErrorClassificationWithoutResponse(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(IsRetryable(nil)).To(BeFalse())
+	g.Expect(IsRetryable(fmt.Errorf("%w test", ErrAPIBudgetExceeded))).To(BeTrue())
+	g.Expect(IsReservationBusy(&ReservationsUnavailableError{Err: ErrReservationPoolExhausted})).To(BeTrue())
+	g.Expect(IsQuotaExceeded(ErrElasticIPQuotaExceeded)).To(BeTrue())
+
+	lost := errors.New(`Post "https://api.equinix.com/metal/v1/projects/project/devices": unexpected EOF`)
+	g.Expect(IsResponseLost(lost)).To(BeTrue())
+	g.Expect(IsRetryable(lost)).To(BeTrue())
+	g.Expect(newAPIError(nil, lost)).To(Equal(lost))
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+const (
+	// hardeningSysctlPath is the file the sysctls of the kernel hardening are written to.
+	hardeningSysctlPath = "/etc/sysctl.d/90-capp-hardening.conf"
+
+	// hardeningMergeHow makes cloud-init append the lists of the hardening cloud-config, e.g. its write_files, to the
+	// ones of the bootstrap data instead of replacing them.
+	hardeningMergeHow = "list(append)+dict(recurse_array)+str()"
+
+	// jinjaTemplateHeader is the first line of cloud-config that cloud-init renders as a Jinja template, as written by
+	// the kubeadm bootstrap provider.
+	jinjaTemplateHeader = "## template: jinja"
+)
+
+// hardeningSysctls restrict access to kernel pointers and logs and ignore ICMP redirects. IP forwarding and reverse
+// path filtering are left alone, as Kubernetes networking depends on them.
+var hardeningSysctls = []string{
+	"kernel.dmesg_restrict = 1",
+	"kernel.kptr_restrict = 2",
+	"net.ipv4.conf.all.accept_redirects = 0",
+	"net.ipv4.conf.default.accept_redirects = 0",
+	"net.ipv4.conf.all.send_redirects = 0",
+	"net.ipv6.conf.all.accept_redirects = 0",
+	"net.ipv6.conf.default.accept_redirects = 0",
+}
+
+// hardeningCloudConfig returns the cloud-config applying an OS hardening, or "" if it hardens nothing.
+func hardeningCloudConfig(hardening *infrav1.OSHardening) (string, error) {
+	if hardening == nil {
+		return "", nil
+	}
+
+	config := map[string]interface{}{}
+	if hardening.DisableSSHPasswordAuth {
+		config["ssh_pwauth"] = false
+	}
+	if hardening.KernelHardening {
+		config["write_files"] = []map[string]interface{}{{
+			"path":        hardeningSysctlPath,
+			"owner":       "root:root",
+			"permissions": "0644",
+			"content":     strings.Join(hardeningSysctls, "\n") + "\n",
+		}}
+		config["runcmd"] = [][]string{{"sysctl", "-p", hardeningSysctlPath}}
+	}
+	if len(config) == 0 {
+		return "", nil
+	}
+	config["merge_how"] = hardeningMergeHow
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the os hardening cloud-config: %w", err)
+	}
+	return "#cloud-config\n" + string(out), nil
+}
+
+// hardenUserData merges the cloud-config applying the OS hardening of a machine into its bootstrap data, as a MIME
+// multi-part archive whose parts cloud-init merges in order. The bootstrap data is returned as it is when the
+// machine has no hardening.
+func hardenUserData(userData string, format scope.BootstrapFormat, hardening *infrav1.OSHardening) (string, error) {
+	fragment, err := hardeningCloudConfig(hardening)
+	if err != nil || fragment == "" {
+		return userData, err
+	}
+	if format != scope.BootstrapFormatCloudConfig {
+		return "", fmt.Errorf("osHardening requires cloud-config bootstrap data, not %s: %w", format, ErrInvalidRequest)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType, filename, content string
+	}{
+		{cloudConfigContentType(userData), "user-data", userData},
+		{"text/cloud-config", "os-hardening.cfg", fragment},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":        {part.contentType + `; charset="utf-8"`},
+			"Content-Disposition": {fmt.Sprintf("attachment; filename=%q", part.filename)},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to merge the os hardening into userdata: %w", err)
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return "", fmt.Errorf("failed to merge the os hardening into userdata: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("failed to merge the os hardening into userdata: %w", err)
+	}
+
+	header := fmt.Sprintf("Content-Type: multipart/mixed; boundary=%q\r\nMIME-Version: 1.0\r\n\r\n", mw.Boundary())
+	return header + body.String(), nil
+}
+
+// cloudConfigContentType returns the MIME type of cloud-config bootstrap data in a multi-part archive, so that
+// cloud-init still renders the Jinja templates of the kubeadm bootstrap provider.
+func cloudConfigContentType(userData string) string {
+	if strings.HasPrefix(userData, jinjaTemplateHeader) {
+		return "text/jinja2"
+	}
+	return "text/cloud-config"
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/yaml"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+func TestHardeningCloudConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(hardeningCloudConfig(nil)).To(BeEmpty())
+	g.Expect(hardeningCloudConfig(&infrav1.OSHardening{})).To(BeEmpty())
+
+	fragment, err := hardeningCloudConfig(&infrav1.OSHardening{DisableSSHPasswordAuth: true, KernelHardening: true})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(fragment).To(HavePrefix("#cloud-config\n"))
+
+	config := map[string]interface{}{}
+	g.Expect(yaml.Unmarshal([]byte(fragment), &config)).To(Succeed())
+	g.Expect(config).To(HaveKeyWithValue("ssh_pwauth", false))
+	g.Expect(config).To(HaveKeyWithValue("merge_how", hardeningMergeHow))
+	g.Expect(config["write_files"]).To(ConsistOf(HaveKeyWithValue("path", hardeningSysctlPath)))
+	g.Expect(config["runcmd"]).To(ConsistOf(ConsistOf("sysctl", "-p", hardeningSysctlPath)))
+}
+
+func TestHardenUserData(t *testing.T) {
+	g := NewWithT(t)
+
+	bootstrap := "## template: jinja\n#cloud-config\nruncmd:\n- kubeadm init\n"
+	hardening := &infrav1.OSHardening{DisableSSHPasswordAuth: true}
+
+	// Without hardening, the bootstrap data is left alone.
+	g.Expect(hardenUserData(bootstrap, scope.BootstrapFormatCloudConfig, nil)).To(Equal(bootstrap))
+
+	// Only cloud-config can be hardened.
+	_, err := hardenUserData(`{"ignition": {}}`, scope.BootstrapFormatIgnition, hardening)
+	g.Expect(errors.Is(err, ErrInvalidRequest)).To(BeTrue())
+
+	// The hardening is appended to the bootstrap data, which is still rendered as a Jinja template.
+	hardened, err := hardenUserData(bootstrap, scope.BootstrapFormatCloudConfig, hardening)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	msg, err := mail.ReadMessage(strings.NewReader(hardened))
+	g.Expect(err).ToNot(HaveOccurred())
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(mediaType).To(Equal("multipart/mixed"))
+
+	type part struct{ contentType, content string }
+	var parts []part
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		g.Expect(err).ToNot(HaveOccurred())
+		content, err := io.ReadAll(p)
+		g.Expect(err).ToNot(HaveOccurred())
+		contentType, _, err := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		g.Expect(err).ToNot(HaveOccurred())
+		parts = append(parts, part{contentType, string(content)})
+	}
+	g.Expect(parts).To(HaveLen(2))
+	g.Expect(parts[0]).To(Equal(part{"text/jinja2", bootstrap}))
+	g.Expect(parts[1].contentType).To(Equal("text/cloud-config"))
+	g.Expect(parts[1].content).To(ContainSubstring("ssh_pwauth: false"))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"strings"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+)
+
+// Tags of the devices created by the packngo based releases of the provider. They have no namespace tag, and the
+// machine tag holds either the name or the UID of the Machine.
+const (
+	legacyMachineUIDTag = "cluster-api-provider-packet:machine-uid"
+	legacyClusterIDTag  = "cluster-api-provider-packet:cluster-id"
+)
+
+// LegacyDeviceTags are the values of the legacy tags of a device.
+type LegacyDeviceTags struct {
+	ClusterName string
+	Machine     string
+}
+
+// GetLegacyDeviceTags returns the values of the legacy tags of the device, and whether it has any.
+func GetLegacyDeviceTags(dev *metal.Device) (LegacyDeviceTags, bool) {
+	var tags LegacyDeviceTags
+	found := false
+	for _, tag := range dev.Tags {
+		if value, ok := strings.CutPrefix(tag, legacyClusterIDTag+":"); ok {
+			tags.ClusterName = value
+			found = true
+		}
+		if value, ok := strings.CutPrefix(tag, legacyMachineUIDTag+":"); ok {
+			tags.Machine = value
+			found = true
+		}
+	}
+	return tags, found
+}
+
+// GetLegacyDevices returns the devices of the project that carry legacy tags, oldest first.
+func (p *Client) GetLegacyDevices(ctx context.Context, project string) ([]metal.Device, error) {
+	devices, err := p.GetDevicesByTags(ctx, project, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var legacy []metal.Device
+	for _, dev := range devices {
+		if _, ok := GetLegacyDeviceTags(&dev); ok {
+			legacy = append(legacy, dev)
+		}
+	}
+	return legacy, nil
+}
+
+// GetLegacyDevice returns the oldest device of a cluster created with legacy tags for the machine with the given name
+// or UID, or nil when there is none.
+func (p *Client) GetLegacyDevice(ctx context.Context, project, clusterName, name, uid string) (*metal.Device, error) {
+	devices, err := p.GetDevicesByTags(ctx, project, []string{legacyClusterIDTag + ":" + clusterName})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range devices {
+		tags, _ := GetLegacyDeviceTags(&devices[i])
+		if tags.Machine != "" && (tags.Machine == name || tags.Machine == uid) {
+			return &devices[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// MigrateDeviceTags replaces the legacy tags of a device with the tags it would have been created with for the
+// PacketMachine, so that it is found by them from then on.
+func (p *Client) MigrateDeviceTags(ctx context.Context, dev *metal.Device, namespace, name, clusterName string) error {
+	return p.retagDevice(ctx, dev, DefaultCreateTags(namespace, name, clusterName))
+}
+
+// isLegacyTag reports whether the tag is one of the legacy tags.
+func isLegacyTag(tag string) bool {
+	return strings.HasPrefix(tag, legacyClusterIDTag+":") || strings.HasPrefix(tag, legacyMachineUIDTag+":")
+}
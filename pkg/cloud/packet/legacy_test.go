@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+)
+
+func TestLegacyDevices(t *testing.T) {
+	g := NewWithT(t)
+
+	var updated []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			g.Expect(r.URL.Path).To(Equal("/projects/project/devices"))
+			_, _ = w.Write([]byte(`{"devices": [
+				{"id": "current", "tags": ["capp:cluster-id:cluster", "capp:machine-uid:machine-a"]},
+				{"id": "by-name", "tags": ["cluster-api-provider-packet:cluster-id:cluster", "cluster-api-provider-packet:machine-uid:machine-b"]},
+				{"id": "by-uid", "tags": ["cluster-api-provider-packet:cluster-id:cluster", "cluster-api-provider-packet:machine-uid:1234", "custom"]},
+				{"id": "other", "tags": ["cluster-api-provider-packet:cluster-id:other", "cluster-api-provider-packet:machine-uid:machine-b"]}
+			]}`))
+		case http.MethodPut:
+			g.Expect(r.URL.Path).To(Equal("/devices/by-uid"))
+			var input metal.DeviceUpdateInput
+			g.Expect(json.NewDecoder(r.Body).Decode(&input)).To(Succeed())
+			updated = input.Tags
+			_, _ = w.Write([]byte(`{"id": "by-uid"}`))
+		}
+	}))
+
+	legacy, err := client.GetLegacyDevices(context.Background(), "project")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(legacy).To(HaveLen(3))
+
+	tags, ok := GetLegacyDeviceTags(&legacy[0])
+	g.Expect(ok).To(BeTrue())
+	g.Expect(tags).To(Equal(LegacyDeviceTags{ClusterName: "cluster", Machine: "machine-b"}))
+
+	dev, err := client.GetLegacyDevice(context.Background(), "project", "cluster", "machine-b", "5678")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(dev.GetId()).To(Equal("by-name"))
+
+	dev, err = client.GetLegacyDevice(context.Background(), "project", "cluster", "machine-c", "1234")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(dev.GetId()).To(Equal("by-uid"))
+
+	dev, err = client.GetLegacyDevice(context.Background(), "project", "cluster", "machine-a", "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(dev).To(BeNil())
+
+	g.Expect(client.MigrateDeviceTags(context.Background(), &legacy[1], "default", "machine-c", "cluster")).To(Succeed())
+	g.Expect(updated).To(ConsistOf("custom", GenerateClusterTag("cluster"), GenerateMachineNameTag("machine-c"), GenerateNamespaceTag("default")))
+}
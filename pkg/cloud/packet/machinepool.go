@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"fmt"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	"k8s.io/utils/ptr"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// CreateMachinePoolDeviceRequest is an object representing the API request to create a device of a machine pool.
+type CreateMachinePoolDeviceRequest struct {
+	MachinePoolScope *scope.MachinePoolScope
+	Hostname         string
+}
+
+// NewMachinePoolDevice creates a new device for a machine pool. Unlike the devices of PacketMachines, the devices of
+// machine pools are always workers, and are neither batched nor created on hardware reservations.
+func (p *Client) NewMachinePoolDevice(ctx context.Context, req CreateMachinePoolDeviceRequest) (*metal.Device, error) {
+	poolScope := req.MachinePoolScope
+	spec := poolScope.PacketMachinePool.Spec.Template
+	packetClusterSpec := poolScope.PacketCluster.Spec
+	if spec.IPXEUrl != "" && spec.OS != ipxeOS {
+		return nil, fmt.Errorf("os should be set to custom_pxe when using pxe urls: %w", ErrInvalidRequest)
+	}
+	if spec.AlwaysPXE && spec.OS != ipxeOS {
+		return nil, fmt.Errorf("os should be set to custom_pxe when always booting from the network: %w", ErrInvalidRequest)
+	}
+
+	userDataRaw, err := poolScope.GetRawBootstrapData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve bootstrap data from secret: %w", err)
+	}
+	bootstrapFormat, err := poolScope.GetBootstrapDataFormat(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve bootstrap data format: %w", err)
+	}
+	userData, err := renderUserData(string(userDataRaw), bootstrapFormat, map[string]interface{}{
+		"kubernetesVersion": ptr.Deref(poolScope.MachinePool.Spec.Template.Spec.Version, ""),
+		"arch":              PlanArchitecture(spec.MachineType),
+		"os":                OSFamily(spec.OS),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if userData, err = fitUserData(userData, bootstrapFormat); err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, 0, len(spec.Tags)+4)
+	tags = append(tags, spec.Tags...)
+	tags = append(tags, MachinePoolCreateTags(poolScope.Namespace(), poolScope.Name(), poolScope.Cluster.Name)...)
+	tags = append(tags, infrav1.WorkerTag)
+
+	// If Metro or Facility are specified at the pool level, we ignore the values set at the Cluster level
+	facility := packetClusterSpec.Facility
+	metro := packetClusterSpec.Metro
+	if spec.Facility != "" || spec.Metro != "" {
+		metro = spec.Metro
+		facility = spec.Facility
+	}
+
+	var alwaysPXE *bool
+	if spec.AlwaysPXE {
+		alwaysPXE = ptr.To(true)
+	}
+
+	serverCreateOpts := metal.CreateDeviceRequest{}
+	if facility != "" {
+		serverCreateOpts.DeviceCreateInFacilityInput = &metal.DeviceCreateInFacilityInput{
+			Hostname:        &req.Hostname,
+			Facility:        []string{facility},
+			BillingCycle:    &spec.BillingCycle,
+			Plan:            spec.MachineType,
+			OperatingSystem: spec.OS,
+			IpxeScriptUrl:   &spec.IPXEUrl,
+			AlwaysPxe:       alwaysPXE,
+			Tags:            tags,
+			Userdata:        &userData,
+		}
+	} else {
+		serverCreateOpts.DeviceCreateInMetroInput = &metal.DeviceCreateInMetroInput{
+			Hostname:        &req.Hostname,
+			Metro:           metro,
+			BillingCycle:    &spec.BillingCycle,
+			Plan:            spec.MachineType,
+			OperatingSystem: spec.OS,
+			IpxeScriptUrl:   &spec.IPXEUrl,
+			AlwaysPxe:       alwaysPXE,
+			Tags:            tags,
+			Userdata:        &userData,
+		}
+	}
+
+	apiRequest := p.DevicesApi.CreateDevice(ctx, packetClusterSpec.ProjectID)
+	dev, resp, err := apiRequest.CreateDeviceRequest(serverCreateOpts).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	return dev, newAPIError(resp, err)
+}
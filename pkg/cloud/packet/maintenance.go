@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+)
+
+const (
+	// maintenanceEventType prefixes the types of the events Equinix Metal records on devices about their hardware
+	// maintenance, e.g. instance.maintenance.scheduled.
+	maintenanceEventType = "instance.maintenance."
+	// maintenanceEventsPerPage is how many of the latest events of a device are looked through for maintenance.
+	maintenanceEventsPerPage = 50
+)
+
+// maintenanceStart matches the start of the maintenance window in the message of a maintenance event.
+var maintenanceStart = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+
+// DeviceMaintenance is a maintenance of a device scheduled, or started, by Equinix Metal.
+type DeviceMaintenance struct {
+	// Start is when the maintenance window starts, zero when the event does not tell.
+	Start time.Time
+	// Started is set once the maintenance is in progress.
+	Started bool
+	// Message is the message of the latest maintenance event.
+	Message string
+}
+
+// GetDeviceMaintenance returns the maintenance of the device that is scheduled or in progress, or nil when there is
+// none, from the latest events of the device.
+func (p *Client) GetDeviceMaintenance(ctx context.Context, deviceID string) (*DeviceMaintenance, error) {
+	events, _, err := p.EventsApi.FindDeviceEvents(ctx, deviceID).PerPage(maintenanceEventsPerPage).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the events of device %s: %w", deviceID, err)
+	}
+	return deviceMaintenance(events.Events), nil
+}
+
+// deviceMaintenance returns the pending maintenance of the latest maintenance event: scheduled or started, but not
+// completed or cancelled.
+func deviceMaintenance(events []metal.Event) *DeviceMaintenance {
+	var maintenanceEvents []metal.Event
+	for _, event := range events {
+		if strings.HasPrefix(event.GetType(), maintenanceEventType) {
+			maintenanceEvents = append(maintenanceEvents, event)
+		}
+	}
+	if len(maintenanceEvents) == 0 {
+		return nil
+	}
+	sort.SliceStable(maintenanceEvents, func(i, j int) bool {
+		return maintenanceEvents[i].GetCreatedAt().After(maintenanceEvents[j].GetCreatedAt())
+	})
+
+	latest := maintenanceEvents[0]
+	message := latest.GetInterpolated()
+	if message == "" {
+		message = latest.GetBody()
+	}
+	maintenance := &DeviceMaintenance{Message: message}
+	switch strings.TrimPrefix(latest.GetType(), maintenanceEventType) {
+	case "scheduled", "rescheduled":
+		if start, err := time.Parse(time.RFC3339, maintenanceStart.FindString(message)); err == nil {
+			maintenance.Start = start
+		}
+	case "started":
+		maintenance.Started = true
+		maintenance.Start = latest.GetCreatedAt()
+	default:
+		// Completed or cancelled.
+		return nil
+	}
+	return maintenance
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+)
+
+func Test_deviceMaintenance(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	event := func(eventType, message string, age time.Duration) metal.Event {
+		return metal.Event{Type: ptr.To(eventType), Interpolated: ptr.To(message), CreatedAt: ptr.To(created.Add(-age))}
+	}
+
+	tests := []struct {
+		name   string
+		events []metal.Event
+		want   *DeviceMaintenance
+	}{
+		{
+			name:   "no maintenance",
+			events: []metal.Event{event("instance.provisioned", "Provisioned device", time.Hour)},
+		},
+		{
+			name: "scheduled",
+			events: []metal.Event{
+				event("instance.provisioned", "Provisioned device", time.Hour),
+				event("instance.maintenance.scheduled", "Maintenance scheduled for 2024-05-03T02:00:00Z", 0),
+			},
+			want: &DeviceMaintenance{
+				Start:   time.Date(2024, 5, 3, 2, 0, 0, 0, time.UTC),
+				Message: "Maintenance scheduled for 2024-05-03T02:00:00Z",
+			},
+		},
+		{
+			name:   "scheduled without a window",
+			events: []metal.Event{event("instance.maintenance.scheduled", "Maintenance scheduled", 0)},
+			want:   &DeviceMaintenance{Message: "Maintenance scheduled"},
+		},
+		{
+			name: "started",
+			events: []metal.Event{
+				event("instance.maintenance.started", "Maintenance started", 0),
+				event("instance.maintenance.scheduled", "Maintenance scheduled", time.Hour),
+			},
+			want: &DeviceMaintenance{Start: created, Started: true, Message: "Maintenance started"},
+		},
+		{
+			name: "completed",
+			events: []metal.Event{
+				event("instance.maintenance.scheduled", "Maintenance scheduled", 2*time.Hour),
+				event("instance.maintenance.completed", "Maintenance completed", 0),
+				event("instance.maintenance.started", "Maintenance started", time.Hour),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(deviceMaintenance(tt.events)).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// ErrMetalGatewayNotFound is returned when no Metal Gateway routes a VLAN.
+var ErrMetalGatewayNotFound = errors.New("metal gateway not found")
+
+// metalGatewayIncludes are the related resources returned with Metal Gateways, to report their VLAN and addresses.
+var metalGatewayIncludes = []string{"ip_reservation", "virtual_network"}
+
+// CreateMetalGateway creates a Metal Gateway routing the VLAN with the given ID.
+func (p *Client) CreateMetalGateway(ctx context.Context, projectID, vlanID string, gateway infrav1.MetalGateway) (*metal.MetalGateway, error) {
+	input := metal.MetalGatewayCreateInput{VirtualNetworkId: vlanID}
+	if gateway.IPReservationID != "" {
+		input.IpReservationId = &gateway.IPReservationID
+	} else {
+		input.PrivateIpv4SubnetSize = &gateway.PrivateIPv4SubnetSize
+	}
+
+	request := metal.MetalGatewayCreateInputAsCreateMetalGatewayRequest(&input)
+	resp, _, err := p.MetalGatewaysApi.CreateMetalGateway(ctx, projectID).CreateMetalGatewayRequest(request).Include(metalGatewayIncludes).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metal gateway for VLAN %s: %w", gateway.VLAN, err)
+	}
+	if resp.MetalGateway == nil {
+		return nil, fmt.Errorf("failed to create metal gateway for VLAN %s: unexpected VRF metal gateway", gateway.VLAN)
+	}
+	return resp.MetalGateway, nil
+}
+
+// GetMetalGateway returns the Metal Gateway routing the VLAN with the given ID.
+func (p *Client) GetMetalGateway(ctx context.Context, projectID, vlanID string) (*metal.MetalGateway, error) {
+	gateways, err := p.MetalGatewaysApi.FindMetalGatewaysByProject(ctx, projectID).Include(metalGatewayIncludes).ExecuteWithPagination()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metal gateways: %w", err)
+	}
+	for _, gateway := range gateways.MetalGateways {
+		if gateway.MetalGateway != nil && gateway.MetalGateway.VirtualNetwork.GetId() == vlanID {
+			return gateway.MetalGateway, nil
+		}
+	}
+	return nil, ErrMetalGatewayNotFound
+}
+
+// DeleteMetalGateway deletes the Metal Gateway with the given ID, releasing the private IPv4 block reserved for it.
+// A Metal Gateway that is already gone is not an error.
+func (p *Client) DeleteMetalGateway(ctx context.Context, id string) error {
+	_, resp, err := p.MetalGatewaysApi.DeleteMetalGateway(ctx, id).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// MetalGatewayAddress returns the address of a Metal Gateway in its VLAN and its IP block in CIDR notation.
+func MetalGatewayAddress(gateway *metal.MetalGateway) (address, cidr string) {
+	reservation := gateway.IpReservation
+	if reservation == nil || reservation.Network == nil {
+		return "", ""
+	}
+	address = reservation.GetGateway()
+	if address == "" {
+		address = reservation.GetAddress()
+	}
+	return address, fmt.Sprintf("%s/%d", reservation.GetNetwork(), reservation.GetCidr())
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+var (
+	apiCallsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capp_metal_api_calls_total",
+		Help: "Number of Equinix Metal API calls, by endpoint and status code, or \"error\" when no response was received, and by cluster.",
+	}, append([]string{"endpoint", "code"}, scope.ClusterMetricLabels...))
+
+	apiCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "capp_metal_api_call_duration_seconds",
+		Help:    "Latency of the Equinix Metal API calls, by endpoint and cluster.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, append([]string{"endpoint"}, scope.ClusterMetricLabels...))
+
+	reservationFallbacksTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capp_hardware_reservation_fallbacks_total",
+		Help: "Number of hardware reservations skipped for the next one of a device creation, by reason: busy, claimed by another device creation, or failed, and by cluster.",
+	}, append([]string{"reason"}, scope.ClusterMetricLabels...))
+)
+
+func init() {
+	metrics.Registry.MustRegister(apiCallsTotal, apiCallDuration, reservationFallbacksTotal)
+}
+
+type metricLabelsKey struct{}
+
+// WithMetricLabels returns a context whose Equinix Metal API calls are recorded with the given values of
+// scope.ClusterMetricLabels, usually the ones of the scope being reconciled.
+func WithMetricLabels(ctx context.Context, labels prometheus.Labels) context.Context {
+	return context.WithValue(ctx, metricLabelsKey{}, labels)
+}
+
+// metricLabelsFromContext returns the values of scope.ClusterMetricLabels of the context, which are empty for the
+// API calls made on behalf of no cluster.
+func metricLabelsFromContext(ctx context.Context) prometheus.Labels {
+	values, _ := ctx.Value(metricLabelsKey{}).(prometheus.Labels)
+	labels := make(prometheus.Labels, len(scope.ClusterMetricLabels))
+	for _, label := range scope.ClusterMetricLabels {
+		labels[label] = values[label]
+	}
+	return labels
+}
+
+// ForgetClusterMetrics drops the Equinix Metal API call metrics of a deleted cluster.
+func ForgetClusterMetrics(namespace, name string) {
+	labels := prometheus.Labels{"cluster_namespace": namespace, "cluster_name": name}
+	apiCallsTotal.DeletePartialMatch(labels)
+	apiCallDuration.DeletePartialMatch(labels)
+	apiThrottledRequestsTotal.DeletePartialMatch(labels)
+}
+
+// metricsTransport records the number and latency of the Equinix Metal API calls.
+type metricsTransport struct {
+	next http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := apiEndpoint(req)
+	labels := metricLabelsFromContext(req.Context())
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	apiCallDuration.MustCurryWith(labels).WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	apiCallsTotal.MustCurryWith(labels).WithLabelValues(endpoint, code).Inc()
+	return resp, err
+}
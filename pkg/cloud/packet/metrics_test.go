@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func Test_metricsTransport(t *testing.T) {
+	g := NewWithT(t)
+
+	errConnection := errors.New("connection refused")
+	var fail bool
+	transport := &metricsTransport{next: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		if fail {
+			return nil, errConnection
+		}
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil
+	})}
+
+	ctx := WithMetricLabels(context.Background(), prometheus.Labels{"cluster_namespace": "ns", "cluster_name": "cluster", "metro": "da"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"https://api.equinix.com/metal/v1/hardware-reservations/0d6a1b1c-6f5e-4c3d-9a5b-8f1e2d3c4b5a", http.NoBody)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	_, err = transport.RoundTrip(req) //nolint:bodyclose
+	g.Expect(err).ToNot(HaveOccurred())
+	fail = true
+	_, err = transport.RoundTrip(req) //nolint:bodyclose
+	g.Expect(err).To(MatchError(errConnection))
+
+	g.Expect(testutil.ToFloat64(apiCallsTotal.WithLabelValues("GET /hardware-reservations/{id}", "404", "ns", "cluster", "da"))).To(Equal(1.0))
+	g.Expect(testutil.ToFloat64(apiCallsTotal.WithLabelValues("GET /hardware-reservations/{id}", "error", "ns", "cluster", "da"))).To(Equal(1.0))
+	g.Expect(testutil.CollectAndCount(apiCallDuration)).To(BeNumerically(">=", 1))
+
+	// Calls made on behalf of no cluster have empty cluster labels.
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, "https://api.equinix.com/metal/v1/projects", http.NoBody)
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = transport.RoundTrip(req) //nolint:bodyclose
+	g.Expect(err).To(MatchError(errConnection))
+	g.Expect(testutil.ToFloat64(apiCallsTotal.WithLabelValues("GET /projects", "error", "", "", ""))).To(Equal(1.0))
+
+	// The metrics of deleted clusters are dropped.
+	ForgetClusterMetrics("ns", "cluster")
+	g.Expect(testutil.ToFloat64(apiCallsTotal.WithLabelValues("GET /hardware-reservations/{id}", "404", "ns", "cluster", "da"))).To(BeZero())
+	g.Expect(testutil.ToFloat64(apiCallsTotal.WithLabelValues("GET /projects", "error", "", "", ""))).To(Equal(1.0))
+}
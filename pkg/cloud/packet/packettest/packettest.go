@@ -0,0 +1,40 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package packettest points the clients of the packet package at fake Equinix Metal APIs for unit tests.
+package packettest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+)
+
+// NewClient returns a client of the Equinix Metal API served by handler. The server is closed when the test ends.
+func NewClient(t testing.TB, handler http.Handler) *packet.Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := packet.NewClient("token")
+	client.GetConfig().Servers = metal.ServerConfigurations{{URL: server.URL}}
+	return client
+}
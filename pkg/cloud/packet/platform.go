@@ -0,0 +1,40 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"strings"
+)
+
+// PlanArchitecture returns the Kubernetes name of the CPU architecture of an Equinix Metal plan, e.g. "arm64" for
+// c3.large.arm64. Plans are assumed to be x86 unless their slug says otherwise.
+func PlanArchitecture(plan string) string {
+	plan = strings.ToLower(plan)
+	if strings.HasSuffix(plan, ".arm64") || strings.HasSuffix(plan, ".arm") {
+		return "arm64"
+	}
+	return "amd64"
+}
+
+// OSFamily returns the Kubernetes name of the family of an Equinix Metal operating system, e.g. "linux" for
+// ubuntu_22_04.
+func OSFamily(os string) string {
+	if strings.HasPrefix(strings.ToLower(os), "windows") {
+		return "windows"
+	}
+	return "linux"
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+)
+
+var (
+	// ErrProjectNotFound is returned when the project of a cluster does not exist, or is not visible to the API key.
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrProjectAccessDenied is returned when the API key is not allowed to access the project of a cluster.
+	ErrProjectAccessDenied = errors.New("access to the project denied")
+	// ErrReadOnlyAPIKey is returned when the API key cannot make changes.
+	ErrReadOnlyAPIKey = errors.New("API key is read-only")
+)
+
+// ValidateProject checks that the project exists and that the API key can manage its devices, IP reservations and
+// BGP configuration, so that a misconfiguration is reported up front rather than by the first device creation.
+func (p *Client) ValidateProject(ctx context.Context, projectID string) error {
+	_, resp, err := p.ProjectsApi.FindProjectById(ctx, projectID).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		switch {
+		case resp != nil && resp.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("%w: %s does not exist or is not visible to the API key, check the projectID of the PacketCluster", ErrProjectNotFound, projectID)
+		case resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden):
+			return fmt.Errorf("%w: the API key cannot access project %s, use a key of the project or of a member of its organization", ErrProjectAccessDenied, projectID)
+		}
+		return fmt.Errorf("failed to retrieve project %s: %w", projectID, err)
+	}
+
+	if p.apiKeyReadOnly(ctx, projectID) {
+		return fmt.Errorf("%w: devices, IP reservations and BGP cannot be managed in project %s, use a read/write API key", ErrReadOnlyAPIKey, projectID)
+	}
+	return nil
+}
+
+// apiKeyReadOnly looks the API key up among the keys of its user and of the project to tell whether it is read-only.
+// Keys that are not found, e.g. because a project key cannot list user keys, are assumed to be read/write.
+func (p *Client) apiKeyReadOnly(ctx context.Context, projectID string) bool {
+	token := p.GetConfig().DefaultHeader["X-Auth-Token"]
+
+	var keys []metal.AuthToken
+	if userKeys, _, err := p.AuthenticationApi.FindAPIKeys(ctx).Execute(); err == nil { //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		keys = append(keys, userKeys.ApiKeys...)
+	}
+	if projectKeys, _, err := p.AuthenticationApi.FindProjectAPIKeys(ctx, projectID).Execute(); err == nil { //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		keys = append(keys, projectKeys.ApiKeys...)
+	}
+
+	for _, key := range keys {
+		if key.GetToken() == token {
+			return key.GetReadOnly()
+		}
+	}
+	return false
+}
+
+// ProjectTags returns the tags of the project created for a cluster, which tell it apart from projects of the same
+// name.
+func ProjectTags(namespace, clusterName string) []string {
+	return []string{GenerateClusterTag(clusterName), GenerateNamespaceTag(namespace)}
+}
+
+// GetProjectByTags returns the project of the organization with the given name and all the given tags, or
+// ErrProjectNotFound.
+func (p *Client) GetProjectByTags(ctx context.Context, organizationID, name string, tags []string) (*metal.Project, error) {
+	projects, err := p.OrganizationsApi.FindOrganizationProjects(ctx, organizationID).Name(name).ExecuteWithPagination()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the projects of organization %s: %w", organizationID, err)
+	}
+	for i, project := range projects.GetProjects() {
+		if project.GetName() == name && ItemsInList(project.Tags, tags) {
+			return &projects.Projects[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s in organization %s", ErrProjectNotFound, name, organizationID)
+}
+
+// CreateProject creates a project with the given name and tags in the organization.
+func (p *Client) CreateProject(ctx context.Context, organizationID, name string, tags []string) (*metal.Project, error) {
+	project, resp, err := p.OrganizationsApi.CreateOrganizationProject(ctx, organizationID).ProjectCreateInput(metal.ProjectCreateInput{
+		Name: name,
+		Tags: tags,
+	}).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return nil, fmt.Errorf("%w: the API key cannot create projects in organization %s, use the key of a user of the organization", ErrProjectAccessDenied, organizationID)
+		}
+		return nil, fmt.Errorf("failed to create project %s: %w", name, newAPIError(resp, err))
+	}
+	return project, nil
+}
+
+// DeleteProject deletes the project with the given ID. A project that is already gone is not an error.
+func (p *Client) DeleteProject(ctx context.Context, projectID string) error {
+	resp, err := p.ProjectsApi.DeleteProject(ctx, projectID).Execute()
+	if resp != nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+	}
+	return newAPIError(resp, err)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestValidateProject(t *testing.T) {
+	tests := []struct {
+		name          string
+		projectStatus int
+		userKeys      string
+		wantErr       error
+	}{
+		{
+			name:          "project of a read/write key",
+			projectStatus: http.StatusOK,
+			userKeys:      `{"api_keys": [{"token": "token", "read_only": false}]}`,
+		},
+		{
+			name:          "key not listed",
+			projectStatus: http.StatusOK,
+			userKeys:      `{"api_keys": [{"token": "other", "read_only": true}]}`,
+		},
+		{
+			name:          "read-only key",
+			projectStatus: http.StatusOK,
+			userKeys:      `{"api_keys": [{"token": "token", "read_only": true}]}`,
+			wantErr:       ErrReadOnlyAPIKey,
+		},
+		{
+			name:          "missing project",
+			projectStatus: http.StatusNotFound,
+			wantErr:       ErrProjectNotFound,
+		},
+		{
+			name:          "project of another organization",
+			projectStatus: http.StatusForbidden,
+			wantErr:       ErrProjectAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.URL.Path == "/projects/project":
+					w.WriteHeader(tt.projectStatus)
+					_, _ = w.Write([]byte(`{"id": "project"}`))
+				case r.URL.Path == "/user/api-keys" && tt.userKeys != "":
+					_, _ = w.Write([]byte(tt.userKeys))
+				case strings.HasSuffix(r.URL.Path, "/api-keys"):
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`{"errors": ["forbidden"]}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+
+			err := client.ValidateProject(context.Background(), "project")
+			if tt.wantErr == nil {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(tt.wantErr))
+		})
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxRateLimitRetries is how many times a rate limited request is retried before its 429 response is returned.
+	maxRateLimitRetries = 3
+	// defaultRateLimitPause is how long requests are paused after a 429 response that does not say when to retry.
+	defaultRateLimitPause = 5 * time.Second
+	// maxRateLimitPause caps how long requests are paused after a rate limited response.
+	maxRateLimitPause = time.Minute
+
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// rateLimitTransport keeps the requests of all the controllers within a shared token bucket, and pauses them all
+// when the API reports the token as rate limited, retrying the requests that were rejected.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+	now     func() time.Time
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+func newRateLimitTransport(next http.RoundTripper, qps float64, burst int) *rateLimitTransport {
+	limit := rate.Limit(qps)
+	if qps <= 0 {
+		limit = rate.Inf
+	}
+	return &rateLimitTransport{
+		next:    next,
+		limiter: rate.NewLimiter(limit, burst),
+		now:     time.Now,
+	}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			t.pause(rateLimitPause(resp.Header, t.now()))
+		case resp.Header.Get(headerRateLimitRemaining) == "0":
+			// The request went through, but the next one would not before the limit resets.
+			t.pause(rateLimitPause(resp.Header, t.now()))
+			return resp, nil
+		default:
+			return resp, nil
+		}
+
+		if attempt >= maxRateLimitRetries || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// wait blocks until the API is not known to be rate limiting the token anymore and a token of the bucket is
+// available, or the context is done.
+func (t *rateLimitTransport) wait(ctx context.Context) error {
+	t.mu.Lock()
+	delay := t.pausedUntil.Sub(t.now())
+	t.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return t.limiter.Wait(ctx)
+}
+
+// pause holds back all requests for the given duration.
+func (t *rateLimitTransport) pause(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if until := t.now().Add(d); until.After(t.pausedUntil) {
+		t.pausedUntil = until
+	}
+}
+
+// rateLimitPause returns how long to wait before the next request from the headers of a rate limited response:
+// Retry-After, in seconds or as an HTTP date, or else X-RateLimit-Reset, in seconds or as a Unix timestamp.
+func rateLimitPause(header http.Header, now time.Time) time.Duration {
+	pause := defaultRateLimitPause
+	if retryAfter := header.Get(headerRetryAfter); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			pause = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			pause = date.Sub(now)
+		}
+	} else if reset, err := strconv.ParseInt(header.Get(headerRateLimitReset), 10, 64); err == nil {
+		// Values beyond a year of seconds can only be timestamps.
+		if reset > int64((365 * 24 * time.Hour).Seconds()) {
+			pause = time.Unix(reset, 0).Sub(now)
+		} else {
+			pause = time.Duration(reset) * time.Second
+		}
+	}
+
+	switch {
+	case pause < 0:
+		return 0
+	case pause > maxRateLimitPause:
+		return maxRateLimitPause
+	}
+	return pause
+}
+
+// SetAPIRateLimit keeps the Equinix Metal API calls of the client, and of the clients derived from it with
+// WithAPIKey, within qps calls per second with bursts of up to burst calls, so that all the controllers share a
+// single budget. Calls rejected by the API with 429 Too Many Requests pause all calls for as long as the API asks
+// to, and are retried. The rate is not limited when qps is 0, but 429 responses are still handled.
+func (p *Client) SetAPIRateLimit(qps float64, burst int) {
+	cfg := p.GetConfig()
+
+	next := http.DefaultTransport
+	if cfg.HTTPClient != nil && cfg.HTTPClient.Transport != nil {
+		next = cfg.HTTPClient.Transport
+	}
+
+	cfg.HTTPClient = &http.Client{Transport: newRateLimitTransport(next, qps, burst)}
+}
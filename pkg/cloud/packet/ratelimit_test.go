@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_rateLimitPause(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{
+			name:   "no header",
+			header: http.Header{},
+			want:   defaultRateLimitPause,
+		},
+		{
+			name:   "retry after seconds",
+			header: http.Header{headerRetryAfter: {"3"}},
+			want:   3 * time.Second,
+		},
+		{
+			name:   "retry after date",
+			header: http.Header{headerRetryAfter: {now.Add(7 * time.Second).Format(http.TimeFormat)}},
+			want:   7 * time.Second,
+		},
+		{
+			name:   "reset in seconds",
+			header: http.Header{headerRateLimitReset: {"12"}},
+			want:   12 * time.Second,
+		},
+		{
+			name:   "reset timestamp",
+			header: http.Header{headerRateLimitReset: {strconv.FormatInt(now.Add(20*time.Second).Unix(), 10)}},
+			want:   20 * time.Second,
+		},
+		{
+			name:   "capped",
+			header: http.Header{headerRetryAfter: {"3600"}},
+			want:   maxRateLimitPause,
+		},
+		{
+			name:   "in the past",
+			header: http.Header{headerRetryAfter: {now.Add(-time.Minute).Format(http.TimeFormat)}},
+			want:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Headers received from the API are canonicalized, e.g. to X-Ratelimit-Reset.
+			header := http.Header{}
+			for key, values := range tt.header {
+				header[http.CanonicalHeaderKey(key)] = values
+			}
+			g.Expect(rateLimitPause(header, now)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_rateLimitTransport(t *testing.T) {
+	g := NewWithT(t)
+
+	var bodies []string
+	transport := newRateLimitTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		g.Expect(err).ToNot(HaveOccurred())
+		bodies = append(bodies, string(body))
+
+		if len(bodies) == 1 {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{headerRetryAfter: {"0"}},
+				Body:       io.NopCloser(strings.NewReader(`{"errors": ["rate limited"]}`)),
+			}, nil
+		}
+		return &http.Response{StatusCode: http.StatusCreated, Body: http.NoBody}, nil
+	}), 0, 1)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://api.equinix.com/metal/v1/projects/project/devices", strings.NewReader(`{"hostname": "machine"}`))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The rate limited request is retried with its body.
+	resp, err := transport.RoundTrip(req)
+	g.Expect(err).ToNot(HaveOccurred())
+	defer resp.Body.Close()
+	g.Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+	g.Expect(bodies).To(Equal([]string{`{"hostname": "machine"}`, `{"hostname": "machine"}`}))
+}
+
+func Test_rateLimitTransportPause(t *testing.T) {
+	g := NewWithT(t)
+
+	var calls int
+	transport := newRateLimitTransport(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{headerRetryAfter: {"30"}},
+			Body:       http.NoBody,
+		}, nil
+	}), 0, 1)
+
+	// Requests are held back while the API rate limits the token, until their context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.equinix.com/metal/v1/projects", http.NoBody)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	_, err = transport.RoundTrip(req) //nolint:bodyclose
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+	g.Expect(calls).To(Equal(1))
+}
+
+func Test_rateLimitTransportBucket(t *testing.T) {
+	g := NewWithT(t)
+
+	transport := newRateLimitTransport(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), 1, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	newRequest := func() *http.Request {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.equinix.com/metal/v1/projects", http.NoBody)
+		g.Expect(err).ToNot(HaveOccurred())
+		return req
+	}
+
+	_, err := transport.RoundTrip(newRequest()) //nolint:bodyclose
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The bucket is empty and does not refill before the context is done.
+	_, err = transport.RoundTrip(newRequest()) //nolint:bodyclose
+	g.Expect(err).To(HaveOccurred())
+}
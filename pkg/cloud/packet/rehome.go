@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+)
+
+// ErrDeviceNotReleased is returned when adopting a device that was not released by its previous PacketMachine.
+var ErrDeviceNotReleased = errors.New("device was not released")
+
+// ReleaseDevice detaches a device from its PacketMachine without deleting it: the machine tag is replaced with the
+// released tags, so that the device is no longer found by the tags of the machine and can be adopted by a machine of
+// the same cluster, or by a machine of machineDeployment when set.
+func (p *Client) ReleaseDevice(ctx context.Context, dev *metal.Device, machineDeployment string) error {
+	add := []string{releasedTag}
+	if machineDeployment != "" {
+		add = append(add, GenerateReleasedTag(machineDeployment))
+	}
+	return p.retagDevice(ctx, dev, add)
+}
+
+// GetReleasedDevices returns the devices of a cluster released for the machines of a MachineDeployment, oldest first.
+func (p *Client) GetReleasedDevices(ctx context.Context, project, namespace, clusterName, machineDeployment string) ([]metal.Device, error) {
+	return p.GetDevicesByTags(ctx, project, []string{
+		GenerateClusterTag(clusterName),
+		GenerateNamespaceTag(namespace),
+		GenerateReleasedTag(machineDeployment),
+	})
+}
+
+// IsReleased reports whether the device was released by its PacketMachine.
+func IsReleased(dev *metal.Device) bool {
+	return ItemsInList(dev.Tags, []string{releasedTag})
+}
+
+// AdoptDevice assigns a released device to a PacketMachine by replacing its released tags with the tags the device
+// would have been created with for the machine.
+func (p *Client) AdoptDevice(ctx context.Context, dev *metal.Device, namespace, name, clusterName string) (*metal.Device, error) {
+	if !IsReleased(dev) {
+		return nil, fmt.Errorf("%w: %s", ErrDeviceNotReleased, dev.GetId())
+	}
+	if err := p.retagDevice(ctx, dev, DefaultCreateTags(namespace, name, clusterName)); err != nil {
+		return nil, err
+	}
+	return dev, nil
+}
+
+// DetachDevice removes the machine tag of the device, so that it is no longer found by the tags of its machine, e.g.
+// before deleting it to create another one.
+func (p *Client) DetachDevice(ctx context.Context, dev *metal.Device) error {
+	return p.retagDevice(ctx, dev, nil)
+}
+
+// retagDevice removes the machine, released and legacy tags of the device and adds the given tags.
+func (p *Client) retagDevice(ctx context.Context, dev *metal.Device, add []string) error {
+	tags := make([]string, 0, len(dev.Tags)+len(add))
+	for _, tag := range dev.Tags {
+		if strings.HasPrefix(tag, machineUIDTag+":") || tag == releasedTag || strings.HasPrefix(tag, releasedTag+":") || isLegacyTag(tag) {
+			continue
+		}
+		if !ItemsInList(add, []string{tag}) {
+			tags = append(tags, tag)
+		}
+	}
+	tags = append(tags, add...)
+	return p.updateDeviceTags(ctx, dev, tags)
+}
+
+// updateDeviceTags replaces the tags of the device.
+func (p *Client) updateDeviceTags(ctx context.Context, dev *metal.Device, tags []string) error {
+	apiRequest := p.DevicesApi.UpdateDevice(ctx, dev.GetId()).DeviceUpdateInput(metal.DeviceUpdateInput{Tags: tags})
+	if _, _, err := apiRequest.Execute(); err != nil { //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+		return fmt.Errorf("failed to update the tags of device %s: %w", dev.GetId(), err)
+	}
+	dev.Tags = tags
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestReleaseAndAdoptDevice(t *testing.T) {
+	g := NewWithT(t)
+
+	var updated []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.Method).To(Equal(http.MethodPut))
+		g.Expect(r.URL.Path).To(Equal("/devices/device"))
+
+		var input metal.DeviceUpdateInput
+		g.Expect(json.NewDecoder(r.Body).Decode(&input)).To(Succeed())
+		updated = input.Tags
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "device"}`))
+	}))
+
+	id := "device"
+	dev := &metal.Device{Id: &id, Tags: append(DefaultCreateTags("default", "md-a-1", "cluster"), "custom", infrav1.WorkerTag)}
+
+	_, err := client.AdoptDevice(context.Background(), dev, "default", "md-b-1", "cluster")
+	g.Expect(err).To(MatchError(ErrDeviceNotReleased))
+
+	g.Expect(client.ReleaseDevice(context.Background(), dev, "md-b")).To(Succeed())
+	g.Expect(updated).To(ConsistOf(
+		GenerateClusterTag("cluster"), GenerateNamespaceTag("default"), "custom", infrav1.WorkerTag,
+		releasedTag, GenerateReleasedTag("md-b"),
+	))
+	g.Expect(IsReleased(dev)).To(BeTrue())
+
+	_, err = client.AdoptDevice(context.Background(), dev, "default", "md-b-1", "cluster")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(updated).To(ConsistOf(
+		GenerateClusterTag("cluster"), GenerateNamespaceTag("default"), "custom", infrav1.WorkerTag,
+		GenerateMachineNameTag("md-b-1"),
+	))
+	g.Expect(IsReleased(dev)).To(BeFalse())
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+const (
+	// reservationClaimTTL is how long a hardware reservation used for a device creation is kept away from other
+	// creations, so that it is not handed out again before the API reports it as no longer provisionable.
+	reservationClaimTTL = 2 * time.Minute
+
+	// reservationBackoffBase and reservationBackoffMax bound how long a hardware reservation a device creation failed
+	// on is not tried again. The delay doubles with every consecutive failure.
+	reservationBackoffBase = 30 * time.Second
+	reservationBackoffMax  = 10 * time.Minute
+
+	// nextAvailableReservation lets Equinix Metal pick any provisionable hardware reservation of the plan.
+	nextAvailableReservation = "next-available"
+)
+
+var (
+	// ErrReservationPoolNotFound is returned when a PacketMachine references a reservation pool its PacketCluster
+	// does not define.
+	ErrReservationPoolNotFound = errors.New("reservation pool not found")
+	// ErrReservationPoolExhausted is returned when a reservation pool has no reservation left to allocate from.
+	// The message matches the one of the Equinix Metal API so that it is not treated as a fatal error.
+	ErrReservationPoolExhausted = errors.New("reservation pool has no available hardware reservations left")
+	// ErrNoMatchingReservations is returned when no provisionable hardware reservation matches the reservation
+	// selector of a machine. Like ErrReservationPoolExhausted, it is not treated as a fatal error.
+	ErrNoMatchingReservations = errors.New("no available hardware reservations match the reservation selector")
+)
+
+// ReservationAttempt is a hardware reservation tried for a device creation, and why it could not be used.
+type ReservationAttempt struct {
+	ReservationID string
+	Reason        string
+}
+
+// ReservationsUnavailableError is returned when none of the hardware reservations of a machine could be used because
+// they are busy, e.g. still deprovisioning. The creation is to be retried after RetryAfter.
+type ReservationsUnavailableError struct {
+	Attempts   []ReservationAttempt
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ReservationsUnavailableError) Error() string {
+	return fmt.Sprintf("no hardware reservation available, retrying in %s: %s", e.RetryAfter, e.Summary())
+}
+
+func (e *ReservationsUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// Summary lists the reservations tried and why they could not be used.
+func (e *ReservationsUnavailableError) Summary() string {
+	if len(e.Attempts) == 0 {
+		return e.Err.Error()
+	}
+	attempts := make([]string, 0, len(e.Attempts))
+	for _, attempt := range e.Attempts {
+		attempts = append(attempts, fmt.Sprintf("%s: %s", attempt.ReservationID, attempt.Reason))
+	}
+	return strings.Join(attempts, "; ")
+}
+
+// reservationClaims tracks the hardware reservations used by in-flight device creations, so that concurrent
+// creations from the same pool each get a different reservation, and the reservations creations recently failed on,
+// so that they are not hammered until they become available.
+type reservationClaims struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	claims map[string]time.Time
+	busy   map[string]reservationBackoff
+}
+
+// reservationBackoff is how long a busy reservation is left alone, after its number of consecutive failures.
+type reservationBackoff struct {
+	until    time.Time
+	failures int
+}
+
+func (c *reservationClaims) init() {
+	if c.claims == nil {
+		c.claims = map[string]time.Time{}
+	}
+	if c.busy == nil {
+		c.busy = map[string]reservationBackoff{}
+	}
+	if c.now == nil {
+		c.now = time.Now
+	}
+}
+
+// claim takes the reservation, reporting whether it was free.
+func (c *reservationClaims) claim(reservationID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+
+	now := c.now()
+	if expiry, ok := c.claims[reservationID]; ok && now.Before(expiry) {
+		return false
+	}
+	c.claims[reservationID] = now.Add(reservationClaimTTL)
+	return true
+}
+
+// release gives back a reservation that could not be used.
+func (c *reservationClaims) release(reservationID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.claims, reservationID)
+}
+
+// busyUntil returns until when a reservation a creation failed on is not to be tried again, or the zero time.
+func (c *reservationClaims) busyUntil(reservationID string) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+
+	if backoff, ok := c.busy[reservationID]; ok && c.now().Before(backoff.until) {
+		return backoff.until
+	}
+	return time.Time{}
+}
+
+// markBusy gives back a reservation a creation failed on because it is not available, and backs off from it for
+// twice as long as the previous time, returning until when.
+func (c *reservationClaims) markBusy(reservationID string) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+
+	delete(c.claims, reservationID)
+
+	backoff := c.busy[reservationID]
+	delay := reservationBackoffMax
+	if backoff.failures < 16 {
+		delay = min(reservationBackoffBase<<backoff.failures, reservationBackoffMax)
+	}
+	backoff.failures++
+	backoff.until = c.now().Add(delay)
+	c.busy[reservationID] = backoff
+	return backoff.until
+}
+
+// markUsed forgets the failures of a reservation a device was created on.
+func (c *reservationClaims) markUsed(reservationID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.busy, reservationID)
+}
+
+// hardwareReservationIDs returns the hardware reservations to try, in order, to create the device of the machine in
+// the facility or metro.
+func (p *Client) hardwareReservationIDs(ctx context.Context, machineScope *scope.MachineScope, facility, metro string) ([]string, error) {
+	packetMachineSpec := machineScope.PacketMachine.Spec
+	if selector := packetMachineSpec.ReservationSelector; selector != nil {
+		reservations, err := p.provisionableReservations(ctx, machineScope.PacketCluster.Spec.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+
+		criteria := *selector
+		if criteria.Plan == "" {
+			criteria.Plan = packetMachineSpec.MachineType
+		}
+		if criteria.Facility == "" && criteria.Metro == "" {
+			criteria.Facility, criteria.Metro = facility, metro
+		}
+		reservationIDs := selectReservationIDs(&criteria, reservations)
+		if selector.NextAvailable {
+			reservationIDs = append(reservationIDs, nextAvailableReservation)
+		}
+		if len(reservationIDs) == 0 {
+			return nil, ErrNoMatchingReservations
+		}
+		return reservationIDs, nil
+	}
+
+	if packetMachineSpec.ReservationPool == "" {
+		if packetMachineSpec.HardwareReservationID == "" {
+			return nil, nil
+		}
+		return strings.Split(packetMachineSpec.HardwareReservationID, ","), nil
+	}
+
+	var pool *infrav1.ReservationPool
+	for i := range machineScope.PacketCluster.Spec.ReservationPools {
+		if machineScope.PacketCluster.Spec.ReservationPools[i].Name == packetMachineSpec.ReservationPool {
+			pool = &machineScope.PacketCluster.Spec.ReservationPools[i]
+			break
+		}
+	}
+	if pool == nil {
+		return nil, fmt.Errorf("%w: %s", ErrReservationPoolNotFound, packetMachineSpec.ReservationPool)
+	}
+
+	reservations, err := p.provisionableReservations(ctx, machineScope.PacketCluster.Spec.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	reservationIDs := poolReservationIDs(pool, reservations)
+	if len(reservationIDs) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrReservationPoolExhausted, pool.Name)
+	}
+	return reservationIDs, nil
+}
+
+// provisionableReservations lists the hardware reservations of the project that devices can be created on.
+func (p *Client) provisionableReservations(ctx context.Context, projectID string) ([]metal.HardwareReservation, error) {
+	reservations, err := p.HardwareReservationsApi.FindProjectHardwareReservations(ctx, projectID).
+		Provisionable(metal.FINDPROJECTHARDWARERESERVATIONSPROVISIONABLEPARAMETER_ONLY).
+		Include([]string{"plan", "facility.metro"}).
+		ExecuteWithPagination()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hardware reservations: %w", err)
+	}
+	return reservations.HardwareReservations, nil
+}
+
+// poolReservationIDs returns the provisionable reservations that belong to the pool.
+func poolReservationIDs(pool *infrav1.ReservationPool, provisionable []metal.HardwareReservation) []string {
+	var reservationIDs []string
+
+	if len(pool.ReservationIDs) > 0 {
+		available := map[string]bool{}
+		for _, reservation := range provisionable {
+			available[reservation.GetId()] = true
+		}
+		for _, reservationID := range pool.ReservationIDs {
+			if available[reservationID] {
+				reservationIDs = append(reservationIDs, reservationID)
+			}
+		}
+		return reservationIDs
+	}
+
+	for _, reservation := range provisionable {
+		plan := reservation.GetPlan()
+		if plan.GetSlug() == pool.Plan {
+			reservationIDs = append(reservationIDs, reservation.GetId())
+		}
+	}
+	sort.Strings(reservationIDs)
+	return reservationIDs
+}
+
+// selectReservationIDs returns the provisionable reservations that match the selector, sorted by ID.
+func selectReservationIDs(selector *infrav1.HardwareReservationSelector, provisionable []metal.HardwareReservation) []string {
+	var reservationIDs []string
+
+	for _, reservation := range provisionable {
+		plan := reservation.GetPlan()
+		facility := reservation.GetFacility()
+		metro := facility.GetMetro()
+		switch {
+		case selector.Plan != "" && plan.GetSlug() != selector.Plan:
+		case selector.Facility != "" && !strings.EqualFold(facility.GetCode(), selector.Facility):
+		case selector.Metro != "" && !strings.EqualFold(metro.GetCode(), selector.Metro):
+		case !hasReservationTags(reservation, selector.Tags):
+		default:
+			reservationIDs = append(reservationIDs, reservation.GetId())
+		}
+	}
+	sort.Strings(reservationIDs)
+	return reservationIDs
+}
+
+// hasReservationTags reports whether the hardware reservation has all the tags. The SDK does not model the tags of
+// hardware reservations, so they are read from the additional properties of the API response.
+func hasReservationTags(reservation metal.HardwareReservation, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	values, _ := reservation.AdditionalProperties["tags"].([]interface{})
+	have := make(map[string]bool, len(values))
+	for _, value := range values {
+		if tag, ok := value.(string); ok {
+			have[tag] = true
+		}
+	}
+	for _, tag := range tags {
+		if !have[tag] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope/scopetest"
+)
+
+func Test_reservationClaims(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	claims := &reservationClaims{now: func() time.Time { return now }}
+
+	g.Expect(claims.claim("a")).To(BeTrue())
+	g.Expect(claims.claim("a")).To(BeFalse())
+	g.Expect(claims.claim("b")).To(BeTrue())
+
+	claims.release("b")
+	g.Expect(claims.claim("b")).To(BeTrue())
+
+	now = now.Add(reservationClaimTTL)
+	g.Expect(claims.claim("a")).To(BeTrue())
+}
+
+func Test_reservationClaimsBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	claims := &reservationClaims{now: func() time.Time { return now }}
+
+	g.Expect(claims.claim("a")).To(BeTrue())
+	g.Expect(claims.markBusy("a")).To(Equal(now.Add(reservationBackoffBase)))
+	g.Expect(claims.busyUntil("a")).To(Equal(now.Add(reservationBackoffBase)))
+
+	// The claim is given back, and the backoff doubles with every failure up to its maximum.
+	g.Expect(claims.claim("a")).To(BeTrue())
+	g.Expect(claims.markBusy("a")).To(Equal(now.Add(2 * reservationBackoffBase)))
+	for i := 0; i < 20; i++ {
+		claims.markBusy("a")
+	}
+	g.Expect(claims.busyUntil("a")).To(Equal(now.Add(reservationBackoffMax)))
+
+	now = now.Add(reservationBackoffMax)
+	g.Expect(claims.busyUntil("a").IsZero()).To(BeTrue())
+
+	claims.markUsed("a")
+	g.Expect(claims.markBusy("a")).To(Equal(now.Add(reservationBackoffBase)))
+}
+
+func TestNewDeviceNextAvailableReservation(t *testing.T) {
+	g := NewWithT(t)
+
+	client, requests := contractServer(t)
+
+	var machineScopes []*scope.MachineScope
+	for _, name := range []string{"machine-a", "machine-b"} {
+		machineScope, _, err := scopetest.NewMachineScopeBuilder().
+			WithMachine(&clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: scopetest.Namespace},
+				Spec: clusterv1.MachineSpec{
+					ClusterName: scopetest.ClusterName,
+					Bootstrap:   clusterv1.Bootstrap{DataSecretName: ptr.To("bootstrap")},
+				},
+			}).
+			WithPacketMachine(&infrav1.PacketMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: scopetest.Namespace},
+				Spec: infrav1.PacketMachineSpec{
+					OS:                    "ubuntu_22_04",
+					MachineType:           "c3.small.x86",
+					Metro:                 "da",
+					HardwareReservationID: nextAvailableReservation,
+				},
+			}).
+			WithPacketCluster(&infrav1.PacketCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: scopetest.ClusterName, Namespace: scopetest.Namespace},
+				Spec:       infrav1.PacketClusterSpec{ProjectID: "project"},
+			}).
+			WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "bootstrap", Namespace: scopetest.Namespace},
+				Data:       map[string][]byte{"value": []byte("#cloud-config\n")},
+			}).
+			Build()
+		g.Expect(err).ToNot(HaveOccurred())
+		machineScopes = append(machineScopes, machineScope)
+	}
+
+	// Both machines are created at once on the next available reservation, which is never claimed by either.
+	errs := make([]error, len(machineScopes))
+	var wg sync.WaitGroup
+	for i := range machineScopes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = client.NewDevice(context.Background(), CreateDeviceRequest{MachineScope: machineScopes[i]})
+		}(i)
+	}
+	wg.Wait()
+	g.Expect(errs).To(HaveEach(Succeed()))
+
+	g.Expect(*requests).To(HaveLen(2))
+	for _, request := range *requests {
+		g.Expect(request.Method + " " + request.Path).To(Equal("POST /projects/project/devices"))
+		g.Expect(request.Body).To(HaveKeyWithValue("hardware_reservation_id", nextAvailableReservation))
+	}
+	g.Expect(client.reservations.claims).ToNot(HaveKey(nextAvailableReservation))
+	g.Expect(client.reservations.busyUntil(nextAvailableReservation).IsZero()).To(BeTrue())
+}
+
+func TestReservationsUnavailableError(t *testing.T) {
+	g := NewWithT(t)
+
+	err := &ReservationsUnavailableError{
+		Attempts: []ReservationAttempt{
+			{ReservationID: "r1", Reason: "Server is not provisionable"},
+			{ReservationID: "r2", Reason: "used by another device creation"},
+		},
+		RetryAfter: time.Minute,
+		Err:        ErrReservationPoolExhausted,
+	}
+	g.Expect(err.Summary()).To(Equal("r1: Server is not provisionable; r2: used by another device creation"))
+	g.Expect(err).To(MatchError(ErrReservationPoolExhausted))
+	g.Expect(err.Error()).To(HavePrefix("no hardware reservation available, retrying in 1m0s: r1"))
+}
+
+func Test_poolReservationIDs(t *testing.T) {
+	provisionable := []metal.HardwareReservation{
+		{Id: ptr.To("r3"), Plan: &metal.Plan{Slug: ptr.To("c3.small.x86")}},
+		{Id: ptr.To("r1"), Plan: &metal.Plan{Slug: ptr.To("c3.small.x86")}},
+		{Id: ptr.To("r2"), Plan: &metal.Plan{Slug: ptr.To("m3.large.x86")}},
+	}
+
+	tests := []struct {
+		name string
+		pool infrav1.ReservationPool
+		want []string
+	}{
+		{
+			name: "reservation IDs keep their order and skip unavailable ones",
+			pool: infrav1.ReservationPool{Name: "controlplane", ReservationIDs: []string{"r2", "r4", "r1"}},
+			want: []string{"r2", "r1"},
+		},
+		{
+			name: "plan",
+			pool: infrav1.ReservationPool{Name: "workers", Plan: "c3.small.x86"},
+			want: []string{"r1", "r3"},
+		},
+		{
+			name: "exhausted",
+			pool: infrav1.ReservationPool{Name: "storage", Plan: "s3.xlarge.x86"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(poolReservationIDs(&tt.pool, provisionable)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_selectReservationIDs(t *testing.T) {
+	provisionable := []metal.HardwareReservation{
+		{
+			Id:                   ptr.To("r3"),
+			Plan:                 &metal.Plan{Slug: ptr.To("c3.small.x86")},
+			Facility:             &metal.Facility{Code: ptr.To("da11"), Metro: &metal.DeviceMetro{Code: ptr.To("da")}},
+			AdditionalProperties: map[string]interface{}{"tags": []interface{}{"gpu", "rack-a"}},
+		},
+		{
+			Id:       ptr.To("r1"),
+			Plan:     &metal.Plan{Slug: ptr.To("c3.small.x86")},
+			Facility: &metal.Facility{Code: ptr.To("da11"), Metro: &metal.DeviceMetro{Code: ptr.To("da")}},
+		},
+		{
+			Id:       ptr.To("r2"),
+			Plan:     &metal.Plan{Slug: ptr.To("c3.small.x86")},
+			Facility: &metal.Facility{Code: ptr.To("sv15"), Metro: &metal.DeviceMetro{Code: ptr.To("sv")}},
+		},
+		{
+			Id:       ptr.To("r4"),
+			Plan:     &metal.Plan{Slug: ptr.To("m3.large.x86")},
+			Facility: &metal.Facility{Code: ptr.To("da11"), Metro: &metal.DeviceMetro{Code: ptr.To("da")}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		selector infrav1.HardwareReservationSelector
+		want     []string
+	}{
+		{
+			name:     "plan",
+			selector: infrav1.HardwareReservationSelector{Plan: "c3.small.x86"},
+			want:     []string{"r1", "r2", "r3"},
+		},
+		{
+			name:     "plan and metro",
+			selector: infrav1.HardwareReservationSelector{Plan: "c3.small.x86", Metro: "DA"},
+			want:     []string{"r1", "r3"},
+		},
+		{
+			name:     "facility",
+			selector: infrav1.HardwareReservationSelector{Facility: "sv15"},
+			want:     []string{"r2"},
+		},
+		{
+			name:     "tags",
+			selector: infrav1.HardwareReservationSelector{Plan: "c3.small.x86", Tags: []string{"rack-a", "gpu"}},
+			want:     []string{"r3"},
+		},
+		{
+			name:     "no match",
+			selector: infrav1.HardwareReservationSelector{Plan: "m3.large.x86", Metro: "sv"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(selectReservationIDs(&tt.selector, provisionable)).To(Equal(tt.want))
+		})
+	}
+}
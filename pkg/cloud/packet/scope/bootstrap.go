@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// BootstrapFormat is the format of the bootstrap data of a Machine.
+type BootstrapFormat string
+
+const (
+	// BootstrapFormatCloudConfig is cloud-init user data, as written by the kubeadm bootstrap provider by default.
+	// It is the only format the bootstrap data is templated for.
+	BootstrapFormatCloudConfig BootstrapFormat = "cloud-config"
+	// BootstrapFormatIgnition is an Ignition config, e.g. for Flatcar.
+	BootstrapFormatIgnition BootstrapFormat = "ignition"
+	// BootstrapFormatTalos is a Talos machine configuration, as written by the Talos bootstrap provider.
+	BootstrapFormatTalos BootstrapFormat = "talos"
+)
+
+// bootstrapFormatKey is the key of the bootstrap data secret bootstrap providers declare the format of the data in.
+const bootstrapFormatKey = "format"
+
+// detectBootstrapFormat returns the format of bootstrap data whose secret does not declare it. Data that is neither
+// an Ignition config nor a Talos machine configuration is assumed to be cloud-config.
+func detectBootstrapFormat(data []byte) BootstrapFormat {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var config map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &config); err == nil {
+			if _, ok := config["ignition"]; ok {
+				return BootstrapFormatIgnition
+			}
+		}
+		return BootstrapFormatCloudConfig
+	}
+
+	// Talos machine configurations are YAML documents with a top-level version and machine section.
+	var version, machine bool
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		switch {
+		case line == "version: v1alpha1":
+			version = true
+		case line == "machine:":
+			machine = true
+		}
+	}
+	if version && machine {
+		return BootstrapFormatTalos
+	}
+	return BootstrapFormatCloudConfig
+}
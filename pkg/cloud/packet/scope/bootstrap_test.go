@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDetectBootstrapFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want BootstrapFormat
+	}{
+		{
+			name: "cloud-config",
+			data: "#cloud-config\nruncmd:\n- kubeadm init\n",
+			want: BootstrapFormatCloudConfig,
+		},
+		{
+			name: "ignition",
+			data: `{"ignition": {"version": "3.3.0"}, "storage": {}}`,
+			want: BootstrapFormatIgnition,
+		},
+		{
+			name: "json without ignition",
+			data: `{"runcmd": ["kubeadm init"]}`,
+			want: BootstrapFormatCloudConfig,
+		},
+		{
+			name: "talos",
+			data: "version: v1alpha1\ndebug: false\nmachine:\n  type: controlplane\ncluster:\n  clusterName: test\n",
+			want: BootstrapFormatTalos,
+		},
+		{
+			name: "yaml with a nested machine section",
+			data: "#cloud-config\nwrite_files:\n- content: |\n    version: v1alpha1\n    machine:\n",
+			want: BootstrapFormatCloudConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(detectBootstrapFormat([]byte(tt.data))).To(Equal(tt.want))
+		})
+	}
+}
@@ -14,7 +14,6 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package scope contains the scope interfaces for the Packet provider.
 package scope
 
 import (
@@ -22,7 +21,6 @@ import (
 
 	"github.com/pkg/errors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
-	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
@@ -33,6 +31,9 @@ type ClusterScopeParams struct {
 	Client        client.Client
 	Cluster       *clusterv1.Cluster
 	PacketCluster *infrav1.PacketCluster
+
+	// Patcher persists the PacketCluster when the scope is closed. Defaults to a patch.Helper using Client.
+	Patcher Patcher
 }
 
 // NewClusterScope creates a new ClusterScope from the supplied parameters.
@@ -45,7 +46,7 @@ func NewClusterScope(params ClusterScopeParams) (*ClusterScope, error) {
 		return nil, errors.New("PacketCluster is required when creating a ClusterScope")
 	}
 
-	helper, err := patch.NewHelper(params.PacketCluster, params.Client)
+	helper, err := newPatcher(params.Patcher, params.PacketCluster, params.Client)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to init patch helper")
 	}
@@ -61,7 +62,7 @@ func NewClusterScope(params ClusterScopeParams) (*ClusterScope, error) {
 // ClusterScope defines the basic context for an actuator to operate upon.
 type ClusterScope struct {
 	client      client.Client
-	patchHelper *patch.Helper
+	patchHelper Patcher
 
 	Cluster       *clusterv1.Cluster
 	PacketCluster *infrav1.PacketCluster
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// ErrMissingPacketDeviceClaim is returned when a packetDeviceClaim is not provided to the DeviceClaimScope.
+var ErrMissingPacketDeviceClaim = errors.New("packetDeviceClaim is required when creating a DeviceClaimScope")
+
+// DeviceClaimScopeParams defines the input parameters used to create a new DeviceClaimScope.
+type DeviceClaimScopeParams struct {
+	Client            client.Client
+	Cluster           *clusterv1.Cluster
+	PacketCluster     *infrav1.PacketCluster
+	PacketDeviceClaim *infrav1.PacketDeviceClaim
+
+	// Patcher persists the PacketDeviceClaim when the scope is closed. Defaults to a patch.Helper using Client.
+	Patcher Patcher
+}
+
+// NewDeviceClaimScope creates a new DeviceClaimScope from the supplied parameters.
+// This is meant to be called for each reconcile iteration of the PacketDeviceClaimReconciler.
+func NewDeviceClaimScope(params DeviceClaimScopeParams) (*DeviceClaimScope, error) {
+	if params.Client == nil {
+		return nil, ErrMissingClient
+	}
+	if params.Cluster == nil {
+		return nil, ErrMissingCluster
+	}
+	if params.PacketCluster == nil {
+		return nil, ErrMissingPacketCluster
+	}
+	if params.PacketDeviceClaim == nil {
+		return nil, ErrMissingPacketDeviceClaim
+	}
+
+	helper, err := newPatcher(params.Patcher, params.PacketDeviceClaim, params.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init patch helper: %w", err)
+	}
+	return &DeviceClaimScope{
+		patchHelper:       helper,
+		Cluster:           params.Cluster,
+		PacketCluster:     params.PacketCluster,
+		PacketDeviceClaim: params.PacketDeviceClaim,
+	}, nil
+}
+
+// DeviceClaimScope defines a scope defined around a device claim and its cluster.
+type DeviceClaimScope struct {
+	patchHelper       Patcher
+	Cluster           *clusterv1.Cluster
+	PacketCluster     *infrav1.PacketCluster
+	PacketDeviceClaim *infrav1.PacketDeviceClaim
+}
+
+// Close the DeviceClaimScope by updating the device claim spec and status.
+func (s *DeviceClaimScope) Close(ctx context.Context) error {
+	return s.PatchObject(ctx)
+}
+
+// Name returns the PacketDeviceClaim name.
+func (s *DeviceClaimScope) Name() string {
+	return s.PacketDeviceClaim.Name
+}
+
+// Namespace returns the PacketDeviceClaim namespace.
+func (s *DeviceClaimScope) Namespace() string {
+	return s.PacketDeviceClaim.Namespace
+}
+
+// PatchObject persists the device claim spec and status.
+func (s *DeviceClaimScope) PatchObject(ctx context.Context) error {
+	conditions.SetSummary(s.PacketDeviceClaim,
+		conditions.WithConditions(infrav1.DeviceBoundCondition),
+	)
+
+	return s.patchHelper.Patch(
+		ctx,
+		s.PacketDeviceClaim,
+		patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			clusterv1.ReadyCondition,
+			infrav1.DeviceBoundCondition,
+		}})
+}
@@ -0,0 +1,27 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scope contains the scopes the Packet provider reconciles its objects with.
+//
+// A ClusterScope holds a Cluster and its PacketCluster, and a MachineScope a Machine and its PacketMachine along
+// with their Cluster and PacketCluster, for the duration of a single reconciliation. The changes made to the
+// PacketCluster or PacketMachine through the scope are persisted when the scope is closed.
+//
+// The scopes only depend on a controller-runtime client.Client and a Patcher, so controllers extending the
+// provider can build them with NewClusterScope and NewMachineScope, and unit tests with the builders of the
+// scopetest package. The exported API of this package follows the compatibility guarantees of the provider API
+// version it works with, v1beta1.
+package scope
@@ -18,9 +18,12 @@ package scope
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -39,8 +42,19 @@ const (
 	// ProviderIDPrefix will be appended to the beginning of Equinix Metal resource IDs to form the Kubernetes Provider ID.
 	// NOTE: this format matches the 2 slashes format used in cloud-provider and cluster-autoscaler.
 	ProviderIDPrefix = "equinixmetal://"
+	// LegacyProviderIDPrefix is the Provider ID prefix used by older releases of the provider and the packet cloud-provider.
+	LegacyProviderIDPrefix = "packet://"
+
+	// defaultDeletionTimeout is how long devices are deleted gracefully when no deletion timeout is set.
+	defaultDeletionTimeout = 10 * time.Minute
 )
 
+// ProviderIDFormats maps the names of the providerID formats the provider can generate to their prefix.
+var ProviderIDFormats = map[string]string{
+	"equinixmetal": ProviderIDPrefix,
+	"packet":       LegacyProviderIDPrefix,
+}
+
 var (
 	// ErrMissingClient is returned when a client is not provided to the MachineScope.
 	ErrMissingClient = errors.New("client is required when creating a MachineScope")
@@ -56,6 +70,8 @@ var (
 	ErrMissingBootstrapDataSecret = errors.New("error retrieving bootstrap data: linked Machine's bootstrap.dataSecretName is nil")
 	// ErrBootstrapDataMissingKey is returned when the bootstrap data secret does not contain the "value" key.
 	ErrBootstrapDataMissingKey = errors.New("error retrieving bootstrap data: secret value key is missing")
+	// ErrIPXEScriptMissingKey is returned when the iPXE script secret does not contain the referenced key.
+	ErrIPXEScriptMissingKey = errors.New("error retrieving iPXE script: secret key is missing")
 )
 
 // MachineScopeParams defines the input parameters used to create a new MachineScope.
@@ -65,6 +81,9 @@ type MachineScopeParams struct {
 	Machine       *clusterv1.Machine
 	PacketCluster *infrav1.PacketCluster
 	PacketMachine *infrav1.PacketMachine
+
+	// Patcher persists the PacketMachine when the scope is closed. Defaults to a patch.Helper using Client.
+	Patcher Patcher
 }
 
 // NewMachineScope creates a new MachineScope from the supplied parameters.
@@ -87,7 +106,7 @@ func NewMachineScope(params MachineScopeParams) (*MachineScope, error) {
 		return nil, ErrMissingPacketMachine
 	}
 
-	helper, err := patch.NewHelper(params.PacketMachine, params.Client)
+	helper, err := newPatcher(params.Patcher, params.PacketMachine, params.Client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init patch helper: %w", err)
 	}
@@ -104,11 +123,16 @@ func NewMachineScope(params MachineScopeParams) (*MachineScope, error) {
 // MachineScope defines a scope defined around a machine and its cluster.
 type MachineScope struct {
 	client        client.Client
-	patchHelper   *patch.Helper
+	patchHelper   Patcher
 	Cluster       *clusterv1.Cluster
 	Machine       *clusterv1.Machine
 	PacketCluster *infrav1.PacketCluster
 	PacketMachine *infrav1.PacketMachine
+
+	// bootstrapData caches the bootstrap data for the lifetime of the scope, i.e. a single reconcile.
+	bootstrapData []byte
+	// bootstrapFormat caches the format the bootstrap data secret declares, if any, alongside bootstrapData.
+	bootstrapFormat BootstrapFormat
 }
 
 // Close the MachineScope by updating the machine spec, machine status.
@@ -121,6 +145,11 @@ func (m *MachineScope) Name() string {
 	return m.PacketMachine.Name
 }
 
+// Hostname returns the hostname the device of the machine should have.
+func (m *MachineScope) Hostname() string {
+	return m.Name()
+}
+
 // Namespace returns the PacketMachine namespace.
 func (m *MachineScope) Namespace() string {
 	return m.PacketMachine.Namespace
@@ -149,9 +178,20 @@ func (m *MachineScope) ProviderID() string {
 	return ptr.Deref(m.PacketMachine.Spec.ProviderID, "")
 }
 
+// HasLegacyProviderID returns true if the PacketMachine providerID uses the legacy "packet://" prefix.
+func (m *MachineScope) HasLegacyProviderID() bool {
+	return strings.HasPrefix(m.ProviderID(), LegacyProviderIDPrefix)
+}
+
 // SetProviderID sets the PacketMachine providerID in spec from device id.
 func (m *MachineScope) SetProviderID(deviceID string) {
-	pid := fmt.Sprintf("%s%s", ProviderIDPrefix, deviceID)
+	m.SetProviderIDWithPrefix(ProviderIDPrefix, deviceID)
+}
+
+// SetProviderIDWithPrefix sets the PacketMachine providerID in spec from device id, with one of the prefixes of
+// ProviderIDFormats.
+func (m *MachineScope) SetProviderIDWithPrefix(prefix, deviceID string) {
+	pid := fmt.Sprintf("%s%s", prefix, deviceID)
 	m.PacketMachine.Spec.ProviderID = ptr.To(pid)
 }
 
@@ -165,6 +205,53 @@ func (m *MachineScope) SetInstanceStatus(v infrav1.PacketResourceStatus) {
 	m.PacketMachine.Status.InstanceStatus = &v
 }
 
+// DeletePolicy returns the delete policy of the device, the one of the PacketMachine if set, else the one of the
+// PacketCluster.
+func (m *MachineScope) DeletePolicy() infrav1.DeletePolicy {
+	if m.PacketMachine.Spec.DeletePolicy != "" {
+		return m.PacketMachine.Spec.DeletePolicy
+	}
+	if m.PacketCluster.Spec.DeletePolicy != "" {
+		return m.PacketCluster.Spec.DeletePolicy
+	}
+	return infrav1.DeletePolicyForce
+}
+
+// DeletionTimeout returns how long the device is deleted gracefully, the timeout of the PacketMachine if set, else
+// the one of the PacketCluster, else 10 minutes.
+func (m *MachineScope) DeletionTimeout() time.Duration {
+	if m.PacketMachine.Spec.DeletionTimeoutSeconds != nil {
+		return time.Duration(*m.PacketMachine.Spec.DeletionTimeoutSeconds) * time.Second
+	}
+	if m.PacketCluster.Spec.DeletionTimeoutSeconds != nil {
+		return time.Duration(*m.PacketCluster.Spec.DeletionTimeoutSeconds) * time.Second
+	}
+	return defaultDeletionTimeout
+}
+
+// SetHardware sets the PacketMachine hardware status.
+func (m *MachineScope) SetHardware(v *infrav1.HardwareStatus) {
+	m.PacketMachine.Status.Hardware = v
+}
+
+// SetHardwareReservationID sets the PacketMachine hardware reservation ID status.
+func (m *MachineScope) SetHardwareReservationID(v string) {
+	m.PacketMachine.Status.HardwareReservationID = v
+}
+
+// SetPublicIPv4Block sets the PacketMachine public IPv4 block status.
+func (m *MachineScope) SetPublicIPv4Block(v string) {
+	m.PacketMachine.Status.PublicIPv4Block = v
+}
+
+// SetProvisioningProgress sets the PacketMachine provisioning percentage, and its last event when the device has one.
+func (m *MachineScope) SetProvisioningProgress(percentage *int32, event *infrav1.DeviceEvent) {
+	m.PacketMachine.Status.ProvisioningPercentage = percentage
+	if event != nil {
+		m.PacketMachine.Status.LastEvent = event
+	}
+}
+
 // SetReady sets the PacketMachine Ready Status.
 func (m *MachineScope) SetReady() {
 	m.PacketMachine.Status.Ready = true
@@ -200,7 +287,12 @@ func (m *MachineScope) Tags() infrav1.Tags {
 }
 
 // GetRawBootstrapData returns the bootstrap data from the secret in the Machine's bootstrap.dataSecretName.
+// The secret is only read once per scope.
 func (m *MachineScope) GetRawBootstrapData(ctx context.Context) ([]byte, error) {
+	if m.bootstrapData != nil {
+		return m.bootstrapData, nil
+	}
+
 	if m.Machine.Spec.Bootstrap.DataSecretName == nil {
 		return nil, ErrMissingBootstrapDataSecret
 	}
@@ -216,6 +308,59 @@ func (m *MachineScope) GetRawBootstrapData(ctx context.Context) ([]byte, error)
 		return nil, ErrBootstrapDataMissingKey
 	}
 
+	m.bootstrapData = value
+	m.bootstrapFormat = BootstrapFormat(secret.Data[bootstrapFormatKey])
+	return value, nil
+}
+
+// GetBootstrapDataFormat returns the format of the bootstrap data. The format declared by the bootstrap provider in
+// the format key of the secret wins, otherwise it is detected from the data.
+func (m *MachineScope) GetBootstrapDataFormat(ctx context.Context) (BootstrapFormat, error) {
+	data, err := m.GetRawBootstrapData(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if m.bootstrapFormat != "" {
+		return m.bootstrapFormat, nil
+	}
+	return detectBootstrapFormat(data), nil
+}
+
+// GetBootstrapDataHash returns the hex encoded SHA-256 hash of the bootstrap data.
+func (m *MachineScope) GetBootstrapDataHash(ctx context.Context) (string, error) {
+	data, err := m.GetRawBootstrapData(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SetBootstrapDataHash records the hash of the bootstrap data the device was created with.
+func (m *MachineScope) SetBootstrapDataHash(v string) {
+	m.PacketMachine.Status.BootstrapDataHash = v
+}
+
+// GetRawIPXEScript returns the iPXE script from the secret in the PacketMachine's ipxeScriptSecretRef.
+func (m *MachineScope) GetRawIPXEScript(ctx context.Context) ([]byte, error) {
+	ref := m.PacketMachine.Spec.IPXEScriptSecretRef
+	if ref == nil {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: m.Namespace(), Name: ref.Name}
+	if err := m.client.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to retrieve iPXE script secret for PacketMachine %s/%s: %w", m.Namespace(), m.Name(), err)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrIPXEScriptMissingKey, ref.Key)
+	}
+
 	return value, nil
 }
 
@@ -239,10 +384,24 @@ func (m *MachineScope) PatchObject(ctx context.Context) error {
 		patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
 			clusterv1.ReadyCondition,
 			infrav1.DeviceReadyCondition,
+			infrav1.ProviderIDMigratedCondition,
+			infrav1.BootstrapDataUpToDateCondition,
+			infrav1.ThrottledByProviderCondition,
+			infrav1.LocationRetiredCondition,
+			infrav1.DuplicateDevicesCondition,
+			infrav1.DeviceIdentityMismatchCondition,
+			infrav1.BGPSessionsReadyCondition,
+			infrav1.NetworkBondReadyCondition,
+			infrav1.VLANsAttachedCondition,
+			infrav1.MaintenanceScheduledCondition,
 		}})
 }
 
-// ParseProviderID parses a string to a PacketMachine Provider ID, first removing the "equinixmetal://" prefix.
+// ParseProviderID parses a string to a PacketMachine Provider ID, first removing the "equinixmetal://"
+// or legacy "packet://" prefix.
 func parseProviderID(id string) string {
+	if strings.HasPrefix(id, LegacyProviderIDPrefix) {
+		return strings.TrimPrefix(id, LegacyProviderIDPrefix)
+	}
 	return strings.TrimPrefix(id, ProviderIDPrefix)
 }
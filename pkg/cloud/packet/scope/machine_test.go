@@ -17,9 +17,15 @@ limitations under the License.
 package scope
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -90,3 +96,161 @@ func TestNewMachineScopeNoPacketMachine(t *testing.T) {
 		})
 	g.Expect(err).To(MatchError(ErrMissingPacketMachine))
 }
+
+func TestMachineScopeLegacyProviderID(t *testing.T) {
+	g := NewWithT(t)
+
+	packetMachine := new(infrav1.PacketMachine)
+	packetMachine.Spec.ProviderID = ptr.To("packet://c4a5d2b0-6c4d-4b87-9d36-3e1bb0a7a3f1")
+
+	machineScope := &MachineScope{PacketMachine: packetMachine}
+	g.Expect(machineScope.HasLegacyProviderID()).To(BeTrue())
+	g.Expect(machineScope.GetDeviceID()).To(Equal("c4a5d2b0-6c4d-4b87-9d36-3e1bb0a7a3f1"))
+
+	machineScope.SetProviderID(machineScope.GetDeviceID())
+	g.Expect(machineScope.HasLegacyProviderID()).To(BeFalse())
+	g.Expect(machineScope.ProviderID()).To(Equal("equinixmetal://c4a5d2b0-6c4d-4b87-9d36-3e1bb0a7a3f1"))
+	g.Expect(machineScope.GetDeviceID()).To(Equal("c4a5d2b0-6c4d-4b87-9d36-3e1bb0a7a3f1"))
+}
+
+func TestMachineScopeGetRawIPXEScript(t *testing.T) {
+	g := NewWithT(t)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ipxe", Namespace: "default"},
+		Data:       map[string][]byte{"script": []byte("#!ipxe\nchain http://10.0.0.1/boot.ipxe\n")},
+	}
+	packetMachine := new(infrav1.PacketMachine)
+	packetMachine.Namespace = "default"
+
+	machineScope := &MachineScope{
+		client:        fake.NewClientBuilder().WithObjects(secret).Build(),
+		PacketMachine: packetMachine,
+	}
+
+	script, err := machineScope.GetRawIPXEScript(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(script).To(BeNil())
+
+	packetMachine.Spec.IPXEScriptSecretRef = &infrav1.SecretKeyReference{Name: "ipxe", Key: "script"}
+	script, err = machineScope.GetRawIPXEScript(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(script)).To(HavePrefix("#!ipxe"))
+
+	packetMachine.Spec.IPXEScriptSecretRef.Key = "missing"
+	_, err = machineScope.GetRawIPXEScript(context.Background())
+	g.Expect(err).To(MatchError(ErrIPXEScriptMissingKey))
+}
+
+func TestMachineScopeGetBootstrapDataHash(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bootstrap", Namespace: "default"},
+		Data:       map[string][]byte{"value": []byte("#cloud-config\n")},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+	machine := new(clusterv1.Machine)
+	machine.Spec.Bootstrap.DataSecretName = ptr.To("bootstrap")
+	packetMachine := new(infrav1.PacketMachine)
+	packetMachine.Namespace = "default"
+
+	machineScope := &MachineScope{client: c, Machine: machine, PacketMachine: packetMachine}
+	hash, err := machineScope.GetBootstrapDataHash(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(hash).To(HaveLen(64))
+
+	secret.Data["value"] = []byte("#cloud-config\nrotated: true\n")
+	g.Expect(c.Update(ctx, secret)).To(Succeed())
+
+	// The bootstrap data is cached for the lifetime of the scope.
+	cached, err := machineScope.GetBootstrapDataHash(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cached).To(Equal(hash))
+
+	// A new scope, i.e. the next reconcile, sees the change.
+	next := &MachineScope{client: c, Machine: machine, PacketMachine: packetMachine}
+	changed, err := next.GetBootstrapDataHash(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(changed).ToNot(Equal(hash))
+}
+
+func TestMachineScopeDeletePolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	machineScope := &MachineScope{
+		PacketCluster: new(infrav1.PacketCluster),
+		PacketMachine: new(infrav1.PacketMachine),
+	}
+	g.Expect(machineScope.DeletePolicy()).To(Equal(infrav1.DeletePolicyForce))
+
+	machineScope.PacketCluster.Spec.DeletePolicy = infrav1.DeletePolicyGraceful
+	g.Expect(machineScope.DeletePolicy()).To(Equal(infrav1.DeletePolicyGraceful))
+
+	machineScope.PacketMachine.Spec.DeletePolicy = infrav1.DeletePolicyForceAfterTimeout
+	g.Expect(machineScope.DeletePolicy()).To(Equal(infrav1.DeletePolicyForceAfterTimeout))
+}
+
+func TestMachineScopeDeletionTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	machineScope := &MachineScope{
+		PacketCluster: new(infrav1.PacketCluster),
+		PacketMachine: new(infrav1.PacketMachine),
+	}
+	g.Expect(machineScope.DeletionTimeout()).To(Equal(10 * time.Minute))
+
+	machineScope.PacketCluster.Spec.DeletionTimeoutSeconds = ptr.To[int32](300)
+	g.Expect(machineScope.DeletionTimeout()).To(Equal(5 * time.Minute))
+
+	machineScope.PacketMachine.Spec.DeletionTimeoutSeconds = ptr.To[int32](0)
+	g.Expect(machineScope.DeletionTimeout()).To(BeZero())
+}
+
+func TestMachineScopeGetBootstrapDataFormat(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bootstrap", Namespace: "default"},
+		Data:       map[string][]byte{"value": []byte(`{"ignition": {"version": "3.3.0"}}`)},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+	machine := new(clusterv1.Machine)
+	machine.Spec.Bootstrap.DataSecretName = ptr.To("bootstrap")
+	packetMachine := new(infrav1.PacketMachine)
+	packetMachine.Namespace = "default"
+
+	machineScope := &MachineScope{client: c, Machine: machine, PacketMachine: packetMachine}
+	format, err := machineScope.GetBootstrapDataFormat(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(format).To(Equal(BootstrapFormatIgnition))
+
+	// The format declared by the bootstrap provider wins over the detected one.
+	secret.Data["format"] = []byte("talos")
+	g.Expect(c.Update(ctx, secret)).To(Succeed())
+
+	next := &MachineScope{client: c, Machine: machine, PacketMachine: packetMachine}
+	format, err = next.GetBootstrapDataFormat(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(format).To(Equal(BootstrapFormatTalos))
+}
+
+func TestMachineScopeMetricLabels(t *testing.T) {
+	g := NewWithT(t)
+
+	machineScope := &MachineScope{
+		Cluster:       &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster"}},
+		PacketCluster: &infrav1.PacketCluster{Spec: infrav1.PacketClusterSpec{Metro: "da"}},
+		PacketMachine: &infrav1.PacketMachine{},
+	}
+	g.Expect(machineScope.MetricLabels()).To(Equal(prometheus.Labels{"cluster_namespace": "default", "cluster_name": "cluster", "metro": "da"}))
+
+	// The device of a machine created in another location is not in the metro of the cluster.
+	machineScope.PacketMachine.Spec.Metro = "sv"
+	g.Expect(machineScope.MetricLabels()).To(HaveKeyWithValue("metro", "sv"))
+	machineScope.PacketMachine.Spec.Metro = ""
+	machineScope.PacketMachine.Spec.Facility = "ny5"
+	g.Expect(machineScope.MetricLabels()).To(HaveKeyWithValue("metro", ""))
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	expclusterv1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+var (
+	// ErrMissingMachinePool is returned when a machinePool is not provided to the MachinePoolScope.
+	ErrMissingMachinePool = errors.New("machinePool is required when creating a MachinePoolScope")
+	// ErrMissingPacketMachinePool is returned when a packetMachinePool is not provided to the MachinePoolScope.
+	ErrMissingPacketMachinePool = errors.New("packetMachinePool is required when creating a MachinePoolScope")
+)
+
+// MachinePoolScopeParams defines the input parameters used to create a new MachinePoolScope.
+type MachinePoolScopeParams struct {
+	Client            client.Client
+	Cluster           *clusterv1.Cluster
+	MachinePool       *expclusterv1.MachinePool
+	PacketCluster     *infrav1.PacketCluster
+	PacketMachinePool *infrav1.PacketMachinePool
+
+	// Patcher persists the PacketMachinePool when the scope is closed. Defaults to a patch.Helper using Client.
+	Patcher Patcher
+}
+
+// NewMachinePoolScope creates a new MachinePoolScope from the supplied parameters.
+// This is meant to be called for each reconcile iteration of the PacketMachinePoolReconciler.
+func NewMachinePoolScope(params MachinePoolScopeParams) (*MachinePoolScope, error) {
+	if params.Client == nil {
+		return nil, ErrMissingClient
+	}
+	if params.MachinePool == nil {
+		return nil, ErrMissingMachinePool
+	}
+	if params.Cluster == nil {
+		return nil, ErrMissingCluster
+	}
+	if params.PacketCluster == nil {
+		return nil, ErrMissingPacketCluster
+	}
+	if params.PacketMachinePool == nil {
+		return nil, ErrMissingPacketMachinePool
+	}
+
+	helper, err := newPatcher(params.Patcher, params.PacketMachinePool, params.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init patch helper: %w", err)
+	}
+	return &MachinePoolScope{
+		client:            params.Client,
+		patchHelper:       helper,
+		Cluster:           params.Cluster,
+		MachinePool:       params.MachinePool,
+		PacketCluster:     params.PacketCluster,
+		PacketMachinePool: params.PacketMachinePool,
+	}, nil
+}
+
+// MachinePoolScope defines a scope defined around a machine pool and its cluster.
+type MachinePoolScope struct {
+	client            client.Client
+	patchHelper       Patcher
+	Cluster           *clusterv1.Cluster
+	MachinePool       *expclusterv1.MachinePool
+	PacketCluster     *infrav1.PacketCluster
+	PacketMachinePool *infrav1.PacketMachinePool
+
+	// bootstrapData caches the bootstrap data for the lifetime of the scope, i.e. a single reconcile.
+	bootstrapData []byte
+	// bootstrapFormat caches the format the bootstrap data secret declares, if any, alongside bootstrapData.
+	bootstrapFormat BootstrapFormat
+}
+
+// Close the MachinePoolScope by updating the machine pool spec and status.
+func (m *MachinePoolScope) Close(ctx context.Context) error {
+	return m.PatchObject(ctx)
+}
+
+// Name returns the PacketMachinePool name.
+func (m *MachinePoolScope) Name() string {
+	return m.PacketMachinePool.Name
+}
+
+// Namespace returns the PacketMachinePool namespace.
+func (m *MachinePoolScope) Namespace() string {
+	return m.PacketMachinePool.Namespace
+}
+
+// DesiredReplicas returns the number of devices the MachinePool wants. A MachinePool without replicas wants one.
+func (m *MachinePoolScope) DesiredReplicas() int32 {
+	if m.MachinePool.Spec.Replicas == nil {
+		return 1
+	}
+	return *m.MachinePool.Spec.Replicas
+}
+
+// GetRawBootstrapData returns the bootstrap data from the secret in the MachinePool's bootstrap.dataSecretName.
+// The secret is only read once per scope.
+func (m *MachinePoolScope) GetRawBootstrapData(ctx context.Context) ([]byte, error) {
+	if m.bootstrapData != nil {
+		return m.bootstrapData, nil
+	}
+
+	dataSecretName := m.MachinePool.Spec.Template.Spec.Bootstrap.DataSecretName
+	if dataSecretName == nil {
+		return nil, ErrMissingBootstrapDataSecret
+	}
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: m.Namespace(), Name: *dataSecretName}
+	if err := m.client.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to retrieve bootstrap data secret for PacketMachinePool %s/%s: %w", m.Namespace(), m.Name(), err)
+	}
+
+	value, ok := secret.Data["value"]
+	if !ok {
+		return nil, ErrBootstrapDataMissingKey
+	}
+
+	m.bootstrapData = value
+	m.bootstrapFormat = BootstrapFormat(secret.Data[bootstrapFormatKey])
+	return value, nil
+}
+
+// GetBootstrapDataFormat returns the format of the bootstrap data, see MachineScope.GetBootstrapDataFormat.
+func (m *MachinePoolScope) GetBootstrapDataFormat(ctx context.Context) (BootstrapFormat, error) {
+	data, err := m.GetRawBootstrapData(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if m.bootstrapFormat != "" {
+		return m.bootstrapFormat, nil
+	}
+	return detectBootstrapFormat(data), nil
+}
+
+// PatchObject persists the machine pool spec and status.
+func (m *MachinePoolScope) PatchObject(ctx context.Context) error {
+	conditions.SetSummary(m.PacketMachinePool,
+		conditions.WithConditions(infrav1.DevicesReadyCondition),
+	)
+
+	return m.patchHelper.Patch(
+		ctx,
+		m.PacketMachinePool,
+		patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			clusterv1.ReadyCondition,
+			infrav1.DevicesReadyCondition,
+			infrav1.ThrottledByProviderCondition,
+		}})
+}
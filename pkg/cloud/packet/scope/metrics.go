@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// ClusterMetricLabels are the labels of the metrics about the resources of a cluster, see the MetricLabels methods of
+// the scopes.
+var ClusterMetricLabels = []string{"cluster_namespace", "cluster_name", "metro"}
+
+// clusterMetricLabels returns the values of ClusterMetricLabels for resources of the cluster created in the facility
+// or metro, which override the ones of the PacketCluster.
+func clusterMetricLabels(cluster *clusterv1.Cluster, packetCluster *infrav1.PacketCluster, facility, metro string) prometheus.Labels {
+	if facility == "" && metro == "" {
+		metro = packetCluster.Spec.Metro
+	}
+	return prometheus.Labels{
+		"cluster_namespace": cluster.Namespace,
+		"cluster_name":      cluster.Name,
+		"metro":             metro,
+	}
+}
+
+// MetricLabels returns the values of ClusterMetricLabels for the cluster.
+func (s *ClusterScope) MetricLabels() prometheus.Labels {
+	return clusterMetricLabels(s.Cluster, s.PacketCluster, "", "")
+}
+
+// MetricLabels returns the values of ClusterMetricLabels for the device of the machine.
+func (m *MachineScope) MetricLabels() prometheus.Labels {
+	return clusterMetricLabels(m.Cluster, m.PacketCluster, m.PacketMachine.Spec.Facility, m.PacketMachine.Spec.Metro)
+}
+
+// MetricLabels returns the values of ClusterMetricLabels for the devices of the machine pool.
+func (s *MachinePoolScope) MetricLabels() prometheus.Labels {
+	return clusterMetricLabels(s.Cluster, s.PacketCluster, s.PacketMachinePool.Spec.Template.Facility, s.PacketMachinePool.Spec.Template.Metro)
+}
+
+// MetricLabels returns the values of ClusterMetricLabels for the device of the claim.
+func (s *DeviceClaimScope) MetricLabels() prometheus.Labels {
+	return clusterMetricLabels(s.Cluster, s.PacketCluster, "", "")
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Patcher persists the changes made to the objects of a scope. It is implemented by the Cluster API patch.Helper,
+// which the scopes use unless another Patcher is given, e.g. to observe the patches in unit tests.
+type Patcher interface {
+	Patch(ctx context.Context, obj client.Object, opts ...patch.Option) error
+}
+
+// newPatcher returns the given Patcher, or a patch.Helper for obj.
+func newPatcher(patcher Patcher, obj client.Object, c client.Client) (Patcher, error) {
+	if patcher != nil {
+		return patcher, nil
+	}
+	return patch.NewHelper(obj, c)
+}
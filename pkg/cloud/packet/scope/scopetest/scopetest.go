@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scopetest builds the scopes of the scope package for unit tests, backed by a fake client.
+package scopetest
+
+import (
+	"context"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+const (
+	// Namespace is the namespace of the objects the builders create by default.
+	Namespace = "default"
+	// ClusterName is the name of the Cluster and PacketCluster the builders create by default.
+	ClusterName = "test-cluster"
+	// MachineName is the name of the Machine and PacketMachine MachineScopeBuilder creates by default.
+	MachineName = "test-machine"
+)
+
+// Scheme returns a scheme with the types of the objects of the scopes.
+func Scheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = clusterv1.AddToScheme(scheme)
+	_ = infrav1.AddToScheme(scheme)
+	_ = ipamv1.AddToScheme(scheme)
+	return scheme
+}
+
+// Patcher is a scope.Patcher that records the objects it is asked to patch instead of persisting them.
+type Patcher struct {
+	mu      sync.Mutex
+	patched []client.Object
+}
+
+var _ scope.Patcher = &Patcher{}
+
+// Patch implements scope.Patcher.
+func (p *Patcher) Patch(_ context.Context, obj client.Object, _ ...patch.Option) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.patched = append(p.patched, obj.DeepCopyObject().(client.Object))
+	return nil
+}
+
+// Patched returns copies of the objects patched so far, in order.
+func (p *Patcher) Patched() []client.Object {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]client.Object(nil), p.patched...)
+}
+
+// ClusterScopeBuilder builds ClusterScopes.
+type ClusterScopeBuilder struct {
+	cluster       *clusterv1.Cluster
+	packetCluster *infrav1.PacketCluster
+	objects       []client.Object
+	patcher       scope.Patcher
+}
+
+// NewClusterScopeBuilder returns a builder of ClusterScopes for a Cluster and a PacketCluster named ClusterName.
+func NewClusterScopeBuilder() *ClusterScopeBuilder {
+	return &ClusterScopeBuilder{
+		cluster:       newCluster(),
+		packetCluster: newPacketCluster(),
+	}
+}
+
+// WithCluster sets the Cluster of the scope.
+func (b *ClusterScopeBuilder) WithCluster(cluster *clusterv1.Cluster) *ClusterScopeBuilder {
+	b.cluster = cluster
+	return b
+}
+
+// WithPacketCluster sets the PacketCluster of the scope.
+func (b *ClusterScopeBuilder) WithPacketCluster(packetCluster *infrav1.PacketCluster) *ClusterScopeBuilder {
+	b.packetCluster = packetCluster
+	return b
+}
+
+// WithObjects adds objects to the fake client of the scope, on top of the ones of the scope.
+func (b *ClusterScopeBuilder) WithObjects(objects ...client.Object) *ClusterScopeBuilder {
+	b.objects = append(b.objects, objects...)
+	return b
+}
+
+// WithPatcher sets the Patcher of the scope, instead of patching the objects of the fake client.
+func (b *ClusterScopeBuilder) WithPatcher(patcher scope.Patcher) *ClusterScopeBuilder {
+	b.patcher = patcher
+	return b
+}
+
+// Build returns the ClusterScope, and the fake client holding its objects.
+func (b *ClusterScopeBuilder) Build() (*scope.ClusterScope, client.Client, error) {
+	c := newClient(append([]client.Object{b.cluster, b.packetCluster}, b.objects...)...)
+	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+		Client:        c,
+		Cluster:       b.cluster,
+		PacketCluster: b.packetCluster,
+		Patcher:       b.patcher,
+	})
+	return clusterScope, c, err
+}
+
+// MachineScopeBuilder builds MachineScopes.
+type MachineScopeBuilder struct {
+	cluster       *clusterv1.Cluster
+	machine       *clusterv1.Machine
+	packetCluster *infrav1.PacketCluster
+	packetMachine *infrav1.PacketMachine
+	objects       []client.Object
+	patcher       scope.Patcher
+}
+
+// NewMachineScopeBuilder returns a builder of MachineScopes for a Machine and a PacketMachine named MachineName, of
+// a Cluster and a PacketCluster named ClusterName.
+func NewMachineScopeBuilder() *MachineScopeBuilder {
+	return &MachineScopeBuilder{
+		cluster: newCluster(),
+		machine: &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      MachineName,
+				Namespace: Namespace,
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: ClusterName},
+			},
+			Spec: clusterv1.MachineSpec{ClusterName: ClusterName},
+		},
+		packetCluster: newPacketCluster(),
+		packetMachine: &infrav1.PacketMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      MachineName,
+				Namespace: Namespace,
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: ClusterName},
+			},
+		},
+	}
+}
+
+// WithCluster sets the Cluster of the scope.
+func (b *MachineScopeBuilder) WithCluster(cluster *clusterv1.Cluster) *MachineScopeBuilder {
+	b.cluster = cluster
+	return b
+}
+
+// WithMachine sets the Machine of the scope.
+func (b *MachineScopeBuilder) WithMachine(machine *clusterv1.Machine) *MachineScopeBuilder {
+	b.machine = machine
+	return b
+}
+
+// WithPacketCluster sets the PacketCluster of the scope.
+func (b *MachineScopeBuilder) WithPacketCluster(packetCluster *infrav1.PacketCluster) *MachineScopeBuilder {
+	b.packetCluster = packetCluster
+	return b
+}
+
+// WithPacketMachine sets the PacketMachine of the scope.
+func (b *MachineScopeBuilder) WithPacketMachine(packetMachine *infrav1.PacketMachine) *MachineScopeBuilder {
+	b.packetMachine = packetMachine
+	return b
+}
+
+// WithObjects adds objects to the fake client of the scope, on top of the ones of the scope, e.g. the bootstrap
+// data Secret of the Machine.
+func (b *MachineScopeBuilder) WithObjects(objects ...client.Object) *MachineScopeBuilder {
+	b.objects = append(b.objects, objects...)
+	return b
+}
+
+// WithPatcher sets the Patcher of the scope, instead of patching the objects of the fake client.
+func (b *MachineScopeBuilder) WithPatcher(patcher scope.Patcher) *MachineScopeBuilder {
+	b.patcher = patcher
+	return b
+}
+
+// Build returns the MachineScope, and the fake client holding its objects.
+func (b *MachineScopeBuilder) Build() (*scope.MachineScope, client.Client, error) {
+	c := newClient(append([]client.Object{b.cluster, b.machine, b.packetCluster, b.packetMachine}, b.objects...)...)
+	machineScope, err := scope.NewMachineScope(scope.MachineScopeParams{
+		Client:        c,
+		Cluster:       b.cluster,
+		Machine:       b.machine,
+		PacketCluster: b.packetCluster,
+		PacketMachine: b.packetMachine,
+		Patcher:       b.patcher,
+	})
+	return machineScope, c, err
+}
+
+func newCluster() *clusterv1.Cluster {
+	return &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: ClusterName, Namespace: Namespace},
+	}
+}
+
+func newPacketCluster() *infrav1.PacketCluster {
+	return &infrav1.PacketCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ClusterName,
+			Namespace: Namespace,
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: ClusterName},
+		},
+	}
+}
+
+func newClient(objects ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(Scheme()).
+		WithObjects(objects...).
+		WithStatusSubresource(&infrav1.PacketCluster{}, &infrav1.PacketMachine{}).
+		Build()
+}
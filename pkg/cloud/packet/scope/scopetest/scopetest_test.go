@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scopetest
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestMachineScopeBuilder(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	machineScope, c, err := NewMachineScopeBuilder().Build()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	machineScope.SetProviderID("device")
+	machineScope.SetReady()
+	g.Expect(machineScope.Close(ctx)).To(Succeed())
+
+	packetMachine := &infrav1.PacketMachine{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: Namespace, Name: MachineName}, packetMachine)).To(Succeed())
+	g.Expect(packetMachine.Spec.ProviderID).To(HaveValue(Equal("equinixmetal://device")))
+	g.Expect(packetMachine.Status.Ready).To(BeTrue())
+}
+
+func TestClusterScopeBuilderWithPatcher(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	patcher := &Patcher{}
+	clusterScope, c, err := NewClusterScopeBuilder().WithPatcher(patcher).Build()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	clusterScope.SetReady()
+	g.Expect(clusterScope.Close(ctx)).To(Succeed())
+
+	g.Expect(patcher.Patched()).To(HaveLen(1))
+	g.Expect(patcher.Patched()[0].(*infrav1.PacketCluster).Status.Ready).To(BeTrue())
+
+	// The patcher replaces the fake client for persisting the scope.
+	packetCluster := &infrav1.PacketCluster{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: Namespace, Name: ClusterName}, packetCluster)).To(Succeed())
+	g.Expect(packetCluster.Status.Ready).To(BeFalse())
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// ResolveSpecTemplates renders the templated fields of the PacketMachine spec, see PacketMachineSpec, in place.
+// The resolved values are persisted with the next patch, so the device keeps them even if the topology variables
+// change afterwards.
+func (m *MachineScope) ResolveSpecTemplates() error {
+	spec := &m.PacketMachine.Spec
+
+	if !hasSpecTemplates(spec) {
+		return nil
+	}
+
+	data, err := m.templateData()
+	if err != nil {
+		return err
+	}
+
+	render := func(name, value string) (string, error) {
+		if !infrav1.IsTemplated(value) {
+			return value, nil
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse %s template: %w", name, err)
+		}
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return "", fmt.Errorf("failed to render %s template: %w", name, err)
+		}
+		return sb.String(), nil
+	}
+
+	machineType, err := render("machineType", spec.MachineType)
+	if err != nil {
+		return err
+	}
+	facility, err := render("facility", spec.Facility)
+	if err != nil {
+		return err
+	}
+	metro, err := render("metro", spec.Metro)
+	if err != nil {
+		return err
+	}
+	// The iPXE URL may refer to the metro of the device, resolved above.
+	data["metro"] = metro
+	if metro == "" && m.PacketCluster != nil {
+		data["metro"] = m.PacketCluster.Spec.Metro
+	}
+	ipxeURL, err := render("ipxeURL", spec.IPXEUrl)
+	if err != nil {
+		return err
+	}
+	var tags infrav1.Tags
+	for i, tag := range spec.Tags {
+		rendered, err := render(fmt.Sprintf("tags[%d]", i), tag)
+		if err != nil {
+			return err
+		}
+		tags = append(tags, rendered)
+	}
+
+	spec.MachineType = machineType
+	spec.Facility = facility
+	spec.Metro = metro
+	spec.IPXEUrl = ipxeURL
+	spec.Tags = tags
+
+	return nil
+}
+
+func hasSpecTemplates(spec *infrav1.PacketMachineSpec) bool {
+	if infrav1.IsTemplated(spec.MachineType) || infrav1.IsTemplated(spec.Facility) || infrav1.IsTemplated(spec.Metro) ||
+		infrav1.IsTemplated(spec.IPXEUrl) {
+		return true
+	}
+	for _, tag := range spec.Tags {
+		if infrav1.IsTemplated(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// templateData returns the values available to PacketMachine spec templates.
+func (m *MachineScope) templateData() (map[string]interface{}, error) {
+	variables := map[string]interface{}{}
+
+	if topology := m.Cluster.Spec.Topology; topology != nil {
+		if err := decodeVariables(variables, topology.Variables); err != nil {
+			return nil, err
+		}
+
+		// Variables overridden by the MachineDeployment of the Machine win over the Cluster ones.
+		if topology.Workers != nil {
+			mdTopologyName := m.Machine.Labels[clusterv1.ClusterTopologyMachineDeploymentNameLabel]
+			for _, md := range topology.Workers.MachineDeployments {
+				if mdTopologyName == "" || md.Name != mdTopologyName || md.Variables == nil {
+					continue
+				}
+				if err := decodeVariables(variables, md.Variables.Overrides); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	role := "worker"
+	if m.IsControlPlane() {
+		role = "control-plane"
+	}
+
+	return map[string]interface{}{
+		"cluster": map[string]interface{}{
+			"name":      m.Cluster.Name,
+			"namespace": m.Cluster.Namespace,
+		},
+		"machine": map[string]interface{}{
+			"name": m.Machine.Name,
+			"role": role,
+		},
+		"machineSet": map[string]interface{}{
+			"name": m.Machine.Labels[clusterv1.MachineSetNameLabel],
+		},
+		"machineDeployment": map[string]interface{}{
+			"name": m.Machine.Labels[clusterv1.MachineDeploymentNameLabel],
+		},
+		"variables": variables,
+	}, nil
+}
+
+func decodeVariables(into map[string]interface{}, variables []clusterv1.ClusterVariable) error {
+	for _, v := range variables {
+		var value interface{}
+		if err := json.Unmarshal(v.Value.Raw, &value); err != nil {
+			return fmt.Errorf("failed to decode topology variable %s: %w", v.Name, err)
+		}
+		into[v.Name] = value
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+func TestMachineScopeResolveSpecTemplates(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "capi", Namespace: "default"},
+		Spec: clusterv1.ClusterSpec{
+			Topology: &clusterv1.Topology{
+				Variables: []clusterv1.ClusterVariable{
+					{Name: "workerMetro", Value: apiextensionsv1.JSON{Raw: []byte(`"da"`)}},
+					{Name: "workerPlan", Value: apiextensionsv1.JSON{Raw: []byte(`"c3.small.x86"`)}},
+				},
+				Workers: &clusterv1.WorkersTopology{
+					MachineDeployments: []clusterv1.MachineDeploymentTopology{
+						{
+							Name: "md-0",
+						},
+						{
+							Name: "md-1",
+							Variables: &clusterv1.MachineDeploymentVariables{
+								Overrides: []clusterv1.ClusterVariable{
+									{Name: "workerMetro", Value: apiextensionsv1.JSON{Raw: []byte(`"sv"`)}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		spec    infrav1.PacketMachineSpec
+		want    infrav1.PacketMachineSpec
+		wantErr bool
+	}{
+		{
+			name: "plain fields are left untouched",
+			spec: infrav1.PacketMachineSpec{MachineType: "m3.small.x86", Metro: "ny", Tags: infrav1.Tags{"worker"}},
+			want: infrav1.PacketMachineSpec{MachineType: "m3.small.x86", Metro: "ny", Tags: infrav1.Tags{"worker"}},
+		},
+		{
+			name: "cluster variables",
+			labels: map[string]string{
+				clusterv1.MachineDeploymentNameLabel:                "capi-md-0-abcde",
+				clusterv1.ClusterTopologyMachineDeploymentNameLabel: "md-0",
+			},
+			spec: infrav1.PacketMachineSpec{
+				MachineType: "{{ .variables.workerPlan }}",
+				Metro:       "{{ .variables.workerMetro }}",
+				Tags:        infrav1.Tags{"pool:{{ .machineDeployment.name }}", "worker"},
+			},
+			want: infrav1.PacketMachineSpec{
+				MachineType: "c3.small.x86",
+				Metro:       "da",
+				Tags:        infrav1.Tags{"pool:capi-md-0-abcde", "worker"},
+			},
+		},
+		{
+			name: "machine deployment overrides win",
+			labels: map[string]string{
+				clusterv1.MachineDeploymentNameLabel:                "capi-md-1-fghij",
+				clusterv1.ClusterTopologyMachineDeploymentNameLabel: "md-1",
+			},
+			spec: infrav1.PacketMachineSpec{
+				MachineType: "{{ .variables.workerPlan }}",
+				Metro:       "{{ .variables.workerMetro }}",
+			},
+			want: infrav1.PacketMachineSpec{
+				MachineType: "c3.small.x86",
+				Metro:       "sv",
+			},
+		},
+		{
+			name: "ipxe url",
+			labels: map[string]string{
+				clusterv1.MachineControlPlaneLabel: "",
+			},
+			spec: infrav1.PacketMachineSpec{
+				IPXEUrl: "https://boot.example.com/{{ .cluster.name }}/{{ .machine.role }}/{{ .machine.name }}?metro={{ .metro }}",
+			},
+			want: infrav1.PacketMachineSpec{
+				IPXEUrl: "https://boot.example.com/capi/control-plane/capi-md-0-abcde-xyz?metro=ny",
+			},
+		},
+		{
+			name: "ipxe url with the metro of the machine",
+			spec: infrav1.PacketMachineSpec{
+				Metro:   "{{ .variables.workerMetro }}",
+				IPXEUrl: "https://boot.example.com/{{ .machine.role }}/{{ .metro }}",
+			},
+			want: infrav1.PacketMachineSpec{
+				Metro:   "da",
+				IPXEUrl: "https://boot.example.com/worker/da",
+			},
+		},
+		{
+			name:    "unknown variable",
+			spec:    infrav1.PacketMachineSpec{Metro: "{{ .variables.missing }}"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machineScope := &MachineScope{
+				Cluster: cluster,
+				Machine: &clusterv1.Machine{
+					ObjectMeta: metav1.ObjectMeta{Name: "capi-md-0-abcde-xyz", Namespace: "default", Labels: tt.labels},
+				},
+				PacketCluster: &infrav1.PacketCluster{Spec: infrav1.PacketClusterSpec{Metro: "ny"}},
+				PacketMachine: &infrav1.PacketMachine{Spec: tt.spec},
+			}
+
+			err := machineScope.ResolveSpecTemplates()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(machineScope.PacketMachine.Spec).To(Equal(tt.spec))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(machineScope.PacketMachine.Spec).To(Equal(tt.want))
+		})
+	}
+}
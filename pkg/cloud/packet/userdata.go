@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// maxUserDataSize is the largest userdata Equinix Metal accepts for a device, in bytes.
+const maxUserDataSize = 64 * 1024
+
+// ErrBootstrapDataTooLarge is returned when the userdata of a device exceeds the size Equinix Metal accepts, even
+// compressed.
+var ErrBootstrapDataTooLarge = errors.New("bootstrap data too large")
+
+// fitUserData returns userdata Equinix Metal accepts. Cloud-config userdata above its size limit is gzipped into a
+// MIME multi-part archive, which cloud-init decompresses; other formats, and iPXE scripts given without a format,
+// cannot be compressed.
+func fitUserData(userData string, format scope.BootstrapFormat) (string, error) {
+	if len(userData) <= maxUserDataSize {
+		return userData, nil
+	}
+	if format != scope.BootstrapFormatCloudConfig {
+		return "", fmt.Errorf("%w: %d bytes of userdata, at most %d are accepted", ErrBootstrapDataTooLarge, len(userData), maxUserDataSize)
+	}
+
+	compressed, err := gzipUserData(userData)
+	if err != nil {
+		return "", err
+	}
+	if len(compressed) > maxUserDataSize {
+		return "", fmt.Errorf("%w: %d bytes of cloud-config userdata, %d once compressed, at most %d are accepted",
+			ErrBootstrapDataTooLarge, len(userData), len(compressed), maxUserDataSize)
+	}
+	return compressed, nil
+}
+
+// gzipUserData wraps userdata into a MIME multi-part archive holding it gzipped and base64 encoded.
+func gzipUserData(userData string) (string, error) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	if _, err := zw.Write([]byte(userData)); err != nil {
+		return "", fmt.Errorf("failed to compress userdata: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress userdata: %w", err)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"application/x-gzip"},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {`attachment; filename="user-data.gz"`},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to compress userdata: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(gz.Bytes())
+	for len(encoded) > 76 {
+		fmt.Fprintf(part, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(part, "%s\r\n", encoded)
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress userdata: %w", err)
+	}
+
+	header := fmt.Sprintf("Content-Type: multipart/mixed; boundary=%q\r\nMIME-Version: 1.0\r\n\r\n", mw.Boundary())
+	return header + body.String(), nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+func TestFitUserData(t *testing.T) {
+	g := NewWithT(t)
+
+	// Userdata within the limit is left alone.
+	small := "#cloud-config\nruncmd: []\n"
+	g.Expect(fitUserData(small, scope.BootstrapFormatCloudConfig)).To(Equal(small))
+
+	// Larger cloud-config is compressed into a multi-part archive cloud-init reads.
+	large := "#cloud-config\nwrite_files:\n" + strings.Repeat("- path: /etc/kubernetes/pki/ca.crt\n  content: AAAA\n", 3000)
+	g.Expect(len(large)).To(BeNumerically(">", maxUserDataSize))
+	fitted, err := fitUserData(large, scope.BootstrapFormatCloudConfig)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(len(fitted)).To(BeNumerically("<=", maxUserDataSize))
+
+	msg, err := mail.ReadMessage(strings.NewReader(fitted))
+	g.Expect(err).ToNot(HaveOccurred())
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(mediaType).To(Equal("multipart/mixed"))
+	part, err := multipart.NewReader(msg.Body, params["boundary"]).NextPart()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(part.Header.Get("Content-Type")).To(Equal("application/x-gzip"))
+	encoded, err := io.ReadAll(part)
+	g.Expect(err).ToNot(HaveOccurred())
+	compressed, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	g.Expect(err).ToNot(HaveOccurred())
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(io.ReadAll(zr)).To(Equal([]byte(large)))
+
+	// Other formats cannot be compressed.
+	_, err = fitUserData(large, scope.BootstrapFormatIgnition)
+	g.Expect(err).To(MatchError(ErrBootstrapDataTooLarge))
+
+	// Neither can data that does not compress enough.
+	random := make([]byte, maxUserDataSize)
+	_, _ = rand.Read(random)
+	_, err = fitUserData("#cloud-config\n"+base64.StdEncoding.EncodeToString(random), scope.BootstrapFormatCloudConfig)
+	g.Expect(err).To(MatchError(ErrBootstrapDataTooLarge))
+}
@@ -18,12 +18,16 @@ package packet
 
 import (
 	"fmt"
+	"strings"
 )
 
 const (
 	machineUIDTag = "capp:machine-uid"
 	clusterIDTag  = "capp:cluster-id"
 	namespaceTag  = "capp:namespace"
+	releasedTag   = "capp:released"
+	poolTag       = "capp:machine-pool"
+	claimTag      = "capp:device-claim"
 )
 
 // GenerateMachineNameTag generates a tag for a machine.
@@ -31,6 +35,16 @@ func GenerateMachineNameTag(name string) string {
 	return fmt.Sprintf("%s:%s", machineUIDTag, name)
 }
 
+// MachineNameFromTags returns the name of the machine a device was created for, from the tags of the device.
+func MachineNameFromTags(tags []string) (string, bool) {
+	for _, tag := range tags {
+		if name, ok := strings.CutPrefix(tag, machineUIDTag+":"); ok && name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
 // GenerateClusterTag generates a tag for a cluster.
 func GenerateClusterTag(clusterName string) string {
 	return fmt.Sprintf("%s:%s", clusterIDTag, clusterName)
@@ -41,6 +55,21 @@ func GenerateNamespaceTag(namespace string) string {
 	return fmt.Sprintf("%s:%s", namespaceTag, namespace)
 }
 
+// GenerateReleasedTag generates a tag for a device released to be adopted by a machine of a MachineDeployment.
+func GenerateReleasedTag(machineDeployment string) string {
+	return fmt.Sprintf("%s:%s", releasedTag, machineDeployment)
+}
+
+// GenerateMachinePoolTag generates a tag for the devices of a machine pool.
+func GenerateMachinePoolTag(name string) string {
+	return fmt.Sprintf("%s:%s", poolTag, name)
+}
+
+// GenerateDeviceClaimTag generates a tag for the device bound to a PacketDeviceClaim.
+func GenerateDeviceClaimTag(namespace, name string) string {
+	return fmt.Sprintf("%s:%s/%s", claimTag, namespace, name)
+}
+
 // ItemsInList checks if all items are in the list.
 func ItemsInList(list []string, items []string) bool {
 	// convert the items against which we are mapping into a map
@@ -71,3 +100,12 @@ func DefaultCreateTags(namespace, name, clusterName string) []string {
 		GenerateNamespaceTag(namespace),
 	}
 }
+
+// MachinePoolCreateTags returns the tags of the devices of a machine pool.
+func MachinePoolCreateTags(namespace, name, clusterName string) []string {
+	return []string{
+		GenerateClusterTag(clusterName),
+		GenerateMachinePoolTag(name),
+		GenerateNamespaceTag(namespace),
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-packet/api/v1beta1"
+)
+
+// bondPortName is the name of the bond port of the devices, which VLANs are attached to in hybrid bonded mode.
+const bondPortName = "bond0"
+
+var (
+	// ErrVLANNotFound is returned when no VLAN of the cluster exists with the given name.
+	ErrVLANNotFound = errors.New("VLAN not found")
+	// ErrBondPortNotFound is returned when a device has no bond port to attach VLANs to.
+	ErrBondPortNotFound = errors.New("bond port not found")
+)
+
+// CreateVLAN creates a VLAN of the cluster in the given metro, tagged to be found again by GetVLAN.
+func (p *Client) CreateVLAN(ctx context.Context, clusterName, projectID, metro string, vlan infrav1.VLAN) (*metal.VirtualNetwork, error) {
+	input := metal.VirtualNetworkCreateInput{
+		Metro: &metro,
+		Vxlan: vlan.VXLAN,
+		Tags:  []string{generateVLANIdentifier(clusterName, vlan.Name)},
+	}
+	if vlan.Description != "" {
+		input.Description = &vlan.Description
+	}
+
+	vn, _, err := p.VLANsApi.CreateVirtualNetwork(ctx, projectID).VirtualNetworkCreateInput(input).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VLAN %s: %w", vlan.Name, err)
+	}
+	return vn, nil
+}
+
+// GetVLAN returns the VLAN of the cluster with the given name.
+func (p *Client) GetVLAN(ctx context.Context, clusterName, projectID, name string) (*metal.VirtualNetwork, error) {
+	identifier := generateVLANIdentifier(clusterName, name)
+	vlans, _, err := p.VLANsApi.FindVirtualNetworks(ctx, projectID).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VLANs: %w", err)
+	}
+	for i := range vlans.VirtualNetworks {
+		if ItemsInList(vlans.VirtualNetworks[i].Tags, []string{identifier}) {
+			return &vlans.VirtualNetworks[i], nil
+		}
+	}
+	return nil, ErrVLANNotFound
+}
+
+// DeleteVLAN deletes the VLAN with the given ID. A VLAN that is already gone is not an error.
+func (p *Client) DeleteVLAN(ctx context.Context, id string) error {
+	resp, err := p.VLANsApi.DeleteVirtualNetwork(ctx, id).Execute()
+	if resp != nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+	}
+	return err
+}
+
+// DeviceBondPort returns the bond port of a device.
+func DeviceBondPort(dev *metal.Device) (*metal.Port, error) {
+	for i := range dev.NetworkPorts {
+		port := &dev.NetworkPorts[i]
+		if port.GetType() == metal.PORTTYPE_NETWORK_BOND_PORT && port.GetName() == bondPortName {
+			return port, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: device %s has no port %s", ErrBondPortNotFound, dev.GetId(), bondPortName)
+}
+
+// AttachVLAN attaches a port to a VLAN. Attaching the bond port of a layer 3 device converts it to hybrid bonded.
+func (p *Client) AttachVLAN(ctx context.Context, portID, vlanID string) error {
+	_, _, err := p.PortsApi.AssignPort(ctx, portID).PortAssignInput(metal.PortAssignInput{Vnid: &vlanID}).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	return err
+}
+
+// DetachVLAN detaches a port from a VLAN.
+func (p *Client) DetachVLAN(ctx context.Context, portID, vlanID string) error {
+	_, _, err := p.PortsApi.UnassignPort(ctx, portID).PortAssignInput(metal.PortAssignInput{Vnid: &vlanID}).Execute() //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	return err
+}
+
+func generateVLANIdentifier(clusterName, name string) string {
+	return fmt.Sprintf("cluster-api-provider-packet:vlan:%s:%s", clusterName, name)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metalinfra
+
+import (
+	"context"
+	"net/http"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+)
+
+// Devices manages the lifecycle of Equinix Metal devices.
+type Devices interface {
+	// Create creates the device of a machine, rendering its userdata from the bootstrap data of the machine and
+	// using the hardware reservations, spot market, network and SSH key settings of its PacketMachine.
+	Create(ctx context.Context, req packet.CreateDeviceRequest) (*metal.Device, error)
+	// Get returns the device with the given ID.
+	Get(ctx context.Context, deviceID string) (*metal.Device, error)
+	// FindByTags returns the devices of the project with all the given tags, oldest first.
+	FindByTags(ctx context.Context, projectID string, tags []string) ([]metal.Device, error)
+	// Addresses returns the node addresses of a device.
+	Addresses(dev *metal.Device) []corev1.NodeAddress
+	// PowerOff powers off a device, keeping it and its hardware reservation.
+	PowerOff(ctx context.Context, deviceID string) error
+	// PowerOn powers on a device.
+	PowerOn(ctx context.Context, deviceID string) error
+	// Reboot reboots a device, keeping its disks.
+	Reboot(ctx context.Context, deviceID string) error
+	// Delete deletes a device, forcing the deletion of devices that are still provisioning when force is set. A
+	// device that is already gone is not an error.
+	Delete(ctx context.Context, deviceID string, force bool) error
+}
+
+type devices struct {
+	packet *packet.Client
+}
+
+var _ Devices = &devices{}
+
+func (d *devices) Create(ctx context.Context, req packet.CreateDeviceRequest) (*metal.Device, error) {
+	return d.packet.NewDevice(ctx, req)
+}
+
+func (d *devices) Get(ctx context.Context, deviceID string) (*metal.Device, error) {
+	dev, _, err := d.packet.GetDevice(ctx, deviceID) //nolint:bodyclose // see https://github.com/timakin/bodyclose/issues/42
+	return dev, err
+}
+
+func (d *devices) FindByTags(ctx context.Context, projectID string, tags []string) ([]metal.Device, error) {
+	return d.packet.GetDevicesByTags(ctx, projectID, tags)
+}
+
+func (d *devices) Addresses(dev *metal.Device) []corev1.NodeAddress {
+	return d.packet.GetDeviceAddresses(dev)
+}
+
+func (d *devices) PowerOff(ctx context.Context, deviceID string) error {
+	return d.packet.PowerOffDevice(ctx, deviceID)
+}
+
+func (d *devices) PowerOn(ctx context.Context, deviceID string) error {
+	return d.packet.PowerOnDevice(ctx, deviceID)
+}
+
+func (d *devices) Reboot(ctx context.Context, deviceID string) error {
+	return d.packet.RebootDevice(ctx, deviceID)
+}
+
+func (d *devices) Delete(ctx context.Context, deviceID string, force bool) error {
+	resp, err := d.packet.DevicesApi.DeleteDevice(ctx, deviceID).ForceDelete(force).Execute()
+	if resp != nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,27 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metalinfra is the supported Go API of the provider for programmatic consumers, such as operators that
+// manage Equinix Metal devices, Elastic IPs and load balancers the way the provider does without running it.
+//
+// The interfaces of this package follow semantic versioning with the module: within a major version of the
+// provider, methods are neither removed nor changed in incompatible ways, and new capabilities are added as new
+// interfaces rather than new methods of existing ones, so that consumers implementing them for tests keep
+// compiling. APIVersion is bumped when an interface is added.
+//
+// The other packages of the module, including pkg/cloud/packet, are the implementation of the controllers and may
+// change in any release.
+package metalinfra
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metalinfra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+)
+
+// ElasticIPs manages the control plane Elastic IPs of clusters, tagged with the name of their cluster the way the
+// provider tags them, so that the provider and consumers of this package find each other's.
+type ElasticIPs interface {
+	// Reserve reserves the control plane Elastic IP of a cluster in a metro, or in a facility when metro is empty.
+	Reserve(ctx context.Context, clusterName, projectID, facility, metro string) (*metal.IPReservation, error)
+	// Find returns the control plane Elastic IP of a cluster, or ErrNotFound.
+	Find(ctx context.Context, clusterName, projectID string) (*metal.IPReservation, error)
+	// Assign assigns an Elastic IP to a device.
+	Assign(ctx context.Context, deviceID, address string) error
+	// Unassign removes the IP assignment with the given ID from its device. An assignment that is already gone is
+	// not an error.
+	Unassign(ctx context.Context, assignmentID string) error
+	// Release releases the IP reservation with the given ID. A reservation that is already gone is not an error.
+	Release(ctx context.Context, reservationID string) error
+}
+
+type elasticIPs struct {
+	packet *packet.Client
+}
+
+var _ ElasticIPs = &elasticIPs{}
+
+func (e *elasticIPs) Reserve(ctx context.Context, clusterName, projectID, facility, metro string) (*metal.IPReservation, error) {
+	if metro != "" {
+		facility = ""
+	}
+	return e.packet.CreateIP(ctx, "", clusterName, projectID, facility, metro)
+}
+
+func (e *elasticIPs) Find(ctx context.Context, clusterName, projectID string) (*metal.IPReservation, error) {
+	reservation, err := e.packet.GetIPByClusterIdentifier(ctx, "", clusterName, projectID)
+	if errors.Is(err, packet.ErrControlPlanEndpointNotFound) {
+		return nil, fmt.Errorf("%w: elastic IP of cluster %s", ErrNotFound, clusterName)
+	}
+	return reservation, err
+}
+
+func (e *elasticIPs) Assign(ctx context.Context, deviceID, address string) error {
+	return e.packet.AssignIP(ctx, deviceID, address)
+}
+
+func (e *elasticIPs) Unassign(ctx context.Context, assignmentID string) error {
+	return e.packet.UnassignIP(ctx, assignmentID)
+}
+
+func (e *elasticIPs) Release(ctx context.Context, reservationID string) error {
+	return e.packet.DeleteIPReservation(ctx, reservationID)
+}
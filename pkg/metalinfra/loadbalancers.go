@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metalinfra
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-packet/internal/emlb"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet/scope"
+)
+
+// LoadBalancers reconciles the Equinix Metal Load Balancer of clusters using the EMLB VIP manager, and its pools,
+// from their PacketCluster and PacketMachines.
+type LoadBalancers interface {
+	// ReconcileCluster creates the load balancer of the cluster, or uses its existing one, and sets the control
+	// plane endpoint of the PacketCluster to it.
+	ReconcileCluster(ctx context.Context, clusterScope *scope.ClusterScope) error
+	// ReconcileClusterPools creates the load balancer pools of the PacketCluster.
+	ReconcileClusterPools(ctx context.Context, clusterScope *scope.ClusterScope) error
+	// DeleteCluster deletes the load balancer of the cluster and its pools, unless the load balancer was not
+	// created for the cluster.
+	DeleteCluster(ctx context.Context, clusterScope *scope.ClusterScope) error
+	// ReconcileMachine adds the device of a machine, with the given addresses, as an origin of the control plane
+	// pool when it is a control plane machine, and of the load balancer pools it opted into.
+	ReconcileMachine(ctx context.Context, machineScope *scope.MachineScope, deviceAddr []corev1.NodeAddress) error
+	// DeleteMachine removes the device of a machine from the pools of the load balancer.
+	DeleteMachine(ctx context.Context, machineScope *scope.MachineScope) error
+}
+
+type loadBalancers struct {
+	emlb *emlb.EMLB
+}
+
+var _ LoadBalancers = &loadBalancers{}
+
+func newLoadBalancers(packetClient *packet.Client, projectID, metro string) *loadBalancers {
+	return &loadBalancers{emlb: emlb.NewEMLB(packetClient.GetConfig().DefaultHeader["X-Auth-Token"], projectID, metro)}
+}
+
+func (l *loadBalancers) ReconcileCluster(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	return l.emlb.ReconcileLoadBalancer(ctx, clusterScope)
+}
+
+func (l *loadBalancers) ReconcileClusterPools(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	return l.emlb.ReconcileLoadBalancerPools(ctx, clusterScope)
+}
+
+func (l *loadBalancers) DeleteCluster(ctx context.Context, clusterScope *scope.ClusterScope) error {
+	if err := l.emlb.DeleteLoadBalancerPools(ctx, clusterScope); err != nil {
+		return err
+	}
+	return l.emlb.DeleteClusterLoadBalancer(ctx, clusterScope)
+}
+
+func (l *loadBalancers) ReconcileMachine(ctx context.Context, machineScope *scope.MachineScope, deviceAddr []corev1.NodeAddress) error {
+	if machineScope.IsControlPlane() {
+		if err := l.emlb.ReconcileVIPOrigin(ctx, machineScope, deviceAddr); err != nil {
+			return err
+		}
+	}
+	return l.emlb.ReconcileLoadBalancerPoolOrigins(ctx, machineScope, deviceAddr)
+}
+
+func (l *loadBalancers) DeleteMachine(ctx context.Context, machineScope *scope.MachineScope) error {
+	if machineScope.IsControlPlane() {
+		if err := l.emlb.DeleteLoadBalancerOrigin(ctx, machineScope); err != nil {
+			return err
+		}
+	}
+	return l.emlb.DeleteLoadBalancerPoolOrigins(ctx, machineScope)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metalinfra
+
+import (
+	"errors"
+
+	"sigs.k8s.io/cluster-api-provider-packet/pkg/cloud/packet"
+)
+
+// APIVersion is the version of the interfaces of this package.
+const APIVersion = "v1.0"
+
+// ErrNotFound is returned when a resource looked up by its tags does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Client gives access to the device, Elastic IP and load balancer management of the provider.
+type Client struct {
+	packet *packet.Client
+}
+
+// New returns a Client using the given Equinix Metal API key.
+func New(apiKey string) *Client {
+	return &Client{packet: packet.NewClient(apiKey)}
+}
+
+// Devices returns the device lifecycle management of the client.
+func (c *Client) Devices() Devices {
+	return &devices{packet: c.packet}
+}
+
+// ElasticIPs returns the Elastic IP management of the client.
+func (c *Client) ElasticIPs() ElasticIPs {
+	return &elasticIPs{packet: c.packet}
+}
+
+// LoadBalancers returns the Equinix Metal Load Balancer management of the client for the clusters of a project in
+// a metro.
+func (c *Client) LoadBalancers(projectID, metro string) LoadBalancers {
+	return newLoadBalancers(c.packet, projectID, metro)
+}
+
+// IsRetryable reports whether a request failed for a reason expected to go away by itself, such as rate limiting,
+// busy hardware reservations or server errors, so that it is to be retried rather than reported as a failure.
+func IsRetryable(err error) bool {
+	return packet.IsRetryable(err)
+}
+
+// IsQuotaExceeded reports whether a request failed because the project or organization reached one of its limits.
+func IsQuotaExceeded(err error) bool {
+	return packet.IsQuotaExceeded(err)
+}
+
+// IsReservationBusy reports whether a device creation failed because its hardware reservations are not available
+// yet.
+func IsReservationBusy(err error) bool {
+	return packet.IsReservationBusy(err)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metalinfra
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/gomega"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := New("token")
+	client.packet.GetConfig().Servers = metal.ServerConfigurations{{URL: server.URL}}
+	return client
+}
+
+func TestDevicesDeleteMissingDevice(t *testing.T) {
+	g := NewWithT(t)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.Method).To(Equal(http.MethodDelete))
+		g.Expect(r.URL.Path).To(Equal("/devices/device"))
+		g.Expect(r.URL.Query().Get("force_delete")).To(Equal("true"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors": ["Not found"]}`))
+	})
+
+	g.Expect(client.Devices().Delete(context.Background(), "device", true)).To(Succeed())
+}
+
+func TestElasticIPsFind(t *testing.T) {
+	g := NewWithT(t)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.URL.Path).To(Equal("/projects/project/ips"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ip_addresses": [
+			{"id": "other", "address": "10.0.0.1", "type": "public_ipv4", "tags": ["cluster-api-provider-packet:cluster-id:other"]},
+			{"id": "eip", "address": "10.0.0.2", "type": "public_ipv4", "tags": ["cluster-api-provider-packet:cluster-id:cluster"]}
+		]}`))
+	})
+
+	eip, err := client.ElasticIPs().Find(context.Background(), "cluster", "project")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(eip.GetId()).To(Equal("eip"))
+
+	_, err = client.ElasticIPs().Find(context.Background(), "missing", "project")
+	g.Expect(err).To(MatchError(ErrNotFound))
+}
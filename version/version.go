@@ -25,6 +25,9 @@ import (
 	metal "github.com/equinix/equinix-sdk-go/services/metalv1"
 )
 
+// ClusterAPIContract is the Cluster API contract the provider implements.
+const ClusterAPIContract = "v1beta1"
+
 var (
 	gitMajor     string  // major version, always numeric
 	gitMinor     string  // minor version, numeric possibly followed by "+"